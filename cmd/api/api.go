@@ -19,8 +19,10 @@ package api
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
@@ -33,6 +35,9 @@ import (
 	mw "github.com/k0sproject/k0s/internal/pkg/middleware"
 	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	apdl "github.com/k0sproject/k0s/pkg/autopilot/download"
+	"github.com/k0sproject/k0s/pkg/certificate"
+	apclient "github.com/k0sproject/k0s/pkg/client/clientset"
 	"github.com/k0sproject/k0s/pkg/config"
 	"github.com/k0sproject/k0s/pkg/constant"
 	"github.com/k0sproject/k0s/pkg/etcd"
@@ -48,7 +53,8 @@ import (
 
 type command struct {
 	config.CLIOptions
-	client kubernetes.Interface
+	client          kubernetes.Interface
+	autopilotClient apclient.Interface
 }
 
 const (
@@ -86,6 +92,27 @@ func (c *command) start() (err error) {
 		return err
 	}
 
+	restConfig, err := kubeutil.ClientConfig(kubeutil.KubeconfigFromFile(c.K0sVars.AdminKubeConfigPath))
+	if err != nil {
+		return err
+	}
+	c.autopilotClient, err = apclient.NewForConfig(restConfig)
+	if err != nil {
+		return err
+	}
+
+	// Client certificates are optional, but need to be issued by the cluster CA.
+	// The whole bundle is trusted, so that clients keep working while the CA
+	// is rotated.
+	caCert, err := certificate.ReadCABundle(c.K0sVars.CertRootDir)
+	if err != nil {
+		return err
+	}
+	clientCAs := x509.NewCertPool()
+	if !clientCAs.AppendCertsFromPEM(caCert) {
+		return fmt.Errorf("no certificates found in CA file")
+	}
+
 	prefix := "/v1beta1"
 	mux := http.NewServeMux()
 	storage := c.NodeConfig.Spec.Storage
@@ -103,6 +130,14 @@ func (c *command) start() (err error) {
 	}
 	mux.Handle(prefix+"/calico/kubeconfig", mw.AllowMethods(http.MethodGet)(
 		c.workerHandler(c.kubeConfigHandler())))
+	mux.Handle(prefix+"/cluster/status", mw.AllowMethods(http.MethodGet)(
+		c.adminHandler(c.clusterStatusHandler())))
+	mux.Handle(apdl.ProxyPath, mw.AllowMethods(http.MethodGet)(
+		c.nodeHandler(apdl.NewProxyHandler(
+			logrus.WithField("component", "autopilot"),
+			filepath.Join(c.K0sVars.DataDir, "autopilot", "downloads"),
+			c.autopilotDownloads,
+		))))
 
 	srv := &http.Server{
 		Handler: mux,
@@ -110,6 +145,8 @@ func (c *command) start() (err error) {
 		TLSConfig: &tls.Config{
 			MinVersion:   tls.VersionTLS12,
 			CipherSuites: constant.AllowedTLS12CipherSuiteIDs,
+			ClientAuth:   tls.VerifyClientCertIfGiven,
+			ClientCAs:    clientCAs,
 		},
 		WriteTimeout: 15 * time.Second,
 		ReadTimeout:  15 * time.Second,
@@ -270,6 +307,24 @@ func (c *command) caHandler() http.Handler {
 		}
 		caResp.SAPub = saPub
 
+		// Pass on an ongoing CA rotation, so that joining controllers trust
+		// the same CAs as the others.
+		for _, f := range []struct {
+			name string
+			data *[]byte
+		}{
+			{"ca-next.key", &caResp.NextKey},
+			{"ca-next.crt", &caResp.NextCert},
+			{"ca-previous.crt", &caResp.PreviousCert},
+		} {
+			data, err := os.ReadFile(path.Join(c.K0sVars.CertRootDir, f.name))
+			if err != nil && !errors.Is(err, os.ErrNotExist) {
+				sendError(err, resp)
+				return
+			}
+			*f.data = data
+		}
+
 		resp.Header().Set("content-type", "application/json")
 		if err := json.NewEncoder(resp).Encode(caResp); err != nil {
 			sendError(err, resp)
@@ -0,0 +1,81 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	apconst "github.com/k0sproject/k0s/pkg/autopilot/constant"
+	apdl "github.com/k0sproject/k0s/pkg/autopilot/download"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// autopilotDownloads returns the URLs of all of the resources of the current
+// autopilot plan that are to be downloaded through the controllers, mapped to
+// their expected SHA256 hashes. The signatures of the resources are included.
+func (c *command) autopilotDownloads(ctx context.Context) (map[string]string, error) {
+	plan, err := c.autopilotClient.AutopilotV1beta2().Plans().Get(ctx, apconst.AutopilotName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return proxiedPlanResources(plan), nil
+}
+
+// proxiedPlanResources collects the URLs of all of the resources of a plan that
+// are to be downloaded through the controllers, including their signatures.
+func proxiedPlanResources(plan *apv1beta2.Plan) map[string]string {
+	resources := make(map[string]string)
+	add := func(platforms apv1beta2.PlanPlatformResourceURLMap) {
+		for _, resource := range platforms {
+			resources[resource.URL] = resource.Sha256
+			resources[resource.URL+apdl.SignatureSuffix] = ""
+		}
+	}
+
+	for _, cmd := range plan.Spec.Commands {
+		if cmd.K0sUpdate != nil && cmd.K0sUpdate.ProxyDownloads {
+			add(cmd.K0sUpdate.Platforms)
+		}
+		if cmd.AirgapUpdate != nil && cmd.AirgapUpdate.ProxyDownloads {
+			add(cmd.AirgapUpdate.Platforms)
+		}
+	}
+
+	return resources
+}
+
+// nodeHandler only allows requests that are authenticated by a client
+// certificate issued by the cluster CA, e.g. the ones of kubelets.
+func (c *command) nodeHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			sendError(fmt.Errorf("go away"), w, http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
+	k0sstatus "github.com/k0sproject/k0s/pkg/component/status"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
+)
+
+// clusterStatus collects the status of all controllers and nodes of the
+// cluster, as published by the k0s instances in their ControlNodes and Nodes.
+func (c *command) clusterStatus(ctx context.Context) (*v1beta1.ClusterStatusResponse, error) {
+	controlNodes, err := c.autopilotClient.AutopilotV1beta2().ControlNodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := c.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	status := clusterStatusFromControlNodes(controlNodes.Items)
+	status.Nodes = nodeStatuses(controlNodes.Items, nodes.Items)
+	return status, nil
+}
+
+// clusterStatusFromControlNodes converts the given ControlNodes into a cluster
+// status. Controllers are sorted by name. The leader of each lease is the
+// controller that reports to hold it.
+func clusterStatusFromControlNodes(nodes []apv1beta2.ControlNode) *v1beta1.ClusterStatusResponse {
+	status := &v1beta1.ClusterStatusResponse{
+		Controllers: []v1beta1.ControllerStatusResponse{},
+		Leaders:     map[string]string{},
+	}
+
+	for _, node := range nodes {
+		controller := v1beta1.ControllerStatusResponse{Name: node.Name}
+		if s := node.Status.Controller; s != nil {
+			controller.Version = s.Version
+			controller.ConfigHash = s.ConfigHash
+			controller.Leases = slices.Clone(s.Leases)
+			if s.Etcd != nil {
+				healthy := s.Etcd.Healthy
+				controller.EtcdHealthy = &healthy
+			}
+			heartbeat := s.LastHeartbeatTime
+			controller.LastHeartbeatTime = &heartbeat
+		}
+		status.Controllers = append(status.Controllers, controller)
+	}
+
+	sort.Slice(status.Controllers, func(i, j int) bool {
+		return status.Controllers[i].Name < status.Controllers[j].Name
+	})
+
+	leaders := make(map[string]*v1beta1.ControllerStatusResponse)
+	for i := range status.Controllers {
+		controller := &status.Controllers[i]
+		for _, lease := range controller.Leases {
+			// Two controllers might claim the same lease for a short while
+			// after a leader change. Prefer the most recent heartbeat.
+			if current, ok := leaders[lease]; ok && !current.LastHeartbeatTime.Before(controller.LastHeartbeatTime) {
+				continue
+			}
+			leaders[lease] = controller
+			status.Leaders[lease] = controller.Name
+		}
+	}
+
+	return status
+}
+
+// nodeStatuses aggregates the node statuses published in the annotations of
+// the given ControlNodes and Nodes, sorted by name. Controllers that run
+// workloads have both, they're reported once.
+func nodeStatuses(controlNodes []apv1beta2.ControlNode, nodes []corev1.Node) []v1beta1.NodeStatusResponse {
+	statuses := make(map[string]*v1beta1.NodeStatusResponse)
+	add := func(name, role string, annotations map[string]string) {
+		status, ok := statuses[name]
+		if !ok {
+			status = &v1beta1.NodeStatusResponse{Name: name, Role: role}
+			statuses[name] = status
+		}
+
+		if status.LastUpdateTime == nil {
+			nodeStatus, err := k0sstatus.ParseNodeStatus(annotations)
+			if err != nil {
+				logrus.WithError(err).Warnf("Ignoring status of node %s", name)
+			} else if nodeStatus != nil {
+				status.Role = nodeStatus.Role
+				status.Version = nodeStatus.Version
+				status.CABundleVersion = nodeStatus.CABundleVersion
+				for _, component := range nodeStatus.Components {
+					if component.Healthy {
+						continue
+					}
+					if status.UnhealthyComponents == nil {
+						status.UnhealthyComponents = make(map[string]string)
+					}
+					status.UnhealthyComponents[component.Name] = component.Message
+				}
+				lastUpdateTime := nodeStatus.LastUpdateTime
+				status.LastUpdateTime = &lastUpdateTime
+			}
+		}
+
+		if status.AutopilotStatus == "" && apsigv2.IsSignalingPresent(annotations) {
+			var signalData apsigv2.SignalData
+			if err := signalData.Unmarshal(annotations); err == nil && signalData.Status != nil {
+				status.AutopilotStatus = signalData.Status.Status
+			}
+		}
+	}
+
+	for i := range controlNodes {
+		add(controlNodes[i].Name, "controller", controlNodes[i].Annotations)
+	}
+	for i := range nodes {
+		add(nodes[i].Name, "worker", nodes[i].Annotations)
+	}
+
+	result := make([]v1beta1.NodeStatusResponse, 0, len(statuses))
+	for _, status := range statuses {
+		result = append(result, *status)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+func (c *command) clusterStatusHandler() http.Handler {
+	return http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		status, err := c.clusterStatus(req.Context())
+		if err != nil {
+			sendError(err, resp)
+			return
+		}
+
+		resp.Header().Set("content-type", "application/json")
+		if err := json.NewEncoder(resp).Encode(status); err != nil {
+			sendError(err, resp)
+			return
+		}
+	})
+}
+
+// adminHandler only allows requests that are authenticated by a client
+// certificate issued by the cluster CA for the system:masters group, e.g. the
+// one of the admin kubeconfig.
+func (c *command) adminHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+			sendError(fmt.Errorf("go away"), w, http.StatusUnauthorized)
+			return
+		}
+		if !slices.Contains(r.TLS.VerifiedChains[0][0].Subject.Organization, "system:masters") {
+			sendError(fmt.Errorf("go away"), w, http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
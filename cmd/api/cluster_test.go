@@ -0,0 +1,136 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
+	k0sstatus "github.com/k0sproject/k0s/pkg/component/status"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterStatusFromControlNodes(t *testing.T) {
+	now := time.Unix(1000, 0)
+	controlNode := func(name string, heartbeat time.Duration, leases ...string) apv1beta2.ControlNode {
+		return apv1beta2.ControlNode{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status: apv1beta2.ControlNodeStatus{Controller: &apv1beta2.ControllerStatus{
+				Version:           "v1.27.1+k0s.0",
+				Leases:            leases,
+				LastHeartbeatTime: metav1.NewTime(now.Add(-heartbeat)),
+			}},
+		}
+	}
+
+	status := clusterStatusFromControlNodes([]apv1beta2.ControlNode{
+		controlNode("controller-2", 10*time.Second, "k0s-component-helm"),
+		controlNode("controller-0", 5*time.Second, "k0s-endpoint-reconciler", "k0s-component-helm"),
+		{ObjectMeta: metav1.ObjectMeta{Name: "controller-1"}},
+	})
+
+	if assert.Len(t, status.Controllers, 3) {
+		assert.Equal(t, "controller-0", status.Controllers[0].Name)
+		assert.Equal(t, "v1.27.1+k0s.0", status.Controllers[0].Version)
+		assert.Equal(t, "controller-1", status.Controllers[1].Name)
+		assert.Nil(t, status.Controllers[1].LastHeartbeatTime)
+		assert.Equal(t, "controller-2", status.Controllers[2].Name)
+	}
+	assert.Equal(t, map[string]string{
+		"k0s-endpoint-reconciler": "controller-0",
+		// Claimed by both, controller-0 has the more recent heartbeat.
+		"k0s-component-helm": "controller-0",
+	}, status.Leaders)
+}
+
+func TestNodeStatuses(t *testing.T) {
+	lastUpdateTime := metav1.NewTime(time.Unix(1000, 0))
+	annotations := func(status *k0sstatus.NodeStatus, autopilotStatus string) map[string]string {
+		annotations := make(map[string]string)
+		if status != nil {
+			data, err := json.Marshal(status)
+			require.NoError(t, err)
+			annotations[k0sstatus.NodeStatusAnnotation] = string(data)
+		}
+		if autopilotStatus != "" {
+			id := 1
+			require.NoError(t, apsigv2.SignalData{
+				PlanID:  "autopilot",
+				Created: "now",
+				Command: apsigv2.Command{ID: &id, K0sUpdate: &apsigv2.CommandK0sUpdate{
+					URL:     "https://example.com/k0s",
+					Version: "v1.27.2+k0s.0",
+				}},
+				Status: apsigv2.NewStatus(autopilotStatus),
+			}.Marshal(annotations))
+		}
+		return annotations
+	}
+
+	controlNodes := []apv1beta2.ControlNode{{
+		ObjectMeta: metav1.ObjectMeta{Name: "controller-0", Annotations: annotations(&k0sstatus.NodeStatus{
+			Version:         "v1.27.1+k0s.0",
+			Role:            "controller+worker",
+			CABundleVersion: "0123456789abcdef",
+			LastUpdateTime:  lastUpdateTime,
+		}, "")},
+	}}
+	nodes := []corev1.Node{{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Annotations: annotations(&k0sstatus.NodeStatus{
+			Version: "v1.27.1+k0s.0",
+			Role:    "worker",
+			Components: []k0sstatus.ComponentHealth{
+				{Name: "containerd", Healthy: true},
+				{Name: "kubelet", Message: "connection refused"},
+			},
+			LastUpdateTime: lastUpdateTime,
+		}, "")},
+	}, {
+		// The worker of controller-0, which is being updated by autopilot.
+		ObjectMeta: metav1.ObjectMeta{Name: "controller-0", Annotations: annotations(nil, "Schedulable")},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-1", Annotations: map[string]string{
+			k0sstatus.NodeStatusAnnotation: "garbage",
+		}},
+	}}
+
+	assert.Equal(t, []v1beta1.NodeStatusResponse{{
+		Name:            "controller-0",
+		Role:            "controller+worker",
+		Version:         "v1.27.1+k0s.0",
+		CABundleVersion: "0123456789abcdef",
+		AutopilotStatus: "Schedulable",
+		LastUpdateTime:  &lastUpdateTime,
+	}, {
+		Name:                "worker-0",
+		Role:                "worker",
+		Version:             "v1.27.1+k0s.0",
+		UnhealthyComponents: map[string]string{"kubelet": "connection refused"},
+		LastUpdateTime:      &lastUpdateTime,
+	}, {
+		Name: "worker-1",
+		Role: "worker",
+	}}, nodeStatuses(controlNodes, nodes))
+}
@@ -0,0 +1,461 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autopilot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	apconst "github.com/k0sproject/k0s/pkg/autopilot/constant"
+	appc "github.com/k0sproject/k0s/pkg/autopilot/controller/plans/core"
+	apclient "github.com/k0sproject/k0s/pkg/client/clientset"
+	"github.com/k0sproject/k0s/pkg/config"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// releaseURLTemplate is the URL of the k0s binaries of a version and
+	// architecture on GitHub.
+	releaseURLTemplate = "https://github.com/k0sproject/k0s/releases/download/%[1]s/k0s-%[1]s-%[2]s"
+
+	// airgapURLTemplate is the URL of the airgap bundles of a version and
+	// architecture on GitHub.
+	airgapURLTemplate = "https://github.com/k0sproject/k0s/releases/download/%[1]s/k0s-airgap-bundle-%[1]s-%[2]s"
+
+	// statusPollInterval is the interval in which the plan status is polled
+	// when watching it.
+	statusPollInterval = 2 * time.Second
+)
+
+// defaultArchitectures are the Linux architectures for which k0s is released.
+var defaultArchitectures = []string{"amd64", "arm64", "arm"}
+
+type autopilotFlags struct {
+	kubeconfig string
+}
+
+// NewAutopilotCmd returns the "k0s autopilot" command, which creates and
+// monitors autopilot plans.
+func NewAutopilotCmd() *cobra.Command {
+	var flags autopilotFlags
+
+	cmd := &cobra.Command{
+		Use:   "autopilot",
+		Short: "Manage autopilot update plans",
+	}
+	cmd.SilenceUsage = true
+
+	pflags := cmd.PersistentFlags()
+	pflags.StringVar(&flags.kubeconfig, "kubeconfig", "", "path to the kubeconfig file (default: $KUBECONFIG or the k0s admin kubeconfig)")
+	pflags.AddFlagSet(config.GetPersistentFlagSet())
+
+	cmd.AddCommand(planCmd(&flags))
+	return cmd
+}
+
+// client returns an autopilot client, using the kubeconfig given on the
+// command line or in the environment, or the k0s admin kubeconfig.
+func (f *autopilotFlags) client() (apclient.Interface, error) {
+	kubeconfig := f.kubeconfig
+	if kubeconfig == "" {
+		if fromEnv, ok := os.LookupEnv("KUBECONFIG"); ok {
+			kubeconfig = fromEnv
+		} else {
+			kubeconfig = config.GetCmdOpts().K0sVars.AdminKubeConfigPath
+		}
+	}
+	if _, err := os.Stat(kubeconfig); err != nil {
+		return nil, fmt.Errorf("cannot stat kubeconfig, is the server running?: %w", err)
+	}
+
+	restConfig, err := kubeutil.ClientConfig(kubeutil.KubeconfigFromFile(kubeconfig))
+	if err != nil {
+		return nil, err
+	}
+
+	return apclient.NewForConfig(restConfig)
+}
+
+func planCmd(flags *autopilotFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Create, monitor and abort autopilot plans",
+	}
+
+	cmd.AddCommand(planCreateCmd(flags))
+	cmd.AddCommand(planStatusCmd(flags))
+	cmd.AddCommand(planAbortCmd(flags))
+	return cmd
+}
+
+// planOptions describe the plan to be created.
+type planOptions struct {
+	version            string
+	platforms          map[string]string
+	airgap             bool
+	airgapPlatforms    map[string]string
+	controllerSelector string
+	workerSelector     string
+	skipControllers    bool
+	skipWorkers        bool
+	workerConcurrency  int
+	force              bool
+	proxyDownloads     bool
+}
+
+func planCreateCmd(flags *autopilotFlags) *cobra.Command {
+	var (
+		opts   planOptions
+		dryRun bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a plan that updates the cluster to another k0s version",
+		Example: `  # Update all nodes to v1.27.2+k0s.0, five workers at a time
+  k0s autopilot plan create --version v1.27.2+k0s.0 --worker-concurrency 5
+
+  # Only update the workers labeled with env=staging, using a custom URL
+  k0s autopilot plan create --version v1.27.2+k0s.0 --skip-controllers \
+    --worker-selector env=staging --platform linux-amd64=https://example.com/k0s
+
+  # Print the plan without creating it
+  k0s autopilot plan create --version v1.27.2+k0s.0 --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			plan, err := buildPlan(&opts, time.Now())
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				data, err := yaml.Marshal(plan)
+				if err != nil {
+					return err
+				}
+				_, err = cmd.OutOrStdout().Write(data)
+				return err
+			}
+
+			client, err := flags.client()
+			if err != nil {
+				return err
+			}
+
+			return createPlan(cmd.Context(), cmd.OutOrStdout(), client, plan)
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&opts.version, "version", "", "the k0s version to update to")
+	f.StringToStringVar(&opts.platforms, "platform", nil, "the download URLs of the k0s binaries per platform (default: the GitHub release of the version)")
+	f.BoolVar(&opts.airgap, "airgap", false, "distribute the airgap bundles of the version to the workers before updating them")
+	f.StringToStringVar(&opts.airgapPlatforms, "airgap-platform", nil, "the download URLs of the airgap bundles per platform, implies --airgap (default: the GitHub release of the version)")
+	f.StringVar(&opts.controllerSelector, "controller-selector", "", "label selector for the controllers to update (default: all controllers)")
+	f.StringVar(&opts.workerSelector, "worker-selector", "", "label selector for the workers to update (default: all workers)")
+	f.BoolVar(&opts.skipControllers, "skip-controllers", false, "don't update any controllers")
+	f.BoolVar(&opts.skipWorkers, "skip-workers", false, "don't update any workers")
+	f.IntVar(&opts.workerConcurrency, "worker-concurrency", 1, "the number of workers that are updated at the same time")
+	f.BoolVar(&opts.force, "force", false, "update the nodes even if they already run the version")
+	f.BoolVar(&opts.proxyDownloads, "proxy-downloads", false, "let the workers download the updates through the controllers")
+	f.BoolVar(&dryRun, "dry-run", false, "print the plan instead of creating it")
+	_ = cmd.MarkFlagRequired("version")
+
+	return cmd
+}
+
+// buildPlan builds the plan described by opts.
+func buildPlan(opts *planOptions, now time.Time) (*apv1beta2.Plan, error) {
+	if opts.version == "" {
+		return nil, errors.New("version must not be empty")
+	}
+	if opts.skipControllers && opts.skipWorkers {
+		return nil, errors.New("cannot skip both controllers and workers")
+	}
+	if opts.workerConcurrency < 1 {
+		return nil, errors.New("worker concurrency must be at least 1")
+	}
+
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	plan := &apv1beta2.Plan{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: apv1beta2.SchemeGroupVersion.String(),
+			Kind:       "Plan",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: apconst.AutopilotName,
+		},
+		Spec: apv1beta2.PlanSpec{
+			ID:        "id-" + timestamp,
+			Timestamp: timestamp,
+		},
+	}
+
+	workers := apv1beta2.PlanCommandTarget{
+		Discovery: apv1beta2.PlanCommandTargetDiscovery{
+			Selector: &apv1beta2.PlanCommandTargetDiscoverySelector{Labels: opts.workerSelector},
+		},
+		Limits: apv1beta2.PlanCommandTargetLimits{Concurrent: opts.workerConcurrency},
+	}
+
+	if (opts.airgap || len(opts.airgapPlatforms) > 0) && !opts.skipWorkers {
+		plan.Spec.Commands = append(plan.Spec.Commands, apv1beta2.PlanCommand{
+			AirgapUpdate: &apv1beta2.PlanCommandAirgapUpdate{
+				Version:        opts.version,
+				Platforms:      platformURLs(opts.airgapPlatforms, airgapURLTemplate, opts.version),
+				Workers:        workers,
+				ProxyDownloads: opts.proxyDownloads,
+			},
+		})
+	}
+
+	k0sUpdate := &apv1beta2.PlanCommandK0sUpdate{
+		Version:        opts.version,
+		ForceUpdate:    opts.force,
+		Platforms:      platformURLs(opts.platforms, releaseURLTemplate, opts.version),
+		ProxyDownloads: opts.proxyDownloads,
+	}
+	if !opts.skipControllers {
+		k0sUpdate.Targets.Controllers = apv1beta2.PlanCommandTarget{
+			Discovery: apv1beta2.PlanCommandTargetDiscovery{
+				Selector: &apv1beta2.PlanCommandTargetDiscoverySelector{Labels: opts.controllerSelector},
+			},
+			Limits: apv1beta2.PlanCommandTargetLimits{Concurrent: 1},
+		}
+	}
+	if !opts.skipWorkers {
+		k0sUpdate.Targets.Workers = workers
+	}
+	plan.Spec.Commands = append(plan.Spec.Commands, apv1beta2.PlanCommand{K0sUpdate: k0sUpdate})
+
+	return plan, nil
+}
+
+// platformURLs returns the given platform URLs, or the URLs of the GitHub
+// release of the version if none are given.
+func platformURLs(platforms map[string]string, urlTemplate, version string) apv1beta2.PlanPlatformResourceURLMap {
+	urls := make(apv1beta2.PlanPlatformResourceURLMap)
+	if len(platforms) > 0 {
+		for platform, url := range platforms {
+			urls[platform] = apv1beta2.PlanResourceURL{URL: url}
+		}
+		return urls
+	}
+
+	for _, arch := range defaultArchitectures {
+		urls["linux-"+arch] = apv1beta2.PlanResourceURL{URL: fmt.Sprintf(urlTemplate, version, arch)}
+	}
+	return urls
+}
+
+// createPlan creates the plan, unless there's already one.
+func createPlan(ctx context.Context, out io.Writer, client apclient.Interface, plan *apv1beta2.Plan) error {
+	plans := client.AutopilotV1beta2().Plans()
+	if _, err := plans.Create(ctx, plan, metav1.CreateOptions{}); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("plan %s already exists, abort it before creating a new one", plan.Name)
+		}
+		return err
+	}
+
+	fmt.Fprintf(out, "Plan %s created, use \"k0s autopilot plan status --watch\" to follow its progress\n", plan.Name)
+	return nil
+}
+
+func planStatusCmd(flags *autopilotFlags) *cobra.Command {
+	var watch bool
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show the progress of the current plan",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := flags.client()
+			if err != nil {
+				return err
+			}
+
+			ctx, out := cmd.Context(), cmd.OutOrStdout()
+			if !watch {
+				plan, err := getPlan(ctx, client)
+				if err != nil {
+					return err
+				}
+				return writeStatus(out, plan)
+			}
+
+			return watchStatus(ctx, out, client)
+		},
+	}
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "keep showing the progress until the plan has finished")
+	return cmd
+}
+
+func getPlan(ctx context.Context, client apclient.Interface) (*apv1beta2.Plan, error) {
+	plan, err := client.AutopilotV1beta2().Plans().Get(ctx, apconst.AutopilotName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, errors.New("there is no plan")
+	}
+	return plan, err
+}
+
+// watchStatus writes the plan status whenever it changes, until the plan has
+// finished.
+func watchStatus(ctx context.Context, out io.Writer, client apclient.Interface) error {
+	var lastResourceVersion string
+	for {
+		plan, err := getPlan(ctx, client)
+		if err != nil {
+			return err
+		}
+
+		if plan.ResourceVersion != lastResourceVersion {
+			lastResourceVersion = plan.ResourceVersion
+			fmt.Fprintln(out)
+			if err := writeStatus(out, plan); err != nil {
+				return err
+			}
+		}
+
+		if isFinished(plan.Status.State) {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(statusPollInterval):
+		}
+	}
+}
+
+// isFinished determines if a plan in the given state won't make any further
+// progress.
+func isFinished(state apv1beta2.PlanStateType) bool {
+	switch state {
+	case "", appc.PlanSchedulable, appc.PlanSchedulableWait:
+		return false
+	}
+	return true
+}
+
+// nodeStatuses are the statuses of the nodes of a role targeted by a command.
+type nodeStatuses struct {
+	role   string
+	status []apv1beta2.PlanCommandTargetStatus
+}
+
+// writeStatus writes the state of the plan and all of its commands and nodes.
+func writeStatus(out io.Writer, plan *apv1beta2.Plan) error {
+	state := plan.Status.State.String()
+	if state == "" {
+		state = "Pending"
+	}
+	fmt.Fprintf(out, "Plan %s (%s): %s\n", plan.Name, plan.Spec.ID, state)
+
+	w := tabwriter.NewWriter(out, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(w, "COMMAND\tNODE\tROLE\tSTATE\tLAST UPDATED")
+	for _, cmd := range plan.Status.Commands {
+		var name string
+		var targets []nodeStatuses
+		switch {
+		case cmd.K0sUpdate != nil:
+			name = "k0supdate"
+			targets = []nodeStatuses{
+				{"controller", cmd.K0sUpdate.Controllers},
+				{"worker", cmd.K0sUpdate.Workers},
+			}
+		case cmd.AirgapUpdate != nil:
+			name = "airgapupdate"
+			targets = []nodeStatuses{{"worker", cmd.AirgapUpdate.Workers}}
+		default:
+			continue
+		}
+
+		command := fmt.Sprintf("%d/%s", cmd.ID, name)
+		for _, target := range targets {
+			nodes := append([]apv1beta2.PlanCommandTargetStatus(nil), target.status...)
+			sort.Slice(nodes, func(i, j int) bool { return nodes[i].Name < nodes[j].Name })
+			for _, node := range nodes {
+				role := target.role
+				if node.Canary {
+					role += " (canary)"
+				}
+				updated := "-"
+				if !node.LastUpdatedTimestamp.IsZero() {
+					updated = node.LastUpdatedTimestamp.UTC().Format(time.RFC3339)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", command, node.Name, role, node.State, updated)
+			}
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, cmd := range plan.Status.Commands {
+		if cmd.Description != "" {
+			fmt.Fprintf(out, "Command %d: %s: %s\n", cmd.ID, cmd.State, cmd.Description)
+		}
+	}
+
+	return nil
+}
+
+func planAbortCmd(flags *autopilotFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "abort",
+		Short: "Abort the current plan",
+		Long: `Abort the current plan by deleting it. No further nodes will be updated, but
+nodes that are already being updated will finish their update.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			client, err := flags.client()
+			if err != nil {
+				return err
+			}
+
+			return abortPlan(cmd.Context(), cmd.OutOrStdout(), client)
+		},
+	}
+}
+
+func abortPlan(ctx context.Context, out io.Writer, client apclient.Interface) error {
+	err := client.AutopilotV1beta2().Plans().Delete(ctx, apconst.AutopilotName, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return errors.New("there is no plan")
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Plan %s aborted\n", apconst.AutopilotName)
+	return nil
+}
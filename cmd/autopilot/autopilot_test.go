@@ -0,0 +1,164 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package autopilot
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	apconst "github.com/k0sproject/k0s/pkg/autopilot/constant"
+	appc "github.com/k0sproject/k0s/pkg/autopilot/controller/plans/core"
+	"github.com/k0sproject/k0s/pkg/client/clientset/fake"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBuildPlan(t *testing.T) {
+	now := time.Unix(1686000000, 0)
+
+	t.Run("defaults", func(t *testing.T) {
+		plan, err := buildPlan(&planOptions{version: "v1.27.2+k0s.0", workerConcurrency: 1}, now)
+		require.NoError(t, err)
+
+		assert.Equal(t, apconst.AutopilotName, plan.Name)
+		assert.Equal(t, "id-1686000000", plan.Spec.ID)
+		assert.Equal(t, "1686000000", plan.Spec.Timestamp)
+		require.Len(t, plan.Spec.Commands, 1)
+
+		update := plan.Spec.Commands[0].K0sUpdate
+		require.NotNil(t, update)
+		assert.Equal(t, "v1.27.2+k0s.0", update.Version)
+		assert.Equal(t,
+			"https://github.com/k0sproject/k0s/releases/download/v1.27.2+k0s.0/k0s-v1.27.2+k0s.0-arm64",
+			update.Platforms["linux-arm64"].URL,
+		)
+		assert.Len(t, update.Platforms, 3)
+		assert.NotNil(t, update.Targets.Controllers.Discovery.Selector)
+		assert.NotNil(t, update.Targets.Workers.Discovery.Selector)
+		assert.Equal(t, 1, update.Targets.Workers.Limits.Concurrent)
+	})
+
+	t.Run("airgap", func(t *testing.T) {
+		plan, err := buildPlan(&planOptions{
+			version:           "v1.27.2+k0s.0",
+			airgapPlatforms:   map[string]string{"linux-amd64": "https://example.com/bundle"},
+			workerSelector:    "env=staging",
+			workerConcurrency: 3,
+			skipControllers:   true,
+			proxyDownloads:    true,
+		}, now)
+		require.NoError(t, err)
+		require.Len(t, plan.Spec.Commands, 2)
+
+		airgap := plan.Spec.Commands[0].AirgapUpdate
+		require.NotNil(t, airgap, "airgap bundles need to be distributed first")
+		assert.Equal(t, apv1beta2.PlanPlatformResourceURLMap{
+			"linux-amd64": {URL: "https://example.com/bundle"},
+		}, airgap.Platforms)
+		assert.Equal(t, "env=staging", airgap.Workers.Discovery.Selector.Labels)
+		assert.True(t, airgap.ProxyDownloads)
+
+		update := plan.Spec.Commands[1].K0sUpdate
+		require.NotNil(t, update)
+		assert.Nil(t, update.Targets.Controllers.Discovery.Selector)
+		assert.Equal(t, "env=staging", update.Targets.Workers.Discovery.Selector.Labels)
+		assert.Equal(t, 3, update.Targets.Workers.Limits.Concurrent)
+		assert.True(t, update.ProxyDownloads)
+	})
+
+	for _, test := range []struct {
+		name string
+		opts planOptions
+	}{
+		{"no_version", planOptions{workerConcurrency: 1}},
+		{"skip_all", planOptions{version: "v1", workerConcurrency: 1, skipControllers: true, skipWorkers: true}},
+		{"no_concurrency", planOptions{version: "v1"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := buildPlan(&test.opts, now)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestCreateAndAbortPlan(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	plan, err := buildPlan(&planOptions{version: "v1.27.2+k0s.0", workerConcurrency: 1}, time.Now())
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	require.NoError(t, createPlan(context.TODO(), &out, client, plan))
+	assert.Contains(t, out.String(), "Plan autopilot created")
+
+	err = createPlan(context.TODO(), &out, client, plan)
+	assert.ErrorContains(t, err, "already exists")
+
+	require.NoError(t, abortPlan(context.TODO(), &out, client))
+	_, err = getPlan(context.TODO(), client)
+	assert.ErrorContains(t, err, "there is no plan")
+
+	assert.ErrorContains(t, abortPlan(context.TODO(), &out, client), "there is no plan")
+}
+
+func TestWriteStatus(t *testing.T) {
+	updated := metav1.NewTime(time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC))
+	plan := &apv1beta2.Plan{
+		ObjectMeta: metav1.ObjectMeta{Name: apconst.AutopilotName},
+		Spec:       apv1beta2.PlanSpec{ID: "id-1"},
+		Status: apv1beta2.PlanStatus{
+			State: appc.PlanSchedulableWait,
+			Commands: []apv1beta2.PlanCommandStatus{{
+				ID:          0,
+				State:       appc.PlanSchedulableWait,
+				Description: "waiting for canaries",
+				K0sUpdate: &apv1beta2.PlanCommandK0sUpdateStatus{
+					Controllers: []apv1beta2.PlanCommandTargetStatus{
+						{Name: "controller0", State: appc.SignalCompleted, LastUpdatedTimestamp: updated},
+					},
+					Workers: []apv1beta2.PlanCommandTargetStatus{
+						{Name: "worker1", State: appc.SignalPending},
+						{Name: "worker0", State: appc.SignalSent, Canary: true, LastUpdatedTimestamp: updated},
+					},
+				},
+			}},
+		},
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, writeStatus(&out, plan))
+
+	assert.Equal(t, `Plan autopilot (id-1): SchedulableWait
+COMMAND		NODE		ROLE		STATE		LAST UPDATED
+0/k0supdate	controller0	controller	SignalCompleted	2023-06-01T12:00:00Z
+0/k0supdate	worker0		worker (canary)	SignalSent	2023-06-01T12:00:00Z
+0/k0supdate	worker1		worker		SignalPending	-
+Command 0: SchedulableWait: waiting for canaries
+`, out.String())
+}
+
+func TestIsFinished(t *testing.T) {
+	assert.False(t, isFinished(""))
+	assert.False(t, isFinished(appc.PlanSchedulable))
+	assert.False(t, isFinished(appc.PlanSchedulableWait))
+	assert.True(t, isFinished(appc.PlanCompleted))
+	assert.True(t, isFinished(appc.PlanApplyFailed))
+}
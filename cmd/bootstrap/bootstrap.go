@@ -0,0 +1,158 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package bootstrap
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/pkg/config"
+	"github.com/k0sproject/k0s/pkg/constant"
+	"github.com/k0sproject/k0s/pkg/install"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+func NewBootstrapCmd() *cobra.Command {
+	var (
+		force   bool
+		noStart bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "bootstrap [flags] <file>",
+		Short: "Set up k0s on a brand-new system from a machine bootstrap document. Must be run as root (or with sudo)",
+		Example: `The machine bootstrap document is a JSON file, use '-' to read it from stdin.
+It writes the given files, the join token and the k0s config, installs the
+k0s service for the given role and starts it, in that order.
+
+{
+  "role": "controller+worker",
+  "token": "H4sIAAAAAAAC/...",
+  "config": {"spec": {"api": {"sans": ["192.168.1.10"]}}},
+  "files": [{"path": "/etc/hosts.d/k0s", "content": "...", "permissions": "0600"}],
+  "args": ["--labels=region=eu"],
+  "env": ["HTTPS_PROXY=http://proxy.example.com:3128"]
+}`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if os.Geteuid() != 0 {
+				return fmt.Errorf("this command must be run as root")
+			}
+
+			var data []byte
+			var err error
+			if args[0] == "-" {
+				data, err = io.ReadAll(cmd.InOrStdin())
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return err
+			}
+
+			b, err := install.ParseMachineBootstrap(data)
+			if err != nil {
+				return err
+			}
+			cmd.SilenceUsage = true
+
+			return bootstrap(b, force, noStart)
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "force init script creation")
+	cmd.Flags().BoolVar(&noStart, "no-start", false, "don't start the k0s service after installing it")
+	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
+	return cmd
+}
+
+func bootstrap(b *install.MachineBootstrap, force, noStart bool) error {
+	for _, f := range b.Files {
+		content, mode, err := f.Decode()
+		if err != nil {
+			return err
+		}
+		if err := writeFile(f.Path, content, mode); err != nil {
+			return err
+		}
+	}
+
+	configPath := constant.K0sConfigPathDefault
+	tokenPath := filepath.Join(filepath.Dir(configPath), "join-token")
+
+	if b.Token != "" {
+		if err := writeFile(tokenPath, []byte(b.Token), 0600); err != nil {
+			return err
+		}
+	}
+
+	configData, clusterConfig, err := b.ClusterConfig()
+	if err != nil {
+		return err
+	}
+	if configData != nil {
+		if err := writeFile(configPath, configData, 0600); err != nil {
+			return err
+		}
+	}
+
+	serviceArgs := b.ServiceArgs(configPath, tokenPath)
+	if config.DataDir != "" {
+		dataDir, err := filepath.Abs(config.DataDir)
+		if err != nil {
+			return err
+		}
+		serviceArgs = append(serviceArgs, "--data-dir="+dataDir)
+	}
+
+	if b.IsController() {
+		if err := install.CreateControllerUsers(clusterConfig, constant.GetConfig(config.DataDir)); err != nil {
+			return fmt.Errorf("failed to create controller users: %w", err)
+		}
+	}
+
+	if err := install.EnsureService(serviceArgs, b.Env, force); err != nil {
+		return fmt.Errorf("failed to install k0s service: %w", err)
+	}
+
+	if noStart {
+		return nil
+	}
+
+	svc, err := install.InstalledService()
+	if err != nil {
+		return err
+	}
+	logrus.Info("Starting k0s service")
+	return svc.Start()
+}
+
+func writeFile(path string, content []byte, mode os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	logrus.Infof("Writing %s", path)
+	if err := file.WriteContentAtomically(path, content, mode); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
@@ -1,5 +1,5 @@
 /*
-Copyright 2021 k0s authors
+Copyright 2023 k0s authors
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -14,17 +14,19 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package cleanup
+package ca
 
-type bridge struct {
-}
+import (
+	"github.com/spf13/cobra"
+)
 
-// Name returns the name of the step
-func (b *bridge) Name() string {
-	return "kube-bridge leftovers cleanup step"
-}
+func NewCACmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ca",
+		Short: "Manage the cluster CA",
+	}
 
-// Run removes found kube-bridge leftovers
-func (b *bridge) Run() error {
-	return nil
+	cmd.SilenceUsage = true
+	cmd.AddCommand(caRotateCmd())
+	return cmd
 }
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ca
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/config"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spf13/cobra"
+)
+
+func caRotateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate",
+		Short: "Rotate the cluster CA in stages",
+		Long: `Rotate the cluster CA in stages. Each stage has to be applied on all nodes
+before moving on to the next one:
+
+  1. trust:    Distribute the new CA and trust it along with the current one.
+  2. reissue:  Issue all certificates with the new CA.
+  3. finalize: Stop trusting the previous CA.
+
+Controllers need to be restarted after each stage. Workers apply the stages
+automatically.`,
+	}
+
+	cmd.AddCommand(caRotateTrustCmd())
+	cmd.AddCommand(caRotateAdvanceCmd(certificate.CARotationReissue, "Issue all certificates with the new CA"))
+	cmd.AddCommand(caRotateAdvanceCmd(certificate.CARotationFinalize, "Stop trusting the previous CA"))
+	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
+	return cmd
+}
+
+func caRotateTrustCmd() *cobra.Command {
+	var certFile, keyFile string
+
+	cmd := &cobra.Command{
+		Use:   string(certificate.CARotationTrust),
+		Short: "Start a CA rotation by distributing the new CA",
+		Example: `k0s ca rotate trust
+k0s ca rotate trust --cert new-ca.crt --key new-ca.key`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if (certFile == "") != (keyFile == "") {
+				return errors.New("--cert and --key need to be specified together")
+			}
+
+			c := config.GetCmdOpts()
+			currentCACert, err := os.ReadFile(filepath.Join(c.K0sVars.CertRootDir, "ca.crt"))
+			if err != nil {
+				return err
+			}
+
+			var caCert, caKey []byte
+			if certFile == "" {
+				certManager := certificate.NewManager(c.K0sVars, c.NodeConfig.Spec.Certificates)
+				if caCert, caKey, err = certManager.GenerateCA("kubernetes-ca"); err != nil {
+					return fmt.Errorf("failed to generate new CA: %w", err)
+				}
+			} else {
+				if caCert, err = os.ReadFile(certFile); err != nil {
+					return err
+				}
+				if caKey, err = os.ReadFile(keyFile); err != nil {
+					return err
+				}
+			}
+
+			return updateCARotation(cmd, func(current *certificate.CARotation) (*certificate.CARotation, error) {
+				return certificate.StartCARotation(current, currentCACert, caCert, caKey)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&certFile, "cert", "", "PEM encoded certificate of the new CA (a new CA is generated if empty)")
+	cmd.Flags().StringVar(&keyFile, "key", "", "PEM encoded key of the new CA")
+	return cmd
+}
+
+func caRotateAdvanceCmd(phase certificate.CARotationPhase, short string) *cobra.Command {
+	return &cobra.Command{
+		Use:   string(phase),
+		Short: short,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			return updateCARotation(cmd, func(current *certificate.CARotation) (*certificate.CARotation, error) {
+				if current == nil {
+					return nil, errors.New("no CA rotation in progress")
+				}
+				return current, current.Advance(phase)
+			})
+		},
+	}
+}
+
+// updateCARotation stores the rotation state returned by update in the
+// cluster. The current state is nil if there's none.
+func updateCARotation(cmd *cobra.Command, update func(*certificate.CARotation) (*certificate.CARotation, error)) error {
+	c := config.GetCmdOpts()
+	client, err := kubeutil.NewClientFromFile(c.K0sVars.AdminKubeConfigPath)
+	if err != nil {
+		return err
+	}
+
+	secrets := client.CoreV1().Secrets(metav1.NamespaceSystem)
+	existing, err := secrets.Get(cmd.Context(), certificate.CARotationSecretName, metav1.GetOptions{})
+	var current *certificate.CARotation
+	if err == nil {
+		if current, err = certificate.CARotationFromSecret(existing); err != nil {
+			return err
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	rotation, err := update(current)
+	if err != nil {
+		return err
+	}
+	secret := rotation.Secret()
+	if current != nil {
+		secret.ResourceVersion = existing.ResourceVersion
+		_, err = secrets.Update(cmd.Context(), secret, metav1.UpdateOptions{})
+	} else {
+		_, err = secrets.Create(cmd.Context(), secret, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return fmt.Errorf("failed to store CA rotation state: %w", err)
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "CA rotation is now in phase %q, restart all controllers to apply it\n", rotation.Phase)
+	return nil
+}
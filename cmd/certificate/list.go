@@ -0,0 +1,127 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/config"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+func certificateListCmd() *cobra.Command {
+	var (
+		output     string
+		failWithin time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the certificates managed by k0s along with their expiry",
+		Example: `k0s certificate list
+k0s certificate list --output json
+k0s certificate list --fail-within 720h  # exits with an error if a certificate expires within 30 days`,
+		Args: cobra.NoArgs,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			switch output {
+			case "", "json":
+				return nil
+			default:
+				return fmt.Errorf("unsupported output format: %q", output)
+			}
+		},
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c := config.GetCmdOpts()
+			now := time.Now()
+			certs, err := certificate.ListCertificates(now,
+				c.K0sVars.CertRootDir,
+				filepath.Join(c.K0sVars.DataDir, "kubelet", "pki"),
+			)
+			if err != nil {
+				return err
+			}
+
+			if output == "json" {
+				if certs == nil {
+					certs = []certificate.Info{}
+				}
+				enc := json.NewEncoder(cmd.OutOrStdout())
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(certs); err != nil {
+					return err
+				}
+			} else {
+				writeCertificateTable(cmd.OutOrStdout(), certs)
+			}
+
+			if failWithin > 0 {
+				var expiring []string
+				for _, cert := range certs {
+					if cert.ExpiresWithin(failWithin, now) {
+						expiring = append(expiring, cert.Path)
+					}
+				}
+				if len(expiring) > 0 {
+					return fmt.Errorf("%d certificate(s) expire within %s: %s", len(expiring), failWithin, strings.Join(expiring, ", "))
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "Output format. Must be empty or json")
+	cmd.Flags().DurationVar(&failWithin, "fail-within", 0, "Exit with an error if a certificate expires within the given duration (disabled if zero)")
+	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
+	return cmd
+}
+
+func writeCertificateTable(w io.Writer, certs []certificate.Info) {
+	table := tablewriter.NewWriter(w)
+	table.SetHeader([]string{"Path", "Subject", "Issuer", "SANs", "Not after", "Days remaining"})
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t") // pad with tabs
+	table.SetNoWhiteSpace(true)
+	for _, cert := range certs {
+		table.Append([]string{
+			cert.Path,
+			cert.Subject,
+			cert.Issuer,
+			strings.Join(cert.SANs, ","),
+			cert.NotAfter.UTC().Format(time.RFC3339),
+			strconv.Itoa(cert.DaysRemaining),
+		})
+	}
+	table.Render()
+}
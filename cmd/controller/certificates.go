@@ -23,16 +23,18 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 
-	"github.com/k0sproject/k0s/internal/pkg/file"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/constant"
 
-	"k8s.io/client-go/tools/clientcmd"
-	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -51,13 +53,29 @@ func (c *Certificates) Init(ctx context.Context) error {
 	caCertPath := filepath.Join(c.K0sVars.CertRootDir, "ca.crt")
 	caCertKey := filepath.Join(c.K0sVars.CertRootDir, "ca.key")
 
+	if certs := c.ClusterSpec.Certificates; certs != nil && certs.CA != nil {
+		if err := c.CertManager.ImportCA("ca", certs.CA); err != nil {
+			return err
+		}
+	}
 	if err := c.CertManager.EnsureCA("ca", "kubernetes-ca"); err != nil {
 		return err
 	}
 
-	// We need CA cert loaded to generate client configs
+	// If the API server's certificate is issued by a provider, its CA needs
+	// to be trusted as well.
+	provideAPIServerCert := c.ClusterSpec.Certificates.GetProvider().Issues(v1beta1.CertificateProviderAPIServer)
+	if err := c.CertManager.ImportProviderCA(ctx, "ca", provideAPIServerCert); err != nil {
+		return err
+	}
+	if _, err := c.CertManager.WriteCABundle("ca"); err != nil {
+		return fmt.Errorf("failed to write CA bundle: %w", err)
+	}
+
+	// We need CA cert loaded to generate client configs. They trust the whole
+	// bundle, so that they keep working while the CA is rotated.
 	logrus.Debugf("CA key and cert exists, loading")
-	cert, err := os.ReadFile(caCertPath)
+	cert, err := certificate.ReadCABundle(c.K0sVars.CertRootDir)
 	if err != nil {
 		return fmt.Errorf("failed to read ca cert: %w", err)
 	}
@@ -85,19 +103,7 @@ func (c *Certificates) Init(ctx context.Context) error {
 
 	eg.Go(func() error {
 		// admin cert & kubeconfig
-		adminReq := certificate.Request{
-			Name:   "admin",
-			CN:     "kubernetes-admin",
-			O:      "system:masters",
-			CACert: caCertPath,
-			CAKey:  caCertKey,
-		}
-		adminCert, err := c.CertManager.EnsureCertificate(adminReq, "root")
-		if err != nil {
-			return err
-		}
-
-		if err := kubeConfig(c.K0sVars.AdminKubeConfigPath, kubeConfigAPIUrl, c.CACert, adminCert.Cert, adminCert.Key, "root"); err != nil {
+		if err := c.CertManager.WriteAdminKubeconfig(c.ClusterSpec.API.Port); err != nil {
 			return err
 		}
 
@@ -117,7 +123,7 @@ func (c *Certificates) Init(ctx context.Context) error {
 		if err != nil {
 			return err
 		}
-		return kubeConfig(c.K0sVars.KonnectivityKubeConfigPath, kubeConfigAPIUrl, c.CACert, konnectivityCert.Cert, konnectivityCert.Key, constant.KonnectivityServerUser)
+		return certificate.WriteKubeconfig(c.K0sVars.KonnectivityKubeConfigPath, kubeConfigAPIUrl, c.CACert, konnectivityCert.Cert, konnectivityCert.Key, constant.KonnectivityServerUser)
 	})
 
 	eg.Go(func() error {
@@ -133,7 +139,7 @@ func (c *Certificates) Init(ctx context.Context) error {
 			return err
 		}
 
-		return kubeConfig(filepath.Join(c.K0sVars.CertRootDir, "ccm.conf"), kubeConfigAPIUrl, c.CACert, ccmCert.Cert, ccmCert.Key, constant.ApiserverUser)
+		return certificate.WriteKubeconfig(filepath.Join(c.K0sVars.CertRootDir, "ccm.conf"), kubeConfigAPIUrl, c.CACert, ccmCert.Cert, ccmCert.Key, constant.ApiserverUser)
 	})
 
 	eg.Go(func() error {
@@ -149,7 +155,7 @@ func (c *Certificates) Init(ctx context.Context) error {
 			return err
 		}
 
-		return kubeConfig(filepath.Join(c.K0sVars.CertRootDir, "scheduler.conf"), kubeConfigAPIUrl, c.CACert, schedulerCert.Cert, schedulerCert.Key, constant.SchedulerUser)
+		return certificate.WriteKubeconfig(filepath.Join(c.K0sVars.CertRootDir, "scheduler.conf"), kubeConfigAPIUrl, c.CACert, schedulerCert.Cert, schedulerCert.Key, constant.SchedulerUser)
 	})
 
 	eg.Go(func() error {
@@ -195,12 +201,28 @@ func (c *Certificates) Init(ctx context.Context) error {
 			CACert:    caCertPath,
 			CAKey:     caCertKey,
 			Hostnames: hostnames,
+			Provided:  provideAPIServerCert,
 		}
 		_, err = c.CertManager.EnsureCertificate(serverReq, constant.ApiserverUser)
 
 		return err
 	})
 
+	eg.Go(func() error {
+		// konnectivity-server is reachable via the same addresses as the API
+		// server, plus the configured ones
+		konnectivityServerReq := certificate.Request{
+			Name:      "konnectivity-server",
+			CN:        "konnectivity-server",
+			O:         "kubernetes",
+			CACert:    caCertPath,
+			CAKey:     caCertKey,
+			Hostnames: append(slices.Clone(hostnames), c.ClusterSpec.Certificates.GetSANs().Konnectivity...),
+		}
+		_, err := c.CertManager.EnsureCertificate(konnectivityServerReq, constant.KonnectivityServerUser)
+		return err
+	})
+
 	eg.Go(func() error {
 		apiReq := certificate.Request{
 			Name:      "k0s-api",
@@ -215,6 +237,20 @@ func (c *Certificates) Init(ctx context.Context) error {
 		return err
 	})
 
+	eg.Go(func() error {
+		// The ClusterConfig webhook is only called by the local API server.
+		webhookReq := certificate.Request{
+			Name:      "k0s-webhook",
+			CN:        "k0s-webhook",
+			O:         "kubernetes",
+			CACert:    caCertPath,
+			CAKey:     caCertKey,
+			Hostnames: []string{"localhost", "127.0.0.1"},
+		}
+		_, err := c.CertManager.EnsureCertificate(webhookReq, "root")
+		return err
+	})
+
 	return eg.Wait()
 }
 
@@ -246,37 +282,62 @@ func detectLocalIPs(ctx context.Context) ([]string, error) {
 	return localIPs, nil
 }
 
-func kubeConfig(dest, url, caCert, clientCert, clientKey, owner string) error {
-	// We always overwrite the kubeconfigs as the certs might be regenerated at startup
-	const (
-		clusterName = "local"
-		contextName = "Default"
-		userName    = "user"
-	)
-
-	kubeconfig, err := clientcmd.Write(clientcmdapi.Config{
-		Clusters: map[string]*clientcmdapi.Cluster{clusterName: {
-			Server:                   url,
-			CertificateAuthorityData: []byte(caCert),
-		}},
-		Contexts: map[string]*clientcmdapi.Context{contextName: {
-			Cluster:  clusterName,
-			AuthInfo: userName,
-		}},
-		CurrentContext: contextName,
-		AuthInfos: map[string]*clientcmdapi.AuthInfo{userName: {
-			ClientCertificateData: []byte(clientCert),
-			ClientKeyData:         []byte(clientKey),
-		}},
-	})
-	if err != nil {
-		return err
+// adminCredentialValidity is the lifetime of the admin credentials issued via
+// the status socket, unless the admin kubeconfig validity is shorter
+const adminCredentialValidity = 1 * time.Hour
+
+// adminCredentialRenewBefore is the remaining lifetime below which cached
+// admin credentials are no longer handed out, but replaced by new ones.
+const adminCredentialRenewBefore = 10 * time.Minute
+
+// adminCredentialIssuer issues short-lived admin client certificates, signed
+// by the cluster CA
+type adminCredentialIssuer struct {
+	certManager certificate.Manager
+	k0sVars     constant.CfgVars
+
+	mu     sync.Mutex
+	cached *clientauthv1.ExecCredentialStatus
+}
+
+// IssueAdminCredential implements the status component's credential issuer.
+// Credentials are cached until shortly before they expire.
+func (a *adminCredentialIssuer) IssueAdminCredential() (*clientauthv1.ExecCredentialStatus, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	validity, renewBefore := adminCredentialValidity, adminCredentialRenewBefore
+	if v := a.certManager.AdminKubeconfigValidity; v > 0 && v < validity {
+		validity = v
+		if renewBefore > v/3 {
+			renewBefore = v / 3
+		}
 	}
 
-	err = file.WriteContentAtomically(dest, kubeconfig, constant.CertSecureMode)
+	if a.cached != nil && time.Until(a.cached.ExpirationTimestamp.Time) > renewBefore {
+		return a.cached.DeepCopy(), nil
+	}
+
+	adminReq := certificate.Request{
+		Name:     "admin",
+		CN:       "kubernetes-admin",
+		O:        "system:masters",
+		CACert:   filepath.Join(a.k0sVars.CertRootDir, "ca.crt"),
+		CAKey:    filepath.Join(a.k0sVars.CertRootDir, "ca.key"),
+		Validity: validity,
+	}
+	// The expiration is reported slightly early, so that clients will
+	// fetch new credentials before the current ones become invalid.
+	expiration := metav1.NewTime(time.Now().Add(validity - time.Minute))
+	adminCert, err := a.certManager.IssueCertificate(adminReq)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	return file.Chown(dest, owner, constant.CertSecureMode)
+	a.cached = &clientauthv1.ExecCredentialStatus{
+		ExpirationTimestamp:   &expiration,
+		ClientCertificateData: adminCert.Cert,
+		ClientKeyData:         adminCert.Key,
+	}
+	return a.cached.DeepCopy(), nil
 }
@@ -18,6 +18,7 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/fs"
 	"net"
@@ -35,22 +36,26 @@ import (
 	"github.com/k0sproject/k0s/internal/pkg/sysinfo"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/applier"
+	"github.com/k0sproject/k0s/pkg/autopilot/updater"
 	"github.com/k0sproject/k0s/pkg/build"
 	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/component/controller"
 	"github.com/k0sproject/k0s/pkg/component/controller/clusterconfig"
 	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
 	"github.com/k0sproject/k0s/pkg/component/controller/workerconfig"
+	"github.com/k0sproject/k0s/pkg/component/diskmonitor"
 	"github.com/k0sproject/k0s/pkg/component/manager"
 	"github.com/k0sproject/k0s/pkg/component/prober"
 	"github.com/k0sproject/k0s/pkg/component/status"
 	"github.com/k0sproject/k0s/pkg/component/worker"
 	"github.com/k0sproject/k0s/pkg/config"
 	"github.com/k0sproject/k0s/pkg/constant"
+	"github.com/k0sproject/k0s/pkg/etcd"
 	"github.com/k0sproject/k0s/pkg/kubernetes"
 	"github.com/k0sproject/k0s/pkg/performance"
 	"github.com/k0sproject/k0s/pkg/telemetry"
 	"github.com/k0sproject/k0s/pkg/token"
+	"github.com/k0sproject/k0s/pkg/tracing"
 
 	"github.com/avast/retry-go"
 	"github.com/sirupsen/logrus"
@@ -60,8 +65,22 @@ import (
 
 type command config.CLIOptions
 
+// leasedComponents are the components that get individual leases if
+// per-component leases are enabled, along with the applier stacks that belong
+// to them.
+var leasedComponents = []struct {
+	name   string
+	stacks []string
+}{
+	{"coredns", []string{"coredns"}},
+	{"network-provider", []string{"calico", "calico_init", "kuberouter"}},
+	{"helm", []string{"helm"}},
+	{"metrics", []string{"metrics", "metricserver"}},
+}
+
 func NewControllerCmd() *cobra.Command {
 	var ignorePreFlightChecks bool
+	var preFlightPolicies map[string]string
 
 	cmd := &cobra.Command{
 		Use:     "controller [join-token]",
@@ -88,9 +107,15 @@ func NewControllerCmd() *cobra.Command {
 			if len(c.TokenArg) > 0 && len(c.TokenFile) > 0 {
 				return fmt.Errorf("you can only pass one token argument either as a CLI argument 'k0s controller [join-token]' or as a flag 'k0s controller --token-file [path]'")
 			}
+			if c.TokenURL != "" && (len(c.TokenArg) > 0 || len(c.TokenFile) > 0) {
+				return fmt.Errorf("--token-url can't be combined with a join-token given as CLI argument or via --token-file")
+			}
 			if err := c.ControllerOptions.Normalize(); err != nil {
 				return err
 			}
+			if c.Rootless {
+				return errors.New("rootless mode is only supported by 'k0s worker', not by controllers, not even with --enable-worker or --single")
+			}
 			if len(c.TokenFile) > 0 {
 				bytes, err := os.ReadFile(c.TokenFile)
 				if err != nil {
@@ -99,13 +124,20 @@ func NewControllerCmd() *cobra.Command {
 				c.TokenArg = string(bytes)
 			}
 			c.Logging = stringmap.Merge(c.CmdLogLevels, c.DefaultLogLevels)
+			if c.SingleNode || c.EnableWorker {
+				k0slog.SetNodeRole("controller+worker")
+			} else {
+				k0slog.SetNodeRole("controller")
+			}
 			cmd.SilenceUsage = true
 
-			if err := (&sysinfo.K0sSysinfoSpec{
-				ControllerRoleEnabled: true,
-				WorkerRoleEnabled:     c.SingleNode || c.EnableWorker,
-				DataDir:               c.K0sVars.DataDir,
-			}).RunPreFlightChecks(ignorePreFlightChecks); !ignorePreFlightChecks && err != nil {
+			preFlightChecks := c.preFlightChecks()
+			policies, err := sysinfo.ParsePreFlightPolicies(preFlightPolicies)
+			if err != nil {
+				return err
+			}
+			preFlightChecks.Policies = policies
+			if err := preFlightChecks.RunPreFlightChecks(ignorePreFlightChecks); err != nil {
 				return err
 			}
 
@@ -117,15 +149,99 @@ func NewControllerCmd() *cobra.Command {
 
 	// append flags
 	cmd.Flags().BoolVar(&ignorePreFlightChecks, "ignore-pre-flight-checks", false, "continue even if pre-flight checks fail")
+	cmd.Flags().StringToStringVar(&preFlightPolicies, "pre-flight-check", nil, "policy for individual pre-flight checks in the form of path=enforce|warn|ignore, overrides --ignore-pre-flight-checks (can be repeated)")
 	cmd.Flags().AddFlagSet(config.GetPersistentFlagSet())
 	cmd.PersistentFlags().AddFlagSet(config.GetControllerFlags())
 	cmd.PersistentFlags().AddFlagSet(config.GetWorkerFlags())
+	// Rootless mode is only supported by 'k0s worker', so don't advertise the
+	// worker flag on controllers. It's still rejected explicitly when given.
+	if err := cmd.PersistentFlags().MarkHidden("rootless"); err != nil {
+		panic(err)
+	}
 	return cmd
 }
 
+// preFlightChecks returns the pre-flight checks for this controller. Besides
+// the host requirements, they verify that the ports and sockets that the
+// controller components listen on are free, and, when joining, that the join
+// address is reachable.
+func (c *command) preFlightChecks() *sysinfo.K0sSysinfoSpec {
+	spec := &sysinfo.K0sSysinfoSpec{
+		ControllerRoleEnabled: true,
+		WorkerRoleEnabled:     c.SingleNode || c.EnableWorker,
+		DataDir:               c.K0sVars.DataDir,
+		Ports: map[int]string{
+			c.NodeConfig.Spec.API.Port: "kube-apiserver",
+		},
+	}
+
+	if !c.SingleNode && !slices.Contains(c.DisableComponents, constant.ControlAPIComponentName) {
+		spec.Ports[c.NodeConfig.Spec.API.K0sAPIPort] = "k0s API"
+	}
+	if c.clusterConfigWebhookEnabled() {
+		spec.Ports[constant.ClusterConfigWebhookPort] = "ClusterConfig webhook"
+	}
+	if c.konnectivityEnabled() && c.NodeConfig.Spec.Konnectivity != nil {
+		spec.Ports[int(c.NodeConfig.Spec.Konnectivity.AgentPort)] = "konnectivity-server"
+	}
+	switch c.NodeConfig.Spec.Storage.Type {
+	case v1beta1.EtcdStorageType:
+		if !c.NodeConfig.Spec.Storage.Etcd.IsExternalClusterUsed() {
+			spec.Ports[2379] = "etcd"
+			spec.Ports[2380] = "etcd"
+		}
+	case v1beta1.KineStorageType:
+		spec.Sockets = map[string]string{c.K0sVars.KineSocketPath: "kine"}
+	}
+	if spec.WorkerRoleEnabled {
+		spec.Ports[10250] = "kubelet"
+	}
+
+	if c.TokenArg != "" && c.needToJoin() {
+		// Invalid tokens are rejected when joining.
+		if address, err := token.JoinAddress(c.TokenArg); err == nil {
+			spec.Endpoints = map[string]string{address: "Join address"}
+		}
+	}
+
+	return spec
+}
+
+func (c *command) konnectivityEnabled() bool {
+	return !c.SingleNode && !slices.Contains(c.DisableComponents, constant.KonnectivityServerComponentName)
+}
+
+// clusterConfigWebhookEnabled returns whether the ClusterConfig webhook is
+// served. It's only needed if the configuration is stored in the cluster.
+func (c *command) clusterConfigWebhookEnabled() bool {
+	return c.EnableDynamicConfig && !slices.Contains(c.DisableComponents, constant.ClusterConfigWebhookComponentName)
+}
+
 func (c *command) start(ctx context.Context) error {
+	timeoutPolicy, err := manager.ParseTimeoutPolicy(c.ComponentTimeoutPolicy)
+	if err != nil {
+		return err
+	}
 	c.NodeComponents = manager.New(prober.DefaultProber)
 	c.ClusterComponents = manager.New(prober.DefaultProber)
+	for _, m := range []*manager.Manager{c.NodeComponents, c.ClusterComponents} {
+		m.Timeout, m.TimeoutPolicy = c.ComponentTimeout, timeoutPolicy
+		m.StopTimeout = c.ComponentStopTimeout
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, c.TracingEndpoint, "controller")
+	if err != nil {
+		return fmt.Errorf("failed to set up tracing: %w", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logrus.WithError(err).Warn("Failed to flush traces")
+		}
+	}()
+	ctx, startupSpan := tracing.Tracer().Start(ctx, "controller startup")
+	defer startupSpan.End()
 
 	perfTimer := performance.NewTimer("controller-start").Buffer().Start()
 
@@ -143,17 +259,39 @@ func (c *command) start(ctx context.Context) error {
 
 	// initialize runtime config
 	loadingRules := config.ClientConfigLoadingRules{Nodeconfig: true}
-	if err := loadingRules.InitRuntimeConfig(c.K0sVars); err != nil {
+	if err := loadingRules.InitRuntimeConfig(ctx, c.K0sVars); err != nil {
 		return fmt.Errorf("failed to initialize k0s runtime config: %s", err.Error())
 	}
+	if c.NodeConfig.Spec.API.HasAutoAddress() {
+		// pick up the discovered addresses from the runtime config
+		nodeConfig, err := loadingRules.ParseRuntimeConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load k0s runtime config: %w", err)
+		}
+		c.NodeConfig = nodeConfig
+	}
 
 	// from now on, we only refer to the runtime config
 	c.CfgFile = loadingRules.RuntimeConfigPath
 
-	certificateManager := certificate.Manager{K0sVars: c.K0sVars}
+	prober.DefaultProber.Configure(c.NodeConfig.Spec.Prober)
+
+	certificateManager := certificate.NewManager(c.K0sVars, c.NodeConfig.Spec.Certificates)
 
 	var joinClient *token.JoinClient
-	var err error
+
+	certificateManager.Provider, err = certificate.NewProvider(c.NodeConfig.Spec.Certificates.GetProvider())
+	if err != nil {
+		return fmt.Errorf("failed to create certificate provider: %w", err)
+	}
+
+	if c.TokenArg == "" && c.TokenURL != "" && c.needToJoin() {
+		logrus.Info("Fetching join token from ", c.TokenURL)
+		c.TokenArg, err = token.FetchJoinToken(ctx, c.TokenURL, c.TokenURLAuthHeaderFile)
+		if err != nil {
+			return err
+		}
+	}
 
 	if c.TokenArg != "" && c.needToJoin() {
 		joinClient, err = joinController(ctx, c.TokenArg, c.K0sVars.CertRootDir)
@@ -185,6 +323,10 @@ func (c *command) start(ctx context.Context) error {
 			JoinClient:  joinClient,
 			K0sVars:     c.K0sVars,
 			LogLevel:    c.Logging["etcd"],
+			PeerSANs:    c.NodeConfig.Spec.Certificates.GetSANs().EtcdPeer,
+			ServerSANs:  c.NodeConfig.Spec.Certificates.GetSANs().EtcdServer,
+
+			UseCertificateProvider: c.NodeConfig.Spec.Certificates.GetProvider().Issues(v1beta1.CertificateProviderEtcd),
 		}
 	default:
 		return fmt.Errorf("invalid storage type: %s", c.NodeConfig.Spec.Storage.Type)
@@ -192,46 +334,119 @@ func (c *command) start(ctx context.Context) error {
 	logrus.Infof("using storage backend %s", c.NodeConfig.Spec.Storage.Type)
 	c.NodeComponents.Add(ctx, storageBackend)
 
+	diskMonitor := &diskmonitor.DiskMonitor{
+		Paths:          []diskmonitor.Path{{Name: "data", Path: c.K0sVars.DataDir}},
+		MinFreePercent: c.MinFreeDiskPercent,
+		Reclaim:        c.ReclaimDiskSpace,
+	}
+	if c.NodeConfig.Spec.Storage.Type == v1beta1.EtcdStorageType && !c.NodeConfig.Spec.Storage.Etcd.IsExternalClusterUsed() {
+		diskMonitor.Paths = append(diskMonitor.Paths, diskmonitor.Path{
+			Name: "etcd",
+			Path: c.K0sVars.EtcdDataDir,
+			Reclaim: func(ctx context.Context) error {
+				client, err := etcd.NewClient(c.K0sVars.CertRootDir, c.K0sVars.EtcdCertDir, c.NodeConfig.Spec.Storage.Etcd)
+				if err != nil {
+					return err
+				}
+				defer client.Close()
+				return client.CompactAndDefragment(ctx)
+			},
+		})
+	}
+	c.NodeComponents.Add(ctx, diskMonitor)
+
+	if c.UpdateCheckInterval > 0 {
+		var updateClient updater.Client
+		if c.UpdateCheckIndex != "" {
+			updateClient = updater.NewIndexClient(c.UpdateCheckIndex)
+		} else if updateClient, err = updater.NewClient(c.UpdateCheckServer, ""); err != nil {
+			return fmt.Errorf("invalid update server: %w", err)
+		}
+		c.NodeComponents.Add(ctx, &controller.UpdateNotifier{
+			Client:         updateClient,
+			Channel:        c.UpdateCheckChannel,
+			CurrentVersion: build.Version,
+			Interval:       c.UpdateCheckInterval,
+		})
+	}
+
 	// common factory to get the admin kube client that's needed in many components
 	adminClientFactory := kubernetes.NewAdminClientFactory(c.K0sVars)
-	enableKonnectivity := !c.SingleNode && !slices.Contains(c.DisableComponents, constant.KonnectivityServerComponentName)
+	enableKonnectivity := c.konnectivityEnabled()
 	disableEndpointReconciler := !slices.Contains(c.DisableComponents, constant.APIEndpointReconcilerComponentName) &&
 		(c.NodeConfig.Spec.API.ExternalAddress != "" || c.NodeConfig.Spec.API.TunneledNetworkingMode)
 
-	c.NodeComponents.Add(ctx, &controller.APIServer{
+	apiServer := &controller.APIServer{
 		ClusterConfig:             c.NodeConfig,
 		K0sVars:                   c.K0sVars,
 		LogLevel:                  c.Logging["kube-apiserver"],
 		Storage:                   storageBackend,
 		EnableKonnectivity:        enableKonnectivity,
 		DisableEndpointReconciler: disableEndpointReconciler,
-	})
+	}
+	c.NodeComponents.Add(ctx, apiServer)
+	c.NodeComponents.DependsOn(apiServer, storageBackend)
 
+	var leaseCounter *controller.K0sControllersLeaseCounter
 	if !c.SingleNode {
-		c.NodeComponents.Add(ctx, &controller.K0sControllersLeaseCounter{
+		leaseCounter = &controller.K0sControllersLeaseCounter{
 			ClusterConfig:     c.NodeConfig,
 			KubeClientFactory: adminClientFactory,
-		})
+			K0sVars:           c.K0sVars,
+			Version:           build.Version,
+		}
+		c.NodeComponents.Add(ctx, leaseCounter)
 	}
 
 	var leaderElector interface {
 		leaderelector.Interface
 		manager.Component
 	}
+	var leasePool *leaderelector.LeasePool
 
 	// One leader elector per controller
 	if !c.SingleNode {
-		leaderElector = leaderelector.NewLeasePool(adminClientFactory)
+		leasePool = leaderelector.NewLeasePool(adminClientFactory, leaderelector.LeaseName, c.NodeConfig.Spec.LeaderElection)
+		leaderElector = leasePool
+		leaseCounter.LeaderElectors = append(leaseCounter.LeaderElectors, leasePool)
 	} else {
 		leaderElector = &leaderelector.Dummy{Leader: true}
 	}
 	c.NodeComponents.Add(ctx, leaderElector)
 
-	c.NodeComponents.Add(ctx, &applier.Manager{
-		K0sVars:           c.K0sVars,
-		KubeClientFactory: adminClientFactory,
-		LeaderElector:     leaderElector,
-	})
+	// With per-component leases, the components are reconciled by the
+	// controllers holding their leases, instead of all by the leader.
+	componentLeaderElectors := make(map[string]leaderelector.Interface)
+	stackLeaderElectors := make(map[string]leaderelector.Interface)
+	if leasePool != nil && c.NodeConfig.Spec.LeaderElection.ComponentLeases {
+		for _, component := range leasedComponents {
+			componentLeasePool := leaderelector.NewLeasePool(adminClientFactory, leaderelector.ComponentLeaseName(component.name), c.NodeConfig.Spec.LeaderElection)
+			c.NodeComponents.Add(ctx, componentLeasePool)
+			leaseCounter.LeaderElectors = append(leaseCounter.LeaderElectors, componentLeasePool)
+			componentLeaderElectors[component.name] = componentLeasePool
+			for _, stack := range component.stacks {
+				stackLeaderElectors[stack] = componentLeasePool
+			}
+		}
+	}
+	componentLeaderElector := func(component string) leaderelector.Interface {
+		if componentLeaderElector, ok := componentLeaderElectors[component]; ok {
+			return componentLeaderElector
+		}
+		return leaderElector
+	}
+
+	applierManager := &applier.Manager{
+		K0sVars:             c.K0sVars,
+		KubeClientFactory:   adminClientFactory,
+		Config:              c.NodeConfig.Spec.Applier,
+		LeaderElector:       leaderElector,
+		StackLeaderElectors: stackLeaderElectors,
+		EventEmitter:        prober.NewEventEmitter(),
+	}
+	c.NodeComponents.Add(ctx, applierManager)
+	// In-flight applies are drained before the API server stops.
+	c.NodeComponents.DependsOn(applierManager, apiServer, leaderElector)
 
 	if !c.SingleNode && !slices.Contains(c.DisableComponents, constant.ControlAPIComponentName) {
 		c.NodeComponents.Add(ctx, &controller.K0SControlAPI{
@@ -246,18 +461,22 @@ func (c *command) start(ctx context.Context) error {
 			adminClientFactory))
 	}
 
+	// The webhook is started along with the API server, so that it's
+	// available before the cluster configuration is created or reconciled.
+	if c.clusterConfigWebhookEnabled() {
+		c.NodeComponents.Add(ctx, controller.NewClusterConfigWebhook(c.K0sVars, constant.ClusterConfigWebhookPort))
+	}
+
 	if c.EnableK0sCloudProvider {
-		c.NodeComponents.Add(
-			ctx,
-			controller.NewK0sCloudProvider(
-				c.K0sVars.AdminKubeConfigPath,
-				c.K0sCloudProviderUpdateFrequency,
-				c.K0sCloudProviderPort,
-			),
-		)
-	}
-	c.NodeComponents.Add(ctx, &status.Status{
-		Prober: prober.DefaultProber,
+		cloudProviderConfig, err := c.K0sCloudProviderConfig(c.K0sVars.AdminKubeConfigPath)
+		if err != nil {
+			return err
+		}
+		c.NodeComponents.Add(ctx, controller.NewK0sCloudProvider(cloudProviderConfig))
+	}
+	statusComponent := &status.Status{
+		Prober:         prober.DefaultProber,
+		StartupProfile: perfTimer,
 		StatusInformation: status.K0sStatus{
 			Pid:           os.Getpid(),
 			Role:          "controller",
@@ -270,7 +489,18 @@ func (c *command) start(ctx context.Context) error {
 		},
 		Socket:      config.StatusSocket,
 		CertManager: worker.NewCertificateManager(ctx, c.K0sVars.KubeletAuthConfigPath),
-	})
+		CredentialIssuer: &adminCredentialIssuer{
+			certManager: certificateManager,
+			k0sVars:     c.K0sVars,
+		},
+		NodeStatusPublisher: &status.ControlNodeStatusPublisher{
+			KubeClientFactory: adminClientFactory,
+		},
+	}
+	if leasePool != nil {
+		statusComponent.LeaderElection = leasePool
+	}
+	c.NodeComponents.Add(ctx, statusComponent)
 
 	perfTimer.Checkpoint("starting-certificates-init")
 	certs := &Certificates{
@@ -282,6 +512,11 @@ func (c *command) start(ctx context.Context) error {
 		return err
 	}
 
+	if certificateManager.Provider != nil {
+		c.NodeComponents.Add(ctx, controller.NewCertificateRenewer(certificateManager))
+	}
+	c.NodeComponents.Add(ctx, controller.NewAdminKubeconfigRenewer(certificateManager, c.NodeConfig.Spec.API.Port))
+
 	perfTimer.Checkpoint("starting-node-component-init")
 	// init Node components
 	if err := c.NodeComponents.Init(ctx); err != nil {
@@ -306,18 +541,12 @@ func (c *command) start(ctx context.Context) error {
 		}
 	}()
 
-	var configSource clusterconfig.ConfigSource
-	// For backwards compatibility, use file as config source by default
-	if c.EnableDynamicConfig {
-		configSource, err = clusterconfig.NewAPIConfigSource(adminClientFactory)
-	} else {
-		var clusterConfig *v1beta1.ClusterConfig
-		clusterConfig, err = config.LoadClusterConfig(c.K0sVars)
-		if err != nil {
-			return fmt.Errorf("failed to load cluster config: %w", err)
-		}
-		configSource, err = clusterconfig.NewStaticSource(clusterConfig)
-	}
+	configSource, err := clusterconfig.NewSource(c.ConfigSource, clusterconfig.SourceOptions{
+		KubeClientFactory: adminClientFactory,
+		LoadFileConfig: func() (*v1beta1.ClusterConfig, error) {
+			return config.LoadClusterConfig(c.K0sVars)
+		},
+	})
 	if err != nil {
 		return err
 	}
@@ -350,16 +579,31 @@ func (c *command) start(ctx context.Context) error {
 			return fmt.Errorf("failed to initialize helm manifests saver: %w", err)
 		}
 		c.ClusterComponents.Add(ctx, controller.NewCRD(helmSaver, []string{"helm"}))
+		var concurrencyLevel int
+		if extensions := c.NodeConfig.Spec.Extensions; extensions != nil && extensions.Helm != nil {
+			concurrencyLevel = extensions.Helm.ConcurrencyLevel
+		}
 		c.ClusterComponents.Add(ctx, controller.NewExtensionsController(
 			helmSaver,
 			c.K0sVars,
 			adminClientFactory,
-			leaderElector,
-			// Hardcode until the config loading is fixed
-			10,
+			componentLeaderElector("helm"),
+			concurrencyLevel,
 		))
 	}
 
+	applierSaver, err := controller.NewManifestsSaver("applier", c.K0sVars.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize applier manifests saver: %w", err)
+	}
+	c.ClusterComponents.Add(ctx, controller.NewCRD(applierSaver, []string{"applier"}))
+
+	statusSaver, err := controller.NewManifestsSaver("status", c.K0sVars.DataDir)
+	if err != nil {
+		return fmt.Errorf("failed to initialize status manifests saver: %w", err)
+	}
+	c.ClusterComponents.Add(ctx, controller.NewCRD(statusSaver, []string{"status"}))
+
 	if !slices.Contains(c.DisableComponents, constant.AutopilotComponentName) {
 		logrus.Debug("starting manifest saver")
 		manifestsSaver, err := controller.NewManifestsSaver("autopilot", c.K0sVars.DataDir)
@@ -386,6 +630,17 @@ func (c *command) start(ctx context.Context) error {
 		))
 	}
 
+	if !slices.Contains(c.DisableComponents, constant.APIEndpointHealthComponentName) && !c.SingleNode {
+		c.ClusterComponents.Add(ctx, controller.NewAPIEndpointHealthChecker(
+			c.NodeConfig,
+			c.K0sVars,
+			adminClientFactory,
+		))
+	}
+
+	c.ClusterComponents.Add(ctx, controller.NewCARotationReconciler(certificateManager, adminClientFactory))
+	c.ClusterComponents.Add(ctx, controller.NewEventForwarder(leaderElector, adminClientFactory))
+
 	if !slices.Contains(c.DisableComponents, constant.KubeProxyComponentName) {
 		c.ClusterComponents.Add(ctx, controller.NewKubeProxy(c.K0sVars, c.NodeConfig))
 	}
@@ -398,6 +653,26 @@ func (c *command) start(ctx context.Context) error {
 		c.ClusterComponents.Add(ctx, coreDNS)
 	}
 
+	if !slices.Contains(c.DisableComponents, constant.NodeLocalDNSComponentName) {
+		c.ClusterComponents.Add(ctx, controller.NewNodeLocalDNS(c.K0sVars, c.NodeConfig))
+	}
+
+	if !slices.Contains(c.DisableComponents, constant.SnapshotControllerComponentName) {
+		c.ClusterComponents.Add(ctx, controller.NewSnapshotController(c.K0sVars))
+	}
+
+	if !slices.Contains(c.DisableComponents, constant.IngressComponentName) {
+		c.ClusterComponents.Add(ctx, controller.NewIngress(c.K0sVars))
+	}
+
+	if !slices.Contains(c.DisableComponents, constant.ServiceLoadBalancerComponentName) {
+		c.ClusterComponents.Add(ctx, controller.NewServiceLoadBalancer(c.K0sVars))
+	}
+
+	if !slices.Contains(c.DisableComponents, constant.CloudProviderComponentName) {
+		c.ClusterComponents.Add(ctx, controller.NewCloudControllerManager(c.K0sVars))
+	}
+
 	if !slices.Contains(c.DisableComponents, constant.NetworkProviderComponentName) {
 		logrus.Infof("Creating network reconcilers")
 
@@ -409,7 +684,7 @@ func (c *command) start(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("failed to create calico_init manifests saver: %w", err)
 		}
-		c.ClusterComponents.Add(ctx, controller.NewCalico(c.K0sVars, calicoInitSaver, calicoSaver))
+		c.ClusterComponents.Add(ctx, controller.NewCalico(c.K0sVars, c.NodeConfig, calicoInitSaver, calicoSaver))
 
 		kubeRouterSaver, err := controller.NewManifestsSaver("kuberouter", c.K0sVars.DataDir)
 		if err != nil {
@@ -427,10 +702,11 @@ func (c *command) start(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("failed to create metrics manifests saver: %w", err)
 		}
-		metrics, err := controller.NewMetrics(c.K0sVars, metricsSaver, adminClientFactory)
+		metrics, err := controller.NewMetrics(c.K0sVars, c.NodeConfig, metricsSaver, adminClientFactory)
 		if err != nil {
 			return fmt.Errorf("failed to create metrics reconciler: %w", err)
 		}
+		metrics.EnableKonnectivity = enableKonnectivity
 		c.ClusterComponents.Add(ctx, metrics)
 	}
 
@@ -458,6 +734,7 @@ func (c *command) start(ctx context.Context) error {
 			K0sVars:           c.K0sVars,
 			KubeClientFactory: adminClientFactory,
 			NodeConfig:        c.NodeConfig,
+			CertManager:       certificateManager,
 			EventEmitter:      prober.NewEventEmitter(),
 		})
 	}
@@ -472,11 +749,12 @@ func (c *command) start(ctx context.Context) error {
 
 	if !slices.Contains(c.DisableComponents, constant.KubeControllerManagerComponentName) {
 		c.ClusterComponents.Add(ctx, &controller.Manager{
-			LogLevel:              c.Logging[constant.KubeControllerManagerComponentName],
-			K0sVars:               c.K0sVars,
-			SingleNode:            c.SingleNode,
-			ServiceClusterIPRange: c.NodeConfig.Spec.Network.BuildServiceCIDR(c.NodeConfig.Spec.API.Address),
-			ExtraArgs:             c.KubeControllerManagerExtraArgs,
+			LogLevel:               c.Logging[constant.KubeControllerManagerComponentName],
+			K0sVars:                c.K0sVars,
+			SingleNode:             c.SingleNode,
+			ServiceClusterIPRange:  c.NodeConfig.Spec.Network.BuildServiceCIDR(c.NodeConfig.Spec.API.Address),
+			ExtraArgs:              c.KubeControllerManagerExtraArgs,
+			ClusterSigningDuration: c.NodeConfig.Spec.Certificates.GetValidity(),
 		})
 	}
 
@@ -490,6 +768,7 @@ func (c *command) start(ctx context.Context) error {
 		K0sVars:            c.K0sVars,
 		AdminClientFactory: adminClientFactory,
 		EnableWorker:       c.EnableWorker,
+		K0sAPIAddress:      c.NodeConfig.Spec.API.K0sControlPlaneAPIAddress(),
 	})
 
 	perfTimer.Checkpoint("starting-cluster-components-init")
@@ -518,7 +797,7 @@ func (c *command) start(ctx context.Context) error {
 
 	if c.EnableWorker {
 		perfTimer.Checkpoint("starting-worker")
-		if err := c.startWorker(ctx, c.WorkerProfile); err != nil {
+		if err := c.startWorker(ctx, c.WorkerProfile, adminClientFactory); err != nil {
 			logrus.WithError(err).Error("Failed to start controller worker")
 		} else {
 			perfTimer.Checkpoint("started-worker")
@@ -526,6 +805,13 @@ func (c *command) start(ctx context.Context) error {
 	}
 
 	perfTimer.Output()
+	startupSpan.End()
+	if c.WriteStartupProfile {
+		profilePath := filepath.Join(c.K0sVars.RunDir, "startup-profile.json")
+		if err := perfTimer.WriteProfile(profilePath); err != nil {
+			logrus.WithError(err).Error("Failed to write startup profile")
+		}
+	}
 
 	// Wait for k0s process termination
 	<-ctx.Done()
@@ -536,7 +822,20 @@ func (c *command) start(ctx context.Context) error {
 	return os.Remove(c.CfgFile)
 }
 
-func (c *command) startWorker(ctx context.Context, profile string) error {
+// apiServerReadyTimeout is the time to wait for the API server before
+// starting the embedded worker.
+const apiServerReadyTimeout = 5 * time.Minute
+
+func (c *command) startWorker(ctx context.Context, profile string, adminClientFactory kubernetes.ClientFactoryInterface) error {
+	// Kubelet would retry noisily until the API server is serving requests.
+	logrus.Info("Waiting for the API server before starting the worker")
+	if err := status.WaitForAPIServer(ctx, adminClientFactory.GetClient, apiServerReadyTimeout); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
+		logrus.WithError(err).Warn("Starting the worker anyway")
+	}
+
 	var bootstrapConfig string
 	if !file.Exists(c.K0sVars.KubeletAuthConfigPath) {
 		// wait for controller to start up
@@ -590,16 +889,24 @@ func (c *command) needToJoin() bool {
 
 func writeCerts(caData v1beta1.CaResponse, certRootDir string) error {
 	type fileData struct {
-		path string
-		data []byte
-		mode fs.FileMode
+		path     string
+		data     []byte
+		mode     fs.FileMode
+		optional bool
 	}
 	for _, f := range []fileData{
 		{path: filepath.Join(certRootDir, "ca.key"), data: caData.Key, mode: constant.CertSecureMode},
 		{path: filepath.Join(certRootDir, "ca.crt"), data: caData.Cert, mode: constant.CertMode},
 		{path: filepath.Join(certRootDir, "sa.key"), data: caData.SAKey, mode: constant.CertSecureMode},
 		{path: filepath.Join(certRootDir, "sa.pub"), data: caData.SAPub, mode: constant.CertMode},
+		{path: filepath.Join(certRootDir, "ca-next.key"), data: caData.NextKey, mode: constant.CertSecureMode, optional: true},
+		{path: filepath.Join(certRootDir, "ca-next.crt"), data: caData.NextCert, mode: constant.CertMode, optional: true},
+		{path: filepath.Join(certRootDir, "ca-previous.crt"), data: caData.PreviousCert, mode: constant.CertMode, optional: true},
 	} {
+		// The files of an ongoing CA rotation are optional.
+		if f.optional && f.data == nil {
+			continue
+		}
 		err := file.WriteContentAtomically(f.path, f.data, f.mode)
 		if err != nil {
 			return fmt.Errorf("failed to write %s: %w", f.path, err)
@@ -620,7 +927,7 @@ func joinController(ctx context.Context, tokenArg string, certRootDir string) (*
 
 	var caData v1beta1.CaResponse
 	err = retry.Do(func() error {
-		caData, err = joinClient.GetCA()
+		caData, err = joinClient.GetCA(ctx)
 		if err != nil {
 			return fmt.Errorf("failed to sync CA: %w", err)
 		}
@@ -0,0 +1,170 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/k0sproject/k0s/pkg/build"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"github.com/spf13/pflag"
+	"sigs.k8s.io/yaml"
+)
+
+func newDocsCmd() *cobra.Command {
+	var outputDir string
+
+	cmd := &cobra.Command{
+		Use:   "docs <markdown|man|json|yaml>",
+		Short: "Generate k0s command documentation",
+		Long: `Generate k0s command documentation.
+
+Markdown and man pages are written to a directory, one file per command.
+JSON and YAML describe the whole command tree, including all flags, and are
+written to standard output.`,
+		Hidden:    true,
+		ValidArgs: []string{"markdown", "man", "json", "yaml"},
+		Args:      cobra.ExactValidArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := NewRootCmd()
+			switch args[0] {
+			case "markdown":
+				return genDocsTree(outputDir, "./docs/cli", func(dir string) error {
+					return doc.GenMarkdownTree(root, dir)
+				})
+			case "man":
+				return genDocsTree(outputDir, "./man", func(dir string) error {
+					return doc.GenManTree(root, &doc.GenManHeader{
+						Title:   "k0s",
+						Section: "1",
+						Source:  "k0s " + build.Version,
+						Manual:  "k0s Manual",
+					}, dir)
+				})
+			case "json", "yaml":
+				return writeCommandDocs(cmd.OutOrStdout(), root, args[0])
+			}
+			return fmt.Errorf("invalid format")
+		},
+	}
+
+	cmd.Flags().StringVarP(&outputDir, "output-dir", "d", "", "the directory to write markdown and man pages to (default: ./docs/cli for markdown, ./man for man pages)")
+	return cmd
+}
+
+func genDocsTree(dir, defaultDir string, gen func(dir string) error) error {
+	if dir == "" {
+		dir = defaultDir
+	}
+	if err := os.MkdirAll(filepath.Clean(dir), 0755); err != nil {
+		return err
+	}
+	return gen(dir)
+}
+
+// commandDoc is the machine-readable description of a command.
+type commandDoc struct {
+	Name           string       `json:"name"`
+	Path           string       `json:"path"`
+	Usage          string       `json:"usage"`
+	Aliases        []string     `json:"aliases,omitempty"`
+	Short          string       `json:"short,omitempty"`
+	Long           string       `json:"long,omitempty"`
+	Example        string       `json:"example,omitempty"`
+	Deprecated     string       `json:"deprecated,omitempty"`
+	Flags          []flagDoc    `json:"flags,omitempty"`
+	InheritedFlags []flagDoc    `json:"inheritedFlags,omitempty"`
+	Commands       []commandDoc `json:"commands,omitempty"`
+}
+
+// flagDoc is the machine-readable description of a flag.
+type flagDoc struct {
+	Name       string `json:"name"`
+	Shorthand  string `json:"shorthand,omitempty"`
+	Type       string `json:"type"`
+	Default    string `json:"default,omitempty"`
+	Usage      string `json:"usage,omitempty"`
+	Deprecated string `json:"deprecated,omitempty"`
+}
+
+func writeCommandDocs(w io.Writer, root *cobra.Command, format string) error {
+	docs := newCommandDoc(root)
+
+	var data []byte
+	var err error
+	if format == "yaml" {
+		data, err = yaml.Marshal(docs)
+	} else {
+		data, err = json.MarshalIndent(docs, "", "  ")
+		data = append(data, '\n')
+	}
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// newCommandDoc describes the given command and all of its subcommands. Like
+// the markdown and man pages, hidden commands are omitted.
+func newCommandDoc(cmd *cobra.Command) commandDoc {
+	d := commandDoc{
+		Name:           cmd.Name(),
+		Path:           cmd.CommandPath(),
+		Usage:          cmd.UseLine(),
+		Aliases:        cmd.Aliases,
+		Short:          cmd.Short,
+		Long:           cmd.Long,
+		Example:        cmd.Example,
+		Deprecated:     cmd.Deprecated,
+		Flags:          newFlagDocs(cmd.NonInheritedFlags()),
+		InheritedFlags: newFlagDocs(cmd.InheritedFlags()),
+	}
+
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() || sub.IsAdditionalHelpTopicCommand() {
+			continue
+		}
+		d.Commands = append(d.Commands, newCommandDoc(sub))
+	}
+
+	return d
+}
+
+func newFlagDocs(flags *pflag.FlagSet) []flagDoc {
+	var docs []flagDoc
+	flags.VisitAll(func(flag *pflag.Flag) {
+		if flag.Hidden {
+			return
+		}
+		docs = append(docs, flagDoc{
+			Name:       flag.Name,
+			Shorthand:  flag.Shorthand,
+			Type:       flag.Value.Type(),
+			Default:    flag.DefValue,
+			Usage:      flag.Usage,
+			Deprecated: flag.Deprecated,
+		})
+	})
+	return docs
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCommandDocs(t *testing.T) {
+	root := &cobra.Command{Use: "k0s", Short: "k0s - Zero Friction Kubernetes"}
+	root.PersistentFlags().Bool("debug", false, "Debug logging")
+
+	sub := &cobra.Command{Use: "start [flags]", Aliases: []string{"run"}, Short: "Start it", Run: func(*cobra.Command, []string) {}}
+	sub.Flags().StringP("config", "c", "k0s.yaml", "config file")
+	sub.Flags().String("secret", "", "hidden flag")
+	require.NoError(t, sub.Flags().MarkHidden("secret"))
+	root.AddCommand(sub)
+	root.AddCommand(&cobra.Command{Use: "hidden", Hidden: true, Run: func(*cobra.Command, []string) {}})
+
+	var out strings.Builder
+	require.NoError(t, writeCommandDocs(&out, root, "json"))
+
+	var docs commandDoc
+	require.NoError(t, json.Unmarshal([]byte(out.String()), &docs))
+
+	assert.Equal(t, "k0s", docs.Name)
+	assert.Equal(t, []flagDoc{{Name: "debug", Type: "bool", Default: "false", Usage: "Debug logging"}}, docs.Flags)
+	if assert.Len(t, docs.Commands, 1, "hidden commands should be omitted") {
+		start := docs.Commands[0]
+		assert.Equal(t, "start", start.Name)
+		assert.Equal(t, "k0s start", start.Path)
+		assert.Equal(t, "k0s start [flags]", start.Usage)
+		assert.Equal(t, []string{"run"}, start.Aliases)
+		assert.Equal(t, []flagDoc{{Name: "config", Shorthand: "c", Type: "string", Default: "k0s.yaml", Usage: "config file"}}, start.Flags)
+		assert.Equal(t, []flagDoc{{Name: "debug", Type: "bool", Default: "false", Usage: "Debug logging"}}, start.InheritedFlags)
+	}
+
+	out.Reset()
+	require.NoError(t, writeCommandDocs(&out, root, "yaml"))
+	assert.Contains(t, out.String(), "path: k0s start\n")
+}
@@ -0,0 +1,380 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/config"
+	"github.com/k0sproject/k0s/pkg/constant"
+	"github.com/k0sproject/k0s/pkg/helm"
+
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// timeFormat is the format in which timestamps are printed, the same as the
+// helm CLI uses.
+const timeFormat = "Mon Jan _2 15:04:05 2006"
+
+type helmFlags struct {
+	kubeconfig string
+	namespace  string
+}
+
+// NewHelmCmd returns the "k0s helm" command, which exposes a subset of the
+// helm CLI using k0s's admin kubeconfig and helm repository settings.
+func NewHelmCmd() *cobra.Command {
+	var flags helmFlags
+
+	cmd := &cobra.Command{
+		Use:   "helm",
+		Short: "Inspect and manage Helm releases using the k0s environment",
+		Long: `Inspect and manage Helm releases using k0s's admin kubeconfig and Helm
+repository configuration. This is a subset of the Helm CLI, focused on
+debugging the releases of Helm extensions. Use "k0s helm env" to set up the
+environment for a standalone helm binary.`,
+	}
+	cmd.SilenceUsage = true
+
+	pflags := cmd.PersistentFlags()
+	pflags.StringVar(&flags.kubeconfig, "kubeconfig", "", "path to the kubeconfig file (default: $KUBECONFIG or the k0s admin kubeconfig)")
+	pflags.StringVarP(&flags.namespace, "namespace", "n", "default", "namespace scope for this request")
+	pflags.AddFlagSet(config.GetPersistentFlagSet())
+
+	cmd.AddCommand(helmEnvCmd(&flags))
+	cmd.AddCommand(helmListCmd(&flags))
+	cmd.AddCommand(helmStatusCmd(&flags))
+	cmd.AddCommand(helmHistoryCmd(&flags))
+	cmd.AddCommand(helmGetCmd(&flags))
+	cmd.AddCommand(helmRollbackCmd(&flags))
+	cmd.AddCommand(helmRepoCmd())
+	return cmd
+}
+
+// k0sVars returns the k0s paths, with the admin kubeconfig replaced by the
+// kubeconfig given on the command line or in the environment.
+func (f *helmFlags) k0sVars() constant.CfgVars {
+	k0sVars := config.GetCmdOpts().K0sVars
+	if f.kubeconfig != "" {
+		k0sVars.AdminKubeConfigPath = f.kubeconfig
+	} else if kubeconfig, ok := os.LookupEnv("KUBECONFIG"); ok {
+		k0sVars.AdminKubeConfigPath = kubeconfig
+	}
+	return k0sVars
+}
+
+func (f *helmFlags) actionConfig(namespace string) (*action.Configuration, error) {
+	k0sVars := f.k0sVars()
+	if _, err := os.Stat(k0sVars.AdminKubeConfigPath); err != nil {
+		return nil, fmt.Errorf("cannot stat kubeconfig, is the server running?: %w", err)
+	}
+	return helm.NewCommands(k0sVars).ActionConfig(namespace)
+}
+
+func helmEnvCmd(flags *helmFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:   "env",
+		Short: "Print the environment for running a standalone helm binary against k0s",
+		Example: `  # Use the k0s environment in the current shell
+  eval "$(k0s helm env)"
+  helm list -A`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			writeEnv(cmd.OutOrStdout(), flags.k0sVars())
+			return nil
+		},
+	}
+}
+
+func writeEnv(out io.Writer, k0sVars constant.CfgVars) {
+	for _, env := range []struct{ name, value string }{
+		{"KUBECONFIG", k0sVars.AdminKubeConfigPath},
+		{"HELM_REPOSITORY_CONFIG", k0sVars.HelmRepositoryConfig},
+		{"HELM_REPOSITORY_CACHE", k0sVars.HelmRepositoryCache},
+	} {
+		fmt.Fprintf(out, "export %s=%s\n", env.name, strconv.Quote(env.value))
+	}
+}
+
+func helmListCmd(flags *helmFlags) *cobra.Command {
+	var allNamespaces bool
+
+	cmd := &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List releases",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			namespace := flags.namespace
+			if allNamespaces {
+				namespace = ""
+			}
+			cfg, err := flags.actionConfig(namespace)
+			if err != nil {
+				return err
+			}
+
+			list := action.NewList(cfg)
+			list.AllNamespaces = allNamespaces
+			list.All = true
+			list.SetStateMask()
+			releases, err := list.Run()
+			if err != nil {
+				return err
+			}
+			return writeReleases(cmd.OutOrStdout(), releases)
+		},
+	}
+	cmd.Flags().BoolVarP(&allNamespaces, "all-namespaces", "A", false, "list releases across all namespaces")
+	return cmd
+}
+
+func writeReleases(out io.Writer, releases []*release.Release) error {
+	w := tabwriter.NewWriter(out, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(w, "NAME\tNAMESPACE\tREVISION\tUPDATED\tSTATUS\tCHART\tAPP VERSION")
+	for _, r := range releases {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n", r.Name, r.Namespace, r.Version, r.Info.LastDeployed.Format(timeFormat), r.Info.Status, chartName(r), appVersion(r))
+	}
+	return w.Flush()
+}
+
+func chartName(r *release.Release) string {
+	if r.Chart == nil || r.Chart.Metadata == nil {
+		return ""
+	}
+	return r.Chart.Metadata.Name + "-" + r.Chart.Metadata.Version
+}
+
+func appVersion(r *release.Release) string {
+	if r.Chart == nil {
+		return ""
+	}
+	return r.Chart.AppVersion()
+}
+
+func helmStatusCmd(flags *helmFlags) *cobra.Command {
+	var showResources bool
+
+	cmd := &cobra.Command{
+		Use:   "status RELEASE",
+		Short: "Display the status of a release",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := flags.actionConfig(flags.namespace)
+			if err != nil {
+				return err
+			}
+
+			r, err := action.NewStatus(cfg).Run(args[0])
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "NAME: %s\n", r.Name)
+			fmt.Fprintf(out, "LAST DEPLOYED: %s\n", r.Info.LastDeployed.Format(timeFormat))
+			fmt.Fprintf(out, "NAMESPACE: %s\n", r.Namespace)
+			fmt.Fprintf(out, "STATUS: %s\n", r.Info.Status)
+			fmt.Fprintf(out, "REVISION: %d\n", r.Version)
+			fmt.Fprintf(out, "CHART: %s\n", chartName(r))
+			if r.Info.Description != "" {
+				fmt.Fprintf(out, "DESCRIPTION: %s\n", r.Info.Description)
+			}
+			if showResources {
+				fmt.Fprintf(out, "MANIFEST:\n%s\n", r.Manifest)
+			}
+			if r.Info.Notes != "" {
+				fmt.Fprintf(out, "NOTES:\n%s\n", r.Info.Notes)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&showResources, "show-resources", false, "print the manifest of the release")
+	return cmd
+}
+
+func helmHistoryCmd(flags *helmFlags) *cobra.Command {
+	return &cobra.Command{
+		Use:     "history RELEASE",
+		Aliases: []string{"hist"},
+		Short:   "Fetch the revision history of a release",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := flags.actionConfig(flags.namespace)
+			if err != nil {
+				return err
+			}
+
+			history, err := action.NewHistory(cfg).Run(args[0])
+			if err != nil {
+				return err
+			}
+			return writeHistory(cmd.OutOrStdout(), history)
+		},
+	}
+}
+
+func writeHistory(out io.Writer, history []*release.Release) error {
+	sort.Slice(history, func(i, j int) bool { return history[i].Version < history[j].Version })
+	w := tabwriter.NewWriter(out, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(w, "REVISION\tUPDATED\tSTATUS\tCHART\tAPP VERSION\tDESCRIPTION")
+	for _, r := range history {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", r.Version, r.Info.LastDeployed.Format(timeFormat), r.Info.Status, chartName(r), appVersion(r), r.Info.Description)
+	}
+	return w.Flush()
+}
+
+func helmGetCmd(flags *helmFlags) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "get",
+		Short: "Download extended information of a release",
+	}
+
+	var allValues bool
+	valuesCmd := &cobra.Command{
+		Use:   "values RELEASE",
+		Short: "Download the values of a release",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := flags.actionConfig(flags.namespace)
+			if err != nil {
+				return err
+			}
+
+			get := action.NewGetValues(cfg)
+			get.AllValues = allValues
+			values, err := get.Run(args[0])
+			if err != nil {
+				return err
+			}
+			data, err := yaml.Marshal(values)
+			if err != nil {
+				return err
+			}
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+	valuesCmd.Flags().BoolVarP(&allValues, "all", "a", false, "include the chart's default values")
+
+	manifestCmd := &cobra.Command{
+		Use:   "manifest RELEASE",
+		Short: "Download the manifest of a release",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := flags.actionConfig(flags.namespace)
+			if err != nil {
+				return err
+			}
+
+			r, err := action.NewGet(cfg).Run(args[0])
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), r.Manifest)
+			return err
+		},
+	}
+
+	cmd.AddCommand(valuesCmd)
+	cmd.AddCommand(manifestCmd)
+	return cmd
+}
+
+func helmRollbackCmd(flags *helmFlags) *cobra.Command {
+	rollback := struct {
+		wait    bool
+		timeout time.Duration
+	}{}
+
+	cmd := &cobra.Command{
+		Use:   "rollback RELEASE [REVISION]",
+		Short: "Roll back a release to a previous revision",
+		Long: `Roll back a release to a previous revision. If the revision is omitted,
+the release is rolled back to the revision before the current one.
+
+Note that the releases of Helm extensions are reconciled by k0s. Rolling them
+back is only effective until k0s upgrades them again.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := flags.actionConfig(flags.namespace)
+			if err != nil {
+				return err
+			}
+
+			r := action.NewRollback(cfg)
+			if len(args) > 1 {
+				if r.Version, err = strconv.Atoi(args[1]); err != nil {
+					return fmt.Errorf("invalid revision %q: %w", args[1], err)
+				}
+			}
+			r.Wait = rollback.wait
+			r.Timeout = rollback.timeout
+			if err := r.Run(args[0]); err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), "Rollback was a success!")
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&rollback.wait, "wait", false, "wait until all resources are ready")
+	cmd.Flags().DurationVar(&rollback.timeout, "timeout", 5*time.Minute, "time to wait for the rollback")
+	return cmd
+}
+
+func helmRepoCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "repo",
+		Short: "Inspect the chart repositories configured by k0s",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List chart repositories",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			f, err := repo.LoadFile(config.GetCmdOpts().K0sVars.HelmRepositoryConfig)
+			if err != nil {
+				if os.IsNotExist(err) {
+					return fmt.Errorf("no repositories configured")
+				}
+				return err
+			}
+			return writeRepositories(cmd.OutOrStdout(), f)
+		},
+	})
+	return cmd
+}
+
+func writeRepositories(out io.Writer, f *repo.File) error {
+	w := tabwriter.NewWriter(out, 0, 8, 1, '\t', 0)
+	fmt.Fprintln(w, "NAME\tURL")
+	for _, r := range f.Repositories {
+		fmt.Fprintf(w, "%s\t%s\n", r.Name, r.URL)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+)
+
+func TestWriteEnv(t *testing.T) {
+	var out bytes.Buffer
+	writeEnv(&out, constant.CfgVars{
+		AdminKubeConfigPath:  "/var/lib/k0s/pki/admin.conf",
+		HelmRepositoryConfig: "/var/lib/k0s/helmhome/repositories.yaml",
+		HelmRepositoryCache:  "/var/lib/k0s/helmhome/cache",
+	})
+
+	assert.Equal(t, `export KUBECONFIG="/var/lib/k0s/pki/admin.conf"
+export HELM_REPOSITORY_CONFIG="/var/lib/k0s/helmhome/repositories.yaml"
+export HELM_REPOSITORY_CACHE="/var/lib/k0s/helmhome/cache"
+`, out.String())
+}
+
+func TestEnvCmd_Kubeconfig(t *testing.T) {
+	t.Setenv("KUBECONFIG", "/from/env")
+
+	for _, test := range []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"env", nil, `export KUBECONFIG="/from/env"`},
+		{"flag", []string{"--kubeconfig", "/from/flag"}, `export KUBECONFIG="/from/flag"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var out, err bytes.Buffer
+			underTest := NewHelmCmd()
+			underTest.SetArgs(append([]string{"env"}, test.args...))
+			underTest.SetOut(&out)
+			underTest.SetErr(&err)
+
+			require.NoError(t, underTest.Execute())
+			assert.Equal(t, test.expected, strings.SplitN(out.String(), "\n", 2)[0])
+			assert.Empty(t, err.String())
+		})
+	}
+}
+
+func TestWriteHistory(t *testing.T) {
+	newRevision := func(version int, status release.Status) *release.Release {
+		return &release.Release{
+			Version: version,
+			Chart:   &chart.Chart{Metadata: &chart.Metadata{Name: "test", Version: "1.0.0", AppVersion: "v1"}},
+			Info: &release.Info{
+				Status:       status,
+				LastDeployed: helmtime.Unix(1680000000, 0).UTC(),
+				Description:  "Upgrade complete",
+			},
+		}
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, writeHistory(&out, []*release.Release{
+		newRevision(2, release.StatusDeployed),
+		newRevision(1, release.StatusSuperseded),
+	}))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, []string{"REVISION", "UPDATED", "STATUS", "CHART", "APP", "VERSION", "DESCRIPTION"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"1", "Tue", "Mar", "28", "10:40:00", "2023", "superseded", "test-1.0.0", "v1", "Upgrade", "complete"}, strings.Fields(lines[1]))
+	assert.Equal(t, "2", strings.Fields(lines[2])[0])
+}
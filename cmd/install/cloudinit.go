@@ -0,0 +1,144 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/k0sproject/k0s/pkg/build"
+	"github.com/k0sproject/k0s/pkg/config"
+	"github.com/k0sproject/k0s/pkg/install"
+
+	"github.com/spf13/cobra"
+)
+
+// Paths on the provisioned node to which the embedded files are written.
+const (
+	nodeConfigPath = "/etc/k0s/k0s.yaml"
+	nodeTokenPath  = "/etc/k0s/join-token"
+)
+
+func installGenerateCloudInitCmd(installFlags *installFlags) *cobra.Command {
+	var (
+		role        string
+		format      string
+		version     string
+		arch        string
+		downloadURL string
+		binaryPath  string
+		tokenURL    string
+		tokenFile   string
+		cfgFile     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "generate-cloud-init [flags] [-- install flags]",
+		Short: "Generate cloud-init or Ignition configs that install k0s on a brand-new system",
+		Example: `The generated config downloads the k0s binary, writes the config and token
+files, installs the k0s service and starts it, in that order. Any arguments
+after "--" are passed on to "k0s install <role>" on the provisioned node.
+
+# cloud-init user data for a worker that fetches its join token via HTTP
+k0s install generate-cloud-init --role worker --token-url https://example.com/token
+
+# Ignition config for a controller, embedding a local config and join token
+k0s install generate-cloud-init --role controller --format ignition --config k0s.yaml --token-file token -- --enable-worker`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var installArgs []string
+			if dash := cmd.ArgsLenAtDash(); dash >= 0 {
+				args, installArgs = args[:dash], args[dash:]
+			}
+			if len(args) > 0 {
+				return fmt.Errorf("unexpected arguments: %v", args)
+			}
+
+			spec := install.ProvisioningSpec{
+				Role:        role,
+				DownloadURL: downloadURL,
+				BinaryPath:  binaryPath,
+			}
+			if spec.DownloadURL == "" {
+				spec.DownloadURL = install.DownloadURL(version, arch)
+			}
+
+			if tokenURL != "" && tokenFile != "" {
+				return errors.New("only one of --token-url and --token-file may be given")
+			}
+			if tokenURL != "" {
+				spec.InstallArgs = append(spec.InstallArgs, "--token-url="+tokenURL)
+			}
+			if tokenFile != "" {
+				token, err := os.ReadFile(tokenFile)
+				if err != nil {
+					return err
+				}
+				spec.Files = append(spec.Files, install.ProvisioningFile{Path: nodeTokenPath, Content: token, Mode: 0600})
+				spec.InstallArgs = append(spec.InstallArgs, "--token-file="+nodeTokenPath)
+			}
+			if cfgFile != "" {
+				if role != "controller" {
+					return errors.New("--config may only be given for controllers")
+				}
+				cfg, err := os.ReadFile(cfgFile)
+				if err != nil {
+					return err
+				}
+				spec.Files = append(spec.Files, install.ProvisioningFile{Path: nodeConfigPath, Content: cfg, Mode: 0600})
+				spec.InstallArgs = append(spec.InstallArgs, "--config="+nodeConfigPath)
+			}
+			if config.DataDir != "" {
+				spec.InstallArgs = append(spec.InstallArgs, "--data-dir="+config.DataDir)
+			}
+			for _, envVar := range installFlags.envVars {
+				spec.InstallArgs = append(spec.InstallArgs, "--env="+envVar)
+			}
+			spec.InstallArgs = append(spec.InstallArgs, installArgs...)
+
+			var data []byte
+			var err error
+			switch format {
+			case "cloud-init":
+				data, err = spec.CloudInit()
+			case "ignition":
+				data, err = spec.Ignition()
+			default:
+				return fmt.Errorf("unsupported format: %q", format)
+			}
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(data)
+			return err
+		},
+	}
+
+	cmd.Flags().StringVar(&role, "role", "worker", "role of the node, either controller or worker")
+	cmd.Flags().StringVar(&format, "format", "cloud-init", "output format, either cloud-init or ignition")
+	cmd.Flags().StringVar(&version, "k0s-version", build.Version, "k0s version to install")
+	cmd.Flags().StringVar(&arch, "arch", runtime.GOARCH, "CPU architecture of the node")
+	cmd.Flags().StringVar(&downloadURL, "download-url", "", "URL to download the k0s binary from (default: GitHub release for the given version and architecture)")
+	cmd.Flags().StringVar(&binaryPath, "binary-path", install.DefaultBinaryPath, "path on the node to which the k0s binary is downloaded")
+	cmd.Flags().StringVar(&tokenURL, "token-url", "", "URL from which the node fetches its join token")
+	cmd.Flags().StringVar(&tokenFile, "token-file", "", "local file containing a join token to be embedded")
+	cmd.Flags().StringVarP(&cfgFile, "config", "c", "", "local k0s config file to be embedded (controllers only)")
+
+	return cmd
+}
@@ -18,13 +18,14 @@ package install
 
 import (
 	"fmt"
-	"os"
 	"path/filepath"
 
 	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/internal/pkg/users"
 	"github.com/k0sproject/k0s/pkg/config"
 	"github.com/k0sproject/k0s/pkg/install"
 
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -45,6 +46,7 @@ func NewInstallCmd() *cobra.Command {
 
 	cmd.AddCommand(installControllerCmd(&installFlags))
 	cmd.AddCommand(installWorkerCmd(&installFlags))
+	cmd.AddCommand(installGenerateCloudInitCmd(&installFlags))
 	cmd.PersistentFlags().BoolVar(&installFlags.force, "force", false, "force init script creation")
 	cmd.PersistentFlags().StringArrayVarP(&installFlags.envVars, "env", "e", nil, "set environment variable")
 	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
@@ -55,8 +57,8 @@ func NewInstallCmd() *cobra.Command {
 //   - Ensures that the proper users are created.
 //   - Sets up startup and logging for k0s.
 func (c *command) setup(role string, args []string, installFlags *installFlags) error {
-	if os.Geteuid() != 0 {
-		return fmt.Errorf("this command must be run as root")
+	if !users.IsPrivileged() {
+		return fmt.Errorf("this command must be run as root (or as Administrator on Windows)")
 	}
 
 	if role == "controller" {
@@ -64,6 +66,9 @@ func (c *command) setup(role string, args []string, installFlags *installFlags)
 			return fmt.Errorf("failed to create controller users: %v", err)
 		}
 	}
+	if err := install.EnsureSELinuxLabels(c.K0sVars); err != nil {
+		logrus.WithError(err).Warn("Failed to apply SELinux labels, containers may fail to start if SELinux is in enforcing mode")
+	}
 	err := install.EnsureService(args, installFlags.envVars, installFlags.force)
 	if err != nil {
 		return fmt.Errorf("failed to install k0s service: %v", err)
@@ -97,5 +102,14 @@ func (c *command) convertFileParamsToAbsolute() (err error) {
 			return fmt.Errorf("%s does not exist", c.TokenFile)
 		}
 	}
+	if c.TokenURLAuthHeaderFile != "" {
+		c.TokenURLAuthHeaderFile, err = filepath.Abs(c.TokenURLAuthHeaderFile)
+		if err != nil {
+			return err
+		}
+		if !file.Exists(c.TokenURLAuthHeaderFile) {
+			return fmt.Errorf("%s does not exist", c.TokenURLAuthHeaderFile)
+		}
+	}
 	return nil
 }
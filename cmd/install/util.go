@@ -38,7 +38,7 @@ func cmdFlagsToArgs(cmd *cobra.Command) []string {
 			if f.Name == "env" || f.Name == "force" {
 				return
 			}
-			if f.Name == "data-dir" || f.Name == "token-file" || f.Name == "config" {
+			if f.Name == "data-dir" || f.Name == "token-file" || f.Name == "token-url-auth-header-file" || f.Name == "config" {
 				val, _ = filepath.Abs(val)
 			}
 			flagsAndVals = append(flagsAndVals, fmt.Sprintf("--%s=%s", f.Name, val))
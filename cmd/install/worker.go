@@ -25,11 +25,11 @@ import (
 func installWorkerCmd(installFlags *installFlags) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "worker",
-		Short: "Install k0s worker on a brand-new system. Must be run as root (or with sudo)",
+		Short: "Install k0s worker on a brand-new system. Must be run as root (or with sudo, or as Administrator on Windows)",
 		Example: `Worker subcommand allows you to pass in all available worker parameters.
 All default values of worker command will be passed to the service stub unless overridden.
 
-Windows flags like "--api-server", "--cidr-range" and "--cluster-dns" will be ignored since install command doesn't yet support Windows services`,
+On Windows, the worker is installed as a Windows service. Run the command from an elevated (Administrator) prompt.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			c := command(config.GetCmdOpts())
 			if err := c.convertFileParamsToAbsolute(); err != nil {
@@ -20,22 +20,63 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/config"
 
+	clientauthv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
 	"github.com/spf13/cobra"
 )
 
+const (
+	adminOutputEmbedded = "embedded"
+	adminOutputExec     = "exec"
+)
+
 func kubeConfigAdminCmd() *cobra.Command {
+	var (
+		output     string
+		regenerate bool
+	)
+
 	cmd := &cobra.Command{
 		Use:   "admin",
 		Short: "Display Admin's Kubeconfig file",
-		Long:  "Print kubeconfig for the Admin user to stdout",
+		Long: `Print kubeconfig for the Admin user to stdout
+
+By default, the admin's client certificate is embedded into the kubeconfig. When
+using "--output exec", the kubeconfig instead invokes "k0s kubeconfig token" to
+fetch short-lived credentials on demand from the k0s controller running on this
+node.
+
+The k0s controller renews the admin's client certificate before it expires (see
+spec.certificates.adminKubeconfigValidity). If it is about to expire nevertheless, e.g.
+because the controller wasn't running, it is regenerated before it's displayed.
+Use "--regenerate" to regenerate it unconditionally.`,
 		Example: `	$ k0s kubeconfig admin > ~/.kube/config
 	$ export KUBECONFIG=~/.kube/config
-	$ kubectl get nodes`,
+	$ kubectl get nodes
+
+	Use short-lived credentials instead of embedding the client certificate:
+	$ k0s kubeconfig admin --output exec > ~/.kube/config
+
+	Issue a new client certificate for the admin user:
+	$ k0s kubeconfig admin --regenerate > ~/.kube/config`,
+		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			c := config.GetCmdOpts()
+			certManager := certificate.NewManager(c.K0sVars, c.NodeConfig.Spec.Certificates)
+			if regenerate || file.Exists(c.K0sVars.AdminKubeConfigPath) && certManager.AdminKubeconfigNeedsRenewal(time.Now()) {
+				if err := certManager.WriteAdminKubeconfig(c.NodeConfig.Spec.API.Port); err != nil {
+					return fmt.Errorf("failed to regenerate admin config, check if the control plane is initialized on this node: %w", err)
+				}
+			}
+
 			content, err := os.ReadFile(c.K0sVars.AdminKubeConfigPath)
 			if err != nil {
 				return fmt.Errorf("failed to read admin config, check if the control plane is initialized on this node: %w", err)
@@ -43,10 +84,52 @@ func kubeConfigAdminCmd() *cobra.Command {
 
 			clusterAPIURL := c.NodeConfig.Spec.API.APIAddressURL()
 			newContent := strings.Replace(string(content), "https://localhost:6443", clusterAPIURL, -1)
+
+			switch output {
+			case adminOutputEmbedded:
+			case adminOutputExec:
+				executable, err := os.Executable()
+				if err != nil {
+					return fmt.Errorf("failed to determine path of the k0s executable: %w", err)
+				}
+				execContent, err := execKubeconfig([]byte(newContent), executable, config.StatusSocket)
+				if err != nil {
+					return err
+				}
+				newContent = string(execContent)
+			default:
+				return fmt.Errorf("unsupported output %q, expected one of %q or %q", output, adminOutputEmbedded, adminOutputExec)
+			}
+
 			_, err = cmd.OutOrStdout().Write([]byte(newContent))
 			return err
 		},
 	}
+	cmd.Flags().StringVarP(&output, "output", "o", adminOutputEmbedded, fmt.Sprintf("Output format, either %q or %q", adminOutputEmbedded, adminOutputExec))
+	cmd.Flags().BoolVar(&regenerate, "regenerate", false, "Issue a new client certificate for the admin user before displaying the kubeconfig")
 	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
 	return cmd
 }
+
+// execKubeconfig replaces the embedded client credentials of all users in the
+// given kubeconfig with an exec credential plugin that invokes "k0s kubeconfig
+// token" using the given executable and status socket.
+func execKubeconfig(kubeconfig []byte, executable, statusSocket string) ([]byte, error) {
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse admin kubeconfig: %w", err)
+	}
+
+	for name := range cfg.AuthInfos {
+		cfg.AuthInfos[name] = &clientcmdapi.AuthInfo{
+			Exec: &clientcmdapi.ExecConfig{
+				APIVersion:      clientauthv1.SchemeGroupVersion.String(),
+				Command:         executable,
+				Args:            []string{"kubeconfig", "token", "--status-socket", statusSocket},
+				InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+			},
+		}
+	}
+
+	return clientcmd.Write(*cfg)
+}
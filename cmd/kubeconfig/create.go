@@ -21,13 +21,17 @@ import (
 	"encoding/base64"
 	"fmt"
 	"html/template"
+	"io"
 	"os"
 	"path"
+	"time"
 
 	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/config"
 
+	"github.com/cloudflare/cfssl/helpers"
 	"github.com/spf13/cobra"
+	"golang.org/x/exp/slices"
 )
 
 var userKubeconfigTemplate = template.Must(template.New("kubeconfig").Parse(`
@@ -53,7 +57,10 @@ users:
 `))
 
 func kubeconfigCreateCmd() *cobra.Command {
-	var groups string
+	var (
+		groups   []string
+		validity time.Duration
+	)
 
 	cmd := &cobra.Command{
 		Use:   "create username",
@@ -65,7 +72,10 @@ Note: A certificate once signed cannot be revoked for a particular user`,
 	$ k0s kubeconfig create username
 
 	optionally add groups:
-	$ k0s kubeconfig create username --groups [groups]`,
+	$ k0s kubeconfig create username --groups [group] --groups [group]
+
+	optionally specify the validity of the client certificate:
+	$ k0s kubeconfig create username --validity 720h`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return fmt.Errorf("username is mandatory")
@@ -74,54 +84,84 @@ Note: A certificate once signed cannot be revoked for a particular user`,
 			c := config.GetCmdOpts()
 			clusterAPIURL := c.NodeConfig.Spec.API.APIAddressURL()
 
-			caCert, err := os.ReadFile(path.Join(c.K0sVars.CertRootDir, "ca.crt"))
+			caCert, err := certificate.ReadCABundle(c.K0sVars.CertRootDir)
 			if err != nil {
 				return fmt.Errorf("failed to read cluster ca certificate: %w, check if the control plane is initialized on this node", err)
 			}
 			caCertPath, caCertKey := path.Join(c.K0sVars.CertRootDir, "ca.crt"), path.Join(c.K0sVars.CertRootDir, "ca.key")
 			userReq := certificate.Request{
-				Name:   username,
-				CN:     username,
-				O:      groups,
-				CACert: caCertPath,
-				CAKey:  caCertKey,
-			}
-			certManager := certificate.Manager{
-				K0sVars: c.K0sVars,
+				Name:     username,
+				CN:       username,
+				Groups:   groups,
+				Validity: validity,
+				CACert:   caCertPath,
+				CAKey:    caCertKey,
 			}
+			certManager := certificate.NewManager(c.K0sVars, c.NodeConfig.Spec.Certificates)
 			userCert, err := certManager.EnsureCertificate(userReq, "root")
 			if err != nil {
 				return err
 			}
-
-			data := struct {
-				CACert     string
-				ClientCert string
-				ClientKey  string
-				User       string
-				JoinURL    string
-			}{
-				CACert:     base64.StdEncoding.EncodeToString(caCert),
-				ClientCert: base64.StdEncoding.EncodeToString([]byte(userCert.Cert)),
-				ClientKey:  base64.StdEncoding.EncodeToString([]byte(userCert.Key)),
-				User:       username,
-				JoinURL:    clusterAPIURL,
+			// EnsureCertificate keeps certificates that haven't been issued
+			// by k0s. Don't silently hand those out with other settings than
+			// the requested ones.
+			if err := checkUserCertificate(userCert, groups, validity); err != nil {
+				return fmt.Errorf("existing certificate of user %s doesn't match: %w, use 'k0s kubeconfig renew' to change it", username, err)
 			}
 
-			var buf bytes.Buffer
-
-			err = userKubeconfigTemplate.Execute(&buf, &data)
-			if err != nil {
-				return err
-			}
-			_, err = cmd.OutOrStdout().Write(buf.Bytes())
-			if err != nil {
-				return err
-			}
-			return nil
+			return writeUserKubeconfig(cmd.OutOrStdout(), username, clusterAPIURL, caCert, userCert)
 		},
 	}
-	cmd.Flags().StringVar(&groups, "groups", "", "Specify groups")
+	cmd.Flags().StringSliceVar(&groups, "groups", nil, "Specify groups (may be repeated or comma-separated)")
+	cmd.Flags().DurationVar(&validity, "validity", 0, "Validity of the client certificate (defaults to spec.certificates.validity, 8760h if unset)")
 	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
 	return cmd
 }
+
+// checkUserCertificate checks that userCert has exactly the given groups and,
+// if given, the validity.
+func checkUserCertificate(userCert certificate.Certificate, groups []string, validity time.Duration) error {
+	cert, err := helpers.ParseCertificatePEM([]byte(userCert.Cert))
+	if err != nil {
+		return err
+	}
+
+	want, got := slices.Clone(groups), slices.Clone(cert.Subject.Organization)
+	slices.Sort(want)
+	slices.Sort(got)
+	if !slices.Equal(slices.Compact(want), slices.Compact(got)) {
+		return fmt.Errorf("groups are %v, not %v", cert.Subject.Organization, groups)
+	}
+
+	if validity != 0 {
+		actual := cert.NotAfter.Sub(cert.NotBefore)
+		if diff := actual - validity; diff < -time.Minute || diff > time.Minute {
+			return fmt.Errorf("validity is %s, not %s", actual, validity)
+		}
+	}
+
+	return nil
+}
+
+func writeUserKubeconfig(out io.Writer, username, clusterAPIURL string, caCert []byte, userCert certificate.Certificate) error {
+	data := struct {
+		CACert     string
+		ClientCert string
+		ClientKey  string
+		User       string
+		JoinURL    string
+	}{
+		CACert:     base64.StdEncoding.EncodeToString(caCert),
+		ClientCert: base64.StdEncoding.EncodeToString([]byte(userCert.Cert)),
+		ClientKey:  base64.StdEncoding.EncodeToString([]byte(userCert.Key)),
+		User:       username,
+		JoinURL:    clusterAPIURL,
+	}
+
+	var buf bytes.Buffer
+	if err := userKubeconfigTemplate.Execute(&buf, &data); err != nil {
+		return err
+	}
+	_, err := out.Write(buf.Bytes())
+	return err
+}
@@ -32,7 +32,11 @@ func NewKubeConfigCmd() *cobra.Command {
 	}
 	cmd.SilenceUsage = true
 	cmd.AddCommand(kubeconfigCreateCmd())
+	cmd.AddCommand(kubeconfigRenewCmd())
 	cmd.AddCommand(kubeConfigAdminCmd())
+	cmd.AddCommand(kubeConfigTokenCmd())
+	cmd.AddCommand(kubeConfigServiceAccountCmd())
+	cmd.AddCommand(kubeConfigOIDCCmd())
 	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
 	return cmd
 }
@@ -0,0 +1,141 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/config"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/spf13/cobra"
+)
+
+type oidcLoginOptions struct {
+	clientSecret      string
+	embedClientSecret bool
+	extraScopes       []string
+	// issuerCACert is the CA that signed the issuer's serving certificate,
+	// read from spec.api.oidc.caFile. It's embedded into the kubeconfig, as
+	// the file is only available on the controller.
+	issuerCACert []byte
+}
+
+func kubeConfigOIDCCmd() *cobra.Command {
+	var opts oidcLoginOptions
+
+	cmd := &cobra.Command{
+		Use:   "oidc",
+		Short: "Create a kubeconfig for OpenID Connect authentication",
+		Long: `Create a kubeconfig that authenticates users via the OpenID Connect settings
+configured in spec.api.oidc. The kubeconfig uses the oidc-login plugin for
+kubectl (https://github.com/int128/kubelogin) to obtain ID tokens, which needs
+to be installed on the machines using it.`,
+		Example: `	$ k0s kubeconfig oidc > ~/.kube/config
+	$ kubectl get nodes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c := config.GetCmdOpts()
+			oidc := c.NodeConfig.Spec.API.OIDC
+			if oidc == nil {
+				return errors.New("OpenID Connect is not configured, see spec.api.oidc")
+			}
+
+			if opts.clientSecret != "" && !opts.embedClientSecret {
+				return errors.New("the client secret would be stored in plaintext in the kubeconfig, use --embed-client-secret to confirm")
+			}
+
+			caCert, err := certificate.ReadCABundle(c.K0sVars.CertRootDir)
+			if err != nil {
+				return fmt.Errorf("failed to read cluster ca certificate: %w, check if the control plane is initialized on this node", err)
+			}
+
+			if oidc.CAFile != "" {
+				opts.issuerCACert, err = os.ReadFile(oidc.CAFile)
+				if err != nil {
+					return fmt.Errorf("failed to read OpenID Connect issuer ca certificate: %w", err)
+				}
+			}
+
+			kubeconfig, err := oidcKubeconfig(c.NodeConfig.Spec.API.APIAddressURL(), caCert, oidc, &opts)
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(kubeconfig)
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&opts.clientSecret, "client-secret", "", "The OpenID Connect client secret, if required by the issuer (needs --embed-client-secret)")
+	cmd.Flags().BoolVar(&opts.embedClientSecret, "embed-client-secret", false, "Confirm that the client secret is stored in plaintext in the kubeconfig")
+	cmd.Flags().StringSliceVar(&opts.extraScopes, "extra-scopes", nil, "Additional scopes to request from the issuer, e.g. email or groups")
+	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
+	return cmd
+}
+
+// oidcKubeconfig renders a kubeconfig that uses the oidc-login exec credential
+// plugin to authenticate against the given OpenID Connect issuer
+func oidcKubeconfig(clusterAPIURL string, caCert []byte, oidc *v1beta1.OIDC, opts *oidcLoginOptions) ([]byte, error) {
+	args := []string{
+		"oidc-login",
+		"get-token",
+		"--oidc-issuer-url=" + oidc.IssuerURL,
+		"--oidc-client-id=" + oidc.ClientID,
+	}
+	if opts.clientSecret != "" {
+		args = append(args, "--oidc-client-secret="+opts.clientSecret)
+	}
+	for _, scope := range opts.extraScopes {
+		args = append(args, "--oidc-extra-scope="+scope)
+	}
+	if len(opts.issuerCACert) > 0 {
+		args = append(args, "--certificate-authority-data="+base64.StdEncoding.EncodeToString(opts.issuerCACert))
+	}
+
+	return clientcmd.Write(clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"k0s": {
+				Server:                   clusterAPIURL,
+				CertificateAuthorityData: caCert,
+			},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"k0s": {
+				Cluster:  "k0s",
+				AuthInfo: "oidc",
+			},
+		},
+		CurrentContext: "k0s",
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			"oidc": {
+				Exec: &clientcmdapi.ExecConfig{
+					APIVersion:      "client.authentication.k8s.io/v1",
+					Command:         "kubectl",
+					Args:            args,
+					InteractiveMode: clientcmdapi.IfAvailableExecInteractiveMode,
+				},
+			},
+		},
+	})
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/config"
+
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/spf13/cobra"
+)
+
+func kubeconfigRenewCmd() *cobra.Command {
+	var (
+		groups   []string
+		validity time.Duration
+	)
+
+	cmd := &cobra.Command{
+		Use:   "renew username",
+		Short: "Renew the kubeconfig of a user",
+		Long: `Renew the kubeconfig of a user previously created via "k0s kubeconfig create".
+The client certificate is re-issued using the user's existing private key. Unless
+specified otherwise, the user's group memberships are kept.`,
+		Example: `	Command to renew the kubeconfig of a user:
+	$ k0s kubeconfig renew username
+
+	optionally change the groups and the validity of the client certificate:
+	$ k0s kubeconfig renew username --groups [group] --validity 720h`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			username := args[0]
+			c := config.GetCmdOpts()
+			clusterAPIURL := c.NodeConfig.Spec.API.APIAddressURL()
+
+			caCert, err := certificate.ReadCABundle(c.K0sVars.CertRootDir)
+			if err != nil {
+				return fmt.Errorf("failed to read cluster ca certificate: %w, check if the control plane is initialized on this node", err)
+			}
+
+			if !cmd.Flags().Changed("groups") {
+				certPath := path.Join(c.K0sVars.CertRootDir, username+".crt")
+				certPEM, err := os.ReadFile(certPath)
+				if err != nil {
+					return fmt.Errorf("failed to read certificate of user %s: %w", username, err)
+				}
+				cert, err := helpers.ParseCertificatePEM(certPEM)
+				if err != nil {
+					return fmt.Errorf("failed to parse certificate of user %s: %w", username, err)
+				}
+				groups = cert.Subject.Organization
+			}
+
+			caCertPath, caCertKey := path.Join(c.K0sVars.CertRootDir, "ca.crt"), path.Join(c.K0sVars.CertRootDir, "ca.key")
+			userReq := certificate.Request{
+				Name:     username,
+				CN:       username,
+				Groups:   groups,
+				Validity: validity,
+				CACert:   caCertPath,
+				CAKey:    caCertKey,
+			}
+			certManager := certificate.NewManager(c.K0sVars, c.NodeConfig.Spec.Certificates)
+			userCert, err := certManager.RenewCertificate(userReq, "root")
+			if err != nil {
+				return err
+			}
+
+			return writeUserKubeconfig(cmd.OutOrStdout(), username, clusterAPIURL, caCert, userCert)
+		},
+	}
+	cmd.Flags().StringSliceVar(&groups, "groups", nil, "Specify groups, replacing the existing ones (may be repeated or comma-separated)")
+	cmd.Flags().DurationVar(&validity, "validity", 0, "Validity of the client certificate (defaults to spec.certificates.validity, 8760h if unset)")
+	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
+	return cmd
+}
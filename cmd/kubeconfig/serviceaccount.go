@@ -0,0 +1,128 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/config"
+	"github.com/k0sproject/k0s/pkg/kubernetes"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/spf13/cobra"
+)
+
+func kubeConfigServiceAccountCmd() *cobra.Command {
+	var duration time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "serviceaccount namespace/name",
+		Short: "Create a kubeconfig for a service account",
+		Long: `Create a kubeconfig that authenticates as the given service account, using a
+bound service account token obtained via the TokenRequest API. The token
+expires after the given duration and is not stored in the cluster.`,
+		Example: `	$ k0s kubeconfig serviceaccount kube-system/ci-deployer --duration 1h > ci.kubeconfig`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			namespace, name, err := parseServiceAccountArgs(args[0], duration)
+			if err != nil {
+				return err
+			}
+
+			c := config.GetCmdOpts()
+			caCert, err := certificate.ReadCABundle(c.K0sVars.CertRootDir)
+			if err != nil {
+				return fmt.Errorf("failed to read cluster ca certificate: %w, check if the control plane is initialized on this node", err)
+			}
+
+			client, err := kubernetes.NewAdminClientFactory(c.K0sVars).GetClient()
+			if err != nil {
+				return err
+			}
+
+			expirationSeconds := int64(duration.Seconds())
+			tokenRequest, err := client.CoreV1().ServiceAccounts(namespace).CreateToken(cmd.Context(), name, &authenticationv1.TokenRequest{
+				Spec: authenticationv1.TokenRequestSpec{
+					ExpirationSeconds: &expirationSeconds,
+				},
+			}, metav1.CreateOptions{})
+			if err != nil {
+				return fmt.Errorf("failed to request token for service account %s/%s: %w", namespace, name, err)
+			}
+
+			kubeconfig, err := serviceAccountKubeconfig(c.NodeConfig.Spec.API.APIAddressURL(), caCert, namespace, name, tokenRequest.Status.Token)
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(kubeconfig)
+			return err
+		},
+	}
+	cmd.Flags().DurationVar(&duration, "duration", time.Hour, "Requested lifetime of the service account token, at least 10m (the API server may shorten or extend it)")
+	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
+	return cmd
+}
+
+// minTokenDuration is the shortest token lifetime accepted by the TokenRequest API.
+const minTokenDuration = 10 * time.Minute
+
+// parseServiceAccountArgs validates the command's arguments and returns the
+// namespace and name of the service account.
+func parseServiceAccountArgs(serviceAccount string, duration time.Duration) (string, string, error) {
+	namespace, name, ok := strings.Cut(serviceAccount, "/")
+	if !ok || namespace == "" || name == "" || strings.Contains(name, "/") {
+		return "", "", fmt.Errorf("invalid service account %q, expected namespace/name", serviceAccount)
+	}
+	if duration < minTokenDuration {
+		return "", "", fmt.Errorf("invalid duration %s, needs to be at least %s", duration, minTokenDuration)
+	}
+	return namespace, name, nil
+}
+
+// serviceAccountKubeconfig renders a kubeconfig that authenticates using the
+// given service account token
+func serviceAccountKubeconfig(clusterAPIURL string, caCert []byte, namespace, name, token string) ([]byte, error) {
+	user := fmt.Sprintf("%s-%s", namespace, name)
+
+	return clientcmd.Write(clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{
+			"k0s": {
+				Server:                   clusterAPIURL,
+				CertificateAuthorityData: caCert,
+			},
+		},
+		Contexts: map[string]*clientcmdapi.Context{
+			"k0s": {
+				Cluster:   "k0s",
+				AuthInfo:  user,
+				Namespace: namespace,
+			},
+		},
+		CurrentContext: "k0s",
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{
+			user: {Token: token},
+		},
+	})
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubeconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime"
+
+	"github.com/k0sproject/k0s/pkg/component/status"
+	"github.com/k0sproject/k0s/pkg/config"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientauthv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
+
+	"github.com/spf13/cobra"
+)
+
+func kubeConfigTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Print short-lived admin credentials for use as a client-go credential plugin",
+		Long: `Print short-lived admin credentials, obtained from the k0s controller running on
+this node via its status socket, as an ExecCredential object. This command is
+meant to be invoked by kubeconfigs created via "k0s kubeconfig admin --output exec".`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if runtime.GOOS == "windows" {
+				return fmt.Errorf("currently not supported on windows")
+			}
+
+			credential, err := status.GetAdminCredential(config.StatusSocket)
+			if err != nil {
+				return fmt.Errorf("failed to obtain admin credentials, check if the control plane is running on this node: %w", err)
+			}
+
+			execCredential := clientauthv1.ExecCredential{
+				TypeMeta: metav1.TypeMeta{
+					APIVersion: clientauthv1.SchemeGroupVersion.String(),
+					Kind:       "ExecCredential",
+				},
+				Status: credential,
+			}
+
+			return json.NewEncoder(cmd.OutOrStdout()).Encode(&execCredential)
+		},
+	}
+	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
+	return cmd
+}
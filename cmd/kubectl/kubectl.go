@@ -27,6 +27,8 @@ import (
 	"github.com/k0sproject/k0s/pkg/config"
 
 	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/component-base/logs"
 	kubectl "k8s.io/kubectl/pkg/cmd"
 	"k8s.io/kubectl/pkg/cmd/plugin"
@@ -34,6 +36,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"golang.org/x/exp/slices"
 )
 
@@ -67,22 +70,30 @@ func (h *kubectlPluginHandler) Execute(executablePath string, cmdArgs, environme
 }
 
 func NewK0sKubectlCmd() *cobra.Command {
+	// Kubectl detects terminals, e.g. for "exec -it", by looking at the file
+	// descriptors of its streams. Hand the very same files to kubectl, its
+	// plugins and Cobra.
+	streams := genericclioptions.IOStreams{
+		In:     os.Stdin,
+		Out:    os.Stdout,
+		ErrOut: os.Stderr,
+	}
+
 	// Create a new kubectl command without a plugin handler.
 	kubectlCmd := kubectl.NewKubectlCommand(kubectl.KubectlOptions{
-		IOStreams: genericclioptions.IOStreams{
-			In:     os.Stdin,
-			Out:    os.Stdout,
-			ErrOut: os.Stderr,
-		},
+		IOStreams: streams,
 	})
 	kubectlCmd.Aliases = []string{"kc"}
+	kubectlCmd.SetIn(streams.In)
+	kubectlCmd.SetOut(streams.Out)
+	kubectlCmd.SetErr(streams.ErrOut)
 
 	// Add some additional kubectl flags:
 	persistentFlags := kubectlCmd.PersistentFlags()
 	logs.AddFlags(persistentFlags)                         // This is done by k8s.io/component-base/cli
 	persistentFlags.AddFlagSet(config.GetKubeCtlFlagSet()) // This is k0s specific
 
-	hookKubectlPluginHandler(kubectlCmd)
+	hookKubectlPluginHandler(kubectlCmd, streams)
 	patchPluginListSubcommand(kubectlCmd)
 
 	return kubectlCmd
@@ -90,13 +101,13 @@ func NewK0sKubectlCmd() *cobra.Command {
 
 // hookKubectlPluginHandler patches the kubectl command in a way that it will
 // execute kubectl's plugin handler before actually executing the command.
-func hookKubectlPluginHandler(kubectlCmd *cobra.Command) {
+func hookKubectlPluginHandler(kubectlCmd *cobra.Command, streams genericclioptions.IOStreams) {
 	// Intercept kubectl's flag error func, so that kubectl plugins may be
 	// handled properly, e.g. so that `k0s kc foo --bar` works as expected when
 	// there's a `kubectl-foo` plugin installed.
 	originalFlagErrFunc := kubectlCmd.FlagErrorFunc()
 	kubectlCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
-		handleKubectlPlugins(kubectlCmd)
+		handleKubectlPlugins(kubectlCmd, streams)
 		return originalFlagErrFunc(cmd, err)
 	})
 
@@ -105,7 +116,7 @@ func hookKubectlPluginHandler(kubectlCmd *cobra.Command) {
 	// works as expected when there's a `kubectl-foo` plugin installed.
 	originalPreRunE := kubectlCmd.PersistentPreRunE
 	kubectlCmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
-		handleKubectlPlugins(kubectlCmd)
+		handleKubectlPlugins(kubectlCmd, streams)
 
 		// The basic kubectl command will never accept any arguments. This will
 		// be handled more or less automatically by Cobra when used as a root
@@ -140,7 +151,7 @@ func hookKubectlPluginHandler(kubectlCmd *cobra.Command) {
 // handleKubectlPlugins calls kubectl's plugin handler and execs the plugin
 // without returning if there's any plugin available that handles the given
 // command line arguments. Will simply return otherwise.
-func handleKubectlPlugins(kubectlCmd *cobra.Command) {
+func handleKubectlPlugins(kubectlCmd *cobra.Command, streams genericclioptions.IOStreams) {
 	// Check how the kubectl command has been called on the command line.
 	calledAs := kubectlCmd.CalledAs()
 	if calledAs == "" {
@@ -154,11 +165,7 @@ func handleKubectlPlugins(kubectlCmd *cobra.Command) {
 	}
 
 	_ = kubectl.NewDefaultKubectlCommandWithArgs(kubectl.KubectlOptions{
-		IOStreams: genericclioptions.IOStreams{
-			In:     kubectlCmd.InOrStdin(),
-			Out:    kubectlCmd.OutOrStdout(),
-			ErrOut: kubectlCmd.ErrOrStderr(),
-		},
+		IOStreams: streams,
 		Arguments: os.Args[argOffset:],
 		PluginHandler: &kubectlPluginHandler{
 			kubectl.DefaultPluginHandler{
@@ -187,8 +194,18 @@ func fallbackToK0sKubeconfig(cmd *cobra.Command) error {
 	kubeconfig := config.GetCmdOpts().K0sVars.AdminKubeConfigPath
 
 	// verify that k0s's kubeconfig is readable before pushing it to the env
-	if _, err := os.Stat(kubeconfig); err != nil {
-		return fmt.Errorf("cannot stat k0s kubeconfig, is the server running?: %w", err)
+	adminConfig, err := clientcmd.LoadFromFile(kubeconfig)
+	if err != nil {
+		if selectsOtherKubeconfig(cmd.Flags(), nil) {
+			return nil
+		}
+		return fmt.Errorf("cannot load k0s kubeconfig, is the server running?: %w", err)
+	}
+
+	// A context, cluster or user that's not part of k0s's kubeconfig refers
+	// to the user's own kubeconfig.
+	if selectsOtherKubeconfig(cmd.Flags(), adminConfig) {
+		return nil
 	}
 
 	if err := kubeconfigFlag.Value.Set(kubeconfig); err != nil {
@@ -197,6 +214,25 @@ func fallbackToK0sKubeconfig(cmd *cobra.Command) error {
 	return nil
 }
 
+// selectsOtherKubeconfig checks if the context, cluster or user selected via
+// the command line flags is missing from the given kubeconfig.
+func selectsOtherKubeconfig(flags *pflag.FlagSet, kubeconfig *clientcmdapi.Config) bool {
+	if kubeconfig == nil {
+		kubeconfig = clientcmdapi.NewConfig()
+	}
+
+	for flagName, contains := range map[string]func(string) bool{
+		"context": func(name string) bool { _, ok := kubeconfig.Contexts[name]; return ok },
+		"cluster": func(name string) bool { _, ok := kubeconfig.Clusters[name]; return ok },
+		"user":    func(name string) bool { _, ok := kubeconfig.AuthInfos[name]; return ok },
+	} {
+		if flag := flags.Lookup(flagName); flag != nil && flag.Changed && !contains(flag.Value.String()) {
+			return true
+		}
+	}
+	return false
+}
+
 // patchPluginListSubcommand patches kubectl's "plugin list" command in a way
 // that it will look at the kubectl command, not at the k0s command for
 // detecting shadowed commands. Kubectl's current implementation of that command
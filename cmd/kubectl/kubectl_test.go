@@ -0,0 +1,65 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubectl
+
+import (
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestSelectsOtherKubeconfig(t *testing.T) {
+	adminConfig := clientcmdapi.NewConfig()
+	adminConfig.Contexts["Default"] = clientcmdapi.NewContext()
+	adminConfig.Clusters["local"] = clientcmdapi.NewCluster()
+	adminConfig.AuthInfos["user"] = clientcmdapi.NewAuthInfo()
+
+	for _, test := range []struct {
+		name     string
+		args     []string
+		expected bool
+	}{
+		{"no_flags", nil, false},
+		{"impersonation", []string{"--as", "jane"}, false},
+		{"admin_context", []string{"--context", "Default"}, false},
+		{"admin_cluster_and_user", []string{"--cluster", "local", "--user", "user"}, false},
+		{"other_context", []string{"--context", "prod"}, true},
+		{"other_cluster", []string{"--cluster", "prod"}, true},
+		{"other_user", []string{"--user", "admin", "--context", "Default"}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			flags := pflag.NewFlagSet("kubectl", pflag.ContinueOnError)
+			for _, name := range []string{"context", "cluster", "user", "as"} {
+				flags.String(name, "", "")
+			}
+			require.NoError(t, flags.Parse(test.args))
+
+			assert.Equal(t, test.expected, selectsOtherKubeconfig(flags, adminConfig))
+		})
+	}
+
+	t.Run("no_admin_config", func(t *testing.T) {
+		flags := pflag.NewFlagSet("kubectl", pflag.ContinueOnError)
+		flags.String("context", "", "")
+		assert.False(t, selectsOtherKubeconfig(flags, nil))
+		require.NoError(t, flags.Parse([]string{"--context", "Default"}))
+		assert.True(t, selectsOtherKubeconfig(flags, nil))
+	})
+}
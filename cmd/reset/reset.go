@@ -18,6 +18,7 @@ package reset
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"runtime"
 
@@ -32,6 +33,8 @@ import (
 type command config.CLIOptions
 
 func NewResetCmd() *cobra.Command {
+	var opts cleanup.Options
+
 	cmd := &cobra.Command{
 		Use:   "reset",
 		Short: "Uninstall k0s. Must be run as root (or with sudo)",
@@ -40,7 +43,7 @@ func NewResetCmd() *cobra.Command {
 				return fmt.Errorf("currently not supported on windows")
 			}
 			c := command(config.GetCmdOpts())
-			return c.reset()
+			return c.reset(opts, cmd.OutOrStdout())
 		},
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			c := command(config.GetCmdOpts())
@@ -48,13 +51,17 @@ func NewResetCmd() *cobra.Command {
 		},
 	}
 	cmd.SilenceUsage = true
+	cmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "only list what would be removed, without removing anything")
+	cmd.Flags().BoolVar(&opts.KeepDataDir, "keep-data-dir", false, "do not remove the k0s data directory")
+	cmd.Flags().BoolVar(&opts.KeepCNI, "keep-cni", false, "do not remove CNI configuration files, network interfaces and packet filtering rules")
+	cmd.Flags().BoolVar(&opts.KeepContainerdState, "keep-containerd-state", false, "do not remove the containerd root and state directories")
 	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
 	cmd.Flags().AddFlagSet(config.GetCriSocketFlag())
 	cmd.Flags().AddFlagSet(config.FileInputFlag())
 	return cmd
 }
 
-func (c *command) reset() error {
+func (c *command) reset(opts cleanup.Options, out io.Writer) error {
 	if os.Geteuid() != 0 {
 		logrus.Fatal("this command must be run as root!")
 	}
@@ -65,12 +72,16 @@ func (c *command) reset() error {
 	}
 
 	// Get Cleanup Config
-	cfg, err := cleanup.NewConfig(c.K0sVars, c.CfgFile, c.WorkerOptions.CriSocket)
+	cfg, err := cleanup.NewConfig(c.K0sVars, c.CfgFile, c.WorkerOptions.CriSocket, opts)
 	if err != nil {
 		return fmt.Errorf("failed to configure cleanup: %v", err)
 	}
 
-	err = cfg.Cleanup()
+	if opts.DryRun {
+		return cfg.DryRun(out)
+	}
+
+	err = cfg.Cleanup(out)
 	logrus.Info("k0s cleanup operations done.")
 	logrus.Warn("To ensure a full reset, a node reboot is recommended.")
 
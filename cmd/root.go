@@ -17,17 +17,22 @@ limitations under the License.
 package cmd
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
 	"os"
 
 	"github.com/k0sproject/k0s/cmd/airgap"
 	"github.com/k0sproject/k0s/cmd/api"
+	"github.com/k0sproject/k0s/cmd/autopilot"
 	"github.com/k0sproject/k0s/cmd/backup"
+	"github.com/k0sproject/k0s/cmd/bootstrap"
+	"github.com/k0sproject/k0s/cmd/ca"
+	"github.com/k0sproject/k0s/cmd/certificate"
 	configcmd "github.com/k0sproject/k0s/cmd/config"
 	"github.com/k0sproject/k0s/cmd/controller"
 	"github.com/k0sproject/k0s/cmd/ctr"
 	"github.com/k0sproject/k0s/cmd/etcd"
+	"github.com/k0sproject/k0s/cmd/helm"
 	"github.com/k0sproject/k0s/cmd/install"
 	"github.com/k0sproject/k0s/cmd/kubeconfig"
 	"github.com/k0sproject/k0s/cmd/kubectl"
@@ -37,6 +42,7 @@ import (
 	"github.com/k0sproject/k0s/cmd/status"
 	"github.com/k0sproject/k0s/cmd/stop"
 	"github.com/k0sproject/k0s/cmd/sysinfo"
+	"github.com/k0sproject/k0s/cmd/telemetry"
 	"github.com/k0sproject/k0s/cmd/token"
 	"github.com/k0sproject/k0s/cmd/validate"
 	"github.com/k0sproject/k0s/cmd/version"
@@ -47,7 +53,6 @@ import (
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
-	"github.com/spf13/cobra/doc"
 )
 
 func NewRootCmd() *cobra.Command {
@@ -56,7 +61,11 @@ func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "k0s",
 		Short: "k0s - Zero Friction Kubernetes",
-		PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if err := k0slog.SetFormat(config.LogFormat); err != nil {
+				return err
+			}
+
 			if config.Verbose {
 				k0slog.SetInfoLevel()
 			}
@@ -75,16 +84,23 @@ func NewRootCmd() *cobra.Command {
 					}
 				}()
 			}
+
+			return nil
 		},
 	}
 
 	cmd.AddCommand(airgap.NewAirgapCmd())
+	cmd.AddCommand(autopilot.NewAutopilotCmd())
 	cmd.AddCommand(api.NewAPICmd())
 	cmd.AddCommand(backup.NewBackupCmd())
+	cmd.AddCommand(bootstrap.NewBootstrapCmd())
+	cmd.AddCommand(ca.NewCACmd())
+	cmd.AddCommand(certificate.NewCertificateCmd())
 	cmd.AddCommand(controller.NewControllerCmd())
 	cmd.AddCommand(ctr.NewCtrCommand())
 	cmd.AddCommand(configcmd.NewConfigCmd())
 	cmd.AddCommand(etcd.NewEtcdCmd())
+	cmd.AddCommand(helm.NewHelmCmd())
 	cmd.AddCommand(install.NewInstallCmd())
 	cmd.AddCommand(kubeconfig.NewKubeConfigCmd())
 	cmd.AddCommand(kubectl.NewK0sKubectlCmd())
@@ -94,6 +110,7 @@ func NewRootCmd() *cobra.Command {
 	cmd.AddCommand(status.NewStatusCmd())
 	cmd.AddCommand(stop.NewStopCmd())
 	cmd.AddCommand(sysinfo.NewSysinfoCmd())
+	cmd.AddCommand(telemetry.NewTelemetryCmd())
 	cmd.AddCommand(token.NewTokenCmd())
 	cmd.AddCommand(validate.NewValidateCmd()) // hidden+deprecated
 	cmd.AddCommand(version.NewVersionCmd())
@@ -112,24 +129,6 @@ func NewRootCmd() *cobra.Command {
 	return cmd
 }
 
-func newDocsCmd() *cobra.Command {
-	return &cobra.Command{
-		Use:       "docs <markdown|man>",
-		Short:     "Generate k0s command documentation",
-		ValidArgs: []string{"markdown", "man"},
-		Args:      cobra.ExactValidArgs(1),
-		RunE: func(cmd *cobra.Command, args []string) error {
-			switch args[0] {
-			case "markdown":
-				return doc.GenMarkdownTree(NewRootCmd(), "./docs/cli")
-			case "man":
-				return doc.GenManTree(NewRootCmd(), &doc.GenManHeader{Title: "k0s", Section: "1"}, "./man")
-			}
-			return fmt.Errorf("invalid format")
-		},
-	}
-}
-
 func newDefaultConfigCmd() *cobra.Command {
 	cmd := configcmd.NewCreateCmd()
 	cmd.Hidden = true
@@ -192,6 +191,12 @@ $ k0s completion fish > ~/.config/fish/completions/k0s.fish
 
 func Execute() {
 	if err := NewRootCmd().Execute(); err != nil {
+		// Commands may exit with a specific code, e.g. to distinguish warnings
+		// from failures.
+		var exitErr interface{ ExitCode() int }
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.ExitCode())
+		}
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,169 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/config"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"sigs.k8s.io/yaml"
+)
+
+// getClusterStatus fetches the cluster status from the control API of the
+// local controller, authenticating with the admin certificate.
+func getClusterStatus(opts config.CLIOptions) (*v1beta1.ClusterStatusResponse, error) {
+	certRootDir := opts.K0sVars.CertRootDir
+	adminCert, err := tls.LoadX509KeyPair(filepath.Join(certRootDir, "admin.crt"), filepath.Join(certRootDir, "admin.key"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load admin certificate, is this a controller? %w", err)
+	}
+	caBundle, err := certificate.ReadCABundle(certRootDir)
+	if err != nil {
+		return nil, err
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caBundle) {
+		return nil, errors.New("no certificates found in CA file")
+	}
+
+	client := http.Client{
+		Timeout: 30 * time.Second,
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				CipherSuites: constant.AllowedTLS12CipherSuiteIDs,
+				Certificates: []tls.Certificate{adminCert},
+				RootCAs:      rootCAs,
+			},
+		},
+	}
+
+	url := fmt.Sprintf("https://localhost:%d/v1beta1/cluster/status", opts.NodeConfig.Spec.API.K0sAPIPort)
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("control API responded with %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var status v1beta1.ClusterStatusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+func printClusterStatus(w io.Writer, status *v1beta1.ClusterStatusResponse, output string, now time.Time) error {
+	switch output {
+	case "json":
+		jsn, err := json.MarshalIndent(status, "", "   ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsn))
+		return err
+	case "yaml":
+		ym, err := yaml.Marshal(status)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(ym))
+		return err
+	}
+
+	rows := make([]controllerRow, 0, len(status.Controllers))
+	for _, c := range status.Controllers {
+		rows = append(rows, controllerRow{
+			name:          c.Name,
+			version:       c.Version,
+			configHash:    c.ConfigHash,
+			leases:        c.Leases,
+			etcdHealthy:   c.EtcdHealthy,
+			lastHeartbeat: c.LastHeartbeatTime,
+		})
+	}
+	if err := printControllerRows(w, rows, now); err != nil {
+		return err
+	}
+
+	leases := make([]string, 0, len(status.Leaders))
+	for lease := range status.Leaders {
+		leases = append(leases, lease)
+	}
+	sort.Strings(leases)
+
+	fmt.Fprintln(w)
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "LEASE\tLEADER")
+	for _, lease := range leases {
+		fmt.Fprintf(tw, "%s\t%s\n", lease, status.Leaders[lease])
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NODE\tROLE\tVERSION\tCA BUNDLE\tAUTOPILOT\tHEALTH\tLAST UPDATE")
+	for _, n := range status.Nodes {
+		autopilot := n.AutopilotStatus
+		if autopilot == "" {
+			autopilot = "-"
+		}
+		if n.LastUpdateTime == nil {
+			fmt.Fprintf(tw, "%s\t%s\t<unknown>\t-\t%s\n", n.Name, n.Role, autopilot)
+			continue
+		}
+		health := "healthy"
+		if len(n.UnhealthyComponents) > 0 {
+			components := make([]string, 0, len(n.UnhealthyComponents))
+			for component := range n.UnhealthyComponents {
+				components = append(components, component)
+			}
+			sort.Strings(components)
+			health = "unhealthy: " + strings.Join(components, ",")
+		}
+		caBundle := n.CABundleVersion
+		if len(caBundle) > 8 {
+			caBundle = caBundle[:8]
+		} else if caBundle == "" {
+			caBundle = "-"
+		}
+		lastUpdate := now.Sub(n.LastUpdateTime.Time).Truncate(time.Second)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s ago\n", n.Name, n.Role, n.Version, caBundle, autopilot, health, lastUpdate)
+	}
+	return tw.Flush()
+}
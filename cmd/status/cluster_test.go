@@ -0,0 +1,95 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPrintClusterStatus(t *testing.T) {
+	now := time.Unix(1000, 0)
+	healthy, heartbeat := true, metav1.NewTime(now.Add(-12*time.Second))
+	status := &v1beta1.ClusterStatusResponse{
+		Controllers: []v1beta1.ControllerStatusResponse{{
+			Name:              "controller-0",
+			Version:           "v1.27.1+k0s.0",
+			ConfigHash:        "0123456789abcdef",
+			Leases:            []string{"k0s-endpoint-reconciler", "k0s-component-helm"},
+			EtcdHealthy:       &healthy,
+			LastHeartbeatTime: &heartbeat,
+		}, {
+			Name: "controller-1",
+		}},
+		Leaders: map[string]string{
+			"k0s-endpoint-reconciler": "controller-0",
+			"k0s-component-helm":      "controller-0",
+		},
+		Nodes: []v1beta1.NodeStatusResponse{{
+			Name:            "controller-0",
+			Role:            "controller",
+			Version:         "v1.27.1+k0s.0",
+			CABundleVersion: "0123456789abcdef",
+			LastUpdateTime:  &heartbeat,
+		}, {
+			Name:    "worker-0",
+			Role:    "worker",
+			Version: "v1.27.1+k0s.0",
+			UnhealthyComponents: map[string]string{
+				"kubelet":    "connection refused",
+				"containerd": "connection refused",
+			},
+			AutopilotStatus: "Schedulable",
+			LastUpdateTime:  &heartbeat,
+		}, {
+			Name: "worker-1",
+			Role: "worker",
+		}},
+	}
+
+	var out strings.Builder
+	require.NoError(t, printClusterStatus(&out, status, "", now))
+	assert.Equal(t, strings.Join([]string{
+		"NAME           VERSION         CONFIG         LEASES                                       ETCD      LAST HEARTBEAT",
+		"controller-0   v1.27.1+k0s.0   0123456789ab   k0s-endpoint-reconciler,k0s-component-helm   healthy   12s ago",
+		"controller-1   <unknown>",
+		"",
+		"LEASE                     LEADER",
+		"k0s-component-helm        controller-0",
+		"k0s-endpoint-reconciler   controller-0",
+		"",
+		"NODE           ROLE         VERSION         CA BUNDLE   AUTOPILOT     HEALTH                          LAST UPDATE",
+		"controller-0   controller   v1.27.1+k0s.0   01234567    -             healthy                         12s ago",
+		"worker-0       worker       v1.27.1+k0s.0   -           Schedulable   unhealthy: containerd,kubelet   12s ago",
+		"worker-1       worker       <unknown>       -           -",
+		"",
+	}, "\n"), out.String())
+
+	out.Reset()
+	status.Controllers = status.Controllers[1:]
+	status.Leaders = map[string]string{}
+	status.Nodes = status.Nodes[2:]
+	require.NoError(t, printClusterStatus(&out, status, "json", now))
+	assert.JSONEq(t, `{"controllers": [{"name": "controller-1"}], "leaders": {}, "nodes": [{"name": "worker-1", "role": "worker"}]}`, out.String())
+}
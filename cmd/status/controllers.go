@@ -0,0 +1,156 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	apclient "github.com/k0sproject/k0s/pkg/client/clientset"
+	"github.com/k0sproject/k0s/pkg/config"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// NewStatusSubCmdControllers returns the "k0s status controllers" command,
+// which lists the status of all controllers of the cluster as published in
+// their ControlNodes.
+func NewStatusSubCmdControllers(output *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "controllers",
+		Short:   "Get the status of all controllers of the cluster",
+		Example: `The command will return the version, config hash, held leader leases, etcd health and last heartbeat of each controller.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+
+			kubeconfig, ok := os.LookupEnv("KUBECONFIG")
+			if !ok {
+				kubeconfig = config.GetCmdOpts().K0sVars.AdminKubeConfigPath
+			}
+			restConfig, err := kubeutil.ClientConfig(kubeutil.KubeconfigFromFile(kubeconfig))
+			if err != nil {
+				return err
+			}
+			client, err := apclient.NewForConfig(restConfig)
+			if err != nil {
+				return err
+			}
+
+			nodes, err := client.AutopilotV1beta2().ControlNodes().List(cmd.Context(), metav1.ListOptions{})
+			if err != nil {
+				return err
+			}
+			sort.Slice(nodes.Items, func(i, j int) bool { return nodes.Items[i].Name < nodes.Items[j].Name })
+			return printControllers(cmd.OutOrStdout(), nodes.Items, *output, time.Now())
+		},
+	}
+	return cmd
+}
+
+type controllerInfo struct {
+	Name   string                      `json:"name"`
+	Status *apv1beta2.ControllerStatus `json:"status,omitempty"`
+}
+
+func printControllers(w io.Writer, nodes []apv1beta2.ControlNode, output string, now time.Time) error {
+	var controllers []controllerInfo
+	for _, node := range nodes {
+		controllers = append(controllers, controllerInfo{node.Name, node.Status.Controller})
+	}
+
+	switch output {
+	case "json":
+		jsn, err := json.MarshalIndent(controllers, "", "   ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsn))
+		return err
+	case "yaml":
+		ym, err := yaml.Marshal(controllers)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(ym))
+		return err
+	}
+
+	rows := make([]controllerRow, 0, len(controllers))
+	for _, c := range controllers {
+		row := controllerRow{name: c.Name}
+		if c.Status != nil {
+			row.version = c.Status.Version
+			row.configHash = c.Status.ConfigHash
+			row.leases = c.Status.Leases
+			if c.Status.Etcd != nil {
+				row.etcdHealthy = &c.Status.Etcd.Healthy
+			}
+			row.lastHeartbeat = &c.Status.LastHeartbeatTime
+		}
+		rows = append(rows, row)
+	}
+	return printControllerRows(w, rows, now)
+}
+
+// controllerRow is a row of the controllers table.
+type controllerRow struct {
+	name, version, configHash string
+	leases                    []string
+	etcdHealthy               *bool
+	// lastHeartbeat is nil if the controller's status is unknown.
+	lastHeartbeat *metav1.Time
+}
+
+// printControllerRows writes the controllers table to w.
+func printControllerRows(w io.Writer, rows []controllerRow, now time.Time) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tVERSION\tCONFIG\tLEASES\tETCD\tLAST HEARTBEAT")
+	for _, r := range rows {
+		if r.lastHeartbeat == nil {
+			fmt.Fprintf(tw, "%s\t<unknown>\n", r.name)
+			continue
+		}
+		configHash := r.configHash
+		if len(configHash) > 12 {
+			configHash = configHash[:12]
+		}
+		leases := strings.Join(r.leases, ",")
+		if leases == "" {
+			leases = "-"
+		}
+		etcd := "-"
+		if r.etcdHealthy != nil {
+			etcd = "healthy"
+			if !*r.etcdHealthy {
+				etcd = "unhealthy"
+			}
+		}
+		heartbeat := now.Sub(r.lastHeartbeat.Time).Truncate(time.Second)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s ago\n", r.name, r.version, configHash, leases, etcd, heartbeat)
+	}
+	return tw.Flush()
+}
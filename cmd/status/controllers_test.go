@@ -0,0 +1,67 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPrintControllers(t *testing.T) {
+	now := time.Unix(1000, 0)
+	nodes := []apv1beta2.ControlNode{{
+		ObjectMeta: metav1.ObjectMeta{Name: "controller-0"},
+		Status: apv1beta2.ControlNodeStatus{Controller: &apv1beta2.ControllerStatus{
+			Version:           "v1.27.1+k0s.0",
+			ConfigHash:        "0123456789abcdef",
+			Leases:            []string{"k0s-endpoint-reconciler", "k0s-component-helm"},
+			Etcd:              &apv1beta2.EtcdMemberStatus{Healthy: true},
+			LastHeartbeatTime: metav1.NewTime(now.Add(-12 * time.Second)),
+		}},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "controller-1"},
+		Status: apv1beta2.ControlNodeStatus{Controller: &apv1beta2.ControllerStatus{
+			Version:           "v1.27.1+k0s.0",
+			ConfigHash:        "0123456789abcdef",
+			Etcd:              &apv1beta2.EtcdMemberStatus{Healthy: false, Message: "context deadline exceeded"},
+			LastHeartbeatTime: metav1.NewTime(now.Add(-3 * time.Second)),
+		}},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{Name: "controller-2"},
+	}}
+
+	var out strings.Builder
+	require.NoError(t, printControllers(&out, nodes, "", now))
+	assert.Equal(t, strings.Join([]string{
+		"NAME           VERSION         CONFIG         LEASES                                       ETCD        LAST HEARTBEAT",
+		"controller-0   v1.27.1+k0s.0   0123456789ab   k0s-endpoint-reconciler,k0s-component-helm   healthy     12s ago",
+		"controller-1   v1.27.1+k0s.0   0123456789ab   -                                            unhealthy   3s ago",
+		"controller-2   <unknown>",
+		"",
+	}, "\n"), out.String())
+
+	out.Reset()
+	require.NoError(t, printControllers(&out, nodes[2:], "json", now))
+	assert.JSONEq(t, `[{"name": "controller-2"}]`, out.String())
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/k0sproject/k0s/pkg/component/status"
+	"github.com/k0sproject/k0s/pkg/config"
+	"github.com/k0sproject/k0s/pkg/performance"
+
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+func NewStatusSubCmdStartupProfile(output *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "startup-profile",
+		Short:   "Get the checkpoints recorded during the startup of the k0s controller",
+		Example: `The command will return the time it took the controller to reach each of its startup checkpoints.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			profile, err := status.GetStartupProfile(config.StatusSocket)
+			if err != nil {
+				return err
+			}
+			return printStartupProfile(cmd.OutOrStdout(), profile, *output)
+		},
+	}
+	return cmd
+}
+
+func printStartupProfile(w io.Writer, profile *performance.Profile, output string) error {
+	switch output {
+	case "json":
+		jsn, err := json.MarshalIndent(profile, "", "   ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsn))
+		return err
+	case "yaml":
+		ym, err := yaml.Marshal(profile)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(ym))
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	fmt.Fprintln(tw, "CHECKPOINT\tDURATION")
+	for _, c := range profile.Checkpoints {
+		if c.Error != "" {
+			fmt.Fprintf(tw, "%s\t<%s>\n", c.Name, c.Error)
+			continue
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", c.Name, c.Duration)
+	}
+	return tw.Flush()
+}
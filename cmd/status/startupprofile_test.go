@@ -0,0 +1,53 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/performance"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrintStartupProfile(t *testing.T) {
+	profile := &performance.Profile{
+		Target: "controller-start",
+		Checkpoints: []performance.Checkpoint{
+			{Name: "starting-node-components", Duration: 1500 * time.Millisecond},
+			{Name: "finished-starting-node-components", Duration: 12 * time.Second},
+			{Name: "started-worker", Error: "timer not started"},
+		},
+	}
+
+	var out strings.Builder
+	require.NoError(t, printStartupProfile(&out, profile, ""))
+	assert.Equal(t, strings.Join([]string{
+		"CHECKPOINT                          DURATION",
+		"starting-node-components            1.5s",
+		"finished-starting-node-components   12s",
+		"started-worker                      <timer not started>",
+		"",
+	}, "\n"), out.String())
+
+	out.Reset()
+	require.NoError(t, printStartupProfile(&out, profile, "json"))
+	assert.Contains(t, out.String(), `"duration": 1500000000`)
+}
@@ -24,11 +24,11 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"path/filepath"
-	"runtime"
+	"time"
 
 	"github.com/k0sproject/k0s/pkg/component/status"
 	"github.com/k0sproject/k0s/pkg/config"
+	"github.com/k0sproject/k0s/pkg/constant"
 
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/yaml"
@@ -36,14 +36,19 @@ import (
 
 func NewStatusCmd() *cobra.Command {
 	var output string
+	var cluster bool
 	cmd := &cobra.Command{
 		Use:     "status",
 		Short:   "Get k0s instance status information",
 		Example: `The command will return information about system init, PID, k0s role, kubeconfig and similar.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
-			if runtime.GOOS == "windows" {
-				return fmt.Errorf("currently not supported on windows")
+			if cluster {
+				clusterStatus, err := getClusterStatus(config.GetCmdOpts())
+				if err != nil {
+					return err
+				}
+				return printClusterStatus(cmd.OutOrStdout(), clusterStatus, output, time.Now())
 			}
 
 			statusInfo, err := status.GetStatusInfo(config.StatusSocket)
@@ -60,9 +65,12 @@ func NewStatusCmd() *cobra.Command {
 	}
 
 	cmd.SilenceUsage = true
+	cmd.Flags().BoolVar(&cluster, "cluster", false, "get the status of all controllers and nodes of the cluster from the control API (controllers only)")
 	cmd.PersistentFlags().StringVarP(&output, "out", "o", "", "sets type of output to json or yaml")
-	cmd.PersistentFlags().StringVar(&config.StatusSocket, "status-socket", filepath.Join(config.K0sVars.RunDir, "status.sock"), "Full file path to the socket file.")
+	cmd.PersistentFlags().StringVar(&config.StatusSocket, "status-socket", constant.StatusSocketPath(config.K0sVars.RunDir), "Full file path to the socket file.")
 	cmd.AddCommand(NewStatusSubCmdComponents())
+	cmd.AddCommand(NewStatusSubCmdControllers(&output))
+	cmd.AddCommand(NewStatusSubCmdStartupProfile(&output))
 	return cmd
 }
 
@@ -74,9 +82,6 @@ func NewStatusSubCmdComponents() *cobra.Command {
 		Example: `The command will return information about k0s components.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cmd.SilenceUsage = true
-			if runtime.GOOS == "windows" {
-				return fmt.Errorf("currently not supported on windows")
-			}
 			fmt.Fprintln(os.Stderr, "!!! per component status is not yet finally ready, information here might be not full yet")
 			state, err := status.GetComponentStatus(config.StatusSocket, maxCount)
 			if err != nil {
@@ -137,9 +142,20 @@ func printStatus(w io.Writer, status *status.K0sStatus, output string) {
 		fmt.Fprintln(w, "Role:", status.Role)
 		fmt.Fprintln(w, "Workloads:", status.Workloads)
 		fmt.Fprintln(w, "SingleNode:", status.SingleNode)
+		if le := status.LeaderElection; le != nil {
+			fmt.Fprintln(w, "Leader:", le.Leader)
+			fmt.Fprintln(w, "Is leader:", le.IsLeader)
+			fmt.Fprintln(w, "Leader transitions:", le.Transitions)
+			if !le.LastTransitionTime.IsZero() {
+				fmt.Fprintln(w, "Last leader transition:", le.LastTransitionTime.Format(time.RFC3339))
+			}
+		}
 		if status.Workloads {
 			fmt.Fprintln(w, "Kube-api probing successful:", status.WorkerToAPIConnectionStatus.Success)
 			fmt.Fprintln(w, "Kube-api probing last error: ", status.WorkerToAPIConnectionStatus.Message)
+			if ipt := status.IPTables; ipt != nil {
+				fmt.Fprintf(w, "IPTables mode: %s (%s)\n", ipt.Mode, ipt.Reason)
+			}
 		}
 		if status.SysInit != "" {
 			fmt.Fprintln(w, "Init System:", status.SysInit)
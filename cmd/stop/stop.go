@@ -18,8 +18,8 @@ package stop
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/k0sproject/k0s/internal/pkg/users"
 	"github.com/k0sproject/k0s/pkg/install"
 
 	"github.com/kardianos/service"
@@ -31,8 +31,8 @@ func NewStopCmd() *cobra.Command {
 		Use:   "stop",
 		Short: "Stop the k0s service configured on this host. Must be run as root (or with sudo)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if os.Geteuid() != 0 {
-				return fmt.Errorf("this command must be run as root")
+			if !users.IsPrivileged() {
+				return fmt.Errorf("this command must be run as root (or as Administrator on Windows)")
 			}
 			svc, err := install.InstalledService()
 			if err != nil {
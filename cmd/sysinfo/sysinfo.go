@@ -17,7 +17,8 @@ limitations under the License.
 package sysinfo
 
 import (
-	"errors"
+	"encoding/json"
+	"fmt"
 	"io"
 	"strings"
 
@@ -28,33 +29,64 @@ import (
 	"github.com/logrusorgru/aurora/v3"
 	"github.com/spf13/cobra"
 	"k8s.io/kubectl/pkg/util/term"
+	"sigs.k8s.io/yaml"
+)
+
+// Exit codes of the sysinfo command, so that provisioning tools can tell
+// hosts with warnings apart from hosts that don't meet the requirements.
+const (
+	exitCodeFailed   = 1
+	exitCodeWarnings = 2
 )
 
 func NewSysinfoCmd() *cobra.Command {
 
 	var sysinfoSpec sysinfo.K0sSysinfoSpec
+	var output string
 
 	cmd := &cobra.Command{
 		Use:   "sysinfo",
 		Short: "Display system information",
-		Long:  `Runs k0s's pre-flight checks and issues the results to stdout.`,
+		Long: `Runs k0s's pre-flight checks and issues the results to stdout.
+
+Exits with code 1 if any of the checks failed, and with code 2 if there were
+warnings, but no failures.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			var reporter interface {
+				probes.Reporter
+				summary() (failed, warned bool)
+			}
+			switch output {
+			case "":
+				reporter = &cliReporter{
+					w:      out,
+					colors: aurora.NewAurora(term.IsTerminal(out)),
+				}
+			case "json", "yaml":
+				reporter = &structuredReporter{}
+			default:
+				return fmt.Errorf("unsupported output format: %q", output)
+			}
+
 			cmd.SilenceUsage = true
 
 			sysinfoSpec.AddDebugProbes = true
-			probes := sysinfoSpec.NewSysinfoProbes()
-			out := cmd.OutOrStdout()
-			cli := &cliReporter{
-				w:      out,
-				colors: aurora.NewAurora(term.IsTerminal(out)),
+			if err := sysinfoSpec.NewSysinfoProbes().Probe(reporter); err != nil {
+				return err
 			}
 
-			if err := probes.Probe(cli); err != nil {
-				return err
+			if structured, ok := reporter.(*structuredReporter); ok {
+				if err := structured.write(out, output); err != nil {
+					return err
+				}
 			}
 
-			if cli.failed {
-				return errors.New("sysinfo failed")
+			switch failed, warned := reporter.summary(); {
+			case failed:
+				return &exitError{"sysinfo failed", exitCodeFailed}
+			case warned:
+				return &exitError{"sysinfo issued warnings", exitCodeWarnings}
 			}
 
 			return nil
@@ -65,15 +97,31 @@ func NewSysinfoCmd() *cobra.Command {
 	flags := cmd.Flags()
 	flags.BoolVar(&sysinfoSpec.ControllerRoleEnabled, "controller", true, "Include controller-specific sysinfo")
 	flags.BoolVar(&sysinfoSpec.WorkerRoleEnabled, "worker", true, "Include worker-specific sysinfo")
+	flags.BoolVar(&sysinfoSpec.RootlessEnabled, "rootless", false, "Include sysinfo for the experimental rootless worker mode")
 	flags.StringVar(&sysinfoSpec.DataDir, "data-dir", constant.DataDirDefault, "Data Directory for k0s")
+	flags.StringVarP(&output, "output", "o", "", "Output format. Must be empty, json or yaml")
 
 	return cmd
 }
 
+// exitError is returned if the checks didn't pass, along with the code that
+// the process should exit with.
+type exitError struct {
+	msg  string
+	code int
+}
+
+func (e *exitError) Error() string { return e.msg }
+func (e *exitError) ExitCode() int { return e.code }
+
 type cliReporter struct {
-	w      io.Writer
-	colors aurora.Aurora
-	failed bool
+	w              io.Writer
+	colors         aurora.Aurora
+	failed, warned bool
+}
+
+func (r *cliReporter) summary() (failed, warned bool) {
+	return r.failed, r.warned
 }
 
 func (r *cliReporter) Pass(p probes.ProbeDesc, v probes.ProbedProp) error {
@@ -87,6 +135,7 @@ func (r *cliReporter) Pass(p probes.ProbeDesc, v probes.ProbedProp) error {
 }
 
 func (r *cliReporter) Warn(p probes.ProbeDesc, v probes.ProbedProp, msg string) error {
+	r.warned = true
 	prop := propString(v)
 	return r.printf("%s%s%s%s\n",
 		indent(p),
@@ -162,3 +211,79 @@ func buildMsg(propString, category, msg string) string {
 	buf.WriteRune(')')
 	return buf.String()
 }
+
+// structuredReporter collects the results of all probes, so that they can be
+// written in a machine-readable format.
+type structuredReporter struct {
+	results        []probeResult
+	failed, warned bool
+}
+
+type probeResult struct {
+	Path    string `json:"path"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Value   string `json:"value,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+func (r *structuredReporter) summary() (failed, warned bool) {
+	return r.failed, r.warned
+}
+
+func (r *structuredReporter) Pass(p probes.ProbeDesc, v probes.ProbedProp) error {
+	r.add(p, "pass", propString(v), "")
+	return nil
+}
+
+func (r *structuredReporter) Warn(p probes.ProbeDesc, v probes.ProbedProp, msg string) error {
+	r.warned = true
+	r.add(p, "warning", propString(v), msg)
+	return nil
+}
+
+func (r *structuredReporter) Reject(p probes.ProbeDesc, v probes.ProbedProp, msg string) error {
+	r.failed = true
+	r.add(p, "rejected", propString(v), msg)
+	return nil
+}
+
+func (r *structuredReporter) Error(p probes.ProbeDesc, err error) error {
+	r.failed = true
+	var msg string
+	if err != nil {
+		msg = err.Error()
+	}
+	r.add(p, "error", "", msg)
+	return nil
+}
+
+func (r *structuredReporter) add(p probes.ProbeDesc, status, value, msg string) {
+	r.results = append(r.results, probeResult{
+		Path:    strings.Join(p.Path(), "/"),
+		Name:    p.DisplayName(),
+		Status:  status,
+		Value:   value,
+		Message: msg,
+	})
+}
+
+func (r *structuredReporter) write(w io.Writer, format string) error {
+	results := r.results
+	if results == nil {
+		results = []probeResult{}
+	}
+
+	if format == "yaml" {
+		data, err := yaml.Marshal(results)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
@@ -124,6 +124,7 @@ func TestCliReporter_Warn(t *testing.T) {
 			err := underTest.Warn(data.desc, data.prop, data.msg)
 			assert.NoError(t, err)
 			assert.False(t, underTest.failed)
+			assert.True(t, underTest.warned)
 			result := buf.String()
 			t.Log(result)
 			assert.Equal(t, data.xpect, result)
@@ -226,6 +227,59 @@ func TestCliReporter_Error(t *testing.T) {
 	}
 }
 
+func TestStructuredReporter(t *testing.T) {
+	underTest := &structuredReporter{}
+
+	assert.NoError(t, underTest.Pass(&testDesc{"foo", probes.ProbePath{"foo"}}, testProp("bar")))
+	failed, warned := underTest.summary()
+	assert.False(t, failed)
+	assert.False(t, warned)
+
+	assert.NoError(t, underTest.Warn(&testDesc{"bar", probes.ProbePath{"foo", "bar"}}, nil, "baz"))
+	failed, warned = underTest.summary()
+	assert.False(t, failed)
+	assert.True(t, warned)
+
+	assert.NoError(t, underTest.Reject(&testDesc{"baz", probes.ProbePath{"baz"}}, testProp("qux"), ""))
+	assert.NoError(t, underTest.Error(&testDesc{"qux", probes.ProbePath{"qux"}}, errors.New("quux")))
+	failed, warned = underTest.summary()
+	assert.True(t, failed)
+	assert.True(t, warned)
+
+	var buf strings.Builder
+	assert.NoError(t, underTest.write(&buf, "json"))
+	assert.JSONEq(t, `[
+		{"path": "foo", "name": "foo", "status": "pass", "value": "bar"},
+		{"path": "foo/bar", "name": "bar", "status": "warning", "message": "baz"},
+		{"path": "baz", "name": "baz", "status": "rejected", "value": "qux"},
+		{"path": "qux", "name": "qux", "status": "error", "message": "quux"}
+	]`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, underTest.write(&buf, "yaml"))
+	assert.Equal(t, `- name: foo
+  path: foo
+  status: pass
+  value: bar
+- message: baz
+  name: bar
+  path: foo/bar
+  status: warning
+- name: baz
+  path: baz
+  status: rejected
+  value: qux
+- message: quux
+  name: qux
+  path: qux
+  status: error
+`, buf.String())
+
+	buf.Reset()
+	assert.NoError(t, (&structuredReporter{}).write(&buf, "json"))
+	assert.Equal(t, "[]\n", buf.String())
+}
+
 type testDesc struct {
 	name string
 	path probes.ProbePath
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package telemetry
+
+import (
+	"fmt"
+
+	"github.com/k0sproject/k0s/pkg/build"
+	"github.com/k0sproject/k0s/pkg/config"
+	"github.com/k0sproject/k0s/pkg/constant"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+	"github.com/k0sproject/k0s/pkg/telemetry"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/spf13/cobra"
+)
+
+func telemetryPreviewCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "preview",
+		Short: "Print the telemetry data that this controller would send",
+		Long: `Print the telemetry data that this controller would send, as configured
+in spec.telemetry. Message IDs and timestamps are added when the data is sent.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c := config.GetCmdOpts()
+			loadingRules := config.ClientConfigLoadingRules{K0sVars: c.K0sVars}
+			clusterConfig, err := loadingRules.Load()
+			if err != nil {
+				return err
+			}
+
+			clientFactory := kubeutil.NewAdminClientFactory(c.K0sVars)
+			client, err := clientFactory.GetClient()
+			if err != nil {
+				return err
+			}
+
+			// With dynamic config, the telemetry settings are stored in the cluster.
+			configClient, err := clientFactory.GetConfigClient()
+			if err != nil {
+				return err
+			}
+			apiConfig, err := configClient.Get(cmd.Context(), constant.ClusterConfigObjectName, metav1.GetOptions{})
+			switch {
+			case err == nil:
+				if apiConfig.Spec != nil && apiConfig.Spec.Telemetry != nil {
+					clusterConfig.Spec.Telemetry = apiConfig.Spec.Telemetry
+				}
+			case !apierrors.IsNotFound(err):
+				return fmt.Errorf("failed to get cluster config from API: %w", err)
+			}
+
+			if settings := clusterConfig.Spec.Telemetry; settings == nil || !settings.Enabled {
+				fmt.Fprintln(cmd.ErrOrStderr(), "Telemetry is disabled, the following data would be sent if it was enabled:")
+			}
+
+			payload, err := telemetry.Preview(cmd.Context(), build.Version, clusterConfig, client)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(payload))
+			return err
+		},
+	}
+}
@@ -36,6 +36,7 @@ func tokenCreateCmd() *cobra.Command {
 		createTokenRole string
 		tokenExpiry     string
 		waitCreate      bool
+		tunnelAddress   string
 	)
 
 	cmd := &cobra.Command{
@@ -43,6 +44,7 @@ func tokenCreateCmd() *cobra.Command {
 		Short: "Create join token",
 		Example: `k0s token create --role worker --expiry 100h //sets expiration time to 100 hours
 k0s token create --role worker --expiry 10m  //sets expiration time to 10 minutes
+k0s token create --role worker --tunnel-address 10.0.0.5 //joins through the konnectivity tunnel of the worker node 10.0.0.5
 `,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			err := checkTokenRole(createTokenRole)
@@ -75,13 +77,17 @@ k0s token create --role worker --expiry 10m  //sets expiration time to 10 minute
 				if statusInfo == nil {
 					return errors.New("k0s is not running")
 				}
-				if err = ensureTokenCreationAcceptable(createTokenRole, statusInfo); err != nil {
+				if err = ensureTokenCreationAcceptable(createTokenRole, tunnelAddress != "", statusInfo); err != nil {
 					waitCreate = false
 					cmd.SilenceUsage = true
 					return err
 				}
 
-				bootstrapConfig, err = token.CreateKubeletBootstrapToken(cmd.Context(), c.NodeConfig.Spec.API, c.K0sVars, createTokenRole, expiry)
+				if tunnelAddress != "" {
+					bootstrapConfig, err = token.CreateTunneledBootstrapToken(cmd.Context(), c.NodeConfig.Spec.API, c.K0sVars, createTokenRole, expiry, tunnelAddress)
+				} else {
+					bootstrapConfig, err = token.CreateKubeletBootstrapToken(cmd.Context(), c.NodeConfig.Spec.API, c.K0sVars, createTokenRole, expiry)
+				}
 				return err
 			})
 			if err != nil {
@@ -96,17 +102,27 @@ k0s token create --role worker --expiry 10m  //sets expiration time to 10 minute
 	cmd.Flags().StringVar(&tokenExpiry, "expiry", "0s", "Expiration time of the token. Format 1.5h, 2h45m or 300ms.")
 	cmd.Flags().StringVar(&createTokenRole, "role", "worker", "Either worker or controller")
 	cmd.Flags().BoolVar(&waitCreate, "wait", false, "wait forever (default false)")
+	cmd.Flags().StringVar(&tunnelAddress, "tunnel-address", "", "Address of a worker node through whose konnectivity tunnel to join (requires tunneled networking mode)")
 
 	return cmd
 }
 
-func ensureTokenCreationAcceptable(createTokenRole string, statusInfo *status.K0sStatus) error {
+func ensureTokenCreationAcceptable(createTokenRole string, tunneled bool, statusInfo *status.K0sStatus) error {
 	if statusInfo.SingleNode {
 		return errors.New("refusing to create token: cannot join into a single node cluster")
 	}
 	if createTokenRole == token.RoleController && !statusInfo.ClusterConfig.Spec.Storage.IsJoinable() {
 		return errors.New("refusing to create token: cannot join controller into current storage")
 	}
+	if tunneled {
+		api := statusInfo.ClusterConfig.Spec.API
+		if !api.TunneledNetworkingMode {
+			return errors.New("refusing to create token: tunneled networking mode is disabled")
+		}
+		if createTokenRole == token.RoleController && !api.TunneledJoinAPI {
+			return errors.New("refusing to create token: the join API is not tunneled")
+		}
+	}
 
 	return nil
 }
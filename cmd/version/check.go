@@ -0,0 +1,115 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/k0sproject/k0s/pkg/autopilot/updater"
+	"github.com/k0sproject/k0s/pkg/build"
+
+	"github.com/spf13/cobra"
+)
+
+func NewVersionCheckCmd() *cobra.Command {
+	var (
+		channel      string
+		updateServer string
+		index        string
+		output       string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "Check if a newer k0s version is available",
+		Example: `k0s version check
+k0s version check --channel unstable
+k0s version check --index /path/to/index.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "" && output != "json" {
+				return fmt.Errorf("unsupported output format: %q (valid values: json)", output)
+			}
+			if index != "" && cmd.Flags().Changed("update-server") {
+				return errors.New("--index and --update-server are mutually exclusive")
+			}
+
+			var client updater.Client
+			if index != "" {
+				client = updater.NewIndexClient(index)
+			} else {
+				var err error
+				if client, err = updater.NewClient(updateServer, ""); err != nil {
+					return err
+				}
+			}
+
+			result, err := checkVersion(client, channel, build.Version)
+			if err != nil {
+				return err
+			}
+			return result.print(cmd.OutOrStdout(), output)
+		},
+	}
+
+	cmd.Flags().StringVar(&channel, "channel", "stable", "the release channel to check")
+	cmd.Flags().StringVar(&updateServer, "update-server", updater.DefaultUpdateServer, "the URL of the update server to query")
+	cmd.Flags().StringVar(&index, "index", "", "the path to a local JSON index of the versions per channel, to be used instead of the update server")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "print the result in the given format (valid values: json)")
+	return cmd
+}
+
+type versionCheck struct {
+	Channel  string `json:"channel"`
+	Current  string `json:"current"`
+	Latest   string `json:"latest"`
+	Outdated bool   `json:"outdated"`
+}
+
+func checkVersion(client updater.Client, channel, current string) (*versionCheck, error) {
+	update, err := client.GetUpdate(channel, "", "", current)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the latest version of channel %q: %w", channel, err)
+	}
+	outdated, err := update.Version.IsNewerThan(current)
+	if err != nil {
+		return nil, err
+	}
+
+	return &versionCheck{channel, current, string(update.Version), outdated}, nil
+}
+
+func (c *versionCheck) print(w io.Writer, output string) error {
+	if output == "json" {
+		jsn, err := json.MarshalIndent(c, "", "   ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsn))
+		return err
+	}
+
+	if c.Outdated {
+		_, err := fmt.Fprintf(w, "k0s %s is outdated, the latest version in the %s channel is %s\n", c.Current, c.Channel, c.Latest)
+		return err
+	}
+	_, err := fmt.Fprintf(w, "k0s %s is up to date, the latest version in the %s channel is %s\n", c.Current, c.Channel, c.Latest)
+	return err
+}
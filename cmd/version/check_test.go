@@ -0,0 +1,54 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/autopilot/updater"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckVersion(t *testing.T) {
+	index := filepath.Join(t.TempDir(), "index.json")
+	require.NoError(t, os.WriteFile(index, []byte(`[{"name": "stable", "versions": ["v1.27.1+k0s.0", "v1.27.2+k0s.0"]}]`), 0644))
+	client := updater.NewIndexClient(index)
+
+	result, err := checkVersion(client, "stable", "v1.27.1+k0s.0")
+	require.NoError(t, err)
+	assert.Equal(t, &versionCheck{"stable", "v1.27.1+k0s.0", "v1.27.2+k0s.0", true}, result)
+
+	var out strings.Builder
+	require.NoError(t, result.print(&out, ""))
+	assert.Equal(t, "k0s v1.27.1+k0s.0 is outdated, the latest version in the stable channel is v1.27.2+k0s.0\n", out.String())
+
+	result, err = checkVersion(client, "stable", "v1.27.2+k0s.0")
+	require.NoError(t, err)
+	assert.False(t, result.Outdated)
+
+	out.Reset()
+	require.NoError(t, result.print(&out, "json"))
+	assert.Contains(t, out.String(), `"outdated": false`)
+
+	_, err = checkVersion(client, "unstable", "v1.27.2+k0s.0")
+	assert.ErrorContains(t, err, `failed to get the latest version of channel "unstable"`)
+}
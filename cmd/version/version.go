@@ -20,23 +20,39 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"runtime"
+	"runtime/debug"
 
 	"github.com/k0sproject/k0s/pkg/build"
+	"github.com/k0sproject/k0s/pkg/constant"
 
 	"github.com/spf13/cobra"
-)
-
-var (
-	all   bool
-	isJsn bool
+	"sigs.k8s.io/yaml"
 )
 
 func NewVersionCmd() *cobra.Command {
+	var (
+		all    bool
+		isJsn  bool
+		output string
+	)
+
 	cmd := &cobra.Command{
 		Use:   "version",
 		Short: "Print the k0s version",
+		Example: `k0s version
+k0s version --all
+k0s version --output json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isJsn && output == "" {
+				output = "json"
+			}
+			switch output {
+			case "", "json", "yaml":
+			default:
+				return fmt.Errorf("unsupported output format: %q (valid values: json, yaml)", output)
+			}
 
-		Run: func(cmd *cobra.Command, args []string) {
 			info := versionInfo{
 				Version:      build.Version,
 				Runc:         build.RuncVersion,
@@ -45,41 +61,98 @@ func NewVersionCmd() *cobra.Command {
 				Kine:         build.KineVersion,
 				Etcd:         build.EtcdVersion,
 				Konnectivity: build.KonnectivityVersion,
+				KubeRouter:   constant.KubeRouterCNIImageVersion,
+				Calico:       constant.CalicoComponentImagesVersion,
+				Build:        newBuildInfo(),
 			}
 
-			info.Print(cmd.OutOrStdout())
+			return info.Print(cmd.OutOrStdout(), all, output)
 		},
 	}
 
 	// append flags
 	cmd.PersistentFlags().BoolVarP(&all, "all", "a", false, "use to print all k0s version info")
 	cmd.PersistentFlags().BoolVarP(&isJsn, "json", "j", false, "use to print all k0s version info in json")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "print all k0s version info in the given format (valid values: json, yaml)")
+	cmd.AddCommand(NewVersionCheckCmd())
 	return cmd
 }
 
 type versionInfo struct {
-	Version      string `json:"k0s,omitempty"`
-	Runc         string `json:"runc,omitempty"`
-	Containerd   string `json:"containerd,omitempty"`
-	Kubernetes   string `json:"kubernetes,omitempty"`
-	Kine         string `json:"kine,omitempty"`
-	Etcd         string `json:"etcd,omitempty"`
-	Konnectivity string `json:"konnectivity,omitempty"`
+	Version      string     `json:"k0s,omitempty"`
+	Runc         string     `json:"runc,omitempty"`
+	Containerd   string     `json:"containerd,omitempty"`
+	Kubernetes   string     `json:"kubernetes,omitempty"`
+	Kine         string     `json:"kine,omitempty"`
+	Etcd         string     `json:"etcd,omitempty"`
+	Konnectivity string     `json:"konnectivity,omitempty"`
+	KubeRouter   string     `json:"kube-router,omitempty"`
+	Calico       string     `json:"calico,omitempty"`
+	Build        *buildInfo `json:"build,omitempty"`
+}
+
+// buildInfo is the metadata of the k0s binary itself.
+type buildInfo struct {
+	Date      string `json:"date,omitempty"`
+	GitCommit string `json:"gitCommit,omitempty"`
+	GoVersion string `json:"goVersion"`
+	Platform  string `json:"platform"`
 }
 
-func (v versionInfo) Print(w io.Writer) {
-	if all {
-		fmt.Fprintln(w, "k0s :", v.Version)
-		fmt.Fprintln(w, "runc :", v.Runc)
-		fmt.Fprintln(w, "containerd :", v.Containerd)
-		fmt.Fprintln(w, "kubernetes :", v.Kubernetes)
-		fmt.Fprintln(w, "kine :", v.Kine)
-		fmt.Fprintln(w, "etcd :", v.Etcd)
-		fmt.Fprintln(w, "konnectivity :", v.Konnectivity)
-	} else if isJsn {
-		jsn, _ := json.MarshalIndent(v, "", "   ")
-		fmt.Fprintln(w, string(jsn))
-	} else {
-		fmt.Fprintln(w, v.Version)
+func newBuildInfo() *buildInfo {
+	info := &buildInfo{
+		Date:      build.BuildDate,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
 	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		var modified bool
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.GitCommit = setting.Value
+			case "vcs.modified":
+				modified = setting.Value == "true"
+			}
+		}
+		if modified && info.GitCommit != "" {
+			info.GitCommit += "-dirty"
+		}
+	}
+	return info
+}
+
+func (v versionInfo) Print(w io.Writer, all bool, output string) error {
+	switch output {
+	case "json":
+		jsn, err := json.MarshalIndent(v, "", "   ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(jsn))
+		return err
+	case "yaml":
+		ym, err := yaml.Marshal(v)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(w, string(ym))
+		return err
+	}
+
+	if !all {
+		_, err := fmt.Fprintln(w, v.Version)
+		return err
+	}
+
+	fmt.Fprintln(w, "k0s :", v.Version)
+	fmt.Fprintln(w, "runc :", v.Runc)
+	fmt.Fprintln(w, "containerd :", v.Containerd)
+	fmt.Fprintln(w, "kubernetes :", v.Kubernetes)
+	fmt.Fprintln(w, "kine :", v.Kine)
+	fmt.Fprintln(w, "etcd :", v.Etcd)
+	fmt.Fprintln(w, "konnectivity :", v.Konnectivity)
+	fmt.Fprintln(w, "kube-router :", v.KubeRouter)
+	_, err := fmt.Fprintln(w, "calico :", v.Calico)
+	return err
 }
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package version
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func TestVersionCmd(t *testing.T) {
+	run := func(args ...string) (string, error) {
+		var out strings.Builder
+		cmd := NewVersionCmd()
+		cmd.SetArgs(args)
+		cmd.SetOut(&out)
+		cmd.SetErr(&out)
+		err := cmd.Execute()
+		return out.String(), err
+	}
+
+	for _, test := range []struct {
+		name      string
+		args      []string
+		unmarshal func([]byte, any) error
+	}{
+		{"output_json", []string{"--output", "json"}, json.Unmarshal},
+		{"legacy_json", []string{"--json"}, json.Unmarshal},
+		{"output_yaml", []string{"-o", "yaml"}, func(data []byte, v any) error { return yaml.Unmarshal(data, v) }},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			out, err := run(test.args...)
+			require.NoError(t, err)
+
+			var info versionInfo
+			require.NoError(t, test.unmarshal([]byte(out), &info))
+			assert.NotEmpty(t, info.KubeRouter)
+			assert.NotEmpty(t, info.Calico)
+			if assert.NotNil(t, info.Build) {
+				assert.NotEmpty(t, info.Build.GoVersion)
+				assert.NotEmpty(t, info.Build.Platform)
+			}
+		})
+	}
+
+	t.Run("all", func(t *testing.T) {
+		out, err := run("--all")
+		require.NoError(t, err)
+		assert.Contains(t, out, "kube-router :")
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := run("-o", "xml")
+		assert.ErrorContains(t, err, `unsupported output format: "xml"`)
+	})
+}
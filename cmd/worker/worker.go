@@ -18,24 +18,33 @@ package worker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"runtime"
 	"syscall"
 
+	"github.com/k0sproject/k0s/internal/pkg/file"
 	k0slog "github.com/k0sproject/k0s/internal/pkg/log"
+	"github.com/k0sproject/k0s/internal/pkg/selinux"
 	"github.com/k0sproject/k0s/internal/pkg/stringmap"
 	"github.com/k0sproject/k0s/internal/pkg/sysinfo"
 	"github.com/k0sproject/k0s/pkg/build"
+	"github.com/k0sproject/k0s/pkg/component/diskmonitor"
 	"github.com/k0sproject/k0s/pkg/component/manager"
 	"github.com/k0sproject/k0s/pkg/component/prober"
 	"github.com/k0sproject/k0s/pkg/component/status"
 	"github.com/k0sproject/k0s/pkg/component/worker"
 	workerconfig "github.com/k0sproject/k0s/pkg/component/worker/config"
 	"github.com/k0sproject/k0s/pkg/component/worker/nllb"
+	"github.com/k0sproject/k0s/pkg/component/worker/nodeproblems"
 	"github.com/k0sproject/k0s/pkg/config"
+	containerruntime "github.com/k0sproject/k0s/pkg/container/runtime"
+	"github.com/k0sproject/k0s/pkg/install"
 	"github.com/k0sproject/k0s/pkg/kubernetes"
+	"github.com/k0sproject/k0s/pkg/token"
 
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -45,6 +54,7 @@ type Command config.CLIOptions
 
 func NewWorkerCmd() *cobra.Command {
 	var ignorePreFlightChecks bool
+	var preFlightPolicies map[string]string
 
 	cmd := &cobra.Command{
 		Use:   "worker [join-token]",
@@ -68,9 +78,13 @@ func NewWorkerCmd() *cobra.Command {
 			}
 
 			c.Logging = stringmap.Merge(c.CmdLogLevels, c.DefaultLogLevels)
+			k0slog.SetNodeRole("worker")
 			if len(c.TokenArg) > 0 && len(c.TokenFile) > 0 {
 				return fmt.Errorf("you can only pass one token argument either as a CLI argument 'k0s worker [token]' or as a flag 'k0s worker --token-file [path]'")
 			}
+			if c.TokenURL != "" && (len(c.TokenArg) > 0 || len(c.TokenFile) > 0) {
+				return fmt.Errorf("--token-url can't be combined with a join-token given as CLI argument or via --token-file")
+			}
 
 			if len(c.TokenFile) > 0 {
 				bytes, err := os.ReadFile(c.TokenFile)
@@ -81,35 +95,77 @@ func NewWorkerCmd() *cobra.Command {
 			}
 			cmd.SilenceUsage = true
 
-			if err := (&sysinfo.K0sSysinfoSpec{
-				ControllerRoleEnabled: false,
-				WorkerRoleEnabled:     true,
-				DataDir:               c.K0sVars.DataDir,
-			}).RunPreFlightChecks(ignorePreFlightChecks); !ignorePreFlightChecks && err != nil {
+			preFlightChecks := c.preFlightChecks()
+			policies, err := sysinfo.ParsePreFlightPolicies(preFlightPolicies)
+			if err != nil {
+				return err
+			}
+			preFlightChecks.Policies = policies
+			if err := preFlightChecks.RunPreFlightChecks(ignorePreFlightChecks); err != nil {
 				return err
 			}
 
+			if c.Rootless {
+				if c.CriSocket != "" {
+					return errors.New("rootless mode doesn't support custom container runtimes")
+				}
+				if err := worker.EnterRootlessNamespaces(c.K0sVars); err != nil {
+					return err
+				}
+			}
+
 			// Set up signal handling
 			ctx, cancel := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 			defer cancel()
 
-			return c.Start(ctx)
+			return install.RunAsService(ctx, "worker", c.Start)
 		},
 	}
 
 	// append flags
 	cmd.Flags().BoolVar(&ignorePreFlightChecks, "ignore-pre-flight-checks", false, "continue even if pre-flight checks fail")
+	cmd.Flags().StringToStringVar(&preFlightPolicies, "pre-flight-check", nil, "policy for individual pre-flight checks in the form of path=enforce|warn|ignore, overrides --ignore-pre-flight-checks (can be repeated)")
 	cmd.PersistentFlags().AddFlagSet(config.GetPersistentFlagSet())
 	cmd.PersistentFlags().AddFlagSet(config.GetWorkerFlags())
 	return cmd
 }
 
+// preFlightChecks returns the pre-flight checks for this worker. Besides the
+// host requirements, they verify that kubelet's port is free, and, when
+// joining, that the join address is reachable.
+func (c *Command) preFlightChecks() *sysinfo.K0sSysinfoSpec {
+	spec := &sysinfo.K0sSysinfoSpec{
+		ControllerRoleEnabled: false,
+		WorkerRoleEnabled:     true,
+		RootlessEnabled:       c.Rootless,
+		DataDir:               c.K0sVars.DataDir,
+	}
+
+	// Rootless kubelets listen in their own network namespace.
+	if !c.Rootless {
+		spec.Ports = map[int]string{10250: "kubelet"}
+	}
+
+	if c.TokenArg != "" && !file.Exists(c.K0sVars.KubeletAuthConfigPath) {
+		// Invalid tokens are rejected when joining.
+		if address, err := token.JoinAddress(c.TokenArg); err == nil {
+			spec.Endpoints = map[string]string{address: "Join address"}
+		}
+	}
+
+	return spec
+}
+
 // Start starts the worker components based on the given [config.CLIOptions].
 func (c *Command) Start(ctx context.Context) error {
 	if err := worker.BootstrapKubeletKubeconfig(ctx, c.K0sVars, &c.WorkerOptions); err != nil {
 		return err
 	}
 
+	if c.WorkerProfile == "default" && runtime.GOOS == "windows" {
+		c.WorkerProfile = "default-windows"
+	}
+
 	kubeletKubeconfigPath := c.K0sVars.KubeletAuthConfigPath
 	workerConfig, err := workerconfig.LoadProfile(
 		ctx,
@@ -120,8 +176,22 @@ func (c *Command) Start(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	if _, err := worker.ApplyCertificateAuthorities(c.K0sVars, &workerConfig.CertificateAuthorities, c.K0sVars.KubeletAuthConfigPath); err != nil {
+		return fmt.Errorf("failed to apply certificate authorities: %w", err)
+	}
 
+	timeoutPolicy, err := manager.ParseTimeoutPolicy(c.ComponentTimeoutPolicy)
+	if err != nil {
+		return err
+	}
 	componentManager := manager.New(prober.DefaultProber)
+	componentManager.Timeout, componentManager.TimeoutPolicy = c.ComponentTimeout, timeoutPolicy
+	componentManager.StopTimeout = c.ComponentStopTimeout
+
+	// Modules and sysctls can't be changed from inside a user namespace.
+	if runtime.GOOS == "linux" && !c.Rootless {
+		componentManager.Add(ctx, &worker.Kernel{Settings: workerConfig.Kernel})
+	}
 
 	var staticPods worker.StaticPods
 
@@ -138,24 +208,47 @@ func (c *Command) Start(ctx context.Context) error {
 		componentManager.Add(ctx, reconciler)
 	}
 
-	if runtime.GOOS == "windows" && c.CriSocket == "" {
-		return fmt.Errorf("windows worker needs to have external CRI")
-	}
+	useSELinux := !c.Rootless && selinuxEnabled(workerConfig.SELinux)
+
 	if c.CriSocket == "" {
 		componentManager.Add(ctx, &worker.ContainerD{
 			LogLevel: c.Logging["containerd"],
 			K0sVars:  c.K0sVars,
+			Rootless: c.Rootless,
+			SELinux:  useSELinux,
 		})
 	}
 
 	componentManager.Add(ctx, worker.NewOCIBundleReconciler(c.K0sVars))
-	if c.WorkerProfile == "default" && runtime.GOOS == "windows" {
-		c.WorkerProfile = "default-windows"
+
+	// Controllers with an enabled worker monitor the data directory already.
+	var diskMonitor diskmonitor.DiskMonitor
+	if !c.SingleNode && !c.EnableWorker {
+		diskMonitor.Paths = append(diskMonitor.Paths, diskmonitor.Path{Name: "data", Path: c.K0sVars.DataDir})
+	}
+	if c.CriSocket == "" {
+		diskMonitor.Paths = append(diskMonitor.Paths, diskmonitor.Path{
+			Name: "containerd",
+			Path: filepath.Join(c.K0sVars.DataDir, "containerd"),
+			Reclaim: func(ctx context.Context) error {
+				cri := containerruntime.NewCRIRuntime(worker.ContainerdEndpoint(c.K0sVars.RunDir))
+				removed, err := cri.PruneImages(ctx)
+				if len(removed) > 0 {
+					logrus.Infof("Removed %d unused images", len(removed))
+				}
+				return err
+			},
+		})
+	}
+	if len(diskMonitor.Paths) > 0 {
+		diskMonitor.MinFreePercent = c.MinFreeDiskPercent
+		diskMonitor.Reclaim = c.ReclaimDiskSpace
+		componentManager.Add(ctx, &diskMonitor)
 	}
 
-	componentManager.Add(ctx, &worker.Kubelet{
+	kubelet := &worker.Kubelet{
 		CRISocket:           c.CriSocket,
-		EnableCloudProvider: c.CloudProvider,
+		EnableCloudProvider: c.CloudProvider || workerConfig.ExternalCloudProvider,
 		K0sVars:             c.K0sVars,
 		StaticPods:          staticPods,
 		Kubeconfig:          kubeletKubeconfigPath,
@@ -165,26 +258,38 @@ func (c *Command) Start(ctx context.Context) error {
 		Taints:              c.Taints,
 		ExtraArgs:           c.KubeletExtraArgs,
 		IPTablesMode:        c.WorkerOptions.IPTablesMode,
+		IPTables:            workerConfig.IPTables,
+		Rootless:            c.Rootless,
+		SELinux:             useSELinux,
+	}
+	componentManager.Add(ctx, kubelet)
+
+	caKubeconfigs := []string{c.K0sVars.KubeletAuthConfigPath}
+	if kubeletKubeconfigPath != c.K0sVars.KubeletAuthConfigPath {
+		caKubeconfigs = append(caKubeconfigs, kubeletKubeconfigPath)
+	}
+	componentManager.Add(ctx, &worker.CARotation{
+		K0sVars:       c.K0sVars,
+		WorkerProfile: c.WorkerProfile,
+		Kubeconfigs:   caKubeconfigs,
+		Kubelet:       kubelet,
 	})
 
-	if runtime.GOOS == "windows" {
-		if c.TokenArg == "" {
-			return fmt.Errorf("no join-token given, which is required for windows bootstrap")
+	certManager := worker.NewCertificateManager(ctx, kubeletKubeconfigPath)
+
+	if c.EnableNodeProblemDetector {
+		detector := &nodeproblems.Detector{CertManager: certManager}
+		if c.CriSocket == "" {
+			detector.CRIEndpoint = worker.ContainerdEndpoint(c.K0sVars.RunDir)
+		} else if runtimeType, runtimeEndpoint, err := worker.SplitRuntimeConfig(c.CriSocket); err == nil && runtimeType == "remote" {
+			detector.CRIEndpoint = runtimeEndpoint
 		}
-		componentManager.Add(ctx, &worker.KubeProxy{
-			K0sVars:   c.K0sVars,
-			LogLevel:  c.Logging["kube-proxy"],
-			CIDRRange: c.CIDRRange,
-		})
-		componentManager.Add(ctx, &worker.CalicoInstaller{
-			Token:      c.TokenArg,
-			APIAddress: c.APIServer,
-			CIDRRange:  c.CIDRRange,
-			ClusterDNS: c.ClusterDNS,
-		})
+		if len(diskMonitor.Paths) > 0 {
+			detector.DiskMonitor = &diskMonitor
+		}
+		componentManager.Add(ctx, detector)
 	}
 
-	certManager := worker.NewCertificateManager(ctx, kubeletKubeconfigPath)
 	if !c.SingleNode && !c.EnableWorker {
 		clusterConfig, err := config.LoadClusterConfig(c.K0sVars)
 		if err != nil {
@@ -205,6 +310,9 @@ func (c *Command) Start(ctx context.Context) error {
 			},
 			CertManager: certManager,
 			Socket:      config.StatusSocket,
+			NodeStatusPublisher: &status.NodeStatusPublisher{
+				CertManager: certManager,
+			},
 		})
 	}
 
@@ -218,7 +326,6 @@ func (c *Command) Start(ctx context.Context) error {
 		return err
 	}
 
-	worker.KernelSetup()
 	err = componentManager.Start(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to start worker components: %w", err)
@@ -233,3 +340,13 @@ func (c *Command) Start(ctx context.Context) error {
 	}
 	return nil
 }
+
+// selinuxEnabled determines whether containerd and kubelet run with SELinux
+// support. The worker profile's setting takes precedence, otherwise it's
+// enabled whenever SELinux is in enforcing mode.
+func selinuxEnabled(profileSetting *bool) bool {
+	if profileSetting != nil {
+		return *profileSetting
+	}
+	return selinux.Enforcing()
+}
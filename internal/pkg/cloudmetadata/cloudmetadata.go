@@ -0,0 +1,149 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package cloudmetadata discovers the public address of cloud instances using
+// the instance metadata services of the major cloud providers.
+package cloudmetadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// metadataURL is the link-local address shared by the metadata services of
+// EC2, GCE, Azure and OpenStack.
+const metadataURL = "http://169.254.169.254"
+
+// DefaultTimeout is the time to wait for the metadata services to respond.
+const DefaultTimeout = 10 * time.Second
+
+type provider struct {
+	name   string
+	lookup func(ctx context.Context, client *http.Client, baseURL string) (string, error)
+}
+
+// providers are the supported metadata services, in order of preference.
+var providers = []provider{
+	{"EC2", ec2PublicAddress},
+	{"GCE", gcePublicAddress},
+	{"Azure", azurePublicAddress},
+	{"OpenStack", openStackPublicAddress},
+}
+
+// PublicAddress returns the public IP address of the instance k0s is running
+// on, as reported by the instance metadata service of its cloud provider.
+func PublicAddress(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, DefaultTimeout)
+	defer cancel()
+
+	// Metadata services are link-local, never talk to them via a proxy.
+	client := &http.Client{Transport: &http.Transport{Proxy: nil}}
+	return publicAddress(ctx, client, metadataURL)
+}
+
+// publicAddress queries all providers concurrently and returns the address
+// reported by the first provider in order of preference.
+func publicAddress(ctx context.Context, client *http.Client, baseURL string) (string, error) {
+	addresses := make([]string, len(providers))
+	errs := make([]error, len(providers))
+
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p provider) {
+			defer wg.Done()
+			address, err := p.lookup(ctx, client, baseURL)
+			if err == nil && net.ParseIP(address) == nil {
+				err = fmt.Errorf("not an IP address: %q", address)
+			}
+			if err != nil {
+				errs[i] = fmt.Errorf("%s: %w", p.name, err)
+				return
+			}
+			addresses[i] = address
+		}(i, p)
+	}
+	wg.Wait()
+
+	for _, address := range addresses {
+		if address != "" {
+			return address, nil
+		}
+	}
+	return "", fmt.Errorf("no instance metadata service reported a public address: %w", errors.Join(errs...))
+}
+
+func ec2PublicAddress(ctx context.Context, client *http.Client, baseURL string) (string, error) {
+	// EC2 requires a session token (IMDSv2).
+	token, err := get(ctx, client, http.MethodPut, baseURL+"/latest/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": "60",
+	})
+	if err != nil {
+		return "", err
+	}
+	return get(ctx, client, http.MethodGet, baseURL+"/latest/meta-data/public-ipv4", map[string]string{
+		"X-aws-ec2-metadata-token": token,
+	})
+}
+
+func gcePublicAddress(ctx context.Context, client *http.Client, baseURL string) (string, error) {
+	return get(ctx, client, http.MethodGet, baseURL+"/computeMetadata/v1/instance/network-interfaces/0/access-configs/0/external-ip", map[string]string{
+		"Metadata-Flavor": "Google",
+	})
+}
+
+func azurePublicAddress(ctx context.Context, client *http.Client, baseURL string) (string, error) {
+	return get(ctx, client, http.MethodGet, baseURL+"/metadata/instance/network/interface/0/ipv4/ipAddress/0/publicIpAddress?api-version=2021-02-01&format=text", map[string]string{
+		"Metadata": "true",
+	})
+}
+
+func openStackPublicAddress(ctx context.Context, client *http.Client, baseURL string) (string, error) {
+	// OpenStack serves the EC2 compatible API without session tokens.
+	return get(ctx, client, http.MethodGet, baseURL+"/latest/meta-data/public-ipv4", nil)
+}
+
+func get(ctx context.Context, client *http.Client, method, url string, headers map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(body)), nil
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudmetadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublicAddress(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		handler  http.HandlerFunc
+		expected string
+	}{
+		{"EC2", func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+				_, _ = w.Write([]byte("token"))
+			case r.URL.Path == "/latest/meta-data/public-ipv4" && r.Header.Get("X-aws-ec2-metadata-token") == "token":
+				_, _ = w.Write([]byte("203.0.113.1"))
+			default:
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+			}
+		}, "203.0.113.1"},
+		{"GCE", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Metadata-Flavor") == "Google" && r.URL.Path == "/computeMetadata/v1/instance/network-interfaces/0/access-configs/0/external-ip" {
+				_, _ = w.Write([]byte("203.0.113.2"))
+				return
+			}
+			http.NotFound(w, r)
+		}, "203.0.113.2"},
+		{"Azure", func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Metadata") == "true" && r.URL.Query().Get("format") == "text" {
+				_, _ = w.Write([]byte("203.0.113.3\n"))
+				return
+			}
+			http.NotFound(w, r)
+		}, "203.0.113.3"},
+		{"OpenStack", func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodGet && r.URL.Path == "/latest/meta-data/public-ipv4" {
+				_, _ = w.Write([]byte("203.0.113.4"))
+				return
+			}
+			http.NotFound(w, r)
+		}, "203.0.113.4"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(test.handler)
+			defer server.Close()
+
+			address, err := publicAddress(context.TODO(), server.Client(), server.URL)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, address)
+		})
+	}
+}
+
+func TestPublicAddressFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("not-an-ip"))
+	}))
+	defer server.Close()
+
+	_, err := publicAddress(context.TODO(), server.Client(), server.URL)
+	assert.ErrorContains(t, err, "no instance metadata service reported a public address")
+	assert.ErrorContains(t, err, `not an IP address: "not-an-ip"`)
+}
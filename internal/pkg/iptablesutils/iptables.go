@@ -18,62 +18,120 @@ package iptablesutils
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
 
+	"github.com/k0sproject/k0s/internal/pkg/file"
+
 	"github.com/sirupsen/logrus"
 	"go.uber.org/multierr"
 )
 
 const (
+	ModeAuto   = "auto"
 	ModeNFT    = "nft"
 	ModeLegacy = "legacy"
 )
 
+// The chains that kubelet creates in the iptables mode it uses.
+var kubeletHints = []string{"KUBE-IPTABLES-HINT", "KUBE-KUBELET-CANARY"}
+
+// The prefixes of the chains that are owned by Kubernetes components, such as
+// kube-proxy, kube-router or Calico.
+var kubeOwnedPrefixes = []string{"KUBE-", "cali-"}
+
+// Detection records which iptables mode has been selected, and why.
+type Detection struct {
+	Mode   string `json:"mode"`
+	Reason string `json:"reason"`
+}
+
+// DetectOptions provides additional hints for the iptables mode detection.
+type DetectOptions struct {
+	// The mode in which kube-proxy operates, if it's enabled.
+	KubeProxyMode string
+
+	// Reports if the kernel supports nf_tables. Inspects the kernel modules if
+	// nil.
+	NFTablesSupported func() (bool, error)
+}
+
 // DetectHostIPTablesMode figure out whether iptables-legacy or iptables-nft is in use on the host.
-// Follows the same logic as kube-proxy/kube-route.
-// See: https://github.com/kubernetes-sigs/iptables-wrappers/blob/master/iptables-wrapper-installer.sh
-func DetectHostIPTablesMode(k0sBinPath string) (string, error) {
+// Follows the same logic as kube-proxy/kube-route, see:
+// https://github.com/kubernetes-sigs/iptables-wrappers/blob/master/iptables-wrapper-installer.sh
+//
+// On hosts that have rules in both modes, the mode that holds more rules of
+// Kubernetes components wins. If there are no such rules at all, hosts whose
+// kernel doesn't support nf_tables get iptables-legacy, and hosts on which
+// kube-proxy operates in nftables mode get iptables-nft. Otherwise, the mode
+// of the host's iptables binary is selected.
+func DetectHostIPTablesMode(k0sBinPath string, opts DetectOptions) (*Detection, error) {
 	logrus.Info("Trying to detect iptables mode")
 
-	nftMatches, nftTotal, nftErr := findMatchingEntries(k0sBinPath, ModeNFT, "KUBE-IPTABLES-HINT", "KUBE-KUBELET-CANARY")
+	nft, nftErr := inspectRules(k0sBinPath, ModeNFT)
 	if nftErr != nil {
 		logrus.WithError(nftErr).Debug("Failed to inspect iptables rules in nft mode")
 		nftErr = fmt.Errorf("nft: %w", nftErr)
-	} else if nftMatches {
-		logrus.Infof("Some kube-related iptables entries found for iptables-nft")
-		return ModeNFT, nil
+	} else if nft.hinted {
+		return detected(ModeNFT, "kubelet's iptables hints found in iptables-nft"), nil
 	}
 
-	legacyMatches, legacyTotal, legacyErr := findMatchingEntries(k0sBinPath, ModeLegacy, "KUBE-IPTABLES-HINT", "KUBE-KUBELET-CANARY")
+	legacy, legacyErr := inspectRules(k0sBinPath, ModeLegacy)
 	if legacyErr != nil {
 		logrus.WithError(legacyErr).Debug("Failed to inspect iptables rules in legacy mode")
 		legacyErr = fmt.Errorf("legacy: %w", legacyErr)
-	} else if legacyMatches {
-		logrus.Infof("Some kube-related iptables entries found for iptables-legacy")
-		return ModeLegacy, nil
+	} else if legacy.hinted {
+		return detected(ModeLegacy, "kubelet's iptables hints found in iptables-legacy"), nil
 	}
 
-	if nftErr == nil && legacyErr == nil && legacyTotal > nftTotal {
-		logrus.Infof(
-			"No kube-related entries found in neither iptables-nft nor iptables-legacy, "+
-				"but there are more legacy entries than nft entries (%d vs. %d), "+
-				"so go with iptables-legacy",
-			legacyTotal, nftTotal,
-		)
-		return ModeLegacy, nil
+	if nft.owned > legacy.owned {
+		return detected(ModeNFT, fmt.Sprintf(
+			"more Kubernetes-owned entries in iptables-nft than in iptables-legacy (%d vs. %d)",
+			nft.owned, legacy.owned,
+		)), nil
+	}
+	if legacy.owned > nft.owned {
+		return detected(ModeLegacy, fmt.Sprintf(
+			"more Kubernetes-owned entries in iptables-legacy than in iptables-nft (%d vs. %d)",
+			legacy.owned, nft.owned,
+		)), nil
+	}
+
+	if nftErr == nil && legacyErr == nil && legacy.total > nft.total {
+		return detected(ModeLegacy, fmt.Sprintf(
+			"no Kubernetes-owned entries, but more entries in iptables-legacy than in iptables-nft (%d vs. %d)",
+			legacy.total, nft.total,
+		)), nil
+	}
+
+	nftablesSupported := opts.NFTablesSupported
+	if nftablesSupported == nil {
+		nftablesSupported = kernelSupportsNFTables
+	}
+	if supported, err := nftablesSupported(); err != nil {
+		logrus.WithError(err).Debug("Failed to check kernel support for nf_tables")
+	} else if !supported {
+		return detected(ModeLegacy, "the kernel doesn't support nf_tables"), nil
+	}
+
+	if opts.KubeProxyMode == "nftables" {
+		return detected(ModeNFT, "kube-proxy operates in nftables mode"), nil
 	}
 
 	iptablesPath, err := exec.LookPath("iptables")
 	if err != nil {
-		return "", multierr.Combine(err, nftErr, legacyErr)
+		return nil, multierr.Combine(err, nftErr, legacyErr)
 	}
 
 	out, err := exec.Command(iptablesPath, "--version").CombinedOutput()
 	if err != nil {
-		return "", multierr.Combine(err, nftErr, legacyErr)
+		return nil, multierr.Combine(err, nftErr, legacyErr)
 	}
 
 	outStr := strings.TrimSpace(string(out))
@@ -83,14 +141,28 @@ func DetectHostIPTablesMode(k0sBinPath string) (string, error) {
 		mode = ModeNFT
 	}
 
-	logrus.Infof("Selecting iptables-%s: %s --version: %s", mode, iptablesPath, outStr)
-	return mode, nil
+	return detected(mode, fmt.Sprintf("%s --version: %s", iptablesPath, outStr)), nil
 }
 
-func findMatchingEntries(k0sBinPath, mode string, entries ...string) (entriesFound bool, total uint, _ error) {
+func detected(mode, reason string) *Detection {
+	logrus.Infof("Selecting iptables-%s: %s", mode, reason)
+	return &Detection{Mode: mode, Reason: reason}
+}
+
+// rules summarizes the iptables rules of a single mode.
+type rules struct {
+	// Whether kubelet's hints have been found.
+	hinted bool
+	// The number of entries owned by Kubernetes components.
+	owned uint
+	// The total number of entries.
+	total uint
+}
+
+func inspectRules(k0sBinPath, mode string) (rules rules, _ error) {
 	binaryPath := filepath.Join(k0sBinPath, fmt.Sprintf("xtables-%s-multi", mode))
 
-	findMatches := func(subcommand string) error {
+	inspect := func(subcommand string) error {
 		cmd := exec.Command(binaryPath, subcommand)
 		out, err := cmd.StdoutPipe()
 		if err != nil {
@@ -103,13 +175,17 @@ func findMatchingEntries(k0sBinPath, mode string, entries ...string) (entriesFou
 		scanner := bufio.NewScanner(out)
 		scanner.Split(bufio.ScanLines)
 		for scanner.Scan() {
-			total++
-			if !entriesFound {
-				line := scanner.Text()
-				for _, entry := range entries {
-					if strings.Contains(line, entry) {
-						entriesFound = true
-					}
+			rules.total++
+			line := scanner.Text()
+			for _, hint := range kubeletHints {
+				if strings.Contains(line, hint) {
+					rules.hinted = true
+				}
+			}
+			for _, prefix := range kubeOwnedPrefixes {
+				if strings.Contains(line, prefix) {
+					rules.owned++
+					break
 				}
 			}
 		}
@@ -117,13 +193,75 @@ func findMatchingEntries(k0sBinPath, mode string, entries ...string) (entriesFou
 		return cmd.Wait()
 	}
 
-	v4Err, v6Err := findMatches("iptables-save"), findMatches("ip6tables-save")
+	v4Err, v6Err := inspect("iptables-save"), inspect("ip6tables-save")
 	if v4Err != nil && v6Err != nil {
-		return false, 0, multierr.Combine(
+		return rules, multierr.Combine(
 			fmt.Errorf("iptables-save: %w", v4Err),
 			fmt.Errorf("ip6tables-save: %w", v6Err),
 		)
 	}
 
-	return entriesFound, total, nil
+	return rules, nil
+}
+
+// kernelSupportsNFTables checks if the nf_tables module is either loaded,
+// built into the kernel, or available to be loaded.
+func kernelSupportsNFTables() (bool, error) {
+	if _, err := os.Stat("/sys/module/nf_tables"); err == nil {
+		return true, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	release, err := os.ReadFile("/proc/sys/kernel/osrelease")
+	if err != nil {
+		return false, err
+	}
+	modulesDir := filepath.Join("/lib/modules", strings.TrimSpace(string(release)))
+
+	var inspected bool
+	for _, name := range []string{"modules.builtin", "modules.dep"} {
+		data, err := os.ReadFile(filepath.Join(modulesDir, name))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		if bytes.Contains(data, []byte("/nf_tables.ko")) {
+			return true, nil
+		}
+		inspected = true
+	}
+
+	if !inspected {
+		return false, fmt.Errorf("no module information found in %s", modulesDir)
+	}
+	return false, nil
+}
+
+// WriteDetection records the given detection in the given run directory, so
+// that it can be reported via the status socket.
+func WriteDetection(runDir string, detection *Detection) error {
+	data, err := json.Marshal(detection)
+	if err != nil {
+		return err
+	}
+	return file.WriteContentAtomically(detectionPath(runDir), data, 0644)
+}
+
+// ReadDetection reads the detection recorded in the given run directory.
+func ReadDetection(runDir string) (*Detection, error) {
+	data, err := os.ReadFile(detectionPath(runDir))
+	if err != nil {
+		return nil, err
+	}
+	var detection Detection
+	if err := json.Unmarshal(data, &detection); err != nil {
+		return nil, err
+	}
+	return &detection, nil
+}
+
+func detectionPath(runDir string) string {
+	return filepath.Join(runDir, "iptables-mode.json")
 }
@@ -18,6 +18,7 @@ package iptablesutils_test
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
@@ -58,13 +59,24 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 		writeScript(t, parentDir, fmt.Sprintf("xtables-%s-multi", mode), content)
 	}
 
+	nftablesSupported := func() (bool, error) { return true, nil }
+	detect := func(binDir string) (string, error) {
+		detection, err := iptablesutils.DetectHostIPTablesMode(binDir, iptablesutils.DetectOptions{
+			NFTablesSupported: nftablesSupported,
+		})
+		if err != nil {
+			return "", err
+		}
+		return detection.Mode, nil
+	}
+
 	pathDir := t.TempDir()
 	t.Setenv("PATH", pathDir)
 
 	t.Run("iptables_not_found", func(t *testing.T) {
 		binDir := t.TempDir()
 
-		_, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		_, err := detect(binDir)
 
 		var execErr *exec.Error
 		require.ErrorAs(t, err, &execErr)
@@ -79,7 +91,7 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 			strings.Repeat("echo KUBE-IPTABLES-HINT\n", 1),
 		)
 
-		mode, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		mode, err := detect(binDir)
 		require.NoError(t, err)
 		assert.Equal(t, iptablesutils.ModeNFT, mode)
 	})
@@ -91,7 +103,7 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 			strings.Repeat("echo KUBE-IPTABLES-HINT\n", 1),
 		)
 
-		mode, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		mode, err := detect(binDir)
 		require.NoError(t, err)
 		assert.Equal(t, iptablesutils.ModeLegacy, mode)
 	})
@@ -108,7 +120,7 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 			strings.Repeat("echo KUBE-IPTABLES-HINT\n", 3),
 		)
 
-		mode, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		mode, err := detect(binDir)
 		require.NoError(t, err)
 		assert.Equal(t, iptablesutils.ModeNFT, mode)
 	})
@@ -124,7 +136,7 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 			strings.Repeat("echo FOOBAR\n", 2),
 		)
 
-		mode, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		mode, err := detect(binDir)
 		require.NoError(t, err)
 		assert.Equal(t, iptablesutils.ModeLegacy, mode)
 	})
@@ -140,19 +152,80 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 			strings.Repeat("echo FOOBAR\n", 1),
 		)
 
-		_, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		_, err := detect(binDir)
 		var execErr *exec.Error
 		require.ErrorAs(t, err, &execErr)
 		assert.Equal(t, "iptables", execErr.Name)
 		assert.ErrorIs(t, execErr.Err, exec.ErrNotFound)
 	})
 
+	t.Run("xtables_owned_nft_over_legacy", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeXtables(t, binDir, "nft",
+			strings.Repeat("echo -A KUBE-SERVICES\n", 2),
+			strings.Repeat("echo -A KUBE-SERVICES\n", 1),
+		)
+		writeXtables(t, binDir, "legacy",
+			strings.Repeat("echo -A cali-INPUT\n", 1)+strings.Repeat("echo FOOBAR\n", 5),
+			strings.Repeat("echo FOOBAR\n", 5),
+		)
+
+		detection, err := iptablesutils.DetectHostIPTablesMode(binDir, iptablesutils.DetectOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, iptablesutils.ModeNFT, detection.Mode)
+		assert.Equal(t, "more Kubernetes-owned entries in iptables-nft than in iptables-legacy (3 vs. 1)", detection.Reason)
+	})
+
+	t.Run("xtables_owned_legacy_over_nft", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeXtables(t, binDir, "nft",
+			strings.Repeat("echo FOOBAR\n", 5),
+			strings.Repeat("echo FOOBAR\n", 5),
+		)
+		writeXtables(t, binDir, "legacy",
+			strings.Repeat("echo -A cali-INPUT\n", 1),
+			"exit 1",
+		)
+
+		mode, err := detect(binDir)
+		require.NoError(t, err)
+		assert.Equal(t, iptablesutils.ModeLegacy, mode)
+	})
+
+	t.Run("kernel_without_nftables", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeXtables(t, binDir, "nft", "", "")
+		writeXtables(t, binDir, "legacy", "", "")
+
+		detection, err := iptablesutils.DetectHostIPTablesMode(binDir, iptablesutils.DetectOptions{
+			KubeProxyMode:     "nftables",
+			NFTablesSupported: func() (bool, error) { return false, nil },
+		})
+		require.NoError(t, err)
+		assert.Equal(t, iptablesutils.ModeLegacy, detection.Mode)
+		assert.Equal(t, "the kernel doesn't support nf_tables", detection.Reason)
+	})
+
+	t.Run("kube_proxy_nftables", func(t *testing.T) {
+		binDir := t.TempDir()
+		writeXtables(t, binDir, "nft", "", "")
+		writeXtables(t, binDir, "legacy", "", "")
+
+		detection, err := iptablesutils.DetectHostIPTablesMode(binDir, iptablesutils.DetectOptions{
+			KubeProxyMode:     "nftables",
+			NFTablesSupported: func() (bool, error) { return false, assert.AnError },
+		})
+		require.NoError(t, err)
+		assert.Equal(t, iptablesutils.ModeNFT, detection.Mode)
+		assert.Equal(t, "kube-proxy operates in nftables mode", detection.Reason)
+	})
+
 	t.Run("xtables_nft_fails", func(t *testing.T) {
 		binDir := t.TempDir()
 		writeXtables(t, binDir, "nft", "exit 1", "exit 1")
 		writeXtables(t, binDir, "legacy", "exit 1", "echo KUBE-IPTABLES-HINT")
 
-		mode, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		mode, err := detect(binDir)
 		require.NoError(t, err)
 		assert.Equal(t, iptablesutils.ModeLegacy, mode)
 	})
@@ -162,7 +235,7 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 		writeXtables(t, binDir, "nft", "exit 1", "echo KUBE-IPTABLES-HINT")
 		writeXtables(t, binDir, "legacy", "exit 1", "exit 1")
 
-		mode, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		mode, err := detect(binDir)
 		require.NoError(t, err)
 		assert.Equal(t, iptablesutils.ModeNFT, mode)
 	})
@@ -172,7 +245,7 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 		writeXtables(t, binDir, "nft", "exit 99", "exit 88")
 		writeXtables(t, binDir, "legacy", "exit 77", "exit 66")
 
-		_, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		_, err := detect(binDir)
 		errs := multierr.Errors(err)
 		require.Len(t, errs, 3)
 		assert.ErrorIs(t, errs[0], exec.ErrNotFound)
@@ -188,7 +261,7 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 	writeXtables(t, binDir, "legacy", "", "")
 
 	t.Run("iptables_legacy", func(t *testing.T) {
-		mode, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		mode, err := detect(binDir)
 		require.NoError(t, err)
 		assert.Equal(t, iptablesutils.ModeLegacy, mode)
 	})
@@ -196,7 +269,7 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 	writeScript(t, pathDir, "iptables", "echo foo-nf_tables-bar")
 
 	t.Run("iptables_nft", func(t *testing.T) {
-		mode, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		mode, err := detect(binDir)
 		require.NoError(t, err)
 		assert.Equal(t, iptablesutils.ModeNFT, mode)
 	})
@@ -204,13 +277,26 @@ func TestDetectHostIPTablesMode(t *testing.T) {
 	writeScript(t, pathDir, "iptables", "exit 1")
 
 	t.Run("iptables_broken", func(t *testing.T) {
-		_, err := iptablesutils.DetectHostIPTablesMode(binDir)
+		_, err := detect(binDir)
 		var exitErr *exec.ExitError
 		require.ErrorAs(t, err, &exitErr)
 		assert.Equal(t, 1, exitErr.ExitCode())
 	})
 }
 
+func TestDetection(t *testing.T) {
+	runDir := t.TempDir()
+
+	_, err := iptablesutils.ReadDetection(runDir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	detection := &iptablesutils.Detection{Mode: iptablesutils.ModeNFT, Reason: "because"}
+	require.NoError(t, iptablesutils.WriteDetection(runDir, detection))
+	read, err := iptablesutils.ReadDetection(runDir)
+	require.NoError(t, err)
+	assert.Equal(t, detection, read)
+}
+
 func TestMain(m *testing.M) {
 	logrus.SetLevel(logrus.DebugLevel)
 	m.Run()
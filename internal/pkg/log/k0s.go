@@ -17,15 +17,25 @@ limitations under the License.
 package log
 
 import (
+	"fmt"
+	"time"
+
 	cfssllog "github.com/cloudflare/cfssl/log"
 	"github.com/sirupsen/logrus"
 )
 
+// The supported log formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// nodeRole is the role of this node, as reported in the logs of supervised
+// processes.
+var nodeRole string
+
 func InitLogging() {
-	customFormatter := new(logrus.TextFormatter)
-	customFormatter.TimestampFormat = "2006-01-02 15:04:05"
-	customFormatter.FullTimestamp = true
-	logrus.SetFormatter(customFormatter)
+	logrus.SetFormatter(newTextFormatter())
 
 	cfssllog.SetLogger((*cfsslAdapter)(logrus.WithField("component", "cfssl")))
 
@@ -46,3 +56,34 @@ func SetWarnLevel() {
 	logrus.SetLevel(logrus.WarnLevel)
 	cfssllog.Level = cfssllog.LevelWarning
 }
+
+// SetFormat switches the log output to the given format. An empty format
+// means text.
+func SetFormat(format string) error {
+	switch format {
+	case "", FormatText:
+		logrus.SetFormatter(newTextFormatter())
+	case FormatJSON:
+		logrus.SetFormatter(&logrus.JSONFormatter{TimestampFormat: time.RFC3339Nano})
+	default:
+		return fmt.Errorf("unsupported log format %q, expected %q or %q", format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
+// SetNodeRole sets the role of this node, e.g. "controller" or "worker".
+func SetNodeRole(role string) {
+	nodeRole = role
+}
+
+// NodeRole returns the role of this node, if it has been set.
+func NodeRole() string {
+	return nodeRole
+}
+
+func newTextFormatter() logrus.Formatter {
+	return &logrus.TextFormatter{
+		TimestampFormat: "2006-01-02 15:04:05",
+		FullTimestamp:   true,
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetFormat(t *testing.T) {
+	t.Cleanup(func() { logrus.SetFormatter(newTextFormatter()) })
+
+	var buf bytes.Buffer
+	log := logrus.New()
+	log.SetOutput(&buf)
+
+	require.NoError(t, SetFormat(FormatJSON))
+	log.SetFormatter(logrus.StandardLogger().Formatter)
+	log.WithFields(logrus.Fields{"component": "etcd", "role": "controller", "stream": "stderr"}).Info("hello")
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "hello", entry["msg"])
+	assert.Equal(t, "info", entry["level"])
+	assert.Equal(t, "etcd", entry["component"])
+	assert.Equal(t, "controller", entry["role"])
+	assert.Equal(t, "stderr", entry["stream"])
+	assert.Contains(t, entry, "time")
+
+	require.NoError(t, SetFormat(FormatText))
+	assert.IsType(t, &logrus.TextFormatter{}, logrus.StandardLogger().Formatter)
+	require.NoError(t, SetFormat(""))
+	assert.IsType(t, &logrus.TextFormatter{}, logrus.StandardLogger().Formatter)
+
+	assert.EqualError(t, SetFormat("xml"), `unsupported log format "xml", expected "text" or "json"`)
+}
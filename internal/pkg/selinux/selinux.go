@@ -0,0 +1,166 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package selinux contains helpers to run k0s on hosts with SELinux enabled.
+package selinux
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	goselinux "github.com/opencontainers/selinux/go-selinux"
+)
+
+// Enabled reports whether SELinux is enabled on this host.
+func Enabled() bool {
+	return goselinux.GetEnabled()
+}
+
+// Enforcing reports whether SELinux is enabled and in enforcing mode.
+func Enforcing() bool {
+	return goselinux.GetEnabled() && goselinux.EnforceMode() == goselinux.Enforcing
+}
+
+// Mode returns a human readable representation of the current SELinux mode.
+func Mode() string {
+	if !goselinux.GetEnabled() {
+		return "disabled"
+	}
+
+	switch mode := goselinux.EnforceMode(); mode {
+	case goselinux.Enforcing:
+		return "enforcing"
+	case goselinux.Permissive:
+		return "permissive"
+	default:
+		return fmt.Sprintf("unknown (%d)", mode)
+	}
+}
+
+// ContainerPolicyLoaded reports whether the loaded policy knows about the
+// container types, i.e. if the container-selinux policy is installed.
+func ContainerPolicyLoaded() bool {
+	return goselinux.SecurityCheckContext(fileContext("container_var_lib_t")) == nil
+}
+
+var errNoContainerPolicy = errors.New("the container-selinux policy is not installed")
+
+type pathLabel struct {
+	path    string
+	seType  string
+	recurse bool
+}
+
+// pathLabels returns the SELinux types of the k0s directories, in the order
+// in which they need to be applied. These mirror the file contexts that the
+// container-selinux policy defines for /var/lib/containerd, /var/lib/kubelet
+// and friends.
+func pathLabels(dataDir, binDir, runDir string) []pathLabel {
+	labels := []pathLabel{
+		{dataDir, "container_var_lib_t", true},
+		{binDir, "container_runtime_exec_t", true},
+		{filepath.Join(dataDir, "containerd", "io.containerd.snapshotter.v1.overlayfs"), "container_share_t", true},
+	}
+	labels = append(labels, kubeletPathLabels(filepath.Join(dataDir, "kubelet"))...)
+	return append(labels, pathLabel{runDir, "container_var_run_t", true})
+}
+
+// kubeletPathLabels returns the SELinux types of the kubelet root directory
+// and its pods directory. They're not applied recursively, as the volumes of
+// running pods carry their own labels. Newly created files inherit the labels
+// of their parent directories.
+func kubeletPathLabels(rootDir string) []pathLabel {
+	return []pathLabel{
+		{rootDir, "container_var_lib_t", false},
+		{filepath.Join(rootDir, "pods"), "container_file_t", false},
+	}
+}
+
+// LabelDirs applies the SELinux file contexts to the k0s data, binary and run
+// directories. Directories that don't exist are skipped. It's a no-op if
+// SELinux is disabled.
+func LabelDirs(dataDir, binDir, runDir string) error {
+	if !goselinux.GetEnabled() {
+		return nil
+	}
+
+	if !ContainerPolicyLoaded() {
+		return errNoContainerPolicy
+	}
+
+	for _, l := range pathLabels(dataDir, binDir, runDir) {
+		if err := l.apply(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// LabelRunDir applies the SELinux file context to the k0s run directory.
+// The run directory usually lives on a tmpfs, so its label doesn't survive
+// reboots and needs to be reapplied whenever k0s starts. It's a no-op if
+// SELinux is disabled.
+func LabelRunDir(runDir string) error {
+	if !goselinux.GetEnabled() {
+		return nil
+	}
+
+	if !ContainerPolicyLoaded() {
+		return errNoContainerPolicy
+	}
+
+	return pathLabel{runDir, "container_var_run_t", true}.apply()
+}
+
+// LabelKubeletDirs applies the SELinux file contexts to the kubelet root
+// directory and its pods directory, so that the pod volumes are accessible by
+// containers. Directories that don't exist are skipped. It's a no-op if SELinux
+// is disabled.
+func LabelKubeletDirs(rootDir string) error {
+	if !goselinux.GetEnabled() {
+		return nil
+	}
+
+	if !ContainerPolicyLoaded() {
+		return errNoContainerPolicy
+	}
+
+	for _, l := range kubeletPathLabels(rootDir) {
+		if err := l.apply(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (l pathLabel) apply() error {
+	if _, err := os.Stat(l.path); errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err := goselinux.Chcon(l.path, fileContext(l.seType), l.recurse); err != nil {
+		return fmt.Errorf("failed to label %s as %s: %w", l.path, l.seType, err)
+	}
+	return nil
+}
+
+func fileContext(seType string) string {
+	return "system_u:object_r:" + seType + ":s0"
+}
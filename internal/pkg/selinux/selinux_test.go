@@ -0,0 +1,58 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package selinux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPathLabels(t *testing.T) {
+	labels := pathLabels("/var/lib/k0s", "/var/lib/k0s/bin", "/run/k0s")
+
+	var paths []string
+	for _, l := range labels {
+		paths = append(paths, l.path)
+	}
+
+	assert.Equal(t, []string{
+		"/var/lib/k0s",
+		"/var/lib/k0s/bin",
+		"/var/lib/k0s/containerd/io.containerd.snapshotter.v1.overlayfs",
+		"/var/lib/k0s/kubelet",
+		"/var/lib/k0s/kubelet/pods",
+		"/run/k0s",
+	}, paths, "The data dir needs to be labeled before its subdirectories")
+
+	assert.Equal(t, "container_var_lib_t", labels[0].seType)
+	assert.Equal(t, "container_runtime_exec_t", labels[1].seType)
+	assert.Equal(t, "container_var_run_t", labels[5].seType)
+}
+
+func TestKubeletPathLabels(t *testing.T) {
+	labels := kubeletPathLabels("/var/lib/k0s/kubelet")
+
+	assert.Equal(t, []pathLabel{
+		{"/var/lib/k0s/kubelet", "container_var_lib_t", false},
+		{"/var/lib/k0s/kubelet/pods", "container_file_t", false},
+	}, labels, "The pod volumes carry their own labels and mustn't be relabeled")
+}
+
+func TestFileContext(t *testing.T) {
+	assert.Equal(t, "system_u:object_r:container_file_t:s0", fileContext("container_file_t"))
+}
@@ -44,6 +44,13 @@ func (s *K0sSysinfoSpec) addHostSpecificProbes(p probes.Probes) {
 		probes.AssertExecutablesInPath(linux, "modprobe")
 		linux.RequireProcFS()
 		addCgroups(linux)
+		linux.AssertSystemdInhibitors()
+		linux.AssertSwap()
+		linux.AssertSELinux()
+
+		if s.RootlessEnabled {
+			addRootless(linux)
+		}
 	}
 
 	s.addKernelConfigs(linux)
@@ -175,6 +182,17 @@ func (s *K0sSysinfoSpec) addKernelConfigs(linux *linux.LinuxProbes) {
 	bridge.AssertKernelConfig("STP", "")
 }
 
+// addRootless adds the probes for the prerequisites of the experimental rootless worker mode.
+func addRootless(linux *linux.LinuxProbes) {
+	probes.AssertExecutablesInPath(linux,
+		"rootlesskit", "slirp4netns", "newuidmap", "newgidmap",
+		"fuse-overlayfs", "containerd-fuse-overlayfs-grpc",
+	)
+	linux.RequireUserNamespaces()
+	linux.RequireSubordinateIDs()
+	linux.RequireFUSE()
+}
+
 func addCgroups(linux *linux.LinuxProbes) {
 	cgroups := linux.RequireCgroups()
 	cgroups.RequireControllers(
@@ -190,4 +208,12 @@ func addCgroups(linux *linux.LinuxProbes) {
 		"hugetlb",
 		"blkio",
 	)
+	// Kubelet creates the pod cgroups below the root cgroup.
+	cgroups.RequireDelegatedControllers(
+		"cpu",
+		"cpuset",
+		"memory",
+		"pids",
+	)
+	cgroups.AssertSystemdVersion()
 }
@@ -0,0 +1,74 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysinfo
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/k0sproject/k0s/internal/pkg/sysinfo/probes"
+)
+
+// minWindowsBuild is the first Windows build that supports HostProcess
+// containers, i.e. Windows Server 2019 / Windows 10 1809.
+const minWindowsBuild = 17763
+
+func (s *K0sSysinfoSpec) addHostSpecificProbes(p probes.Probes) {
+	p.Set("os", func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+		return probes.ProbeFn(func(r probes.Reporter) error {
+			desc := probes.NewProbeDesc("Operating system", path)
+			version := windows.RtlGetVersion()
+			prop := probes.StringProp(fmt.Sprintf("Windows %d.%d.%d", version.MajorVersion, version.MinorVersion, version.BuildNumber))
+			if s.WorkerRoleEnabled && version.BuildNumber < minWindowsBuild {
+				return r.Reject(desc, prop, fmt.Sprintf("Windows build %d or newer required", minWindowsBuild))
+			}
+			return r.Pass(desc, prop)
+		})
+	})
+
+	if s.WorkerRoleEnabled {
+		p.Set("hns", func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+			return probes.ProbeFn(func(r probes.Reporter) error {
+				desc := probes.NewProbeDesc("Host Network Service", path)
+				if err := probeService("hns"); err != nil {
+					if errors.Is(err, windows.ERROR_SERVICE_DOES_NOT_EXIST) {
+						return r.Reject(desc, probes.ErrorProp(err), "enable the Containers feature")
+					}
+					return r.Error(desc, err)
+				}
+				return r.Pass(desc, probes.StringProp("installed"))
+			})
+		})
+	}
+}
+
+func probeService(name string) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("failed to connect to service manager: %w", err)
+	}
+	defer func() { _ = m.Disconnect() }()
+
+	svc, err := m.OpenService(name)
+	if err != nil {
+		return err
+	}
+	return svc.Close()
+}
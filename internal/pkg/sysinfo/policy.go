@@ -0,0 +1,76 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysinfo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PreFlightPolicy determines how the result of a pre-flight check is treated.
+type PreFlightPolicy string
+
+const (
+	// PreFlightEnforce fails on warnings, rejections and errors, even if the
+	// pre-flight checks are lenient.
+	PreFlightEnforce PreFlightPolicy = "enforce"
+	// PreFlightWarn logs rejections and errors as warnings.
+	PreFlightWarn PreFlightPolicy = "warn"
+	// PreFlightIgnore logs all results on debug level only.
+	PreFlightIgnore PreFlightPolicy = "ignore"
+)
+
+// ParsePreFlightPolicies parses the given pre-flight check policies, keyed by
+// the path of the checks they apply to, e.g. "os/kernelRelease" or
+// "os/cgroups". A policy applies to all checks below its path, unless there's
+// a more specific one.
+func ParsePreFlightPolicies(policies map[string]string) (map[string]PreFlightPolicy, error) {
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	parsed := make(map[string]PreFlightPolicy, len(policies))
+	for path, policy := range policies {
+		path = strings.Trim(path, "/")
+		if path == "" {
+			return nil, fmt.Errorf("pre-flight check path may not be empty")
+		}
+
+		switch p := PreFlightPolicy(policy); p {
+		case PreFlightEnforce, PreFlightWarn, PreFlightIgnore:
+			parsed[path] = p
+		default:
+			return nil, fmt.Errorf("unsupported policy for pre-flight check %q: %q (must be one of %s, %s or %s)",
+				path, policy, PreFlightEnforce, PreFlightWarn, PreFlightIgnore)
+		}
+	}
+
+	return parsed, nil
+}
+
+// policyFor returns the most specific policy that applies to the check at the
+// given path, if any.
+func policyFor(policies map[string]PreFlightPolicy, path []string) (string, PreFlightPolicy) {
+	for i := len(path); i > 0; i-- {
+		prefix := strings.Join(path[:i], "/")
+		if policy, ok := policies[prefix]; ok {
+			return prefix, policy
+		}
+	}
+
+	return "", ""
+}
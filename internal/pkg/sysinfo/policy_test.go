@@ -0,0 +1,104 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysinfo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/k0sproject/k0s/internal/pkg/sysinfo/probes"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePreFlightPolicies(t *testing.T) {
+	policies, err := ParsePreFlightPolicies(map[string]string{
+		"os/cgroups":        "enforce",
+		"/os/kernelRelease": "warn",
+		"memory":            "ignore",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]PreFlightPolicy{
+		"os/cgroups":       PreFlightEnforce,
+		"os/kernelRelease": PreFlightWarn,
+		"memory":           PreFlightIgnore,
+	}, policies)
+
+	_, err = ParsePreFlightPolicies(map[string]string{"os": "skip"})
+	assert.ErrorContains(t, err, `unsupported policy for pre-flight check "os": "skip"`)
+
+	_, err = ParsePreFlightPolicies(map[string]string{"/": "warn"})
+	assert.ErrorContains(t, err, "may not be empty")
+}
+
+func TestPreFlightReporter(t *testing.T) {
+	policies := map[string]PreFlightPolicy{
+		"os":               PreFlightEnforce,
+		"os/kernelRelease": PreFlightWarn,
+		"os/swap":          PreFlightIgnore,
+	}
+
+	for _, test := range []struct {
+		name    string
+		lenient bool
+		path    probes.ProbePath
+		report  func(*preFlightReporter, probes.ProbeDesc) error
+		failed  bool
+		level   logrus.Level
+	}{
+		{"warning_without_policy", false, probes.ProbePath{"memory"}, warn, false, logrus.WarnLevel},
+		{"rejection_without_policy", false, probes.ProbePath{"memory"}, reject, true, logrus.ErrorLevel},
+		{"lenient_rejection_without_policy", true, probes.ProbePath{"memory"}, reject, false, logrus.WarnLevel},
+		{"enforced_warning", true, probes.ProbePath{"os", "cgroups"}, warn, true, logrus.ErrorLevel},
+		{"enforced_rejection", true, probes.ProbePath{"os", "cgroups", "cpu"}, reject, true, logrus.ErrorLevel},
+		{"rejection_as_warning", false, probes.ProbePath{"os", "kernelRelease"}, reject, false, logrus.WarnLevel},
+		{"ignored_warning", false, probes.ProbePath{"os", "swap"}, warn, false, logrus.DebugLevel},
+		{"ignored_error", false, probes.ProbePath{"os", "swap"}, fail, false, logrus.DebugLevel},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			log, hook := logtest.NewNullLogger()
+			log.SetLevel(logrus.DebugLevel)
+			underTest := &preFlightReporter{
+				log:      logrus.NewEntry(log),
+				lenient:  test.lenient,
+				policies: policies,
+				matched:  make(map[string]bool),
+			}
+
+			assert.NoError(t, test.report(underTest, probes.NewProbeDesc(test.name, test.path)))
+			assert.Equal(t, test.failed, underTest.failed)
+			if entry := hook.LastEntry(); assert.NotNil(t, entry) {
+				assert.Equal(t, test.level, entry.Level)
+			}
+		})
+	}
+}
+
+func warn(r *preFlightReporter, d probes.ProbeDesc) error {
+	return r.Warn(d, probes.StringProp("foo"), "bar")
+}
+
+func reject(r *preFlightReporter, d probes.ProbeDesc) error {
+	return r.Reject(d, probes.StringProp("foo"), "bar")
+}
+
+func fail(r *preFlightReporter, d probes.ProbeDesc) error {
+	return r.Error(d, errors.New("foo"))
+}
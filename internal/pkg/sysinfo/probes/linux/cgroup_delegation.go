@@ -0,0 +1,93 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/k0sproject/k0s/internal/pkg/sysinfo/probes"
+)
+
+// RequireDelegatedControllers checks that the given cgroup v2 controllers are
+// enabled for the child cgroups of the cgroup hierarchy's root, so that
+// kubelet can use them for the pod cgroups it creates below it. This is a
+// no-op for cgroup v1, in which all controllers are available everywhere.
+func (c *CgroupsProbes) RequireDelegatedControllers(controllerNames ...string) {
+	c.Set("delegation", func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+		return probes.ProbeFn(func(r probes.Reporter) error {
+			desc := probes.NewProbeDesc("cgroup controller delegation", path)
+
+			sys, err := c.probeCgroupSystem()
+			if err != nil {
+				return reportCgroupSystemErr(r, desc, err)
+			}
+			v2, ok := sys.(*cgroupV2)
+			if !ok {
+				return r.Pass(desc, probes.StringProp("not applicable for cgroup "+sys.String()))
+			}
+
+			missing, writable, err := checkDelegatedControllers(v2.mountPoint, controllerNames)
+			switch {
+			case err != nil:
+				return r.Error(desc, err)
+			case len(missing) == 0:
+				return r.Pass(desc, probes.StringProp("delegated"))
+			case writable:
+				// The container runtime enables the controllers on demand.
+				return r.Pass(desc, probes.StringProp("delegated on demand"))
+			}
+
+			subtreeControl := filepath.Join(v2.mountPoint, "cgroup.subtree_control")
+			return r.Reject(desc, probes.StringProp("not delegated: "+strings.Join(missing, ", ")), fmt.Sprintf(
+				"enable them via `echo '+%s' > %s`, or, if k0s runs inside a container, make sure the controllers are delegated to it",
+				strings.Join(missing, " +"), subtreeControl,
+			))
+		})
+	})
+}
+
+// checkDelegatedControllers returns the given controllers that aren't enabled
+// in the cgroup.subtree_control file of the given cgroup directory, and whether
+// that file is writable, i.e. whether they can still be enabled.
+func checkDelegatedControllers(dir string, controllerNames []string) (missing []string, writable bool, _ error) {
+	subtreeControl := filepath.Join(dir, "cgroup.subtree_control")
+	data, err := os.ReadFile(subtreeControl)
+	if err != nil {
+		return nil, false, err
+	}
+
+	enabled := make(map[string]bool)
+	for _, name := range strings.Fields(string(data)) {
+		enabled[name] = true
+	}
+	for _, name := range controllerNames {
+		if !enabled[name] {
+			missing = append(missing, name)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil, false, nil
+	}
+
+	return missing, unix.Access(subtreeControl, unix.W_OK) == nil, nil
+}
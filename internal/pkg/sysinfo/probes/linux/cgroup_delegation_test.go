@@ -0,0 +1,45 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDelegatedControllers(t *testing.T) {
+	dir := t.TempDir()
+	subtreeControl := filepath.Join(dir, "cgroup.subtree_control")
+
+	_, _, err := checkDelegatedControllers(dir, []string{"cpu"})
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(subtreeControl, []byte("cpu memory pids\n"), 0644))
+
+	missing, _, err := checkDelegatedControllers(dir, []string{"cpu", "memory", "pids"})
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+
+	missing, writable, err := checkDelegatedControllers(dir, []string{"cpu", "cpuset", "memory", "io"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"cpuset", "io"}, missing)
+	assert.True(t, writable)
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/k0sproject/k0s/internal/pkg/sysinfo/probes"
+)
+
+// AssertSystemdInhibitors checks that systemd-logind is available, so that
+// kubelet can take an inhibitor lock to delay node shutdowns until its pods
+// have been terminated gracefully.
+func (l *LinuxProbes) AssertSystemdInhibitors() {
+	l.Set("systemdInhibitors", func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+		return probes.ProbeFn(func(r probes.Reporter) error {
+			desc := probes.NewProbeDesc("systemd inhibitor locks", path)
+
+			reason, err := checkSystemdInhibitors("/")
+			if err != nil {
+				return r.Warn(desc, probes.ErrorProp(err), "")
+			}
+			if reason != "" {
+				return r.Warn(desc, probes.StringProp("unavailable"), reason+", graceful node shutdown won't work")
+			}
+
+			return r.Pass(desc, probes.StringProp("available"))
+		})
+	})
+}
+
+// checkSystemdInhibitors checks the prerequisites of systemd inhibitor locks
+// below the given root directory. Returns the reason why they're unavailable,
+// if any.
+func checkSystemdInhibitors(root string) (string, error) {
+	for _, prerequisite := range []struct{ path, reason string }{
+		// See sd_booted(3).
+		{"run/systemd/system", "systemd is not running"},
+		{"run/dbus/system_bus_socket", "D-Bus system bus is not available"},
+		// Created by systemd-logind on startup.
+		{"run/systemd/seats", "systemd-logind is not running"},
+	} {
+		_, err := os.Stat(filepath.Join(root, prerequisite.path))
+		if errors.Is(err, fs.ErrNotExist) {
+			return prerequisite.reason, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed to check for /%s: %w", prerequisite.path, err)
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSystemdInhibitors(t *testing.T) {
+	root := t.TempDir()
+
+	for _, step := range []struct{ path, reason string }{
+		{"run/systemd/system", "systemd is not running"},
+		{"run/dbus/system_bus_socket", "D-Bus system bus is not available"},
+		{"run/systemd/seats", "systemd-logind is not running"},
+	} {
+		reason, err := checkSystemdInhibitors(root)
+		assert.NoError(t, err)
+		assert.Equal(t, step.reason, reason)
+
+		require.NoError(t, os.MkdirAll(filepath.Join(root, step.path), 0755))
+	}
+
+	reason, err := checkSystemdInhibitors(root)
+	assert.NoError(t, err)
+	assert.Empty(t, reason)
+}
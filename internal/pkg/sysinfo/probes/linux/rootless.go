@@ -0,0 +1,145 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"golang.org/x/sys/unix"
+
+	"github.com/k0sproject/k0s/internal/pkg/sysinfo/probes"
+)
+
+// RequireUserNamespaces checks that unprivileged users may create user namespaces.
+func (l *LinuxProbes) RequireUserNamespaces() {
+	l.Set("userNamespaces", func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+		return probes.ProbeFn(func(r probes.Reporter) error {
+			desc := probes.NewProbeDesc("Unprivileged user namespaces", path)
+
+			maxUserNamespaces, err := readProcSysInt("/proc/sys/user/max_user_namespaces")
+			if err != nil {
+				return r.Error(desc, err)
+			}
+			if maxUserNamespaces < 1 {
+				return r.Reject(desc, probes.StringProp(fmt.Sprintf("user.max_user_namespaces = %d", maxUserNamespaces)), "user namespaces are disabled")
+			}
+
+			// This sysctl only exists on Debian and derived kernels.
+			userNSClone, err := readProcSysInt("/proc/sys/kernel/unprivileged_userns_clone")
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return r.Error(desc, err)
+			}
+			if err == nil && userNSClone != 1 {
+				return r.Reject(desc, probes.StringProp(fmt.Sprintf("kernel.unprivileged_userns_clone = %d", userNSClone)), "unprivileged user namespaces are disabled")
+			}
+
+			return r.Pass(desc, probes.StringProp(fmt.Sprintf("user.max_user_namespaces = %d", maxUserNamespaces)))
+		})
+	})
+}
+
+// RequireSubordinateIDs checks that the current user has been assigned
+// subordinate user and group ID ranges.
+func (l *LinuxProbes) RequireSubordinateIDs() {
+	for _, idFile := range []string{"/etc/subuid", "/etc/subgid"} {
+		idFile := idFile
+		l.Set(fmt.Sprintf("subordinateIDs:%s", idFile), func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+			return probes.ProbeFn(func(r probes.Reporter) error {
+				desc := probes.NewProbeDesc(fmt.Sprintf("Subordinate IDs in %s", idFile), path)
+
+				current, err := user.Current()
+				if err != nil {
+					return r.Error(desc, err)
+				}
+
+				count, err := countSubordinateIDs(idFile, current)
+				if err != nil {
+					if errors.Is(err, fs.ErrNotExist) {
+						return r.Reject(desc, probes.ErrorProp(err), "")
+					}
+					return r.Error(desc, err)
+				}
+				// Kubernetes pods require at least 65536 IDs to work properly.
+				if count < 65536 {
+					return r.Reject(desc, probes.StringProp(fmt.Sprintf("%d for %s", count, current.Username)), "at least 65536 required")
+				}
+
+				return r.Pass(desc, probes.StringProp(fmt.Sprintf("%d for %s", count, current.Username)))
+			})
+		})
+	}
+}
+
+// RequireFUSE checks that the FUSE device is accessible, as needed by fuse-overlayfs.
+func (l *LinuxProbes) RequireFUSE() {
+	l.Set("fuse", func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+		return probes.ProbeFn(func(r probes.Reporter) error {
+			const fuseDevice = "/dev/fuse"
+			desc := probes.NewProbeDesc(fmt.Sprintf("FUSE device %s", fuseDevice), path)
+
+			if err := unix.Access(fuseDevice, unix.R_OK|unix.W_OK); err != nil {
+				return r.Reject(desc, probes.ErrorProp(&fs.PathError{Op: "access", Path: fuseDevice, Err: err}), "")
+			}
+
+			return r.Pass(desc, probes.StringProp("accessible"))
+		})
+	})
+}
+
+func readProcSysInt(path string) (int64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(strings.TrimSpace(string(content)), 10, 64)
+}
+
+// countSubordinateIDs sums up the ranges in a subuid(5) or subgid(5) file that
+// are assigned to the given user, either by name or by ID.
+func countSubordinateIDs(idFile string, u *user.User) (uint64, error) {
+	f, err := os.Open(idFile)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var count uint64
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Split(strings.TrimSpace(scanner.Text()), ":")
+		if len(fields) != 3 || (fields[0] != u.Username && fields[0] != u.Uid) {
+			continue
+		}
+		n, err := strconv.ParseUint(fields[2], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid entry in %s: %w", idFile, err)
+		}
+		count += n
+	}
+
+	return count, scanner.Err()
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"github.com/k0sproject/k0s/internal/pkg/selinux"
+	"github.com/k0sproject/k0s/internal/pkg/sysinfo/probes"
+)
+
+// AssertSELinux reports the SELinux mode and warns if SELinux is enabled, but
+// the container-selinux policy, which is required to label the k0s
+// directories and containers, isn't installed.
+func (l *LinuxProbes) AssertSELinux() {
+	l.Set("selinux", func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+		return probes.ProbeFn(func(r probes.Reporter) error {
+			desc := probes.NewProbeDesc("SELinux", path)
+			if !selinux.Enabled() {
+				return r.Pass(desc, probes.StringProp("disabled"))
+			}
+
+			mode := probes.StringProp(selinux.Mode())
+			if !selinux.ContainerPolicyLoaded() {
+				return r.Warn(desc, mode,
+					"the container-selinux policy is not installed, containers may fail to start; "+
+						"install it and re-run `k0s install` to label the k0s directories",
+				)
+			}
+
+			return r.Pass(desc, mode)
+		})
+	})
+}
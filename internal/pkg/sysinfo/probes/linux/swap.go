@@ -0,0 +1,78 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/k0sproject/k0s/internal/pkg/sysinfo/probes"
+)
+
+// AssertSwap checks if swap is enabled. k0s configures kubelet to tolerate
+// swap (failSwapOn: false), but kubelet refuses to start on nodes with swap if
+// a worker profile overrides that.
+func (l *LinuxProbes) AssertSwap() {
+	l.Set("swap", func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+		return probes.ProbeFn(func(r probes.Reporter) error {
+			desc := probes.NewProbeDesc("swap", path)
+
+			f, err := os.Open("/proc/swaps")
+			if err != nil {
+				return r.Warn(desc, probes.ErrorProp(err), "")
+			}
+			defer f.Close()
+
+			devices, err := parseSwaps(f)
+			if err != nil {
+				return r.Warn(desc, probes.ErrorProp(err), "")
+			}
+			if len(devices) == 0 {
+				return r.Pass(desc, probes.StringProp("disabled"))
+			}
+
+			return r.Warn(desc, probes.StringProp("enabled: "+strings.Join(devices, ", ")),
+				"kubelet won't start if failSwapOn is set to true in the worker profile; "+
+					"either keep failSwapOn: false, or disable swap via `swapoff -a` and remove it from /etc/fstab",
+			)
+		})
+	})
+}
+
+// parseSwaps returns the active swap devices listed in the given /proc/swaps
+// content.
+func parseSwaps(r io.Reader) ([]string, error) {
+	var devices []string
+	lines := bufio.NewScanner(r)
+	for first := true; lines.Scan(); first = false {
+		if first {
+			// Skip the header line.
+			continue
+		}
+		if fields := strings.Fields(lines.Text()); len(fields) > 0 {
+			devices = append(devices, fields[0])
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read swaps: %w", err)
+	}
+
+	return devices, nil
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSwaps(t *testing.T) {
+	const header = "Filename\t\t\t\tType\t\tSize\t\tUsed\t\tPriority\n"
+
+	devices, err := parseSwaps(strings.NewReader(header))
+	assert.NoError(t, err)
+	assert.Empty(t, devices)
+
+	devices, err = parseSwaps(strings.NewReader(header +
+		"/swap.img                               file\t\t2097148\t\t0\t\t-2\n" +
+		"/dev/sda2                               partition\t1048572\t\t0\t\t-3\n",
+	))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/swap.img", "/dev/sda2"}, devices)
+}
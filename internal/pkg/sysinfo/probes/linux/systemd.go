@@ -0,0 +1,90 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/k0sproject/k0s/internal/pkg/sysinfo/probes"
+)
+
+// minSystemdVersionCgroupV2 is the systemd version that's required to
+// delegate all cgroup v2 controllers that kubelet needs. Older versions don't
+// support the cpuset controller.
+const minSystemdVersionCgroupV2 = 244
+
+// AssertSystemdVersion checks that a running systemd is recent enough to
+// manage the cgroup version in use. Hosts without systemd pass.
+func (c *CgroupsProbes) AssertSystemdVersion() {
+	c.Set("systemd", func(path probes.ProbePath, _ probes.Probe) probes.Probe {
+		return probes.ProbeFn(func(r probes.Reporter) error {
+			desc := probes.NewProbeDesc("systemd version", path)
+
+			// See sd_booted(3).
+			if _, err := os.Stat("/run/systemd/system"); errors.Is(err, fs.ErrNotExist) {
+				return r.Pass(desc, probes.StringProp("not running"))
+			} else if err != nil {
+				return r.Warn(desc, probes.ErrorProp(err), "")
+			}
+
+			out, err := exec.Command("systemctl", "--version").Output()
+			if err != nil {
+				return r.Warn(desc, probes.ErrorProp(fmt.Errorf("failed to run systemctl: %w", err)), "")
+			}
+			version, err := parseSystemdVersion(string(out))
+			if err != nil {
+				return r.Warn(desc, probes.ErrorProp(err), "")
+			}
+
+			prop := probes.StringProp(strconv.Itoa(version))
+			sys, err := c.probeCgroupSystem()
+			if err != nil {
+				return reportCgroupSystemErr(r, desc, err)
+			}
+			if _, isV2 := sys.(*cgroupV2); isV2 && version < minSystemdVersionCgroupV2 {
+				return r.Warn(desc, prop, fmt.Sprintf(
+					"systemd %d or newer is required to delegate all cgroup v2 controllers; upgrade systemd, or boot with cgroup v1 (systemd.unified_cgroup_hierarchy=0)",
+					minSystemdVersionCgroupV2,
+				))
+			}
+
+			return r.Pass(desc, prop)
+		})
+	})
+}
+
+// parseSystemdVersion parses the version from the output of
+// `systemctl --version`, whose first line looks like "systemd 249 (249.11-0ubuntu3)".
+func parseSystemdVersion(out string) (int, error) {
+	firstLine, _, _ := strings.Cut(out, "\n")
+	fields := strings.Fields(firstLine)
+	if len(fields) < 2 || fields[0] != "systemd" {
+		return 0, fmt.Errorf("unexpected systemctl output: %q", firstLine)
+	}
+	version, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return 0, fmt.Errorf("unexpected systemd version: %q", fields[1])
+	}
+
+	return version, nil
+}
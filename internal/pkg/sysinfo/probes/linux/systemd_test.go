@@ -0,0 +1,39 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package linux
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSystemdVersion(t *testing.T) {
+	version, err := parseSystemdVersion("systemd 249 (249.11-0ubuntu3.9)\n+PAM +AUDIT +SELINUX\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 249, version)
+
+	version, err = parseSystemdVersion("systemd 239\n")
+	assert.NoError(t, err)
+	assert.Equal(t, 239, version)
+
+	_, err = parseSystemdVersion("")
+	assert.ErrorContains(t, err, "unexpected systemctl output")
+
+	_, err = parseSystemdVersion("systemd v250")
+	assert.ErrorContains(t, err, `unexpected systemd version: "v250"`)
+}
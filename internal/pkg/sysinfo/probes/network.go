@@ -0,0 +1,102 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probes
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// dialTimeout is the time after which endpoints are considered unreachable.
+const dialTimeout = 5 * time.Second
+
+// RequireFreePort requires that the given TCP port can be listened on by the
+// given component.
+func RequireFreePort(parent ParentProbe, port int, component string) {
+	parent.Set("port:"+strconv.Itoa(port), func(path ProbePath, _ Probe) Probe {
+		return ProbeFn(func(r Reporter) error {
+			desc := NewProbeDesc(fmt.Sprintf("TCP port %d (%s)", port, component), path)
+			l, err := net.Listen("tcp", net.JoinHostPort("", strconv.Itoa(port)))
+			if err != nil {
+				return r.Reject(desc, ErrorProp(err), fmt.Sprintf(
+					"%s needs to listen on this port; stop the process that's using it, e.g. find it via `ss -ltnp 'sport = :%d'`",
+					component, port,
+				))
+			}
+			if err := l.Close(); err != nil {
+				return r.Error(desc, err)
+			}
+
+			return r.Pass(desc, StringProp("free"))
+		})
+	})
+}
+
+// RequireFreeSocket requires that no process is listening on the given unix
+// socket, so that the given component can listen on it. Stale sockets are
+// replaced by the component.
+func RequireFreeSocket(parent ParentProbe, socketPath string, component string) {
+	parent.Set("socket:"+socketPath, func(path ProbePath, _ Probe) Probe {
+		return ProbeFn(func(r Reporter) error {
+			desc := NewProbeDesc(fmt.Sprintf("Unix socket %s (%s)", socketPath, component), path)
+			if _, err := os.Stat(socketPath); errors.Is(err, fs.ErrNotExist) {
+				return r.Pass(desc, StringProp("free"))
+			} else if err != nil {
+				return r.Error(desc, err)
+			}
+
+			conn, err := net.DialTimeout("unix", socketPath, dialTimeout)
+			if err != nil {
+				return r.Pass(desc, StringProp("stale"))
+			}
+			if err := conn.Close(); err != nil {
+				return r.Error(desc, err)
+			}
+
+			return r.Reject(desc, StringProp("in use"), fmt.Sprintf(
+				"%s needs to listen on this socket; stop the process that's using it, e.g. another instance of k0s",
+				component,
+			))
+		})
+	})
+}
+
+// RequireReachable requires that a TCP connection can be established to the
+// given address in the form of "host:port".
+func RequireReachable(parent ParentProbe, address string, name string) {
+	parent.Set("endpoint:"+address, func(path ProbePath, _ Probe) Probe {
+		return ProbeFn(func(r Reporter) error {
+			desc := NewProbeDesc(fmt.Sprintf("%s at %s", name, address), path)
+			conn, err := net.DialTimeout("tcp", address, dialTimeout)
+			if err != nil {
+				return r.Reject(desc, ErrorProp(err),
+					"check that the address is correct and that it's reachable from this host, e.g. that no firewall is blocking the connection",
+				)
+			}
+			if err := conn.Close(); err != nil {
+				return r.Error(desc, err)
+			}
+
+			return r.Pass(desc, StringProp("reachable"))
+		})
+	})
+}
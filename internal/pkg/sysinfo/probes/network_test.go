@@ -0,0 +1,100 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package probes
+
+import (
+	"net"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNetworkProbes(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	inUse := l.Addr().(*net.TCPAddr).Port
+
+	t.Run("RequireFreePort", func(t *testing.T) {
+		free, err := net.Listen("tcp", ":0")
+		require.NoError(t, err)
+		freePort := free.Addr().(*net.TCPAddr).Port
+		require.NoError(t, free.Close())
+
+		p := NewRootProbes()
+		RequireFreePort(p, inUse, "foo")
+		RequireFreePort(p, freePort, "bar")
+
+		var r statusReporter
+		require.NoError(t, p.Probe(&r))
+		assert.Equal(t, []string{"rejected", "pass"}, r.statuses)
+	})
+
+	t.Run("RequireReachable", func(t *testing.T) {
+		p := NewRootProbes()
+		RequireReachable(p, net.JoinHostPort("127.0.0.1", strconv.Itoa(inUse)), "foo")
+
+		var r statusReporter
+		require.NoError(t, p.Probe(&r))
+		assert.Equal(t, []string{"pass"}, r.statuses)
+	})
+
+	t.Run("RequireFreeSocket", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("unix sockets aren't tested on Windows")
+		}
+
+		dir := t.TempDir()
+		socketPath := filepath.Join(dir, "in-use.sock")
+		l, err := net.Listen("unix", socketPath)
+		require.NoError(t, err)
+		defer l.Close()
+
+		p := NewRootProbes()
+		RequireFreeSocket(p, socketPath, "foo")
+		RequireFreeSocket(p, filepath.Join(dir, "free.sock"), "bar")
+
+		var r statusReporter
+		require.NoError(t, p.Probe(&r))
+		assert.Equal(t, []string{"rejected", "pass"}, r.statuses)
+	})
+}
+
+type statusReporter struct{ statuses []string }
+
+func (r *statusReporter) Pass(ProbeDesc, ProbedProp) error {
+	r.statuses = append(r.statuses, "pass")
+	return nil
+}
+
+func (r *statusReporter) Warn(ProbeDesc, ProbedProp, string) error {
+	r.statuses = append(r.statuses, "warning")
+	return nil
+}
+
+func (r *statusReporter) Reject(ProbeDesc, ProbedProp, string) error {
+	r.statuses = append(r.statuses, "rejected")
+	return nil
+}
+
+func (r *statusReporter) Error(_ ProbeDesc, err error) error {
+	return err
+}
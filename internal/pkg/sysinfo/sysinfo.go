@@ -19,6 +19,7 @@ package sysinfo
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/k0sproject/k0s/internal/pkg/sysinfo/probes"
@@ -29,18 +30,48 @@ import (
 type K0sSysinfoSpec struct {
 	ControllerRoleEnabled bool
 	WorkerRoleEnabled     bool
+	RootlessEnabled       bool
 	DataDir               string
 
 	// This is mainly for the sysinfo CLI subcommand.
 	AddDebugProbes bool
 
+	// Local TCP ports that need to be free, mapped to the components that
+	// will listen on them.
+	Ports map[int]string
+	// Unix sockets that need to be free, mapped to the components that will
+	// listen on them.
+	Sockets map[string]string
+	// Addresses in the form of "host:port" that need to be reachable, e.g.
+	// the join address, mapped to what they are.
+	Endpoints map[string]string
+
+	// Policies for individual pre-flight checks, keyed by their paths.
+	Policies map[string]PreFlightPolicy
+
 	// May be extended with more flags in the future, e.g. for
 	// kube-router, calico, konnectivity, ...
 }
 
+// RunPreFlightChecks runs the pre-flight checks and returns an error if any of
+// them failed. If lenient is true, only failures of checks whose policy is
+// "enforce" are returned.
 func (s *K0sSysinfoSpec) RunPreFlightChecks(lenient bool) error {
-	reporter := &preFlightReporter{log: logrus.NewEntry(logrus.StandardLogger()), lenient: lenient}
-	if err := s.NewSysinfoProbes().Probe(reporter); err != nil {
+	reporter := &preFlightReporter{
+		log:      logrus.NewEntry(logrus.StandardLogger()),
+		lenient:  lenient,
+		policies: s.Policies,
+		matched:  make(map[string]bool),
+	}
+	err := s.NewSysinfoProbes().Probe(reporter)
+
+	for path := range s.Policies {
+		if !reporter.matched[path] {
+			logrus.Warnf("No pre-flight check matches the policy for %q", path)
+		}
+	}
+
+	if err != nil {
 		return fmt.Errorf("pre-flight checks failed, check out `k0s sysinfo`: %w", err)
 	}
 
@@ -74,17 +105,49 @@ func (s *K0sSysinfoSpec) NewSysinfoProbes() probes.Probes {
 	}
 	probes.AssertFreeDiskSpace(p, s.DataDir, minFreeDiskSpace)
 
+	s.addNetworkProbes(p)
 	s.addHostSpecificProbes(p)
 
 	return p
 }
 
+func (s *K0sSysinfoSpec) addNetworkProbes(p probes.Probes) {
+	ports := make([]int, 0, len(s.Ports))
+	for port := range s.Ports {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+	for _, port := range ports {
+		probes.RequireFreePort(p, port, s.Ports[port])
+	}
+
+	for _, socket := range sortedKeys(s.Sockets) {
+		probes.RequireFreeSocket(p, socket, s.Sockets[socket])
+	}
+
+	for _, address := range sortedKeys(s.Endpoints) {
+		probes.RequireReachable(p, address, s.Endpoints[address])
+	}
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
 type preFlightReporter struct {
 	log             *logrus.Entry
 	lenient, failed bool
+	policies        map[string]PreFlightPolicy
+	matched         map[string]bool
 }
 
 func (p *preFlightReporter) Pass(d probes.ProbeDesc, prop probes.ProbedProp) error {
+	p.policy(d)
 	if p.log.Logger.IsLevelEnabled(logrus.DebugLevel) {
 		p.logger(d, prop).Debug("")
 	}
@@ -92,39 +155,77 @@ func (p *preFlightReporter) Pass(d probes.ProbeDesc, prop probes.ProbedProp) err
 }
 
 func (p *preFlightReporter) Warn(d probes.ProbeDesc, prop probes.ProbedProp, msg string) error {
-	p.logger(d, prop).Warn(msg)
+	switch p.policy(d) {
+	case PreFlightIgnore:
+		p.logger(d, prop).Debug(msg)
+	case PreFlightEnforce:
+		return p.Reject(d, prop, msg)
+	default:
+		p.logger(d, prop).Warn(msg)
+	}
 	return nil
 }
 
 func (p *preFlightReporter) Reject(d probes.ProbeDesc, prop probes.ProbedProp, msg string) error {
-	p.failed = true
-
-	level := logrus.ErrorLevel
-	if p.lenient {
-		level = logrus.WarnLevel
-	}
-
 	if msg == "" {
 		msg = "Rejected"
 	} else {
 		msg = "Rejected: " + msg
 	}
 
+	level := logrus.ErrorLevel
+	switch p.policy(d) {
+	case PreFlightIgnore:
+		level = logrus.DebugLevel
+	case PreFlightWarn:
+		level = logrus.WarnLevel
+	case PreFlightEnforce:
+		p.failed = true
+	default:
+		if p.lenient {
+			level = logrus.WarnLevel
+		} else {
+			p.failed = true
+		}
+	}
+
 	p.logger(d, prop).Log(level, msg)
 
 	return nil
 }
 
 func (p *preFlightReporter) Error(d probes.ProbeDesc, err error) error {
-	p.failed = true
+	switch p.policy(d) {
+	case PreFlightIgnore:
+		p.logger(d, nil).Debug(err)
+		return nil
+	case PreFlightWarn:
+		p.logger(d, nil).Warn(err)
+		return nil
+	case PreFlightEnforce:
+		p.failed = true
+		return err
+	}
+
 	if p.lenient {
 		p.logger(d, nil).Error(err)
 		return nil
 	}
 
+	p.failed = true
 	return err
 }
 
+// policy returns the policy for the given check and remembers that it has
+// been applied.
+func (p *preFlightReporter) policy(d probes.ProbeDesc) PreFlightPolicy {
+	path, policy := policyFor(p.policies, d.Path())
+	if path != "" {
+		p.matched[path] = true
+	}
+	return policy
+}
+
 func (p *preFlightReporter) logger(desc probes.ProbeDesc, prop probes.ProbedProp) *logrus.Entry {
 	log := p.log.WithField("pre-flight-check", strings.Join(desc.Path(), "/"))
 	if prop != nil {
@@ -20,8 +20,11 @@ import (
 	"fmt"
 	"k8s.io/client-go/rest"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/discovery/cached/memory"
 	discoveryfake "k8s.io/client-go/discovery/fake"
@@ -50,9 +53,12 @@ func NewFakeClientFactory(objects ...runtime.Object) FakeClientFactory {
 		{Group: "apps", Version: "v1", Resource: "deployments"}:                               "DeploymentList",
 	}
 
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvkLists)
+	dynamicClient.PrependReactor("patch", "*", applyPatchReactor(dynamicClient.Tracker()))
+
 	return FakeClientFactory{
 		Client:          fake.NewSimpleClientset(objects...),
-		DynamicClient:   dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), gvkLists),
+		DynamicClient:   dynamicClient,
 		DiscoveryClient: memory.NewMemCacheClient(rawDiscovery),
 		RawDiscovery:    rawDiscovery,
 		RESTClient:      &restfake.RESTClient{},
@@ -89,3 +95,34 @@ func (f FakeClientFactory) GetRESTClient() (rest.Interface, error) {
 func (f FakeClientFactory) GetRESTConfig() *rest.Config {
 	return &rest.Config{}
 }
+
+// applyPatchReactor emulates server-side apply, which isn't supported by the
+// fake dynamic client for unstructured objects. Applied objects are created if
+// they don't exist and replaced otherwise. Field ownership is not tracked.
+func applyPatchReactor(tracker kubetesting.ObjectTracker) kubetesting.ReactionFunc {
+	return func(action kubetesting.Action) (bool, runtime.Object, error) {
+		patchAction, ok := action.(kubetesting.PatchAction)
+		if !ok || patchAction.GetPatchType() != types.ApplyPatchType {
+			return false, nil, nil
+		}
+
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(patchAction.GetPatch()); err != nil {
+			return true, nil, err
+		}
+
+		gvr, ns, name := patchAction.GetResource(), patchAction.GetNamespace(), patchAction.GetName()
+		if _, err := tracker.Get(gvr, ns, name); apierrors.IsNotFound(err) {
+			if err := tracker.Create(gvr, obj, ns); err != nil {
+				return true, nil, err
+			}
+		} else if err != nil {
+			return true, nil, err
+		} else if err := tracker.Update(gvr, obj, ns); err != nil {
+			return true, nil, err
+		}
+
+		applied, err := tracker.Get(gvr, ns, name)
+		return true, applied, err
+	}
+}
@@ -53,6 +53,56 @@ func GetImageURIs(spec *v1beta1.ClusterSpec, all bool) []string {
 				}
 			}
 		}
+
+		nodeLocalDNS := spec.Network.NodeLocalDNSCache
+		if nodeLocalDNS.IsEnabled() && nodeLocalDNS.Image != nil {
+			imageURIs = append(imageURIs, nodeLocalDNS.Image.URI())
+		} else if all {
+			imageURIs = append(imageURIs, v1beta1.DefaultNodeLocalDNSCacheImage().URI())
+		}
+
+		slb := spec.Network.ServiceLoadBalancer
+		if slb.IsEnabled() && slb.Type == v1beta1.ServiceLoadBalancerTypeMetalLB && slb.MetalLB != nil {
+			for _, image := range []*v1beta1.ImageSpec{slb.MetalLB.ControllerImage, slb.MetalLB.SpeakerImage} {
+				if image != nil {
+					imageURIs = append(imageURIs, image.URI())
+				}
+			}
+		} else if all {
+			imageURIs = append(imageURIs,
+				v1beta1.DefaultMetalLBControllerImage().URI(),
+				v1beta1.DefaultMetalLBSpeakerImage().URI(),
+			)
+		}
+	}
+
+	if snapshotController := spec.SnapshotController; snapshotController.IsEnabled() && snapshotController.Image != nil {
+		imageURIs = append(imageURIs, snapshotController.Image.URI())
+	} else if all {
+		imageURIs = append(imageURIs, v1beta1.DefaultSnapshotControllerImage().URI())
+	}
+
+	var ingress *v1beta1.IngressExtension
+	var cloudProvider *v1beta1.CloudProviderExtension
+	if spec.Extensions != nil {
+		ingress = spec.Extensions.Ingress
+		cloudProvider = spec.Extensions.CloudProvider
+	}
+	if ingress.IsEnabled() && ingress.Image != nil {
+		imageURIs = append(imageURIs, ingress.Image.URI())
+	} else if all {
+		imageURIs = append(imageURIs, v1beta1.DefaultIngressImage().URI())
+	}
+	if cloudProvider.IsEnabled() && cloudProvider.Image != nil {
+		imageURIs = append(imageURIs, cloudProvider.Image.URI())
+	} else if all {
+		for _, provider := range []v1beta1.CloudProviderType{
+			v1beta1.CloudProviderAWS,
+			v1beta1.CloudProviderOpenStack,
+			v1beta1.CloudProviderHetzner,
+		} {
+			imageURIs = append(imageURIs, v1beta1.DefaultCloudProviderImage(provider).URI())
+		}
 	}
 
 	return imageURIs
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// StackNamespace is the namespace in which the Stack objects reflecting
+	// the stacks in the manifests directory are maintained.
+	StackNamespace = "kube-system"
+
+	// ReadyCondition indicates whether the last apply of a stack succeeded.
+	ReadyCondition = "Ready"
+)
+
+// StackStatus defines the observed state of a stack in the manifests directory
+type StackStatus struct {
+	// Revision identifies the set of manifests that have been applied last.
+	Revision string `json:"revision,omitempty"`
+	// Resources is the number of resources in the stack.
+	Resources int `json:"resources"`
+	// PrunedResources is the number of resources that have been pruned during
+	// the last apply.
+	PrunedResources int `json:"prunedResources"`
+	// LastAppliedTime is the time of the last apply attempt.
+	LastAppliedTime *metav1.Time `json:"lastAppliedTime,omitempty"`
+	// LastError is the error of the last apply attempt, if it failed.
+	LastError string `json:"lastError,omitempty"`
+	// Conditions of the stack.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Resources",type="integer",JSONPath=".status.resources"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// Stack reflects the status of a stack in the k0s manifests directory
+type Stack struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status StackStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// StackList contains a list of Stack
+type StackList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Stack `json:"items"`
+}
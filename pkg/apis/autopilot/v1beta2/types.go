@@ -46,6 +46,68 @@ type ControlNode struct {
 // ControlNodeStatus has the runtime status info of the controller such as address etc.
 type ControlNodeStatus struct {
 	Addresses []corev1.NodeAddress `json:"addresses,omitempty"`
+
+	// APIEndpoints is the reachability of the cluster's API endpoints, i.e.
+	// the external address and the SANs, as seen from this controller.
+	// +optional
+	APIEndpoints []APIEndpointStatus `json:"apiEndpoints,omitempty"`
+
+	// Controller is the state of the k0s controller on this node, as reported
+	// by the controller itself.
+	// +optional
+	Controller *ControllerStatus `json:"controller,omitempty"`
+}
+
+// ControllerStatus is the state of a k0s controller.
+type ControllerStatus struct {
+	// Version is the k0s version of the controller.
+	Version string `json:"version"`
+
+	// ConfigHash is the hash of the controller's node config. Controllers
+	// that report different hashes run with different configurations.
+	ConfigHash string `json:"configHash"`
+
+	// Leases are the names of the leader leases that the controller holds.
+	// +optional
+	Leases []string `json:"leases,omitempty"`
+
+	// Etcd is the health of the controller's etcd member, if it runs one.
+	// +optional
+	Etcd *EtcdMemberStatus `json:"etcd,omitempty"`
+
+	// LastHeartbeatTime is the time at which the controller reported its
+	// status the last time.
+	LastHeartbeatTime metav1.Time `json:"lastHeartbeatTime"`
+}
+
+// EtcdMemberStatus is the result of the latest health check of an etcd member.
+type EtcdMemberStatus struct {
+	// Healthy is true if the member's health check succeeded.
+	Healthy bool `json:"healthy"`
+
+	// Message describes why the health check failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// APIEndpointStatus is the result of the latest health check of an API endpoint.
+type APIEndpointStatus struct {
+	// Address is the address of the endpoint.
+	Address string `json:"address"`
+
+	// Reachable is true if the endpoint's health check succeeded.
+	Reachable bool `json:"reachable"`
+
+	// Message describes why the health check failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastCheckTime is the time of the latest health check.
+	LastCheckTime metav1.Time `json:"lastCheckTime"`
+
+	// LastTransitionTime is the time at which the endpoint's reachability
+	// changed the last time.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
 }
 
 // GetInternalIP returns the internal IP address for the object. Returns empty string if the object does not have InternalIP set.
@@ -143,6 +205,16 @@ type PlanCommandK0sUpdate struct {
 
 	// Targets defines how the controllers/workers should be discovered and upgraded.
 	Targets PlanCommandTargets `json:"targets"`
+
+	// Verification enforces the verification of the downloaded k0s binaries.
+	Verification *PlanCommandVerification `json:"verification,omitempty"`
+
+	// ProxyDownloads makes workers download the k0s binaries through the k0s API
+	// of the controllers, instead of directly from the platform URLs.
+	ProxyDownloads bool `json:"proxyDownloads,omitempty"`
+
+	// Hooks are commands that are run on each node before and after it is updated.
+	Hooks *PlanCommandHooks `json:"hooks,omitempty"`
 }
 
 // PlanCommandAirgapUpdate provides all of the information to for a `AirgapUpdate` command to
@@ -157,6 +229,13 @@ type PlanCommandAirgapUpdate struct {
 
 	// Workers defines how the k0s workers will be discovered and airgap updated.
 	Workers PlanCommandTarget `json:"workers"`
+
+	// Verification enforces the verification of the downloaded airgap bundles.
+	Verification *PlanCommandVerification `json:"verification,omitempty"`
+
+	// ProxyDownloads makes workers download the airgap bundles through the k0s
+	// API of the controllers, instead of directly from the platform URLs.
+	ProxyDownloads bool `json:"proxyDownloads,omitempty"`
 }
 
 // PlanResourceURL is a remote URL resource.
@@ -168,6 +247,46 @@ type PlanResourceURL struct {
 	Sha256 string `json:"sha256,omitempty"`
 }
 
+// PlanCommandVerification describes how downloaded update artifacts are verified.
+// Unless disabled, every artifact needs to be verified against either its SHA256
+// hash or a signature made by one of the trusted public keys.
+type PlanCommandVerification struct {
+	// Disabled turns off the mandatory verification. Artifacts are still verified
+	// against their SHA256 hash, if provided.
+	Disabled bool `json:"disabled,omitempty"`
+
+	// PublicKeys is a list of PEM encoded public keys (ECDSA or RSA) that are
+	// trusted to sign update artifacts. If not empty, each artifact needs to have
+	// a valid signature of its SHA256 digest, published next to it with a `.sig`
+	// suffix, made by any of these keys.
+	PublicKeys []string `json:"publicKeys,omitempty"`
+}
+
+// PlanCommandHooks are host-level commands that are run on each node before and
+// after it is updated. If a hook fails, the rollout is paused until it succeeds.
+type PlanCommandHooks struct {
+	// PreUpdate hooks are run after the update has been downloaded, before the
+	// node gets cordoned and drained.
+	PreUpdate []PlanCommandHook `json:"preUpdate,omitempty"`
+
+	// PostUpdate hooks are run after the updated node has been uncordoned.
+	PostUpdate []PlanCommandHook `json:"postUpdate,omitempty"`
+}
+
+// PlanCommandHook is a command that is run on the host of a node.
+type PlanCommandHook struct {
+	// Command is the executable to run, followed by its arguments. It's not
+	// run in a shell.
+	//
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+
+	// Timeout is the time after which the command is considered to have failed.
+	//
+	// +kubebuilder:default="5m"
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
 // PlanCommandTargets contains the target definitions for both controllers and workers.
 type PlanCommandTargets struct {
 	// Controllers defines how k0s controllers will be discovered and executed.
@@ -187,6 +306,63 @@ type PlanCommandTarget struct {
 	//
 	// +kubebuilder:default={concurrent:1}
 	Limits PlanCommandTargetLimits `json:"limits,omitempty"`
+
+	// Canary selects a group of nodes that is updated before all other nodes of this target.
+	Canary *PlanCommandTargetCanary `json:"canary,omitempty"`
+}
+
+// PlanCommandTargetCanary defines a group of nodes of a target that is updated first. The
+// remaining nodes are only updated once all canary nodes have been updated and the health
+// checks have passed.
+type PlanCommandTargetCanary struct {
+	// Nodes is an explicit set of discovered nodes that form the canary group.
+	Nodes []string `json:"nodes,omitempty"`
+
+	// Count is the number of discovered nodes that form the canary group, in alphabetical
+	// order of their names. It is ignored if Nodes is set.
+	//
+	// +kubebuilder:validation:Minimum=0
+	Count int `json:"count,omitempty"`
+
+	// HealthChecks need to pass after the canary group has been updated.
+	HealthChecks PlanCommandHealthChecks `json:"healthChecks,omitempty"`
+}
+
+// PlanCommandHealthChecks are checks that determine whether the cluster is healthy.
+type PlanCommandHealthChecks struct {
+	// NodesReady requires all Kubernetes nodes of the cluster to be ready.
+	NodesReady bool `json:"nodesReady,omitempty"`
+
+	// Workloads requires all replicas of the listed workloads to be updated and available.
+	Workloads []PlanCommandHealthCheckWorkload `json:"workloads,omitempty"`
+
+	// HTTP requires all listed endpoints to respond with a 2xx status code.
+	HTTP []PlanCommandHealthCheckHTTP `json:"http,omitempty"`
+
+	// Timeout is the time after which the plan fails if the health checks haven't passed.
+	//
+	// +kubebuilder:default="10m"
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+}
+
+// PlanCommandHealthCheckWorkload identifies a workload that needs to be available.
+type PlanCommandHealthCheckWorkload struct {
+	// Kind is the kind of the workload.
+	//
+	// +kubebuilder:validation:Enum=Deployment;DaemonSet;StatefulSet
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the workload.
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the workload.
+	Name string `json:"name"`
+}
+
+// PlanCommandHealthCheckHTTP is an HTTP endpoint that needs to respond successfully.
+type PlanCommandHealthCheckHTTP struct {
+	// URL is the URL that is requested using HTTP GET.
+	URL string `json:"url"`
 }
 
 // PlanCommandTargetLimits are limits that can be imposed on a target of a command.
@@ -266,6 +442,12 @@ type PlanCommandK0sUpdateStatus struct {
 
 	// Workers are a collection of status for resolved k0s worker targets.
 	Workers []PlanCommandTargetStatus `json:"workers,omitempty"`
+
+	// ControllersHealthGate is the status of the health checks of the controller canary group.
+	ControllersHealthGate *PlanCommandHealthGateStatus `json:"controllersHealthGate,omitempty"`
+
+	// WorkersHealthGate is the status of the health checks of the worker canary group.
+	WorkersHealthGate *PlanCommandHealthGateStatus `json:"workersHealthGate,omitempty"`
 }
 
 // PlanCommandAirgapUpdateStatus is the status of a `AirgapUpdate` command for
@@ -273,6 +455,21 @@ type PlanCommandK0sUpdateStatus struct {
 type PlanCommandAirgapUpdateStatus struct {
 	// Workers are a collection of status for resolved k0s worker targets.
 	Workers []PlanCommandTargetStatus `json:"workers,omitempty"`
+
+	// WorkersHealthGate is the status of the health checks of the worker canary group.
+	WorkersHealthGate *PlanCommandHealthGateStatus `json:"workersHealthGate,omitempty"`
+}
+
+// PlanCommandHealthGateStatus is the status of the health checks of a canary group.
+type PlanCommandHealthGateStatus struct {
+	// Passed indicates that the health checks have passed.
+	Passed bool `json:"passed"`
+
+	// Message describes why the health checks didn't pass yet.
+	Message string `json:"message,omitempty"`
+
+	// StartedTimestamp is the time at which the health checks were first evaluated.
+	StartedTimestamp metav1.Time `json:"startedTimestamp"`
 }
 
 // PlanCommandTargetStateType is the state of a PlanCommandTarget
@@ -288,4 +485,7 @@ type PlanCommandTargetStatus struct {
 
 	// LastUpdatedTimestamp is a timestamp of the last time the status has changed.
 	LastUpdatedTimestamp metav1.Time `json:"lastUpdatedTimestamp"`
+
+	// Canary indicates that the target signal node is part of the canary group.
+	Canary bool `json:"canary,omitempty"`
 }
@@ -42,6 +42,10 @@ type UpdateSpec struct {
 	UpdateServer    string            `json:"updateServer,omitempty"`
 	UpgradeStrategy UpgradeStrategy   `json:"upgradeStrategy,omitempty"`
 	PlanSpec        AutopilotPlanSpec `json:"planSpec,omitempty"`
+
+	// Verification describes how the update artifacts of the generated plans
+	// are verified. Verification is mandatory unless explicitly disabled.
+	Verification PlanCommandVerification `json:"verification,omitempty"`
 }
 
 // AutopilotPlanSpec describes the behavior of the autopilot generated `Plan`
@@ -68,6 +72,13 @@ type AutopilotPlanCommandK0sUpdate struct {
 
 	// Targets defines how the controllers/workers should be discovered and upgraded.
 	Targets PlanCommandTargets `json:"targets"`
+
+	// ProxyDownloads makes workers download the k0s binaries through the k0s API
+	// of the controllers, instead of directly from the update server.
+	ProxyDownloads bool `json:"proxyDownloads,omitempty"`
+
+	// Hooks are commands that are run on each node before and after it is updated.
+	Hooks *PlanCommandHooks `json:"hooks,omitempty"`
 }
 
 // AutopilotPlanCommandAirgapUpdate provides all of the information to for a `AirgapUpdate` command to
@@ -75,6 +86,10 @@ type AutopilotPlanCommandK0sUpdate struct {
 type AutopilotPlanCommandAirgapUpdate struct {
 	// Workers defines how the k0s workers will be discovered and airgap updated.
 	Workers PlanCommandTarget `json:"workers"`
+
+	// ProxyDownloads makes workers download the airgap bundles through the k0s
+	// API of the controllers, instead of directly from the update server.
+	ProxyDownloads bool `json:"proxyDownloads,omitempty"`
 }
 
 type UpgradeStrategy struct {
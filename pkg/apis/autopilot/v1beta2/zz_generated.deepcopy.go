@@ -26,6 +26,23 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIEndpointStatus) DeepCopyInto(out *APIEndpointStatus) {
+	*out = *in
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIEndpointStatus.
+func (in *APIEndpointStatus) DeepCopy() *APIEndpointStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(APIEndpointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AutopilotPlanCommand) DeepCopyInto(out *AutopilotPlanCommand) {
 	*out = *in
@@ -71,6 +88,11 @@ func (in *AutopilotPlanCommandAirgapUpdate) DeepCopy() *AutopilotPlanCommandAirg
 func (in *AutopilotPlanCommandK0sUpdate) DeepCopyInto(out *AutopilotPlanCommandK0sUpdate) {
 	*out = *in
 	in.Targets.DeepCopyInto(&out.Targets)
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(PlanCommandHooks)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AutopilotPlanCommandK0sUpdate.
@@ -171,6 +193,18 @@ func (in *ControlNodeStatus) DeepCopyInto(out *ControlNodeStatus) {
 		*out = make([]v1.NodeAddress, len(*in))
 		copy(*out, *in)
 	}
+	if in.APIEndpoints != nil {
+		in, out := &in.APIEndpoints, &out.APIEndpoints
+		*out = make([]APIEndpointStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Controller != nil {
+		in, out := &in.Controller, &out.Controller
+		*out = new(ControllerStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControlNodeStatus.
@@ -183,6 +217,47 @@ func (in *ControlNodeStatus) DeepCopy() *ControlNodeStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerStatus) DeepCopyInto(out *ControllerStatus) {
+	*out = *in
+	if in.Leases != nil {
+		in, out := &in.Leases, &out.Leases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Etcd != nil {
+		in, out := &in.Etcd, &out.Etcd
+		*out = new(EtcdMemberStatus)
+		**out = **in
+	}
+	in.LastHeartbeatTime.DeepCopyInto(&out.LastHeartbeatTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerStatus.
+func (in *ControllerStatus) DeepCopy() *ControllerStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EtcdMemberStatus) DeepCopyInto(out *EtcdMemberStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EtcdMemberStatus.
+func (in *EtcdMemberStatus) DeepCopy() *EtcdMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EtcdMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Plan) DeepCopyInto(out *Plan) {
 	*out = *in
@@ -246,6 +321,11 @@ func (in *PlanCommandAirgapUpdate) DeepCopyInto(out *PlanCommandAirgapUpdate) {
 		}
 	}
 	in.Workers.DeepCopyInto(&out.Workers)
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(PlanCommandVerification)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandAirgapUpdate.
@@ -268,6 +348,11 @@ func (in *PlanCommandAirgapUpdateStatus) DeepCopyInto(out *PlanCommandAirgapUpda
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.WorkersHealthGate != nil {
+		in, out := &in.WorkersHealthGate, &out.WorkersHealthGate
+		*out = new(PlanCommandHealthGateStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandAirgapUpdateStatus.
@@ -280,6 +365,128 @@ func (in *PlanCommandAirgapUpdateStatus) DeepCopy() *PlanCommandAirgapUpdateStat
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanCommandHealthCheckHTTP) DeepCopyInto(out *PlanCommandHealthCheckHTTP) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandHealthCheckHTTP.
+func (in *PlanCommandHealthCheckHTTP) DeepCopy() *PlanCommandHealthCheckHTTP {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanCommandHealthCheckHTTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanCommandHealthCheckWorkload) DeepCopyInto(out *PlanCommandHealthCheckWorkload) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandHealthCheckWorkload.
+func (in *PlanCommandHealthCheckWorkload) DeepCopy() *PlanCommandHealthCheckWorkload {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanCommandHealthCheckWorkload)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanCommandHealthChecks) DeepCopyInto(out *PlanCommandHealthChecks) {
+	*out = *in
+	if in.Workloads != nil {
+		in, out := &in.Workloads, &out.Workloads
+		*out = make([]PlanCommandHealthCheckWorkload, len(*in))
+		copy(*out, *in)
+	}
+	if in.HTTP != nil {
+		in, out := &in.HTTP, &out.HTTP
+		*out = make([]PlanCommandHealthCheckHTTP, len(*in))
+		copy(*out, *in)
+	}
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandHealthChecks.
+func (in *PlanCommandHealthChecks) DeepCopy() *PlanCommandHealthChecks {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanCommandHealthChecks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanCommandHealthGateStatus) DeepCopyInto(out *PlanCommandHealthGateStatus) {
+	*out = *in
+	in.StartedTimestamp.DeepCopyInto(&out.StartedTimestamp)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandHealthGateStatus.
+func (in *PlanCommandHealthGateStatus) DeepCopy() *PlanCommandHealthGateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanCommandHealthGateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanCommandHook) DeepCopyInto(out *PlanCommandHook) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandHook.
+func (in *PlanCommandHook) DeepCopy() *PlanCommandHook {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanCommandHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanCommandHooks) DeepCopyInto(out *PlanCommandHooks) {
+	*out = *in
+	if in.PreUpdate != nil {
+		in, out := &in.PreUpdate, &out.PreUpdate
+		*out = make([]PlanCommandHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PostUpdate != nil {
+		in, out := &in.PostUpdate, &out.PostUpdate
+		*out = make([]PlanCommandHook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandHooks.
+func (in *PlanCommandHooks) DeepCopy() *PlanCommandHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanCommandHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PlanCommandK0sUpdate) DeepCopyInto(out *PlanCommandK0sUpdate) {
 	*out = *in
@@ -291,6 +498,16 @@ func (in *PlanCommandK0sUpdate) DeepCopyInto(out *PlanCommandK0sUpdate) {
 		}
 	}
 	in.Targets.DeepCopyInto(&out.Targets)
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(PlanCommandVerification)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(PlanCommandHooks)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandK0sUpdate.
@@ -320,6 +537,16 @@ func (in *PlanCommandK0sUpdateStatus) DeepCopyInto(out *PlanCommandK0sUpdateStat
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ControllersHealthGate != nil {
+		in, out := &in.ControllersHealthGate, &out.ControllersHealthGate
+		*out = new(PlanCommandHealthGateStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkersHealthGate != nil {
+		in, out := &in.WorkersHealthGate, &out.WorkersHealthGate
+		*out = new(PlanCommandHealthGateStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandK0sUpdateStatus.
@@ -362,6 +589,11 @@ func (in *PlanCommandTarget) DeepCopyInto(out *PlanCommandTarget) {
 	*out = *in
 	in.Discovery.DeepCopyInto(&out.Discovery)
 	out.Limits = in.Limits
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(PlanCommandTargetCanary)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandTarget.
@@ -374,6 +606,27 @@ func (in *PlanCommandTarget) DeepCopy() *PlanCommandTarget {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanCommandTargetCanary) DeepCopyInto(out *PlanCommandTargetCanary) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.HealthChecks.DeepCopyInto(&out.HealthChecks)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandTargetCanary.
+func (in *PlanCommandTargetCanary) DeepCopy() *PlanCommandTargetCanary {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanCommandTargetCanary)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PlanCommandTargetDiscovery) DeepCopyInto(out *PlanCommandTargetDiscovery) {
 	*out = *in
@@ -482,6 +735,26 @@ func (in *PlanCommandTargets) DeepCopy() *PlanCommandTargets {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlanCommandVerification) DeepCopyInto(out *PlanCommandVerification) {
+	*out = *in
+	if in.PublicKeys != nil {
+		in, out := &in.PublicKeys, &out.PublicKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlanCommandVerification.
+func (in *PlanCommandVerification) DeepCopy() *PlanCommandVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(PlanCommandVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PlanList) DeepCopyInto(out *PlanList) {
 	*out = *in
@@ -657,6 +930,7 @@ func (in *UpdateSpec) DeepCopyInto(out *UpdateSpec) {
 	*out = *in
 	out.UpgradeStrategy = in.UpgradeStrategy
 	in.PlanSpec.DeepCopyInto(&out.PlanSpec)
+	in.Verification.DeepCopyInto(&out.Verification)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateSpec.
@@ -34,6 +34,79 @@ type ChartSpec struct {
 	Namespace   string `json:"namespace,omitempty"`
 	Timeout     string `json:"timeout,omitempty"`
 	Order       int    `json:"order,omitempty"`
+	// Roll back the release if the install or upgrade fails (default true)
+	// +optional
+	Atomic *bool `json:"atomic,omitempty"`
+	// Wait until all resources of the release are ready before marking it as
+	// successful (default true)
+	// +optional
+	Wait *bool `json:"wait,omitempty"`
+	// Don't install the CRDs of the chart
+	// +optional
+	SkipCRDs bool `json:"skipCRDs,omitempty"`
+	// Don't run the hooks of the chart
+	// +optional
+	DisableHooks bool `json:"disableHooks,omitempty"`
+	// How to handle live resources of the release that differ from the
+	// rendered chart (default Ignore)
+	// +kubebuilder:validation:Enum=Ignore;Report;Remediate
+	// +optional
+	DriftPolicy DriftPolicy `json:"driftPolicy,omitempty"`
+	// Dependencies that need to be ready before the chart is installed or
+	// upgraded
+	// +optional
+	DependsOn []ChartDependency `json:"dependsOn,omitempty"`
+}
+
+// ChartDependency is a dependency of a chart. Exactly one of its fields is
+// set.
+type ChartDependency struct {
+	// Name of a Chart resource in the same namespace that needs to be
+	// installed successfully
+	// +optional
+	Chart string `json:"chart,omitempty"`
+	// Built-in component that needs to be ready. The only supported component
+	// is "network", which is ready as soon as any node is ready, i.e. the
+	// network provider is up.
+	// +kubebuilder:validation:Enum=network
+	// +optional
+	Component string `json:"component,omitempty"`
+}
+
+// ComponentNetwork is the network provider component.
+const ComponentNetwork = "network"
+
+// DriftPolicy defines how drift between a release and its live resources is
+// handled.
+type DriftPolicy string
+
+const (
+	// DriftPolicyIgnore doesn't check the live resources of a release.
+	DriftPolicyIgnore DriftPolicy = "Ignore"
+	// DriftPolicyReport reports drifted resources in the Chart status.
+	DriftPolicyReport DriftPolicy = "Report"
+	// DriftPolicyRemediate upgrades the release when its resources drifted.
+	DriftPolicyRemediate DriftPolicy = "Remediate"
+)
+
+// GetDriftPolicy returns the drift policy of the chart, defaulting to
+// [DriftPolicyIgnore].
+func (cs ChartSpec) GetDriftPolicy() DriftPolicy {
+	if cs.DriftPolicy == "" {
+		return DriftPolicyIgnore
+	}
+	return cs.DriftPolicy
+}
+
+// IsAtomic returns whether failed installs and upgrades are rolled back.
+func (cs ChartSpec) IsAtomic() bool {
+	return cs.Atomic == nil || *cs.Atomic
+}
+
+// ShouldWait returns whether to wait for the resources of the release to
+// become ready. Atomic releases always wait.
+func (cs ChartSpec) ShouldWait() bool {
+	return cs.IsAtomic() || cs.Wait == nil || *cs.Wait
 }
 
 // YamlValues returns values as map
@@ -54,6 +127,20 @@ func (cs ChartSpec) HashValues() string {
 	return fmt.Sprintf("%x", h.Sum(nil))
 }
 
+const (
+	// ConditionInstalled indicates whether a release of the chart is
+	// installed.
+	ConditionInstalled = "Installed"
+	// ConditionUpgrading indicates whether the release is being installed or
+	// upgraded.
+	ConditionUpgrading = "Upgrading"
+	// ConditionFailed indicates whether the last install or upgrade failed.
+	ConditionFailed = "Failed"
+	// ConditionDependenciesReady indicates whether the chart's dependencies
+	// are ready.
+	ConditionDependenciesReady = "DependenciesReady"
+)
+
 // ChartStatus defines the observed state of Chart
 type ChartStatus struct {
 	ReleaseName string `json:"releaseName,omitempty"`
@@ -65,11 +152,39 @@ type ChartStatus struct {
 	Revision    int64  `json:"revision,omitempty"`
 	ValuesHash  string `json:"valuesHash,omitempty"`
 	Error       string `json:"error,omitempty"`
+	// Resources of the release whose live state differs from the rendered
+	// chart, as of the last drift check
+	Drift []string `json:"drift,omitempty"`
+	// Time at which the current revision of the release was deployed
+	LastDeployed *metav1.Time `json:"lastDeployed,omitempty"`
+	// Checksum of the values the current revision was rendered with,
+	// including the chart's defaults
+	RenderedValuesHash string `json:"renderedValuesHash,omitempty"`
+	// Latest revisions of the release, newest first
+	History []ChartRevision `json:"history,omitempty"`
+	// Conditions of the chart
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ChartRevision describes a single revision of a release.
+type ChartRevision struct {
+	Revision    int64        `json:"revision"`
+	Status      string       `json:"status,omitempty"`
+	Version     string       `json:"version,omitempty"`
+	AppVersion  string       `json:"appVersion,omitempty"`
+	Updated     *metav1.Time `json:"updated,omitempty"`
+	Description string       `json:"description,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Release",type="string",JSONPath=".status.releaseName"
+// +kubebuilder:printcolumn:name="Revision",type="integer",JSONPath=".status.revision"
+// +kubebuilder:printcolumn:name="Installed",type="string",JSONPath=".status.conditions[?(@.type==\"Installed\")].status"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
 // Chart is the Schema for the charts API
 type Chart struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -0,0 +1,162 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+
+	"golang.org/x/exp/slices"
+)
+
+// Admission defines the admission control settings of kube-apiserver
+type Admission struct {
+	// Admission plugins to enable in addition to the ones enabled by default
+	EnablePlugins []string `json:"enablePlugins,omitempty"`
+	// Admission plugins to disable, even if they're enabled by default
+	DisablePlugins []string `json:"disablePlugins,omitempty"`
+	// Configuration of individual admission plugins, rendered into an
+	// AdmissionConfiguration
+	Plugins []AdmissionPlugin `json:"plugins,omitempty"`
+}
+
+// AdmissionPlugin defines the configuration of an admission plugin
+type AdmissionPlugin struct {
+	// The name of the admission plugin
+	Name string `json:"name"`
+	// Path to a file containing the plugin's configuration. Mutually exclusive
+	// with configuration.
+	Path string `json:"path,omitempty"`
+	// The plugin's configuration. Mutually exclusive with path.
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Configuration *runtime.RawExtension `json:"configuration,omitempty"`
+}
+
+// Validate validates the Admission struct
+func (a *Admission) Validate(path *field.Path) []error {
+	if a == nil {
+		return nil
+	}
+
+	var errors []error
+
+	validateNames := func(path *field.Path, names []string) {
+		for i, name := range names {
+			if name == "" || strings.ContainsAny(name, ", ") {
+				errors = append(errors, field.Invalid(path.Index(i), name, "invalid admission plugin name"))
+			}
+		}
+	}
+	validateNames(path.Child("enablePlugins"), a.EnablePlugins)
+	validateNames(path.Child("disablePlugins"), a.DisablePlugins)
+
+	for i, name := range a.EnablePlugins {
+		if slices.Contains(a.DisablePlugins, name) {
+			errors = append(errors, field.Invalid(path.Child("enablePlugins").Index(i), name, "admission plugin is also disabled"))
+		}
+	}
+
+	seen := make(map[string]bool, len(a.Plugins))
+	for i, plugin := range a.Plugins {
+		path := path.Child("plugins").Index(i)
+		switch {
+		case plugin.Name == "":
+			errors = append(errors, field.Required(path.Child("name"), ""))
+		case seen[plugin.Name]:
+			errors = append(errors, field.Duplicate(path.Child("name"), plugin.Name))
+		}
+		seen[plugin.Name] = true
+
+		hasConfiguration := plugin.Configuration != nil && len(plugin.Configuration.Raw) > 0
+		switch {
+		case plugin.Path == "" && !hasConfiguration:
+			errors = append(errors, field.Required(path, "either path or configuration is required"))
+		case plugin.Path != "" && hasConfiguration:
+			errors = append(errors, field.Forbidden(path.Child("path"), "mutually exclusive with configuration"))
+		case hasConfiguration:
+			var configuration map[string]any
+			if err := json.Unmarshal(plugin.Configuration.Raw, &configuration); err != nil {
+				errors = append(errors, field.Invalid(path.Child("configuration"), "", "must be an object"))
+			}
+		}
+	}
+
+	return errors
+}
+
+// BuildArgs adds the kube-apiserver flags for these admission settings to
+// args. If there are plugins to be configured, the AdmissionConfiguration is
+// expected to have been written to the given file.
+func (a *Admission) BuildArgs(args stringmap.StringMap, configFile string) stringmap.StringMap {
+	if a == nil {
+		return args
+	}
+
+	var enabled []string
+	if args["enable-admission-plugins"] != "" {
+		enabled = strings.Split(args["enable-admission-plugins"], ",")
+	}
+	enabled = append(enabled, a.EnablePlugins...)
+
+	var filtered []string
+	seen := make(map[string]bool, len(enabled))
+	for _, name := range enabled {
+		if seen[name] || slices.Contains(a.DisablePlugins, name) {
+			continue
+		}
+		seen[name] = true
+		filtered = append(filtered, name)
+	}
+
+	if len(filtered) > 0 {
+		args["enable-admission-plugins"] = strings.Join(filtered, ",")
+	} else {
+		delete(args, "enable-admission-plugins")
+	}
+	if len(a.DisablePlugins) > 0 {
+		args["disable-admission-plugins"] = strings.Join(a.DisablePlugins, ",")
+	}
+	if len(a.Plugins) > 0 {
+		args["admission-control-config-file"] = configFile
+	}
+
+	return args
+}
+
+// AdmissionConfiguration renders the AdmissionConfiguration for the
+// configured plugins, or returns nil if there are none.
+func (a *Admission) AdmissionConfiguration() ([]byte, error) {
+	if a == nil || len(a.Plugins) == 0 {
+		return nil, nil
+	}
+
+	return yaml.Marshal(&struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Plugins    []AdmissionPlugin `json:"plugins"`
+	}{
+		APIVersion: "apiserver.config.k8s.io/v1",
+		Kind:       "AdmissionConfiguration",
+		Plugins:    a.Plugins,
+	})
+}
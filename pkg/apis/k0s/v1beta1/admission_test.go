@@ -0,0 +1,115 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmission_FromConfig(t *testing.T) {
+	c, err := ConfigFromString(`
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+spec:
+  api:
+    admission:
+      enablePlugins: [EventRateLimit, AlwaysPullImages]
+      disablePlugins: [DefaultStorageClass]
+      plugins:
+      - name: PodSecurity
+        configuration:
+          apiVersion: pod-security.admission.config.k8s.io/v1
+          kind: PodSecurityConfiguration
+          defaults:
+            enforce: baseline
+      - name: EventRateLimit
+        path: /etc/k0s/eventratelimit.yaml
+`)
+	require.NoError(t, err)
+	admission := c.Spec.API.Admission
+	assert.Empty(t, admission.Validate(field.NewPath("admission")))
+
+	args := admission.BuildArgs(stringmap.StringMap{"enable-admission-plugins": "NodeRestriction"}, "/var/lib/k0s/admission-config.yaml")
+	assert.Equal(t, stringmap.StringMap{
+		"enable-admission-plugins":      "NodeRestriction,EventRateLimit,AlwaysPullImages",
+		"disable-admission-plugins":     "DefaultStorageClass",
+		"admission-control-config-file": "/var/lib/k0s/admission-config.yaml",
+	}, args)
+
+	config, err := admission.AdmissionConfiguration()
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apiserver.config.k8s.io/v1
+kind: AdmissionConfiguration
+plugins:
+- configuration:
+    apiVersion: pod-security.admission.config.k8s.io/v1
+    defaults:
+      enforce: baseline
+    kind: PodSecurityConfiguration
+  name: PodSecurity
+- name: EventRateLimit
+  path: /etc/k0s/eventratelimit.yaml
+`, string(config))
+}
+
+func TestAdmission_BuildArgs(t *testing.T) {
+	var nilAdmission *Admission
+	assert.Equal(t, stringmap.StringMap{"enable-admission-plugins": "NodeRestriction"}, nilAdmission.BuildArgs(stringmap.StringMap{"enable-admission-plugins": "NodeRestriction"}, "ignored"))
+
+	admission := &Admission{DisablePlugins: []string{"NodeRestriction"}}
+	assert.Equal(t, stringmap.StringMap{
+		"disable-admission-plugins": "NodeRestriction",
+	}, admission.BuildArgs(stringmap.StringMap{"enable-admission-plugins": "NodeRestriction"}, "ignored"))
+}
+
+func TestAdmission_Validate(t *testing.T) {
+	config := &runtime.RawExtension{Raw: []byte(`{"kind":"PodSecurityConfiguration"}`)}
+
+	for _, test := range []struct {
+		name      string
+		admission *Admission
+		fields    []string
+	}{
+		{"nil", nil, nil},
+		{"invalid_name", &Admission{EnablePlugins: []string{"A,B"}}, []string{"admission.enablePlugins[0]"}},
+		{"enabled_and_disabled", &Admission{EnablePlugins: []string{"A"}, DisablePlugins: []string{"A"}}, []string{"admission.enablePlugins[0]"}},
+		{"no_name", &Admission{Plugins: []AdmissionPlugin{{Configuration: config}}}, []string{"admission.plugins[0].name"}},
+		{"duplicate", &Admission{Plugins: []AdmissionPlugin{{Name: "A", Path: "/a"}, {Name: "A", Path: "/b"}}}, []string{"admission.plugins[1].name"}},
+		{"no_config", &Admission{Plugins: []AdmissionPlugin{{Name: "A"}}}, []string{"admission.plugins[0]"}},
+		{"both_configs", &Admission{Plugins: []AdmissionPlugin{{Name: "A", Path: "/a", Configuration: config}}}, []string{"admission.plugins[0].path"}},
+		{"not_an_object", &Admission{Plugins: []AdmissionPlugin{{Name: "A", Configuration: &runtime.RawExtension{Raw: []byte(`"foo"`)}}}}, []string{"admission.plugins[0].configuration"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var fields []string
+			for _, err := range test.admission.Validate(field.NewPath("admission")) {
+				var fieldErr *field.Error
+				if assert.ErrorAs(t, err, &fieldErr) {
+					fields = append(fields, fieldErr.Field)
+				}
+			}
+			assert.Equal(t, test.fields, fields)
+		})
+	}
+}
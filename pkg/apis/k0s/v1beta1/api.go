@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 
 	"github.com/asaskevich/govalidator"
+	"golang.org/x/exp/slices"
 )
 
 var _ Validateable = (*APISpec)(nil)
@@ -35,10 +36,17 @@ type APISpec struct {
 	// Local address on which to bind an API
 	Address string `json:"address"`
 
-	// The loadbalancer address (for k0s controllers running behind a loadbalancer)
+	// The loadbalancer address (for k0s controllers running behind a loadbalancer).
+	// Set to "auto" to discover the node's public address from the instance
+	// metadata service of its cloud provider.
 	ExternalAddress string `json:"externalAddress,omitempty"`
 	// TunneledNetworkingMode indicates if we access to KAS through konnectivity tunnel
 	TunneledNetworkingMode bool `json:"tunneledNetworkingMode"`
+	// TunneledJoinAPI exposes the k0s join API through the konnectivity tunnel
+	// on the worker nodes, so that nodes can join via any existing worker.
+	// Requires TunneledNetworkingMode.
+	// +optional
+	TunneledJoinAPI bool `json:"tunneledJoinAPI,omitempty"`
 	// Map of key-values (strings) for any extra arguments to pass down to Kubernetes api-server process
 	ExtraArgs map[string]string `json:"extraArgs,omitempty"`
 	// Custom port for k0s-api server to listen on (default: 9443)
@@ -47,11 +55,35 @@ type APISpec struct {
 	// Custom port for kube-api server to listen on (default: 6443)
 	Port int `json:"port"`
 
-	// List of additional addresses to push to API servers serving the certificate
+	// List of additional addresses to push to API servers serving the certificate.
+	// An "auto" entry is replaced by the node's public address, see externalAddress.
 	SANs []string `json:"sans"`
+
+	// OpenID Connect authentication settings for kube-apiserver
+	// +optional
+	OIDC *OIDC `json:"oidc,omitempty"`
+
+	// Audit logging settings for kube-apiserver
+	// +optional
+	Audit *Audit `json:"audit,omitempty"`
+
+	// Admission control settings for kube-apiserver
+	// +optional
+	Admission *Admission `json:"admission,omitempty"`
+
+	// Settings for encrypting resources at rest via a KMS plugin
+	// +optional
+	Encryption *Encryption `json:"encryption,omitempty"`
 }
 
-const defaultKasPort = 6443
+const (
+	defaultKasPort    = 6443
+	defaultK0sAPIPort = 9443
+)
+
+// AutoAddress is the placeholder for externalAddress and SANs that is replaced
+// by the public address discovered from the cloud provider's metadata service.
+const AutoAddress = "auto"
 
 // DefaultAPISpec default settings for api
 func DefaultAPISpec() *APISpec {
@@ -60,7 +92,7 @@ func DefaultAPISpec() *APISpec {
 	publicAddress, _ := iface.FirstPublicAddress()
 	return &APISpec{
 		Port:                   defaultKasPort,
-		K0sAPIPort:             9443,
+		K0sAPIPort:             defaultK0sAPIPort,
 		SANs:                   addresses,
 		Address:                publicAddress,
 		ExtraArgs:              make(map[string]string),
@@ -115,6 +147,28 @@ func (a *APISpec) Sans() []string {
 	return stringslice.Unique(sans)
 }
 
+// HasAutoAddress returns true if the external address or one of the SANs is
+// set to AutoAddress.
+func (a *APISpec) HasAutoAddress() bool {
+	if a == nil {
+		return false
+	}
+	return a.ExternalAddress == AutoAddress || slices.Contains(a.SANs, AutoAddress)
+}
+
+// ReplaceAutoAddress replaces the AutoAddress placeholders with the given address.
+func (a *APISpec) ReplaceAutoAddress(address string) {
+	if a.ExternalAddress == AutoAddress {
+		a.ExternalAddress = address
+	}
+	for i, san := range a.SANs {
+		if san == AutoAddress {
+			a.SANs[i] = address
+		}
+	}
+	a.SANs = stringslice.Unique(a.SANs)
+}
+
 // Validate validates APISpec struct
 func (a *APISpec) Validate() []error {
 	if a == nil {
@@ -134,10 +188,7 @@ func (a *APISpec) Validate() []error {
 		errors = append(errors, field.Invalid(path, san, "invalid IP address / DNS name"))
 	}
 
-	sansPath := field.NewPath("sans")
-	for idx, san := range a.SANs {
-		validateIPAddressOrDNSName(sansPath.Index(idx), san)
-	}
+	errors = append(errors, validateSANs(field.NewPath("sans"), a.SANs)...)
 
 	if a.ExternalAddress != "" {
 		validateIPAddressOrDNSName(field.NewPath("externalAddress"), a.ExternalAddress)
@@ -145,5 +196,27 @@ func (a *APISpec) Validate() []error {
 	if a.TunneledNetworkingMode && a.Port == defaultKasPort {
 		errors = append(errors, fmt.Errorf("can't use default kubeapi port if TunneledNetworkingMode is enabled"))
 	}
+	if a.TunneledJoinAPI {
+		if !a.TunneledNetworkingMode {
+			errors = append(errors, field.Forbidden(field.NewPath("tunneledJoinAPI"), "requires tunneledNetworkingMode"))
+		}
+		if a.K0sAPIPort == defaultK0sAPIPort {
+			errors = append(errors, field.Invalid(field.NewPath("k0sApiPort"), a.K0sAPIPort, "can't use default k0s API port if tunneledJoinAPI is enabled"))
+		}
+	}
+	errors = append(errors, a.OIDC.Validate(field.NewPath("oidc"))...)
+	errors = append(errors, a.Audit.Validate(field.NewPath("audit"))...)
+	errors = append(errors, a.Admission.Validate(field.NewPath("admission"))...)
+	errors = append(errors, a.Encryption.Validate(field.NewPath("encryption"))...)
 	return errors
 }
+
+// validateSANs checks that all the given SANs are IP addresses or DNS names.
+func validateSANs(path *field.Path, sans []string) (errs []error) {
+	for idx, san := range sans {
+		if !govalidator.IsIP(san) && !govalidator.IsDNSName(san) {
+			errs = append(errs, field.Invalid(path.Index(idx), san, "invalid IP address / DNS name"))
+		}
+	}
+	return errs
+}
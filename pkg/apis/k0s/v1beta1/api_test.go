@@ -19,6 +19,8 @@ package v1beta1
 import (
 	"testing"
 
+	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+
 	"github.com/stretchr/testify/suite"
 )
 
@@ -76,6 +78,93 @@ func (s *APISuite) TestValidation() {
 		s.Len(errors, 1)
 		s.Contains(errors[0].Error(), "can't use default kubeapi port if TunneledNetworkingMode is enabled")
 	})
+
+	s.T().Run("TunneledJoinAPI", func(t *testing.T) {
+		a := DefaultAPISpec()
+		a.Port = 7443
+		a.TunneledJoinAPI = true
+		errors := a.Validate()
+		if s.Len(errors, 2) {
+			s.ErrorContains(errors[0], "tunneledJoinAPI: Forbidden: requires tunneledNetworkingMode")
+			s.ErrorContains(errors[1], `k0sApiPort: Invalid value: 9443: can't use default k0s API port if tunneledJoinAPI is enabled`)
+		}
+
+		a.TunneledNetworkingMode = true
+		a.K0sAPIPort = 9444
+		s.Nil(a.Validate())
+	})
+
+	s.T().Run("valid_oidc", func(t *testing.T) {
+		a := DefaultAPISpec()
+		a.OIDC = &OIDC{
+			IssuerURL:      "https://issuer.example.com",
+			ClientID:       "k0s",
+			RequiredClaims: map[string]string{"hd": "example.com"},
+		}
+
+		s.Nil(a.Validate())
+	})
+
+	s.T().Run("invalid_oidc", func(t *testing.T) {
+		a := DefaultAPISpec()
+		a.OIDC = &OIDC{
+			IssuerURL: "http://issuer.example.com",
+		}
+
+		errors := a.Validate()
+		if s.Len(errors, 2) {
+			s.ErrorContains(errors[0], `oidc.issuerURL: Invalid value: "http://issuer.example.com": must be an HTTPS URL`)
+			s.ErrorContains(errors[1], `oidc.clientID: Required value`)
+		}
+	})
+}
+
+func (s *APISuite) TestReplaceAutoAddress() {
+	a := APISpec{
+		Address:         "10.0.0.1",
+		ExternalAddress: AutoAddress,
+		SANs:            []string{"k0s.example.com", AutoAddress, "203.0.113.1"},
+	}
+	s.True(a.HasAutoAddress())
+	s.Nil(a.Validate())
+
+	a.ReplaceAutoAddress("203.0.113.1")
+	s.False(a.HasAutoAddress())
+	s.Equal("203.0.113.1", a.ExternalAddress)
+	s.Equal([]string{"k0s.example.com", "203.0.113.1"}, a.SANs)
+}
+
+func (s *APISuite) TestOIDCBuildArgs() {
+	s.T().Run("nil_oidc_adds_nothing", func(t *testing.T) {
+		var o *OIDC
+		s.Empty(o.BuildArgs(stringmap.StringMap{}))
+	})
+
+	s.T().Run("all_settings", func(t *testing.T) {
+		o := &OIDC{
+			IssuerURL:      "https://issuer.example.com",
+			ClientID:       "k0s",
+			UsernameClaim:  "email",
+			UsernamePrefix: "oidc:",
+			GroupsClaim:    "groups",
+			GroupsPrefix:   "oidc:",
+			RequiredClaims: map[string]string{"hd": "example.com", "aud": "k0s"},
+			CAFile:         "/etc/k0s/oidc-ca.crt",
+			SigningAlgs:    []string{"RS256", "ES256"},
+		}
+
+		s.Equal(stringmap.StringMap{
+			"oidc-issuer-url":      "https://issuer.example.com",
+			"oidc-client-id":       "k0s",
+			"oidc-username-claim":  "email",
+			"oidc-username-prefix": "oidc:",
+			"oidc-groups-claim":    "groups",
+			"oidc-groups-prefix":   "oidc:",
+			"oidc-required-claim":  "aud=k0s,hd=example.com",
+			"oidc-ca-file":         "/etc/k0s/oidc-ca.crt",
+			"oidc-signing-algs":    "RS256,ES256",
+		}, o.BuildArgs(stringmap.StringMap{}))
+	})
 }
 
 func TestApiSuite(t *testing.T) {
@@ -0,0 +1,75 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ Validateable = (*ApplierSpec)(nil)
+
+// ApplierSpec defines how the manifest deployer watches the stack directories
+type ApplierSpec struct {
+	// Time to wait for further changes in a stack directory before the stack
+	// is applied (default 1s)
+	// +kubebuilder:default="1s"
+	// +optional
+	DebounceInterval metav1.Duration `json:"debounceInterval,omitempty"`
+
+	// Only apply stacks when manifest files are created or moved into place,
+	// ignoring in-place writes to existing files. Use this if manifest files
+	// are written atomically, i.e. written to a temporary file and then
+	// renamed.
+	// +optional
+	AtomicWrites bool `json:"atomicWrites,omitempty"`
+
+	// File name patterns of files in stack directories that are ignored
+	// (default [".*"], i.e. hidden files)
+	// +optional
+	IgnorePatterns []string `json:"ignorePatterns,omitempty"`
+}
+
+// DefaultApplierSpec builds default ApplierSpec
+func DefaultApplierSpec() *ApplierSpec {
+	return &ApplierSpec{
+		DebounceInterval: metav1.Duration{Duration: 1 * time.Second},
+		IgnorePatterns:   []string{".*"},
+	}
+}
+
+// Validate implements [Validateable].
+func (a *ApplierSpec) Validate() (errs []error) {
+	if a == nil {
+		return nil
+	}
+
+	if a.DebounceInterval.Duration < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("debounceInterval"), a.DebounceInterval.Duration.String(), "must not be negative"))
+	}
+
+	for i, pattern := range a.IgnorePatterns {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("ignorePatterns").Index(i), pattern, err.Error()))
+		}
+	}
+
+	return errs
+}
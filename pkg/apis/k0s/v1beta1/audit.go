@@ -0,0 +1,182 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"strconv"
+
+	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultAuditLogPath is the default path of the audit log file.
+const DefaultAuditLogPath = "/var/log/kubernetes/audit/audit.log"
+
+// Audit defines the settings for the audit logging of kube-apiserver
+type Audit struct {
+	// The audit policy in YAML. Mutually exclusive with policyFile.
+	Policy string `json:"policy,omitempty"`
+	// Path to a file containing the audit policy. Mutually exclusive with
+	// policy.
+	PolicyFile string `json:"policyFile,omitempty"`
+	// Settings for the log backend, which writes audit events to a file
+	// +optional
+	Log *AuditLog `json:"log,omitempty"`
+	// Settings for the webhook backend, which sends audit events to a remote
+	// API
+	// +optional
+	Webhook *AuditWebhook `json:"webhook,omitempty"`
+}
+
+// AuditLog defines the settings for the audit log backend
+type AuditLog struct {
+	// Path of the audit log file. The value "-" writes to standard output.
+	// (default: /var/log/kubernetes/audit/audit.log)
+	Path string `json:"path,omitempty"`
+	// The maximum number of days to retain rotated audit log files
+	MaxAge int `json:"maxAge,omitempty"`
+	// The maximum number of rotated audit log files to retain
+	MaxBackup int `json:"maxBackup,omitempty"`
+	// The maximum size in megabytes of the audit log file before it gets
+	// rotated
+	MaxSize int `json:"maxSize,omitempty"`
+}
+
+// AuditWebhook defines the settings for the audit webhook backend
+type AuditWebhook struct {
+	// Path to a kubeconfig file that defines the remote API to send the audit
+	// events to
+	ConfigFile string `json:"configFile"`
+	// The strategy for sending audit events: batch, blocking or
+	// blocking-strict (default: batch)
+	Mode string `json:"mode,omitempty"`
+	// The time to wait before retrying the first failed request (default: 10s)
+	InitialBackoff *metav1.Duration `json:"initialBackoff,omitempty"`
+}
+
+// LogPath returns the path of the audit log file, or the empty string if
+// the log backend isn't enabled.
+func (a *Audit) LogPath() string {
+	if a == nil || a.Log == nil {
+		return ""
+	}
+	if a.Log.Path == "" {
+		return DefaultAuditLogPath
+	}
+	return a.Log.Path
+}
+
+// Validate validates the Audit struct
+func (a *Audit) Validate(path *field.Path) []error {
+	if a == nil {
+		return nil
+	}
+
+	var errors []error
+
+	switch {
+	case a.Policy == "" && a.PolicyFile == "":
+		errors = append(errors, field.Required(path.Child("policy"), "either policy or policyFile is required"))
+	case a.Policy != "" && a.PolicyFile != "":
+		errors = append(errors, field.Forbidden(path.Child("policyFile"), "mutually exclusive with policy"))
+	case a.Policy != "":
+		var policy struct {
+			Kind string `json:"kind"`
+		}
+		if err := yaml.Unmarshal([]byte(a.Policy), &policy); err != nil {
+			errors = append(errors, field.Invalid(path.Child("policy"), "", err.Error()))
+		} else if policy.Kind != "Policy" {
+			errors = append(errors, field.Invalid(path.Child("policy"), policy.Kind, "expected an audit policy of kind Policy"))
+		}
+	}
+
+	if a.Log == nil && a.Webhook == nil {
+		errors = append(errors, field.Required(path, "at least one of log or webhook is required"))
+	}
+
+	if a.Log != nil {
+		path := path.Child("log")
+		for name, value := range map[string]int{
+			"maxAge":    a.Log.MaxAge,
+			"maxBackup": a.Log.MaxBackup,
+			"maxSize":   a.Log.MaxSize,
+		} {
+			if value < 0 {
+				errors = append(errors, field.Invalid(path.Child(name), value, "must not be negative"))
+			}
+		}
+	}
+
+	if a.Webhook != nil {
+		path := path.Child("webhook")
+		if a.Webhook.ConfigFile == "" {
+			errors = append(errors, field.Required(path.Child("configFile"), ""))
+		}
+		switch a.Webhook.Mode {
+		case "", "batch", "blocking", "blocking-strict":
+		default:
+			errors = append(errors, field.NotSupported(path.Child("mode"), a.Webhook.Mode, []string{"batch", "blocking", "blocking-strict"}))
+		}
+		if a.Webhook.InitialBackoff != nil && a.Webhook.InitialBackoff.Duration <= 0 {
+			errors = append(errors, field.Invalid(path.Child("initialBackoff"), a.Webhook.InitialBackoff.String(), "must be positive"))
+		}
+	}
+
+	return errors
+}
+
+// BuildArgs adds the kube-apiserver flags for this audit configuration to
+// args. An inline policy is expected to have been written to the given file.
+func (a *Audit) BuildArgs(args stringmap.StringMap, inlinePolicyFile string) stringmap.StringMap {
+	if a == nil {
+		return args
+	}
+
+	if a.PolicyFile != "" {
+		args["audit-policy-file"] = a.PolicyFile
+	} else {
+		args["audit-policy-file"] = inlinePolicyFile
+	}
+
+	if a.Log != nil {
+		args["audit-log-path"] = a.LogPath()
+		for name, value := range map[string]int{
+			"audit-log-maxage":    a.Log.MaxAge,
+			"audit-log-maxbackup": a.Log.MaxBackup,
+			"audit-log-maxsize":   a.Log.MaxSize,
+		} {
+			if value > 0 {
+				args[name] = strconv.Itoa(value)
+			}
+		}
+	}
+
+	if a.Webhook != nil {
+		args["audit-webhook-config-file"] = a.Webhook.ConfigFile
+		if a.Webhook.Mode != "" {
+			args["audit-webhook-mode"] = a.Webhook.Mode
+		}
+		if a.Webhook.InitialBackoff != nil {
+			args["audit-webhook-initial-backoff"] = a.Webhook.InitialBackoff.Duration.String()
+		}
+	}
+
+	return args
+}
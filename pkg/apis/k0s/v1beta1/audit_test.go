@@ -0,0 +1,106 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit_FromConfig(t *testing.T) {
+	c, err := ConfigFromString(`
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+spec:
+  api:
+    audit:
+      policy: |
+        apiVersion: audit.k8s.io/v1
+        kind: Policy
+        rules:
+        - level: Metadata
+      log:
+        maxAge: 30
+        maxBackup: 10
+        maxSize: 100
+      webhook:
+        configFile: /etc/k0s/audit-webhook.conf
+        initialBackoff: 5s
+`)
+	require.NoError(t, err)
+	assert.Empty(t, c.Spec.API.Audit.Validate(field.NewPath("audit")))
+
+	args := c.Spec.API.Audit.BuildArgs(stringmap.StringMap{}, "/var/lib/k0s/audit-policy.yaml")
+	assert.Equal(t, stringmap.StringMap{
+		"audit-policy-file":             "/var/lib/k0s/audit-policy.yaml",
+		"audit-log-path":                DefaultAuditLogPath,
+		"audit-log-maxage":              "30",
+		"audit-log-maxbackup":           "10",
+		"audit-log-maxsize":             "100",
+		"audit-webhook-config-file":     "/etc/k0s/audit-webhook.conf",
+		"audit-webhook-initial-backoff": "5s",
+	}, args)
+}
+
+func TestAudit_BuildArgs(t *testing.T) {
+	var nilAudit *Audit
+	assert.Empty(t, nilAudit.BuildArgs(stringmap.StringMap{}, "ignored"))
+
+	audit := &Audit{
+		PolicyFile: "/etc/k0s/audit-policy.yaml",
+		Log:        &AuditLog{Path: "-"},
+	}
+	assert.Equal(t, stringmap.StringMap{
+		"audit-policy-file": "/etc/k0s/audit-policy.yaml",
+		"audit-log-path":    "-",
+	}, audit.BuildArgs(stringmap.StringMap{}, "ignored"))
+}
+
+func TestAudit_Validate(t *testing.T) {
+	const policy = "apiVersion: audit.k8s.io/v1\nkind: Policy\n"
+
+	for _, test := range []struct {
+		name   string
+		audit  *Audit
+		fields []string
+	}{
+		{"nil", nil, nil},
+		{"no_policy", &Audit{Log: &AuditLog{}}, []string{"audit.policy"}},
+		{"both_policies", &Audit{Policy: policy, PolicyFile: "/policy.yaml", Log: &AuditLog{}}, []string{"audit.policyFile"}},
+		{"wrong_kind", &Audit{Policy: "kind: ConfigMap", Log: &AuditLog{}}, []string{"audit.policy"}},
+		{"no_backend", &Audit{Policy: policy}, []string{"audit"}},
+		{"negative", &Audit{Policy: policy, Log: &AuditLog{MaxAge: -1}}, []string{"audit.log.maxAge"}},
+		{"webhook", &Audit{Policy: policy, Webhook: &AuditWebhook{Mode: "async"}}, []string{"audit.webhook.configFile", "audit.webhook.mode"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var fields []string
+			for _, err := range test.audit.Validate(field.NewPath("audit")) {
+				var fieldErr *field.Error
+				if assert.ErrorAs(t, err, &fieldErr) {
+					fields = append(fields, fieldErr.Field)
+				}
+			}
+			assert.Equal(t, test.fields, fields)
+		})
+	}
+}
@@ -16,16 +16,28 @@ limitations under the License.
 
 package v1beta1
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"net"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
 
 // Calico defines the calico related config options
 type Calico struct {
+	// BGP peers to be configured for the cluster (requires mode bird)
+	BGPPeers []CalicoBGPPeer `json:"bgpPeers,omitempty"`
+
 	// Enable wireguard-based encryption (default: false)
 	EnableWireguard bool `json:"wireguard"`
 
 	// Environment variables to configure Calico node (see https://docs.projectcalico.org/reference/node/configuration)
 	EnvVars map[string]string `json:"envVars,omitempty"`
 
+	// Felix settings for Calico node
+	Felix *CalicoFelix `json:"felix,omitempty"`
+
 	// The host path for Calicos flex-volume-driver(default: /usr/libexec/k0s/kubelet-plugins/volume/exec/nodeagent~uds)
 	FlexVolumeDriverPath string `json:"flexVolumeDriverPath"`
 
@@ -54,6 +66,41 @@ type Calico struct {
 	WithWindowsNodes bool `json:"withWindowsNodes"`
 }
 
+// CalicoBGPPeer defines a BGP peer to which Calico nodes will peer.
+type CalicoBGPPeer struct {
+	// Name of the BGPPeer resource
+	Name string `json:"name"`
+
+	// IP address of the peer, optionally followed by a port (e.g. 192.0.2.1 or [2001:db8::1]:179)
+	PeerIP string `json:"peerIP"`
+
+	// AS number of the peer
+	ASNumber uint32 `json:"asNumber"`
+
+	// Selector for the nodes that should peer with this peer (default: all nodes)
+	NodeSelector string `json:"nodeSelector,omitempty"`
+
+	// Maintain and forward the original next hop BGP route attribute to this peer
+	KeepOriginalNextHop bool `json:"keepOriginalNextHop,omitempty"`
+}
+
+// CalicoFelix defines the settings for Felix, the Calico per-node agent.
+type CalicoFelix struct {
+	// Log severity above which logs are sent to stdout (default: Info)
+	// +kubebuilder:validation:Enum=Debug;Info;Warning;Error;Fatal
+	LogSeverityScreen string `json:"logSeverityScreen,omitempty"`
+
+	// Enable the Prometheus metrics server in Felix (default: true)
+	PrometheusMetricsEnabled *bool `json:"prometheusMetricsEnabled,omitempty"`
+
+	// TCP port on which Felix serves Prometheus metrics (default: 9091)
+	PrometheusMetricsPort int `json:"prometheusMetricsPort,omitempty"`
+
+	// Which iptables backend Felix should use: auto, legacy or nft (default: auto)
+	// +kubebuilder:validation:Enum=auto;legacy;nft
+	IptablesBackend string `json:"iptablesBackend,omitempty"`
+}
+
 // DefaultCalico returns sane defaults for calico
 func DefaultCalico() *Calico {
 	return &Calico{
@@ -87,3 +134,70 @@ func (c *Calico) UnmarshalJSON(data []byte) error {
 	jc := (*calico)(c)
 	return json.Unmarshal(data, jc)
 }
+
+// Validate validates the Calico settings.
+func (c *Calico) Validate(path *field.Path) (errs field.ErrorList) {
+	if c == nil {
+		return
+	}
+
+	if len(c.BGPPeers) > 0 && c.Mode != "bird" {
+		errs = append(errs, field.Forbidden(path.Child("bgpPeers"), "BGP peers are only supported for mode `bird`"))
+	}
+	names := make(map[string]struct{}, len(c.BGPPeers))
+	for i, peer := range c.BGPPeers {
+		path := path.Child("bgpPeers").Index(i)
+		if peer.Name == "" {
+			errs = append(errs, field.Required(path.Child("name"), ""))
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(peer.Name) {
+				errs = append(errs, field.Invalid(path.Child("name"), peer.Name, msg))
+			}
+			if _, found := names[peer.Name]; found {
+				errs = append(errs, field.Duplicate(path.Child("name"), peer.Name))
+			}
+			names[peer.Name] = struct{}{}
+		}
+		if !isValidPeerIP(peer.PeerIP) {
+			errs = append(errs, field.Invalid(path.Child("peerIP"), peer.PeerIP, "invalid IP address"))
+		}
+		if peer.ASNumber == 0 {
+			errs = append(errs, field.Required(path.Child("asNumber"), ""))
+		}
+	}
+
+	errs = append(errs, c.Felix.Validate(path.Child("felix"))...)
+
+	return
+}
+
+func isValidPeerIP(addr string) bool {
+	if net.ParseIP(addr) != nil {
+		return true
+	}
+	host, _, err := net.SplitHostPort(addr)
+	return err == nil && net.ParseIP(host) != nil
+}
+
+// Validate validates the Felix settings.
+func (f *CalicoFelix) Validate(path *field.Path) (errs field.ErrorList) {
+	if f == nil {
+		return
+	}
+
+	switch f.LogSeverityScreen {
+	case "", "Debug", "Info", "Warning", "Error", "Fatal":
+	default:
+		errs = append(errs, field.NotSupported(path.Child("logSeverityScreen"), f.LogSeverityScreen, []string{"Debug", "Info", "Warning", "Error", "Fatal"}))
+	}
+	if f.PrometheusMetricsPort < 0 || f.PrometheusMetricsPort > 65535 {
+		errs = append(errs, field.Invalid(path.Child("prometheusMetricsPort"), f.PrometheusMetricsPort, "must be a valid port number"))
+	}
+	switch f.IptablesBackend {
+	case "", "auto", "legacy", "nft":
+	default:
+		errs = append(errs, field.NotSupported(path.Child("iptablesBackend"), f.IptablesBackend, []string{"auto", "legacy", "nft"}))
+	}
+
+	return
+}
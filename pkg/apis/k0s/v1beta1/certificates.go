@@ -0,0 +1,348 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"net/url"
+	"path/filepath"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ Validateable = (*CertificatesSpec)(nil)
+
+// CertificatesSpec defines the settings of the certificates managed by k0s
+type CertificatesSpec struct {
+	// An externally issued certificate authority that k0s uses to issue the
+	// cluster's certificates, instead of generating a self-signed one
+	// +optional
+	CA *CASpec `json:"ca,omitempty"`
+
+	// The algorithm of the private keys that k0s generates for certificate
+	// authorities and certificates. Defaults to RSA-2048.
+	// +kubebuilder:validation:Enum=RSA-2048;RSA-4096;ECDSA-P256
+	// +optional
+	KeyAlgorithm KeyAlgorithm `json:"keyAlgorithm,omitempty"`
+
+	// The validity of the certificate authorities that k0s generates.
+	// Defaults to 87600h (ten years).
+	// +optional
+	CAValidity *metav1.Duration `json:"caValidity,omitempty"`
+
+	// The validity of the certificates that k0s issues, unless specified
+	// otherwise. Defaults to 8760h (one year).
+	// +optional
+	Validity *metav1.Duration `json:"validity,omitempty"`
+
+	// The validity of the admin client certificate embedded into admin.conf.
+	// k0s renews it automatically before it expires. Defaults to the
+	// validity of the other certificates.
+	// +optional
+	AdminKubeconfigValidity *metav1.Duration `json:"adminKubeconfigValidity,omitempty"`
+
+	// Additional subject alternative names for the certificates of
+	// components other than the API server, e.g. if they're accessed via load
+	// balancers or NAT
+	// +optional
+	SANs *ComponentSANs `json:"sans,omitempty"`
+
+	// An external certificate authority that issues the serving certificates
+	// of the API server and etcd, instead of the CAs managed by k0s
+	// +optional
+	Provider *CertificateProviderSpec `json:"provider,omitempty"`
+}
+
+// CertificateProviderSpec configures an external certificate authority.
+type CertificateProviderSpec struct {
+	// The components whose certificates are issued by the provider. Defaults
+	// to all of them.
+	// +optional
+	Components []CertificateProviderComponent `json:"components,omitempty"`
+
+	// Issue certificates via the PKI secrets engine of HashiCorp Vault
+	// +optional
+	Vault *VaultProviderSpec `json:"vault,omitempty"`
+}
+
+// CertificateProviderComponent is a component whose certificates can be
+// issued by a certificate provider.
+// +kubebuilder:validation:Enum=apiserver;etcd
+type CertificateProviderComponent string
+
+const (
+	// CertificateProviderAPIServer is the API server's serving certificate.
+	CertificateProviderAPIServer CertificateProviderComponent = "apiserver"
+	// CertificateProviderEtcd are etcd's server and peer certificates.
+	CertificateProviderEtcd CertificateProviderComponent = "etcd"
+)
+
+// VaultProviderSpec configures the PKI secrets engine of a Vault server.
+type VaultProviderSpec struct {
+	// The address of the Vault server, e.g. https://vault.example.com:8200
+	Address string `json:"address"`
+
+	// Path to a file with the PEM encoded CA certificates to verify the
+	// Vault server with. Defaults to the system's trust store.
+	// +optional
+	CAFile string `json:"caFile,omitempty"`
+
+	// Path to a file with the token to authenticate with. The file is read
+	// for every request, so that it can be kept up to date externally, e.g.
+	// by a Vault agent.
+	TokenFile string `json:"tokenFile"`
+
+	// The Vault namespace (Vault Enterprise only)
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// The mount path of the PKI secrets engine (default: pki)
+	// +optional
+	Mount string `json:"mount,omitempty"`
+
+	// The role to issue certificates with
+	Role string `json:"role"`
+}
+
+// ComponentSANs holds additional subject alternative names per component.
+type ComponentSANs struct {
+	// Additional SANs for etcd's peer certificate
+	// +optional
+	EtcdPeer []string `json:"etcdPeer,omitempty"`
+
+	// Additional SANs for etcd's server certificate
+	// +optional
+	EtcdServer []string `json:"etcdServer,omitempty"`
+
+	// Additional SANs for konnectivity-server's certificate
+	// +optional
+	Konnectivity []string `json:"konnectivity,omitempty"`
+}
+
+// KeyAlgorithm is the algorithm and size of a private key.
+type KeyAlgorithm string
+
+const (
+	KeyAlgorithmRSA2048   KeyAlgorithm = "RSA-2048"
+	KeyAlgorithmRSA4096   KeyAlgorithm = "RSA-4096"
+	KeyAlgorithmECDSAP256 KeyAlgorithm = "ECDSA-P256"
+)
+
+const (
+	// DefaultCAValidity is the default validity of generated CAs.
+	DefaultCAValidity = 87600 * time.Hour
+	// DefaultCertificateValidity is the default validity of issued certificates.
+	DefaultCertificateValidity = 8760 * time.Hour
+	// MinAdminKubeconfigValidity is the minimum validity of the admin client
+	// certificate, so that it can be renewed in time.
+	MinAdminKubeconfigValidity = 10 * time.Minute
+)
+
+// CASpec references the files of an externally issued certificate authority
+type CASpec struct {
+	// Path to the PEM encoded CA certificate
+	CertFile string `json:"certFile"`
+
+	// Path to the PEM encoded private key of the CA certificate
+	KeyFile string `json:"keyFile"`
+
+	// Path to the PEM encoded certificates of the intermediate and root
+	// certificate authorities that issued the CA certificate. If given, the CA
+	// certificate has to chain up to one of them.
+	// +optional
+	ChainFile string `json:"chainFile,omitempty"`
+}
+
+// Validate implements [Validateable].
+func (c *CertificatesSpec) Validate() (errs []error) {
+	if c == nil {
+		return nil
+	}
+
+	switch c.KeyAlgorithm {
+	case "", KeyAlgorithmRSA2048, KeyAlgorithmRSA4096, KeyAlgorithmECDSAP256:
+	default:
+		errs = append(errs, field.NotSupported(field.NewPath("keyAlgorithm"), c.KeyAlgorithm,
+			[]string{string(KeyAlgorithmRSA2048), string(KeyAlgorithmRSA4096), string(KeyAlgorithmECDSAP256)}))
+	}
+
+	for _, v := range []struct {
+		name     string
+		validity *metav1.Duration
+	}{
+		{"caValidity", c.CAValidity},
+		{"validity", c.Validity},
+		{"adminKubeconfigValidity", c.AdminKubeconfigValidity},
+	} {
+		if v.validity != nil && v.validity.Duration <= 0 {
+			errs = append(errs, field.Invalid(field.NewPath(v.name), v.validity.Duration.String(), "must be positive"))
+		}
+	}
+	if caValidity, validity := c.GetCAValidity(), c.GetValidity(); caValidity > 0 && validity > caValidity {
+		errs = append(errs, field.Invalid(field.NewPath("validity"), validity.String(), "must not exceed the CA validity of "+caValidity.String()))
+	}
+	if v := c.AdminKubeconfigValidity; v != nil && v.Duration > 0 {
+		if v.Duration < MinAdminKubeconfigValidity {
+			errs = append(errs, field.Invalid(field.NewPath("adminKubeconfigValidity"), v.Duration.String(), "must be at least "+MinAdminKubeconfigValidity.String()))
+		} else if caValidity := c.GetCAValidity(); caValidity > 0 && v.Duration > caValidity {
+			errs = append(errs, field.Invalid(field.NewPath("adminKubeconfigValidity"), v.Duration.String(), "must not exceed the CA validity of "+caValidity.String()))
+		}
+	}
+
+	if c.SANs != nil {
+		path := field.NewPath("sans")
+		errs = append(errs, validateSANs(path.Child("etcdPeer"), c.SANs.EtcdPeer)...)
+		errs = append(errs, validateSANs(path.Child("etcdServer"), c.SANs.EtcdServer)...)
+		errs = append(errs, validateSANs(path.Child("konnectivity"), c.SANs.Konnectivity)...)
+	}
+
+	if c.Provider != nil {
+		errs = append(errs, c.Provider.validate(field.NewPath("provider"))...)
+	}
+
+	if c.CA == nil {
+		return errs
+	}
+
+	path := field.NewPath("ca")
+	for _, f := range []struct {
+		name, value string
+		required    bool
+	}{
+		{"certFile", c.CA.CertFile, true},
+		{"keyFile", c.CA.KeyFile, true},
+		{"chainFile", c.CA.ChainFile, false},
+	} {
+		switch {
+		case f.value == "" && f.required:
+			errs = append(errs, field.Required(path.Child(f.name), ""))
+		case f.value != "" && !filepath.IsAbs(f.value):
+			errs = append(errs, field.Invalid(path.Child(f.name), f.value, "must be an absolute path"))
+		}
+	}
+
+	return errs
+}
+
+// GetKeyAlgorithm returns the configured key algorithm or the default one.
+func (c *CertificatesSpec) GetKeyAlgorithm() KeyAlgorithm {
+	if c == nil || c.KeyAlgorithm == "" {
+		return KeyAlgorithmRSA2048
+	}
+	return c.KeyAlgorithm
+}
+
+// GetCAValidity returns the configured CA validity or the default one.
+func (c *CertificatesSpec) GetCAValidity() time.Duration {
+	if c == nil || c.CAValidity == nil {
+		return DefaultCAValidity
+	}
+	return c.CAValidity.Duration
+}
+
+// GetValidity returns the configured certificate validity or the default one.
+func (c *CertificatesSpec) GetValidity() time.Duration {
+	if c == nil || c.Validity == nil {
+		return DefaultCertificateValidity
+	}
+	return c.Validity.Duration
+}
+
+// GetAdminKubeconfigValidity returns the configured validity of the admin
+// client certificate or the certificate validity.
+func (c *CertificatesSpec) GetAdminKubeconfigValidity() time.Duration {
+	if c == nil || c.AdminKubeconfigValidity == nil {
+		return c.GetValidity()
+	}
+	return c.AdminKubeconfigValidity.Duration
+}
+
+// GetProvider returns the configured certificate provider, if any.
+func (c *CertificatesSpec) GetProvider() *CertificateProviderSpec {
+	if c == nil {
+		return nil
+	}
+	return c.Provider
+}
+
+// GetSANs returns the configured additional SANs per component.
+func (c *CertificatesSpec) GetSANs() ComponentSANs {
+	if c == nil || c.SANs == nil {
+		return ComponentSANs{}
+	}
+	return *c.SANs
+}
+
+// Issues returns true if the provider issues the certificates of the given
+// component.
+func (p *CertificateProviderSpec) Issues(component CertificateProviderComponent) bool {
+	if p == nil {
+		return false
+	}
+	if len(p.Components) == 0 {
+		return true
+	}
+	for _, c := range p.Components {
+		if c == component {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *CertificateProviderSpec) validate(path *field.Path) (errs []error) {
+	for i, c := range p.Components {
+		switch c {
+		case CertificateProviderAPIServer, CertificateProviderEtcd:
+		default:
+			errs = append(errs, field.NotSupported(path.Child("components").Index(i), c,
+				[]string{string(CertificateProviderAPIServer), string(CertificateProviderEtcd)}))
+		}
+	}
+
+	if p.Vault == nil {
+		return append(errs, field.Required(path.Child("vault"), ""))
+	}
+
+	path = path.Child("vault")
+	if u, err := url.Parse(p.Vault.Address); err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		errs = append(errs, field.Invalid(path.Child("address"), p.Vault.Address, "must be an http(s) URL"))
+	}
+	if p.Vault.Role == "" {
+		errs = append(errs, field.Required(path.Child("role"), ""))
+	}
+	switch {
+	case p.Vault.TokenFile == "":
+		errs = append(errs, field.Required(path.Child("tokenFile"), ""))
+	case !filepath.IsAbs(p.Vault.TokenFile):
+		errs = append(errs, field.Invalid(path.Child("tokenFile"), p.Vault.TokenFile, "must be an absolute path"))
+	}
+	if p.Vault.CAFile != "" && !filepath.IsAbs(p.Vault.CAFile) {
+		errs = append(errs, field.Invalid(path.Child("caFile"), p.Vault.CAFile, "must be an absolute path"))
+	}
+
+	return errs
+}
+
+// GetMount returns the configured mount path or the default one.
+func (v *VaultProviderSpec) GetMount() string {
+	if v.Mount == "" {
+		return "pki"
+	}
+	return v.Mount
+}
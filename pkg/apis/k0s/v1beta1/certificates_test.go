@@ -0,0 +1,176 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCertificatesSpec_Unmarshal(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  certificates:
+    keyAlgorithm: ECDSA-P256
+    caValidity: 8760h
+    validity: 720h
+    adminKubeconfigValidity: 24h
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Empty(t, c.Validate())
+
+	certs := c.Spec.Certificates
+	assert.Equal(t, KeyAlgorithmECDSAP256, certs.GetKeyAlgorithm())
+	assert.Equal(t, 8760*time.Hour, certs.GetCAValidity())
+	assert.Equal(t, 720*time.Hour, certs.GetValidity())
+	assert.Equal(t, 24*time.Hour, certs.GetAdminKubeconfigValidity())
+}
+
+func TestCertificatesSpec_Defaults(t *testing.T) {
+	var certs *CertificatesSpec
+	assert.Empty(t, certs.Validate())
+	assert.Equal(t, KeyAlgorithmRSA2048, certs.GetKeyAlgorithm())
+	assert.Equal(t, DefaultCAValidity, certs.GetCAValidity())
+	assert.Equal(t, DefaultCertificateValidity, certs.GetValidity())
+	assert.Equal(t, DefaultCertificateValidity, certs.GetAdminKubeconfigValidity())
+
+	certs = &CertificatesSpec{Validity: &metav1.Duration{Duration: 720 * time.Hour}}
+	assert.Equal(t, 720*time.Hour, certs.GetAdminKubeconfigValidity())
+}
+
+func TestCertificatesSpec_Validate(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		certs := &CertificatesSpec{
+			KeyAlgorithm: "DSA-1024",
+			CAValidity:   &metav1.Duration{Duration: -time.Hour},
+		}
+		errs := certs.Validate()
+		if assert.Len(t, errs, 2) {
+			assert.ErrorContains(t, errs[0], `keyAlgorithm: Unsupported value: "DSA-1024"`)
+			assert.ErrorContains(t, errs[1], `caValidity: Invalid value: "-1h0m0s": must be positive`)
+		}
+	})
+
+	t.Run("validity_exceeds_ca_validity", func(t *testing.T) {
+		certs := &CertificatesSpec{CAValidity: &metav1.Duration{Duration: 720 * time.Hour}}
+		errs := certs.Validate()
+		if assert.Len(t, errs, 1) {
+			assert.ErrorContains(t, errs[0], `validity: Invalid value: "8760h0m0s": must not exceed the CA validity of 720h0m0s`)
+		}
+	})
+
+	t.Run("admin_kubeconfig_validity", func(t *testing.T) {
+		for _, test := range []struct {
+			validity time.Duration
+			err      string
+		}{
+			{time.Hour, ""},
+			{-time.Hour, `adminKubeconfigValidity: Invalid value: "-1h0m0s": must be positive`},
+			{time.Minute, `adminKubeconfigValidity: Invalid value: "1m0s": must be at least 10m0s`},
+			{2 * DefaultCAValidity, `adminKubeconfigValidity: Invalid value: "175200h0m0s": must not exceed the CA validity of 87600h0m0s`},
+		} {
+			certs := &CertificatesSpec{AdminKubeconfigValidity: &metav1.Duration{Duration: test.validity}}
+			errs := certs.Validate()
+			if test.err == "" {
+				assert.Empty(t, errs, test.validity)
+			} else if assert.Len(t, errs, 1, test.validity) {
+				assert.ErrorContains(t, errs[0], test.err)
+			}
+		}
+	})
+}
+
+func TestCertificatesSpec_SANs(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  certificates:
+    sans:
+      etcdPeer: [10.0.0.1, etcd.example.com]
+      konnectivity: [konnectivity.example.com]
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Empty(t, c.Validate())
+
+	sans := c.Spec.Certificates.GetSANs()
+	assert.Equal(t, []string{"10.0.0.1", "etcd.example.com"}, sans.EtcdPeer)
+	assert.Empty(t, sans.EtcdServer)
+	assert.Equal(t, []string{"konnectivity.example.com"}, sans.Konnectivity)
+
+	c.Spec.Certificates.SANs.EtcdServer = []string{"not a name"}
+	errs := c.Spec.Certificates.Validate()
+	if assert.Len(t, errs, 1) {
+		assert.ErrorContains(t, errs[0], `sans.etcdServer[0]: Invalid value: "not a name": invalid IP address / DNS name`)
+	}
+}
+
+func TestCertificatesSpec_Provider(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  certificates:
+    provider:
+      components: [apiserver]
+      vault:
+        address: https://vault.example.com:8200
+        tokenFile: /run/vault/token
+        role: k0s
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Empty(t, c.Validate())
+
+	provider := c.Spec.Certificates.GetProvider()
+	assert.True(t, provider.Issues(CertificateProviderAPIServer))
+	assert.False(t, provider.Issues(CertificateProviderEtcd))
+	assert.Equal(t, "pki", provider.Vault.GetMount())
+
+	provider.Components = nil
+	assert.True(t, provider.Issues(CertificateProviderEtcd), "all components should be issued by default")
+
+	var noProvider *CertificateProviderSpec
+	assert.False(t, noProvider.Issues(CertificateProviderAPIServer))
+
+	provider.Components = []CertificateProviderComponent{"kubelet"}
+	provider.Vault = &VaultProviderSpec{Address: "vault:8200", TokenFile: "token"}
+	errs := c.Spec.Certificates.Validate()
+	if assert.Len(t, errs, 4) {
+		assert.ErrorContains(t, errs[0], `provider.components[0]: Unsupported value: "kubelet"`)
+		assert.ErrorContains(t, errs[1], `provider.vault.address: Invalid value: "vault:8200": must be an http(s) URL`)
+		assert.ErrorContains(t, errs[2], `provider.vault.role: Required value`)
+		assert.ErrorContains(t, errs[3], `provider.vault.tokenFile: Invalid value: "token": must be an absolute path`)
+	}
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// CloudProviderType is the type of an external cloud controller manager.
+type CloudProviderType string
+
+const (
+	// CloudProviderAWS deploys the AWS cloud controller manager.
+	CloudProviderAWS CloudProviderType = "aws"
+	// CloudProviderOpenStack deploys the OpenStack cloud controller manager.
+	CloudProviderOpenStack CloudProviderType = "openstack"
+	// CloudProviderHetzner deploys the Hetzner Cloud cloud controller manager.
+	CloudProviderHetzner CloudProviderType = "hcloud"
+)
+
+var _ Validateable = (*CloudProviderExtension)(nil)
+
+// CloudProviderExtension defines the configuration options related to the
+// external cloud controller manager that k0s deploys into the cluster.
+type CloudProviderExtension struct {
+	// enabled indicates if the cloud controller manager should be deployed.
+	// Worker nodes will run their kubelets with an external cloud provider
+	// whenever this is enabled.
+	// Default: false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// provider is the cloud provider whose cloud controller manager is
+	// deployed. Required if enabled.
+	// +kubebuilder:validation:Enum=aws;openstack;hcloud
+	// +optional
+	Provider CloudProviderType `json:"provider,omitempty"`
+
+	// secretName is the name of the Secret in the kube-system namespace that
+	// holds the provider's credentials. For OpenStack, it's expected to
+	// contain the cloud.conf file. For Hetzner Cloud, it's expected to contain
+	// the token and, optionally, the network keys. AWS doesn't require a
+	// Secret, since the cloud controller manager uses the instance profile.
+	// Defaults to "cloud-config" for OpenStack and "hcloud" for Hetzner Cloud.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// image specifies the OCI image that's being used for the cloud
+	// controller manager. Defaults to the provider's upstream image.
+	// +optional
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// imagePullPolicy specifies the pull policy being used for the cloud
+	// controller manager. Defaults to the default image pull policy.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// extraArgs are additional command line arguments for the cloud
+	// controller manager. They take precedence over the ones set by k0s.
+	// +optional
+	ExtraArgs map[string]string `json:"extraArgs,omitempty"`
+}
+
+var _ json.Unmarshaler = (*CloudProviderExtension)(nil)
+
+func (c *CloudProviderExtension) UnmarshalJSON(data []byte) error {
+	type cloudProviderExtension CloudProviderExtension
+	if err := json.Unmarshal(data, (*cloudProviderExtension)(c)); err != nil {
+		return err
+	}
+
+	c.setDefaults()
+
+	return nil
+}
+
+func (c *CloudProviderExtension) setDefaults() {
+	if c.SecretName == "" {
+		c.SecretName = DefaultCloudProviderSecretName(c.Provider)
+	}
+
+	defaultImage := DefaultCloudProviderImage(c.Provider)
+	if defaultImage == nil {
+		return
+	}
+	if c.Image == nil {
+		c.Image = defaultImage
+	} else {
+		if c.Image.Image == "" {
+			c.Image.Image = defaultImage.Image
+		}
+		if c.Image.Version == "" {
+			c.Image.Version = defaultImage.Version
+		}
+	}
+}
+
+// Validate implements [Validateable].
+func (c *CloudProviderExtension) Validate() (errs []error) {
+	if c == nil {
+		return
+	}
+
+	path := field.NewPath("cloudProvider")
+
+	switch c.Provider {
+	case CloudProviderAWS, CloudProviderOpenStack, CloudProviderHetzner:
+		break
+	case "":
+		if c.Enabled {
+			errs = append(errs, field.Required(path.Child("provider"), "provider must be set"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(
+			path.Child("provider"), c.Provider, []string{
+				string(CloudProviderAWS),
+				string(CloudProviderOpenStack),
+				string(CloudProviderHetzner),
+			},
+		))
+	}
+
+	if c.Image != nil {
+		for _, err := range c.Image.Validate(path.Child("image")) {
+			errs = append(errs, err)
+		}
+	}
+
+	switch c.ImagePullPolicy {
+	case corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent, "":
+		break
+	default:
+		errs = append(errs, field.NotSupported(
+			path.Child("imagePullPolicy"), c.ImagePullPolicy, []string{
+				string(corev1.PullAlways),
+				string(corev1.PullNever),
+				string(corev1.PullIfNotPresent),
+			},
+		))
+	}
+
+	return
+}
+
+func (c *CloudProviderExtension) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// DefaultCloudProviderSecretName returns the name of the Secret that holds the
+// credentials for the given provider, if any.
+func DefaultCloudProviderSecretName(provider CloudProviderType) string {
+	switch provider {
+	case CloudProviderOpenStack:
+		return "cloud-config"
+	case CloudProviderHetzner:
+		return "hcloud"
+	default:
+		return ""
+	}
+}
+
+// DefaultCloudProviderImage returns the default image spec to use for the
+// cloud controller manager of the given provider. Returns nil for unknown
+// providers.
+func DefaultCloudProviderImage(provider CloudProviderType) *ImageSpec {
+	switch provider {
+	case CloudProviderAWS:
+		return &ImageSpec{
+			Image:   constant.AWSCCMImage,
+			Version: constant.AWSCCMImageVersion,
+		}
+	case CloudProviderOpenStack:
+		return &ImageSpec{
+			Image:   constant.OpenStackCCMImage,
+			Version: constant.OpenStackCCMImageVersion,
+		}
+	case CloudProviderHetzner:
+		return &ImageSpec{
+			Image:   constant.HetznerCCMImage,
+			Version: constant.HetznerCCMImageVersion,
+		}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCloudProviderExtension_Unmarshal(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  images:
+    repository: example.com
+  extensions:
+    cloudProvider:
+      enabled: true
+      provider: openstack
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Nil(t, c.Validate())
+
+	cloudProvider := c.Spec.Extensions.CloudProvider
+	require.NotNil(t, cloudProvider)
+	assert.True(t, cloudProvider.IsEnabled())
+	assert.Equal(t, CloudProviderOpenStack, cloudProvider.Provider)
+	assert.Equal(t, "cloud-config", cloudProvider.SecretName)
+	require.NotNil(t, cloudProvider.Image)
+	assert.Equal(t, "example.com/provider-os/openstack-cloud-controller-manager", cloudProvider.Image.Image)
+	assert.Equal(t, DefaultCloudProviderImage(CloudProviderOpenStack).Version, cloudProvider.Image.Version)
+}
+
+func TestCloudProviderExtension_Validate(t *testing.T) {
+	assert.Empty(t, (*CloudProviderExtension)(nil).Validate())
+	assert.Empty(t, (&CloudProviderExtension{}).Validate())
+	assert.Empty(t, (&CloudProviderExtension{Enabled: true, Provider: CloudProviderAWS}).Validate())
+
+	for _, test := range []struct {
+		name   string
+		modify func(*CloudProviderExtension)
+		err    string
+	}{
+		{"provider_missing", func(c *CloudProviderExtension) { c.Provider = "" }, "cloudProvider.provider: Required value: provider must be set"},
+		{"provider", func(c *CloudProviderExtension) { c.Provider = "gce" }, `cloudProvider.provider: Unsupported value: "gce"`},
+		{"image", func(c *CloudProviderExtension) { c.Image = &ImageSpec{Image: "foo", Version: "bar baz"} }, "cloudProvider.image.version: Invalid value"},
+		{"imagePullPolicy", func(c *CloudProviderExtension) { c.ImagePullPolicy = "Sometimes" }, `cloudProvider.imagePullPolicy: Unsupported value: "Sometimes"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := CloudProviderExtension{Enabled: true, Provider: CloudProviderHetzner}
+			test.modify(&c)
+			errs := c.Validate()
+			if assert.Len(t, errs, 1) {
+				assert.ErrorContains(t, errs[0], test.err)
+			}
+		})
+	}
+}
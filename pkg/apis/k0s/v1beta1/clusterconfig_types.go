@@ -51,19 +51,65 @@ type ClusterSpec struct {
 	Extensions        *ClusterExtensions     `json:"extensions,omitempty"`
 	Konnectivity      *KonnectivitySpec      `json:"konnectivity,omitempty"`
 	FeatureGates      FeatureGates           `json:"featureGates,omitempty"`
+	Applier           *ApplierSpec           `json:"applier,omitempty"`
+	Certificates      *CertificatesSpec      `json:"certificates,omitempty"`
+	Prober            *ProberSpec            `json:"prober,omitempty"`
+	LeaderElection    *LeaderElectionSpec    `json:"leaderElection,omitempty"`
+	EventForwarding   *EventForwardingSpec   `json:"eventForwarding,omitempty"`
+	MetricsScraper    *MetricsScraperSpec    `json:"metricsScraper,omitempty"`
+	// metricsServer defines the configuration options related to the
+	// metrics-server cluster component.
+	// +optional
+	MetricsServer *MetricsServerSpec `json:"metricsServer,omitempty"`
+	// snapshotController defines the configuration options related to the
+	// CSI snapshot controller cluster component.
+	// +optional
+	SnapshotController *SnapshotControllerSpec `json:"snapshotController,omitempty"`
 }
 
+// Condition types of a ClusterConfig.
+const (
+	// ClusterConfigReconciled indicates whether the last reconciliation of
+	// the ClusterConfig succeeded for all components.
+	ClusterConfigReconciled = "Reconciled"
+	// ClusterConfigDegraded indicates that the ClusterConfig couldn't be
+	// applied, either because it's invalid or because some components failed
+	// to reconcile it.
+	ClusterConfigDegraded = "Degraded"
+)
+
 // ClusterConfigStatus defines the observed state of ClusterConfig
 type ClusterConfigStatus struct {
-	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
-	// Important: Run "make" to regenerate code after modifying this file
+	// The generation of the ClusterConfig that has been reconciled last.
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The controller that reconciled the ClusterConfig last.
+	ReconciledBy string `json:"reconciledBy,omitempty"`
+
+	// The latest observations of the ClusterConfig's state.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// The errors of the components that failed to reconcile the
+	// ClusterConfig the last time.
+	ComponentErrors []ComponentError `json:"componentErrors,omitempty"`
+}
+
+// ComponentError is the error of a component that failed to reconcile the
+// ClusterConfig.
+type ComponentError struct {
+	// The name of the component.
+	Component string `json:"component"`
+	// The error message.
+	Message string `json:"message"`
 }
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
 //+kubebuilder:validation:Optional
 // +genclient
-// +genclient:onlyVerbs=create,delete,list,get,watch,update
+// +genclient:onlyVerbs=create,delete,list,get,watch,update,updateStatus
 // +groupName=k0s.k0sproject.io
 
 // ClusterConfig is the Schema for the clusterconfigs API
@@ -102,6 +148,15 @@ func (c *ClusterConfig) StripDefaults() *ClusterConfig {
 	if reflect.DeepEqual(copy.Spec.Konnectivity, DefaultKonnectivitySpec()) {
 		copy.Spec.Konnectivity = nil
 	}
+	if reflect.DeepEqual(copy.Spec.Applier, DefaultApplierSpec()) {
+		copy.Spec.Applier = nil
+	}
+	if reflect.DeepEqual(copy.Spec.Prober, DefaultProberSpec()) {
+		copy.Spec.Prober = nil
+	}
+	if reflect.DeepEqual(copy.Spec.LeaderElection, DefaultLeaderElectionSpec()) {
+		copy.Spec.LeaderElection = nil
+	}
 	return copy
 }
 
@@ -263,6 +318,15 @@ func (c *ClusterConfig) UnmarshalJSON(data []byte) error {
 	if jc.Spec.Konnectivity == nil {
 		jc.Spec.Konnectivity = DefaultKonnectivitySpec()
 	}
+	if jc.Spec.Applier == nil {
+		jc.Spec.Applier = DefaultApplierSpec()
+	}
+	if jc.Spec.Prober == nil {
+		jc.Spec.Prober = DefaultProberSpec()
+	}
+	if jc.Spec.LeaderElection == nil {
+		jc.Spec.LeaderElection = DefaultLeaderElectionSpec()
+	}
 
 	jc.Spec.overrideImageRepositories()
 
@@ -289,6 +353,9 @@ func DefaultClusterSpec(defaultStorage ...*StorageSpec) *ClusterSpec {
 		Images:            DefaultClusterImages(),
 		Telemetry:         DefaultClusterTelemetry(),
 		Konnectivity:      DefaultKonnectivitySpec(),
+		Applier:           DefaultApplierSpec(),
+		Prober:            DefaultProberSpec(),
+		LeaderElection:    DefaultLeaderElectionSpec(),
 	}
 
 	spec.overrideImageRepositories()
@@ -308,16 +375,25 @@ func (s *ClusterSpec) Validate() (errs []error) {
 	}
 
 	for name, field := range map[string]Validateable{
-		"api":               s.API,
-		"controllerManager": s.ControllerManager,
-		"scheduler":         s.Scheduler,
-		"storage":           s.Storage,
-		"network":           s.Network,
-		"workerProfiles":    s.WorkerProfiles,
-		"telemetry":         s.Telemetry,
-		"install":           s.Install,
-		"extensions":        s.Extensions,
-		"konnectivity":      s.Konnectivity,
+		"api":                s.API,
+		"controllerManager":  s.ControllerManager,
+		"scheduler":          s.Scheduler,
+		"storage":            s.Storage,
+		"network":            s.Network,
+		"workerProfiles":     s.WorkerProfiles,
+		"telemetry":          s.Telemetry,
+		"install":            s.Install,
+		"extensions":         s.Extensions,
+		"konnectivity":       s.Konnectivity,
+		"applier":            s.Applier,
+		"certificates":       s.Certificates,
+		"prober":             s.Prober,
+		"leaderElection":     s.LeaderElection,
+		"eventForwarding":    s.EventForwarding,
+		"metricsScraper":     s.MetricsScraper,
+		"metricsServer":      s.MetricsServer,
+		"snapshotController": s.SnapshotController,
+		"featureGates":       s.FeatureGates,
 	} {
 		for _, err := range field.Validate() {
 			errs = append(errs, fmt.Errorf("%s: %w", name, err))
@@ -332,6 +408,12 @@ func (s *ClusterSpec) Validate() (errs []error) {
 		errs = append(errs, err)
 	}
 
+	if s.API != nil && s.API.TunneledNetworkingMode && s.Konnectivity != nil &&
+		s.Konnectivity.Agent != nil && s.Konnectivity.Agent.Mode == KonnectivityAgentModeDeployment {
+		detail := "konnectivity agents need to run on each node in tunneled networking mode"
+		errs = append(errs, field.Forbidden(field.NewPath("konnectivity", "agent", "mode"), detail))
+	}
+
 	return
 }
 
@@ -359,15 +441,38 @@ func (s *ClusterSpec) ValidateNodeLocalLoadBalancing() (errs field.ErrorList) {
 }
 
 func (s *ClusterSpec) overrideImageRepositories() {
-	if s != nil &&
-		s.Images != nil &&
-		s.Images.Repository != "" &&
-		s.Network != nil &&
-		s.Network.NodeLocalLoadBalancing != nil &&
-		s.Network.NodeLocalLoadBalancing.EnvoyProxy != nil &&
-		s.Network.NodeLocalLoadBalancing.EnvoyProxy.Image != nil {
-		i := s.Network.NodeLocalLoadBalancing.EnvoyProxy.Image
-		i.Image = overrideRepository(s.Images.Repository, i.Image)
+	if s == nil || s.Images == nil || s.Images.Repository == "" {
+		return
+	}
+
+	override := func(i *ImageSpec) {
+		if i != nil {
+			i.Image = overrideRepository(s.Images.Repository, i.Image)
+		}
+	}
+
+	if s.Network != nil {
+		if nllb := s.Network.NodeLocalLoadBalancing; nllb != nil && nllb.EnvoyProxy != nil {
+			override(nllb.EnvoyProxy.Image)
+		}
+		if nodeLocalDNS := s.Network.NodeLocalDNSCache; nodeLocalDNS != nil {
+			override(nodeLocalDNS.Image)
+		}
+		if slb := s.Network.ServiceLoadBalancer; slb != nil && slb.MetalLB != nil {
+			override(slb.MetalLB.ControllerImage)
+			override(slb.MetalLB.SpeakerImage)
+		}
+	}
+	if snapshotController := s.SnapshotController; snapshotController != nil {
+		override(snapshotController.Image)
+	}
+	if s.Extensions != nil {
+		if s.Extensions.Ingress != nil {
+			override(s.Extensions.Ingress.Image)
+		}
+		if s.Extensions.CloudProvider != nil {
+			override(s.Extensions.CloudProvider.Image)
+		}
 	}
 }
 
@@ -384,6 +489,36 @@ func (c *ClusterConfig) Validate() (errs []error) {
 	return errs
 }
 
+// ValidateUpdate checks that the update from the given old configuration
+// doesn't change any of the fields that can't be changed once the cluster has
+// been created.
+func (c *ClusterConfig) ValidateUpdate(old *ClusterConfig) (errs field.ErrorList) {
+	if c == nil || c.Spec == nil || old == nil || old.Spec == nil {
+		return nil
+	}
+
+	immutable := func(path *field.Path, value, oldValue string) {
+		if value != oldValue {
+			errs = append(errs, field.Forbidden(path, fmt.Sprintf("cannot be changed from %q to %q", oldValue, value)))
+		}
+	}
+
+	spec, oldSpec := c.Spec, old.Spec
+	if spec.Storage != nil && oldSpec.Storage != nil {
+		immutable(field.NewPath("spec", "storage", "type"), spec.Storage.Type, oldSpec.Storage.Type)
+	}
+	if spec.Network != nil && oldSpec.Network != nil {
+		path := field.NewPath("spec", "network")
+		immutable(path.Child("provider"), spec.Network.Provider, oldSpec.Network.Provider)
+		immutable(path.Child("podCIDR"), spec.Network.PodCIDR, oldSpec.Network.PodCIDR)
+		immutable(path.Child("serviceCIDR"), spec.Network.ServiceCIDR, oldSpec.Network.ServiceCIDR)
+		immutable(path.Child("dualStack", "IPv6podCIDR"), spec.Network.DualStack.IPv6PodCIDR, oldSpec.Network.DualStack.IPv6PodCIDR)
+		immutable(path.Child("dualStack", "IPv6serviceCIDR"), spec.Network.DualStack.IPv6ServiceCIDR, oldSpec.Network.DualStack.IPv6ServiceCIDR)
+	}
+
+	return errs
+}
+
 // GetBootstrappingConfig returns a ClusterConfig object stripped of Cluster-Wide Settings
 func (c *ClusterConfig) GetBootstrappingConfig(storageSpec *StorageSpec) *ClusterConfig {
 	var etcdConfig *EtcdConfig
@@ -406,7 +541,11 @@ func (c *ClusterConfig) GetBootstrappingConfig(storageSpec *StorageSpec) *Cluste
 				DualStack:     c.Spec.Network.DualStack,
 				ClusterDomain: c.Spec.Network.ClusterDomain,
 			},
-			Install: c.Spec.Install,
+			Install:        c.Spec.Install,
+			Applier:        c.Spec.Applier,
+			Certificates:   c.Spec.Certificates,
+			Prober:         c.Spec.Prober,
+			LeaderElection: c.Spec.LeaderElection,
 		},
 		Status: c.Status,
 	}
@@ -420,6 +559,10 @@ func (c *ClusterConfig) GetBootstrappingConfig(storageSpec *StorageSpec) *Cluste
 // - Network.ServiceCIDR
 // - Network.ClusterDomain
 // - Install
+// - Applier
+// - Certificates
+// - Prober
+// - LeaderElection
 func (c *ClusterConfig) GetClusterWideConfig() *ClusterConfig {
 	c = c.DeepCopy()
 	if c != nil && c.Spec != nil {
@@ -430,6 +573,10 @@ func (c *ClusterConfig) GetClusterWideConfig() *ClusterConfig {
 			c.Spec.Network.ClusterDomain = ""
 		}
 		c.Spec.Install = nil
+		c.Spec.Applier = nil
+		c.Spec.Certificates = nil
+		c.Spec.Prober = nil
+		c.Spec.LeaderElection = nil
 	}
 
 	return c
@@ -142,6 +142,33 @@ spec:
 	}
 }
 
+func TestKonnectivityValidation(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  api:
+    port: 7443
+    tunneledNetworkingMode: true
+  konnectivity:
+    serverCount: -1
+    agent:
+      mode: Deployment
+      replicas: 0
+`
+
+	c, err := ConfigFromString(yamlData)
+	assert.NoError(t, err)
+	errors := c.Validate()
+	if assert.Len(t, errors, 3) {
+		assert.ErrorContains(t, errors[0], `spec: konnectivity: serverCount: Invalid value: -1: must not be negative`)
+		assert.ErrorContains(t, errors[1], `spec: konnectivity: agent.replicas: Invalid value: 0: must be greater than zero`)
+		assert.ErrorContains(t, errors[2], `spec: konnectivity.agent.mode: Forbidden: konnectivity agents need to run on each node in tunneled networking mode`)
+	}
+}
+
 func TestApiExternalAddress(t *testing.T) {
 	yamlData := `
 apiVersion: k0s.k0sproject.io/v1beta1
@@ -261,6 +288,7 @@ func TestStripDefaults(t *testing.T) {
 	a.Nil(stripped.Spec.ControllerManager)
 	a.Nil(stripped.Spec.Scheduler)
 	a.Nil(stripped.Spec.Network)
+	a.Nil(stripped.Spec.Prober)
 }
 
 func TestFeatureGates(t *testing.T) {
@@ -299,3 +327,32 @@ func TestFeatureGates(t *testing.T) {
 
 	assert.False(t, c.Spec.FeatureGates[2].Enabled)
 }
+
+func TestClusterConfig_ValidateUpdate(t *testing.T) {
+	old := DefaultClusterConfig()
+
+	t.Run("mutable", func(t *testing.T) {
+		c := old.DeepCopy()
+		c.Spec.Network.KubeRouter.MTU = 1350
+		assert.Empty(t, c.ValidateUpdate(old))
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		c := old.DeepCopy()
+		c.Spec.Storage.Type = KineStorageType
+		c.Spec.Network.Provider = "calico"
+		c.Spec.Network.PodCIDR = "10.100.0.0/16"
+		c.Spec.Network.DualStack.IPv6ServiceCIDR = "fd00::/108"
+
+		var fields []string
+		for _, err := range c.ValidateUpdate(old) {
+			fields = append(fields, err.Field)
+		}
+		assert.Equal(t, []string{
+			"spec.storage.type",
+			"spec.network.provider",
+			"spec.network.podCIDR",
+			"spec.network.dualStack.IPv6serviceCIDR",
+		}, fields)
+	})
+}
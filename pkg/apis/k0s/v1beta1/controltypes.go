@@ -16,7 +16,11 @@ limitations under the License.
 
 package v1beta1
 
-import "fmt"
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // CaResponse defines the response type for /ca control API
 type CaResponse struct {
@@ -24,6 +28,12 @@ type CaResponse struct {
 	Cert  []byte `json:"cert"`
 	SAKey []byte `json:"saKey"`
 	SAPub []byte `json:"saPub"`
+
+	// The CA that's rotated in, while a CA rotation is in the trust phase
+	NextKey  []byte `json:"nextKey,omitempty"`
+	NextCert []byte `json:"nextCert,omitempty"`
+	// The CA that's rotated out, while a CA rotation is in the reissue phase
+	PreviousCert []byte `json:"previousCert,omitempty"`
 }
 
 // EtcdRequest defines the etcd control api request structure
@@ -50,3 +60,44 @@ type EtcdResponse struct {
 	CA             CaResponse `json:"ca"`
 	InitialCluster []string   `json:"initialCluster"`
 }
+
+// ClusterStatusResponse defines the response type for /cluster/status control API
+type ClusterStatusResponse struct {
+	// The controllers of the cluster, as reported by themselves
+	Controllers []ControllerStatusResponse `json:"controllers"`
+	// The names of the controllers that hold the leader leases, by lease name
+	Leaders map[string]string `json:"leaders"`
+	// The k0s nodes of the cluster, as published by the k0s instances
+	// running on them
+	Nodes []NodeStatusResponse `json:"nodes"`
+}
+
+// ControllerStatusResponse defines the status of a single controller in the
+// /cluster/status control API
+type ControllerStatusResponse struct {
+	Name       string   `json:"name"`
+	Version    string   `json:"version,omitempty"`
+	ConfigHash string   `json:"configHash,omitempty"`
+	Leases     []string `json:"leases,omitempty"`
+	// Unset if the controller doesn't run an etcd member
+	EtcdHealthy *bool `json:"etcdHealthy,omitempty"`
+	// Unset if the controller didn't report its status yet
+	LastHeartbeatTime *metav1.Time `json:"lastHeartbeatTime,omitempty"`
+}
+
+// NodeStatusResponse defines the status of a single k0s node in the
+// /cluster/status control API
+type NodeStatusResponse struct {
+	Name    string `json:"name"`
+	Role    string `json:"role"`
+	Version string `json:"version,omitempty"`
+	// The status of the autopilot update that's applied to the node, if any
+	AutopilotStatus string `json:"autopilotStatus,omitempty"`
+	// The version of the cluster CA bundle that the node trusts
+	CABundleVersion string `json:"caBundleVersion,omitempty"`
+	// The messages of the components that failed their last health probe,
+	// by component name
+	UnhealthyComponents map[string]string `json:"unhealthyComponents,omitempty"`
+	// Unset if the node didn't publish its status yet
+	LastUpdateTime *metav1.Time `json:"lastUpdateTime,omitempty"`
+}
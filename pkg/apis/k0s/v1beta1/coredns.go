@@ -0,0 +1,304 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/asaskevich/govalidator"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// CoreDNS defines the configuration options for the CoreDNS cluster component.
+type CoreDNS struct {
+	// upstreamResolvers are the DNS servers to which queries for names outside
+	// of the cluster domain are forwarded, given as IP addresses with an
+	// optional port. Defaults to the resolvers in the host's /etc/resolv.conf.
+	// +optional
+	UpstreamResolvers []string `json:"upstreamResolvers,omitempty"`
+
+	// stubDomains maps DNS domains to the DNS servers that are authoritative
+	// for them, given as IP addresses with an optional port.
+	// +optional
+	StubDomains map[string][]string `json:"stubDomains,omitempty"`
+
+	// rewrites are rules that rewrite the names of DNS queries before they are
+	// resolved.
+	// +optional
+	Rewrites []CoreDNSRewrite `json:"rewrites,omitempty"`
+
+	// serverBlocks are additional server blocks that are appended to the
+	// Corefile as-is.
+	// +optional
+	ServerBlocks []string `json:"serverBlocks,omitempty"`
+
+	// replicas configures the number of CoreDNS replicas. By default, there's
+	// one replica plus an extra one per ten nodes.
+	// +optional
+	Replicas *CoreDNSReplicas `json:"replicas,omitempty"`
+
+	// resources are the compute resources of the CoreDNS containers.
+	// Default: requests of 100m CPU and 70Mi memory, limit of 170Mi memory.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// topologySpreadConstraints describe how CoreDNS replicas are spread
+	// across the cluster. If set, they replace the default rule that requires
+	// each replica to run on a different node. Constraints without a label
+	// selector select the CoreDNS Pods.
+	// +optional
+	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
+
+	// podDisruptionBudget configures a PodDisruptionBudget for CoreDNS.
+	// Default: none
+	// +optional
+	PodDisruptionBudget *CoreDNSPodDisruptionBudget `json:"podDisruptionBudget,omitempty"`
+}
+
+// CoreDNSReplicas configures the number of CoreDNS replicas.
+type CoreDNSReplicas struct {
+	// count is a fixed number of replicas. If set, the number of replicas
+	// won't depend on the number of nodes.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Count *int32 `json:"count,omitempty"`
+
+	// nodesPerReplica is the number of nodes per extra replica.
+	// Default: 10
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	NodesPerReplica int32 `json:"nodesPerReplica,omitempty"`
+
+	// min is the minimum number of replicas.
+	// Default: 1
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Min int32 `json:"min,omitempty"`
+
+	// max is the maximum number of replicas.
+	// Default: unlimited
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Max int32 `json:"max,omitempty"`
+}
+
+// CoreDNSPodDisruptionBudget configures the PodDisruptionBudget for CoreDNS.
+// Exactly one of its fields needs to be set.
+type CoreDNSPodDisruptionBudget struct {
+	// minAvailable is the number or percentage of CoreDNS replicas that need
+	// to remain available during voluntary disruptions.
+	// +optional
+	MinAvailable *intstr.IntOrString `json:"minAvailable,omitempty"`
+
+	// maxUnavailable is the number or percentage of CoreDNS replicas that may
+	// be unavailable during voluntary disruptions.
+	// +optional
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+}
+
+// CoreDNSRewrite rewrites the names of DNS queries, e.g. in order to serve an
+// external name from a cluster service.
+type CoreDNSRewrite struct {
+	// match specifies how names are matched against from (default: exact).
+	// +kubebuilder:default=exact
+	// +optional
+	Match CoreDNSRewriteMatch `json:"match,omitempty"`
+
+	// from is the name to be rewritten, or the pattern of the names to be
+	// rewritten, depending on match.
+	From string `json:"from"`
+
+	// to is the name that matching names are rewritten to. Regex rewrites may
+	// refer to capture groups via {1}, {2}, etc.
+	To string `json:"to"`
+}
+
+// +kubebuilder:validation:Enum=exact;prefix;suffix;substring;regex
+type CoreDNSRewriteMatch string
+
+const (
+	CoreDNSRewriteMatchExact     CoreDNSRewriteMatch = "exact"
+	CoreDNSRewriteMatchPrefix    CoreDNSRewriteMatch = "prefix"
+	CoreDNSRewriteMatchSuffix    CoreDNSRewriteMatch = "suffix"
+	CoreDNSRewriteMatchSubstring CoreDNSRewriteMatch = "substring"
+	CoreDNSRewriteMatchRegex     CoreDNSRewriteMatch = "regex"
+)
+
+// Validate validates the CoreDNS settings.
+func (c *CoreDNS) Validate(path *field.Path) (errs field.ErrorList) {
+	if c == nil {
+		return
+	}
+
+	for i, resolver := range c.UpstreamResolvers {
+		errs = append(errs, validateDNSResolver(path.Child("upstreamResolvers").Index(i), resolver)...)
+	}
+
+	domains := make([]string, 0, len(c.StubDomains))
+	for domain := range c.StubDomains {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	for _, domain := range domains {
+		path := path.Child("stubDomains").Key(domain)
+		if !govalidator.IsDNSName(domain) {
+			errs = append(errs, field.Invalid(path, domain, "invalid DNS name"))
+		}
+		resolvers := c.StubDomains[domain]
+		if len(resolvers) == 0 {
+			errs = append(errs, field.Required(path, "at least one resolver is required"))
+		}
+		for i, resolver := range resolvers {
+			errs = append(errs, validateDNSResolver(path.Index(i), resolver)...)
+		}
+	}
+
+	for i, rewrite := range c.Rewrites {
+		errs = append(errs, rewrite.Validate(path.Child("rewrites").Index(i))...)
+	}
+
+	errs = append(errs, c.Replicas.Validate(path.Child("replicas"))...)
+
+	for i, constraint := range c.TopologySpreadConstraints {
+		path := path.Child("topologySpreadConstraints").Index(i)
+		if constraint.MaxSkew < 1 {
+			errs = append(errs, field.Invalid(path.Child("maxSkew"), constraint.MaxSkew, "must be greater than zero"))
+		}
+		if constraint.TopologyKey == "" {
+			errs = append(errs, field.Required(path.Child("topologyKey"), ""))
+		}
+		switch constraint.WhenUnsatisfiable {
+		case corev1.DoNotSchedule, corev1.ScheduleAnyway:
+		default:
+			errs = append(errs, field.NotSupported(path.Child("whenUnsatisfiable"), constraint.WhenUnsatisfiable, []string{
+				string(corev1.DoNotSchedule),
+				string(corev1.ScheduleAnyway),
+			}))
+		}
+	}
+
+	if pdb := c.PodDisruptionBudget; pdb != nil {
+		path := path.Child("podDisruptionBudget")
+		if (pdb.MinAvailable == nil) == (pdb.MaxUnavailable == nil) {
+			errs = append(errs, field.Invalid(path, pdb, "exactly one of minAvailable or maxUnavailable needs to be set"))
+		}
+	}
+
+	for i, block := range c.ServerBlocks {
+		path := path.Child("serverBlocks").Index(i)
+		if strings.TrimSpace(block) == "" {
+			errs = append(errs, field.Required(path, "server block must not be empty"))
+		} else if strings.Count(block, "{") != strings.Count(block, "}") {
+			errs = append(errs, field.Invalid(path, block, "unbalanced braces"))
+		}
+	}
+
+	return
+}
+
+// Validate validates the replica settings.
+func (r *CoreDNSReplicas) Validate(path *field.Path) (errs field.ErrorList) {
+	if r == nil {
+		return
+	}
+
+	if r.Count != nil && *r.Count < 0 {
+		errs = append(errs, field.Invalid(path.Child("count"), *r.Count, "must not be negative"))
+	}
+	for _, f := range []struct {
+		name  string
+		value int32
+	}{{"nodesPerReplica", r.NodesPerReplica}, {"min", r.Min}, {"max", r.Max}} {
+		if f.value < 0 {
+			errs = append(errs, field.Invalid(path.Child(f.name), f.value, "must not be negative"))
+		}
+	}
+	if r.Max > 0 && r.Max < r.Min {
+		errs = append(errs, field.Invalid(path.Child("max"), r.Max, "must not be less than min"))
+	}
+
+	return
+}
+
+// Validate validates the rewrite rule.
+func (r *CoreDNSRewrite) Validate(path *field.Path) (errs field.ErrorList) {
+	switch r.Match {
+	case "", CoreDNSRewriteMatchExact, CoreDNSRewriteMatchPrefix, CoreDNSRewriteMatchSuffix, CoreDNSRewriteMatchSubstring:
+	case CoreDNSRewriteMatchRegex:
+		if _, err := regexp.Compile(r.From); err != nil {
+			errs = append(errs, field.Invalid(path.Child("from"), r.From, err.Error()))
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("match"), r.Match, []string{
+			string(CoreDNSRewriteMatchExact),
+			string(CoreDNSRewriteMatchPrefix),
+			string(CoreDNSRewriteMatchSuffix),
+			string(CoreDNSRewriteMatchSubstring),
+			string(CoreDNSRewriteMatchRegex),
+		}))
+	}
+
+	for _, f := range []struct{ name, value string }{{"from", r.From}, {"to", r.To}} {
+		if f.value == "" {
+			errs = append(errs, field.Required(path.Child(f.name), ""))
+		} else if !isCorefileToken(f.value) {
+			errs = append(errs, field.Invalid(path.Child(f.name), f.value, "must be a single token without quotes or comments"))
+		}
+	}
+
+	return
+}
+
+// MatchOrDefault returns the way names are matched, defaulting to exact.
+func (r *CoreDNSRewrite) MatchOrDefault() CoreDNSRewriteMatch {
+	if r.Match == "" {
+		return CoreDNSRewriteMatchExact
+	}
+	return r.Match
+}
+
+// validateDNSResolver validates that the resolver is an IP address with an
+// optional port.
+func validateDNSResolver(path *field.Path, resolver string) (errs field.ErrorList) {
+	host := resolver
+	if h, port, err := net.SplitHostPort(resolver); err == nil {
+		host = h
+		portNum, err := strconv.Atoi(port)
+		if err != nil || len(validation.IsValidPortNum(portNum)) > 0 {
+			errs = append(errs, field.Invalid(path, resolver, "invalid port"))
+		}
+	}
+
+	if net.ParseIP(host) == nil {
+		errs = append(errs, field.Invalid(path, resolver, "must be an IP address with an optional port"))
+	}
+
+	return
+}
+
+// isCorefileToken checks if the value can be used as a single Corefile token.
+// Braces are only special if they form a token on their own.
+func isCorefileToken(value string) bool {
+	return value != "{" && value != "}" && !strings.ContainsAny(value, " \t\r\n\"'#")
+}
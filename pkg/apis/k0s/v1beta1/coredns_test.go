@@ -0,0 +1,159 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+func TestCoreDNS_Unmarshal(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  network:
+    coreDNS:
+      upstreamResolvers: [1.1.1.1, "[2606:4700:4700::1111]:53"]
+      stubDomains:
+        corp.example.com: [10.0.0.10]
+      rewrites:
+      - from: db.example.com
+        to: db.default.svc.cluster.local
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Nil(t, c.Validate())
+
+	coreDNS := c.Spec.Network.CoreDNS
+	require.NotNil(t, coreDNS)
+	assert.Equal(t, []string{"1.1.1.1", "[2606:4700:4700::1111]:53"}, coreDNS.UpstreamResolvers)
+	assert.Equal(t, map[string][]string{"corp.example.com": {"10.0.0.10"}}, coreDNS.StubDomains)
+	if assert.Len(t, coreDNS.Rewrites, 1) {
+		assert.Equal(t, CoreDNSRewriteMatchExact, coreDNS.Rewrites[0].MatchOrDefault())
+	}
+}
+
+func TestCoreDNS_Validate(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		coreDNS *CoreDNS
+		errs    []string
+	}{
+		{"nil", nil, nil},
+		{"empty", &CoreDNS{}, nil},
+		{
+			"invalid_upstream_resolvers",
+			&CoreDNS{UpstreamResolvers: []string{"1.1.1.1:53", "dns.example.com", "1.1.1.1:99999"}},
+			[]string{
+				`coreDNS.upstreamResolvers[1]: Invalid value: "dns.example.com": must be an IP address with an optional port`,
+				`coreDNS.upstreamResolvers[2]: Invalid value: "1.1.1.1:99999": invalid port`,
+			},
+		},
+		{
+			"invalid_stub_domains",
+			&CoreDNS{StubDomains: map[string][]string{"-invalid-": {"10.0.0.10"}, "example.com": nil}},
+			[]string{
+				`coreDNS.stubDomains[-invalid-]: Invalid value: "-invalid-": invalid DNS name`,
+				`coreDNS.stubDomains[example.com]: Required value: at least one resolver is required`,
+			},
+		},
+		{
+			"invalid_rewrites",
+			&CoreDNS{Rewrites: []CoreDNSRewrite{
+				{Match: "glob", From: "a.example.com", To: "b.example.com"},
+				{From: "a example.com"},
+				{Match: CoreDNSRewriteMatchRegex, From: "(.*", To: "{1}.example.com"},
+				{From: "a.example.com", To: "{"},
+			}},
+			[]string{
+				`coreDNS.rewrites[0].match: Unsupported value: "glob"`,
+				`coreDNS.rewrites[1].from: Invalid value: "a example.com": must be a single token without quotes or comments`,
+				`coreDNS.rewrites[1].to: Required value`,
+				`coreDNS.rewrites[2].from: Invalid value: "(.*": error parsing regexp`,
+				`coreDNS.rewrites[3].to: Invalid value: "{": must be a single token without quotes or comments`,
+			},
+		},
+		{
+			"invalid_server_blocks",
+			&CoreDNS{ServerBlocks: []string{" ", "example.net {\n  whoami\n"}},
+			[]string{
+				`coreDNS.serverBlocks[0]: Required value: server block must not be empty`,
+				`coreDNS.serverBlocks[1]: Invalid value: "example.net {\n  whoami\n": unbalanced braces`,
+			},
+		},
+		{
+			"invalid_replicas",
+			&CoreDNS{Replicas: &CoreDNSReplicas{Count: pointer.Int32(-1), NodesPerReplica: -1, Min: 3, Max: 2}},
+			[]string{
+				`coreDNS.replicas.count: Invalid value: -1: must not be negative`,
+				`coreDNS.replicas.nodesPerReplica: Invalid value: -1: must not be negative`,
+				`coreDNS.replicas.max: Invalid value: 2: must not be less than min`,
+			},
+		},
+		{
+			"invalid_topology_spread_constraints",
+			&CoreDNS{TopologySpreadConstraints: []corev1.TopologySpreadConstraint{
+				{MaxSkew: 1, TopologyKey: "topology.kubernetes.io/zone", WhenUnsatisfiable: corev1.DoNotSchedule},
+				{WhenUnsatisfiable: "Sometimes"},
+			}},
+			[]string{
+				`coreDNS.topologySpreadConstraints[1].maxSkew: Invalid value: 0: must be greater than zero`,
+				`coreDNS.topologySpreadConstraints[1].topologyKey: Required value`,
+				`coreDNS.topologySpreadConstraints[1].whenUnsatisfiable: Unsupported value: "Sometimes"`,
+			},
+		},
+		{
+			"pod_disruption_budget_without_values",
+			&CoreDNS{PodDisruptionBudget: &CoreDNSPodDisruptionBudget{}},
+			[]string{`coreDNS.podDisruptionBudget: Invalid value: `},
+		},
+		{
+			"pod_disruption_budget_with_both_values",
+			&CoreDNS{PodDisruptionBudget: &CoreDNSPodDisruptionBudget{
+				MinAvailable:   &intstr.IntOrString{IntVal: 1},
+				MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+			}},
+			[]string{`exactly one of minAvailable or maxUnavailable needs to be set`},
+		},
+		{
+			"valid_pod_disruption_budget",
+			&CoreDNS{PodDisruptionBudget: &CoreDNSPodDisruptionBudget{
+				MaxUnavailable: &intstr.IntOrString{Type: intstr.String, StrVal: "50%"},
+			}},
+			nil,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			errs := test.coreDNS.Validate(field.NewPath("coreDNS"))
+			if assert.Len(t, errs, len(test.errs)) {
+				for i, err := range errs {
+					assert.ErrorContains(t, err, test.errs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/yaml"
+)
+
+// Encryption defines the settings for encrypting resources at rest
+type Encryption struct {
+	// The resources to encrypt (default: [secrets])
+	Resources []string `json:"resources,omitempty"`
+	// The KMS plugin that encrypts the resources
+	KMS *KMS `json:"kms"`
+}
+
+// KMS defines the settings of a KMS plugin
+type KMS struct {
+	// The name of the KMS plugin
+	Name string `json:"name"`
+	// Path to the unix socket the KMS plugin listens on
+	Socket string `json:"socket"`
+	// The version of the KMS API: v1 or v2 (default: v2)
+	APIVersion string `json:"apiVersion,omitempty"`
+	// The timeout for calls to the KMS plugin (default: 3s)
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+const (
+	// KMSAPIVersionV1 is version 1 of the KMS API.
+	KMSAPIVersionV1 = "v1"
+	// KMSAPIVersionV2 is version 2 of the KMS API.
+	KMSAPIVersionV2 = "v2"
+)
+
+// GetResources returns the resources to encrypt.
+func (e *Encryption) GetResources() []string {
+	if len(e.Resources) == 0 {
+		return []string{"secrets"}
+	}
+	return e.Resources
+}
+
+// GetAPIVersion returns the version of the KMS API.
+func (k *KMS) GetAPIVersion() string {
+	if k.APIVersion == "" {
+		return KMSAPIVersionV2
+	}
+	return k.APIVersion
+}
+
+// GetTimeout returns the timeout for calls to the KMS plugin.
+func (k *KMS) GetTimeout() time.Duration {
+	if k.Timeout == nil {
+		return 3 * time.Second
+	}
+	return k.Timeout.Duration
+}
+
+// Validate validates the Encryption struct
+func (e *Encryption) Validate(path *field.Path) []error {
+	if e == nil {
+		return nil
+	}
+
+	var errors []error
+
+	for i, resource := range e.Resources {
+		if resource == "" {
+			errors = append(errors, field.Invalid(path.Child("resources").Index(i), resource, "must not be empty"))
+		}
+	}
+
+	if e.KMS == nil {
+		return append(errors, field.Required(path.Child("kms"), ""))
+	}
+
+	path = path.Child("kms")
+	if e.KMS.Name == "" {
+		errors = append(errors, field.Required(path.Child("name"), ""))
+	}
+	if e.KMS.Socket == "" {
+		errors = append(errors, field.Required(path.Child("socket"), ""))
+	} else if !filepath.IsAbs(e.KMS.Socket) {
+		errors = append(errors, field.Invalid(path.Child("socket"), e.KMS.Socket, "must be an absolute path"))
+	}
+	switch e.KMS.APIVersion {
+	case "", KMSAPIVersionV1, KMSAPIVersionV2:
+	default:
+		errors = append(errors, field.NotSupported(path.Child("apiVersion"), e.KMS.APIVersion, []string{KMSAPIVersionV1, KMSAPIVersionV2}))
+	}
+	if e.KMS.Timeout != nil && e.KMS.Timeout.Duration <= 0 {
+		errors = append(errors, field.Invalid(path.Child("timeout"), e.KMS.Timeout.String(), "must be positive"))
+	}
+
+	return errors
+}
+
+// BuildArgs adds the kube-apiserver flags for this encryption configuration to
+// args. The EncryptionConfiguration is expected to have been written to the
+// given file.
+func (e *Encryption) BuildArgs(args stringmap.StringMap, configFile string) stringmap.StringMap {
+	if e == nil {
+		return args
+	}
+
+	args["encryption-provider-config"] = configFile
+	return args
+}
+
+// EncryptionConfiguration renders the EncryptionConfiguration for
+// kube-apiserver. Resources that have been stored before encryption was
+// enabled remain readable via the identity provider, until they're rewritten.
+func (e *Encryption) EncryptionConfiguration() ([]byte, error) {
+	type kmsConfig struct {
+		APIVersion string `json:"apiVersion"`
+		Name       string `json:"name"`
+		Endpoint   string `json:"endpoint"`
+		Timeout    string `json:"timeout"`
+	}
+	type provider struct {
+		KMS      *kmsConfig `json:"kms,omitempty"`
+		Identity *struct{}  `json:"identity,omitempty"`
+	}
+	type resourceConfig struct {
+		Resources []string   `json:"resources"`
+		Providers []provider `json:"providers"`
+	}
+
+	return yaml.Marshal(&struct {
+		APIVersion string           `json:"apiVersion"`
+		Kind       string           `json:"kind"`
+		Resources  []resourceConfig `json:"resources"`
+	}{
+		APIVersion: "apiserver.config.k8s.io/v1",
+		Kind:       "EncryptionConfiguration",
+		Resources: []resourceConfig{{
+			Resources: e.GetResources(),
+			Providers: []provider{
+				{KMS: &kmsConfig{
+					APIVersion: e.KMS.GetAPIVersion(),
+					Name:       e.KMS.Name,
+					Endpoint:   "unix://" + e.KMS.Socket,
+					Timeout:    e.KMS.GetTimeout().String(),
+				}},
+				{Identity: &struct{}{}},
+			},
+		}},
+	})
+}
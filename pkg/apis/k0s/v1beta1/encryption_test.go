@@ -0,0 +1,113 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryption_FromConfig(t *testing.T) {
+	c, err := ConfigFromString(`
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+spec:
+  api:
+    encryption:
+      resources: [secrets, configmaps]
+      kms:
+        name: vault
+        socket: /run/kms/vault.sock
+        timeout: 10s
+`)
+	require.NoError(t, err)
+	encryption := c.Spec.API.Encryption
+	assert.Empty(t, encryption.Validate(field.NewPath("encryption")))
+
+	args := encryption.BuildArgs(stringmap.StringMap{}, "/var/lib/k0s/encryption-config.yaml")
+	assert.Equal(t, stringmap.StringMap{
+		"encryption-provider-config": "/var/lib/k0s/encryption-config.yaml",
+	}, args)
+
+	config, err := encryption.EncryptionConfiguration()
+	require.NoError(t, err)
+	assert.Equal(t, `apiVersion: apiserver.config.k8s.io/v1
+kind: EncryptionConfiguration
+resources:
+- providers:
+  - kms:
+      apiVersion: v2
+      endpoint: unix:///run/kms/vault.sock
+      name: vault
+      timeout: 10s
+  - identity: {}
+  resources:
+  - secrets
+  - configmaps
+`, string(config))
+}
+
+func TestEncryption_Defaults(t *testing.T) {
+	encryption := &Encryption{KMS: &KMS{Name: "vault", Socket: "/run/kms/vault.sock"}}
+	assert.Equal(t, []string{"secrets"}, encryption.GetResources())
+	assert.Equal(t, KMSAPIVersionV2, encryption.KMS.GetAPIVersion())
+	assert.Equal(t, "3s", encryption.KMS.GetTimeout().String())
+
+	var nilEncryption *Encryption
+	assert.Equal(t, stringmap.StringMap{}, nilEncryption.BuildArgs(stringmap.StringMap{}, "ignored"))
+}
+
+func TestEncryption_Validate(t *testing.T) {
+	kms := func(modify func(*KMS)) *Encryption {
+		kms := &KMS{Name: "vault", Socket: "/run/kms/vault.sock"}
+		modify(kms)
+		return &Encryption{KMS: kms}
+	}
+
+	for _, test := range []struct {
+		name       string
+		encryption *Encryption
+		fields     []string
+	}{
+		{"nil", nil, nil},
+		{"valid", kms(func(*KMS) {}), nil},
+		{"no_kms", &Encryption{}, []string{"encryption.kms"}},
+		{"empty_resource", &Encryption{Resources: []string{""}, KMS: &KMS{Name: "a", Socket: "/a"}}, []string{"encryption.resources[0]"}},
+		{"no_name", kms(func(k *KMS) { k.Name = "" }), []string{"encryption.kms.name"}},
+		{"no_socket", kms(func(k *KMS) { k.Socket = "" }), []string{"encryption.kms.socket"}},
+		{"relative_socket", kms(func(k *KMS) { k.Socket = "kms.sock" }), []string{"encryption.kms.socket"}},
+		{"v1", kms(func(k *KMS) { k.APIVersion = "v1" }), nil},
+		{"unknown_version", kms(func(k *KMS) { k.APIVersion = "v3" }), []string{"encryption.kms.apiVersion"}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var fields []string
+			for _, err := range test.encryption.Validate(field.NewPath("encryption")) {
+				var fieldErr *field.Error
+				if assert.ErrorAs(t, err, &fieldErr) {
+					fields = append(fields, fieldErr.Field)
+				}
+			}
+			assert.Equal(t, test.fields, fields)
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ Validateable = (*EventForwardingSpec)(nil)
+
+// EventForwardingSpec defines where the Kubernetes events that are reported by
+// k0s are forwarded to
+type EventForwardingSpec struct {
+	// Types of the events that are forwarded, Normal and/or Warning (default:
+	// both)
+	// +optional
+	Types []string `json:"types,omitempty"`
+
+	// Sinks to which the events are forwarded
+	// +optional
+	Sinks []EventSink `json:"sinks,omitempty"`
+}
+
+// EventSink defines a destination for forwarded events. Exactly one of
+// webhook, syslog or slack has to be set.
+type EventSink struct {
+	// Name of the sink, used to refer to it in logs
+	Name string `json:"name"`
+
+	// Forwards events as JSON to an HTTP endpoint
+	// +optional
+	Webhook *WebhookEventSink `json:"webhook,omitempty"`
+
+	// Forwards events to a syslog server
+	// +optional
+	Syslog *SyslogEventSink `json:"syslog,omitempty"`
+
+	// Forwards events to a Slack channel
+	// +optional
+	Slack *SlackEventSink `json:"slack,omitempty"`
+}
+
+// WebhookEventSink forwards events as JSON to an HTTP endpoint
+type WebhookEventSink struct {
+	// URL to which the events are POSTed
+	URL string `json:"url"`
+
+	// Additional HTTP headers that are sent along, e.g. for authentication
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+// SyslogEventSink forwards events to a syslog server
+type SyslogEventSink struct {
+	// Address of the syslog server, e.g. udp://syslog.example.com:514 or
+	// tcp://syslog.example.com:601
+	Address string `json:"address"`
+}
+
+// SlackEventSink forwards events to a Slack channel
+type SlackEventSink struct {
+	// URL of a Slack incoming webhook
+	WebhookURL string `json:"webhookURL"`
+}
+
+// IsEnabled checks if any sinks are configured.
+func (e *EventForwardingSpec) IsEnabled() bool {
+	return e != nil && len(e.Sinks) > 0
+}
+
+// Includes checks if events of the given type are forwarded.
+func (e *EventForwardingSpec) Includes(eventType string) bool {
+	return len(e.Types) == 0 || slices.Contains(e.Types, eventType)
+}
+
+// Validate implements [Validateable].
+func (e *EventForwardingSpec) Validate() (errs []error) {
+	if e == nil {
+		return nil
+	}
+
+	for i, t := range e.Types {
+		if t != corev1.EventTypeNormal && t != corev1.EventTypeWarning {
+			errs = append(errs, field.NotSupported(field.NewPath("types").Index(i), t, []string{corev1.EventTypeNormal, corev1.EventTypeWarning}))
+		}
+	}
+
+	names := make(map[string]bool, len(e.Sinks))
+	for i, sink := range e.Sinks {
+		path := field.NewPath("sinks").Index(i)
+		if sink.Name == "" {
+			errs = append(errs, field.Required(path.Child("name"), ""))
+		} else if names[sink.Name] {
+			errs = append(errs, field.Duplicate(path.Child("name"), sink.Name))
+		}
+		names[sink.Name] = true
+		errs = append(errs, sink.validate(path)...)
+	}
+
+	return errs
+}
+
+func (s *EventSink) validate(path *field.Path) (errs []error) {
+	var kinds int
+	if s.Webhook != nil {
+		kinds++
+		if err := validateURL(s.Webhook.URL, "http", "https"); err != nil {
+			errs = append(errs, field.Invalid(path.Child("webhook", "url"), s.Webhook.URL, err.Error()))
+		}
+	}
+	if s.Syslog != nil {
+		kinds++
+		if err := validateURL(s.Syslog.Address, "udp", "tcp"); err != nil {
+			errs = append(errs, field.Invalid(path.Child("syslog", "address"), s.Syslog.Address, err.Error()))
+		}
+	}
+	if s.Slack != nil {
+		kinds++
+		if err := validateURL(s.Slack.WebhookURL, "https"); err != nil {
+			errs = append(errs, field.Invalid(path.Child("slack", "webhookURL"), s.Slack.WebhookURL, err.Error()))
+		}
+	}
+	if kinds != 1 {
+		errs = append(errs, field.Invalid(path, s.Name, "exactly one of webhook, syslog or slack has to be set"))
+	}
+	return errs
+}
+
+func validateURL(rawURL string, schemes ...string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(schemes, u.Scheme) {
+		return fmt.Errorf("unsupported scheme %q, expected one of %v", u.Scheme, schemes)
+	}
+	if u.Host == "" {
+		return errors.New("no host given")
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventForwardingSpec_Unmarshal(t *testing.T) {
+	c, err := ConfigFromString(`
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+spec:
+  eventForwarding:
+    types: [Warning]
+    sinks:
+    - name: ops
+      slack:
+        webhookURL: https://hooks.slack.com/services/T000/B000/XXXX
+`)
+	require.NoError(t, err)
+	assert.Empty(t, c.Validate())
+	require.True(t, c.Spec.EventForwarding.IsEnabled())
+	assert.True(t, c.Spec.EventForwarding.Includes("Warning"))
+	assert.False(t, c.Spec.EventForwarding.Includes("Normal"))
+	assert.Equal(t, "https://hooks.slack.com/services/T000/B000/XXXX", c.Spec.EventForwarding.Sinks[0].Slack.WebhookURL)
+
+	assert.False(t, (*EventForwardingSpec)(nil).IsEnabled())
+	assert.True(t, (&EventForwardingSpec{}).Includes("Normal"))
+}
+
+func TestEventForwardingSpec_Validate(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		spec EventForwardingSpec
+		errs []string
+	}{
+		{"empty", EventForwardingSpec{}, nil},
+		{
+			"valid",
+			EventForwardingSpec{
+				Types: []string{"Normal", "Warning"},
+				Sinks: []EventSink{
+					{Name: "webhook", Webhook: &WebhookEventSink{URL: "http://example.com/events"}},
+					{Name: "syslog", Syslog: &SyslogEventSink{Address: "tcp://syslog.example.com:601"}},
+				},
+			},
+			nil,
+		},
+		{
+			"unsupported_type",
+			EventForwardingSpec{Types: []string{"Error"}},
+			[]string{`types[0]: Unsupported value: "Error": supported values: "Normal", "Warning"`},
+		},
+		{
+			"names",
+			EventForwardingSpec{Sinks: []EventSink{
+				{Syslog: &SyslogEventSink{Address: "udp://localhost:514"}},
+				{Name: "a", Syslog: &SyslogEventSink{Address: "udp://localhost:514"}},
+				{Name: "a", Syslog: &SyslogEventSink{Address: "udp://localhost:514"}},
+			}},
+			[]string{`sinks[0].name: Required value`, `sinks[2].name: Duplicate value: "a"`},
+		},
+		{
+			"kinds",
+			EventForwardingSpec{Sinks: []EventSink{
+				{Name: "none"},
+				{
+					Name:    "both",
+					Webhook: &WebhookEventSink{URL: "https://example.com"},
+					Slack:   &SlackEventSink{WebhookURL: "https://hooks.slack.com/services/T000/B000/XXXX"},
+				},
+			}},
+			[]string{
+				`sinks[0]: Invalid value: "none": exactly one of webhook, syslog or slack has to be set`,
+				`sinks[1]: Invalid value: "both": exactly one of webhook, syslog or slack has to be set`,
+			},
+		},
+		{
+			"urls",
+			EventForwardingSpec{Sinks: []EventSink{
+				{Name: "webhook", Webhook: &WebhookEventSink{URL: "ftp://example.com"}},
+				{Name: "syslog", Syslog: &SyslogEventSink{Address: "syslog.example.com:514"}},
+				{Name: "slack", Slack: &SlackEventSink{WebhookURL: "http://hooks.slack.com/services"}},
+				{Name: "nohost", Webhook: &WebhookEventSink{URL: "https:///events"}},
+			}},
+			[]string{
+				`sinks[0].webhook.url: Invalid value: "ftp://example.com": unsupported scheme "ftp", expected one of [http https]`,
+				`sinks[1].syslog.address: Invalid value: "syslog.example.com:514": unsupported scheme "syslog.example.com", expected one of [udp tcp]`,
+				`sinks[2].slack.webhookURL: Invalid value: "http://hooks.slack.com/services": unsupported scheme "http", expected one of [https]`,
+				`sinks[3].webhook.url: Invalid value: "https:///events": no host given`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var errs []string
+			for _, err := range test.spec.Validate() {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, test.errs, errs)
+		})
+	}
+}
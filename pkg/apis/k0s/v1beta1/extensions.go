@@ -17,8 +17,10 @@ limitations under the License.
 package v1beta1
 
 import (
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"helm.sh/helm/v3/pkg/chartutil"
@@ -30,10 +32,19 @@ var _ Validateable = (*ClusterExtensions)(nil)
 type ClusterExtensions struct {
 	Storage *StorageExtension `json:"storage"`
 	Helm    *HelmExtensions   `json:"helm"`
+	// ingress defines the configuration options related to the bundled
+	// ingress controller.
+	// +optional
+	Ingress *IngressExtension `json:"ingress,omitempty"`
+	// cloudProvider defines the configuration options related to the
+	// external cloud controller manager.
+	// +optional
+	CloudProvider *CloudProviderExtension `json:"cloudProvider,omitempty"`
 }
 
 // HelmExtensions specifies settings for cluster helm based extensions
 type HelmExtensions struct {
+	// Number of charts that are reconciled concurrently (default 5)
 	ConcurrencyLevel int                  `json:"concurrencyLevel"`
 	Repositories     RepositoriesSettings `json:"repositories"`
 	Charts           ChartsSettings       `json:"charts"`
@@ -67,15 +78,64 @@ func (cs ChartsSettings) Validate() []error {
 			errs = append(errs, err)
 		}
 	}
+	if err := cs.validateDependencies(); err != nil {
+		errs = append(errs, err)
+	}
 	if len(errs) > 0 {
 		return errs
 	}
 	return nil
 }
 
+// validateDependencies checks that the charts don't depend on each other in
+// a cycle.
+func (cs ChartsSettings) validateDependencies() error {
+	dependencies := make(map[string][]string, len(cs))
+	for _, c := range cs {
+		for _, d := range c.DependsOn {
+			if d.Chart != "" {
+				dependencies[c.Name] = append(dependencies[c.Name], d.Chart)
+			}
+		}
+	}
+
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int, len(cs))
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("charts have cyclic dependencies: %s", strings.Join(append(path, name), " -> "))
+		case visited:
+			return nil
+		}
+		state[name] = visiting
+		for _, dependency := range dependencies[name] {
+			if err := visit(dependency, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		return nil
+	}
+
+	for _, c := range cs {
+		if err := visit(c.Name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // Validate performs validation
 func (he HelmExtensions) Validate() []error {
 	var errs []error
+	if he.ConcurrencyLevel < 0 {
+		errs = append(errs, errors.New("concurrencyLevel must not be negative"))
+	}
 	if rErrs := he.Repositories.Validate(); rErrs != nil {
 		errs = append(errs, rErrs...)
 	}
@@ -97,6 +157,58 @@ type Chart struct {
 	TargetNS  string        `json:"namespace"`
 	Timeout   time.Duration `json:"timeout"`
 	Order     int           `json:"order"`
+	// Roll back the release if the install or upgrade fails (default true)
+	// +optional
+	Atomic *bool `json:"atomic,omitempty"`
+	// Wait until all resources of the release are ready before marking it as
+	// successful (default true)
+	// +optional
+	Wait *bool `json:"wait,omitempty"`
+	// Don't install the CRDs of the chart
+	// +optional
+	SkipCRDs bool `json:"skipCRDs,omitempty"`
+	// Don't run the hooks of the chart
+	// +optional
+	DisableHooks bool `json:"disableHooks,omitempty"`
+	// How to handle live resources of the release that differ from the
+	// rendered chart (default Ignore)
+	// +kubebuilder:validation:Enum=Ignore;Report;Remediate
+	// +optional
+	DriftPolicy string `json:"driftPolicy,omitempty"`
+	// Dependencies that need to be ready before the chart is installed or
+	// upgraded
+	// +optional
+	DependsOn []ChartDependency `json:"dependsOn,omitempty"`
+}
+
+// ChartDependency is a dependency of a chart. Exactly one of its fields is
+// set.
+type ChartDependency struct {
+	// Name of another chart that needs to be installed successfully
+	// +optional
+	Chart string `json:"chart,omitempty"`
+	// Built-in component that needs to be ready. The only supported component
+	// is "network", which is ready as soon as any node is ready, i.e. the
+	// network provider is up.
+	// +kubebuilder:validation:Enum=network
+	// +optional
+	Component string `json:"component,omitempty"`
+}
+
+// Validate performs validation
+func (d ChartDependency) Validate() error {
+	switch {
+	case d.Chart != "" && d.Component != "":
+		return errors.New("chart dependency must not have both Chart and Component set")
+	case d.Chart != "":
+		return nil
+	case d.Component == "network":
+		return nil
+	case d.Component != "":
+		return fmt.Errorf("chart dependency has unsupported Component %q", d.Component)
+	default:
+		return errors.New("chart dependency must have either Chart or Component set")
+	}
 }
 
 // ManifestFileName returns filename to use for the crd manifest
@@ -118,6 +230,19 @@ func (c Chart) Validate() error {
 	if c.TargetNS == "" {
 		return errors.New("chart must have TargetNS field not empty")
 	}
+	switch c.DriftPolicy {
+	case "", "Ignore", "Report", "Remediate":
+	default:
+		return fmt.Errorf("chart has unsupported DriftPolicy %q", c.DriftPolicy)
+	}
+	for _, d := range c.DependsOn {
+		if err := d.Validate(); err != nil {
+			return err
+		}
+		if d.Chart == c.Name {
+			return fmt.Errorf("chart %q must not depend on itself", c.Name)
+		}
+	}
 	return nil
 }
 
@@ -131,6 +256,15 @@ type Repository struct {
 	KeyFile  string `json:"keyfile"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// Bearer token used to authenticate against the repository
+	Token string `json:"token,omitempty"`
+	// PEM encoded CA bundle used to verify the repository's TLS certificate
+	CABundle string `json:"caBundle,omitempty"`
+}
+
+// HasCredentials checks if any of the repository's secret fields are set.
+func (r Repository) HasCredentials() bool {
+	return r.Username != "" || r.Password != "" || r.Token != "" || r.CABundle != ""
 }
 
 // Validate performs validation
@@ -141,6 +275,14 @@ func (r Repository) Validate() error {
 	if r.URL == "" {
 		return errors.New("repository must have URL field not empty")
 	}
+	if r.Token != "" && (r.Username != "" || r.Password != "") {
+		return fmt.Errorf("repository %q must not have both Token and Username/Password set", r.Name)
+	}
+	if r.CABundle != "" {
+		if block, _ := pem.Decode([]byte(r.CABundle)); block == nil {
+			return fmt.Errorf("repository %q has an invalid CABundle: no PEM data found", r.Name)
+		}
+	}
 	return nil
 }
 
@@ -156,6 +298,8 @@ func (e *ClusterExtensions) Validate() []error {
 	if e.Storage != nil {
 		errs = append(errs, e.Storage.Validate()...)
 	}
+	errs = append(errs, e.Ingress.Validate()...)
+	errs = append(errs, e.CloudProvider.Validate()...)
 	return errs
 }
 
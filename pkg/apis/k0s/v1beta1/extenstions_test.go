@@ -56,6 +56,61 @@ func TestValidation(t *testing.T) {
 			}
 			assert.NoError(t, chart.Validate())
 		})
+		t.Run("drift_policy", func(t *testing.T) {
+			chart := Chart{
+				Name:        "release",
+				ChartName:   "k0s/chart",
+				TargetNS:    "default",
+				DriftPolicy: "Remediate",
+			}
+			assert.NoError(t, chart.Validate())
+			chart.DriftPolicy = "Sometimes"
+			assert.Error(t, chart.Validate())
+		})
+	})
+
+	t.Run("dependencies_validation", func(t *testing.T) {
+		newChart := func(name string, dependsOn ...ChartDependency) Chart {
+			return Chart{Name: name, ChartName: "k0s/chart", TargetNS: "default", DependsOn: dependsOn}
+		}
+
+		t.Run("valid", func(t *testing.T) {
+			charts := ChartsSettings{
+				newChart("a", ChartDependency{Chart: "b"}, ChartDependency{Component: "network"}),
+				newChart("b", ChartDependency{Chart: "c"}),
+				newChart("c"),
+			}
+			assert.Nil(t, charts.Validate())
+		})
+		t.Run("invalid_dependency", func(t *testing.T) {
+			for _, dependency := range []ChartDependency{
+				{},
+				{Chart: "b", Component: "network"},
+				{Component: "dns"},
+			} {
+				assert.Error(t, newChart("a", dependency).Validate(), "%+v", dependency)
+			}
+		})
+		t.Run("self_dependency", func(t *testing.T) {
+			assert.Error(t, newChart("a", ChartDependency{Chart: "a"}).Validate())
+		})
+		t.Run("cycle", func(t *testing.T) {
+			charts := ChartsSettings{
+				newChart("a", ChartDependency{Chart: "b"}),
+				newChart("b", ChartDependency{Chart: "c"}),
+				newChart("c", ChartDependency{Chart: "a"}),
+			}
+			errs := charts.Validate()
+			if assert.Len(t, errs, 1) {
+				assert.ErrorContains(t, errs[0], "a -> b -> c -> a")
+			}
+		})
+	})
+
+	t.Run("concurrency_level_validation", func(t *testing.T) {
+		assert.Nil(t, HelmExtensions{ConcurrencyLevel: 0}.Validate())
+		assert.Nil(t, HelmExtensions{ConcurrencyLevel: 20}.Validate())
+		assert.Len(t, HelmExtensions{ConcurrencyLevel: -1}.Validate(), 1)
 	})
 
 	t.Run("repository_validation", func(t *testing.T) {
@@ -79,7 +134,32 @@ func TestValidation(t *testing.T) {
 			}
 			assert.NoError(t, repo.Validate())
 		})
-
+		t.Run("token_and_basic_auth", func(t *testing.T) {
+			repo := Repository{
+				Name:     "repo",
+				URL:      "https://charts.example.com",
+				Username: "user",
+				Token:    "token",
+			}
+			assert.ErrorContains(t, repo.Validate(), "must not have both Token and Username/Password set")
+		})
+		t.Run("invalid_ca_bundle", func(t *testing.T) {
+			repo := Repository{
+				Name:     "repo",
+				URL:      "https://charts.example.com",
+				CABundle: "not a certificate",
+			}
+			assert.ErrorContains(t, repo.Validate(), "invalid CABundle")
+		})
+		t.Run("valid_ca_bundle", func(t *testing.T) {
+			repo := Repository{
+				Name:     "repo",
+				URL:      "https://charts.example.com",
+				Token:    "token",
+				CABundle: "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+			}
+			assert.NoError(t, repo.Validate())
+		})
 	})
 
 	t.Run("chart_manifest_name", func(t *testing.T) {
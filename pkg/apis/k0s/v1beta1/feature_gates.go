@@ -21,6 +21,8 @@ import (
 	"strings"
 
 	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+
+	"golang.org/x/exp/slices"
 )
 
 var _ Validateable = (*FeatureGates)(nil)
@@ -37,13 +39,18 @@ var KubernetesComponents = []string{
 // FeatureGates collection of feature gate specs
 type FeatureGates []FeatureGate
 
-// Validate validates all profiles
+// Validate validates all feature gates
 func (fgs FeatureGates) Validate() []error {
 	var errors []error
+	seen := make(map[string]bool, len(fgs))
 	for _, p := range fgs {
 		if err := p.Validate(); err != nil {
 			errors = append(errors, err)
 		}
+		if seen[p.Name] {
+			errors = append(errors, fmt.Errorf("duplicate feature gate %q", p.Name))
+		}
+		seen[p.Name] = true
 	}
 	return errors
 }
@@ -51,6 +58,9 @@ func (fgs FeatureGates) Validate() []error {
 // BuildArgs build cli args using the given args and component name
 func (fgs FeatureGates) BuildArgs(args stringmap.StringMap, component string) stringmap.StringMap {
 	componentFeatureGates := fgs.AsSliceOfStrings(component)
+	if len(componentFeatureGates) == 0 {
+		return args
+	}
 	fg, componentHasFeatureGates := args["feature-gates"]
 	featureGatesString := strings.Join(componentFeatureGates, ",")
 	if componentHasFeatureGates {
@@ -112,6 +122,34 @@ func (fg *FeatureGate) Validate() error {
 	if fg.Name == "" {
 		return fmt.Errorf("feature gate must have name")
 	}
+	for _, component := range fg.Components {
+		if !slices.Contains(KubernetesComponents, component) {
+			return fmt.Errorf("feature gate %q: unknown component %q, expected one of %s", fg.Name, component, strings.Join(KubernetesComponents, ", "))
+		}
+	}
+	// The feature gate is rendered for all components, not only for the
+	// ones it's enabled for.
+	for _, component := range KubernetesComponents {
+		if err := validateKubernetesFeatureGate(fg.Name, fg.EnabledFor(component)); err != nil {
+			if len(fg.Components) > 0 {
+				return fmt.Errorf("%w, but would be set to %t for %s", err, fg.EnabledFor(component), component)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// validateKubernetesFeatureGate checks if the given feature gate is known to
+// the bundled Kubernetes version, and if it may be set to the given value.
+func validateKubernetesFeatureGate(name string, enabled bool) error {
+	known, ok := kubernetesFeatureGates[name]
+	if !ok {
+		return fmt.Errorf("unknown feature gate %q", name)
+	}
+	if known.lockToDefault && enabled != known.defaultValue {
+		return fmt.Errorf("feature gate %q is locked to %t", name, known.defaultValue)
+	}
 	return nil
 }
 
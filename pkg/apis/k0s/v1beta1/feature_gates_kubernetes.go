@@ -0,0 +1,170 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// kubernetesFeatureGate describes a feature gate of the bundled Kubernetes
+// version.
+type kubernetesFeatureGate struct {
+	// Whether the feature is enabled by default
+	defaultValue bool
+	// Whether the feature gate can't be set to anything but its default
+	lockToDefault bool
+}
+
+// kubernetesFeatureGates are the feature gates known to the Kubernetes
+// components of the bundled Kubernetes version (v1.27). They're taken from
+// k8s.io/kubernetes/pkg/features and k8s.io/component-base/logs/api/v1, and
+// need to be updated along with the Kubernetes version.
+var kubernetesFeatureGates = map[string]kubernetesFeatureGate{
+	"AllAlpha": {},
+	"AllBeta":  {},
+
+	"APIListChunking":                                {defaultValue: true},
+	"APIPriorityAndFairness":                         {defaultValue: true},
+	"APIResponseCompression":                         {defaultValue: true},
+	"APISelfSubjectReview":                           {defaultValue: true},
+	"AdmissionWebhookMatchConditions":                {},
+	"AdvancedAuditing":                               {defaultValue: true, lockToDefault: true},
+	"AggregatedDiscoveryEndpoint":                    {defaultValue: true},
+	"AnyVolumeDataSource":                            {defaultValue: true},
+	"AppArmor":                                       {defaultValue: true},
+	"CPUManager":                                     {defaultValue: true, lockToDefault: true},
+	"CPUManagerPolicyAlphaOptions":                   {},
+	"CPUManagerPolicyBetaOptions":                    {defaultValue: true},
+	"CPUManagerPolicyOptions":                        {defaultValue: true},
+	"CSIMigrationAzureFile":                          {defaultValue: true, lockToDefault: true},
+	"CSIMigrationGCE":                                {defaultValue: true, lockToDefault: true},
+	"CSIMigrationPortworx":                           {},
+	"CSIMigrationRBD":                                {},
+	"CSIMigrationvSphere":                            {defaultValue: true, lockToDefault: true},
+	"CSINodeExpandSecret":                            {defaultValue: true},
+	"CSIStorageCapacity":                             {defaultValue: true, lockToDefault: true},
+	"CSIVolumeHealth":                                {},
+	"CloudControllerManagerWebhook":                  {},
+	"CloudDualStackNodeIPs":                          {},
+	"ClusterTrustBundle":                             {},
+	"ConsistentHTTPGetHandlers":                      {defaultValue: true},
+	"ContainerCheckpoint":                            {},
+	"ContextualLogging":                              {},
+	"CronJobTimeZone":                                {defaultValue: true, lockToDefault: true},
+	"CrossNamespaceVolumeDataSource":                 {},
+	"CustomCPUCFSQuotaPeriod":                        {},
+	"CustomResourceValidationExpressions":            {defaultValue: true},
+	"DelegateFSGroupToCSIDriver":                     {defaultValue: true, lockToDefault: true},
+	"DevicePlugins":                                  {defaultValue: true, lockToDefault: true},
+	"DisableAcceleratorUsageMetrics":                 {defaultValue: true, lockToDefault: true},
+	"DisableCloudProviders":                          {},
+	"DisableKubeletCloudCredentialProviders":         {},
+	"DownwardAPIHugePages":                           {defaultValue: true, lockToDefault: true},
+	"DryRun":                                         {defaultValue: true, lockToDefault: true},
+	"DynamicResourceAllocation":                      {},
+	"ElasticIndexedJob":                              {defaultValue: true},
+	"EndpointSliceTerminatingCondition":              {defaultValue: true, lockToDefault: true},
+	"EventedPLEG":                                    {},
+	"ExecProbeTimeout":                               {defaultValue: true},
+	"ExpandedDNSConfig":                              {defaultValue: true},
+	"ExperimentalHostUserNamespaceDefaulting":        {},
+	"GRPCContainerProbe":                             {defaultValue: true, lockToDefault: true},
+	"GracefulNodeShutdown":                           {defaultValue: true},
+	"GracefulNodeShutdownBasedOnPodPriority":         {defaultValue: true},
+	"HPAContainerMetrics":                            {defaultValue: true},
+	"HPAScaleToZero":                                 {},
+	"HonorPVReclaimPolicy":                           {},
+	"IPTablesOwnershipCleanup":                       {defaultValue: true},
+	"InPlacePodVerticalScaling":                      {},
+	"InTreePluginAWSUnregister":                      {},
+	"InTreePluginAzureDiskUnregister":                {},
+	"InTreePluginAzureFileUnregister":                {},
+	"InTreePluginGCEUnregister":                      {},
+	"InTreePluginOpenStackUnregister":                {},
+	"InTreePluginPortworxUnregister":                 {},
+	"InTreePluginRBDUnregister":                      {},
+	"InTreePluginvSphereUnregister":                  {},
+	"JobMutableNodeSchedulingDirectives":             {defaultValue: true, lockToDefault: true},
+	"JobPodFailurePolicy":                            {defaultValue: true},
+	"JobReadyPods":                                   {defaultValue: true},
+	"JobTrackingWithFinalizers":                      {defaultValue: true, lockToDefault: true},
+	"KubeletCredentialProviders":                     {defaultValue: true, lockToDefault: true},
+	"KubeletInUserNamespace":                         {},
+	"KubeletPodResources":                            {defaultValue: true},
+	"KubeletPodResourcesDynamicResources":            {},
+	"KubeletPodResourcesGet":                         {},
+	"KubeletPodResourcesGetAllocatable":              {defaultValue: true},
+	"KubeletTracing":                                 {defaultValue: true},
+	"LegacyServiceAccountTokenNoAutoGeneration":      {defaultValue: true, lockToDefault: true},
+	"LegacyServiceAccountTokenTracking":              {defaultValue: true},
+	"LocalStorageCapacityIsolationFSQuotaMonitoring": {},
+	"LogarithmicScaleDown":                           {defaultValue: true},
+	"LoggingAlphaOptions":                            {},
+	"LoggingBetaOptions":                             {defaultValue: true},
+	"MatchLabelKeysInPodTopologySpread":              {defaultValue: true},
+	"MaxUnavailableStatefulSet":                      {},
+	"MemoryManager":                                  {defaultValue: true},
+	"MemoryQoS":                                      {},
+	"MinDomainsInPodTopologySpread":                  {defaultValue: true},
+	"MinimizeIPTablesRestore":                        {defaultValue: true},
+	"MixedProtocolLBService":                         {defaultValue: true, lockToDefault: true},
+	"MultiCIDRRangeAllocator":                        {},
+	"MultiCIDRServiceAllocator":                      {},
+	"NetworkPolicyStatus":                            {},
+	"NewVolumeManagerReconstruction":                 {defaultValue: true},
+	"NodeInclusionPolicyInPodTopologySpread":         {defaultValue: true},
+	"NodeLogQuery":                                   {},
+	"NodeOutOfServiceVolumeDetach":                   {defaultValue: true},
+	"NodeSwap":                                       {},
+	"OpenAPIEnums":                                   {defaultValue: true},
+	"OpenAPIV3":                                      {defaultValue: true, lockToDefault: true},
+	"PDBUnhealthyPodEvictionPolicy":                  {defaultValue: true},
+	"PodAndContainerStatsFromCRI":                    {},
+	"PodDeletionCost":                                {defaultValue: true},
+	"PodDisruptionConditions":                        {defaultValue: true},
+	"PodHasNetworkCondition":                         {},
+	"PodSchedulingReadiness":                         {defaultValue: true},
+	"PodSecurity":                                    {defaultValue: true, lockToDefault: true},
+	"ProbeTerminationGracePeriod":                    {defaultValue: true},
+	"ProcMountType":                                  {},
+	"ProxyTerminatingEndpoints":                      {defaultValue: true},
+	"QOSReserved":                                    {},
+	"ReadWriteOncePod":                               {defaultValue: true},
+	"RecoverVolumeExpansionFailure":                  {},
+	"RetroactiveDefaultStorageClass":                 {defaultValue: true},
+	"RotateKubeletServerCertificate":                 {defaultValue: true},
+	"SELinuxMountReadWriteOncePod":                   {defaultValue: true},
+	"SeccompDefault":                                 {defaultValue: true, lockToDefault: true},
+	"SecurityContextDeny":                            {},
+	"ServerSideApply":                                {defaultValue: true, lockToDefault: true},
+	"ServerSideFieldValidation":                      {defaultValue: true, lockToDefault: true},
+	"ServiceIPStaticSubrange":                        {defaultValue: true, lockToDefault: true},
+	"ServiceInternalTrafficPolicy":                   {defaultValue: true, lockToDefault: true},
+	"ServiceNodePortStaticSubrange":                  {},
+	"SizeMemoryBackedVolumes":                        {defaultValue: true},
+	"StableLoadBalancerNodeSet":                      {defaultValue: true},
+	"StatefulSetAutoDeletePVC":                       {defaultValue: true},
+	"StatefulSetStartOrdinal":                        {defaultValue: true},
+	"TopologyAwareHints":                             {defaultValue: true},
+	"TopologyManager":                                {defaultValue: true, lockToDefault: true},
+	"TopologyManagerPolicyAlphaOptions":              {},
+	"TopologyManagerPolicyBetaOptions":               {},
+	"TopologyManagerPolicyOptions":                   {},
+	"UserNamespacesStatelessPodsSupport":             {},
+	"ValidatingAdmissionPolicy":                      {},
+	"VolumeCapacityPriority":                         {},
+	"WinDSR":                                         {},
+	"WinOverlay":                                     {defaultValue: true},
+	"WindowsHostNetwork":                             {defaultValue: true},
+	"WindowsHostProcessContainers":                   {defaultValue: true, lockToDefault: true},
+}
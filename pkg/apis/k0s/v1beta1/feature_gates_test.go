@@ -103,7 +103,7 @@ func TestArgsFeatureGates(t *testing.T) {
 
 	t.Run("feature_gate_validation", func(t *testing.T) {
 		validFeature := FeatureGate{
-			Name: "some_feature_gate",
+			Name: "InPlacePodVerticalScaling",
 		}
 
 		invalidFeatureGate := FeatureGate{}
@@ -112,6 +112,46 @@ func TestArgsFeatureGates(t *testing.T) {
 		require.Error(t, invalidFeatureGate.Validate())
 	})
 
+	t.Run("feature_gate_validation_against_kubernetes", func(t *testing.T) {
+		for _, test := range []struct {
+			name string
+			fg   FeatureGate
+			err  string
+		}{
+			{"known", FeatureGate{Name: "InPlacePodVerticalScaling", Enabled: true, Components: []string{"kubelet", "kube-apiserver"}}, ""},
+			{"unknown", FeatureGate{Name: "some_feature_gate", Enabled: true}, `unknown feature gate "some_feature_gate"`},
+			{"unknown_component", FeatureGate{Name: "InPlacePodVerticalScaling", Components: []string{"kube-api"}}, `feature gate "InPlacePodVerticalScaling": unknown component "kube-api"`},
+			{"locked_to_default", FeatureGate{Name: "CPUManager", Enabled: true}, ""},
+			{"locked_disabled", FeatureGate{Name: "CPUManager"}, `feature gate "CPUManager" is locked to true`},
+			{"locked_for_some_components", FeatureGate{Name: "CPUManager", Enabled: true, Components: []string{"kubelet"}}, `feature gate "CPUManager" is locked to true, but would be set to false for kube-apiserver`},
+		} {
+			t.Run(test.name, func(t *testing.T) {
+				err := test.fg.Validate()
+				if test.err == "" {
+					require.NoError(t, err)
+				} else {
+					require.ErrorContains(t, err, test.err)
+				}
+			})
+		}
+	})
+
+	t.Run("feature_gates_validation_duplicates", func(t *testing.T) {
+		featureGates := FeatureGates{
+			{Name: "InPlacePodVerticalScaling", Enabled: true},
+			{Name: "InPlacePodVerticalScaling", Enabled: false},
+		}
+		errs := featureGates.Validate()
+		require.Len(t, errs, 1)
+		require.ErrorContains(t, errs[0], `duplicate feature gate "InPlacePodVerticalScaling"`)
+	})
+
+	t.Run("no_feature_gates_keep_args", func(t *testing.T) {
+		args := FeatureGates{}.BuildArgs(stringmap.StringMap{"feature-gates": "Magic=true"}, "kubelet")
+		require.Equal(t, stringmap.StringMap{"feature-gates": "Magic=true"}, args)
+		require.Empty(t, FeatureGates{}.BuildArgs(stringmap.StringMap{}, "kubelet"))
+	})
+
 	t.Run("feature_gates_as_slice_of_strings", func(t *testing.T) {
 		featureGates := FeatureGates{
 			{
@@ -129,6 +129,9 @@ func (ci *ClusterImages) overrideImageRepositories() {
 	override(&ci.Calico.CNI)
 	override(&ci.Calico.Node)
 	override(&ci.Calico.KubeControllers)
+	override(&ci.Calico.CNIWindows)
+	override(&ci.Calico.NodeWindows)
+	override(&ci.Calico.KubeProxyWindows)
 	override(&ci.KubeRouter.CNI)
 	override(&ci.KubeRouter.CNIInstaller)
 }
@@ -138,6 +141,13 @@ type CalicoImageSpec struct {
 	CNI             ImageSpec `json:"cni"`
 	Node            ImageSpec `json:"node"`
 	KubeControllers ImageSpec `json:"kubecontrollers"`
+
+	// CNIWindows is the CNI installer image used on Windows nodes.
+	CNIWindows ImageSpec `json:"cniWindows"`
+	// NodeWindows is the calico-node image used on Windows nodes.
+	NodeWindows ImageSpec `json:"nodeWindows"`
+	// KubeProxyWindows is the kube-proxy image used on Windows nodes.
+	KubeProxyWindows ImageSpec `json:"kubeproxyWindows"`
 }
 
 // KubeRouterImageSpec config group for kube-router related images
@@ -183,6 +193,18 @@ func DefaultClusterImages() *ClusterImages {
 				Image:   constant.KubeControllerImage,
 				Version: constant.CalicoComponentImagesVersion,
 			},
+			CNIWindows: ImageSpec{
+				Image:   constant.CalicoCNIWindowsImage,
+				Version: constant.CalicoWindowsImagesVersion,
+			},
+			NodeWindows: ImageSpec{
+				Image:   constant.CalicoNodeWindowsImage,
+				Version: constant.CalicoWindowsImagesVersion,
+			},
+			KubeProxyWindows: ImageSpec{
+				Image:   constant.KubeProxyWindowsImage,
+				Version: constant.KubeProxyWindowsImageVersion,
+			},
 		},
 		KubeRouter: KubeRouterImageSpec{
 			CNI: ImageSpec{
@@ -74,6 +74,9 @@ func TestImagesRepoOverrideInConfiguration(t *testing.T) {
 			require.Equal(t, fmt.Sprintf("my.repo/k0sproject/calico-kube-controllers:%s", constant.CalicoComponentImagesVersion), testingConfig.Spec.Images.Calico.KubeControllers.URI())
 			require.Equal(t, fmt.Sprintf("my.repo/k0sproject/kube-router:%s", constant.KubeRouterCNIImageVersion), testingConfig.Spec.Images.KubeRouter.CNI.URI())
 			require.Equal(t, fmt.Sprintf("my.repo/k0sproject/cni-node:%s", constant.KubeRouterCNIInstallerImageVersion), testingConfig.Spec.Images.KubeRouter.CNIInstaller.URI())
+			require.Equal(t, fmt.Sprintf("my.repo/calico/cni-windows:%s", constant.CalicoWindowsImagesVersion), testingConfig.Spec.Images.Calico.CNIWindows.URI())
+			require.Equal(t, fmt.Sprintf("my.repo/calico/node-windows:%s", constant.CalicoWindowsImagesVersion), testingConfig.Spec.Images.Calico.NodeWindows.URI())
+			require.Equal(t, fmt.Sprintf("my.repo/sigwindowstools/kube-proxy:%s", constant.KubeProxyWindowsImageVersion), testingConfig.Spec.Images.Calico.KubeProxyWindows.URI())
 		})
 		t.Run("config_with_custom_images", func(t *testing.T) {
 			cfg := DefaultClusterConfig()
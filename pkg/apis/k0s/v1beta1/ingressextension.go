@@ -0,0 +1,213 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// IngressType is the type of the bundled ingress controller.
+type IngressType string
+
+// IngressExposure defines how the bundled ingress controller is exposed.
+type IngressExposure string
+
+const (
+	// IngressTypeNginx deploys ingress-nginx.
+	IngressTypeNginx IngressType = "nginx"
+
+	// IngressExposureLoadBalancer exposes the ingress controller via a
+	// LoadBalancer Service.
+	IngressExposureLoadBalancer IngressExposure = "LoadBalancer"
+	// IngressExposureNodePort exposes the ingress controller via a NodePort
+	// Service.
+	IngressExposureNodePort IngressExposure = "NodePort"
+	// IngressExposureHostPort runs the ingress controller on each worker,
+	// binding to the ports 80 and 443 of the host.
+	IngressExposureHostPort IngressExposure = "HostPort"
+
+	// DefaultIngressReplicas is the default number of ingress controller
+	// replicas for the LoadBalancer and NodePort exposures.
+	DefaultIngressReplicas = 2
+)
+
+var _ Validateable = (*IngressExtension)(nil)
+
+// IngressExtension defines the configuration options related to the bundled
+// ingress controller.
+type IngressExtension struct {
+	// enabled indicates if the ingress controller should be deployed.
+	// Default: false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// type is the type of the ingress controller to deploy.
+	// Default: nginx
+	// +kubebuilder:validation:Enum=nginx
+	// +kubebuilder:default=nginx
+	// +optional
+	Type IngressType `json:"type,omitempty"`
+
+	// exposure defines how the ingress controller is exposed. LoadBalancer
+	// and NodePort deploy the controller as a Deployment behind a Service of
+	// the respective type. HostPort deploys it as a DaemonSet which binds to
+	// the ports 80 and 443 of each worker.
+	// Default: LoadBalancer
+	// +kubebuilder:validation:Enum=LoadBalancer;NodePort;HostPort
+	// +kubebuilder:default=LoadBalancer
+	// +optional
+	Exposure IngressExposure `json:"exposure,omitempty"`
+
+	// replicas is the number of ingress controller replicas. Ignored for the
+	// HostPort exposure.
+	// Default: 2
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// defaultClass indicates if the ingress controller's IngressClass should
+	// be the cluster's default one.
+	// Default: false
+	// +optional
+	DefaultClass bool `json:"defaultClass,omitempty"`
+
+	// image specifies the OCI image that's being used for the ingress
+	// controller.
+	// +optional
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// imagePullPolicy specifies the pull policy being used for the ingress
+	// controller. Defaults to the default image pull policy.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+}
+
+// DefaultIngressExtension returns the default ingress controller
+// configuration.
+func DefaultIngressExtension() *IngressExtension {
+	var i IngressExtension
+	i.setDefaults()
+	return &i
+}
+
+var _ json.Unmarshaler = (*IngressExtension)(nil)
+
+func (i *IngressExtension) UnmarshalJSON(data []byte) error {
+	type ingressExtension IngressExtension
+	if err := json.Unmarshal(data, (*ingressExtension)(i)); err != nil {
+		return err
+	}
+
+	i.setDefaults()
+
+	return nil
+}
+
+func (i *IngressExtension) setDefaults() {
+	if i.Type == "" {
+		i.Type = IngressTypeNginx
+	}
+	if i.Exposure == "" {
+		i.Exposure = IngressExposureLoadBalancer
+	}
+	if i.Replicas == 0 {
+		i.Replicas = DefaultIngressReplicas
+	}
+	if i.Image == nil {
+		i.Image = DefaultIngressImage()
+	} else {
+		if i.Image.Image == "" {
+			i.Image.Image = constant.IngressNginxImage
+		}
+		if i.Image.Version == "" {
+			i.Image.Version = constant.IngressNginxImageVersion
+		}
+	}
+}
+
+// Validate implements [Validateable].
+func (i *IngressExtension) Validate() (errs []error) {
+	if i == nil {
+		return
+	}
+
+	path := field.NewPath("ingress")
+
+	if i.Type != IngressTypeNginx {
+		errs = append(errs, field.NotSupported(path.Child("type"), i.Type, []string{string(IngressTypeNginx)}))
+	}
+
+	switch i.Exposure {
+	case IngressExposureLoadBalancer, IngressExposureNodePort, IngressExposureHostPort:
+		break
+	default:
+		errs = append(errs, field.NotSupported(
+			path.Child("exposure"), i.Exposure, []string{
+				string(IngressExposureLoadBalancer),
+				string(IngressExposureNodePort),
+				string(IngressExposureHostPort),
+			},
+		))
+	}
+
+	if i.Replicas < 1 {
+		errs = append(errs, field.Invalid(path.Child("replicas"), i.Replicas, "must be at least 1"))
+	}
+
+	image := path.Child("image")
+	if i.Image == nil {
+		errs = append(errs, field.Required(image, "image must be set"))
+	} else {
+		for _, err := range i.Image.Validate(image) {
+			errs = append(errs, err)
+		}
+	}
+
+	switch i.ImagePullPolicy {
+	case corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent, "":
+		break
+	default:
+		errs = append(errs, field.NotSupported(
+			path.Child("imagePullPolicy"), i.ImagePullPolicy, []string{
+				string(corev1.PullAlways),
+				string(corev1.PullNever),
+				string(corev1.PullIfNotPresent),
+			},
+		))
+	}
+
+	return
+}
+
+func (i *IngressExtension) IsEnabled() bool {
+	return i != nil && i.Enabled
+}
+
+// DefaultIngressImage returns the default image spec to use for the ingress
+// controller.
+func DefaultIngressImage() *ImageSpec {
+	return &ImageSpec{
+		Image:   constant.IngressNginxImage,
+		Version: constant.IngressNginxImageVersion,
+	}
+}
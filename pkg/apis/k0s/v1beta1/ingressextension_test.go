@@ -0,0 +1,80 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIngressExtension_Unmarshal(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  images:
+    repository: example.com
+  extensions:
+    ingress:
+      enabled: true
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Nil(t, c.Validate())
+
+	ingress := c.Spec.Extensions.Ingress
+	require.NotNil(t, ingress)
+	assert.True(t, ingress.IsEnabled())
+	assert.Equal(t, IngressTypeNginx, ingress.Type)
+	assert.Equal(t, IngressExposureLoadBalancer, ingress.Exposure)
+	assert.Equal(t, int32(DefaultIngressReplicas), ingress.Replicas)
+	assert.False(t, ingress.DefaultClass)
+	require.NotNil(t, ingress.Image)
+	assert.Equal(t, "example.com/ingress-nginx/controller", ingress.Image.Image)
+	assert.Equal(t, DefaultIngressImage().Version, ingress.Image.Version)
+}
+
+func TestIngressExtension_Validate(t *testing.T) {
+	assert.Empty(t, (*IngressExtension)(nil).Validate())
+	assert.Empty(t, DefaultIngressExtension().Validate())
+
+	for _, test := range []struct {
+		name   string
+		modify func(*IngressExtension)
+		err    string
+	}{
+		{"type", func(i *IngressExtension) { i.Type = "traefik" }, `ingress.type: Unsupported value: "traefik"`},
+		{"exposure", func(i *IngressExtension) { i.Exposure = "ClusterIP" }, `ingress.exposure: Unsupported value: "ClusterIP"`},
+		{"replicas", func(i *IngressExtension) { i.Replicas = -1 }, "ingress.replicas: Invalid value: -1: must be at least 1"},
+		{"image", func(i *IngressExtension) { i.Image = nil }, "ingress.image: Required value: image must be set"},
+		{"imagePullPolicy", func(i *IngressExtension) { i.ImagePullPolicy = "Sometimes" }, `ingress.imagePullPolicy: Unsupported value: "Sometimes"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			i := DefaultIngressExtension()
+			test.modify(i)
+			errs := i.Validate()
+			if assert.Len(t, errs, 1) {
+				assert.ErrorContains(t, errs[0], test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// KernelSettings defines the sysctls and kernel modules that are ensured on
+// the workers of a profile, on top of the ones that k0s requires.
+type KernelSettings struct {
+	// Disables the management of sysctls and kernel modules. They need to be
+	// set up by other means then.
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Sysctls to set, keyed by their name, e.g. `net.ipv4.ip_forward`.
+	// Override the values that k0s sets by default.
+	// +optional
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+
+	// Kernel modules to load.
+	// +optional
+	Modules []string `json:"modules,omitempty"`
+}
+
+var (
+	sysctlNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+([./][a-zA-Z0-9_-]+)*$`)
+	moduleNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+)
+
+// Validate validates the kernel settings.
+func (k *KernelSettings) Validate(path *field.Path) (errs field.ErrorList) {
+	if k == nil {
+		return
+	}
+
+	for name, value := range k.Sysctls {
+		if !sysctlNameRegex.MatchString(name) {
+			errs = append(errs, field.Invalid(path.Child("sysctls").Key(name), name, "not a valid sysctl name"))
+		} else if strings.TrimSpace(value) == "" {
+			errs = append(errs, field.Required(path.Child("sysctls").Key(name), ""))
+		}
+	}
+
+	for i, module := range k.Modules {
+		if !moduleNameRegex.MatchString(module) {
+			errs = append(errs, field.Invalid(path.Child("modules").Index(i), module, "not a valid kernel module name"))
+		}
+	}
+
+	return
+}
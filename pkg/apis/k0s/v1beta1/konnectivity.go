@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 )
@@ -38,6 +39,72 @@ type KonnectivitySpec struct {
 	// +kubebuilder:default=8132
 	// +optional
 	AgentPort int32 `json:"agentPort,omitempty"`
+
+	// number of konnectivity servers the agents connect to. If unset, k0s
+	// keeps it in sync with the number of running controllers.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ServerCount int32 `json:"serverCount,omitempty"`
+
+	// settings for the konnectivity agents
+	// +optional
+	Agent *KonnectivityAgentSpec `json:"agent,omitempty"`
+
+	// how the konnectivity agents authenticate against the servers:
+	// ServiceAccountToken or MTLS (default: ServiceAccountToken)
+	// +optional
+	AuthMode KonnectivityAuthMode `json:"authMode,omitempty"`
+}
+
+// KonnectivityAuthMode defines how the konnectivity agents authenticate
+// against the konnectivity servers.
+// +kubebuilder:validation:Enum=ServiceAccountToken;MTLS
+type KonnectivityAuthMode string
+
+const (
+	// KonnectivityAuthModeServiceAccountToken authenticates the agents via
+	// projected service account tokens, which are reviewed by the servers.
+	KonnectivityAuthModeServiceAccountToken KonnectivityAuthMode = "ServiceAccountToken"
+	// KonnectivityAuthModeMTLS authenticates the agents via client
+	// certificates issued by the cluster CA.
+	KonnectivityAuthModeMTLS KonnectivityAuthMode = "MTLS"
+)
+
+// IsMTLS returns true if the agents authenticate via client certificates.
+func (k *KonnectivitySpec) IsMTLS() bool {
+	return k != nil && k.AuthMode == KonnectivityAuthModeMTLS
+}
+
+// KonnectivityAgentMode defines how the konnectivity agents are deployed.
+// +kubebuilder:validation:Enum=DaemonSet;Deployment
+type KonnectivityAgentMode string
+
+const (
+	// KonnectivityAgentModeDaemonSet runs an agent on each node.
+	KonnectivityAgentModeDaemonSet KonnectivityAgentMode = "DaemonSet"
+	// KonnectivityAgentModeDeployment runs a fixed number of agents.
+	KonnectivityAgentModeDeployment KonnectivityAgentMode = "Deployment"
+)
+
+// KonnectivityAgentSpec defines the settings for the konnectivity agents
+type KonnectivityAgentSpec struct {
+	// how the agents are deployed: DaemonSet runs an agent on each node,
+	// Deployment runs a fixed number of replicas (default: DaemonSet)
+	// +optional
+	Mode KonnectivityAgentMode `json:"mode,omitempty"`
+
+	// number of agent replicas if the mode is Deployment (default: 2)
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// tolerations of the agent pods (default: tolerate everything)
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+
+	// compute resources of the agent containers (default: none)
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 }
 
 // DefaultKonnectivitySpec builds default KonnectivitySpec
@@ -62,5 +129,37 @@ func (k *KonnectivitySpec) Validate() (errs []error) {
 		errs = append(errs, field.Invalid(field.NewPath("agentPort"), k.AgentPort, msg))
 	}
 
+	if k.ServerCount < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("serverCount"), k.ServerCount, "must not be negative"))
+	}
+
+	switch k.AuthMode {
+	case "", KonnectivityAuthModeServiceAccountToken, KonnectivityAuthModeMTLS:
+	default:
+		errs = append(errs, field.NotSupported(field.NewPath("authMode"), k.AuthMode, []string{
+			string(KonnectivityAuthModeServiceAccountToken),
+			string(KonnectivityAuthModeMTLS),
+		}))
+	}
+
+	if agent := k.Agent; agent != nil {
+		path := field.NewPath("agent")
+		switch agent.Mode {
+		case "", KonnectivityAgentModeDaemonSet:
+			if agent.Replicas != nil {
+				errs = append(errs, field.Forbidden(path.Child("replicas"), "replicas can only be set if the mode is Deployment"))
+			}
+		case KonnectivityAgentModeDeployment:
+			if agent.Replicas != nil && *agent.Replicas < 1 {
+				errs = append(errs, field.Invalid(path.Child("replicas"), *agent.Replicas, "must be greater than zero"))
+			}
+		default:
+			errs = append(errs, field.NotSupported(path.Child("mode"), agent.Mode, []string{
+				string(KonnectivityAgentModeDaemonSet),
+				string(KonnectivityAgentModeDeployment),
+			}))
+		}
+	}
+
 	return errs
 }
@@ -18,7 +18,9 @@ package v1beta1
 
 import (
 	"fmt"
+	"strings"
 
+	"golang.org/x/exp/slices"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -28,8 +30,12 @@ const (
 	ModeIptables  = "iptables"
 	ModeIPVS      = "ipvs"
 	ModeUSerspace = "userspace"
+	ModeNFTables  = "nftables"
 )
 
+// Supported IPVS schedulers, see http://www.linuxvirtualserver.org/docs/scheduling.html
+var ipvsSchedulers = []string{"rr", "wrr", "lc", "wlc", "lblc", "lblcr", "dh", "sh", "sed", "nq", "mh"}
+
 // KubeProxy defines the configuration for kube-proxy
 type KubeProxy struct {
 	Disabled           bool                            `json:"disabled,omitempty"`
@@ -37,6 +43,7 @@ type KubeProxy struct {
 	MetricsBindAddress string                          `json:"metricsBindAddress,omitempty"`
 	IPTables           *KubeProxyIPTablesConfiguration `json:"iptables,omitempty"`
 	IPVS               *KubeProxyIPVSConfiguration     `json:"ipvs,omitempty"`
+	NFTables           *KubeProxyNFTablesConfiguration `json:"nftables,omitempty"`
 }
 
 // KubeProxyIPTablesConfiguration contains iptables-related kube-proxy configuration
@@ -62,6 +69,15 @@ type KubeProxyIPVSConfiguration struct {
 	UDPTimeout    metav1.Duration `json:"udpTimeout,omitempty"`
 }
 
+// KubeProxyNFTablesConfiguration contains nftables-related kube-proxy configuration
+// @see https://github.com/kubernetes/kube-proxy/blob/master/config/v1alpha1/types.go#L80
+type KubeProxyNFTablesConfiguration struct {
+	MasqueradeBit *int32          `json:"masqueradeBit,omitempty"`
+	MasqueradeAll bool            `json:"masqueradeAll,omitempty"`
+	SyncPeriod    metav1.Duration `json:"syncPeriod,omitempty"`
+	MinSyncPeriod metav1.Duration `json:"minSyncPeriod,omitempty"`
+}
+
 // DefaultKubeProxy creates the default config for kube-proxy
 func DefaultKubeProxy() *KubeProxy {
 	return &KubeProxy{
@@ -70,6 +86,7 @@ func DefaultKubeProxy() *KubeProxy {
 		MetricsBindAddress: "0.0.0.0:10249",
 		IPTables:           DefaultKubeProxyIPTables(),
 		IPVS:               DefaultKubeProxyIPVS(),
+		NFTables:           DefaultKubeProxyNFTables(),
 	}
 }
 
@@ -95,14 +112,26 @@ func DefaultKubeProxyIPVS() *KubeProxyIPVSConfiguration {
 	}
 }
 
+func DefaultKubeProxyNFTables() *KubeProxyNFTablesConfiguration {
+	return &KubeProxyNFTablesConfiguration{
+		MasqueradeAll: false,
+		SyncPeriod:    metav1.Duration{Duration: 0},
+		MinSyncPeriod: metav1.Duration{Duration: 0},
+		MasqueradeBit: nil,
+	}
+}
+
 // Validate validates kube proxy config
 func (k *KubeProxy) Validate() []error {
 	if k.Disabled {
 		return nil
 	}
 	var errors []error
-	if k.Mode != "iptables" && k.Mode != "ipvs" && k.Mode != "userspace" {
+	if k.Mode != ModeIptables && k.Mode != ModeIPVS && k.Mode != ModeUSerspace && k.Mode != ModeNFTables {
 		errors = append(errors, fmt.Errorf("unsupported mode %s for kubeProxy config", k.Mode))
 	}
+	if k.IPVS != nil && k.IPVS.Scheduler != "" && !slices.Contains(ipvsSchedulers, k.IPVS.Scheduler) {
+		errors = append(errors, fmt.Errorf("unsupported ipvs scheduler %s for kubeProxy config, supported schedulers: %s", k.IPVS.Scheduler, strings.Join(ipvsSchedulers, ", ")))
+	}
 	return errors
 }
@@ -16,6 +16,12 @@ limitations under the License.
 
 package v1beta1
 
+import (
+	"net"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
 // KubeRouter defines the kube-router related config options
 type KubeRouter struct {
 	// Auto-detection of used MTU (default: true)
@@ -36,6 +42,38 @@ type KubeRouter struct {
 	PeerRouterASNs string `json:"peerRouterASNs"`
 	// Comma-separated list of global peer ASNs
 	PeerRouterIPs string `json:"peerRouterIPs"`
+	// External BGP peers to which all nodes will peer. Cannot be combined with peerRouterIPs and peerRouterASNs.
+	BGPPeers []KubeRouterBGPPeer `json:"bgpPeers,omitempty"`
+	// BGP router ID: an IPv4 address or "generate" (default: the node's IP address)
+	RouterID string `json:"routerID,omitempty"`
+	// ASN number under which the cluster nodes will run iBGP (default: 64512)
+	ClusterASN uint32 `json:"clusterASN,omitempty"`
+	// Route advertisement settings
+	Advertise *KubeRouterAdvertise `json:"advertise,omitempty"`
+	// Extra arguments to pass to kube-router. These may override the arguments set by k0s.
+	ExtraArgs map[string]string `json:"extraArgs,omitempty"`
+}
+
+// KubeRouterBGPPeer defines an external BGP peer for kube-router.
+type KubeRouterBGPPeer struct {
+	// IP address of the peer
+	IP string `json:"ip"`
+	// ASN of the peer
+	ASN uint32 `json:"asn"`
+	// Port of the peer (default: 179)
+	Port uint16 `json:"port,omitempty"`
+}
+
+// KubeRouterAdvertise defines which routes kube-router advertises to its BGP peers.
+type KubeRouterAdvertise struct {
+	// Advertise the cluster IPs of services (default: false)
+	ClusterIP bool `json:"clusterIP,omitempty"`
+	// Advertise the external IPs of services (default: false)
+	ExternalIP bool `json:"externalIP,omitempty"`
+	// Advertise the load balancer IPs of services (default: false)
+	LoadBalancerIP bool `json:"loadBalancerIP,omitempty"`
+	// Advertise the node's pod CIDR (default: true)
+	PodCIDR *bool `json:"podCIDR,omitempty"`
 }
 
 // +kubebuilder:validation:Enum=Enabled;Allowed;Disabled
@@ -58,3 +96,31 @@ func DefaultKubeRouter() *KubeRouter {
 		Hairpin:     HairpinEnabled,
 	}
 }
+
+// Validate validates the kube-router settings.
+func (k *KubeRouter) Validate(path *field.Path) (errs field.ErrorList) {
+	if k == nil {
+		return
+	}
+
+	if len(k.BGPPeers) > 0 && (k.PeerRouterIPs != "" || k.PeerRouterASNs != "") {
+		errs = append(errs, field.Forbidden(path.Child("bgpPeers"), "cannot be combined with peerRouterIPs and peerRouterASNs"))
+	}
+	for i, peer := range k.BGPPeers {
+		path := path.Child("bgpPeers").Index(i)
+		if net.ParseIP(peer.IP) == nil {
+			errs = append(errs, field.Invalid(path.Child("ip"), peer.IP, "invalid IP address"))
+		}
+		if peer.ASN == 0 {
+			errs = append(errs, field.Required(path.Child("asn"), ""))
+		}
+	}
+
+	if k.RouterID != "" && k.RouterID != "generate" {
+		if ip := net.ParseIP(k.RouterID); ip == nil || ip.To4() == nil {
+			errs = append(errs, field.Invalid(path.Child("routerID"), k.RouterID, `must be an IPv4 address or "generate"`))
+		}
+	}
+
+	return
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ Validateable = (*LeaderElectionSpec)(nil)
+
+// LeaderElectionSpec defines the timings of the leader election among the
+// controllers. All controllers of a cluster should use the same timings.
+type LeaderElectionSpec struct {
+	// Duration that non-leader controllers wait before trying to acquire a
+	// lease that hasn't been renewed (default 60s)
+	// +kubebuilder:default="60s"
+	// +optional
+	LeaseDuration metav1.Duration `json:"leaseDuration,omitempty"`
+
+	// Duration that the leader keeps retrying to renew its lease before
+	// giving it up (default 15s)
+	// +kubebuilder:default="15s"
+	// +optional
+	RenewDeadline metav1.Duration `json:"renewDeadline,omitempty"`
+
+	// Interval between two attempts to acquire or renew a lease (default 5s)
+	// +kubebuilder:default="5s"
+	// +optional
+	RetryPeriod metav1.Duration `json:"retryPeriod,omitempty"`
+
+	// Elect the controllers that reconcile CoreDNS, the network provider, the
+	// Helm extensions and the metrics components via individual leases, so
+	// that they are spread across controllers. All controllers of a cluster
+	// need to agree on this setting.
+	// +optional
+	ComponentLeases bool `json:"componentLeases,omitempty"`
+}
+
+// DefaultLeaderElectionSpec builds default LeaderElectionSpec
+func DefaultLeaderElectionSpec() *LeaderElectionSpec {
+	l := new(LeaderElectionSpec)
+	l.setDefaults()
+	return l
+}
+
+var _ json.Unmarshaler = (*LeaderElectionSpec)(nil)
+
+func (l *LeaderElectionSpec) UnmarshalJSON(data []byte) error {
+	type leaderElectionSpec LeaderElectionSpec
+	if err := json.Unmarshal(data, (*leaderElectionSpec)(l)); err != nil {
+		return err
+	}
+
+	l.setDefaults()
+
+	return nil
+}
+
+func (l *LeaderElectionSpec) setDefaults() {
+	if l.LeaseDuration.Duration == 0 {
+		l.LeaseDuration.Duration = 60 * time.Second
+	}
+	if l.RenewDeadline.Duration == 0 {
+		l.RenewDeadline.Duration = 15 * time.Second
+	}
+	if l.RetryPeriod.Duration == 0 {
+		l.RetryPeriod.Duration = 5 * time.Second
+	}
+}
+
+// leaderElectionJitterFactor is the factor by which the retry period is
+// jittered by the leader election. The renew deadline needs to accommodate
+// for it.
+const leaderElectionJitterFactor = 1.2
+
+// Validate implements [Validateable].
+func (l *LeaderElectionSpec) Validate() (errs []error) {
+	if l == nil {
+		return nil
+	}
+
+	if l.LeaseDuration.Duration <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("leaseDuration"), l.LeaseDuration.Duration.String(), "must be positive"))
+	}
+	if l.RenewDeadline.Duration <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("renewDeadline"), l.RenewDeadline.Duration.String(), "must be positive"))
+	} else if l.RenewDeadline.Duration >= l.LeaseDuration.Duration {
+		errs = append(errs, field.Invalid(field.NewPath("renewDeadline"), l.RenewDeadline.Duration.String(), "must be less than the lease duration"))
+	}
+	if l.RetryPeriod.Duration <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("retryPeriod"), l.RetryPeriod.Duration.String(), "must be positive"))
+	} else if float64(l.RenewDeadline.Duration) <= leaderElectionJitterFactor*float64(l.RetryPeriod.Duration) {
+		errs = append(errs, field.Invalid(field.NewPath("retryPeriod"), l.RetryPeriod.Duration.String(), "must be less than the renew deadline divided by 1.2"))
+	}
+
+	return errs
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLeaderElectionSpec_Defaults(t *testing.T) {
+	c, err := ConfigFromString(`
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+spec:
+  leaderElection:
+    leaseDuration: 20s
+`)
+	require.NoError(t, err)
+	assert.Empty(t, c.Validate())
+
+	expected := DefaultLeaderElectionSpec()
+	expected.LeaseDuration.Duration = 20 * time.Second
+	assert.Equal(t, expected, c.Spec.LeaderElection)
+}
+
+func TestLeaderElectionSpec_Validate(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		modify func(*LeaderElectionSpec)
+		errs   []string
+	}{
+		{"default", func(*LeaderElectionSpec) {}, nil},
+		{
+			"fast",
+			func(l *LeaderElectionSpec) {
+				l.LeaseDuration.Duration = 4 * time.Second
+				l.RenewDeadline.Duration = 3 * time.Second
+				l.RetryPeriod.Duration = 1 * time.Second
+			},
+			nil,
+		},
+		{
+			"negative_lease_duration",
+			func(l *LeaderElectionSpec) { l.LeaseDuration.Duration = -1 * time.Second },
+			[]string{
+				`leaseDuration: Invalid value: "-1s": must be positive`,
+				`renewDeadline: Invalid value: "15s": must be less than the lease duration`,
+			},
+		},
+		{
+			"renew_deadline_exceeds_lease_duration",
+			func(l *LeaderElectionSpec) { l.RenewDeadline.Duration = 1 * time.Minute },
+			[]string{`renewDeadline: Invalid value: "1m0s": must be less than the lease duration`},
+		},
+		{
+			"retry_period_too_long",
+			func(l *LeaderElectionSpec) { l.RetryPeriod.Duration = 13 * time.Second },
+			[]string{`retryPeriod: Invalid value: "13s": must be less than the renew deadline divided by 1.2`},
+		},
+		{
+			"zero_retry_period",
+			func(l *LeaderElectionSpec) { l.RetryPeriod.Duration = 0 },
+			[]string{`retryPeriod: Invalid value: "0s": must be positive`},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec := DefaultLeaderElectionSpec()
+			test.modify(spec)
+
+			var errs []string
+			for _, err := range spec.Validate() {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, test.errs, errs)
+		})
+	}
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"golang.org/x/exp/slices"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// The names of the metrics scraper jobs.
+const (
+	MetricsJobKubeScheduler         = "kube-scheduler"
+	MetricsJobKubeControllerManager = "kube-controller-manager"
+	MetricsJobEtcd                  = "etcd"
+	MetricsJobKine                  = "kine"
+	MetricsJobKonnectivityServer    = "konnectivity-server"
+	MetricsJobK0s                   = "k0s"
+)
+
+// The actions of metric relabeling rules.
+const (
+	RelabelReplace   = "replace"
+	RelabelKeep      = "keep"
+	RelabelDrop      = "drop"
+	RelabelLabelDrop = "labeldrop"
+	RelabelLabelKeep = "labelkeep"
+)
+
+var _ Validateable = (*MetricsScraperSpec)(nil)
+
+// MetricsScraperSpec configures the metrics scraper that is enabled via
+// --enable-metrics-scraper
+type MetricsScraperSpec struct {
+	// Interval between two scrapes of a job (default: 30s)
+	// +optional
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// Configuration of individual jobs
+	// +optional
+	Jobs []MetricsScraperJob `json:"jobs,omitempty"`
+
+	// Pushes the scraped metrics to a Prometheus remote write endpoint, in
+	// addition to the in-cluster push gateway
+	// +optional
+	RemoteWrite *MetricsRemoteWriteSpec `json:"remoteWrite,omitempty"`
+}
+
+// MetricsRemoteWriteSpec defines a Prometheus remote write endpoint. All file
+// paths refer to files on the controllers.
+type MetricsRemoteWriteSpec struct {
+	// URL of the remote write endpoint, e.g.
+	// https://prometheus.example.com/api/v1/write
+	URL string `json:"url"`
+
+	// Basic authentication credentials for the endpoint
+	// +optional
+	BasicAuth *MetricsRemoteWriteBasicAuth `json:"basicAuth,omitempty"`
+
+	// Absolute path to the CA certificates to verify the endpoint with
+	// (default: the system's trust store)
+	// +optional
+	CAFile string `json:"caFile,omitempty"`
+
+	// Absolute paths to a client certificate and key to authenticate with
+	// +optional
+	CertFile string `json:"certFile,omitempty"`
+	// +optional
+	KeyFile string `json:"keyFile,omitempty"`
+
+	// Disables the verification of the endpoint's certificate
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+}
+
+// MetricsRemoteWriteBasicAuth defines basic authentication credentials
+type MetricsRemoteWriteBasicAuth struct {
+	// User name for basic authentication
+	Username string `json:"username"`
+
+	// Absolute path to a file with the password. The file is re-read for each
+	// request.
+	PasswordFile string `json:"passwordFile"`
+}
+
+// MetricsScraperJob configures a metrics scraper job
+type MetricsScraperJob struct {
+	// Name of the job, one of kube-scheduler, kube-controller-manager, etcd,
+	// kine, konnectivity-server or k0s
+	Name string `json:"name"`
+
+	// Disables the job
+	// +optional
+	Disabled bool `json:"disabled,omitempty"`
+
+	// Relabeling rules that are applied to the scraped metrics before they're
+	// pushed, in the given order
+	// +optional
+	MetricRelabelings []MetricRelabeling `json:"metricRelabelings,omitempty"`
+}
+
+// MetricRelabeling is a rule that rewrites the labels of metrics, following
+// the semantics of Prometheus' metric_relabel_configs. The metric name is
+// available as the __name__ label.
+type MetricRelabeling struct {
+	// Labels whose values are concatenated and matched against the regex
+	// +optional
+	SourceLabels []string `json:"sourceLabels,omitempty"`
+
+	// Separator between the concatenated source label values (default: ;)
+	// +optional
+	Separator *string `json:"separator,omitempty"`
+
+	// Regular expression that's matched against the concatenated source label
+	// values, or the label names for labeldrop and labelkeep (default: (.*))
+	// +optional
+	Regex string `json:"regex,omitempty"`
+
+	// Label whose value is set by the replace action
+	// +optional
+	TargetLabel string `json:"targetLabel,omitempty"`
+
+	// Replacement value for the replace action, which may refer to regex
+	// capture groups (default: $1)
+	// +optional
+	Replacement *string `json:"replacement,omitempty"`
+
+	// Action to perform, one of replace, keep, drop, labeldrop or labelkeep
+	// (default: replace)
+	// +optional
+	Action string `json:"action,omitempty"`
+}
+
+// GetInterval returns the scrape interval, falling back to the default.
+func (m *MetricsScraperSpec) GetInterval() time.Duration {
+	if m == nil || m.Interval.Duration == 0 {
+		return 30 * time.Second
+	}
+	return m.Interval.Duration
+}
+
+// Job returns the configuration of the job with the given name. Returns nil
+// if there's none.
+func (m *MetricsScraperSpec) Job(name string) *MetricsScraperJob {
+	if m == nil {
+		return nil
+	}
+	for i := range m.Jobs {
+		if m.Jobs[i].Name == name {
+			return &m.Jobs[i]
+		}
+	}
+	return nil
+}
+
+// Validate implements [Validateable].
+func (m *MetricsScraperSpec) Validate() (errs []error) {
+	if m == nil {
+		return nil
+	}
+
+	if m.Interval.Duration < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("interval"), m.Interval.Duration.String(), "must not be negative"))
+	}
+
+	jobNames := []string{
+		MetricsJobKubeScheduler, MetricsJobKubeControllerManager, MetricsJobEtcd,
+		MetricsJobKine, MetricsJobKonnectivityServer, MetricsJobK0s,
+	}
+	seen := make(map[string]bool, len(m.Jobs))
+	for i, job := range m.Jobs {
+		path := field.NewPath("jobs").Index(i)
+		if !slices.Contains(jobNames, job.Name) {
+			errs = append(errs, field.NotSupported(path.Child("name"), job.Name, jobNames))
+		} else if seen[job.Name] {
+			errs = append(errs, field.Duplicate(path.Child("name"), job.Name))
+		}
+		seen[job.Name] = true
+
+		for j, rule := range job.MetricRelabelings {
+			errs = append(errs, rule.validate(path.Child("metricRelabelings").Index(j))...)
+		}
+	}
+
+	if m.RemoteWrite != nil {
+		errs = append(errs, m.RemoteWrite.validate(field.NewPath("remoteWrite"))...)
+	}
+
+	return errs
+}
+
+func (r *MetricsRemoteWriteSpec) validate(path *field.Path) (errs []error) {
+	if err := validateURL(r.URL, "http", "https"); err != nil {
+		errs = append(errs, field.Invalid(path.Child("url"), r.URL, err.Error()))
+	}
+
+	if r.BasicAuth != nil {
+		if r.BasicAuth.Username == "" {
+			errs = append(errs, field.Required(path.Child("basicAuth", "username"), ""))
+		}
+		if !filepath.IsAbs(r.BasicAuth.PasswordFile) {
+			errs = append(errs, field.Invalid(path.Child("basicAuth", "passwordFile"), r.BasicAuth.PasswordFile, "must be an absolute path"))
+		}
+	}
+
+	for _, f := range []struct{ name, value string }{
+		{"caFile", r.CAFile},
+		{"certFile", r.CertFile},
+		{"keyFile", r.KeyFile},
+	} {
+		if f.value != "" && !filepath.IsAbs(f.value) {
+			errs = append(errs, field.Invalid(path.Child(f.name), f.value, "must be an absolute path"))
+		}
+	}
+	if (r.CertFile == "") != (r.KeyFile == "") {
+		errs = append(errs, field.Invalid(path.Child("certFile"), r.CertFile, "certFile and keyFile have to be given together"))
+	}
+
+	return errs
+}
+
+func (r *MetricRelabeling) validate(path *field.Path) (errs []error) {
+	if _, err := r.CompileRegex(); err != nil {
+		errs = append(errs, field.Invalid(path.Child("regex"), r.Regex, err.Error()))
+	}
+
+	switch r.GetAction() {
+	case RelabelReplace:
+		if r.TargetLabel == "" {
+			errs = append(errs, field.Required(path.Child("targetLabel"), "required for the replace action"))
+		}
+	case RelabelKeep, RelabelDrop:
+		if len(r.SourceLabels) == 0 {
+			errs = append(errs, field.Required(path.Child("sourceLabels"), fmt.Sprintf("required for the %s action", r.Action)))
+		}
+	case RelabelLabelDrop, RelabelLabelKeep:
+	default:
+		errs = append(errs, field.NotSupported(path.Child("action"), r.Action, []string{
+			RelabelReplace, RelabelKeep, RelabelDrop, RelabelLabelDrop, RelabelLabelKeep,
+		}))
+	}
+
+	return errs
+}
+
+// GetAction returns the rule's action, falling back to the default.
+func (r *MetricRelabeling) GetAction() string {
+	if r.Action == "" {
+		return RelabelReplace
+	}
+	return r.Action
+}
+
+// GetSeparator returns the rule's separator, falling back to the default.
+func (r *MetricRelabeling) GetSeparator() string {
+	if r.Separator == nil {
+		return ";"
+	}
+	return *r.Separator
+}
+
+// GetReplacement returns the rule's replacement, falling back to the default.
+func (r *MetricRelabeling) GetReplacement() string {
+	if r.Replacement == nil {
+		return "$1"
+	}
+	return *r.Replacement
+}
+
+// CompileRegex compiles the rule's regex. As in Prometheus, it's anchored on
+// both ends.
+func (r *MetricRelabeling) CompileRegex() (*regexp.Regexp, error) {
+	regex := r.Regex
+	if regex == "" {
+		regex = "(.*)"
+	}
+	return regexp.Compile("^(?:" + regex + ")$")
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsScraperSpec(t *testing.T) {
+	var spec *MetricsScraperSpec
+	assert.Equal(t, 30*time.Second, spec.GetInterval())
+	assert.Nil(t, spec.Job(MetricsJobEtcd))
+
+	c, err := ConfigFromString(`
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+spec:
+  metricsScraper:
+    interval: 1m
+    jobs:
+    - name: kine
+      disabled: true
+    - name: etcd
+      metricRelabelings:
+      - sourceLabels: [__name__]
+        regex: grpc_.*
+        action: drop
+`)
+	require.NoError(t, err)
+	assert.Empty(t, c.Validate())
+
+	spec = c.Spec.MetricsScraper
+	assert.Equal(t, 1*time.Minute, spec.GetInterval())
+	assert.True(t, spec.Job(MetricsJobKine).Disabled)
+	if etcd := spec.Job(MetricsJobEtcd); assert.NotNil(t, etcd) && assert.Len(t, etcd.MetricRelabelings, 1) {
+		rule := etcd.MetricRelabelings[0]
+		assert.Equal(t, RelabelDrop, rule.GetAction())
+		assert.Equal(t, ";", rule.GetSeparator())
+		assert.Equal(t, "$1", rule.GetReplacement())
+	}
+	assert.Nil(t, spec.Job(MetricsJobKonnectivityServer))
+}
+
+func TestMetricsScraperSpec_Validate(t *testing.T) {
+	spec := &MetricsScraperSpec{
+		Jobs: []MetricsScraperJob{
+			{Name: "etcd"},
+			{Name: "etcd"},
+			{Name: "prometheus"},
+			{Name: "kine", MetricRelabelings: []MetricRelabeling{
+				{Regex: "("},
+				{Action: "keep"},
+				{Action: "hashmod"},
+				{Action: "labeldrop", Regex: "pod"},
+			}},
+		},
+	}
+
+	var errs []string
+	for _, err := range spec.Validate() {
+		errs = append(errs, err.Error())
+	}
+	assert.Equal(t, []string{
+		`jobs[1].name: Duplicate value: "etcd"`,
+		`jobs[2].name: Unsupported value: "prometheus": supported values: "kube-scheduler", "kube-controller-manager", "etcd", "kine", "konnectivity-server", "k0s"`,
+		"jobs[3].metricRelabelings[0].regex: Invalid value: \"(\": error parsing regexp: missing closing ): `^(?:()$`",
+		`jobs[3].metricRelabelings[0].targetLabel: Required value: required for the replace action`,
+		`jobs[3].metricRelabelings[1].sourceLabels: Required value: required for the keep action`,
+		`jobs[3].metricRelabelings[2].action: Unsupported value: "hashmod": supported values: "replace", "keep", "drop", "labeldrop", "labelkeep"`,
+	}, errs)
+}
+
+func TestMetricsRemoteWriteSpec_Validate(t *testing.T) {
+	spec := &MetricsScraperSpec{RemoteWrite: &MetricsRemoteWriteSpec{
+		URL:       "prometheus.example.com/api/v1/write",
+		BasicAuth: &MetricsRemoteWriteBasicAuth{PasswordFile: "password"},
+		CAFile:    "ca.crt",
+		CertFile:  "/etc/k0s/remote-write.crt",
+	}}
+
+	var errs []string
+	for _, err := range spec.Validate() {
+		errs = append(errs, err.Error())
+	}
+	assert.Equal(t, []string{
+		`remoteWrite.url: Invalid value: "prometheus.example.com/api/v1/write": unsupported scheme "", expected one of [http https]`,
+		`remoteWrite.basicAuth.username: Required value`,
+		`remoteWrite.basicAuth.passwordFile: Invalid value: "password": must be an absolute path`,
+		`remoteWrite.caFile: Invalid value: "ca.crt": must be an absolute path`,
+		`remoteWrite.certFile: Invalid value: "/etc/k0s/remote-write.crt": certFile and keyFile have to be given together`,
+	}, errs)
+
+	spec.RemoteWrite = &MetricsRemoteWriteSpec{URL: "https://prometheus.example.com/api/v1/write"}
+	assert.Empty(t, spec.Validate())
+}
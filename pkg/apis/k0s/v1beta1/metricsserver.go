@@ -0,0 +1,82 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ Validateable = (*MetricsServerSpec)(nil)
+
+// MinMetricsServerMetricResolution is the smallest metric resolution that
+// metrics-server accepts.
+const MinMetricsServerMetricResolution = 10 * time.Second
+
+// MetricsServerSpec defines the configuration options related to the
+// metrics-server cluster component.
+type MetricsServerSpec struct {
+	// replicas is the number of metrics-server replicas. If greater than one,
+	// the replicas are spread across nodes and guarded by a
+	// PodDisruptionBudget.
+	// Default: 1
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// resources are the compute resources of the metrics-server container.
+	// If set, they replace the default requests, which scale with the number
+	// of nodes in the cluster.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// kubeletInsecureTLS disables the verification of the kubelets' serving
+	// certificates. Only use this if the kubelets' serving certificates are
+	// not signed by the cluster CA.
+	// Default: false
+	// +optional
+	KubeletInsecureTLS bool `json:"kubeletInsecureTLS,omitempty"`
+
+	// metricResolution is the interval in which metrics are scraped from the
+	// kubelets. Must be at least 10s.
+	// Default: 15s
+	// +optional
+	MetricResolution *metav1.Duration `json:"metricResolution,omitempty"`
+}
+
+// Validate implements [Validateable].
+func (m *MetricsServerSpec) Validate() (errs []error) {
+	if m == nil {
+		return
+	}
+
+	if m.Replicas < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("replicas"), m.Replicas, "must not be negative"))
+	}
+
+	if m.MetricResolution != nil && m.MetricResolution.Duration < MinMetricsServerMetricResolution {
+		errs = append(errs, field.Invalid(
+			field.NewPath("metricResolution"), m.MetricResolution.Duration.String(),
+			"must be at least "+MinMetricsServerMetricResolution.String(),
+		))
+	}
+
+	return
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMetricsServerSpec_Unmarshal(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  metricsServer:
+    replicas: 2
+    kubeletInsecureTLS: true
+    metricResolution: 30s
+    resources:
+      requests:
+        cpu: 200m
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Nil(t, c.Validate())
+
+	metricsServer := c.Spec.MetricsServer
+	require.NotNil(t, metricsServer)
+	assert.Equal(t, int32(2), metricsServer.Replicas)
+	assert.True(t, metricsServer.KubeletInsecureTLS)
+	require.NotNil(t, metricsServer.MetricResolution)
+	assert.Equal(t, 30*time.Second, metricsServer.MetricResolution.Duration)
+	require.NotNil(t, metricsServer.Resources)
+	assert.Equal(t, "200m", metricsServer.Resources.Requests.Cpu().String())
+}
+
+func TestMetricsServerSpec_Validate(t *testing.T) {
+	assert.Empty(t, (*MetricsServerSpec)(nil).Validate())
+	assert.Empty(t, (&MetricsServerSpec{}).Validate())
+
+	m := &MetricsServerSpec{
+		Replicas:         -1,
+		MetricResolution: &metav1.Duration{Duration: 5 * time.Second},
+	}
+	errs := m.Validate()
+	if assert.Len(t, errs, 2) {
+		assert.ErrorContains(t, errs[0], "replicas: Invalid value: -1: must not be negative")
+		assert.ErrorContains(t, errs[1], `metricResolution: Invalid value: "5s": must be at least 10s`)
+	}
+}
@@ -36,12 +36,28 @@ type Network struct {
 	KubeProxy  *KubeProxy  `json:"kubeProxy"`
 	KubeRouter *KubeRouter `json:"kuberouter"`
 
+	// coreDNS defines the configuration options for the CoreDNS cluster
+	// component.
+	// +optional
+	CoreDNS *CoreDNS `json:"coreDNS,omitempty"`
+
 	// nodeLocalLoadBalancing defines the configuration options related to k0s's
 	// node-local load balancing feature.
 	// NOTE: This feature is experimental, and currently unsupported on ARMv7!
 	// +optional
 	NodeLocalLoadBalancing *NodeLocalLoadBalancing `json:"nodeLocalLoadBalancing,omitempty"`
 
+	// nodeLocalDNSCache defines the configuration options related to the
+	// NodeLocal DNSCache cluster component.
+	// +optional
+	NodeLocalDNSCache *NodeLocalDNSCache `json:"nodeLocalDNSCache,omitempty"`
+
+	// serviceLoadBalancer defines the configuration options related to the
+	// ServiceLoadBalancer cluster component, which implements Services of
+	// type LoadBalancer on clusters without a cloud provider.
+	// +optional
+	ServiceLoadBalancer *ServiceLoadBalancer `json:"serviceLoadBalancer,omitempty"`
+
 	// Pod network CIDR to use in the cluster
 	PodCIDR string `json:"podCIDR"`
 	// Network provider (valid values: calico, kuberouter, or custom)
@@ -50,6 +66,13 @@ type Network struct {
 	ServiceCIDR string `json:"serviceCIDR,omitempty"`
 	// Cluster Domain
 	ClusterDomain string `json:"clusterDomain,omitempty"`
+
+	// iptablesMode is the iptables mode used by worker nodes that don't
+	// specify one via --iptables-mode (valid values: auto, legacy or nft).
+	// Defaults to auto, i.e. the mode is detected on each node.
+	// +kubebuilder:validation:Enum=auto;legacy;nft
+	// +optional
+	IPTablesMode string `json:"iptablesMode,omitempty"`
 }
 
 // DefaultNetwork creates the Network config struct with sane default values
@@ -94,6 +117,12 @@ func (n *Network) Validate() []error {
 		errors = append(errors, field.Invalid(field.NewPath("clusterDomain"), n.ClusterDomain, "invalid DNS name"))
 	}
 
+	switch n.IPTablesMode {
+	case "", "auto", "legacy", "nft":
+	default:
+		errors = append(errors, field.NotSupported(field.NewPath("iptablesMode"), n.IPTablesMode, []string{"auto", "legacy", "nft"}))
+	}
+
 	if n.DualStack.Enabled {
 		if n.Provider == "calico" && n.Calico.Mode != "bird" {
 			errors = append(errors, field.Forbidden(field.NewPath("calico", "mode"), "dual stack for calico is only supported for mode `bird`"))
@@ -108,10 +137,29 @@ func (n *Network) Validate() []error {
 		}
 	}
 
+	if n.Provider == "calico" {
+		for _, err := range n.Calico.Validate(field.NewPath("calico")) {
+			errors = append(errors, err)
+		}
+	}
+	if n.Provider == "kuberouter" {
+		for _, err := range n.KubeRouter.Validate(field.NewPath("kuberouter")) {
+			errors = append(errors, err)
+		}
+	}
 	errors = append(errors, n.KubeProxy.Validate()...)
 	for _, err := range n.NodeLocalLoadBalancing.Validate(field.NewPath("nodeLocalLoadBalancing")) {
 		errors = append(errors, err)
 	}
+	for _, err := range n.CoreDNS.Validate(field.NewPath("coreDNS")) {
+		errors = append(errors, err)
+	}
+	for _, err := range n.NodeLocalDNSCache.Validate(field.NewPath("nodeLocalDNSCache")) {
+		errors = append(errors, err)
+	}
+	for _, err := range n.ServiceLoadBalancer.Validate(field.NewPath("serviceLoadBalancer")) {
+		errors = append(errors, err)
+	}
 
 	return errors
 }
@@ -239,6 +239,23 @@ func (s *NetworkSuite) TestValidation() {
 		}
 	})
 
+	s.T().Run("invalid_iptables_mode", func(t *testing.T) {
+		n := DefaultNetwork()
+		n.IPTablesMode = "foobar"
+
+		errors := n.Validate()
+		if s.Len(errors, 1) {
+			s.ErrorContains(errors[0], `iptablesMode: Unsupported value: "foobar": supported values: "auto", "legacy", "nft"`)
+		}
+	})
+
+	s.T().Run("valid_iptables_mode", func(t *testing.T) {
+		n := DefaultNetwork()
+		n.IPTablesMode = "legacy"
+
+		s.Nil(n.Validate())
+	})
+
 	s.T().Run("invalid_ipv6_service_cidr", func(t *testing.T) {
 		n := DefaultNetwork()
 		n.Calico = DefaultCalico()
@@ -281,6 +298,24 @@ func (s *NetworkSuite) TestValidation() {
 		}
 	})
 
+	s.T().Run("valid_nftables_mode_for_kube_proxy", func(t *testing.T) {
+		n := DefaultNetwork()
+		n.KubeProxy.Mode = "nftables"
+
+		s.Nil(n.Validate())
+	})
+
+	s.T().Run("invalid_ipvs_scheduler_for_kube_proxy", func(t *testing.T) {
+		n := DefaultNetwork()
+		n.KubeProxy.Mode = "ipvs"
+		n.KubeProxy.IPVS.Scheduler = "foobar"
+
+		errors := n.Validate()
+		if s.Len(errors, 1) {
+			s.ErrorContains(errors[0], "unsupported ipvs scheduler foobar for kubeProxy config")
+		}
+	})
+
 	s.T().Run("valid_proxy_disabled_for_dualstack", func(t *testing.T) {
 		n := DefaultNetwork()
 		n.Calico = DefaultCalico()
@@ -295,6 +330,65 @@ func (s *NetworkSuite) TestValidation() {
 		errors := n.Validate()
 		s.Nil(errors)
 	})
+
+	s.T().Run("calico_bgp_peers_require_bird", func(t *testing.T) {
+		n := DefaultNetwork()
+		n.Provider = "calico"
+		n.Calico = DefaultCalico()
+		n.Calico.BGPPeers = []CalicoBGPPeer{{Name: "tor", PeerIP: "192.0.2.1", ASNumber: 64512}}
+
+		errors := n.Validate()
+		if s.Len(errors, 1) {
+			s.ErrorContains(errors[0], "calico.bgpPeers: Forbidden: BGP peers are only supported for mode `bird`")
+		}
+	})
+
+	s.T().Run("invalid_calico_bgp_peers", func(t *testing.T) {
+		n := DefaultNetwork()
+		n.Provider = "calico"
+		n.Calico = DefaultCalico()
+		n.Calico.Mode = "bird"
+		n.Calico.BGPPeers = []CalicoBGPPeer{
+			{Name: "tor", PeerIP: "[2001:db8::1]:179", ASNumber: 64512},
+			{Name: "tor", PeerIP: "foobar"},
+		}
+
+		errors := n.Validate()
+		if s.Len(errors, 3) {
+			s.ErrorContains(errors[0], `calico.bgpPeers[1].name: Duplicate value: "tor"`)
+			s.ErrorContains(errors[1], `calico.bgpPeers[1].peerIP: Invalid value: "foobar": invalid IP address`)
+			s.ErrorContains(errors[2], `calico.bgpPeers[1].asNumber: Required value`)
+		}
+	})
+
+	s.T().Run("invalid_calico_felix", func(t *testing.T) {
+		n := DefaultNetwork()
+		n.Provider = "calico"
+		n.Calico = DefaultCalico()
+		n.Calico.Felix = &CalicoFelix{LogSeverityScreen: "Verbose", PrometheusMetricsPort: 70000, IptablesBackend: "ebpf"}
+
+		errors := n.Validate()
+		if s.Len(errors, 3) {
+			s.ErrorContains(errors[0], `calico.felix.logSeverityScreen: Unsupported value: "Verbose"`)
+			s.ErrorContains(errors[1], `calico.felix.prometheusMetricsPort: Invalid value: 70000: must be a valid port number`)
+			s.ErrorContains(errors[2], `calico.felix.iptablesBackend: Unsupported value: "ebpf"`)
+		}
+	})
+
+	s.T().Run("invalid_kuberouter_bgp_settings", func(t *testing.T) {
+		n := DefaultNetwork()
+		n.KubeRouter.PeerRouterIPs = "192.0.2.1"
+		n.KubeRouter.BGPPeers = []KubeRouterBGPPeer{{IP: "foobar"}}
+		n.KubeRouter.RouterID = "2001:db8::1"
+
+		errors := n.Validate()
+		if s.Len(errors, 4) {
+			s.ErrorContains(errors[0], "kuberouter.bgpPeers: Forbidden: cannot be combined with peerRouterIPs and peerRouterASNs")
+			s.ErrorContains(errors[1], `kuberouter.bgpPeers[0].ip: Invalid value: "foobar": invalid IP address`)
+			s.ErrorContains(errors[2], "kuberouter.bgpPeers[0].asn: Required value")
+			s.ErrorContains(errors[3], `kuberouter.routerID: Invalid value: "2001:db8::1": must be an IPv4 address or "generate"`)
+		}
+	})
 }
 
 func TestNetworkSuite(t *testing.T) {
@@ -0,0 +1,141 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"net"
+
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// DefaultNodeLocalDNSCacheLocalIP is the link-local address on which
+// NodeLocal DNSCache listens by default.
+const DefaultNodeLocalDNSCacheLocalIP = "169.254.20.10"
+
+// NodeLocalDNSCache defines the configuration options related to the
+// NodeLocal DNSCache cluster component, which runs a DNS caching agent on
+// each Linux worker node.
+type NodeLocalDNSCache struct {
+	// enabled indicates if NodeLocal DNSCache should be deployed, and if
+	// kubelets should point Pods to it instead of CoreDNS.
+	// Default: false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// localIP is the IP address on which the caching agent listens on each
+	// node. It needs to be an address that isn't used anywhere else in the
+	// cluster.
+	// Default: 169.254.20.10
+	// +kubebuilder:default="169.254.20.10"
+	// +optional
+	LocalIP string `json:"localIP,omitempty"`
+
+	// image specifies the OCI image that's being used for the caching agent.
+	// +optional
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// imagePullPolicy specifies the pull policy being used for the caching
+	// agent. Defaults to the default image pull policy.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+}
+
+// DefaultNodeLocalDNSCache returns the default NodeLocal DNSCache configuration.
+func DefaultNodeLocalDNSCache() *NodeLocalDNSCache {
+	var c NodeLocalDNSCache
+	c.setDefaults()
+	return &c
+}
+
+var _ json.Unmarshaler = (*NodeLocalDNSCache)(nil)
+
+func (c *NodeLocalDNSCache) UnmarshalJSON(data []byte) error {
+	type nodeLocalDNSCache NodeLocalDNSCache
+	if err := json.Unmarshal(data, (*nodeLocalDNSCache)(c)); err != nil {
+		return err
+	}
+
+	c.setDefaults()
+
+	return nil
+}
+
+func (c *NodeLocalDNSCache) setDefaults() {
+	if c.LocalIP == "" {
+		c.LocalIP = DefaultNodeLocalDNSCacheLocalIP
+	}
+	if c.Image == nil {
+		c.Image = DefaultNodeLocalDNSCacheImage()
+	} else {
+		if c.Image.Image == "" {
+			c.Image.Image = constant.NodeLocalDNSImage
+		}
+		if c.Image.Version == "" {
+			c.Image.Version = constant.NodeLocalDNSImageVersion
+		}
+	}
+}
+
+func (c *NodeLocalDNSCache) Validate(path *field.Path) (errs field.ErrorList) {
+	if c == nil {
+		return
+	}
+
+	if net.ParseIP(c.LocalIP) == nil {
+		errs = append(errs, field.Invalid(path.Child("localIP"), c.LocalIP, "not an IP address"))
+	}
+
+	image := path.Child("image")
+	if c.Image == nil {
+		errs = append(errs, field.Required(image, "image must be set"))
+	} else {
+		errs = append(errs, c.Image.Validate(image)...)
+	}
+
+	switch c.ImagePullPolicy {
+	case corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent, "":
+		break
+	default:
+		errs = append(errs, field.NotSupported(
+			path.Child("imagePullPolicy"), c.ImagePullPolicy, []string{
+				string(corev1.PullAlways),
+				string(corev1.PullNever),
+				string(corev1.PullIfNotPresent),
+			},
+		))
+	}
+
+	return
+}
+
+func (c *NodeLocalDNSCache) IsEnabled() bool {
+	return c != nil && c.Enabled
+}
+
+// DefaultNodeLocalDNSCacheImage returns the default image spec to use for
+// NodeLocal DNSCache.
+func DefaultNodeLocalDNSCacheImage() *ImageSpec {
+	return &ImageSpec{
+		Image:   constant.NodeLocalDNSImage,
+		Version: constant.NodeLocalDNSImageVersion,
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestNodeLocalDNSCache_Unmarshal(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  images:
+    repository: example.com
+  network:
+    nodeLocalDNSCache:
+      enabled: true
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Nil(t, c.Validate())
+
+	nodeLocalDNS := c.Spec.Network.NodeLocalDNSCache
+	require.NotNil(t, nodeLocalDNS)
+	assert.True(t, nodeLocalDNS.IsEnabled())
+	assert.Equal(t, DefaultNodeLocalDNSCacheLocalIP, nodeLocalDNS.LocalIP)
+	require.NotNil(t, nodeLocalDNS.Image)
+	assert.Contains(t, nodeLocalDNS.Image.Image, "example.com/")
+	assert.Equal(t, DefaultNodeLocalDNSCacheImage().Version, nodeLocalDNS.Image.Version)
+}
+
+func TestNodeLocalDNSCache_Validate(t *testing.T) {
+	assert.Empty(t, (*NodeLocalDNSCache)(nil).Validate(field.NewPath("nodeLocalDNSCache")))
+	assert.Empty(t, DefaultNodeLocalDNSCache().Validate(field.NewPath("nodeLocalDNSCache")))
+
+	c := DefaultNodeLocalDNSCache()
+	c.LocalIP = "local"
+	c.ImagePullPolicy = "Sometimes"
+	errs := c.Validate(field.NewPath("nodeLocalDNSCache"))
+	if assert.Len(t, errs, 2) {
+		assert.ErrorContains(t, errs[0], `nodeLocalDNSCache.localIP: Invalid value: "local": not an IP address`)
+		assert.ErrorContains(t, errs[1], `nodeLocalDNSCache.imagePullPolicy: Unsupported value: "Sometimes"`)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// OIDC defines the settings for authenticating users via OpenID Connect
+type OIDC struct {
+	// The URL of the OpenID issuer. Only the HTTPS scheme is accepted.
+	IssuerURL string `json:"issuerURL"`
+	// The client ID for the OpenID Connect client. Tokens must be issued for
+	// this client ID.
+	ClientID string `json:"clientID"`
+	// The OpenID claim to use as the user name (default: sub)
+	UsernameClaim string `json:"usernameClaim,omitempty"`
+	// Prefix prepended to username claims to prevent clashes with existing
+	// names. The value "-" disables any prefixing.
+	UsernamePrefix string `json:"usernamePrefix,omitempty"`
+	// The OpenID claim to use as the user's groups
+	GroupsClaim string `json:"groupsClaim,omitempty"`
+	// Prefix prepended to group claims to prevent clashes with existing names
+	GroupsPrefix string `json:"groupsPrefix,omitempty"`
+	// Key-value pairs that are required to be present as claims in the ID
+	// token, with a matching value
+	RequiredClaims map[string]string `json:"requiredClaims,omitempty"`
+	// Path to a file containing the CA that signed the issuer's serving
+	// certificate. Defaults to the host's root CAs.
+	CAFile string `json:"caFile,omitempty"`
+	// The allowed JOSE asymmetric signing algorithms (default: RS256)
+	SigningAlgs []string `json:"signingAlgs,omitempty"`
+}
+
+// Validate validates OIDC struct
+func (o *OIDC) Validate(path *field.Path) []error {
+	if o == nil {
+		return nil
+	}
+
+	var errors []error
+
+	if o.IssuerURL == "" {
+		errors = append(errors, field.Required(path.Child("issuerURL"), ""))
+	} else if u, err := url.Parse(o.IssuerURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		errors = append(errors, field.Invalid(path.Child("issuerURL"), o.IssuerURL, "must be an HTTPS URL"))
+	}
+
+	if o.ClientID == "" {
+		errors = append(errors, field.Required(path.Child("clientID"), ""))
+	}
+
+	for key := range o.RequiredClaims {
+		if key == "" || strings.ContainsAny(key, "=,") {
+			errors = append(errors, field.Invalid(path.Child("requiredClaims"), key, "invalid claim name"))
+		}
+	}
+
+	return errors
+}
+
+// BuildArgs adds the kube-apiserver flags for this OIDC configuration to args
+func (o *OIDC) BuildArgs(args stringmap.StringMap) stringmap.StringMap {
+	if o == nil {
+		return args
+	}
+
+	args["oidc-issuer-url"] = o.IssuerURL
+	args["oidc-client-id"] = o.ClientID
+
+	optionalArgs := map[string]string{
+		"oidc-username-claim":  o.UsernameClaim,
+		"oidc-username-prefix": o.UsernamePrefix,
+		"oidc-groups-claim":    o.GroupsClaim,
+		"oidc-groups-prefix":   o.GroupsPrefix,
+		"oidc-ca-file":         o.CAFile,
+		"oidc-signing-algs":    strings.Join(o.SigningAlgs, ","),
+	}
+	for name, value := range optionalArgs {
+		if value != "" {
+			args[name] = value
+		}
+	}
+
+	if len(o.RequiredClaims) > 0 {
+		claims := make([]string, 0, len(o.RequiredClaims))
+		for key, value := range o.RequiredClaims {
+			claims = append(claims, fmt.Sprintf("%s=%s", key, value))
+		}
+		sort.Strings(claims)
+		args["oidc-required-claim"] = strings.Join(claims, ",")
+	}
+
+	return args
+}
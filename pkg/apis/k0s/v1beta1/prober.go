@@ -0,0 +1,110 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ Validateable = (*ProberSpec)(nil)
+
+// ProberSpec defines how the health of the k0s components is probed
+type ProberSpec struct {
+	// Interval between two health probes of a component (default 10s)
+	// +kubebuilder:default="10s"
+	// +optional
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// Time after which a single health probe is considered failed
+	// (default 5s)
+	// +kubebuilder:default="5s"
+	// +optional
+	Timeout metav1.Duration `json:"timeout,omitempty"`
+
+	// Number of health probe results that are kept per component (default 3)
+	// +kubebuilder:default=3
+	// +optional
+	ProbesTrackLength int `json:"probesTrackLength,omitempty"`
+
+	// Number of events that are kept per component (default 3)
+	// +kubebuilder:default=3
+	// +optional
+	EventsTrackLength int `json:"eventsTrackLength,omitempty"`
+}
+
+// DefaultProberSpec builds default ProberSpec
+func DefaultProberSpec() *ProberSpec {
+	p := new(ProberSpec)
+	p.setDefaults()
+	return p
+}
+
+var _ json.Unmarshaler = (*ProberSpec)(nil)
+
+func (p *ProberSpec) UnmarshalJSON(data []byte) error {
+	type proberSpec ProberSpec
+	if err := json.Unmarshal(data, (*proberSpec)(p)); err != nil {
+		return err
+	}
+
+	p.setDefaults()
+
+	return nil
+}
+
+func (p *ProberSpec) setDefaults() {
+	if p.Interval.Duration == 0 {
+		p.Interval.Duration = 10 * time.Second
+	}
+	if p.Timeout.Duration == 0 {
+		p.Timeout.Duration = 5 * time.Second
+	}
+	if p.ProbesTrackLength == 0 {
+		p.ProbesTrackLength = 3
+	}
+	if p.EventsTrackLength == 0 {
+		p.EventsTrackLength = 3
+	}
+}
+
+// Validate implements [Validateable].
+func (p *ProberSpec) Validate() (errs []error) {
+	if p == nil {
+		return nil
+	}
+
+	if p.Interval.Duration <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("interval"), p.Interval.Duration.String(), "must be positive"))
+	}
+	if p.Timeout.Duration <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("timeout"), p.Timeout.Duration.String(), "must be positive"))
+	} else if p.Timeout.Duration > p.Interval.Duration {
+		errs = append(errs, field.Invalid(field.NewPath("timeout"), p.Timeout.Duration.String(), "must not exceed the interval"))
+	}
+	if p.ProbesTrackLength < 1 {
+		errs = append(errs, field.Invalid(field.NewPath("probesTrackLength"), p.ProbesTrackLength, "must be at least 1"))
+	}
+	if p.EventsTrackLength < 1 {
+		errs = append(errs, field.Invalid(field.NewPath("eventsTrackLength"), p.EventsTrackLength, "must be at least 1"))
+	}
+
+	return errs
+}
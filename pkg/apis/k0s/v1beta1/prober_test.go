@@ -0,0 +1,83 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProberSpec_Defaults(t *testing.T) {
+	c, err := ConfigFromString(`
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+spec:
+  prober:
+    interval: 30s
+`)
+	require.NoError(t, err)
+	assert.Empty(t, c.Validate())
+
+	expected := DefaultProberSpec()
+	expected.Interval.Duration = 30 * time.Second
+	assert.Equal(t, expected, c.Spec.Prober)
+}
+
+func TestProberSpec_Validate(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		modify func(*ProberSpec)
+		errs   []string
+	}{
+		{"default", func(*ProberSpec) {}, nil},
+		{
+			"negative_interval",
+			func(p *ProberSpec) { p.Interval.Duration = -1 * time.Second },
+			[]string{
+				`interval: Invalid value: "-1s": must be positive`,
+				`timeout: Invalid value: "5s": must not exceed the interval`,
+			},
+		},
+		{
+			"timeout_exceeds_interval",
+			func(p *ProberSpec) { p.Timeout.Duration = 1 * time.Minute },
+			[]string{`timeout: Invalid value: "1m0s": must not exceed the interval`},
+		},
+		{
+			"negative_track_lengths",
+			func(p *ProberSpec) { p.ProbesTrackLength, p.EventsTrackLength = -1, -1 },
+			[]string{
+				`probesTrackLength: Invalid value: -1: must be at least 1`,
+				`eventsTrackLength: Invalid value: -1: must be at least 1`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			spec := DefaultProberSpec()
+			test.modify(spec)
+
+			var errs []string
+			for _, err := range spec.Validate() {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, test.errs, errs)
+		})
+	}
+}
@@ -0,0 +1,311 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"strings"
+
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+// ServiceLoadBalancer defines the configuration options related to the
+// ServiceLoadBalancer cluster component, which implements Services of type
+// LoadBalancer on clusters without a cloud provider, e.g. on bare metal.
+type ServiceLoadBalancer struct {
+	// enabled indicates if the load balancer implementation should be
+	// deployed.
+	// Default: false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// type indicates the type of the load balancer implementation to deploy.
+	// Currently, the only supported type is "MetalLB".
+	// +kubebuilder:default=MetalLB
+	// +optional
+	Type ServiceLoadBalancerType `json:"type,omitempty"`
+
+	// addressPools are the pools of IP addresses that are assigned to
+	// Services of type LoadBalancer. The addresses are announced to the local
+	// network via ARP (IPv4) and NDP (IPv6).
+	// +optional
+	AddressPools []ServiceLoadBalancerAddressPool `json:"addressPools,omitempty"`
+
+	// metalLB contains configuration options related to the "MetalLB" type of
+	// load balancer implementation.
+	// +optional
+	MetalLB *MetalLB `json:"metalLB,omitempty"`
+}
+
+// ServiceLoadBalancerType describes which load balancer implementation should
+// be deployed. The default is [ServiceLoadBalancerTypeMetalLB].
+// +kubebuilder:validation:Enum=MetalLB
+type ServiceLoadBalancerType string
+
+const (
+	// ServiceLoadBalancerTypeMetalLB selects MetalLB as the load balancer
+	// implementation.
+	ServiceLoadBalancerTypeMetalLB ServiceLoadBalancerType = "MetalLB"
+)
+
+// ServiceLoadBalancerAddressPool is a pool of IP addresses that are assigned
+// to Services of type LoadBalancer.
+type ServiceLoadBalancerAddressPool struct {
+	// name is the name of the address pool. Services may request addresses
+	// from a specific pool via the "metallb.universe.tf/address-pool"
+	// annotation.
+	Name string `json:"name"`
+
+	// addresses is the list of the pool's addresses, either as CIDRs, e.g.
+	// "192.168.1.0/24", or as ranges, e.g. "192.168.1.10-192.168.1.20".
+	Addresses []string `json:"addresses"`
+
+	// autoAssign indicates if addresses may be automatically assigned from
+	// this pool. If false, addresses are only assigned to Services that
+	// explicitly request this pool.
+	// Default: true
+	// +optional
+	AutoAssign *bool `json:"autoAssign,omitempty"`
+}
+
+// MetalLB describes configuration options required for using MetalLB as the
+// load balancer implementation.
+type MetalLB struct {
+	// controllerImage specifies the OCI image that's being used for the
+	// MetalLB controller, which assigns the addresses.
+	// +optional
+	ControllerImage *ImageSpec `json:"controllerImage,omitempty"`
+
+	// speakerImage specifies the OCI image that's being used for the MetalLB
+	// speaker, which announces the addresses on each node.
+	// +optional
+	SpeakerImage *ImageSpec `json:"speakerImage,omitempty"`
+
+	// imagePullPolicy specifies the pull policy being used for the MetalLB
+	// Pods. Defaults to the default image pull policy.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+}
+
+// DefaultServiceLoadBalancer returns the default service load balancer
+// configuration.
+func DefaultServiceLoadBalancer() *ServiceLoadBalancer {
+	var s ServiceLoadBalancer
+	s.setDefaults()
+	return &s
+}
+
+var _ json.Unmarshaler = (*ServiceLoadBalancer)(nil)
+
+func (s *ServiceLoadBalancer) UnmarshalJSON(data []byte) error {
+	type serviceLoadBalancer ServiceLoadBalancer
+	if err := json.Unmarshal(data, (*serviceLoadBalancer)(s)); err != nil {
+		return err
+	}
+
+	s.setDefaults()
+
+	return nil
+}
+
+func (s *ServiceLoadBalancer) setDefaults() {
+	if s.Type == "" {
+		s.Type = ServiceLoadBalancerTypeMetalLB
+	}
+	if s.MetalLB == nil {
+		s.MetalLB = DefaultMetalLB()
+	}
+}
+
+func (s *ServiceLoadBalancer) Validate(path *field.Path) (errs field.ErrorList) {
+	if s == nil {
+		return
+	}
+
+	switch s.Type {
+	case ServiceLoadBalancerTypeMetalLB:
+	case "":
+		if s.IsEnabled() {
+			errs = append(errs, field.Forbidden(path.Child("type"), "need to specify type if enabled"))
+		}
+	default:
+		errs = append(errs, field.NotSupported(path.Child("type"), s.Type, []string{string(ServiceLoadBalancerTypeMetalLB)}))
+	}
+
+	names := make(map[string]struct{}, len(s.AddressPools))
+	for i, pool := range s.AddressPools {
+		path := path.Child("addressPools").Index(i)
+
+		if pool.Name == "" {
+			errs = append(errs, field.Required(path.Child("name"), ""))
+		} else if _, ok := names[pool.Name]; ok {
+			errs = append(errs, field.Duplicate(path.Child("name"), pool.Name))
+		} else {
+			for _, msg := range validation.IsDNS1123Subdomain(pool.Name) {
+				errs = append(errs, field.Invalid(path.Child("name"), pool.Name, msg))
+			}
+		}
+		names[pool.Name] = struct{}{}
+
+		if len(pool.Addresses) < 1 {
+			errs = append(errs, field.Required(path.Child("addresses"), "at least one address is required"))
+		}
+		for j, addresses := range pool.Addresses {
+			if err := validateAddressPoolAddresses(addresses); err != nil {
+				errs = append(errs, field.Invalid(path.Child("addresses").Index(j), addresses, err.Error()))
+			}
+		}
+	}
+
+	errs = append(errs, s.MetalLB.Validate(path.Child("metalLB"))...)
+
+	return
+}
+
+// validateAddressPoolAddresses checks if the given string is either a CIDR or
+// a range of IP addresses of the same family.
+func validateAddressPoolAddresses(addresses string) error {
+	from, to, isRange := strings.Cut(addresses, "-")
+	if !isRange {
+		if _, err := netip.ParsePrefix(addresses); err != nil {
+			return errors.New("neither a CIDR nor an IP address range")
+		}
+		return nil
+	}
+
+	start, err := netip.ParseAddr(strings.TrimSpace(from))
+	if err != nil {
+		return errors.New("invalid start of IP address range")
+	}
+	end, err := netip.ParseAddr(strings.TrimSpace(to))
+	if err != nil {
+		return errors.New("invalid end of IP address range")
+	}
+	if start.Is4() != end.Is4() {
+		return errors.New("IP address range mixes IPv4 and IPv6")
+	}
+	if end.Less(start) {
+		return errors.New("end of IP address range is before its start")
+	}
+	return nil
+}
+
+// IsEnabled returns true if the service load balancer is enabled.
+func (s *ServiceLoadBalancer) IsEnabled() bool {
+	return s != nil && s.Enabled
+}
+
+// DefaultMetalLB returns the default MetalLB configuration.
+func DefaultMetalLB() *MetalLB {
+	m := new(MetalLB)
+	m.setDefaults()
+	return m
+}
+
+var _ json.Unmarshaler = (*MetalLB)(nil)
+
+func (m *MetalLB) UnmarshalJSON(data []byte) error {
+	type metalLB MetalLB
+	if err := json.Unmarshal(data, (*metalLB)(m)); err != nil {
+		return err
+	}
+
+	m.setDefaults()
+
+	return nil
+}
+
+func (m *MetalLB) setDefaults() {
+	if m.ControllerImage == nil {
+		m.ControllerImage = DefaultMetalLBControllerImage()
+	} else {
+		if m.ControllerImage.Image == "" {
+			m.ControllerImage.Image = constant.MetalLBControllerImage
+		}
+		if m.ControllerImage.Version == "" {
+			m.ControllerImage.Version = constant.MetalLBImageVersion
+		}
+	}
+	if m.SpeakerImage == nil {
+		m.SpeakerImage = DefaultMetalLBSpeakerImage()
+	} else {
+		if m.SpeakerImage.Image == "" {
+			m.SpeakerImage.Image = constant.MetalLBSpeakerImage
+		}
+		if m.SpeakerImage.Version == "" {
+			m.SpeakerImage.Version = constant.MetalLBImageVersion
+		}
+	}
+}
+
+func (m *MetalLB) Validate(path *field.Path) (errs field.ErrorList) {
+	if m == nil {
+		return
+	}
+
+	for name, image := range map[string]*ImageSpec{
+		"controllerImage": m.ControllerImage,
+		"speakerImage":    m.SpeakerImage,
+	} {
+		path := path.Child(name)
+		if image == nil {
+			errs = append(errs, field.Required(path, "image must be set"))
+		} else {
+			errs = append(errs, image.Validate(path)...)
+		}
+	}
+
+	switch m.ImagePullPolicy {
+	case corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent, "":
+		break
+	default:
+		errs = append(errs, field.NotSupported(
+			path.Child("imagePullPolicy"), m.ImagePullPolicy, []string{
+				string(corev1.PullAlways),
+				string(corev1.PullNever),
+				string(corev1.PullIfNotPresent),
+			},
+		))
+	}
+
+	return
+}
+
+// DefaultMetalLBControllerImage returns the default image spec to use for the
+// MetalLB controller.
+func DefaultMetalLBControllerImage() *ImageSpec {
+	return &ImageSpec{
+		Image:   constant.MetalLBControllerImage,
+		Version: constant.MetalLBImageVersion,
+	}
+}
+
+// DefaultMetalLBSpeakerImage returns the default image spec to use for the
+// MetalLB speaker.
+func DefaultMetalLBSpeakerImage() *ImageSpec {
+	return &ImageSpec{
+		Image:   constant.MetalLBSpeakerImage,
+		Version: constant.MetalLBImageVersion,
+	}
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+func TestServiceLoadBalancer_Unmarshal(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  images:
+    repository: example.com
+  network:
+    serviceLoadBalancer:
+      enabled: true
+      addressPools:
+      - name: default
+        addresses: [192.168.1.240/28, 192.168.2.10-192.168.2.20]
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Nil(t, c.Validate())
+
+	slb := c.Spec.Network.ServiceLoadBalancer
+	require.NotNil(t, slb)
+	assert.True(t, slb.IsEnabled())
+	assert.Equal(t, ServiceLoadBalancerTypeMetalLB, slb.Type)
+	assert.Equal(t, []ServiceLoadBalancerAddressPool{{
+		Name:      "default",
+		Addresses: []string{"192.168.1.240/28", "192.168.2.10-192.168.2.20"},
+	}}, slb.AddressPools)
+	require.NotNil(t, slb.MetalLB)
+	assert.Equal(t, "example.com/metallb/controller", slb.MetalLB.ControllerImage.Image)
+	assert.Equal(t, "example.com/metallb/speaker", slb.MetalLB.SpeakerImage.Image)
+	assert.Equal(t, DefaultMetalLBSpeakerImage().Version, slb.MetalLB.SpeakerImage.Version)
+}
+
+func TestServiceLoadBalancer_Validate(t *testing.T) {
+	path := field.NewPath("serviceLoadBalancer")
+	assert.Empty(t, (*ServiceLoadBalancer)(nil).Validate(path))
+	assert.Empty(t, DefaultServiceLoadBalancer().Validate(path))
+
+	for _, test := range []struct {
+		name   string
+		modify func(*ServiceLoadBalancer)
+		errs   []string
+	}{
+		{
+			"type", func(s *ServiceLoadBalancer) { s.Type = "kube-vip" },
+			[]string{`serviceLoadBalancer.type: Unsupported value: "kube-vip"`},
+		},
+		{
+			"no_type", func(s *ServiceLoadBalancer) { s.Enabled, s.Type = true, "" },
+			[]string{"serviceLoadBalancer.type: Forbidden: need to specify type if enabled"},
+		},
+		{
+			"valid_pools", func(s *ServiceLoadBalancer) {
+				s.AddressPools = []ServiceLoadBalancerAddressPool{
+					{Name: "v4", Addresses: []string{"10.0.0.0/24", "10.0.1.10 - 10.0.1.20", "10.0.2.1-10.0.2.1"}},
+					{Name: "v6", Addresses: []string{"fd00::/120", "fd01::10-fd01::20"}},
+				}
+			},
+			nil,
+		},
+		{
+			"pool_names", func(s *ServiceLoadBalancer) {
+				s.AddressPools = []ServiceLoadBalancerAddressPool{
+					{Name: "", Addresses: []string{"10.0.0.0/24"}},
+					{Name: "Pool", Addresses: []string{"10.0.1.0/24"}},
+					{Name: "pool", Addresses: []string{"10.0.2.0/24"}},
+					{Name: "pool", Addresses: []string{"10.0.3.0/24"}},
+				}
+			},
+			[]string{
+				"serviceLoadBalancer.addressPools[0].name: Required value",
+				`serviceLoadBalancer.addressPools[1].name: Invalid value: "Pool"`,
+				`serviceLoadBalancer.addressPools[3].name: Duplicate value: "pool"`,
+			},
+		},
+		{
+			"pool_addresses", func(s *ServiceLoadBalancer) {
+				s.AddressPools = []ServiceLoadBalancerAddressPool{
+					{Name: "empty"},
+					{Name: "invalid", Addresses: []string{"10.0.0.1", "10.0.0.x-10.0.0.2", "10.0.0.1-fd00::1", "10.0.0.2-10.0.0.1"}},
+				}
+			},
+			[]string{
+				"serviceLoadBalancer.addressPools[0].addresses: Required value: at least one address is required",
+				`serviceLoadBalancer.addressPools[1].addresses[0]: Invalid value: "10.0.0.1": neither a CIDR nor an IP address range`,
+				`serviceLoadBalancer.addressPools[1].addresses[1]: Invalid value: "10.0.0.x-10.0.0.2": invalid start of IP address range`,
+				`serviceLoadBalancer.addressPools[1].addresses[2]: Invalid value: "10.0.0.1-fd00::1": IP address range mixes IPv4 and IPv6`,
+				`serviceLoadBalancer.addressPools[1].addresses[3]: Invalid value: "10.0.0.2-10.0.0.1": end of IP address range is before its start`,
+			},
+		},
+		{
+			"metalLB", func(s *ServiceLoadBalancer) {
+				s.MetalLB.SpeakerImage = nil
+				s.MetalLB.ImagePullPolicy = "Sometimes"
+			},
+			[]string{
+				"serviceLoadBalancer.metalLB.speakerImage: Required value: image must be set",
+				`serviceLoadBalancer.metalLB.imagePullPolicy: Unsupported value: "Sometimes"`,
+			},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			s := DefaultServiceLoadBalancer()
+			test.modify(s)
+			errs := s.Validate(path)
+			if assert.Len(t, errs, len(test.errs)) {
+				for i, err := range errs {
+					assert.ErrorContains(t, err, test.errs[i])
+				}
+			}
+		})
+	}
+}
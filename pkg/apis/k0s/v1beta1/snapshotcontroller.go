@@ -0,0 +1,128 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
+var _ Validateable = (*SnapshotControllerSpec)(nil)
+
+// SnapshotControllerSpec defines the configuration options related to the
+// CSI snapshot controller cluster component, which adds volume snapshot
+// support to CSI drivers.
+type SnapshotControllerSpec struct {
+	// enabled indicates if the snapshot controller and the volume snapshot
+	// CRDs should be deployed.
+	// Default: false
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// image specifies the OCI image that's being used for the snapshot
+	// controller.
+	// +optional
+	Image *ImageSpec `json:"image,omitempty"`
+
+	// imagePullPolicy specifies the pull policy being used for the snapshot
+	// controller. Defaults to the default image pull policy.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+}
+
+// DefaultSnapshotControllerSpec returns the default snapshot controller
+// configuration.
+func DefaultSnapshotControllerSpec() *SnapshotControllerSpec {
+	var s SnapshotControllerSpec
+	s.setDefaults()
+	return &s
+}
+
+var _ json.Unmarshaler = (*SnapshotControllerSpec)(nil)
+
+func (s *SnapshotControllerSpec) UnmarshalJSON(data []byte) error {
+	type snapshotControllerSpec SnapshotControllerSpec
+	if err := json.Unmarshal(data, (*snapshotControllerSpec)(s)); err != nil {
+		return err
+	}
+
+	s.setDefaults()
+
+	return nil
+}
+
+func (s *SnapshotControllerSpec) setDefaults() {
+	if s.Image == nil {
+		s.Image = DefaultSnapshotControllerImage()
+	} else {
+		if s.Image.Image == "" {
+			s.Image.Image = constant.SnapshotControllerImage
+		}
+		if s.Image.Version == "" {
+			s.Image.Version = constant.SnapshotControllerImageVersion
+		}
+	}
+}
+
+// Validate implements [Validateable].
+func (s *SnapshotControllerSpec) Validate() (errs []error) {
+	if s == nil {
+		return
+	}
+
+	image := field.NewPath("image")
+	if s.Image == nil {
+		errs = append(errs, field.Required(image, "image must be set"))
+	} else {
+		for _, err := range s.Image.Validate(image) {
+			errs = append(errs, err)
+		}
+	}
+
+	switch s.ImagePullPolicy {
+	case corev1.PullAlways, corev1.PullNever, corev1.PullIfNotPresent, "":
+		break
+	default:
+		errs = append(errs, field.NotSupported(
+			field.NewPath("imagePullPolicy"), s.ImagePullPolicy, []string{
+				string(corev1.PullAlways),
+				string(corev1.PullNever),
+				string(corev1.PullIfNotPresent),
+			},
+		))
+	}
+
+	return
+}
+
+func (s *SnapshotControllerSpec) IsEnabled() bool {
+	return s != nil && s.Enabled
+}
+
+// DefaultSnapshotControllerImage returns the default image spec to use for
+// the snapshot controller.
+func DefaultSnapshotControllerImage() *ImageSpec {
+	return &ImageSpec{
+		Image:   constant.SnapshotControllerImage,
+		Version: constant.SnapshotControllerImageVersion,
+	}
+}
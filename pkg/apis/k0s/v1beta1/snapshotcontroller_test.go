@@ -0,0 +1,67 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshotControllerSpec_Unmarshal(t *testing.T) {
+	yamlData := `
+apiVersion: k0s.k0sproject.io/v1beta1
+kind: ClusterConfig
+metadata:
+  name: foobar
+spec:
+  images:
+    repository: example.com
+  snapshotController:
+    enabled: true
+`
+
+	c, err := ConfigFromString(yamlData)
+	require.NoError(t, err)
+	require.Nil(t, c.Validate())
+
+	snapshotController := c.Spec.SnapshotController
+	require.NotNil(t, snapshotController)
+	assert.True(t, snapshotController.IsEnabled())
+	require.NotNil(t, snapshotController.Image)
+	assert.Equal(t, "example.com/sig-storage/snapshot-controller", snapshotController.Image.Image)
+	assert.Equal(t, DefaultSnapshotControllerImage().Version, snapshotController.Image.Version)
+}
+
+func TestSnapshotControllerSpec_Validate(t *testing.T) {
+	assert.Empty(t, (*SnapshotControllerSpec)(nil).Validate())
+	assert.Empty(t, DefaultSnapshotControllerSpec().Validate())
+
+	s := DefaultSnapshotControllerSpec()
+	s.ImagePullPolicy = "Sometimes"
+	errs := s.Validate()
+	if assert.Len(t, errs, 1) {
+		assert.ErrorContains(t, errs[0], `imagePullPolicy: Unsupported value: "Sometimes"`)
+	}
+
+	s.Image = nil
+	errs = s.Validate()
+	if assert.Len(t, errs, 2) {
+		assert.ErrorContains(t, errs[0], "image: Required value: image must be set")
+	}
+}
@@ -204,12 +204,12 @@ func (e *EtcdConfig) IsTLSEnabled() bool {
 }
 
 // GetCaFilePath returns the host path to a file with CA certificate if external cluster has configured all TLS properties,
-// otherwise it returns the host path to a default CA certificate in a given certDir directory.
+// otherwise it returns the host path to the trust bundle of the default CA in a given certDir directory.
 func (e *EtcdConfig) GetCaFilePath(certDir string) string {
 	if e.IsExternalClusterUsed() && e.ExternalCluster.hasAllTLSPropertiesDefined() {
 		return e.ExternalCluster.CaFile
 	}
-	return filepath.Join(certDir, "ca.crt")
+	return filepath.Join(certDir, "ca-bundle.crt")
 }
 
 // GetCertFilePath returns the host path to a file with a client certificate if external cluster has configured all TLS properties,
@@ -16,11 +16,51 @@ limitations under the License.
 
 package v1beta1
 
+import (
+	"golang.org/x/exp/slices"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+)
+
 var _ Validateable = (*ClusterTelemetry)(nil)
 
 // ClusterTelemetry holds telemetry related settings
 type ClusterTelemetry struct {
 	Enabled bool `json:"enabled"`
+
+	// The categories of data that are sent. Defaults to all of them.
+	// +optional
+	Categories []TelemetryCategory `json:"categories,omitempty"`
+}
+
+// TelemetryCategory is a category of telemetry data.
+// +kubebuilder:validation:Enum=cluster;clusterID;nodes;host;custom
+type TelemetryCategory string
+
+const (
+	// TelemetryCluster is the storage type, the number of nodes and the
+	// cluster's total CPU and memory capacity.
+	TelemetryCluster TelemetryCategory = "cluster"
+	// TelemetryClusterID are the UID of the kube-system namespace and the
+	// controller's machine ID. Without them, every submission is anonymous.
+	TelemetryClusterID TelemetryCategory = "clusterID"
+	// TelemetryNodes are the OS, architecture, capacity and container runtime
+	// of each node.
+	TelemetryNodes TelemetryCategory = "nodes"
+	// TelemetryHost are the OS, CPU and memory of the controller that sends
+	// the data, and whether it uses an HTTP proxy.
+	TelemetryHost TelemetryCategory = "host"
+	// TelemetryCustom is the data of the k0s-telemetry ConfigMap in the
+	// kube-system namespace.
+	TelemetryCustom TelemetryCategory = "custom"
+)
+
+// TelemetryCategories are all the categories of telemetry data.
+var TelemetryCategories = []TelemetryCategory{
+	TelemetryCluster,
+	TelemetryClusterID,
+	TelemetryNodes,
+	TelemetryHost,
+	TelemetryCustom,
 }
 
 // DefaultClusterTelemetry default settings
@@ -30,7 +70,27 @@ func DefaultClusterTelemetry() *ClusterTelemetry {
 	}
 }
 
-// Validate stub for Validateable interface
-func (c *ClusterTelemetry) Validate() []error {
-	return nil
+// Validate implements [Validateable].
+func (c *ClusterTelemetry) Validate() (errs []error) {
+	if c == nil {
+		return nil
+	}
+
+	supported := make([]string, len(TelemetryCategories))
+	for i, category := range TelemetryCategories {
+		supported[i] = string(category)
+	}
+
+	path := field.NewPath("categories")
+	for i, category := range c.Categories {
+		if !slices.Contains(TelemetryCategories, category) {
+			errs = append(errs, field.NotSupported(path.Index(i), category, supported))
+		}
+	}
+	return errs
+}
+
+// Includes returns true if data of the given category is sent.
+func (c *ClusterTelemetry) Includes(category TelemetryCategory) bool {
+	return c == nil || len(c.Categories) == 0 || slices.Contains(c.Categories, category)
 }
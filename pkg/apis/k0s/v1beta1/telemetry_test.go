@@ -0,0 +1,39 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterTelemetry_Categories(t *testing.T) {
+	var telemetry *ClusterTelemetry
+	assert.True(t, telemetry.Includes(TelemetryNodes), "all categories should be included by default")
+
+	telemetry = &ClusterTelemetry{Enabled: true, Categories: []TelemetryCategory{TelemetryCluster}}
+	assert.Empty(t, telemetry.Validate())
+	assert.True(t, telemetry.Includes(TelemetryCluster))
+	assert.False(t, telemetry.Includes(TelemetryNodes))
+
+	telemetry.Categories = append(telemetry.Categories, "hostname")
+	errs := telemetry.Validate()
+	if assert.Len(t, errs, 1) {
+		assert.ErrorContains(t, errs[0], `categories[1]: Unsupported value: "hostname"`)
+	}
+}
@@ -19,10 +19,31 @@ package v1beta1
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	kubeletv1beta1 "k8s.io/kubelet/config/v1beta1"
+
+	"go.uber.org/multierr"
+	"golang.org/x/exp/slices"
+	kjson "sigs.k8s.io/json"
 )
 
 var _ Validateable = (*WorkerProfiles)(nil)
 
+const (
+	// DefaultShutdownGracePeriod is the default time for which kubelet delays
+	// node shutdowns, so that pods can be terminated gracefully.
+	DefaultShutdownGracePeriod = 30 * time.Second
+	// DefaultShutdownGracePeriodCriticalPods is the default part of the
+	// shutdown grace period that's reserved for terminating critical pods.
+	DefaultShutdownGracePeriodCriticalPods = 10 * time.Second
+)
+
 // WorkerProfiles profiles collection
 type WorkerProfiles []WorkerProfile
 
@@ -31,7 +52,7 @@ func (wps WorkerProfiles) Validate() []error {
 	var errors []error
 	for _, p := range wps {
 		if err := p.Validate(); err != nil {
-			errors = append(errors, err)
+			errors = append(errors, fmt.Errorf("profile %q: %w", p.Name, err))
 		}
 	}
 	return errors
@@ -41,8 +62,69 @@ func (wps WorkerProfiles) Validate() []error {
 type WorkerProfile struct {
 	// String; name to use as profile selector for the worker process
 	Name string `json:"name"`
+
+	// Kubelet feature gates of this profile. They take precedence over the
+	// cluster-wide feature gates.
+	// +optional
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	// Resources reserved for system daemons, keyed by resource name.
+	// +optional
+	SystemReserved map[string]string `json:"systemReserved,omitempty"`
+
+	// Resources reserved for Kubernetes system daemons, keyed by resource
+	// name.
+	// +optional
+	KubeReserved map[string]string `json:"kubeReserved,omitempty"`
+
+	// Hard eviction thresholds, keyed by eviction signal.
+	// +optional
+	EvictionHard map[string]string `json:"evictionHard,omitempty"`
+
+	// Soft eviction thresholds, keyed by eviction signal. Each of them needs
+	// a grace period.
+	// +optional
+	EvictionSoft map[string]string `json:"evictionSoft,omitempty"`
+
+	// Grace periods of the soft eviction thresholds, keyed by eviction
+	// signal.
+	// +optional
+	EvictionSoftGracePeriod map[string]string `json:"evictionSoftGracePeriod,omitempty"`
+
+	// The maximum number of pods that can run on a worker.
+	// +optional
+	MaxPods *int32 `json:"maxPods,omitempty"`
+
+	// The time for which kubelet delays node shutdowns, so that pods can be
+	// terminated gracefully. Zero disables graceful node shutdown. Defaults to
+	// 30s on Linux.
+	// +optional
+	ShutdownGracePeriod *metav1.Duration `json:"shutdownGracePeriod,omitempty"`
+
+	// The part of the shutdown grace period that's reserved for terminating
+	// critical pods. Defaults to 10s on Linux.
+	// +optional
+	ShutdownGracePeriodCriticalPods *metav1.Duration `json:"shutdownGracePeriodCriticalPods,omitempty"`
+
+	// The sysctls and kernel modules that are ensured on the workers.
+	// +optional
+	Kernel *KernelSettings `json:"kernel,omitempty"`
+
+	// Enables SELinux support in the containerd and kubelet that are managed
+	// by k0s.
+	// Defaults to enabled on hosts where SELinux is in enforcing mode.
+	// +optional
+	SELinux *bool `json:"selinux,omitempty"`
+
 	// Worker Mapping object
-	Config json.RawMessage `json:"values"`
+	Config json.RawMessage `json:"values,omitempty"`
+
+	// A strategic merge patch that's applied on top of the generated kubelet
+	// configuration, after the values and all other fields of this profile.
+	// Nested objects and maps are merged, lists are replaced. Keys can be
+	// removed by setting them to null.
+	// +optional
+	KubeletConfigPatch json.RawMessage `json:"kubeletConfigPatch,omitempty"`
 }
 
 var lockedFields = map[string]struct{}{
@@ -53,8 +135,34 @@ var lockedFields = map[string]struct{}{
 	"staticPodURL":  {},
 }
 
+// The resources that can be reserved for system daemons.
+var reservableResources = []string{"cpu", "memory", "ephemeral-storage", "pid"}
+
+// The signals that eviction thresholds can be defined for.
+var evictionSignals = []string{
+	"memory.available",
+	"allocatableMemory.available",
+	"nodefs.available",
+	"nodefs.inodesFree",
+	"imagefs.available",
+	"imagefs.inodesFree",
+	"pid.available",
+}
+
 // Validate validates instance
 func (wp *WorkerProfile) Validate() error {
+	if errs := wp.validateFields(); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+
+	if err := wp.validateKubeletConfigPatch(); err != nil {
+		return err
+	}
+
+	if len(wp.Config) == 0 {
+		return nil
+	}
+
 	var parsed map[string]interface{}
 
 	err := json.Unmarshal(wp.Config, &parsed)
@@ -67,5 +175,211 @@ func (wp *WorkerProfile) Validate() error {
 			return fmt.Errorf("field `%s` is prohibited to override in worker profile", field)
 		}
 	}
+
+	for field, isSet := range map[string]bool{
+		"featureGates":                    wp.FeatureGates != nil,
+		"systemReserved":                  wp.SystemReserved != nil,
+		"kubeReserved":                    wp.KubeReserved != nil,
+		"evictionHard":                    wp.EvictionHard != nil,
+		"evictionSoft":                    wp.EvictionSoft != nil,
+		"evictionSoftGracePeriod":         wp.EvictionSoftGracePeriod != nil,
+		"maxPods":                         wp.MaxPods != nil,
+		"shutdownGracePeriod":             wp.ShutdownGracePeriod != nil,
+		"shutdownGracePeriodCriticalPods": wp.ShutdownGracePeriodCriticalPods != nil,
+	} {
+		if _, found := parsed[field]; found && isSet {
+			return fmt.Errorf("field `%s` is set in both the worker profile and its values", field)
+		}
+	}
+
+	var kubeletConfig kubeletv1beta1.KubeletConfiguration
+	return wp.DecodeKubeletConfiguration(&kubeletConfig)
+}
+
+func (wp *WorkerProfile) validateFields() (errs field.ErrorList) {
+	for name, enabled := range wp.FeatureGates {
+		if name == "" {
+			errs = append(errs, field.Invalid(field.NewPath("featureGates"), name, "feature gate must have name"))
+		} else if err := validateKubernetesFeatureGate(name, enabled); err != nil {
+			errs = append(errs, field.Invalid(field.NewPath("featureGates").Key(name), enabled, err.Error()))
+		}
+	}
+
+	for path, reserved := range map[*field.Path]map[string]string{
+		field.NewPath("systemReserved"): wp.SystemReserved,
+		field.NewPath("kubeReserved"):   wp.KubeReserved,
+	} {
+		for name, value := range reserved {
+			path := path.Key(name)
+			if !slices.Contains(reservableResources, name) {
+				errs = append(errs, field.NotSupported(path, name, reservableResources))
+			} else if quantity, err := resource.ParseQuantity(value); err != nil {
+				errs = append(errs, field.Invalid(path, value, err.Error()))
+			} else if quantity.Sign() < 0 {
+				errs = append(errs, field.Invalid(path, value, "must not be negative"))
+			}
+		}
+	}
+
+	for path, thresholds := range map[*field.Path]map[string]string{
+		field.NewPath("evictionHard"): wp.EvictionHard,
+		field.NewPath("evictionSoft"): wp.EvictionSoft,
+	} {
+		for signal, value := range thresholds {
+			path := path.Key(signal)
+			if !slices.Contains(evictionSignals, signal) {
+				errs = append(errs, field.NotSupported(path, signal, evictionSignals))
+			} else if err := validateEvictionThreshold(value); err != nil {
+				errs = append(errs, field.Invalid(path, value, err.Error()))
+			}
+		}
+	}
+
+	path := field.NewPath("evictionSoftGracePeriod")
+	for signal, value := range wp.EvictionSoftGracePeriod {
+		if _, ok := wp.EvictionSoft[signal]; !ok {
+			errs = append(errs, field.Invalid(path.Key(signal), value, "no soft eviction threshold defined for this signal"))
+		} else if gracePeriod, err := time.ParseDuration(value); err != nil {
+			errs = append(errs, field.Invalid(path.Key(signal), value, err.Error()))
+		} else if gracePeriod <= 0 {
+			errs = append(errs, field.Invalid(path.Key(signal), value, "must be positive"))
+		}
+	}
+	for signal := range wp.EvictionSoft {
+		if _, ok := wp.EvictionSoftGracePeriod[signal]; !ok {
+			errs = append(errs, field.Required(path.Key(signal), "soft eviction thresholds need a grace period"))
+		}
+	}
+
+	if wp.MaxPods != nil && *wp.MaxPods <= 0 {
+		errs = append(errs, field.Invalid(field.NewPath("maxPods"), *wp.MaxPods, "must be positive"))
+	}
+
+	gracePeriod, criticalPodsGracePeriod := wp.ShutdownGracePeriods()
+	if gracePeriod < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("shutdownGracePeriod"), gracePeriod.String(), "must not be negative"))
+	}
+	if criticalPodsGracePeriod < 0 {
+		errs = append(errs, field.Invalid(field.NewPath("shutdownGracePeriodCriticalPods"), criticalPodsGracePeriod.String(), "must not be negative"))
+	} else if criticalPodsGracePeriod > gracePeriod {
+		errs = append(errs, field.Invalid(field.NewPath("shutdownGracePeriodCriticalPods"), criticalPodsGracePeriod.String(), "must not be greater than the shutdown grace period"))
+	}
+
+	errs = append(errs, wp.Kernel.Validate(field.NewPath("kernel"))...)
+
+	return errs
+}
+
+// ShutdownGracePeriods returns the shutdown grace periods of the profile,
+// falling back to the defaults for the ones that aren't set. The default grace
+// period for critical pods is capped by the shutdown grace period.
+func (wp *WorkerProfile) ShutdownGracePeriods() (gracePeriod, criticalPodsGracePeriod time.Duration) {
+	gracePeriod = DefaultShutdownGracePeriod
+	if wp.ShutdownGracePeriod != nil {
+		gracePeriod = wp.ShutdownGracePeriod.Duration
+	}
+
+	if wp.ShutdownGracePeriodCriticalPods != nil {
+		criticalPodsGracePeriod = wp.ShutdownGracePeriodCriticalPods.Duration
+	} else if gracePeriod > DefaultShutdownGracePeriodCriticalPods {
+		criticalPodsGracePeriod = DefaultShutdownGracePeriodCriticalPods
+	} else if gracePeriod > 0 {
+		criticalPodsGracePeriod = gracePeriod
+	}
+
+	return gracePeriod, criticalPodsGracePeriod
+}
+
+// validateEvictionThreshold checks if the given value is either a percentage
+// or a resource quantity.
+func validateEvictionThreshold(value string) error {
+	if percentage, isPercentage := strings.CutSuffix(value, "%"); isPercentage {
+		p, err := strconv.ParseFloat(percentage, 64)
+		if err != nil {
+			return fmt.Errorf("invalid percentage: %w", err)
+		}
+		if p <= 0 || p > 100 {
+			return fmt.Errorf("percentage must be greater than 0%% and at most 100%%")
+		}
+		return nil
+	}
+
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return err
+	}
+	if quantity.Sign() < 0 {
+		return fmt.Errorf("must not be negative")
+	}
+	return nil
+}
+
+// DecodeKubeletConfiguration decodes the profile's values into the given
+// kubelet configuration, overriding the fields that are set in the profile.
+// Values that aren't part of the kubelet configuration schema are rejected.
+func (wp *WorkerProfile) DecodeKubeletConfiguration(into *kubeletv1beta1.KubeletConfiguration) error {
+	if len(wp.Config) == 0 {
+		return nil
+	}
+
+	strictErrs, err := kjson.UnmarshalStrict(wp.Config, into)
+	if err != nil {
+		return fmt.Errorf("values: %w", err)
+	}
+	if len(strictErrs) > 0 {
+		return fmt.Errorf("values: %w", multierr.Combine(strictErrs...))
+	}
+	return nil
+}
+
+// validateKubeletConfigPatch checks that the kubelet configuration patch is an
+// object that conforms to the kubelet configuration schema, once its patch
+// directives are removed, and that it doesn't patch any locked fields.
+func (wp *WorkerProfile) validateKubeletConfigPatch() error {
+	if len(wp.KubeletConfigPatch) == 0 {
+		return nil
+	}
+
+	var patch map[string]any
+	if err := json.Unmarshal(wp.KubeletConfigPatch, &patch); err != nil {
+		return fmt.Errorf("kubeletConfigPatch: %w", err)
+	}
+	for field := range patch {
+		if _, found := lockedFields[field]; found {
+			return fmt.Errorf("field `%s` is prohibited to patch in worker profile", field)
+		}
+	}
+
+	data, err := json.Marshal(removePatchDirectives(patch))
+	if err != nil {
+		return fmt.Errorf("kubeletConfigPatch: %w", err)
+	}
+	strictErrs, err := kjson.UnmarshalStrict(data, &kubeletv1beta1.KubeletConfiguration{})
+	if err != nil {
+		return fmt.Errorf("kubeletConfigPatch: %w", err)
+	}
+	if len(strictErrs) > 0 {
+		return fmt.Errorf("kubeletConfigPatch: %w", multierr.Combine(strictErrs...))
+	}
 	return nil
 }
+
+// removePatchDirectives removes the strategic merge patch directives, i.e. all
+// keys starting with "$", from the given decoded JSON value.
+func removePatchDirectives(value any) any {
+	switch value := value.(type) {
+	case map[string]any:
+		for key, nested := range value {
+			if strings.HasPrefix(key, "$") {
+				delete(value, key)
+			} else {
+				value[key] = removePatchDirectives(nested)
+			}
+		}
+	case []any:
+		for i, nested := range value {
+			value[i] = removePatchDirectives(nested)
+		}
+	}
+	return value
+}
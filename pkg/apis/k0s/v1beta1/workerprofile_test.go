@@ -19,6 +19,9 @@ package v1beta1
 import (
 	"encoding/json"
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -70,6 +73,46 @@ func TestWorkerProfile(t *testing.T) {
 				},
 				valid: false,
 			},
+			{
+				name: "Kubelet configuration fields",
+				spec: map[string]interface{}{
+					"volumePluginDir": "/var/libexec/k0s/kubelet-plugins/volume/exec",
+					"evictionHard": map[string]interface{}{
+						"memory.available": "500Mi",
+					},
+				},
+				valid: true,
+			},
+			{
+				name: "Unknown field",
+				spec: map[string]interface{}{
+					"volumePluginDirectory": "/var/libexec/k0s/kubelet-plugins/volume/exec",
+				},
+				valid: false,
+			},
+			{
+				name: "Wrongly capitalized field",
+				spec: map[string]interface{}{
+					"VolumePluginDir": "/var/libexec/k0s/kubelet-plugins/volume/exec",
+				},
+				valid: false,
+			},
+			{
+				name: "Unknown nested field",
+				spec: map[string]interface{}{
+					"authentication": map[string]interface{}{
+						"anonymous": map[string]interface{}{"enable": true},
+					},
+				},
+				valid: false,
+			},
+			{
+				name: "Invalid field type",
+				spec: map[string]interface{}{
+					"maxPods": "many",
+				},
+				valid: false,
+			},
 		}
 
 		for _, tc := range cases {
@@ -84,4 +127,195 @@ func TestWorkerProfile(t *testing.T) {
 			})
 		}
 	})
+
+	t.Run("worker_profile_validation_errors", func(t *testing.T) {
+		profiles := WorkerProfiles{{
+			Name:   "valid",
+			Config: []byte(`{"maxPods": 42}`),
+		}, {
+			Name:   "typos",
+			Config: []byte(`{"maxPod": 42, "authentication": {"anonymous": {"enable": true}}}`),
+		}}
+
+		errs := profiles.Validate()
+		if assert.Len(t, errs, 1) {
+			assert.ErrorContains(t, errs[0], `profile "typos": values: `)
+			assert.ErrorContains(t, errs[0], `unknown field "maxPod"`)
+			assert.ErrorContains(t, errs[0], `unknown field "authentication.anonymous.enable"`)
+		}
+	})
+	t.Run("worker_profile_fields_validation", func(t *testing.T) {
+		maxPods := func(maxPods int32) *int32 { return &maxPods }
+
+		cases := []struct {
+			name    string
+			profile WorkerProfile
+			err     string
+		}{
+			{
+				name: "Valid fields",
+				profile: WorkerProfile{
+					FeatureGates:            map[string]bool{"GracefulNodeShutdown": true},
+					SystemReserved:          map[string]string{"memory": "1Gi", "pid": "1000"},
+					KubeReserved:            map[string]string{"cpu": "500m", "ephemeral-storage": "1Gi"},
+					EvictionHard:            map[string]string{"memory.available": "500Mi", "nodefs.available": "10%"},
+					EvictionSoft:            map[string]string{"memory.available": "1Gi"},
+					EvictionSoftGracePeriod: map[string]string{"memory.available": "1m30s"},
+					MaxPods:                 maxPods(42),
+					Config:                  json.RawMessage(`{"volumePluginDir": "/var/libexec/k0s/kubelet-plugins/volume/exec"}`),
+				},
+			},
+			{
+				name:    "Unknown feature gate",
+				profile: WorkerProfile{FeatureGates: map[string]bool{"GracefulNodeShutdownn": true}},
+				err:     `featureGates[GracefulNodeShutdownn]: Invalid value: true: unknown feature gate "GracefulNodeShutdownn"`,
+			},
+			{
+				name:    "Feature gate locked to default",
+				profile: WorkerProfile{FeatureGates: map[string]bool{"CPUManager": false}},
+				err:     `featureGates[CPUManager]: Invalid value: false: feature gate "CPUManager" is locked to true`,
+			},
+			{
+				name:    "Unsupported reserved resource",
+				profile: WorkerProfile{SystemReserved: map[string]string{"gpu": "1"}},
+				err:     `systemReserved[gpu]: Unsupported value: "gpu"`,
+			},
+			{
+				name:    "Invalid reserved quantity",
+				profile: WorkerProfile{KubeReserved: map[string]string{"memory": "lots"}},
+				err:     `kubeReserved[memory]: Invalid value: "lots"`,
+			},
+			{
+				name:    "Unsupported eviction signal",
+				profile: WorkerProfile{EvictionHard: map[string]string{"memory.free": "1Gi"}},
+				err:     `evictionHard[memory.free]: Unsupported value: "memory.free"`,
+			},
+			{
+				name:    "Invalid eviction percentage",
+				profile: WorkerProfile{EvictionHard: map[string]string{"memory.available": "120%"}},
+				err:     `evictionHard[memory.available]: Invalid value: "120%": percentage must be greater than 0% and at most 100%`,
+			},
+			{
+				name:    "Soft eviction without grace period",
+				profile: WorkerProfile{EvictionSoft: map[string]string{"memory.available": "1Gi"}},
+				err:     `evictionSoftGracePeriod[memory.available]: Required value: soft eviction thresholds need a grace period`,
+			},
+			{
+				name: "Grace period without soft eviction",
+				profile: WorkerProfile{
+					EvictionSoft:            map[string]string{"memory.available": "1Gi"},
+					EvictionSoftGracePeriod: map[string]string{"memory.available": "1m", "nodefs.available": "1m"},
+				},
+				err: `evictionSoftGracePeriod[nodefs.available]: Invalid value: "1m": no soft eviction threshold defined for this signal`,
+			},
+			{
+				name: "Invalid grace period",
+				profile: WorkerProfile{
+					EvictionSoft:            map[string]string{"memory.available": "1Gi"},
+					EvictionSoftGracePeriod: map[string]string{"memory.available": "a while"},
+				},
+				err: `evictionSoftGracePeriod[memory.available]: Invalid value: "a while"`,
+			},
+			{
+				name:    "Non-positive maxPods",
+				profile: WorkerProfile{MaxPods: maxPods(0)},
+				err:     `maxPods: Invalid value: 0: must be positive`,
+			},
+			{
+				name:    "Negative shutdown grace period",
+				profile: WorkerProfile{ShutdownGracePeriod: &metav1.Duration{Duration: -1 * time.Second}},
+				err:     `shutdownGracePeriod: Invalid value: "-1s": must not be negative`,
+			},
+			{
+				name: "Critical pods shutdown grace period too long",
+				profile: WorkerProfile{
+					ShutdownGracePeriod:             &metav1.Duration{Duration: 30 * time.Second},
+					ShutdownGracePeriodCriticalPods: &metav1.Duration{Duration: 40 * time.Second},
+				},
+				err: `shutdownGracePeriodCriticalPods: Invalid value: "40s": must not be greater than the shutdown grace period`,
+			},
+			{
+				name: "Kernel settings",
+				profile: WorkerProfile{Kernel: &KernelSettings{
+					Sysctls: map[string]string{"net.ipv4.ip_local_port_range": "1024 65535", "net/ipv4/conf/eth0.1/rp_filter": "2"},
+					Modules: []string{"nf_nat", "ip_vs-rr"},
+				}},
+			},
+			{
+				name:    "Invalid sysctl name",
+				profile: WorkerProfile{Kernel: &KernelSettings{Sysctls: map[string]string{"../../etc/passwd": "1"}}},
+				err:     `kernel.sysctls[../../etc/passwd]: Invalid value: "../../etc/passwd": not a valid sysctl name`,
+			},
+			{
+				name:    "Empty sysctl value",
+				profile: WorkerProfile{Kernel: &KernelSettings{Sysctls: map[string]string{"vm.swappiness": " "}}},
+				err:     `kernel.sysctls[vm.swappiness]: Required value`,
+			},
+			{
+				name:    "Invalid module name",
+				profile: WorkerProfile{Kernel: &KernelSettings{Modules: []string{"nf_nat", "../evil"}}},
+				err:     `kernel.modules[1]: Invalid value: "../evil": not a valid kernel module name`,
+			},
+			{
+				name: "Field set twice",
+				profile: WorkerProfile{
+					MaxPods: maxPods(42),
+					Config:  json.RawMessage(`{"maxPods": 110}`),
+				},
+				err: "field `maxPods` is set in both the worker profile and its values",
+			},
+			{
+				name: "Kubelet config patch",
+				profile: WorkerProfile{
+					EvictionHard:       map[string]string{"memory.available": "500Mi"},
+					KubeletConfigPatch: json.RawMessage(`{"evictionHard": {"nodefs.available": "5%", "imagefs.available": null}, "authentication": {"$patch": "replace", "anonymous": {"enabled": false}}}`),
+				},
+			},
+			{
+				name:    "Kubelet config patch not an object",
+				profile: WorkerProfile{KubeletConfigPatch: json.RawMessage(`["maxPods", 42]`)},
+				err:     "kubeletConfigPatch: json: cannot unmarshal array",
+			},
+			{
+				name:    "Kubelet config patch with unknown field",
+				profile: WorkerProfile{KubeletConfigPatch: json.RawMessage(`{"authentication": {"anonymous": {"enable": true}}}`)},
+				err:     `kubeletConfigPatch: unknown field "authentication.anonymous.enable"`,
+			},
+			{
+				name:    "Kubelet config patch of locked field",
+				profile: WorkerProfile{KubeletConfigPatch: json.RawMessage(`{"clusterDNS": ["10.0.0.10"]}`)},
+				err:     "field `clusterDNS` is prohibited to patch in worker profile",
+			},
+		}
+
+		for _, tc := range cases {
+			t.Run(tc.name, func(t *testing.T) {
+				err := tc.profile.Validate()
+				if tc.err == "" {
+					assert.NoError(t, err)
+				} else {
+					assert.ErrorContains(t, err, tc.err)
+				}
+			})
+		}
+	})
+	t.Run("shutdown_grace_periods", func(t *testing.T) {
+		for _, tc := range []struct {
+			name                  string
+			gracePeriod, critical *metav1.Duration
+			expected              [2]time.Duration
+		}{
+			{"defaults", nil, nil, [2]time.Duration{30 * time.Second, 10 * time.Second}},
+			{"disabled", &metav1.Duration{}, nil, [2]time.Duration{0, 0}},
+			{"short", &metav1.Duration{Duration: 5 * time.Second}, nil, [2]time.Duration{5 * time.Second, 5 * time.Second}},
+			{"long", &metav1.Duration{Duration: 2 * time.Minute}, nil, [2]time.Duration{2 * time.Minute, 10 * time.Second}},
+			{"critical", nil, &metav1.Duration{Duration: 20 * time.Second}, [2]time.Duration{30 * time.Second, 20 * time.Second}},
+		} {
+			t.Run(tc.name, func(t *testing.T) {
+				profile := WorkerProfile{ShutdownGracePeriod: tc.gracePeriod, ShutdownGracePeriodCriticalPods: tc.critical}
+				gracePeriod, critical := profile.ShutdownGracePeriods()
+				assert.Equal(t, tc.expected, [2]time.Duration{gracePeriod, critical})
+			})
+		}
+	})
 }
@@ -23,7 +23,10 @@ package v1beta1
 
 import (
 	"encoding/json"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -41,6 +44,26 @@ func (in *APISpec) DeepCopyInto(out *APISpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.OIDC != nil {
+		in, out := &in.OIDC, &out.OIDC
+		*out = new(OIDC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		*out = new(Audit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Admission != nil {
+		in, out := &in.Admission, &out.Admission
+		*out = new(Admission)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(Encryption)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APISpec.
@@ -53,6 +76,139 @@ func (in *APISpec) DeepCopy() *APISpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Admission) DeepCopyInto(out *Admission) {
+	*out = *in
+	if in.EnablePlugins != nil {
+		in, out := &in.EnablePlugins, &out.EnablePlugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisablePlugins != nil {
+		in, out := &in.DisablePlugins, &out.DisablePlugins
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]AdmissionPlugin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Admission.
+func (in *Admission) DeepCopy() *Admission {
+	if in == nil {
+		return nil
+	}
+	out := new(Admission)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdmissionPlugin) DeepCopyInto(out *AdmissionPlugin) {
+	*out = *in
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdmissionPlugin.
+func (in *AdmissionPlugin) DeepCopy() *AdmissionPlugin {
+	if in == nil {
+		return nil
+	}
+	out := new(AdmissionPlugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplierSpec) DeepCopyInto(out *ApplierSpec) {
+	*out = *in
+	out.DebounceInterval = in.DebounceInterval
+	if in.IgnorePatterns != nil {
+		in, out := &in.IgnorePatterns, &out.IgnorePatterns
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplierSpec.
+func (in *ApplierSpec) DeepCopy() *ApplierSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplierSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Audit) DeepCopyInto(out *Audit) {
+	*out = *in
+	if in.Log != nil {
+		in, out := &in.Log, &out.Log
+		*out = new(AuditLog)
+		**out = **in
+	}
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(AuditWebhook)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Audit.
+func (in *Audit) DeepCopy() *Audit {
+	if in == nil {
+		return nil
+	}
+	out := new(Audit)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditLog) DeepCopyInto(out *AuditLog) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditLog.
+func (in *AuditLog) DeepCopy() *AuditLog {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditLog)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuditWebhook) DeepCopyInto(out *AuditWebhook) {
+	*out = *in
+	if in.InitialBackoff != nil {
+		in, out := &in.InitialBackoff, &out.InitialBackoff
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditWebhook.
+func (in *AuditWebhook) DeepCopy() *AuditWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CaResponse) DeepCopyInto(out *CaResponse) {
 	*out = *in
@@ -76,6 +232,21 @@ func (in *CaResponse) DeepCopyInto(out *CaResponse) {
 		*out = make([]byte, len(*in))
 		copy(*out, *in)
 	}
+	if in.NextKey != nil {
+		in, out := &in.NextKey, &out.NextKey
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextCert != nil {
+		in, out := &in.NextCert, &out.NextCert
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
+	if in.PreviousCert != nil {
+		in, out := &in.PreviousCert, &out.PreviousCert
+		*out = make([]byte, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaResponse.
@@ -91,6 +262,11 @@ func (in *CaResponse) DeepCopy() *CaResponse {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Calico) DeepCopyInto(out *Calico) {
 	*out = *in
+	if in.BGPPeers != nil {
+		in, out := &in.BGPPeers, &out.BGPPeers
+		*out = make([]CalicoBGPPeer, len(*in))
+		copy(*out, *in)
+	}
 	if in.EnvVars != nil {
 		in, out := &in.EnvVars, &out.EnvVars
 		*out = make(map[string]string, len(*in))
@@ -98,6 +274,11 @@ func (in *Calico) DeepCopyInto(out *Calico) {
 			(*out)[key] = val
 		}
 	}
+	if in.Felix != nil {
+		in, out := &in.Felix, &out.Felix
+		*out = new(CalicoFelix)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Calico.
@@ -110,12 +291,65 @@ func (in *Calico) DeepCopy() *Calico {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CASpec) DeepCopyInto(out *CASpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CASpec.
+func (in *CASpec) DeepCopy() *CASpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CASpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoBGPPeer) DeepCopyInto(out *CalicoBGPPeer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoBGPPeer.
+func (in *CalicoBGPPeer) DeepCopy() *CalicoBGPPeer {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoBGPPeer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CalicoFelix) DeepCopyInto(out *CalicoFelix) {
+	*out = *in
+	if in.PrometheusMetricsEnabled != nil {
+		in, out := &in.PrometheusMetricsEnabled, &out.PrometheusMetricsEnabled
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoFelix.
+func (in *CalicoFelix) DeepCopy() *CalicoFelix {
+	if in == nil {
+		return nil
+	}
+	out := new(CalicoFelix)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CalicoImageSpec) DeepCopyInto(out *CalicoImageSpec) {
 	*out = *in
 	out.CNI = in.CNI
 	out.Node = in.Node
 	out.KubeControllers = in.KubeControllers
+	out.CNIWindows = in.CNIWindows
+	out.NodeWindows = in.NodeWindows
+	out.KubeProxyWindows = in.KubeProxyWindows
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CalicoImageSpec.
@@ -131,6 +365,21 @@ func (in *CalicoImageSpec) DeepCopy() *CalicoImageSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Chart) DeepCopyInto(out *Chart) {
 	*out = *in
+	if in.Atomic != nil {
+		in, out := &in.Atomic, &out.Atomic
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Wait != nil {
+		in, out := &in.Wait, &out.Wait
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]ChartDependency, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Chart.
@@ -143,12 +392,56 @@ func (in *Chart) DeepCopy() *Chart {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChartDependency) DeepCopyInto(out *ChartDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartDependency.
+func (in *ChartDependency) DeepCopy() *ChartDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudProviderExtension) DeepCopyInto(out *CloudProviderExtension) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageSpec)
+		**out = **in
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudProviderExtension.
+func (in *CloudProviderExtension) DeepCopy() *CloudProviderExtension {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudProviderExtension)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in ChartsSettings) DeepCopyInto(out *ChartsSettings) {
 	{
 		in := &in
 		*out = make(ChartsSettings, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -162,6 +455,76 @@ func (in ChartsSettings) DeepCopy() ChartsSettings {
 	return *out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateProviderSpec) DeepCopyInto(out *CertificateProviderSpec) {
+	*out = *in
+	if in.Components != nil {
+		in, out := &in.Components, &out.Components
+		*out = make([]CertificateProviderComponent, len(*in))
+		copy(*out, *in)
+	}
+	if in.Vault != nil {
+		in, out := &in.Vault, &out.Vault
+		*out = new(VaultProviderSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateProviderSpec.
+func (in *CertificateProviderSpec) DeepCopy() *CertificateProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificatesSpec) DeepCopyInto(out *CertificatesSpec) {
+	*out = *in
+	if in.CA != nil {
+		in, out := &in.CA, &out.CA
+		*out = new(CASpec)
+		**out = **in
+	}
+	if in.CAValidity != nil {
+		in, out := &in.CAValidity, &out.CAValidity
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Validity != nil {
+		in, out := &in.Validity, &out.Validity
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.AdminKubeconfigValidity != nil {
+		in, out := &in.AdminKubeconfigValidity, &out.AdminKubeconfigValidity
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.SANs != nil {
+		in, out := &in.SANs, &out.SANs
+		*out = new(ComponentSANs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Provider != nil {
+		in, out := &in.Provider, &out.Provider
+		*out = new(CertificateProviderSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificatesSpec.
+func (in *CertificatesSpec) DeepCopy() *CertificatesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificatesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterConfig) DeepCopyInto(out *ClusterConfig) {
 	*out = *in
@@ -172,7 +535,7 @@ func (in *ClusterConfig) DeepCopyInto(out *ClusterConfig) {
 		*out = new(ClusterSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConfig.
@@ -228,6 +591,18 @@ func (in *ClusterConfigList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterConfigStatus) DeepCopyInto(out *ClusterConfigStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ComponentErrors != nil {
+		in, out := &in.ComponentErrors, &out.ComponentErrors
+		*out = make([]ComponentError, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterConfigStatus.
@@ -253,6 +628,16 @@ func (in *ClusterExtensions) DeepCopyInto(out *ClusterExtensions) {
 		*out = new(HelmExtensions)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(IngressExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloudProvider != nil {
+		in, out := &in.CloudProvider, &out.CloudProvider
+		*out = new(CloudProviderExtension)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterExtensions.
@@ -325,7 +710,7 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	if in.Telemetry != nil {
 		in, out := &in.Telemetry, &out.Telemetry
 		*out = new(ClusterTelemetry)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.Install != nil {
 		in, out := &in.Install, &out.Install
@@ -345,7 +730,7 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 	if in.Konnectivity != nil {
 		in, out := &in.Konnectivity, &out.Konnectivity
 		*out = new(KonnectivitySpec)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.FeatureGates != nil {
 		in, out := &in.FeatureGates, &out.FeatureGates
@@ -354,21 +739,102 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
-func (in *ClusterSpec) DeepCopy() *ClusterSpec {
-	if in == nil {
-		return nil
+	if in.Applier != nil {
+		in, out := &in.Applier, &out.Applier
+		*out = new(ApplierSpec)
+		(*in).DeepCopyInto(*out)
 	}
-	out := new(ClusterSpec)
-	in.DeepCopyInto(out)
-	return out
+	if in.Certificates != nil {
+		in, out := &in.Certificates, &out.Certificates
+		*out = new(CertificatesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Prober != nil {
+		in, out := &in.Prober, &out.Prober
+		*out = new(ProberSpec)
+		**out = **in
+	}
+	if in.LeaderElection != nil {
+		in, out := &in.LeaderElection, &out.LeaderElection
+		*out = new(LeaderElectionSpec)
+		**out = **in
+	}
+	if in.EventForwarding != nil {
+		in, out := &in.EventForwarding, &out.EventForwarding
+		*out = new(EventForwardingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricsScraper != nil {
+		in, out := &in.MetricsScraper, &out.MetricsScraper
+		*out = new(MetricsScraperSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricsServer != nil {
+		in, out := &in.MetricsServer, &out.MetricsServer
+		*out = new(MetricsServerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SnapshotController != nil {
+		in, out := &in.SnapshotController, &out.SnapshotController
+		*out = new(SnapshotControllerSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
+func (in *ClusterSpec) DeepCopy() *ClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterStatusResponse) DeepCopyInto(out *ClusterStatusResponse) {
+	*out = *in
+	if in.Controllers != nil {
+		in, out := &in.Controllers, &out.Controllers
+		*out = make([]ControllerStatusResponse, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Leaders != nil {
+		in, out := &in.Leaders, &out.Leaders
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeStatusResponse, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterStatusResponse.
+func (in *ClusterStatusResponse) DeepCopy() *ClusterStatusResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterStatusResponse)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterTelemetry) DeepCopyInto(out *ClusterTelemetry) {
 	*out = *in
+	if in.Categories != nil {
+		in, out := &in.Categories, &out.Categories
+		*out = make([]TelemetryCategory, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterTelemetry.
@@ -381,6 +847,51 @@ func (in *ClusterTelemetry) DeepCopy() *ClusterTelemetry {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentError) DeepCopyInto(out *ComponentError) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentError.
+func (in *ComponentError) DeepCopy() *ComponentError {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentError)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ComponentSANs) DeepCopyInto(out *ComponentSANs) {
+	*out = *in
+	if in.EtcdPeer != nil {
+		in, out := &in.EtcdPeer, &out.EtcdPeer
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EtcdServer != nil {
+		in, out := &in.EtcdServer, &out.EtcdServer
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Konnectivity != nil {
+		in, out := &in.Konnectivity, &out.Konnectivity
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ComponentSANs.
+func (in *ComponentSANs) DeepCopy() *ComponentSANs {
+	if in == nil {
+		return nil
+	}
+	out := new(ComponentSANs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ControllerManagerSpec) DeepCopyInto(out *ControllerManagerSpec) {
 	*out = *in
@@ -403,6 +914,162 @@ func (in *ControllerManagerSpec) DeepCopy() *ControllerManagerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerStatusResponse) DeepCopyInto(out *ControllerStatusResponse) {
+	*out = *in
+	if in.Leases != nil {
+		in, out := &in.Leases, &out.Leases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.EtcdHealthy != nil {
+		in, out := &in.EtcdHealthy, &out.EtcdHealthy
+		*out = new(bool)
+		**out = **in
+	}
+	if in.LastHeartbeatTime != nil {
+		in, out := &in.LastHeartbeatTime, &out.LastHeartbeatTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerStatusResponse.
+func (in *ControllerStatusResponse) DeepCopy() *ControllerStatusResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerStatusResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNS) DeepCopyInto(out *CoreDNS) {
+	*out = *in
+	if in.UpstreamResolvers != nil {
+		in, out := &in.UpstreamResolvers, &out.UpstreamResolvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StubDomains != nil {
+		in, out := &in.StubDomains, &out.StubDomains
+		*out = make(map[string][]string, len(*in))
+		for key, val := range *in {
+			var outVal []string
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = make([]string, len(*in))
+				copy(*out, *in)
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.Rewrites != nil {
+		in, out := &in.Rewrites, &out.Rewrites
+		*out = make([]CoreDNSRewrite, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServerBlocks != nil {
+		in, out := &in.ServerBlocks, &out.ServerBlocks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(CoreDNSReplicas)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]v1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PodDisruptionBudget != nil {
+		in, out := &in.PodDisruptionBudget, &out.PodDisruptionBudget
+		*out = new(CoreDNSPodDisruptionBudget)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNS.
+func (in *CoreDNS) DeepCopy() *CoreDNS {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSPodDisruptionBudget) DeepCopyInto(out *CoreDNSPodDisruptionBudget) {
+	*out = *in
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSPodDisruptionBudget.
+func (in *CoreDNSPodDisruptionBudget) DeepCopy() *CoreDNSPodDisruptionBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSPodDisruptionBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSReplicas) DeepCopyInto(out *CoreDNSReplicas) {
+	*out = *in
+	if in.Count != nil {
+		in, out := &in.Count, &out.Count
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSReplicas.
+func (in *CoreDNSReplicas) DeepCopy() *CoreDNSReplicas {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSReplicas)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CoreDNSRewrite) DeepCopyInto(out *CoreDNSRewrite) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CoreDNSRewrite.
+func (in *CoreDNSRewrite) DeepCopy() *CoreDNSRewrite {
+	if in == nil {
+		return nil
+	}
+	out := new(CoreDNSRewrite)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DualStack) DeepCopyInto(out *DualStack) {
 	*out = *in
@@ -418,6 +1085,31 @@ func (in *DualStack) DeepCopy() *DualStack {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Encryption) DeepCopyInto(out *Encryption) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.KMS != nil {
+		in, out := &in.KMS, &out.KMS
+		*out = new(KMS)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Encryption.
+func (in *Encryption) DeepCopy() *Encryption {
+	if in == nil {
+		return nil
+	}
+	out := new(Encryption)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvoyProxy) DeepCopyInto(out *EnvoyProxy) {
 	*out = *in
@@ -506,6 +1198,63 @@ func (in *EtcdResponse) DeepCopy() *EtcdResponse {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventForwardingSpec) DeepCopyInto(out *EventForwardingSpec) {
+	*out = *in
+	if in.Types != nil {
+		in, out := &in.Types, &out.Types
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Sinks != nil {
+		in, out := &in.Sinks, &out.Sinks
+		*out = make([]EventSink, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventForwardingSpec.
+func (in *EventForwardingSpec) DeepCopy() *EventForwardingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EventForwardingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EventSink) DeepCopyInto(out *EventSink) {
+	*out = *in
+	if in.Webhook != nil {
+		in, out := &in.Webhook, &out.Webhook
+		*out = new(WebhookEventSink)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Syslog != nil {
+		in, out := &in.Syslog, &out.Syslog
+		*out = new(SyslogEventSink)
+		**out = **in
+	}
+	if in.Slack != nil {
+		in, out := &in.Slack, &out.Slack
+		*out = new(SlackEventSink)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EventSink.
+func (in *EventSink) DeepCopy() *EventSink {
+	if in == nil {
+		return nil
+	}
+	out := new(EventSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExternalCluster) DeepCopyInto(out *ExternalCluster) {
 	*out = *in
@@ -578,7 +1327,9 @@ func (in *HelmExtensions) DeepCopyInto(out *HelmExtensions) {
 	if in.Charts != nil {
 		in, out := &in.Charts, &out.Charts
 		*out = make(ChartsSettings, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -607,6 +1358,26 @@ func (in *ImageSpec) DeepCopy() *ImageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressExtension) DeepCopyInto(out *IngressExtension) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressExtension.
+func (in *IngressExtension) DeepCopy() *IngressExtension {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressExtension)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstallSpec) DeepCopyInto(out *InstallSpec) {
 	*out = *in
@@ -628,28 +1399,112 @@ func (in *InstallSpec) DeepCopy() *InstallSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KineConfig) DeepCopyInto(out *KineConfig) {
+func (in *KMS) DeepCopyInto(out *KMS) {
 	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KineConfig.
-func (in *KineConfig) DeepCopy() *KineConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KMS.
+func (in *KMS) DeepCopy() *KMS {
 	if in == nil {
 		return nil
 	}
-	out := new(KineConfig)
+	out := new(KMS)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *KonnectivitySpec) DeepCopyInto(out *KonnectivitySpec) {
+func (in *KernelSettings) DeepCopyInto(out *KernelSettings) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KonnectivitySpec.
-func (in *KonnectivitySpec) DeepCopy() *KonnectivitySpec {
-	if in == nil {
+	if in.Sysctls != nil {
+		in, out := &in.Sysctls, &out.Sysctls
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Modules != nil {
+		in, out := &in.Modules, &out.Modules
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KernelSettings.
+func (in *KernelSettings) DeepCopy() *KernelSettings {
+	if in == nil {
+		return nil
+	}
+	out := new(KernelSettings)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KineConfig) DeepCopyInto(out *KineConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KineConfig.
+func (in *KineConfig) DeepCopy() *KineConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KineConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KonnectivityAgentSpec) DeepCopyInto(out *KonnectivityAgentSpec) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KonnectivityAgentSpec.
+func (in *KonnectivityAgentSpec) DeepCopy() *KonnectivityAgentSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(KonnectivityAgentSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KonnectivitySpec) DeepCopyInto(out *KonnectivitySpec) {
+	*out = *in
+	if in.Agent != nil {
+		in, out := &in.Agent, &out.Agent
+		*out = new(KonnectivityAgentSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KonnectivitySpec.
+func (in *KonnectivitySpec) DeepCopy() *KonnectivitySpec {
+	if in == nil {
 		return nil
 	}
 	out := new(KonnectivitySpec)
@@ -670,6 +1525,11 @@ func (in *KubeProxy) DeepCopyInto(out *KubeProxy) {
 		*out = new(KubeProxyIPVSConfiguration)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NFTables != nil {
+		in, out := &in.NFTables, &out.NFTables
+		*out = new(KubeProxyNFTablesConfiguration)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeProxy.
@@ -734,9 +1594,48 @@ func (in *KubeProxyIPVSConfiguration) DeepCopy() *KubeProxyIPVSConfiguration {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeProxyNFTablesConfiguration) DeepCopyInto(out *KubeProxyNFTablesConfiguration) {
+	*out = *in
+	if in.MasqueradeBit != nil {
+		in, out := &in.MasqueradeBit, &out.MasqueradeBit
+		*out = new(int32)
+		**out = **in
+	}
+	out.SyncPeriod = in.SyncPeriod
+	out.MinSyncPeriod = in.MinSyncPeriod
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeProxyNFTablesConfiguration.
+func (in *KubeProxyNFTablesConfiguration) DeepCopy() *KubeProxyNFTablesConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeProxyNFTablesConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeRouter) DeepCopyInto(out *KubeRouter) {
 	*out = *in
+	if in.BGPPeers != nil {
+		in, out := &in.BGPPeers, &out.BGPPeers
+		*out = make([]KubeRouterBGPPeer, len(*in))
+		copy(*out, *in)
+	}
+	if in.Advertise != nil {
+		in, out := &in.Advertise, &out.Advertise
+		*out = new(KubeRouterAdvertise)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExtraArgs != nil {
+		in, out := &in.ExtraArgs, &out.ExtraArgs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeRouter.
@@ -749,6 +1648,41 @@ func (in *KubeRouter) DeepCopy() *KubeRouter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeRouterAdvertise) DeepCopyInto(out *KubeRouterAdvertise) {
+	*out = *in
+	if in.PodCIDR != nil {
+		in, out := &in.PodCIDR, &out.PodCIDR
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeRouterAdvertise.
+func (in *KubeRouterAdvertise) DeepCopy() *KubeRouterAdvertise {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeRouterAdvertise)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubeRouterBGPPeer) DeepCopyInto(out *KubeRouterBGPPeer) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeRouterBGPPeer.
+func (in *KubeRouterBGPPeer) DeepCopy() *KubeRouterBGPPeer {
+	if in == nil {
+		return nil
+	}
+	out := new(KubeRouterBGPPeer)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KubeRouterImageSpec) DeepCopyInto(out *KubeRouterImageSpec) {
 	*out = *in
@@ -766,6 +1700,189 @@ func (in *KubeRouterImageSpec) DeepCopy() *KubeRouterImageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LeaderElectionSpec) DeepCopyInto(out *LeaderElectionSpec) {
+	*out = *in
+	out.LeaseDuration = in.LeaseDuration
+	out.RenewDeadline = in.RenewDeadline
+	out.RetryPeriod = in.RetryPeriod
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LeaderElectionSpec.
+func (in *LeaderElectionSpec) DeepCopy() *LeaderElectionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(LeaderElectionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetalLB) DeepCopyInto(out *MetalLB) {
+	*out = *in
+	if in.ControllerImage != nil {
+		in, out := &in.ControllerImage, &out.ControllerImage
+		*out = new(ImageSpec)
+		**out = **in
+	}
+	if in.SpeakerImage != nil {
+		in, out := &in.SpeakerImage, &out.SpeakerImage
+		*out = new(ImageSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetalLB.
+func (in *MetalLB) DeepCopy() *MetalLB {
+	if in == nil {
+		return nil
+	}
+	out := new(MetalLB)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricRelabeling) DeepCopyInto(out *MetricRelabeling) {
+	*out = *in
+	if in.SourceLabels != nil {
+		in, out := &in.SourceLabels, &out.SourceLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Separator != nil {
+		in, out := &in.Separator, &out.Separator
+		*out = new(string)
+		**out = **in
+	}
+	if in.Replacement != nil {
+		in, out := &in.Replacement, &out.Replacement
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricRelabeling.
+func (in *MetricRelabeling) DeepCopy() *MetricRelabeling {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricRelabeling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsRemoteWriteBasicAuth) DeepCopyInto(out *MetricsRemoteWriteBasicAuth) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsRemoteWriteBasicAuth.
+func (in *MetricsRemoteWriteBasicAuth) DeepCopy() *MetricsRemoteWriteBasicAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsRemoteWriteBasicAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsRemoteWriteSpec) DeepCopyInto(out *MetricsRemoteWriteSpec) {
+	*out = *in
+	if in.BasicAuth != nil {
+		in, out := &in.BasicAuth, &out.BasicAuth
+		*out = new(MetricsRemoteWriteBasicAuth)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsRemoteWriteSpec.
+func (in *MetricsRemoteWriteSpec) DeepCopy() *MetricsRemoteWriteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsRemoteWriteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsScraperJob) DeepCopyInto(out *MetricsScraperJob) {
+	*out = *in
+	if in.MetricRelabelings != nil {
+		in, out := &in.MetricRelabelings, &out.MetricRelabelings
+		*out = make([]MetricRelabeling, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsScraperJob.
+func (in *MetricsScraperJob) DeepCopy() *MetricsScraperJob {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsScraperJob)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsScraperSpec) DeepCopyInto(out *MetricsScraperSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = make([]MetricsScraperJob, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RemoteWrite != nil {
+		in, out := &in.RemoteWrite, &out.RemoteWrite
+		*out = new(MetricsRemoteWriteSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsScraperSpec.
+func (in *MetricsScraperSpec) DeepCopy() *MetricsScraperSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsScraperSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricsServerSpec) DeepCopyInto(out *MetricsServerSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MetricResolution != nil {
+		in, out := &in.MetricResolution, &out.MetricResolution
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricsServerSpec.
+func (in *MetricsServerSpec) DeepCopy() *MetricsServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricsServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Network) DeepCopyInto(out *Network) {
 	*out = *in
@@ -783,13 +1900,28 @@ func (in *Network) DeepCopyInto(out *Network) {
 	if in.KubeRouter != nil {
 		in, out := &in.KubeRouter, &out.KubeRouter
 		*out = new(KubeRouter)
-		**out = **in
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CoreDNS != nil {
+		in, out := &in.CoreDNS, &out.CoreDNS
+		*out = new(CoreDNS)
+		(*in).DeepCopyInto(*out)
 	}
 	if in.NodeLocalLoadBalancing != nil {
 		in, out := &in.NodeLocalLoadBalancing, &out.NodeLocalLoadBalancing
 		*out = new(NodeLocalLoadBalancing)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.NodeLocalDNSCache != nil {
+		in, out := &in.NodeLocalDNSCache, &out.NodeLocalDNSCache
+		*out = new(NodeLocalDNSCache)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceLoadBalancer != nil {
+		in, out := &in.ServiceLoadBalancer, &out.ServiceLoadBalancer
+		*out = new(ServiceLoadBalancer)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Network.
@@ -802,6 +1934,26 @@ func (in *Network) DeepCopy() *Network {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeLocalDNSCache) DeepCopyInto(out *NodeLocalDNSCache) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeLocalDNSCache.
+func (in *NodeLocalDNSCache) DeepCopy() *NodeLocalDNSCache {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeLocalDNSCache)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NodeLocalLoadBalancing) DeepCopyInto(out *NodeLocalLoadBalancing) {
 	*out = *in
@@ -822,6 +1974,76 @@ func (in *NodeLocalLoadBalancing) DeepCopy() *NodeLocalLoadBalancing {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeStatusResponse) DeepCopyInto(out *NodeStatusResponse) {
+	*out = *in
+	if in.UnhealthyComponents != nil {
+		in, out := &in.UnhealthyComponents, &out.UnhealthyComponents
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LastUpdateTime != nil {
+		in, out := &in.LastUpdateTime, &out.LastUpdateTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatusResponse.
+func (in *NodeStatusResponse) DeepCopy() *NodeStatusResponse {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeStatusResponse)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OIDC) DeepCopyInto(out *OIDC) {
+	*out = *in
+	if in.RequiredClaims != nil {
+		in, out := &in.RequiredClaims, &out.RequiredClaims
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SigningAlgs != nil {
+		in, out := &in.SigningAlgs, &out.SigningAlgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OIDC.
+func (in *OIDC) DeepCopy() *OIDC {
+	if in == nil {
+		return nil
+	}
+	out := new(OIDC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProberSpec) DeepCopyInto(out *ProberSpec) {
+	*out = *in
+	out.Interval = in.Interval
+	out.Timeout = in.Timeout
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProberSpec.
+func (in *ProberSpec) DeepCopy() *ProberSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProberSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in RepositoriesSettings) DeepCopyInto(out *RepositoriesSettings) {
 	{
@@ -878,6 +2100,93 @@ func (in *SchedulerSpec) DeepCopy() *SchedulerSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceLoadBalancer) DeepCopyInto(out *ServiceLoadBalancer) {
+	*out = *in
+	if in.AddressPools != nil {
+		in, out := &in.AddressPools, &out.AddressPools
+		*out = make([]ServiceLoadBalancerAddressPool, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MetalLB != nil {
+		in, out := &in.MetalLB, &out.MetalLB
+		*out = new(MetalLB)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLoadBalancer.
+func (in *ServiceLoadBalancer) DeepCopy() *ServiceLoadBalancer {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceLoadBalancer)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceLoadBalancerAddressPool) DeepCopyInto(out *ServiceLoadBalancerAddressPool) {
+	*out = *in
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AutoAssign != nil {
+		in, out := &in.AutoAssign, &out.AutoAssign
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceLoadBalancerAddressPool.
+func (in *ServiceLoadBalancerAddressPool) DeepCopy() *ServiceLoadBalancerAddressPool {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceLoadBalancerAddressPool)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlackEventSink) DeepCopyInto(out *SlackEventSink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlackEventSink.
+func (in *SlackEventSink) DeepCopy() *SlackEventSink {
+	if in == nil {
+		return nil
+	}
+	out := new(SlackEventSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SnapshotControllerSpec) DeepCopyInto(out *SnapshotControllerSpec) {
+	*out = *in
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(ImageSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SnapshotControllerSpec.
+func (in *SnapshotControllerSpec) DeepCopy() *SnapshotControllerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SnapshotControllerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StorageExtension) DeepCopyInto(out *StorageExtension) {
 	*out = *in
@@ -918,6 +2227,21 @@ func (in *StorageSpec) DeepCopy() *StorageSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyslogEventSink) DeepCopyInto(out *SyslogEventSink) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyslogEventSink.
+func (in *SyslogEventSink) DeepCopy() *SyslogEventSink {
+	if in == nil {
+		return nil
+	}
+	out := new(SyslogEventSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SystemUser) DeepCopyInto(out *SystemUser) {
 	*out = *in
@@ -933,14 +2257,123 @@ func (in *SystemUser) DeepCopy() *SystemUser {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultProviderSpec) DeepCopyInto(out *VaultProviderSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultProviderSpec.
+func (in *VaultProviderSpec) DeepCopy() *VaultProviderSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultProviderSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WebhookEventSink) DeepCopyInto(out *WebhookEventSink) {
+	*out = *in
+	if in.Headers != nil {
+		in, out := &in.Headers, &out.Headers
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WebhookEventSink.
+func (in *WebhookEventSink) DeepCopy() *WebhookEventSink {
+	if in == nil {
+		return nil
+	}
+	out := new(WebhookEventSink)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkerProfile) DeepCopyInto(out *WorkerProfile) {
 	*out = *in
+	if in.FeatureGates != nil {
+		in, out := &in.FeatureGates, &out.FeatureGates
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SystemReserved != nil {
+		in, out := &in.SystemReserved, &out.SystemReserved
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.KubeReserved != nil {
+		in, out := &in.KubeReserved, &out.KubeReserved
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EvictionHard != nil {
+		in, out := &in.EvictionHard, &out.EvictionHard
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EvictionSoft != nil {
+		in, out := &in.EvictionSoft, &out.EvictionSoft
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.EvictionSoftGracePeriod != nil {
+		in, out := &in.EvictionSoftGracePeriod, &out.EvictionSoftGracePeriod
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.MaxPods != nil {
+		in, out := &in.MaxPods, &out.MaxPods
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ShutdownGracePeriod != nil {
+		in, out := &in.ShutdownGracePeriod, &out.ShutdownGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ShutdownGracePeriodCriticalPods != nil {
+		in, out := &in.ShutdownGracePeriodCriticalPods, &out.ShutdownGracePeriodCriticalPods
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.Kernel != nil {
+		in, out := &in.Kernel, &out.Kernel
+		*out = new(KernelSettings)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SELinux != nil {
+		in, out := &in.SELinux, &out.SELinux
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Config != nil {
 		in, out := &in.Config, &out.Config
 		*out = make(json.RawMessage, len(*in))
 		copy(*out, *in)
 	}
+	if in.KubeletConfigPatch != nil {
+		in, out := &in.KubeletConfigPatch, &out.KubeletConfigPatch
+		*out = make(json.RawMessage, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkerProfile.
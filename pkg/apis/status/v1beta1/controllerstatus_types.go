@@ -0,0 +1,85 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ControllerStatusNamespace is the namespace in which the ControllerStatus
+	// objects of the controllers are maintained.
+	ControllerStatusNamespace = "kube-system"
+
+	// HealthyCondition indicates whether all of the controller's components
+	// passed their most recent health probes.
+	HealthyCondition = "Healthy"
+)
+
+// ControllerState defines the observed state of a k0s controller
+type ControllerState struct {
+	// Version is the k0s version that the controller is running.
+	Version string `json:"version,omitempty"`
+	// Role is the role of the controller, i.e. controller or
+	// controller+worker.
+	Role string `json:"role,omitempty"`
+	// CABundleVersion is the version of the cluster CA bundle that the
+	// controller trusts.
+	CABundleVersion string `json:"caBundleVersion,omitempty"`
+	// LastUpdateTime is the time at which the state has been published.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+	// Components are the results of the most recent health probes of the
+	// controller's components.
+	// +listType=map
+	// +listMapKey=name
+	Components []ComponentState `json:"components,omitempty"`
+	// Conditions of the controller.
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// ComponentState is the result of the most recent health probe of a component
+type ComponentState struct {
+	// Name of the component.
+	Name string `json:"name"`
+	// Healthy indicates whether the component passed the health probe.
+	Healthy bool `json:"healthy"`
+	// Message is the error of the health probe, if it failed.
+	Message string `json:"message,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Healthy",type="string",JSONPath=".status.conditions[?(@.type==\"Healthy\")].status"
+// +kubebuilder:printcolumn:name="Version",type="string",JSONPath=".status.version"
+// +kubebuilder:printcolumn:name="Last Update",type="date",JSONPath=".status.lastUpdateTime"
+// ControllerStatus reflects the state of the components of a k0s controller
+type ControllerStatus struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ControllerState `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// ControllerStatusList contains a list of ControllerStatus
+type ControllerStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ControllerStatus `json:"items"`
+}
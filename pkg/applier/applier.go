@@ -40,10 +40,21 @@ import (
 // manifestFilePattern is the glob pattern that all applicable manifest files need to match.
 const manifestFilePattern = "*.yaml"
 
+// SkipFileName is the name of a marker file that pauses a stack. As long as it
+// exists in a stack directory, the stack is neither applied nor pruned.
+const SkipFileName = ".skip"
+
+// kustomizationFileNames are the file names that mark a stack directory as a
+// kustomization. Such stacks are rendered using kustomize before being applied.
+var kustomizationFileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
 // Applier manages all the "static" manifests and applies them on the k8s API
 type Applier struct {
 	Name string
 	Dir  string
+	// IgnorePatterns are the file name patterns of manifest files that won't
+	// be applied.
+	IgnorePatterns []string
 
 	log             *logrus.Entry
 	clientFactory   kubernetes.ClientFactoryInterface
@@ -52,6 +63,12 @@ type Applier struct {
 
 	restClientGetter resource.RESTClientGetter
 	resourceBuilder  *resource.Builder
+
+	// prunePolicy is the prune policy of the last applied stack config. It's
+	// retained so that it's still honored when the stack directory is deleted.
+	prunePolicy PrunePolicy
+	// conflicts is the conflict policy of the last applied stack config.
+	conflicts ConflictPolicy
 }
 
 // NewApplier creates new Applier
@@ -107,20 +124,49 @@ func (a *Applier) Apply(ctx context.Context) error {
 		return err
 	}
 
-	files, err := filepath.Glob(path.Join(a.Dir, manifestFilePattern))
-	if err != nil {
-		return err
+	if a.isSkipped() {
+		a.log.Infof("Skipping stack, %s file found", SkipFileName)
+		return nil
 	}
 
-	resources, err := a.parseFiles(files)
+	// Keep the previous config if the stack directory is being removed.
+	if _, err := os.Stat(a.Dir); err == nil {
+		config, err := readStackConfig(a.Dir)
+		if err != nil {
+			return err
+		}
+		a.prunePolicy = config.PrunePolicy
+		a.conflicts = config.Conflicts
+	}
+
+	var resources []*unstructured.Unstructured
+	if a.isKustomization() {
+		a.log.Debug("rendering kustomization")
+		resources, err = a.parseKustomization()
+	} else {
+		var files []string
+		files, err = filepath.Glob(path.Join(a.Dir, manifestFilePattern))
+		if err != nil {
+			return err
+		}
+		resources, err = a.parseFiles(manifestFiles(files, a.IgnorePatterns))
+	}
 	if err != nil {
+		a.updateStatus(ctx, &applyResult{err: err})
 		return err
 	}
+	result := applyResult{
+		revision:  stackRevision(resources),
+		resources: len(resources),
+	}
 	stack := Stack{
-		Name:      a.Name,
-		Resources: resources,
-		Client:    a.client,
-		Discovery: a.discoveryClient,
+		Name:        a.Name,
+		Resources:   resources,
+		Client:      a.client,
+		Discovery:   a.discoveryClient,
+		PrunePolicy: a.prunePolicy,
+
+		ForceConflicts: a.conflicts.forceConflicts(a.Name),
 	}
 	a.log.Debug("applying stack")
 	err = stack.Apply(ctx, true)
@@ -131,6 +177,9 @@ func (a *Applier) Apply(ctx context.Context) error {
 		a.log.Debug("successfully applied stack")
 	}
 
+	result.prunedResources, result.err = stack.prunedResources, err
+	a.updateStatus(ctx, &result)
+
 	return err
 }
 
@@ -141,19 +190,22 @@ func (a *Applier) Delete(ctx context.Context) error {
 		return err
 	}
 	stack := Stack{
-		Name:      a.Name,
-		Client:    a.client,
-		Discovery: a.discoveryClient,
+		Name:        a.Name,
+		Client:      a.client,
+		Discovery:   a.discoveryClient,
+		PrunePolicy: a.prunePolicy,
 	}
 	logrus.Debugf("about to delete a stack %s with empty apply", a.Name)
 	err = stack.Apply(ctx, true)
+	if err == nil {
+		a.deleteStatus(ctx)
+	}
 	return err
 }
 
 func (a *Applier) parseFiles(files []string) ([]*unstructured.Unstructured, error) {
-	var resources []*unstructured.Unstructured
 	if len(files) == 0 {
-		return resources, nil
+		return nil, nil
 	}
 
 	r := a.resourceBuilder.
@@ -168,15 +220,81 @@ func (a *Applier) parseFiles(files []string) ([]*unstructured.Unstructured, erro
 		}
 
 	}
+
+	return toUnstructured(objects), nil
+}
+
+// manifestFiles filters out the stack config file and all ignored files from
+// the given files.
+func manifestFiles(files []string, ignorePatterns []string) []string {
+	manifests := make([]string, 0, len(files))
+	for _, file := range files {
+		name := filepath.Base(file)
+		if name != StackConfigFileName && !isIgnored(name, ignorePatterns) {
+			manifests = append(manifests, file)
+		}
+	}
+	return manifests
+}
+
+// isIgnored checks if the given file name matches any of the ignore patterns.
+func isIgnored(name string, ignorePatterns []string) bool {
+	for _, pattern := range ignorePatterns {
+		if match, _ := filepath.Match(pattern, name); match {
+			return true
+		}
+	}
+	return false
+}
+
+// isSkipped returns true if the stack directory contains the skip file.
+func (a *Applier) isSkipped() bool {
+	_, err := os.Stat(filepath.Join(a.Dir, SkipFileName))
+	return err == nil
+}
+
+// isKustomization returns true if the stack directory contains a kustomization file.
+func (a *Applier) isKustomization() bool {
+	return isKustomizationDir(a.Dir)
+}
+
+// isKustomizationDir returns true if dir contains a kustomization file.
+func isKustomizationDir(dir string) bool {
+	for _, name := range kustomizationFileNames {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// parseKustomization renders the kustomization in the stack directory.
+func (a *Applier) parseKustomization() ([]*unstructured.Unstructured, error) {
+	// Use a dedicated builder, so that the rendered kustomization doesn't
+	// accumulate with the visitors of previous runs.
+	r := resource.NewBuilder(a.restClientGetter).
+		Unstructured().
+		Flatten().
+		FilenameParam(false, &resource.FilenameOptions{Kustomize: a.Dir}).
+		Do()
+
+	objects, err := r.Infos()
+	if err != nil {
+		return nil, fmt.Errorf("failed to render kustomization: %w", err)
+	}
+
+	return toUnstructured(objects), nil
+}
+
+func toUnstructured(objects []*resource.Info) []*unstructured.Unstructured {
+	var resources []*unstructured.Unstructured
 	for _, o := range objects {
 		item := o.Object.(*unstructured.Unstructured)
 		if item.GetAPIVersion() != "" && item.GetKind() != "" {
 			resources = append(resources, item)
-			o = nil
 		}
 	}
-
-	return resources, nil
+	return resources
 }
 
 type restClientGetter struct {
@@ -18,16 +18,26 @@ package applier
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
+	applierv1beta1 "github.com/k0sproject/k0s/pkg/apis/applier/v1beta1"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubetesting "k8s.io/client-go/testing"
 
 	kubeutil "github.com/k0sproject/k0s/internal/testutil"
 )
@@ -154,3 +164,281 @@ spec:
 	assert.Error(t, err)
 	assert.True(t, errors.IsNotFound(err))
 }
+
+func TestApplierRendersKustomization(t *testing.T) {
+	dir := t.TempDir()
+	kustomization := `
+apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+namespace: kube-system
+namePrefix: kustomized-
+commonLabels:
+  component: applier
+resources:
+  - configmap.yaml
+`
+	configMap := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: applier-test
+data:
+  foo: bar
+`
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("%s/kustomization.yaml", dir), []byte(kustomization), 0400))
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("%s/configmap.yaml", dir), []byte(configMap), 0400))
+
+	fakes := kubeutil.NewFakeClientFactory()
+	verbs := []string{"get", "list", "delete", "create"}
+	fakes.RawDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: corev1.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: verbs},
+			},
+		},
+	}
+
+	a := NewApplier(dir, fakes)
+
+	ctx := context.Background()
+	assert.NoError(t, a.Apply(ctx))
+	gv, _ := schema.ParseResourceArg("configmaps.v1.")
+	r, err := a.client.Resource(*gv).Namespace("kube-system").Get(ctx, "kustomized-applier-test", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "applier", r.GetLabels()["component"])
+	}
+	_, err = a.client.Resource(*gv).Namespace("kube-system").Get(ctx, "applier-test", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err), "the non-kustomized config map should not have been applied: %v", err)
+}
+
+func TestApplierUpdatesStackStatus(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "status-test")
+	require.NoError(t, os.Mkdir(dir, 0700))
+	configMap := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: applier-test
+  namespace: kube-system
+data:
+  foo: bar
+`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cm.yaml"), []byte(configMap), 0600))
+
+	fakes := kubeutil.NewFakeClientFactory()
+	verbs := []string{"get", "list", "delete", "create"}
+	fakes.RawDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: corev1.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: verbs},
+			},
+		},
+	}
+
+	a := NewApplier(dir, fakes)
+	ctx := context.Background()
+	getStatus := func() *applierv1beta1.StackStatus {
+		u, err := a.client.Resource(stackGVR).Namespace(applierv1beta1.StackNamespace).Get(ctx, "status-test", metav1.GetOptions{})
+		require.NoError(t, err)
+		var stack applierv1beta1.Stack
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &stack))
+		return &stack.Status
+	}
+
+	require.NoError(t, a.Apply(ctx))
+	status := getStatus()
+	assert.Equal(t, 1, status.Resources)
+	assert.NotEmpty(t, status.Revision)
+	assert.Empty(t, status.LastError)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, applierv1beta1.ReadyCondition))
+	revision := status.Revision
+
+	// Break the manifests and check that the error is reported, while the
+	// previous revision is retained.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cm.yaml"), []byte("kind: [ConfigMap"), 0600))
+	assert.Error(t, a.Apply(ctx))
+	status = getStatus()
+	assert.Equal(t, revision, status.Revision)
+	assert.NotEmpty(t, status.LastError)
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, applierv1beta1.ReadyCondition))
+
+	require.NoError(t, a.Delete(ctx))
+	_, err := a.client.Resource(stackGVR).Namespace(applierv1beta1.StackNamespace).Get(ctx, "status-test", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err), "stack status should have been deleted: %v", err)
+}
+
+func TestApplierOrphansPrunedResources(t *testing.T) {
+	dir := t.TempDir()
+	configMap := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: kube-system
+data:
+  foo: bar
+`
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("%s/%s", dir, StackConfigFileName), []byte("prunePolicy: orphan\n"), 0400))
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("%s/keep.yaml", dir), []byte(fmt.Sprintf(configMap, "keep")), 0400))
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("%s/orphan.yaml", dir), []byte(fmt.Sprintf(configMap, "orphan")), 0600))
+
+	fakes := kubeutil.NewFakeClientFactory()
+	verbs := []string{"get", "list", "delete", "create", "patch"}
+	fakes.RawDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: corev1.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: verbs},
+			},
+		},
+	}
+
+	a := NewApplier(dir, fakes)
+
+	ctx := context.Background()
+	assert.NoError(t, a.Apply(ctx))
+
+	assert.NoError(t, os.Remove(fmt.Sprintf("%s/orphan.yaml", dir)))
+	assert.NoError(t, a.Apply(ctx))
+
+	gv, _ := schema.ParseResourceArg("configmaps.v1.")
+	r, err := a.client.Resource(*gv).Namespace("kube-system").Get(ctx, "orphan", metav1.GetOptions{})
+	if assert.NoError(t, err, "the removed config map should have been orphaned") {
+		assert.NotContains(t, r.GetLabels(), NameLabel)
+		assert.NotContains(t, r.GetAnnotations(), LastConfigAnnotation)
+	}
+	r, err = a.client.Resource(*gv).Namespace("kube-system").Get(ctx, "keep", metav1.GetOptions{})
+	if assert.NoError(t, err) {
+		assert.Equal(t, a.Name, r.GetLabels()[NameLabel])
+	}
+}
+
+func TestApplierReportsApplyConflicts(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("%s/cm.yaml", dir), []byte(`
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: conflicting
+  namespace: kube-system
+data:
+  foo: bar
+`), 0400))
+
+	fakes := kubeutil.NewFakeClientFactory()
+	verbs := []string{"get", "list", "delete", "create", "patch"}
+	fakes.RawDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: corev1.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: verbs},
+			},
+		},
+	}
+	fakes.DynamicClient.(*dynamicfake.FakeDynamicClient).PrependReactor("patch", "configmaps", func(action kubetesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.NewConflict(schema.GroupResource{Resource: "configmaps"}, "conflicting", fmt.Errorf(`conflict with "kubectl-edit": .data.foo`))
+	})
+
+	a := NewApplier(dir, fakes)
+	err := a.Apply(context.Background())
+	assert.ErrorContains(t, err, "fields are managed by another field manager")
+	assert.True(t, errors.IsConflict(err))
+}
+
+func TestApplierHonorsSkipFileAndIgnorePatterns(t *testing.T) {
+	dir := t.TempDir()
+	configMap := `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: kube-system
+data:
+  foo: bar
+`
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("%s/applied.yaml", dir), []byte(fmt.Sprintf(configMap, "applied")), 0400))
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("%s/.ignored.yaml", dir), []byte(fmt.Sprintf(configMap, "ignored")), 0400))
+	assert.NoError(t, os.WriteFile(fmt.Sprintf("%s/%s", dir, SkipFileName), nil, 0400))
+
+	fakes := kubeutil.NewFakeClientFactory()
+	verbs := []string{"get", "list", "delete", "create", "patch"}
+	fakes.RawDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: corev1.SchemeGroupVersion.String(),
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Kind: "ConfigMap", Verbs: verbs},
+			},
+		},
+	}
+
+	a := NewApplier(dir, fakes)
+	a.IgnorePatterns = []string{".*"}
+
+	ctx := context.Background()
+	gv, _ := schema.ParseResourceArg("configmaps.v1.")
+
+	assert.NoError(t, a.Apply(ctx))
+	_, err := a.client.Resource(*gv).Namespace("kube-system").Get(ctx, "applied", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err), "skipped stack should not have been applied: %v", err)
+
+	assert.NoError(t, os.Remove(fmt.Sprintf("%s/%s", dir, SkipFileName)))
+	assert.NoError(t, a.Apply(ctx))
+	_, err = a.client.Resource(*gv).Namespace("kube-system").Get(ctx, "applied", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = a.client.Resource(*gv).Namespace("kube-system").Get(ctx, "ignored", metav1.GetOptions{})
+	assert.True(t, errors.IsNotFound(err), "ignored file should not have been applied: %v", err)
+}
+
+func TestClientSideApplyMigrationPatch(t *testing.T) {
+	resource := &unstructured.Unstructured{}
+	resource.SetAPIVersion("v1")
+	resource.SetKind("ConfigMap")
+	resource.SetName("migrated")
+	resource.SetResourceVersion("42")
+	resource.SetAnnotations(map[string]string{LastConfigAnnotation: "{}"})
+	resource.SetManagedFields([]metav1.ManagedFieldsEntry{{
+		Manager:    "k0s",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:foo":{}}}`)},
+	}, {
+		Manager:    "kubectl-edit",
+		Operation:  metav1.ManagedFieldsOperationUpdate,
+		APIVersion: "v1",
+		FieldsType: "FieldsV1",
+		FieldsV1:   &metav1.FieldsV1{Raw: []byte(`{"f:data":{"f:bar":{}}}`)},
+	}})
+
+	patchJSON, err := clientSideApplyMigrationPatch(resource, DefaultFieldManager)
+	require.NoError(t, err)
+
+	var patch []struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		Value json.RawMessage `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal(patchJSON, &patch))
+	require.Len(t, patch, 3)
+
+	assert.Equal(t, "/metadata/managedFields", patch[0].Path)
+	var managedFields []metav1.ManagedFieldsEntry
+	require.NoError(t, json.Unmarshal(patch[0].Value, &managedFields))
+	managers := map[string]metav1.ManagedFieldsOperationType{}
+	for _, entry := range managedFields {
+		managers[entry.Manager] = entry.Operation
+	}
+	assert.Equal(t, map[string]metav1.ManagedFieldsOperationType{
+		"k0s":          metav1.ManagedFieldsOperationApply,
+		"kubectl-edit": metav1.ManagedFieldsOperationUpdate,
+	}, managers)
+
+	assert.Equal(t, "replace", patch[1].Op)
+	assert.Equal(t, "/metadata/resourceVersion", patch[1].Path)
+	assert.JSONEq(t, `"42"`, string(patch[1].Value))
+
+	assert.Equal(t, "remove", patch[2].Op)
+	assert.Equal(t, "/metadata/annotations/k0s.k0sproject.io~1last-applied-configuration", patch[2].Path)
+}
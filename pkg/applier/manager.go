@@ -18,17 +18,23 @@ package applier
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
 	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/component/prober"
 	"github.com/k0sproject/k0s/pkg/constant"
 	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
 
 	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/sirupsen/logrus"
 )
 
@@ -36,18 +42,31 @@ import (
 type Manager struct {
 	K0sVars           constant.CfgVars
 	KubeClientFactory kubeutil.ClientFactoryInterface
+	// Config are the applier settings, the defaults are used if nil.
+	Config *v1beta1.ApplierSpec
 
 	// client               kubernetes.Interface
-	applier       Applier
-	bundlePath    string
-	cancelWatcher context.CancelFunc
-	log           *logrus.Entry
-	stacks        map[string]stack
+	applier        Applier
+	bundlePath     string
+	cancelWatchers context.CancelFunc
+	log            *logrus.Entry
+	stacksMu       sync.Mutex
+	stacks         map[string]stack
+	draining       bool
+	metrics        *applierMetrics
 
 	LeaderElector leaderelector.Interface
+	// StackLeaderElectors assigns stacks, by their directory name, to leader
+	// electors other than LeaderElector. Those stacks are applied by the
+	// controller holding the respective lease.
+	StackLeaderElectors map[string]leaderelector.Interface
+	*prober.EventEmitter
 }
 
-var _ manager.Component = (*Manager)(nil)
+var (
+	_ manager.Component = (*Manager)(nil)
+	_ manager.Drainer   = (*Manager)(nil)
+)
 
 type stack = struct {
 	context.CancelFunc
@@ -62,24 +81,58 @@ func (m *Manager) Init(ctx context.Context) error {
 	}
 	m.log = logrus.WithField("component", "applier-manager")
 	m.stacks = make(map[string]stack)
+	m.metrics = newApplierMetrics(nil)
+	if m.EventEmitter != nil {
+		m.metrics.emit = m.EmitWithPayload
+	}
+	if err := m.metrics.register(prometheus.DefaultRegisterer); err != nil {
+		return fmt.Errorf("failed to register applier metrics: %w", err)
+	}
 	m.bundlePath = m.K0sVars.ManifestsDir
 
 	m.applier = NewApplier(m.K0sVars.ManifestsDir, m.KubeClientFactory)
 
-	m.LeaderElector.AddAcquiredLeaseCallback(func() {
+	ctx, m.cancelWatchers = context.WithCancel(ctx)
+	m.watchWhileLeading(ctx, m.LeaderElector, func(stack string) bool {
+		_, assigned := m.StackLeaderElectors[stack]
+		return !assigned
+	})
+	for name, leaderElector := range m.StackLeaderElectors {
+		name := name
+		m.watchWhileLeading(ctx, leaderElector, func(stack string) bool {
+			return stack == name
+		})
+	}
+
+	return err
+}
+
+// watchWhileLeading runs a watcher for the stacks selected by includes as long
+// as the given leader elector holds its lease.
+func (m *Manager) watchWhileLeading(ctx context.Context, leaderElector leaderelector.Interface, includes func(stack string) bool) {
+	var cancelWatcher context.CancelFunc
+	leaderElector.AddAcquiredLeaseCallback(func() {
 		watcherCtx, cancel := context.WithCancel(ctx)
-		m.cancelWatcher = cancel
+		cancelWatcher = cancel
 		go func() {
-			_ = m.runWatchers(watcherCtx)
+			_ = m.runWatchers(watcherCtx, includes)
 		}()
 	})
-	m.LeaderElector.AddLostLeaseCallback(func() {
-		if m.cancelWatcher != nil {
-			m.cancelWatcher()
+	leaderElector.AddLostLeaseCallback(func() {
+		if cancelWatcher != nil {
+			cancelWatcher()
 		}
 	})
+}
 
-	return err
+// Healthy implements [prober.Healthz]. The applier is considered unhealthy as
+// long as there are stacks managed by k0s whose last apply attempt failed.
+// Failing user stacks are only reported via events and metrics.
+func (m *Manager) Healthy(context.Context) error {
+	if failing := m.metrics.failingStacks(isK0sStack); len(failing) > 0 {
+		return fmt.Errorf("failed to apply stacks: %s", strings.Join(failing, ", "))
+	}
+	return nil
 }
 
 // Run runs the Manager
@@ -89,13 +142,36 @@ func (m *Manager) Start(_ context.Context) error {
 
 // Stop stops the Manager
 func (m *Manager) Stop() error {
-	if m.cancelWatcher != nil {
-		m.cancelWatcher()
+	if m.cancelWatchers != nil {
+		m.cancelWatchers()
 	}
 	return nil
 }
 
-func (m *Manager) runWatchers(ctx context.Context) error {
+// Drain implements [manager.Drainer]. It stops applying new stacks and waits
+// for the in-flight applies of the existing ones to finish, so that they
+// aren't interrupted when the API server stops. All stacks are drained, even
+// if some of them fail to drain.
+func (m *Manager) Drain(ctx context.Context) error {
+	m.stacksMu.Lock()
+	m.draining = true
+	stacks := make([]stack, 0, len(m.stacks))
+	for _, stack := range m.stacks {
+		stacks = append(stacks, stack)
+	}
+	m.stacksMu.Unlock()
+
+	var errs []error
+	for _, stack := range stacks {
+		if err := stack.Drain(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("failed to drain stack %s: %w", stack.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runWatchers runs the stacks selected by includes until ctx is done.
+func (m *Manager) runWatchers(ctx context.Context, includes func(stack string) bool) error {
 	log := logrus.WithField("component", "applier-manager")
 
 	watcher, err := fsnotify.NewWatcher()
@@ -119,8 +195,24 @@ func (m *Manager) runWatchers(ctx context.Context) error {
 		return err
 	}
 
+	// Forget about the stacks when done, so that they're recreated once the
+	// lease is reacquired.
+	var names []string
+	defer func() {
+		m.stacksMu.Lock()
+		defer m.stacksMu.Unlock()
+		for _, name := range names {
+			delete(m.stacks, name)
+		}
+	}()
+	createStack := func(name string) {
+		if includes(path.Base(name)) && m.createStack(ctx, name) {
+			names = append(names, name)
+		}
+	}
+
 	for _, dir := range dirs {
-		m.createStack(ctx, path.Join(m.bundlePath, dir))
+		createStack(path.Join(m.bundlePath, dir))
 	}
 
 	for {
@@ -138,10 +230,12 @@ func (m *Manager) runWatchers(ctx context.Context) error {
 			switch event.Op {
 			case fsnotify.Create:
 				if dir.IsDirectory(event.Name) {
-					m.createStack(ctx, event.Name)
+					createStack(event.Name)
 				}
 			case fsnotify.Remove:
-				m.removeStack(ctx, event.Name)
+				if includes(path.Base(event.Name)) {
+					m.removeStack(ctx, event.Name)
+				}
 			}
 		case <-ctx.Done():
 			log.Info("manifest watcher done")
@@ -150,14 +244,24 @@ func (m *Manager) runWatchers(ctx context.Context) error {
 	}
 }
 
-func (m *Manager) createStack(ctx context.Context, name string) {
+// createStack starts to apply the stack with the given name. Returns false if
+// the stack already exists.
+func (m *Manager) createStack(ctx context.Context, name string) bool {
+	m.stacksMu.Lock()
+	defer m.stacksMu.Unlock()
+
 	// safeguard in case the fswatcher would trigger an event for an already existing stack
 	if _, ok := m.stacks[name]; ok {
-		return
+		return false
+	}
+	// no new stacks are applied once draining
+	if m.draining {
+		return false
 	}
 
 	stackCtx, cancelStack := context.WithCancel(ctx)
-	stack := stack{cancelStack, NewStackApplier(name, m.KubeClientFactory)}
+	stack := stack{cancelStack, NewStackApplier(name, m.KubeClientFactory, m.Config)}
+	stack.metrics = m.metrics
 	m.stacks[name] = stack
 
 	go func() {
@@ -177,10 +281,15 @@ func (m *Manager) createStack(ctx context.Context, name string) {
 			}
 		}
 	}()
+
+	return true
 }
 
 func (m *Manager) removeStack(ctx context.Context, name string) {
+	m.stacksMu.Lock()
 	stack, ok := m.stacks[name]
+	delete(m.stacks, name)
+	m.stacksMu.Unlock()
 	if !ok {
 		m.log.
 			WithField("path", name).
@@ -188,8 +297,8 @@ func (m *Manager) removeStack(ctx context.Context, name string) {
 		return
 	}
 
-	delete(m.stacks, name)
 	stack.CancelFunc()
+	m.metrics.remove(stack.name)
 
 	log := m.log.WithField("stack", name)
 	if err := stack.DeleteStack(ctx); err != nil {
@@ -34,6 +34,10 @@ const (
 
 	// LastConfigAnnotation defines the annotation to be used for last applied configs
 	LastConfigAnnotation = MetaPrefix + "/last-applied-configuration"
+
+	// PrunePolicyAnnotation defines the annotation to be used to override the
+	// stack's prune policy for individual resources
+	PrunePolicyAnnotation = MetaPrefix + "/prune-policy"
 )
 
 // Meta is a convenience wrapper for metav1.ObjectMeta.Labels and
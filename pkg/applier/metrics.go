@@ -0,0 +1,219 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StackMetrics are the apply statistics of a single stack.
+type StackMetrics struct {
+	// Applies is the total number of apply attempts.
+	Applies uint64 `json:"applies"`
+	// Failures is the total number of failed apply attempts.
+	Failures uint64 `json:"failures"`
+	// ConsecutiveFailures is the number of failed apply attempts since the
+	// last successful one.
+	ConsecutiveFailures uint64 `json:"consecutiveFailures"`
+	// LastApplyDuration is the time the last apply attempt took.
+	LastApplyDuration metav1.Duration `json:"lastApplyDuration"`
+	// LastError is the error of the last apply attempt, if it failed.
+	LastError string `json:"lastError,omitempty"`
+	// NextRetry is the time at which the next apply attempt is scheduled, if
+	// the last one failed.
+	NextRetry *metav1.Time `json:"nextRetry,omitempty"`
+}
+
+// stackMetricsEvent is the payload of the prober events emitted for each apply
+// attempt.
+type stackMetricsEvent struct {
+	Stack string `json:"stack"`
+	StackMetrics
+}
+
+// applierMetrics collects the apply statistics of all stacks. They're emitted
+// as prober events and exposed as Prometheus metrics. A nil *applierMetrics is
+// valid and doesn't record anything.
+type applierMetrics struct {
+	mu     sync.Mutex
+	stacks map[string]*StackMetrics
+	emit   func(message string, payload interface{})
+
+	applies             *prometheus.CounterVec
+	failures            *prometheus.CounterVec
+	consecutiveFailures *prometheus.GaugeVec
+	applyDuration       *prometheus.HistogramVec
+	nextRetry           *prometheus.GaugeVec
+}
+
+func newApplierMetrics(emit func(message string, payload interface{})) *applierMetrics {
+	const namespace, subsystem = "k0s", "applier"
+	labels := []string{"stack"}
+
+	return &applierMetrics{
+		stacks: make(map[string]*StackMetrics),
+		emit:   emit,
+
+		applies: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "stack_applies_total",
+			Help: "Total number of apply attempts per stack.",
+		}, labels),
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "stack_apply_failures_total",
+			Help: "Total number of failed apply attempts per stack.",
+		}, labels),
+		consecutiveFailures: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "stack_consecutive_failures",
+			Help: "Number of failed apply attempts per stack since the last successful one.",
+		}, labels),
+		applyDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name:    "stack_apply_duration_seconds",
+			Help:    "Duration of apply attempts per stack.",
+			Buckets: prometheus.ExponentialBuckets(0.1, 2, 10),
+		}, labels),
+		nextRetry: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace, Subsystem: subsystem,
+			Name: "stack_next_retry_timestamp_seconds",
+			Help: "Unix time of the next scheduled apply attempt per stack, zero if the last one succeeded.",
+		}, labels),
+	}
+}
+
+// register registers the Prometheus metrics with the given registerer.
+// Metrics that are already registered are reused.
+func (m *applierMetrics) register(registerer prometheus.Registerer) (err error) {
+	if m.applies, err = registerOrReuse(registerer, m.applies); err != nil {
+		return err
+	}
+	if m.failures, err = registerOrReuse(registerer, m.failures); err != nil {
+		return err
+	}
+	if m.consecutiveFailures, err = registerOrReuse(registerer, m.consecutiveFailures); err != nil {
+		return err
+	}
+	if m.applyDuration, err = registerOrReuse(registerer, m.applyDuration); err != nil {
+		return err
+	}
+	m.nextRetry, err = registerOrReuse(registerer, m.nextRetry)
+	return err
+}
+
+func registerOrReuse[C prometheus.Collector](registerer prometheus.Registerer, collector C) (C, error) {
+	err := registerer.Register(collector)
+	var alreadyRegistered prometheus.AlreadyRegisteredError
+	if errors.As(err, &alreadyRegistered) {
+		if existing, ok := alreadyRegistered.ExistingCollector.(C); ok {
+			return existing, nil
+		}
+	}
+	return collector, err
+}
+
+// record records an apply attempt of the given stack. For failed attempts,
+// nextRetry is the time of the next scheduled attempt.
+func (m *applierMetrics) record(stack string, duration time.Duration, err error, nextRetry time.Time) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	metrics, ok := m.stacks[stack]
+	if !ok {
+		metrics = &StackMetrics{}
+		m.stacks[stack] = metrics
+	}
+
+	metrics.Applies++
+	metrics.LastApplyDuration = metav1.Duration{Duration: duration}
+	if err == nil {
+		metrics.ConsecutiveFailures = 0
+		metrics.LastError = ""
+		metrics.NextRetry = nil
+	} else {
+		metrics.Failures++
+		metrics.ConsecutiveFailures++
+		metrics.LastError = err.Error()
+		metrics.NextRetry = &metav1.Time{Time: nextRetry}
+	}
+	event := stackMetricsEvent{stack, *metrics}
+	m.mu.Unlock()
+
+	m.applies.WithLabelValues(stack).Inc()
+	m.applyDuration.WithLabelValues(stack).Observe(duration.Seconds())
+	m.consecutiveFailures.WithLabelValues(stack).Set(float64(event.ConsecutiveFailures))
+	if err == nil {
+		m.nextRetry.WithLabelValues(stack).Set(0)
+	} else {
+		m.failures.WithLabelValues(stack).Inc()
+		m.nextRetry.WithLabelValues(stack).Set(float64(nextRetry.Unix()))
+	}
+
+	if m.emit != nil {
+		if err == nil {
+			m.emit("stack applied", event)
+		} else {
+			m.emit("stack apply failed", event)
+		}
+	}
+}
+
+// remove drops the statistics of the given stack.
+func (m *applierMetrics) remove(stack string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.stacks, stack)
+
+	for _, vec := range []interface{ DeleteLabelValues(...string) bool }{
+		m.applies, m.failures, m.consecutiveFailures, m.applyDuration, m.nextRetry,
+	} {
+		vec.DeleteLabelValues(stack)
+	}
+}
+
+// failingStacks returns the sorted names of all stacks whose last apply
+// attempt failed. If filter is given, only the matching stacks are returned.
+func (m *applierMetrics) failingStacks(filter func(stack string) bool) []string {
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var failing []string
+	for stack, metrics := range m.stacks {
+		if metrics.ConsecutiveFailures > 0 && (filter == nil || filter(stack)) {
+			failing = append(failing, stack)
+		}
+	}
+	sort.Strings(failing)
+	return failing
+}
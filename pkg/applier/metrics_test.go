@@ -0,0 +1,94 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplierMetrics(t *testing.T) {
+	var messages []string
+	var events []stackMetricsEvent
+	underTest := newApplierMetrics(func(message string, payload interface{}) {
+		messages = append(messages, message)
+		events = append(events, payload.(stackMetricsEvent))
+	})
+
+	nextRetry := time.Now().Add(time.Minute)
+	underTest.record("foo", time.Second, errors.New("boom"), nextRetry)
+	underTest.record("foo", time.Second, errors.New("boom"), nextRetry)
+	underTest.record("bar", time.Second, nil, time.Time{})
+
+	assert.Equal(t, []string{"stack apply failed", "stack apply failed", "stack applied"}, messages)
+	require.Len(t, events, 3)
+	assert.Equal(t, "foo", events[1].Stack)
+	assert.Equal(t, uint64(2), events[1].Applies)
+	assert.Equal(t, uint64(2), events[1].ConsecutiveFailures)
+	assert.Equal(t, "boom", events[1].LastError)
+	if assert.NotNil(t, events[1].NextRetry) {
+		assert.Equal(t, nextRetry, events[1].NextRetry.Time)
+	}
+	assert.Equal(t, []string{"foo"}, underTest.failingStacks(nil))
+
+	underTest.record("foo", time.Second, nil, time.Time{})
+	last := events[len(events)-1]
+	assert.Equal(t, uint64(3), last.Applies)
+	assert.Equal(t, uint64(2), last.Failures)
+	assert.Zero(t, last.ConsecutiveFailures)
+	assert.Empty(t, last.LastError)
+	assert.Nil(t, last.NextRetry)
+	assert.Empty(t, underTest.failingStacks(nil))
+
+	assert.Equal(t, 3.0, testutil.ToFloat64(underTest.applies.WithLabelValues("foo")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(underTest.failures.WithLabelValues("foo")))
+	assert.Zero(t, testutil.ToFloat64(underTest.consecutiveFailures.WithLabelValues("foo")))
+	assert.Zero(t, testutil.ToFloat64(underTest.nextRetry.WithLabelValues("foo")))
+
+	underTest.record("foo", time.Second, errors.New("boom"), nextRetry)
+	assert.Equal(t, 1.0, testutil.ToFloat64(underTest.consecutiveFailures.WithLabelValues("foo")))
+	assert.Equal(t, float64(nextRetry.Unix()), testutil.ToFloat64(underTest.nextRetry.WithLabelValues("foo")))
+	assert.Equal(t, []string{"foo"}, underTest.failingStacks(nil))
+	assert.Empty(t, underTest.failingStacks(func(stack string) bool { return stack != "foo" }))
+
+	underTest.remove("foo")
+	assert.Empty(t, underTest.failingStacks(nil))
+	assert.Equal(t, 1, testutil.CollectAndCount(underTest.applies), "only bar should be left")
+}
+
+func TestApplierMetrics_Register(t *testing.T) {
+	registry := prometheus.NewPedanticRegistry()
+	first, second := newApplierMetrics(nil), newApplierMetrics(nil)
+	require.NoError(t, first.register(registry))
+	require.NoError(t, second.register(registry), "already registered metrics should be reused")
+
+	second.record("foo", time.Second, nil, time.Time{})
+	assert.Equal(t, 1.0, testutil.ToFloat64(first.applies.WithLabelValues("foo")))
+}
+
+func TestApplierMetrics_Nil(t *testing.T) {
+	var underTest *applierMetrics
+	underTest.record("foo", time.Second, errors.New("boom"), time.Now())
+	underTest.remove("foo")
+	assert.Empty(t, underTest.failingStacks(nil))
+}
@@ -20,11 +20,14 @@ import (
 	"context"
 	"crypto/md5"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
-	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/exp/slices"
 	apiErrors "k8s.io/apimachinery/pkg/api/errors"
@@ -33,9 +36,11 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/util/csaupgrade"
 )
 
 // Stack is a k8s resource bundle
@@ -45,8 +50,18 @@ type Stack struct {
 	keepResources []string
 	Client        dynamic.Interface
 	Discovery     discovery.CachedDiscoveryInterface
-
-	log *logrus.Entry
+	// PrunePolicy controls what happens to resources that are pruned from
+	// the stack (default: delete)
+	PrunePolicy PrunePolicy
+	// FieldManager is the field manager used for server-side apply
+	// (default: k0s)
+	FieldManager string
+	// ForceConflicts makes server-side apply take over the ownership of
+	// fields that are managed by other field managers
+	ForceConflicts bool
+
+	log             *logrus.Entry
+	prunedResources int
 }
 
 // Apply applies stack resources by creating or updating the resources. If prune is requested,
@@ -81,31 +96,31 @@ func (s *Stack) Apply(ctx context.Context, prune bool) error {
 			drClient = s.Client.Resource(mapping.Resource)
 		}
 		serverResource, err := drClient.Get(ctx, resource.GetName(), metav1.GetOptions{})
-		if apiErrors.IsNotFound(err) {
-			_, err := drClient.Create(ctx, resource, metav1.CreateOptions{})
-			if err != nil {
-				return fmt.Errorf("cannot create resource %s: %s", resource.GetName(), err)
+		if err == nil && isClientSideApplied(serverResource) {
+			// Always apply migrated resources, so that the fields that have
+			// been taken over by the migration are reconciled.
+			if err := s.migrateClientSideApply(ctx, drClient, serverResource); err != nil {
+				return fmt.Errorf("can't migrate resource %s to server-side apply: %w", generateResourceID(*resource), err)
 			}
-		} else if err != nil {
-			return fmt.Errorf("unknown api error: %s", err)
-		} else { // The resource already exists, we need to update/patch it
+		} else if err == nil {
 			localChecksum := resource.GetAnnotations()[ChecksumAnnotation]
 			if serverResource.GetAnnotations()[ChecksumAnnotation] == localChecksum {
 				s.log.Debug("resource checksums match, no need to update")
 				s.keepResource(resource)
 				continue
 			}
-			if serverResource.GetAnnotations()[LastConfigAnnotation] == "" {
-				s.log.Debug("doing plain update as no last-config label present")
-				resource.SetResourceVersion(serverResource.GetResourceVersion())
-				_, err = drClient.Update(ctx, resource, metav1.UpdateOptions{})
-			} else {
-				s.log.Debug("patching resource")
-				err = s.patchResource(ctx, drClient, serverResource, resource)
-			}
-			if err != nil {
-				return fmt.Errorf("can't update resource:%v", err)
-			}
+		} else if !apiErrors.IsNotFound(err) {
+			return fmt.Errorf("unknown api error: %s", err)
+		}
+
+		_, err = drClient.Apply(ctx, resource.GetName(), resource, metav1.ApplyOptions{
+			FieldManager: s.fieldManager(),
+			Force:        s.ForceConflicts,
+		})
+		if apiErrors.IsConflict(err) {
+			return fmt.Errorf("can't apply resource %s, fields are managed by another field manager (consider forcing conflicts): %w", generateResourceID(*resource), err)
+		} else if err != nil {
+			return fmt.Errorf("can't apply resource %s: %w", generateResourceID(*resource), err)
 		}
 		s.keepResource(resource)
 	}
@@ -118,6 +133,50 @@ func (s *Stack) Apply(ctx context.Context, prune bool) error {
 	return err
 }
 
+// clientSideFieldManagers are the field managers of resources that have been
+// applied by k0s versions that used client-side apply. Those didn't specify a
+// field manager, so it's been derived from the user agent, i.e. the name of
+// the k0s executable.
+var clientSideFieldManagers = sets.New(DefaultFieldManager, filepath.Base(os.Args[0]))
+
+// isClientSideApplied checks if the resource has been applied by a k0s version
+// that used client-side apply, which stored the last applied configuration.
+func isClientSideApplied(resource *unstructured.Unstructured) bool {
+	_, ok := resource.GetAnnotations()[LastConfigAnnotation]
+	return ok
+}
+
+// migrateClientSideApply hands over the fields that have been managed by k0s
+// via client-side apply to the server-side apply field manager, and drops the
+// last applied configuration annotation. Otherwise, fields that have been
+// removed from the manifests would never be removed from the resource.
+func (s *Stack) migrateClientSideApply(ctx context.Context, drClient dynamic.ResourceInterface, resource *unstructured.Unstructured) error {
+	patch, err := clientSideApplyMigrationPatch(resource, s.fieldManager())
+	if err != nil {
+		return err
+	}
+	s.log.Infof("migrating resource %s to server-side apply", generateResourceID(*resource))
+	_, err = drClient.Patch(ctx, resource.GetName(), types.JSONPatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// clientSideApplyMigrationPatch returns the JSON patch that migrates the given
+// client-side applied resource to server-side apply.
+func clientSideApplyMigrationPatch(resource *unstructured.Unstructured, fieldManager string) ([]byte, error) {
+	upgraded := resource.DeepCopy()
+	if err := csaupgrade.UpgradeManagedFields(upgraded, clientSideFieldManagers, fieldManager); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal([]map[string]any{
+		{"op": "replace", "path": "/metadata/managedFields", "value": upgraded.GetManagedFields()},
+		// Conflict on concurrent modifications of the resource. A "test"
+		// operation would be rejected as invalid instead.
+		{"op": "replace", "path": "/metadata/resourceVersion", "value": resource.GetResourceVersion()},
+		{"op": "remove", "path": "/metadata/annotations/" + strings.ReplaceAll(LastConfigAnnotation, "/", "~1")},
+	})
+}
+
 func (s *Stack) keepResource(resource *unstructured.Unstructured) {
 	resourceID := generateResourceID(*resource)
 	logrus.WithField("stack", s.Name).Debugf("marking resource to be kept: %s", resourceID)
@@ -133,29 +192,24 @@ func (s *Stack) prune(ctx context.Context, mapper *restmapper.DeferredDiscoveryR
 		return nil
 	}
 
-	s.log.Debug("starting to delete resources, namespaced resources first")
+	s.log.Debug("starting to prune resources, namespaced resources first")
 	for _, resource := range pruneableResources {
-		resourceID := generateResourceID(resource)
 		if resource.GetNamespace() != "" {
-			s.log.Debugf("deleting resource %s", resourceID)
-			err = s.deleteResource(ctx, mapper, resource)
-			if err != nil {
+			if err := s.pruneResource(ctx, mapper, resource); err != nil {
 				return err
 			}
 		}
 	}
 	for _, resource := range pruneableResources {
-		resourceID := generateResourceID(resource)
 		if resource.GetNamespace() == "" {
-			s.log.Debugf("deleting resource %s", resourceID)
-			err = s.deleteResource(ctx, mapper, resource)
-			if err != nil {
+			if err := s.pruneResource(ctx, mapper, resource); err != nil {
 				return err
 			}
 		}
 	}
 	s.log.Debug("resources pruned succesfully")
 	s.keepResources = []string{}
+	s.prunedResources = len(pruneableResources)
 
 	return nil
 }
@@ -237,6 +291,34 @@ func (s *Stack) findPruneableResources(ctx context.Context, mapper *restmapper.D
 	return pruneableResources, nil
 }
 
+func (s *Stack) pruneResource(ctx context.Context, mapper *restmapper.DeferredDiscoveryRESTMapper, resource unstructured.Unstructured) error {
+	resourceID := generateResourceID(resource)
+	if prunePolicyFor(&resource, s.PrunePolicy) == PrunePolicyOrphan {
+		s.log.Infof("orphaning resource %s", resourceID)
+		return s.orphanResource(ctx, mapper, resource)
+	}
+
+	s.log.Debugf("deleting resource %s", resourceID)
+	return s.deleteResource(ctx, mapper, resource)
+}
+
+// orphanResource removes the stack label and annotations from the resource, so
+// that it's left alone by subsequent applies.
+func (s *Stack) orphanResource(ctx context.Context, mapper *restmapper.DeferredDiscoveryRESTMapper, resource unstructured.Unstructured) error {
+	drClient, err := s.clientForResource(mapper, resource)
+	if err != nil {
+		return fmt.Errorf("failed to get dynamic client for resource %s: %w", resource.GetSelfLink(), err)
+	}
+	patch := []byte(fmt.Sprintf(`{"metadata":{"labels":{%q:null},"annotations":{%q:null,%q:null}}}`,
+		NameLabel, ChecksumAnnotation, LastConfigAnnotation,
+	))
+	_, err = drClient.Patch(ctx, resource.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
+	if err != nil && !apiErrors.IsNotFound(err) && !apiErrors.IsGone(err) {
+		return fmt.Errorf("orphaning resource failed: %s", err)
+	}
+	return nil
+}
+
 func (s *Stack) deleteResource(ctx context.Context, mapper *restmapper.DeferredDiscoveryRESTMapper, resource unstructured.Unstructured) error {
 	propagationPolicy := metav1.DeletePropagationForeground
 	drClient, err := s.clientForResource(mapper, resource)
@@ -316,28 +398,19 @@ func (s *Stack) isInStack(resource unstructured.Unstructured) bool {
 	return false
 }
 
-func (s *Stack) patchResource(ctx context.Context, drClient dynamic.ResourceInterface, serverResource *unstructured.Unstructured, localResource *unstructured.Unstructured) error {
-	original := serverResource.GetAnnotations()[LastConfigAnnotation]
-	if original == "" {
-		return fmt.Errorf("%s does not have last-applied-configuration", localResource.GetSelfLink())
-	}
-	modified, _ := localResource.MarshalJSON()
+// DefaultFieldManager is the field manager used for server-side apply if the
+// stack doesn't specify one.
+const DefaultFieldManager = "k0s"
 
-	patch, err := jsonpatch.CreateMergePatch([]byte(original), modified)
-	if err != nil {
-		return fmt.Errorf("failed to create jsonpatch data: %w", err)
-	}
-	_, err = drClient.Patch(ctx, localResource.GetName(), types.MergePatchType, patch, metav1.PatchOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to patch resource: %w", err)
+func (s *Stack) fieldManager() string {
+	if s.FieldManager == "" {
+		return DefaultFieldManager
 	}
-
-	return nil
+	return s.FieldManager
 }
 
 func (s *Stack) prepareResource(resource *unstructured.Unstructured) {
 	checksum := resourceChecksum(resource)
-	lastAppliedConfig, _ := resource.MarshalJSON()
 
 	labels := resource.GetLabels()
 	if labels == nil {
@@ -351,7 +424,6 @@ func (s *Stack) prepareResource(resource *unstructured.Unstructured) {
 		annotations = map[string]string{}
 	}
 	annotations[ChecksumAnnotation] = checksum
-	annotations[LastConfigAnnotation] = string(lastAppliedConfig)
 	resource.SetAnnotations(annotations)
 }
 
@@ -19,34 +19,73 @@ package applier
 import (
 	"context"
 	"fmt"
+	"io/fs"
+	"math"
+	"os"
 	"path/filepath"
 	"sync"
 	"time"
 
-	"github.com/avast/retry-go"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/debounce"
 	"github.com/k0sproject/k0s/pkg/kubernetes"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 // StackApplier applies a stack whenever the files on disk change.
 type StackApplier struct {
-	log  logrus.FieldLogger
-	path string
+	log    logrus.FieldLogger
+	path   string
+	name   string
+	config *v1beta1.ApplierSpec
+	// metrics records the apply attempts, may be nil.
+	metrics *applierMetrics
 
 	doApply, doDelete func(context.Context) error
+
+	// applyMu is held while applying, so that draining waits for in-flight
+	// applies. It protects draining.
+	applyMu  sync.Mutex
+	draining bool
+
+	// retryMu protects the retry state below.
+	retryMu    sync.Mutex
+	backoff    wait.Backoff
+	retryTimer *time.Timer
 }
 
-// NewStackApplier crates new stack applier to manage a stack
-func NewStackApplier(path string, kubeClientFactory kubernetes.ClientFactoryInterface) *StackApplier {
+// newApplyBackoff returns the backoff for retrying failed applies. It starts at
+// one second and doubles with each failure, up to five minutes.
+func newApplyBackoff() wait.Backoff {
+	return wait.Backoff{
+		Duration: 1 * time.Second,
+		Factor:   2,
+		Jitter:   0.2,
+		Steps:    math.MaxInt32,
+		Cap:      5 * time.Minute,
+	}
+}
+
+// NewStackApplier crates new stack applier to manage a stack. If config is
+// nil, the default applier settings are used.
+func NewStackApplier(path string, kubeClientFactory kubernetes.ClientFactoryInterface, config *v1beta1.ApplierSpec) *StackApplier {
+	if config == nil {
+		config = v1beta1.DefaultApplierSpec()
+	}
+
 	var mu sync.Mutex
 	applier := NewApplier(path, kubeClientFactory)
+	applier.IgnorePatterns = config.IgnorePatterns
 
 	return &StackApplier{
-		log:  logrus.WithField("component", "applier-"+applier.Name),
-		path: path,
+		log:     logrus.WithField("component", "applier-"+applier.Name),
+		path:    path,
+		name:    applier.Name,
+		config:  config,
+		backoff: newApplyBackoff(),
 
 		doApply: func(ctx context.Context) error {
 			mu.Lock()
@@ -76,11 +115,15 @@ func (s *StackApplier) Run(ctx context.Context) error {
 
 	debounceCtx, cancelDebouncer := context.WithCancel(ctx)
 	defer cancelDebouncer()
+	defer s.cancelRetry()
 
 	debouncer := debounce.Debouncer[fsnotify.Event]{
-		Input:    watcher.Events,
-		Timeout:  1 * time.Second,
-		Filter:   s.triggersApply,
+		Input:   watcher.Events,
+		Timeout: s.config.DebounceInterval.Duration,
+		Filter: func(event fsnotify.Event) bool {
+			s.watchNewDirectory(watcher, event)
+			return s.triggersApply(event)
+		},
 		Callback: func(fsnotify.Event) { s.apply(debounceCtx) },
 	}
 
@@ -98,7 +141,8 @@ func (s *StackApplier) Run(ctx context.Context) error {
 		}
 	}()
 
-	err = watcher.Add(s.path)
+	// Kustomizations may refer to files in subdirectories, watch them, too.
+	err = s.watchRecursively(watcher, s.path)
 	if err != nil {
 		return fmt.Errorf("failed to watch %q: %w", s.path, err)
 	}
@@ -107,34 +151,151 @@ func (s *StackApplier) Run(ctx context.Context) error {
 	return nil
 }
 
-func (*StackApplier) triggersApply(event fsnotify.Event) bool {
+// watchRecursively adds dir and all of its non-ignored subdirectories to watcher.
+func (s *StackApplier) watchRecursively(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && isIgnored(d.Name(), s.config.IgnorePatterns) {
+			return filepath.SkipDir
+		}
+		return watcher.Add(path)
+	})
+}
+
+// watchNewDirectory starts watching directories that are created inside the
+// stack after the watch has been set up.
+func (s *StackApplier) watchNewDirectory(watcher *fsnotify.Watcher, event fsnotify.Event) {
+	if !event.Has(fsnotify.Create) {
+		return
+	}
+	if info, err := os.Stat(event.Name); err != nil || !info.IsDir() {
+		return
+	}
+	if err := s.watchRecursively(watcher, event.Name); err != nil {
+		s.log.WithError(err).Warnf("Failed to watch %s", event.Name)
+	}
+}
+
+func (s *StackApplier) triggersApply(event fsnotify.Event) bool {
 	// Always let the initial apply happen
 	if event == (fsnotify.Event{}) {
 		return true
 	}
 
-	// Only consider events on manifest files
-	if match, _ := filepath.Match(manifestFilePattern, filepath.Base(event.Name)); !match {
+	name := filepath.Base(event.Name)
+
+	// Pausing and resuming a stack needs to trigger an apply
+	if name == SkipFileName {
+		return true
+	}
+
+	if isIgnored(name, s.config.IgnorePatterns) {
+		return false
+	}
+
+	// Files that are written atomically are moved into place. Writes to
+	// existing files are considered to be partial writes in that case.
+	if s.config.AtomicWrites && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Remove) && !event.Has(fsnotify.Rename) {
+		return false
+	}
+
+	// Kustomizations may refer to arbitrary files, e.g. for generators, and
+	// to files in subdirectories. Any change may affect the rendered stack.
+	if isKustomizationDir(s.path) {
+		return true
+	}
+
+	// Plain stacks only consist of the manifest files in the stack directory.
+	if filepath.Dir(event.Name) != filepath.Clean(s.path) {
 		return false
 	}
 
-	return true
+	// Only consider events on manifest and kustomization files
+	if match, _ := filepath.Match(manifestFilePattern, name); match {
+		return true
+	}
+	for _, kustomizationFileName := range kustomizationFileNames {
+		if name == kustomizationFileName {
+			return true
+		}
+	}
+
+	return false
 }
 
+// apply applies the stack. Failed applies are retried with an exponential
+// backoff, until either an apply succeeds or the context is done. Any apply
+// in between, e.g. triggered by a file change, cancels the pending retry.
 func (s *StackApplier) apply(ctx context.Context) {
+	s.cancelRetry()
+	if ctx.Err() != nil {
+		return
+	}
+
+	// Don't hold the retry lock while applying, so that the retry state
+	// doesn't block on slow applies. Concurrent applies are serialized by
+	// doApply itself.
+	s.applyMu.Lock()
+	if s.draining {
+		s.applyMu.Unlock()
+		return
+	}
 	s.log.Info("Applying manifests")
+	start := time.Now()
+	err := s.doApply(ctx)
+	duration := time.Since(start)
+	s.applyMu.Unlock()
 
-	err := retry.Do(
-		func() error { return s.doApply(ctx) },
-		retry.OnRetry(func(attempt uint, err error) {
-			s.log.WithError(err).Warnf("Failed to apply manifests in attempt #%d, retrying after backoff", attempt+1)
-		}),
-		retry.Context(ctx),
-		retry.LastErrorOnly(true),
-	)
+	s.retryMu.Lock()
+	defer s.retryMu.Unlock()
 
-	if err != nil {
-		s.log.WithError(err).Error("Failed to apply manifests")
+	if err == nil {
+		s.backoff = newApplyBackoff()
+		s.metrics.record(s.name, duration, nil, time.Time{})
+		return
+	}
+
+	delay := s.backoff.Step()
+	s.log.WithError(err).Warnf("Failed to apply manifests, retrying in %s", delay.Round(time.Millisecond))
+	s.metrics.record(s.name, duration, err, time.Now().Add(delay))
+	if s.retryTimer != nil {
+		s.retryTimer.Stop()
+	}
+	s.retryTimer = time.AfterFunc(delay, func() { s.apply(ctx) })
+}
+
+// cancelRetry stops any pending retry.
+func (s *StackApplier) cancelRetry() {
+	s.retryMu.Lock()
+	defer s.retryMu.Unlock()
+	if s.retryTimer != nil {
+		s.retryTimer.Stop()
+		s.retryTimer = nil
+	}
+}
+
+// Drain prevents any further applies of the stack and waits for an in-flight
+// one to finish, or until ctx is done.
+func (s *StackApplier) Drain(ctx context.Context) error {
+	drained := make(chan struct{})
+	go func() {
+		s.applyMu.Lock()
+		defer s.applyMu.Unlock()
+		s.draining = true
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		s.cancelRetry()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
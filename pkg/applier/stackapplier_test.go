@@ -0,0 +1,185 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+func TestStackApplierTriggersApply(t *testing.T) {
+	for _, test := range []struct {
+		name         string
+		atomicWrites bool
+		event        fsnotify.Event
+		expected     bool
+	}{
+		{"initial", false, fsnotify.Event{}, true},
+		{"manifest_write", false, fsnotify.Event{Name: "/stack/cm.yaml", Op: fsnotify.Write}, true},
+		{"other_file", false, fsnotify.Event{Name: "/stack/README.md", Op: fsnotify.Write}, false},
+		{"kustomization", false, fsnotify.Event{Name: "/stack/Kustomization", Op: fsnotify.Write}, true},
+		{"hidden_file", false, fsnotify.Event{Name: "/stack/.cm.yaml", Op: fsnotify.Create}, false},
+		{"skip_file", false, fsnotify.Event{Name: "/stack/.skip", Op: fsnotify.Remove}, true},
+		{"atomic_write", true, fsnotify.Event{Name: "/stack/cm.yaml", Op: fsnotify.Write}, false},
+		{"atomic_chmod", true, fsnotify.Event{Name: "/stack/cm.yaml", Op: fsnotify.Chmod}, false},
+		{"atomic_rename", true, fsnotify.Event{Name: "/stack/cm.yaml", Op: fsnotify.Create}, true},
+		{"atomic_remove", true, fsnotify.Event{Name: "/stack/cm.yaml", Op: fsnotify.Remove}, true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			config := v1beta1.DefaultApplierSpec()
+			config.AtomicWrites = test.atomicWrites
+			underTest := StackApplier{path: "/stack", config: config}
+			assert.Equal(t, test.expected, underTest.triggersApply(test.event))
+		})
+	}
+}
+
+func TestStackApplierTriggersApplyForKustomizations(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), nil, 0644))
+
+	for _, test := range []struct {
+		name     string
+		event    fsnotify.Event
+		expected bool
+	}{
+		{"generator_input", fsnotify.Event{Name: filepath.Join(dir, "app.properties"), Op: fsnotify.Write}, true},
+		{"subdirectory", fsnotify.Event{Name: filepath.Join(dir, "base", "deployment.yaml"), Op: fsnotify.Write}, true},
+		{"nested_generator_input", fsnotify.Event{Name: filepath.Join(dir, "base", "config", "app.env"), Op: fsnotify.Create}, true},
+		{"hidden_file", fsnotify.Event{Name: filepath.Join(dir, "base", ".swp"), Op: fsnotify.Create}, false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			underTest := StackApplier{path: dir, config: v1beta1.DefaultApplierSpec()}
+			assert.Equal(t, test.expected, underTest.triggersApply(test.event))
+		})
+	}
+
+	t.Run("plain_stack_subdirectory", func(t *testing.T) {
+		underTest := StackApplier{path: t.TempDir(), config: v1beta1.DefaultApplierSpec()}
+		event := fsnotify.Event{Name: filepath.Join(underTest.path, "sub", "cm.yaml"), Op: fsnotify.Write}
+		assert.False(t, underTest.triggersApply(event))
+	})
+}
+
+func TestStackApplierWatchesRecursively(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "base", "config"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, ".git"), 0755))
+
+	watcher, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	t.Cleanup(func() { assert.NoError(t, watcher.Close()) })
+
+	underTest := StackApplier{path: dir, config: v1beta1.DefaultApplierSpec()}
+	require.NoError(t, underTest.watchRecursively(watcher, dir))
+	assert.ElementsMatch(t, []string{
+		dir,
+		filepath.Join(dir, "base"),
+		filepath.Join(dir, "base", "config"),
+	}, watcher.WatchList())
+}
+
+func TestStackApplierRetriesFailedApplies(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	attempts := 0
+	succeeded := make(chan struct{})
+	underTest := &StackApplier{
+		log:     logrus.WithField("test", t.Name()),
+		name:    "test",
+		config:  v1beta1.DefaultApplierSpec(),
+		metrics: newApplierMetrics(nil),
+		backoff: wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: math.MaxInt32},
+		doApply: func(context.Context) error {
+			attempts++
+			if attempts < 3 {
+				return errors.New("boom")
+			}
+			close(succeeded)
+			return nil
+		},
+	}
+
+	underTest.apply(ctx)
+	assert.Equal(t, []string{"test"}, underTest.metrics.failingStacks(nil))
+
+	select {
+	case <-succeeded:
+	case <-time.After(10 * time.Second):
+		require.Fail(t, "failed apply hasn't been retried")
+	}
+
+	// The successful apply has reset the backoff.
+	underTest.retryMu.Lock()
+	defer underTest.retryMu.Unlock()
+	assert.Equal(t, 3, attempts)
+	assert.Equal(t, newApplyBackoff(), underTest.backoff)
+	assert.Nil(t, underTest.retryTimer)
+	assert.Empty(t, underTest.metrics.failingStacks(nil))
+}
+
+func TestStackApplierDrain(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	applying, release := make(chan struct{}), make(chan struct{})
+	applies := 0
+	underTest := &StackApplier{
+		log:     logrus.WithField("test", t.Name()),
+		name:    "test",
+		config:  v1beta1.DefaultApplierSpec(),
+		metrics: newApplierMetrics(nil),
+		backoff: newApplyBackoff(),
+		doApply: func(context.Context) error {
+			applies++
+			close(applying)
+			<-release
+			return nil
+		},
+	}
+
+	applied := make(chan struct{})
+	go func() { defer close(applied); underTest.apply(ctx) }()
+	<-applying
+
+	// Draining waits for the in-flight apply.
+	drainCtx, cancelDrain := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancelDrain()
+	assert.ErrorIs(t, underTest.Drain(drainCtx), context.DeadlineExceeded)
+
+	close(release)
+	<-applied
+	require.NoError(t, underTest.Drain(ctx))
+
+	// No further applies once drained.
+	underTest.apply(ctx)
+	assert.Equal(t, 1, applies)
+}
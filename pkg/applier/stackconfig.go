@@ -0,0 +1,191 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/yaml"
+)
+
+// StackConfigFileName is the name of the optional file in a stack directory
+// that configures how the stack is applied. It's not applied as a manifest.
+const StackConfigFileName = "k0s-stack.yaml"
+
+// PrunePolicy controls what happens to resources that have been removed from
+// a stack's manifests.
+type PrunePolicy string
+
+const (
+	// PrunePolicyDelete deletes resources that have been removed from the
+	// stack. This is the default.
+	PrunePolicyDelete PrunePolicy = "delete"
+
+	// PrunePolicyOrphan leaves resources that have been removed from the stack
+	// in the cluster, and removes the k0s stack metadata from them, so that
+	// they're not managed by k0s anymore.
+	PrunePolicyOrphan PrunePolicy = "orphan"
+)
+
+// Validate checks if the prune policy is a known one. The empty policy is valid.
+func (p PrunePolicy) Validate() error {
+	switch p {
+	case "", PrunePolicyDelete, PrunePolicyOrphan:
+		return nil
+	default:
+		return fmt.Errorf("unknown prune policy %q, expected %q or %q", p, PrunePolicyDelete, PrunePolicyOrphan)
+	}
+}
+
+// ConflictPolicy controls how server-side apply conflicts are handled, i.e.
+// when fields of a stack's resources are managed by other field managers.
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyForce takes over the ownership of conflicting fields.
+	// This is the default for stacks managed by k0s itself.
+	ConflictPolicyForce ConflictPolicy = "force"
+
+	// ConflictPolicyFail fails the stack apply on conflicting fields. This is
+	// the default for user-provided stacks.
+	ConflictPolicyFail ConflictPolicy = "fail"
+)
+
+// Validate checks if the conflict policy is a known one. The empty policy is valid.
+func (p ConflictPolicy) Validate() error {
+	switch p {
+	case "", ConflictPolicyForce, ConflictPolicyFail:
+		return nil
+	default:
+		return fmt.Errorf("unknown conflict policy %q, expected %q or %q", p, ConflictPolicyForce, ConflictPolicyFail)
+	}
+}
+
+// k0sStacks are the names of the stacks that are written by k0s itself. They
+// need to be known before the applier starts, so that existing stacks are
+// treated as k0s stacks right away, even before the components that write
+// them have been started.
+var k0sStacks = map[string]struct{}{
+	"api-config":            {},
+	"applier":               {},
+	"autopilot":             {},
+	"bootstraprbac":         {},
+	"calico":                {},
+	"calico_init":           {},
+	"cloud-provider":        {},
+	"clusterconfig-webhook": {},
+	"coredns":               {},
+	"helm":                  {},
+	"ingress":               {},
+	"konnectivity":          {},
+	"kubelet":               {},
+	"kubeproxy":             {},
+	"kuberouter":            {},
+	"metallb":               {},
+	"metrics":               {},
+	"metricserver":          {},
+	"nodelocaldns":          {},
+	"snapshot-controller":   {},
+	"status":                {},
+}
+
+// isK0sStack checks if the stack with the given name is managed by k0s.
+func isK0sStack(name string) bool {
+	_, ok := k0sStacks[name]
+	return ok
+}
+
+// forceConflicts determines if conflicts should be forced for the given stack.
+// If no policy is given, conflicts are forced for stacks managed by k0s only.
+func (p ConflictPolicy) forceConflicts(stackName string) bool {
+	switch p {
+	case ConflictPolicyForce:
+		return true
+	case ConflictPolicyFail:
+		return false
+	default:
+		return isK0sStack(stackName)
+	}
+}
+
+// StackConfig is the content of a stack's config file.
+type StackConfig struct {
+	// PrunePolicy applies to all resources of the stack that don't specify
+	// their own policy via the PrunePolicyAnnotation (default: delete).
+	PrunePolicy PrunePolicy `json:"prunePolicy,omitempty"`
+
+	// Conflicts controls how server-side apply conflicts are handled
+	// (default: force for stacks managed by k0s, fail otherwise).
+	Conflicts ConflictPolicy `json:"conflicts,omitempty"`
+}
+
+// readStackConfig reads the stack config from the given stack directory. If
+// the directory doesn't contain a config file, the zero config is returned.
+func readStackConfig(dir string) (*StackConfig, error) {
+	data, err := os.ReadFile(filepath.Join(dir, StackConfigFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return &StackConfig{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	// Give a helpful error for manifests that happen to use the reserved name.
+	var typeMeta struct {
+		APIVersion string `json:"apiVersion"`
+		Kind       string `json:"kind"`
+	}
+	if err := yaml.Unmarshal(data, &typeMeta); err == nil && typeMeta.APIVersion != "" && typeMeta.Kind != "" {
+		return nil, fmt.Errorf("%s looks like a %s manifest, but the file name is reserved for the stack config, rename it", StackConfigFileName, typeMeta.Kind)
+	}
+
+	var config StackConfig
+	if err := yaml.UnmarshalStrict(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", StackConfigFileName, err)
+	}
+	if err := config.PrunePolicy.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", StackConfigFileName, err)
+	}
+	if err := config.Conflicts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", StackConfigFileName, err)
+	}
+
+	return &config, nil
+}
+
+// prunePolicyFor determines the prune policy for a resource that's about to
+// be pruned. The resource's own annotation takes precedence over the stack's
+// policy. CustomResourceDefinitions are always orphaned, unless annotated
+// otherwise, since deleting them would delete all of their custom resources.
+func prunePolicyFor(resource *unstructured.Unstructured, stackPolicy PrunePolicy) PrunePolicy {
+	if policy := PrunePolicy(resource.GetAnnotations()[PrunePolicyAnnotation]); policy != "" && policy.Validate() == nil {
+		return policy
+	}
+
+	gvk := resource.GroupVersionKind()
+	if gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition" {
+		return PrunePolicyOrphan
+	}
+
+	if stackPolicy == "" {
+		return PrunePolicyDelete
+	}
+	return stackPolicy
+}
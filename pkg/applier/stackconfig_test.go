@@ -0,0 +1,127 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReadStackConfig(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		config, err := readStackConfig(t.TempDir())
+		require.NoError(t, err)
+		assert.Equal(t, &StackConfig{}, config)
+	})
+
+	t.Run("orphan", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, StackConfigFileName), []byte("prunePolicy: orphan\n"), 0644))
+		config, err := readStackConfig(dir)
+		require.NoError(t, err)
+		assert.Equal(t, PrunePolicyOrphan, config.PrunePolicy)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, StackConfigFileName), []byte("prunePolicy: keep\n"), 0644))
+		_, err := readStackConfig(dir)
+		assert.ErrorContains(t, err, `unknown prune policy "keep"`)
+	})
+
+	t.Run("conflicts", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, StackConfigFileName), []byte("conflicts: force\n"), 0644))
+		config, err := readStackConfig(dir)
+		require.NoError(t, err)
+		assert.Equal(t, ConflictPolicyForce, config.Conflicts)
+	})
+
+	t.Run("invalid_conflicts", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, StackConfigFileName), []byte("conflicts: ignore\n"), 0644))
+		_, err := readStackConfig(dir)
+		assert.ErrorContains(t, err, `unknown conflict policy "ignore"`)
+	})
+
+	t.Run("manifest", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, StackConfigFileName), []byte("apiVersion: v1\nkind: ConfigMap\n"), 0644))
+		_, err := readStackConfig(dir)
+		assert.ErrorContains(t, err, "k0s-stack.yaml looks like a ConfigMap manifest, but the file name is reserved for the stack config")
+	})
+
+	t.Run("unknown_field", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, StackConfigFileName), []byte("prune: orphan\n"), 0644))
+		_, err := readStackConfig(dir)
+		assert.Error(t, err)
+	})
+}
+
+func TestPrunePolicyFor(t *testing.T) {
+	newResource := func(apiVersion, kind, policy string) *unstructured.Unstructured {
+		var u unstructured.Unstructured
+		u.SetAPIVersion(apiVersion)
+		u.SetKind(kind)
+		if policy != "" {
+			u.SetAnnotations(map[string]string{PrunePolicyAnnotation: policy})
+		}
+		return &u
+	}
+
+	for _, test := range []struct {
+		name        string
+		resource    *unstructured.Unstructured
+		stackPolicy PrunePolicy
+		expected    PrunePolicy
+	}{
+		{"default", newResource("v1", "ConfigMap", ""), "", PrunePolicyDelete},
+		{"stack_policy", newResource("v1", "ConfigMap", ""), PrunePolicyOrphan, PrunePolicyOrphan},
+		{"annotation_overrides_stack", newResource("v1", "ConfigMap", "delete"), PrunePolicyOrphan, PrunePolicyDelete},
+		{"invalid_annotation_ignored", newResource("v1", "ConfigMap", "bogus"), PrunePolicyOrphan, PrunePolicyOrphan},
+		{"crd_orphaned", newResource("apiextensions.k8s.io/v1", "CustomResourceDefinition", ""), PrunePolicyDelete, PrunePolicyOrphan},
+		{"crd_annotated_delete", newResource("apiextensions.k8s.io/v1", "CustomResourceDefinition", "delete"), "", PrunePolicyDelete},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, prunePolicyFor(test.resource, test.stackPolicy))
+		})
+	}
+}
+
+func TestConflictPolicyForceConflicts(t *testing.T) {
+	for _, test := range []struct {
+		name      string
+		policy    ConflictPolicy
+		stackName string
+		expected  bool
+	}{
+		{"k0s_stack_default", "", "coredns", true},
+		{"user_stack_default", "", "my-app", false},
+		{"k0s_stack_fail", ConflictPolicyFail, "coredns", false},
+		{"user_stack_force", ConflictPolicyForce, "my-app", true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, test.policy.forceConflicts(test.stackName))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package applier
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	applierv1beta1 "github.com/k0sproject/k0s/pkg/apis/applier/v1beta1"
+
+	apiErrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/dynamic"
+)
+
+var stackGVR = applierv1beta1.GroupVersion.WithResource("stacks")
+
+// applyResult describes the outcome of a stack apply, to be reported in the
+// stack's status.
+type applyResult struct {
+	// revision of the applied manifests, empty if they couldn't be parsed
+	revision        string
+	resources       int
+	prunedResources int
+	err             error
+}
+
+// stackRevision calculates a revision identifying the given resources.
+func stackRevision(resources []*unstructured.Unstructured) string {
+	hasher := sha256.New()
+	for _, resource := range resources {
+		// based on the implementation hasher.Write never returns err
+		_, _ = hasher.Write([]byte(resourceChecksum(resource)))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))[:16]
+}
+
+// updateStatus reflects the result of an apply in the stack's Stack object.
+// This is done on a best effort basis: Errors are only logged, since the Stack
+// CRD might not yet be available.
+func (a *Applier) updateStatus(ctx context.Context, result *applyResult) {
+	if errs := validation.IsDNS1123Subdomain(a.Name); len(errs) > 0 {
+		a.log.Debugf("not updating stack status, stack name is not a valid object name: %v", errs)
+		return
+	}
+
+	if err := a.doUpdateStatus(ctx, a.client.Resource(stackGVR).Namespace(applierv1beta1.StackNamespace), result); err != nil {
+		a.log.WithError(err).Debug("failed to update stack status")
+	}
+}
+
+func (a *Applier) doUpdateStatus(ctx context.Context, client dynamic.ResourceInterface, result *applyResult) error {
+	current, err := client.Get(ctx, a.Name, metav1.GetOptions{})
+	if apiErrors.IsNotFound(err) {
+		var newStack unstructured.Unstructured
+		newStack.SetGroupVersionKind(applierv1beta1.GroupVersion.WithKind("Stack"))
+		newStack.SetName(a.Name)
+		newStack.SetNamespace(applierv1beta1.StackNamespace)
+		newStack.SetLabels(CommonLabels("applier"))
+		current, err = client.Create(ctx, &newStack, metav1.CreateOptions{})
+	}
+	if err != nil {
+		return err
+	}
+
+	var stack applierv1beta1.Stack
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(current.Object, &stack); err != nil {
+		return err
+	}
+
+	now := metav1.Now()
+	stack.Status.LastAppliedTime = &now
+	if result.revision != "" {
+		stack.Status.Revision = result.revision
+		stack.Status.Resources = result.resources
+		stack.Status.PrunedResources = result.prunedResources
+	}
+	ready := metav1.Condition{
+		Type:               applierv1beta1.ReadyCondition,
+		ObservedGeneration: stack.Generation,
+	}
+	if result.err == nil {
+		stack.Status.LastError = ""
+		ready.Status = metav1.ConditionTrue
+		ready.Reason = "Applied"
+		ready.Message = fmt.Sprintf("Applied %d resources", result.resources)
+	} else {
+		stack.Status.LastError = result.err.Error()
+		ready.Status = metav1.ConditionFalse
+		ready.Reason = "ApplyFailed"
+		ready.Message = result.err.Error()
+	}
+	meta.SetStatusCondition(&stack.Status.Conditions, ready)
+
+	updated, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&stack)
+	if err != nil {
+		return err
+	}
+	_, err = client.UpdateStatus(ctx, &unstructured.Unstructured{Object: updated}, metav1.UpdateOptions{})
+	return err
+}
+
+// deleteStatus deletes the stack's Stack object.
+func (a *Applier) deleteStatus(ctx context.Context) {
+	if errs := validation.IsDNS1123Subdomain(a.Name); len(errs) > 0 {
+		return
+	}
+
+	err := a.client.Resource(stackGVR).Namespace(applierv1beta1.StackNamespace).Delete(ctx, a.Name, metav1.DeleteOptions{})
+	if err != nil && !apiErrors.IsNotFound(err) {
+		a.log.WithError(err).Debug("failed to delete stack status")
+	}
+}
@@ -19,6 +19,7 @@ import (
 
 	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
 	"github.com/k0sproject/k0s/pkg/autopilot/checks"
+	appku "github.com/k0sproject/k0s/pkg/autopilot/controller/plans/cmdprovider/k0supdate/utils"
 	appc "github.com/k0sproject/k0s/pkg/autopilot/controller/plans/core"
 )
 
@@ -44,6 +45,8 @@ func (aup *airgapupdate) NewPlan(ctx context.Context, cmd apv1beta2.PlanCommand,
 		return appc.PlanIncompleteTargets, false, nil
 	}
 
+	appku.MarkCanaries(status.AirgapUpdate.Workers, cmd.AirgapUpdate.Workers.Canary)
+
 	if _, found := aup.excludedFromPlans["worker"]; found && len(status.AirgapUpdate.Workers) > 0 {
 		return appc.PlanRestricted, false, nil
 	}
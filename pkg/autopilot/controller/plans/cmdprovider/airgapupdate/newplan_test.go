@@ -227,6 +227,7 @@ func TestNewPlan(t *testing.T) {
 				},
 				testutil.NewFakeClientFactory(),
 				test.excludedFromPlans,
+				"",
 			)
 
 			status := apv1beta2.PlanCommandStatus{
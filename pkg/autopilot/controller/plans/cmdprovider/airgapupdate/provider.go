@@ -39,11 +39,12 @@ type airgapupdate struct {
 	controllerDelegateMap apdel.ControllerDelegateMap
 	excludedFromPlans     map[string]struct{}
 	cf                    kubernetes.ClientFactoryInterface
+	k0sAPIAddress         string
 }
 
 var _ appc.PlanCommandProvider = (*airgapupdate)(nil)
 
-func NewAirgapUpdatePlanCommandProvider(logger *logrus.Entry, client crcli.Client, dm apdel.ControllerDelegateMap, cf kubernetes.ClientFactoryInterface, excludeFromPlans []string) appc.PlanCommandProvider {
+func NewAirgapUpdatePlanCommandProvider(logger *logrus.Entry, client crcli.Client, dm apdel.ControllerDelegateMap, cf kubernetes.ClientFactoryInterface, excludeFromPlans []string, k0sAPIAddress string) appc.PlanCommandProvider {
 	excludedFromPlans := make(map[string]struct{})
 	for _, excluded := range excludeFromPlans {
 		excludedFromPlans[excluded] = struct{}{}
@@ -55,6 +56,7 @@ func NewAirgapUpdatePlanCommandProvider(logger *logrus.Entry, client crcli.Clien
 		controllerDelegateMap: dm,
 		cf:                    cf,
 		excludedFromPlans:     excludedFromPlans,
+		k0sAPIAddress:         k0sAPIAddress,
 	}
 }
 
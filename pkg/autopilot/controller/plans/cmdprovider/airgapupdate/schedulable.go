@@ -61,7 +61,8 @@ func (aup *airgapupdate) Schedulable(ctx context.Context, planID string, cmd apv
 	logger.Infof("Sending signalling to node='%s'", nextForSignal.Name)
 
 	signalNodeCopy := signalNodeDelegate.DeepCopy(signalNode)
-	signalNodeCommandBuilder, err := signalNodeAirgapUpdateCommandBuilder(signalNodeCopy, cmd, status)
+	proxyURL := appku.DownloadProxyURL(aup.k0sAPIAddress, signalNodeCopy, cmd.AirgapUpdate.ProxyDownloads)
+	signalNodeCommandBuilder, err := signalNodeAirgapUpdateCommandBuilder(signalNodeCopy, cmd, status, proxyURL)
 	if err != nil {
 		logger.Warnf("Unable to build signal node content: %v", err)
 		return appc.PlanIncompleteTargets, false, nil
@@ -89,8 +90,12 @@ func (aup *airgapupdate) Schedulable(ctx context.Context, planID string, cmd apv
 // findNextSchedulableTarget searches through the plan status targets, searching for the
 // first entry that has the status `PendingSignal`. The plan targets are either a 'controller',
 // or a 'worker', and have a label indicating this. If none remain, nil is returned.
+// Pending canary targets are always selected before any other targets.
 func findNextSchedulableTarget(logger *logrus.Entry, cmd *apv1beta2.PlanCommandAirgapUpdateStatus) *apv1beta2.PlanCommandTargetStatus {
 	pendingNodes := appku.FindPending(cmd.Workers)
+	if canaries := appku.FindCanaries(pendingNodes); len(canaries) > 0 {
+		pendingNodes = canaries
+	}
 	pendingNodeCount := len(pendingNodes)
 
 	if pendingNodeCount > 0 {
@@ -107,7 +112,7 @@ func findNextSchedulableTarget(logger *logrus.Entry, cmd *apv1beta2.PlanCommandA
 	return nil
 }
 
-func signalNodeAirgapUpdateCommandBuilder(node crcli.Object, cmd apv1beta2.PlanCommand, cmdStatus *apv1beta2.PlanCommandStatus) (appku.SignalNodeCommandBuilder, error) {
+func signalNodeAirgapUpdateCommandBuilder(node crcli.Object, cmd apv1beta2.PlanCommand, cmdStatus *apv1beta2.PlanCommandStatus, proxyURL string) (appku.SignalNodeCommandBuilder, error) {
 	// Determine the platform identifier of the target signal node
 	nodePlatformID, err := appku.SignalNodePlatformIdentifier(node)
 	if err != nil {
@@ -128,6 +133,9 @@ func signalNodeAirgapUpdateCommandBuilder(node crcli.Object, cmd apv1beta2.PlanC
 				URL:     updateContent.URL,
 				Version: cmd.AirgapUpdate.Version,
 				Sha256:  updateContent.Sha256,
+
+				Verification: appku.SignalVerification(cmd.AirgapUpdate.Verification),
+				ProxyURL:     proxyURL,
 			},
 		}
 	}, nil
@@ -166,6 +166,7 @@ func TestSchedulable(t *testing.T) {
 				},
 				testutil.NewFakeClientFactory(),
 				[]string{},
+				"",
 			)
 
 			ctx := context.TODO()
@@ -49,13 +49,30 @@ func (aup *airgapupdate) SchedulableWait(ctx context.Context, planID string, cmd
 		return appc.PlanCompleted, false, nil
 	}
 
-	canScheduleWorkers, _ := isSchedulableWorkers(cmd.AirgapUpdate.Workers, status.AirgapUpdate.Workers)
+	// Canary groups are updated first. The remaining targets are only considered
+	// once the health checks have passed after the canary group has been updated.
+
+	workers, workersGateUpdated, err := appku.HealthGate(ctx, aup.client, cmd.AirgapUpdate.Workers.Canary, status.AirgapUpdate.Workers, &status.AirgapUpdate.WorkersHealthGate)
+	if err != nil {
+		logger.Infof("Plan is non-recoverable due to failed worker health checks: %v", err)
+		status.Description = err.Error()
+		return appc.PlanHealthChecksFailed, false, nil
+	}
+
+	canScheduleWorkers, _ := isSchedulableWorkers(cmd.AirgapUpdate.Workers, workers)
 
 	if canScheduleWorkers {
 		logger.Info("Workers can be scheduled (controllers done)")
 		return appc.PlanSchedulable, false, nil
 	}
 
+	// Persist any changes to the health gate, which results in another reconciliation.
+
+	if workersGateUpdated {
+		logger.Info("Waiting for canary health checks")
+		return appc.PlanSchedulableWait, false, nil
+	}
+
 	logger.Info("No applicable transitions available, requesting retry")
 	return appc.PlanSchedulableWait, true, nil
 }
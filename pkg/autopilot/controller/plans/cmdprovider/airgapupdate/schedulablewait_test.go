@@ -386,6 +386,7 @@ func TestSchedulableWait(t *testing.T) {
 				},
 				testutil.NewFakeClientFactory(),
 				[]string{},
+				"",
 			)
 
 			ctx := context.TODO()
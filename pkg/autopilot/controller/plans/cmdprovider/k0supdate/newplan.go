@@ -55,6 +55,9 @@ func (kp *k0supdate) NewPlan(ctx context.Context, cmd apv1beta2.PlanCommand, sta
 		return appc.PlanIncompleteTargets, false, nil
 	}
 
+	appku.MarkCanaries(status.K0sUpdate.Controllers, cmd.K0sUpdate.Targets.Controllers.Canary)
+	appku.MarkCanaries(status.K0sUpdate.Workers, cmd.K0sUpdate.Targets.Workers.Canary)
+
 	// With the work done for this command, determine if the content should be restricted. Performing this
 	// assertion after processing prevents keeps this function consistent in that the content is guaranteed
 	// to be processed (vs. exiting early with incomplete results)
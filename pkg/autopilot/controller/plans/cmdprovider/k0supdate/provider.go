@@ -33,13 +33,14 @@ type k0supdate struct {
 	controllerDelegateMap apdel.ControllerDelegateMap
 	excludedFromPlans     map[string]struct{}
 	cf                    kubernetes.ClientFactoryInterface
+	k0sAPIAddress         string
 }
 
 var _ appc.PlanCommandProvider = (*k0supdate)(nil)
 
 // NewK0sUpdatePlanCommandProvider builds a `PlanCommandProvider` for the
 // `K0sUpdate` command.
-func NewK0sUpdatePlanCommandProvider(logger *logrus.Entry, client crcli.Client, dm apdel.ControllerDelegateMap, cf kubernetes.ClientFactoryInterface, excludeFromPlans []string) appc.PlanCommandProvider {
+func NewK0sUpdatePlanCommandProvider(logger *logrus.Entry, client crcli.Client, dm apdel.ControllerDelegateMap, cf kubernetes.ClientFactoryInterface, excludeFromPlans []string, k0sAPIAddress string) appc.PlanCommandProvider {
 	excludedFromPlans := make(map[string]struct{})
 	for _, excluded := range excludeFromPlans {
 		excludedFromPlans[excluded] = struct{}{}
@@ -51,6 +52,7 @@ func NewK0sUpdatePlanCommandProvider(logger *logrus.Entry, client crcli.Client,
 		controllerDelegateMap: dm,
 		cf:                    cf,
 		excludedFromPlans:     excludedFromPlans,
+		k0sAPIAddress:         k0sAPIAddress,
 	}
 }
 
@@ -87,7 +87,8 @@ func (kp *k0supdate) Schedulable(ctx context.Context, planID string, cmd apv1bet
 	// disagree. This target state will move to `IncompleteTargets` in this case.
 
 	signalNodeCopy := signalNodeDelegate.DeepCopy(signalNode)
-	signalNodeCommandBuilder, err := signalNodeK0sUpdateCommandBuilder(signalNodeCopy, cmd, status)
+	proxyURL := appku.DownloadProxyURL(kp.k0sAPIAddress, signalNodeCopy, cmd.K0sUpdate.ProxyDownloads)
+	signalNodeCommandBuilder, err := signalNodeK0sUpdateCommandBuilder(signalNodeCopy, cmd, status, proxyURL)
 	if err != nil {
 		logger.Warnf("Unable to build signal node content: %v", err)
 		return appc.PlanIncompleteTargets, false, nil
@@ -115,6 +116,7 @@ func (kp *k0supdate) Schedulable(ctx context.Context, planID string, cmd apv1bet
 // findNextSchedulableTarget searches through the plan status targets, searching for the
 // first entry that has the status `PendingSignal`. The plan targets are either a 'controller',
 // or a 'worker', and have a label indicating this. If none remain, nil is returned.
+// Pending canary targets are always selected before any other targets.
 func findNextSchedulableTarget(logger *logrus.Entry, cmd *apv1beta2.PlanCommandK0sUpdateStatus) (*apv1beta2.PlanCommandTargetStatus, string, int) {
 	var targets = []struct {
 		nodes []apv1beta2.PlanCommandTargetStatus
@@ -126,6 +128,9 @@ func findNextSchedulableTarget(logger *logrus.Entry, cmd *apv1beta2.PlanCommandK
 
 	for _, target := range targets {
 		pendingNodes := appku.FindPending(target.nodes)
+		if canaries := appku.FindCanaries(pendingNodes); len(canaries) > 0 {
+			pendingNodes = canaries
+		}
 		pendingNodeCount := len(pendingNodes)
 
 		if pendingNodeCount > 0 {
@@ -143,7 +148,7 @@ func findNextSchedulableTarget(logger *logrus.Entry, cmd *apv1beta2.PlanCommandK
 	return nil, "", 0
 }
 
-func signalNodeK0sUpdateCommandBuilder(node crcli.Object, cmd apv1beta2.PlanCommand, cmdStatus *apv1beta2.PlanCommandStatus) (appku.SignalNodeCommandBuilder, error) {
+func signalNodeK0sUpdateCommandBuilder(node crcli.Object, cmd apv1beta2.PlanCommand, cmdStatus *apv1beta2.PlanCommandStatus, proxyURL string) (appku.SignalNodeCommandBuilder, error) {
 	// Determine the platform identifier of the target signal node
 	nodePlatformID, err := appku.SignalNodePlatformIdentifier(node)
 	if err != nil {
@@ -165,6 +170,10 @@ func signalNodeK0sUpdateCommandBuilder(node crcli.Object, cmd apv1beta2.PlanComm
 				Version:     cmd.K0sUpdate.Version,
 				Sha256:      updateContent.Sha256,
 				ForceUpdate: cmd.K0sUpdate.ForceUpdate,
+
+				Verification: appku.SignalVerification(cmd.K0sUpdate.Verification),
+				ProxyURL:     proxyURL,
+				Hooks:        appku.SignalHooks(cmd.K0sUpdate.Hooks),
 			},
 		}
 	}, nil
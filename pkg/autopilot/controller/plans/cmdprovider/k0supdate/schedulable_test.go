@@ -173,6 +173,7 @@ func TestSchedulable(t *testing.T) {
 				},
 				testutil.NewFakeClientFactory(),
 				[]string{},
+				"",
 			)
 
 			ctx := context.TODO()
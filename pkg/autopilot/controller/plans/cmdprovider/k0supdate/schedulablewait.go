@@ -17,6 +17,7 @@ package k0supdate
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
 	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
@@ -26,6 +27,10 @@ import (
 	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
 )
 
+// hooksFailedDescription prefixes the description of commands whose rollout is
+// paused due to failed update hooks.
+const hooksFailedDescription = "rollout paused, update hooks failed"
+
 // SchedulableWait handles the provider state 'schedulablewait'
 func (kp *k0supdate) SchedulableWait(ctx context.Context, planID string, cmd apv1beta2.PlanCommand, status *apv1beta2.PlanCommandStatus) (apv1beta2.PlanStateType, bool, error) {
 	logger := kp.logger.WithField("state", "schedulablewait")
@@ -47,6 +52,35 @@ func (kp *k0supdate) SchedulableWait(ctx context.Context, planID string, cmd apv
 		return appc.PlanApplyFailed, false, nil
 	}
 
+	// Nodes that failed to become healthy after an update have been rolled back
+	// to their previous version, which also ends the plan.
+
+	if appku.IsRolledBack(status.K0sUpdate.Controllers, status.K0sUpdate.Workers) {
+		logger.Info("Plan is non-recoverable due to a rolled back update")
+		return appc.PlanRolledBack, false, nil
+	}
+
+	// Failed update hooks pause the rollout. They are retried on the node, and
+	// the rollout continues as soon as they succeed.
+
+	if node, found := appku.FindHookFailed(status.K0sUpdate.Controllers, status.K0sUpdate.Workers); found {
+		description := fmt.Sprintf("%s on node %s", hooksFailedDescription, node)
+		if status.Description != description {
+			logger.Infof("Rollout is paused due to failed update hooks on node %s", node)
+			status.Description = description
+			return appc.PlanSchedulableWait, false, nil
+		}
+
+		logger.Info("Rollout is paused, requesting retry")
+		return appc.PlanSchedulableWait, true, nil
+	}
+
+	if strings.HasPrefix(status.Description, hooksFailedDescription) {
+		logger.Info("Rollout is resumed")
+		status.Description = ""
+		return appc.PlanSchedulableWait, false, nil
+	}
+
 	controllersDone := appku.IsCompleted(status.K0sUpdate.Controllers)
 	workersDone := appku.IsCompleted(status.K0sUpdate.Workers)
 
@@ -55,8 +89,29 @@ func (kp *k0supdate) SchedulableWait(ctx context.Context, planID string, cmd apv
 		return appc.PlanCompleted, false, nil
 	}
 
-	canScheduleController, _ := isSchedulableControllers(status.K0sUpdate.Controllers)
-	canScheduleWorkers, _ := isSchedulableWorkers(cmd.K0sUpdate.Targets.Workers, status.K0sUpdate.Workers)
+	// Canary groups are updated first. The remaining targets are only considered
+	// once the health checks have passed after the canary group has been updated.
+
+	controllers, controllersGateUpdated, err := appku.HealthGate(ctx, kp.client, cmd.K0sUpdate.Targets.Controllers.Canary, status.K0sUpdate.Controllers, &status.K0sUpdate.ControllersHealthGate)
+	if err != nil {
+		logger.Infof("Plan is non-recoverable due to failed controller health checks: %v", err)
+		status.Description = err.Error()
+		return appc.PlanHealthChecksFailed, false, nil
+	}
+
+	workers := status.K0sUpdate.Workers
+	var workersGateUpdated bool
+	if controllersDone {
+		workers, workersGateUpdated, err = appku.HealthGate(ctx, kp.client, cmd.K0sUpdate.Targets.Workers.Canary, status.K0sUpdate.Workers, &status.K0sUpdate.WorkersHealthGate)
+		if err != nil {
+			logger.Infof("Plan is non-recoverable due to failed worker health checks: %v", err)
+			status.Description = err.Error()
+			return appc.PlanHealthChecksFailed, false, nil
+		}
+	}
+
+	canScheduleController, _ := isSchedulableControllers(controllers)
+	canScheduleWorkers, _ := isSchedulableWorkers(cmd.K0sUpdate.Targets.Workers, workers)
 
 	// Controllers have priority for scheduling evaluation, as it is important that controllers
 	// are updated before workers due to the Kubernetes version-skew policy.
@@ -75,6 +130,13 @@ func (kp *k0supdate) SchedulableWait(ctx context.Context, planID string, cmd apv
 		return appc.PlanSchedulable, false, nil
 	}
 
+	// Persist any changes to the health gates, which results in another reconciliation.
+
+	if controllersGateUpdated || workersGateUpdated {
+		logger.Info("Waiting for canary health checks")
+		return appc.PlanSchedulableWait, false, nil
+	}
+
 	logger.Info("No applicable transitions available, requesting retry")
 	return appc.PlanSchedulableWait, true, nil
 }
@@ -127,10 +189,23 @@ func (kp *k0supdate) reconcileSignalNodeStatusTarget(ctx context.Context, planID
 					if appku.IsSignalDataSameCommand(cmdStatus, signalData) && appku.IsSignalDataStatusDifferent(signalNodes[i], signalData.Status) {
 						origState := signalNodes[i].State
 
+						// Failed hooks are retried on the node, so they might have succeeded since.
+						if signalNodes[i].State == appc.SignalHookFailed {
+							signalNodes[i].State = appc.SignalSent
+						}
+
+						if signalData.Status.Status == apsigcomm.FailedPreUpdateHooks || signalData.Status.Status == apsigcomm.FailedPostUpdateHooks {
+							signalNodes[i].State = appc.SignalHookFailed
+						}
+
 						if signalData.Status.Status == apsigcomm.Failed || signalData.Status.Status == apsigcomm.FailedDownload {
 							signalNodes[i].State = appc.SignalApplyFailed
 						}
 
+						if signalData.Status.Status == apsigcomm.RolledBack {
+							signalNodes[i].State = appc.SignalRolledBack
+						}
+
 						if signalData.Status.Status == apsigcomm.Completed {
 							signalNodes[i].State = appc.SignalCompleted
 						}
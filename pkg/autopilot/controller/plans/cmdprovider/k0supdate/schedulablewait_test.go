@@ -17,6 +17,7 @@ package k0supdate
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/k0sproject/k0s/internal/testutil"
 	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
@@ -561,6 +562,153 @@ func TestSchedulableWait(t *testing.T) {
 				apv1beta2.NewPlanCommandTargetStatus("worker0", appc.SignalCompleted),
 			},
 		},
+
+		// Covers the scenario of a v1.Node that has been rolled back to its previous k0s
+		// version, after failing to become healthy. This ends the plan as 'RolledBack'.
+		{
+			"WorkerRolledBack",
+			[]crcli.Object{
+				&v1.Node{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Node",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "worker0",
+						Annotations: map[string]string{
+							"k0sproject.io/autopilot-signal-version": apsigv2.Version,
+							"k0sproject.io/autopilot-signal-data":    `{"planId":"id123","created":"2022-07-01T00:56:19Z","command":{"id":0,"k0supdate":{"url":"http://localhost/dist/k0s","version":"v0.0.0","forceupdate":true}},"status":{"status":"RolledBack","timestamp":"2022-07-01T01:06:27Z"}}`,
+						},
+					},
+				},
+			},
+			apv1beta2.PlanCommand{
+				K0sUpdate: &apv1beta2.PlanCommandK0sUpdate{
+					Targets: apv1beta2.PlanCommandTargets{
+						Workers: apv1beta2.PlanCommandTarget{
+							Limits: apv1beta2.PlanCommandTargetLimits{
+								Concurrent: 2,
+							},
+						},
+					},
+				},
+			},
+			apv1beta2.PlanCommandStatus{
+				State: appc.PlanSchedulableWait,
+				K0sUpdate: &apv1beta2.PlanCommandK0sUpdateStatus{
+					Workers: []apv1beta2.PlanCommandTargetStatus{
+						apv1beta2.NewPlanCommandTargetStatus("worker0", appc.SignalSent),
+						apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+					},
+				},
+			},
+			appc.PlanRolledBack,
+			false,
+			false,
+			nil,
+			[]apv1beta2.PlanCommandTargetStatus{
+				apv1beta2.NewPlanCommandTargetStatus("worker0", appc.SignalRolledBack),
+				apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+			},
+		},
+
+		// Covers the scenario of a v1.Node whose pre-update hooks have failed. This pauses
+		// the rollout, even though there would be room for another worker.
+		{
+			"WorkerHookFailed",
+			[]crcli.Object{
+				&v1.Node{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Node",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "worker0",
+						Annotations: map[string]string{
+							"k0sproject.io/autopilot-signal-version": apsigv2.Version,
+							"k0sproject.io/autopilot-signal-data":    `{"planId":"id123","created":"2022-07-01T00:56:19Z","command":{"id":0,"k0supdate":{"url":"http://localhost/dist/k0s","version":"v0.0.0","forceupdate":true}},"status":{"status":"FailedPreUpdateHooks","timestamp":"2022-07-01T01:06:27Z"}}`,
+						},
+					},
+				},
+			},
+			apv1beta2.PlanCommand{
+				K0sUpdate: &apv1beta2.PlanCommandK0sUpdate{
+					Targets: apv1beta2.PlanCommandTargets{
+						Workers: apv1beta2.PlanCommandTarget{
+							Limits: apv1beta2.PlanCommandTargetLimits{
+								Concurrent: 2,
+							},
+						},
+					},
+				},
+			},
+			apv1beta2.PlanCommandStatus{
+				State: appc.PlanSchedulableWait,
+				K0sUpdate: &apv1beta2.PlanCommandK0sUpdateStatus{
+					Workers: []apv1beta2.PlanCommandTargetStatus{
+						apv1beta2.NewPlanCommandTargetStatus("worker0", appc.SignalSent),
+						apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+					},
+				},
+			},
+			appc.PlanSchedulableWait,
+			false,
+			false,
+			nil,
+			[]apv1beta2.PlanCommandTargetStatus{
+				apv1beta2.NewPlanCommandTargetStatus("worker0", appc.SignalHookFailed),
+				apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+			},
+		},
+
+		// Covers the scenario of a v1.Node whose failed hooks have succeeded after being
+		// retried, which resumes the rollout.
+		{
+			"WorkerHookRecovered",
+			[]crcli.Object{
+				&v1.Node{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "Node",
+						APIVersion: "v1",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "worker0",
+						Annotations: map[string]string{
+							"k0sproject.io/autopilot-signal-version": apsigv2.Version,
+							"k0sproject.io/autopilot-signal-data":    `{"planId":"id123","created":"2022-07-01T00:56:19Z","command":{"id":0,"k0supdate":{"url":"http://localhost/dist/k0s","version":"v0.0.0","forceupdate":true}},"status":{"status":"Cordoning","timestamp":"2022-07-01T01:06:27Z"}}`,
+						},
+					},
+				},
+			},
+			apv1beta2.PlanCommand{
+				K0sUpdate: &apv1beta2.PlanCommandK0sUpdate{
+					Targets: apv1beta2.PlanCommandTargets{
+						Workers: apv1beta2.PlanCommandTarget{
+							Limits: apv1beta2.PlanCommandTargetLimits{
+								Concurrent: 2,
+							},
+						},
+					},
+				},
+			},
+			apv1beta2.PlanCommandStatus{
+				State: appc.PlanSchedulableWait,
+				K0sUpdate: &apv1beta2.PlanCommandK0sUpdateStatus{
+					Workers: []apv1beta2.PlanCommandTargetStatus{
+						apv1beta2.NewPlanCommandTargetStatus("worker0", appc.SignalHookFailed),
+						apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+					},
+				},
+			},
+			appc.PlanSchedulable,
+			false,
+			false,
+			nil,
+			[]apv1beta2.PlanCommandTargetStatus{
+				apv1beta2.NewPlanCommandTargetStatus("worker0", appc.SignalSent),
+				apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+			},
+		},
 	}
 
 	scheme := runtime.NewScheme()
@@ -580,6 +728,7 @@ func TestSchedulableWait(t *testing.T) {
 				},
 				testutil.NewFakeClientFactory(),
 				[]string{},
+				"",
 			)
 
 			ctx := context.TODO()
@@ -594,3 +743,170 @@ func TestSchedulableWait(t *testing.T) {
 		})
 	}
 }
+
+func canaryTargetStatus(name string, state apv1beta2.PlanCommandTargetStateType) apv1beta2.PlanCommandTargetStatus {
+	status := apv1beta2.NewPlanCommandTargetStatus(name, state)
+	status.Canary = true
+	return status
+}
+
+func readyNode(name string, ready v1.ConditionStatus) *v1.Node {
+	return &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+// TestSchedulableWaitCanary ensures that non-canary workers are only scheduled once the
+// canary group has been updated and the health checks have passed.
+func TestSchedulableWaitCanary(t *testing.T) {
+	command := apv1beta2.PlanCommand{
+		K0sUpdate: &apv1beta2.PlanCommandK0sUpdate{
+			Targets: apv1beta2.PlanCommandTargets{
+				Workers: apv1beta2.PlanCommandTarget{
+					Limits: apv1beta2.PlanCommandTargetLimits{Concurrent: 3},
+					Canary: &apv1beta2.PlanCommandTargetCanary{
+						Count: 1,
+						HealthChecks: apv1beta2.PlanCommandHealthChecks{
+							NodesReady: true,
+							Timeout:    metav1.Duration{Duration: 10 * time.Minute},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var tests = []struct {
+		name              string
+		objects           []crcli.Object
+		workers           []apv1beta2.PlanCommandTargetStatus
+		gate              *apv1beta2.PlanCommandHealthGateStatus
+		expectedNextState apv1beta2.PlanStateType
+		expectedRetry     bool
+		expectedPassed    bool
+	}{
+		{
+			"CanaryPending",
+			[]crcli.Object{},
+			[]apv1beta2.PlanCommandTargetStatus{
+				canaryTargetStatus("worker0", appc.SignalPending),
+				apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+			},
+			nil,
+			appc.PlanSchedulable,
+			false,
+			false,
+		},
+		{
+			"CanarySent",
+			[]crcli.Object{},
+			[]apv1beta2.PlanCommandTargetStatus{
+				canaryTargetStatus("worker0", appc.SignalSent),
+				apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+			},
+			nil,
+			appc.PlanSchedulableWait,
+			true,
+			false,
+		},
+		{
+			"HealthChecksPending",
+			[]crcli.Object{readyNode("worker0", v1.ConditionFalse)},
+			[]apv1beta2.PlanCommandTargetStatus{
+				canaryTargetStatus("worker0", appc.SignalCompleted),
+				apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+			},
+			nil,
+			appc.PlanSchedulableWait,
+			false,
+			false,
+		},
+		{
+			"HealthChecksStillPending",
+			[]crcli.Object{readyNode("worker0", v1.ConditionFalse)},
+			[]apv1beta2.PlanCommandTargetStatus{
+				canaryTargetStatus("worker0", appc.SignalCompleted),
+				apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+			},
+			&apv1beta2.PlanCommandHealthGateStatus{
+				Message:          "node worker0 is not ready",
+				StartedTimestamp: metav1.Now(),
+			},
+			appc.PlanSchedulableWait,
+			true,
+			false,
+		},
+		{
+			"HealthChecksPassed",
+			[]crcli.Object{readyNode("worker0", v1.ConditionTrue)},
+			[]apv1beta2.PlanCommandTargetStatus{
+				canaryTargetStatus("worker0", appc.SignalCompleted),
+				apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+			},
+			nil,
+			appc.PlanSchedulable,
+			false,
+			true,
+		},
+		{
+			"HealthChecksTimedOut",
+			[]crcli.Object{readyNode("worker0", v1.ConditionFalse)},
+			[]apv1beta2.PlanCommandTargetStatus{
+				canaryTargetStatus("worker0", appc.SignalCompleted),
+				apv1beta2.NewPlanCommandTargetStatus("worker1", appc.SignalPending),
+			},
+			&apv1beta2.PlanCommandHealthGateStatus{
+				StartedTimestamp: metav1.NewTime(time.Now().Add(-time.Hour)),
+			},
+			appc.PlanHealthChecksFailed,
+			false,
+			false,
+		},
+	}
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, apscheme.AddToScheme(scheme))
+	assert.NoError(t, v1.AddToScheme(scheme))
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := crfake.NewClientBuilder().WithObjects(test.objects...).WithScheme(scheme).Build()
+
+			provider := NewK0sUpdatePlanCommandProvider(
+				logrus.NewEntry(logrus.StandardLogger()),
+				client,
+				map[string]apdel.ControllerDelegate{
+					"controller": apdel.ControlNodeControllerDelegate(),
+					"worker":     apdel.NodeControllerDelegate(),
+				},
+				testutil.NewFakeClientFactory(),
+				[]string{},
+				"",
+			)
+
+			status := apv1beta2.PlanCommandStatus{
+				State: appc.PlanSchedulableWait,
+				K0sUpdate: &apv1beta2.PlanCommandK0sUpdateStatus{
+					Workers:           test.workers,
+					WorkersHealthGate: test.gate,
+				},
+			}
+
+			nextState, retry, err := provider.SchedulableWait(context.TODO(), "id123", command, &status)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedNextState, nextState)
+			assert.Equal(t, test.expectedRetry, retry)
+
+			if gate := status.K0sUpdate.WorkersHealthGate; test.expectedPassed {
+				if assert.NotNil(t, gate) {
+					assert.True(t, gate.Passed)
+				}
+			} else if gate != nil {
+				assert.False(t, gate.Passed)
+			}
+		})
+	}
+}
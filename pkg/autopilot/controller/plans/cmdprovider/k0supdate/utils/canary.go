@@ -0,0 +1,131 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	crcli "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultHealthCheckTimeout is used for canary health checks that don't specify a timeout.
+const DefaultHealthCheckTimeout = 10 * time.Minute
+
+// MarkCanaries flags the targets that are part of the canary group. Explicitly named
+// canary nodes take precedence over a canary count, which selects targets in
+// alphabetical order of their names.
+func MarkCanaries(targets []apv1beta2.PlanCommandTargetStatus, canary *apv1beta2.PlanCommandTargetCanary) {
+	if canary == nil {
+		return
+	}
+
+	if len(canary.Nodes) > 0 {
+		names := make(map[string]struct{}, len(canary.Nodes))
+		for _, name := range canary.Nodes {
+			names[name] = struct{}{}
+		}
+
+		for i := range targets {
+			_, targets[i].Canary = names[targets[i].Name]
+		}
+
+		return
+	}
+
+	indices := make([]int, len(targets))
+	for i := range indices {
+		indices[i] = i
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return targets[indices[i]].Name < targets[indices[j]].Name
+	})
+
+	for i, idx := range indices {
+		targets[idx].Canary = i < canary.Count
+	}
+}
+
+// FindCanaries returns all targets that are part of the canary group.
+func FindCanaries(targets []apv1beta2.PlanCommandTargetStatus) []apv1beta2.PlanCommandTargetStatus {
+	var canaries []apv1beta2.PlanCommandTargetStatus
+
+	for _, target := range targets {
+		if target.Canary {
+			canaries = append(canaries, target)
+		}
+	}
+
+	return canaries
+}
+
+// HealthGate determines which of the provided targets can currently be considered
+// for scheduling. As long as the canary group isn't updated, these are only the
+// canary targets. Once all canary targets are completed, the health checks are run
+// and their result is recorded in the provided gate status. Only after they passed,
+// all targets are returned.
+//
+// The returned boolean indicates that the gate status has been changed and should
+// be persisted. An error is returned if the health checks didn't pass within their
+// timeout.
+func HealthGate(ctx context.Context, client crcli.Client, canary *apv1beta2.PlanCommandTargetCanary, targets []apv1beta2.PlanCommandTargetStatus, gate **apv1beta2.PlanCommandHealthGateStatus) ([]apv1beta2.PlanCommandTargetStatus, bool, error) {
+	if canary == nil || (*gate != nil && (*gate).Passed) {
+		return targets, false, nil
+	}
+
+	canaries := FindCanaries(targets)
+	if len(canaries) == 0 {
+		return targets, false, nil
+	}
+
+	if !IsCompleted(canaries) {
+		return canaries, false, nil
+	}
+
+	var updated bool
+	now := metav1.Now()
+	if *gate == nil {
+		*gate = &apv1beta2.PlanCommandHealthGateStatus{StartedTimestamp: now}
+		updated = true
+	}
+
+	if err := RunHealthChecks(ctx, client, canary.HealthChecks); err != nil {
+		if msg := err.Error(); (*gate).Message != msg {
+			(*gate).Message = msg
+			updated = true
+		}
+
+		timeout := canary.HealthChecks.Timeout.Duration
+		if timeout <= 0 {
+			timeout = DefaultHealthCheckTimeout
+		}
+
+		if now.Sub((*gate).StartedTimestamp.Time) > timeout {
+			return nil, updated, fmt.Errorf("health checks didn't pass within %s: %w", timeout, err)
+		}
+
+		return nil, updated, nil
+	}
+
+	(*gate).Passed = true
+	(*gate).Message = ""
+
+	return targets, true, nil
+}
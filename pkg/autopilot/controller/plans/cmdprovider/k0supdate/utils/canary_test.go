@@ -0,0 +1,57 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"testing"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	appc "github.com/k0sproject/k0s/pkg/autopilot/controller/plans/core"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMarkCanaries ensures that canary targets are selected either explicitly
+// by name, or by count in alphabetical order.
+func TestMarkCanaries(t *testing.T) {
+	var tests = []struct {
+		name     string
+		canary   *apv1beta2.PlanCommandTargetCanary
+		expected []string
+	}{
+		{"None", nil, nil},
+		{"Count", &apv1beta2.PlanCommandTargetCanary{Count: 2}, []string{"aaa", "bbb"}},
+		{"CountExceedsTargets", &apv1beta2.PlanCommandTargetCanary{Count: 5}, []string{"ccc", "aaa", "bbb"}},
+		{"Nodes", &apv1beta2.PlanCommandTargetCanary{Nodes: []string{"ccc", "zzz"}, Count: 2}, []string{"ccc"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			targets := []apv1beta2.PlanCommandTargetStatus{
+				apv1beta2.NewPlanCommandTargetStatus("ccc", appc.SignalPending),
+				apv1beta2.NewPlanCommandTargetStatus("aaa", appc.SignalPending),
+				apv1beta2.NewPlanCommandTargetStatus("bbb", appc.SignalPending),
+			}
+
+			MarkCanaries(targets, test.canary)
+
+			var canaries []string
+			for _, target := range FindCanaries(targets) {
+				canaries = append(canaries, target.Name)
+			}
+			assert.Equal(t, test.expected, canaries)
+		})
+	}
+}
@@ -65,3 +65,29 @@ func IsNotRecoverable(groups ...[]apv1beta2.PlanCommandTargetStatus) bool {
 
 	return false
 }
+
+func IsRolledBack(groups ...[]apv1beta2.PlanCommandTargetStatus) bool {
+	for _, group := range groups {
+		for _, target := range group {
+			if target.State == appc.SignalRolledBack {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// FindHookFailed returns the name of the first PlanCommandTargetStatus whose
+// update hooks have failed.
+func FindHookFailed(groups ...[]apv1beta2.PlanCommandTargetStatus) (string, bool) {
+	for _, group := range groups {
+		for _, target := range group {
+			if target.State == appc.SignalHookFailed {
+				return target.Name, true
+			}
+		}
+	}
+
+	return "", false
+}
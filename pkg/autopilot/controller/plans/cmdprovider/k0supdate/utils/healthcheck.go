@@ -0,0 +1,165 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	crcli "sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// httpHealthCheckTimeout is the timeout of a single HTTP health check request.
+const httpHealthCheckTimeout = 10 * time.Second
+
+// RunHealthChecks runs all of the provided health checks, returning an error
+// describing the first check that didn't pass.
+func RunHealthChecks(ctx context.Context, client crcli.Client, checks apv1beta2.PlanCommandHealthChecks) error {
+	if checks.NodesReady {
+		if err := checkNodesReady(ctx, client); err != nil {
+			return err
+		}
+	}
+
+	for _, workload := range checks.Workloads {
+		if err := checkWorkloadAvailable(ctx, client, workload); err != nil {
+			return err
+		}
+	}
+
+	for _, endpoint := range checks.HTTP {
+		if err := checkHTTP(ctx, endpoint.URL); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkNodesReady ensures that every node in the cluster is ready.
+func checkNodesReady(ctx context.Context, client crcli.Client) error {
+	var nodes corev1.NodeList
+	if err := client.List(ctx, &nodes); err != nil {
+		return fmt.Errorf("unable to list nodes: %w", err)
+	}
+
+	for _, node := range nodes.Items {
+		ready := false
+		for _, cond := range node.Status.Conditions {
+			if cond.Type == corev1.NodeReady {
+				ready = cond.Status == corev1.ConditionTrue
+				break
+			}
+		}
+
+		if !ready {
+			return fmt.Errorf("node %s is not ready", node.Name)
+		}
+	}
+
+	return nil
+}
+
+// checkWorkloadAvailable ensures that all replicas of a workload are updated
+// to its latest revision and available.
+func checkWorkloadAvailable(ctx context.Context, client crcli.Client, workload apv1beta2.PlanCommandHealthCheckWorkload) error {
+	key := types.NamespacedName{Namespace: workload.Namespace, Name: workload.Name}
+	name := fmt.Sprintf("%s %s", workload.Kind, key)
+
+	var available bool
+	switch workload.Kind {
+	case "Deployment":
+		var deployment appsv1.Deployment
+		if err := client.Get(ctx, key, &deployment); err != nil {
+			return fmt.Errorf("unable to get %s: %w", name, err)
+		}
+
+		desired := int32(1)
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+
+		status := deployment.Status
+		available = status.ObservedGeneration >= deployment.Generation &&
+			status.UpdatedReplicas == desired &&
+			status.AvailableReplicas == desired
+
+	case "DaemonSet":
+		var daemonSet appsv1.DaemonSet
+		if err := client.Get(ctx, key, &daemonSet); err != nil {
+			return fmt.Errorf("unable to get %s: %w", name, err)
+		}
+
+		status := daemonSet.Status
+		available = status.ObservedGeneration >= daemonSet.Generation &&
+			status.UpdatedNumberScheduled == status.DesiredNumberScheduled &&
+			status.NumberAvailable == status.DesiredNumberScheduled
+
+	case "StatefulSet":
+		var statefulSet appsv1.StatefulSet
+		if err := client.Get(ctx, key, &statefulSet); err != nil {
+			return fmt.Errorf("unable to get %s: %w", name, err)
+		}
+
+		desired := int32(1)
+		if statefulSet.Spec.Replicas != nil {
+			desired = *statefulSet.Spec.Replicas
+		}
+
+		status := statefulSet.Status
+		available = status.ObservedGeneration >= statefulSet.Generation &&
+			status.UpdatedReplicas == desired &&
+			status.ReadyReplicas == desired
+
+	default:
+		return fmt.Errorf("unsupported workload kind %q", workload.Kind)
+	}
+
+	if !available {
+		return fmt.Errorf("%s is not available", name)
+	}
+
+	return nil
+}
+
+// checkHTTP ensures that the provided URL responds with a 2xx status code.
+func checkHTTP(ctx context.Context, url string) error {
+	ctx, cancel := context.WithTimeout(ctx, httpHealthCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("invalid health check URL %s: %w", url, err)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("health check request to %s failed: %w", url, err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("health check request to %s returned status %s", url, resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	crcli "sigs.k8s.io/controller-runtime/pkg/client"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// TestRunHealthChecks ensures that workload and HTTP health checks only pass
+// if the workloads are available and the endpoints respond successfully.
+func TestRunHealthChecks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	deployment := func(available int32) crcli.Object {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "app", Generation: 2},
+			Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(2)},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: 2,
+				UpdatedReplicas:    2,
+				AvailableReplicas:  available,
+			},
+		}
+	}
+
+	workload := apv1beta2.PlanCommandHealthCheckWorkload{Kind: "Deployment", Namespace: "default", Name: "app"}
+
+	var tests = []struct {
+		name        string
+		objects     []crcli.Object
+		checks      apv1beta2.PlanCommandHealthChecks
+		expectedErr string
+	}{
+		{"NoChecks", nil, apv1beta2.PlanCommandHealthChecks{}, ""},
+		{
+			"WorkloadAvailable",
+			[]crcli.Object{deployment(2)},
+			apv1beta2.PlanCommandHealthChecks{Workloads: []apv1beta2.PlanCommandHealthCheckWorkload{workload}},
+			"",
+		},
+		{
+			"WorkloadUnavailable",
+			[]crcli.Object{deployment(1)},
+			apv1beta2.PlanCommandHealthChecks{Workloads: []apv1beta2.PlanCommandHealthCheckWorkload{workload}},
+			"Deployment default/app is not available",
+		},
+		{
+			"WorkloadMissing",
+			nil,
+			apv1beta2.PlanCommandHealthChecks{Workloads: []apv1beta2.PlanCommandHealthCheckWorkload{workload}},
+			"unable to get Deployment default/app",
+		},
+		{
+			"HTTPHealthy",
+			nil,
+			apv1beta2.PlanCommandHealthChecks{HTTP: []apv1beta2.PlanCommandHealthCheckHTTP{{URL: server.URL + "/healthy"}}},
+			"",
+		},
+		{
+			"HTTPUnhealthy",
+			nil,
+			apv1beta2.PlanCommandHealthChecks{HTTP: []apv1beta2.PlanCommandHealthCheckHTTP{{URL: server.URL + "/unhealthy"}}},
+			"returned status 503 Service Unavailable",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := crfake.NewClientBuilder().WithObjects(test.objects...).WithScheme(scheme.Scheme).Build()
+
+			err := RunHealthChecks(context.TODO(), client, test.checks)
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, test.expectedErr)
+			}
+		})
+	}
+}
@@ -19,8 +19,10 @@ import (
 	"time"
 
 	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	apdl "github.com/k0sproject/k0s/pkg/autopilot/download"
 	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	crcli "sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -61,3 +63,51 @@ func UpdatePlanCommandTargetStatusByName(name string, status apv1beta2.PlanComma
 
 	return false
 }
+
+// SignalVerification converts the verification settings of a plan command into
+// the verification requirements of a signal command. This is nil if the plan
+// command doesn't enforce verification.
+func SignalVerification(verification *apv1beta2.PlanCommandVerification) *apsigv2.CommandVerification {
+	if verification == nil || verification.Disabled {
+		return nil
+	}
+
+	return &apsigv2.CommandVerification{
+		PublicKeys: verification.PublicKeys,
+	}
+}
+
+// SignalHooks converts the hooks of a plan command into the hooks of a signal
+// command. This is nil if the plan command doesn't define any hooks.
+func SignalHooks(hooks *apv1beta2.PlanCommandHooks) *apsigv2.CommandHooks {
+	if hooks == nil || (len(hooks.PreUpdate) == 0 && len(hooks.PostUpdate) == 0) {
+		return nil
+	}
+
+	convert := func(hooks []apv1beta2.PlanCommandHook) []apsigv2.CommandHook {
+		var converted []apsigv2.CommandHook
+		for _, hook := range hooks {
+			converted = append(converted, apsigv2.CommandHook{
+				Command: hook.Command,
+				Timeout: hook.Timeout.Duration,
+			})
+		}
+		return converted
+	}
+
+	return &apsigv2.CommandHooks{
+		PreUpdate:  convert(hooks.PreUpdate),
+		PostUpdate: convert(hooks.PostUpdate),
+	}
+}
+
+// DownloadProxyURL returns the URL of the download proxy that a signal node
+// needs to download its update through, or an empty string if it downloads
+// the update directly. Only workers download through the proxy.
+func DownloadProxyURL(k0sAPIAddress string, node crcli.Object, proxyDownloads bool) string {
+	if _, isWorker := node.(*corev1.Node); !isWorker || !proxyDownloads {
+		return ""
+	}
+
+	return k0sAPIAddress + apdl.ProxyPath
+}
@@ -22,6 +22,8 @@ import (
 	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
 
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	cr "sigs.k8s.io/controller-runtime"
 	crcli "sigs.k8s.io/controller-runtime/pkg/client"
 	crrec "sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -81,5 +83,44 @@ func (c *planStateController) Reconcile(ctx context.Context, req cr.Request) (cr
 		return cr.Result{}, fmt.Errorf("unable to update plan '%s' with status: %w", req.NamespacedName, err)
 	}
 
+	if planCopy.Status.State != plan.Status.State {
+		if err := c.client.Create(ctx, planStateTransitionEvent(planCopy, plan.Status.State)); err != nil {
+			logger.Warnf("Unable to create event for plan '%s': %v", req.NamespacedName, err)
+		}
+	}
+
 	return cr.Result{}, nil
 }
+
+// planStateTransitionEvent creates an event that reports the transition of the
+// given plan from the previous state to its current state.
+func planStateTransitionEvent(plan *apv1beta2.Plan, previous apv1beta2.PlanStateType) *corev1.Event {
+	eventType := corev1.EventTypeWarning
+	switch plan.Status.State {
+	case PlanSchedulable, PlanSchedulableWait, PlanCompleted:
+		eventType = corev1.EventTypeNormal
+	}
+
+	now := metav1.Now()
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "k0s.",
+			Namespace:    metav1.NamespaceDefault,
+		},
+		EventTime:      metav1.NewMicroTime(now.Time),
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		InvolvedObject: corev1.ObjectReference{
+			Kind:            "Plan",
+			Name:            plan.Name,
+			UID:             plan.UID,
+			APIVersion:      apv1beta2.SchemeGroupVersion.String(),
+			ResourceVersion: plan.ResourceVersion,
+		},
+		Type:                eventType,
+		Reason:              "Plan" + plan.Status.State.String(),
+		Message:             fmt.Sprintf("Plan transitioned from %q to %q", previous, plan.Status.State),
+		Action:              "PlanStateTransition",
+		ReportingController: "k0s-controller",
+	}
+}
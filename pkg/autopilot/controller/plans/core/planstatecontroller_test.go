@@ -151,3 +151,49 @@ func TestReconcile(t *testing.T) {
 		})
 	}
 }
+
+// TestReconcileStateTransitionEvent ensures that state transitions of a plan
+// are reported as events.
+func TestReconcileStateTransitionEvent(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, apscheme2.AddToScheme(scheme))
+	assert.NoError(t, v1.AddToScheme(scheme))
+
+	plan := &apv1beta2.Plan{
+		ObjectMeta: metav1.ObjectMeta{Name: "autopilot"},
+		Status:     apv1beta2.PlanStatus{State: PlanSchedulable},
+	}
+	client := crfake.NewClientBuilder().WithObjects(plan).WithScheme(scheme).Build()
+
+	state := PlanSchedulable
+	handler := &fakePlanStateHandler{
+		func(ctx context.Context, plan *apv1beta2.Plan) (ProviderResult, error) {
+			plan.Status.State = state
+			return ProviderResultSuccess, nil
+		},
+	}
+	controller := NewPlanStateController(t.Name(), logrus.NewEntry(logrus.StandardLogger()), client, handler)
+	req := cr.Request{NamespacedName: types.NamespacedName{Name: "autopilot"}}
+	ctx := context.TODO()
+
+	// No transition, no event.
+	_, err := controller.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	var events v1.EventList
+	assert.NoError(t, client.List(ctx, &events))
+	assert.Empty(t, events.Items)
+
+	state = PlanApplyFailed
+	_, err = controller.Reconcile(ctx, req)
+	assert.NoError(t, err)
+	assert.NoError(t, client.List(ctx, &events))
+	if assert.Len(t, events.Items, 1) {
+		event := events.Items[0]
+		assert.Equal(t, "Plan", event.InvolvedObject.Kind)
+		assert.Equal(t, "autopilot", event.InvolvedObject.Name)
+		assert.Equal(t, v1.EventTypeWarning, event.Type)
+		assert.Equal(t, "PlanApplyFailed", event.Reason)
+		assert.Equal(t, `Plan transitioned from "Schedulable" to "ApplyFailed"`, event.Message)
+		assert.Equal(t, "k0s-controller", event.ReportingController)
+	}
+}
@@ -32,6 +32,8 @@ var (
 	PlanRestricted          apv1beta2.PlanStateType = "Restricted"
 	PlanMissingSignalNode   apv1beta2.PlanStateType = "MissingSignalNode"
 	PlanApplyFailed         apv1beta2.PlanStateType = "ApplyFailed"
+	PlanHealthChecksFailed  apv1beta2.PlanStateType = "HealthChecksFailed"
+	PlanRolledBack          apv1beta2.PlanStateType = "RolledBack"
 )
 
 // PlanCommandStatusType
@@ -42,6 +44,8 @@ var (
 	SignalMissingNode     apv1beta2.PlanCommandTargetStateType = "SignalMissingNode"
 	SignalMissingPlatform apv1beta2.PlanCommandTargetStateType = "SignalMissingPlatform"
 	SignalApplyFailed     apv1beta2.PlanCommandTargetStateType = "SignalApplyFailed"
+	SignalRolledBack      apv1beta2.PlanCommandTargetStateType = "SignalRolledBack"
+	SignalHookFailed      apv1beta2.PlanCommandTargetStateType = "SignalHookFailed"
 )
 
 type ProviderResult int
@@ -36,12 +36,12 @@ import (
 
 // RegisterControllers registers all of the autopilot controllers used by `plans`
 // to the controller-runtime manager when running in 'controller' mode.
-func RegisterControllers(ctx context.Context, logger *logrus.Entry, mgr crman.Manager, cf kubernetes.ClientFactoryInterface, leaderMode bool, controllerDelegateMap apdel.ControllerDelegateMap, excludeFromPlans []string) error {
+func RegisterControllers(ctx context.Context, logger *logrus.Entry, mgr crman.Manager, cf kubernetes.ClientFactoryInterface, leaderMode bool, controllerDelegateMap apdel.ControllerDelegateMap, excludeFromPlans []string, k0sAPIAddress string) error {
 	logger = logger.WithField("controller", "plans")
 
 	cmdProviders := []appc.PlanCommandProvider{
-		appk0supdate.NewK0sUpdatePlanCommandProvider(logger, mgr.GetClient(), controllerDelegateMap, cf, excludeFromPlans),
-		appagupdate.NewAirgapUpdatePlanCommandProvider(logger, mgr.GetClient(), controllerDelegateMap, cf, excludeFromPlans),
+		appk0supdate.NewK0sUpdatePlanCommandProvider(logger, mgr.GetClient(), controllerDelegateMap, cf, excludeFromPlans, k0sAPIAddress),
+		appagupdate.NewAirgapUpdatePlanCommandProvider(logger, mgr.GetClient(), controllerDelegateMap, cf, excludeFromPlans, k0sAPIAddress),
 	}
 
 	if leaderMode {
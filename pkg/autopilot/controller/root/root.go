@@ -28,6 +28,7 @@ type RootConfig struct {
 	MetricsBindAddr     string
 	HealthProbeBindAddr string
 	ExcludeFromPlans    []string
+	K0sAPIAddress       string
 }
 
 // Root is the 'root' of all controllers
@@ -165,9 +165,7 @@ func (sc *setupController) createControlNode(ctx context.Context, cf apcli.Facto
 		return err
 	}
 
-	node.Status = apv1beta2.ControlNodeStatus{
-		Addresses: addresses,
-	}
+	node.Status.Addresses = addresses
 
 	logger.Infof("Updating controlnode status '%s'", name)
 	if node, err = client.AutopilotV1beta2().ControlNodes().UpdateStatus(ctx, node, metav1.UpdateOptions{}); err != nil {
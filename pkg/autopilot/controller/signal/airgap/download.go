@@ -16,6 +16,8 @@ package airgap
 
 import (
 	"crypto/sha256"
+	"fmt"
+	"net/http"
 	"path"
 
 	apconst "github.com/k0sproject/k0s/pkg/autopilot/constant"
@@ -25,6 +27,7 @@ import (
 	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/rest"
 	cr "sigs.k8s.io/controller-runtime"
 	crcli "sigs.k8s.io/controller-runtime/pkg/client"
 	crman "sigs.k8s.io/controller-runtime/pkg/manager"
@@ -32,7 +35,8 @@ import (
 )
 
 type downloadManfiestBuilderAirgap struct {
-	k0sDataDir string
+	k0sDataDir  string
+	proxyClient *http.Client
 }
 
 var _ apsigcomm.DownloadManifestBuilder = (*downloadManfiestBuilderAirgap)(nil)
@@ -46,11 +50,16 @@ var _ apsigcomm.DownloadManifestBuilder = (*downloadManfiestBuilderAirgap)(nil)
 func registerDownloadController(logger *logrus.Entry, mgr crman.Manager, eventFilter crpred.Predicate, delegate apdel.ControllerDelegate, k0sDataDir string) error {
 	logger.Infof("Registering airgap 'downloading' reconciler for '%s'", delegate.Name())
 
+	proxyClient, err := rest.HTTPClientFor(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("unable to create download proxy client: %w", err)
+	}
+
 	return cr.NewControllerManagedBy(mgr).
 		For(delegate.CreateObject()).
 		WithEventFilter(eventFilter).
 		Complete(
-			apsigcomm.NewDownloadController(logger, mgr.GetClient(), delegate, &downloadManfiestBuilderAirgap{k0sDataDir: k0sDataDir}),
+			apsigcomm.NewDownloadController(logger, mgr.GetClient(), delegate, &downloadManfiestBuilderAirgap{k0sDataDir: k0sDataDir, proxyClient: proxyClient}),
 		)
 }
 
@@ -63,9 +72,14 @@ func (b downloadManfiestBuilderAirgap) Build(signalNode crcli.Object, signalData
 			ExpectedHash: signalData.Command.AirgapUpdate.Sha256,
 			Hasher:       sha256.New(),
 			DownloadDir:  path.Join(b.k0sDataDir, apconst.K0sImagesDir),
+			Verification: apsigcomm.DownloadVerification(signalData.Command.AirgapUpdate.Verification),
 		},
 		SuccessState: apsigcomm.Completed,
 	}
 
+	if proxyURL := signalData.Command.AirgapUpdate.ProxyURL; proxyURL != "" {
+		apsigcomm.ProxyDownload(&m.Config, proxyURL, b.proxyClient)
+	}
+
 	return m, nil
 }
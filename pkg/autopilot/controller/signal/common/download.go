@@ -17,6 +17,7 @@ package common
 import (
 	"context"
 	"fmt"
+	"net/http"
 
 	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
 	apdl "github.com/k0sproject/k0s/pkg/autopilot/download"
@@ -106,3 +107,28 @@ func (r *downloadController) Reconcile(ctx context.Context, req cr.Request) (cr.
 
 	return cr.Result{}, nil
 }
+
+// DownloadVerification converts the verification requirements of a signal
+// command into the verification of a download. This is nil if the command
+// doesn't require verification.
+func DownloadVerification(verification *apsigv2.CommandVerification) *apdl.Verification {
+	if verification == nil {
+		return nil
+	}
+
+	return &apdl.Verification{
+		PublicKeys: verification.PublicKeys,
+	}
+}
+
+// ProxyDownload makes a download go through the download proxy of the
+// controllers at proxyURL, using the provided HTTP client. Signatures are
+// downloaded through the proxy as well.
+func ProxyDownload(config *apdl.Config, proxyURL string, client *http.Client) {
+	if config.Verification != nil && config.Verification.SignatureURL == "" {
+		config.Verification.SignatureURL = apdl.ProxiedURL(proxyURL, config.URL+apdl.SignatureSuffix)
+	}
+
+	config.URL = apdl.ProxiedURL(proxyURL, config.URL)
+	config.HTTPClient = client
+}
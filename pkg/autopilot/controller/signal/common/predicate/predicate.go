@@ -41,10 +41,18 @@ func SignalNamePredicate(hostname string) crpred.Predicate {
 }
 
 // SignalDataStatusPredicate creates a predicate that ensures that SignalData
-// status matches the provided value.
-func SignalDataStatusPredicate(status string) SignalDataPredicate {
+// status matches any of the provided values.
+func SignalDataStatusPredicate(statuses ...string) SignalDataPredicate {
 	return func(signalData apsigv2.SignalData) bool {
-		return signalData.Status != nil && signalData.Status.Status == status
+		if signalData.Status == nil {
+			return false
+		}
+		for _, status := range statuses {
+			if signalData.Status.Status == status {
+				return true
+			}
+		}
+		return false
 	}
 }
 
@@ -15,8 +15,12 @@
 package common
 
 const (
-	Completed = "Completed"
-	Failed    = "Failed"
+	Completed  = "Completed"
+	Failed     = "Failed"
+	RolledBack = "RolledBack"
 
 	FailedDownload = "FailedDownload"
+
+	FailedPreUpdateHooks  = "FailedPreUpdateHooks"
+	FailedPostUpdateHooks = "FailedPostUpdateHooks"
 )
@@ -18,6 +18,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"path"
@@ -127,6 +128,12 @@ func (r *applyingUpdate) Reconcile(ctx context.Context, req cr.Request) (cr.Resu
 		return cr.Result{}, fmt.Errorf("unable to chmod update file '%s': %w", updateFilename, err)
 	}
 
+	// Keep the current binary, so that the update can be rolled back if the
+	// updated k0s doesn't become healthy.
+	if err := backupK0sBinary(r.k0sBinaryDir); err != nil {
+		logger.Warnf("Unable to keep the current k0s binary, rollback won't be possible: %v", err)
+	}
+
 	// Perform the update atomically
 	if err := os.Rename(updateFilenamePath, filepath.Join(r.k0sBinaryDir, "k0s")); err != nil {
 		return cr.Result{}, fmt.Errorf("unable to update (rename) to the new file: %w", err)
@@ -147,3 +154,36 @@ func (r *applyingUpdate) Reconcile(ctx context.Context, req cr.Request) (cr.Resu
 
 	return cr.Result{}, nil
 }
+
+// backupK0sBinary keeps the k0s binary in the provided directory as
+// `PreviousK0sBinary`. It is hard-linked if possible, and copied otherwise.
+func backupK0sBinary(k0sBinaryDir string) error {
+	current := filepath.Join(k0sBinaryDir, "k0s")
+	previous := filepath.Join(k0sBinaryDir, PreviousK0sBinary)
+
+	if err := os.Remove(previous); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("unable to remove '%s': %w", previous, err)
+	}
+
+	if err := os.Link(current, previous); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(current)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(previous, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0755)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		return err
+	}
+
+	return dst.Close()
+}
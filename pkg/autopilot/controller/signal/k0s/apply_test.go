@@ -16,11 +16,14 @@ package k0s
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	crev "sigs.k8s.io/controller-runtime/pkg/event"
 )
@@ -230,3 +233,23 @@ func TestApplyingUpdateEventFilter(t *testing.T) {
 		})
 	}
 }
+
+// TestBackupK0sBinary ensures that the current k0s binary is kept, replacing
+// any previous backup.
+func TestBackupK0sBinary(t *testing.T) {
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "k0s"), []byte("current"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, PreviousK0sBinary), []byte("outdated"), 0755))
+
+	require.NoError(t, backupK0sBinary(binDir))
+
+	content, err := os.ReadFile(filepath.Join(binDir, PreviousK0sBinary))
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(content))
+
+	// Replacing the binary must not affect the backup.
+	require.NoError(t, os.Rename(filepath.Join(binDir, "k0s"), filepath.Join(binDir, "k0s.tmp")))
+	content, err = os.ReadFile(filepath.Join(binDir, PreviousK0sBinary))
+	require.NoError(t, err)
+	assert.Equal(t, "current", string(content))
+}
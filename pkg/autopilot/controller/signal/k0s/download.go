@@ -16,6 +16,8 @@ package k0s
 
 import (
 	"crypto/sha256"
+	"fmt"
+	"net/http"
 
 	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
 	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
@@ -25,6 +27,7 @@ import (
 	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
 
 	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/rest"
 	cr "sigs.k8s.io/controller-runtime"
 	crcli "sigs.k8s.io/controller-runtime/pkg/client"
 	crev "sigs.k8s.io/controller-runtime/pkg/event"
@@ -55,6 +58,7 @@ func downloadEventFilter(hostname string, handler apsigpred.ErrorHandler) crpred
 
 type downloadManifestBuilderK0s struct {
 	k0sBinaryDir string
+	proxyClient  *http.Client
 }
 
 var _ apsigcomm.DownloadManifestBuilder = (*downloadManifestBuilderK0s)(nil)
@@ -68,12 +72,18 @@ var _ apsigcomm.DownloadManifestBuilder = (*downloadManifestBuilderK0s)(nil)
 func registerDownloading(logger *logrus.Entry, mgr crman.Manager, eventFilter crpred.Predicate, delegate apdel.ControllerDelegate, k0sBinaryDir string) error {
 	logger.Infof("Registering k0s 'downloading' reconciler for '%s'", delegate.Name())
 
+	proxyClient, err := rest.HTTPClientFor(mgr.GetConfig())
+	if err != nil {
+		return fmt.Errorf("unable to create download proxy client: %w", err)
+	}
+
 	return cr.NewControllerManagedBy(mgr).
 		For(delegate.CreateObject()).
 		WithEventFilter(eventFilter).
 		Complete(
 			apsigcomm.NewDownloadController(logger, mgr.GetClient(), delegate, &downloadManifestBuilderK0s{
 				k0sBinaryDir: k0sBinaryDir,
+				proxyClient:  proxyClient,
 			}),
 		)
 }
@@ -87,8 +97,13 @@ func (b downloadManifestBuilderK0s) Build(signalNode crcli.Object, signalData ap
 			ExpectedHash: signalData.Command.K0sUpdate.Sha256,
 			Hasher:       sha256.New(),
 			DownloadDir:  b.k0sBinaryDir,
+			Verification: apsigcomm.DownloadVerification(signalData.Command.K0sUpdate.Verification),
 		},
-		SuccessState: Cordoning,
+		SuccessState: preUpdateHooks.initialState(signalData),
+	}
+
+	if proxyURL := signalData.Command.K0sUpdate.ProxyURL; proxyURL != "" {
+		apsigcomm.ProxyDownload(&m.Config, proxyURL, b.proxyClient)
 	}
 
 	return m, nil
@@ -0,0 +1,239 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k0s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
+	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
+	apsigcomm "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common"
+	apsigpred "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common/predicate"
+	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
+
+	"github.com/sirupsen/logrus"
+	cr "sigs.k8s.io/controller-runtime"
+	crcli "sigs.k8s.io/controller-runtime/pkg/client"
+	crev "sigs.k8s.io/controller-runtime/pkg/event"
+	crman "sigs.k8s.io/controller-runtime/pkg/manager"
+	crpred "sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+const (
+	// DefaultHookTimeout is the time in which an update hook needs to finish,
+	// unless the hook specifies its own timeout.
+	DefaultHookTimeout = 5 * time.Minute
+
+	// hookRetryInterval is the time after which failed update hooks are retried.
+	hookRetryInterval = 1 * time.Minute
+)
+
+// hookPhase describes at which point of an update a set of hooks is run.
+type hookPhase struct {
+	name      string
+	status    string
+	failed    string
+	nextState string
+	hooks     func(*apsigv2.CommandHooks) []apsigv2.CommandHook
+}
+
+var (
+	preUpdateHooks = hookPhase{
+		name:      "pre-update",
+		status:    PreUpdateHooks,
+		failed:    apsigcomm.FailedPreUpdateHooks,
+		nextState: Cordoning,
+		hooks:     func(h *apsigv2.CommandHooks) []apsigv2.CommandHook { return h.PreUpdate },
+	}
+
+	postUpdateHooks = hookPhase{
+		name:      "post-update",
+		status:    PostUpdateHooks,
+		failed:    apsigcomm.FailedPostUpdateHooks,
+		nextState: apsigcomm.Completed,
+		hooks:     func(h *apsigv2.CommandHooks) []apsigv2.CommandHook { return h.PostUpdate },
+	}
+)
+
+// initialState returns the status in which the hooks of this phase are run, or
+// the state that follows this phase if there are no hooks to be run.
+func (p hookPhase) initialState(signalData apsigv2.SignalData) string {
+	if hooks := signalData.Command.K0sUpdate.Hooks; hooks != nil && len(p.hooks(hooks)) > 0 {
+		return p.status
+	}
+
+	return p.nextState
+}
+
+// hooksEventFilter creates a controller-runtime predicate that governs which objects
+// will make it into reconciliation, and which will be ignored.
+func hooksEventFilter(hostname string, phase hookPhase, handler apsigpred.ErrorHandler) crpred.Predicate {
+	return crpred.And(
+		crpred.AnnotationChangedPredicate{},
+		apsigpred.SignalNamePredicate(hostname),
+		apsigpred.NewSignalDataPredicateAdapter(handler).And(
+			signalDataUpdateCommandK0sPredicate(),
+			apsigpred.SignalDataStatusPredicate(phase.status, phase.failed),
+		),
+		apcomm.FalseFuncs{
+			CreateFunc: func(ce crev.CreateEvent) bool {
+				return true
+			},
+			UpdateFunc: func(ue crev.UpdateEvent) bool {
+				return true
+			},
+		},
+	)
+}
+
+type hooks struct {
+	log      *logrus.Entry
+	client   crcli.Client
+	delegate apdel.ControllerDelegate
+	phase    hookPhase
+}
+
+// registerHooks registers a 'hooks' controller for the given phase to the
+// controller-runtime manager.
+//
+// This controller is only interested when autopilot signaling annotations have
+// moved to the status of its phase, or to the failed status of its phase. It
+// runs the hooks of the phase on the host. If any of them fails, they are
+// retried periodically, which pauses the update of the node.
+func registerHooks(logger *logrus.Entry, mgr crman.Manager, eventFilter crpred.Predicate, delegate apdel.ControllerDelegate, phase hookPhase) error {
+	logger.Infof("Registering '%s-hooks' reconciler for '%s'", phase.name, delegate.Name())
+
+	return cr.NewControllerManagedBy(mgr).
+		For(delegate.CreateObject()).
+		WithEventFilter(eventFilter).
+		Complete(
+			&hooks{
+				log:      logger.WithFields(logrus.Fields{"reconciler": phase.name + "-hooks", "object": delegate.Name()}),
+				client:   mgr.GetClient(),
+				delegate: delegate,
+				phase:    phase,
+			},
+		)
+}
+
+// Reconcile for the 'hooks' reconciler runs all of the hooks of its phase, moving
+// to the next state once all of them succeeded.
+func (r *hooks) Reconcile(ctx context.Context, req cr.Request) (cr.Result, error) {
+	signalNode := r.delegate.CreateObject()
+	if err := r.client.Get(ctx, req.NamespacedName, signalNode); err != nil {
+		return cr.Result{}, fmt.Errorf("unable to get signal for node='%s': %w", req.NamespacedName.Name, err)
+	}
+
+	logger := r.log.WithField("signalnode", signalNode.GetName())
+
+	var signalData apsigv2.SignalData
+	if err := signalData.Unmarshal(signalNode.GetAnnotations()); err != nil {
+		return cr.Result{}, fmt.Errorf("unable to unmarshal signal data for node='%s': %w", req.NamespacedName.Name, err)
+	}
+
+	// Failed hooks are only retried after the retry interval.
+
+	if signalData.Status.Status == r.phase.failed {
+		failedAt, err := time.Parse(time.RFC3339, signalData.Status.Timestamp)
+		if err != nil {
+			return cr.Result{}, fmt.Errorf("invalid signaling response timestamp '%s': %w", signalData.Status.Timestamp, err)
+		}
+
+		if wait := hookRetryInterval - time.Since(failedAt); wait > 0 {
+			return cr.Result{RequeueAfter: wait}, nil
+		}
+
+		logger.Infof("Retrying failed %s hooks", r.phase.name)
+	}
+
+	var hooks []apsigv2.CommandHook
+	if signalData.Command.K0sUpdate.Hooks != nil {
+		hooks = r.phase.hooks(signalData.Command.K0sUpdate.Hooks)
+	}
+
+	env := []string{
+		"K0S_AUTOPILOT_PLAN_ID=" + signalData.PlanID,
+		"K0S_AUTOPILOT_NODE_NAME=" + signalNode.GetName(),
+		"K0S_AUTOPILOT_UPDATE_VERSION=" + signalData.Command.K0sUpdate.Version,
+		"K0S_AUTOPILOT_HOOK_PHASE=" + r.phase.name,
+	}
+
+	for i, hook := range hooks {
+		logger.Infof("Running %s hook %d: %v", r.phase.name, i, hook.Command)
+		if err := runHook(ctx, logger, hook, env); err != nil {
+			logger.WithError(err).Errorf("The %s hook %d failed, retrying in %s", r.phase.name, i, hookRetryInterval)
+			if err := r.moveToNextState(ctx, signalNode, signalData, r.phase.failed); err != nil {
+				return cr.Result{}, err
+			}
+
+			return cr.Result{RequeueAfter: hookRetryInterval}, nil
+		}
+	}
+
+	return cr.Result{}, r.moveToNextState(ctx, signalNode, signalData, r.phase.nextState)
+}
+
+func (r *hooks) moveToNextState(ctx context.Context, signalNode crcli.Object, signalData apsigv2.SignalData, state string) error {
+	signalNodeCopy := r.delegate.DeepCopy(signalNode)
+	signalData.Status = apsigv2.NewStatus(state)
+
+	if err := signalData.Marshal(signalNodeCopy.GetAnnotations()); err != nil {
+		return fmt.Errorf("unable to marshal signal data for node='%s': %w", signalNode.GetName(), err)
+	}
+
+	r.log.WithField("signalnode", signalNode.GetName()).Infof("Updating signaling response to '%s'", signalData.Status.Status)
+	if err := r.client.Update(ctx, signalNodeCopy, &crcli.UpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update signal node with '%s' status: %w", signalData.Status.Status, err)
+	}
+
+	return nil
+}
+
+// runHook runs the command of a hook, logging its output.
+func runHook(ctx context.Context, logger *logrus.Entry, hook apsigv2.CommandHook, env []string) error {
+	if len(hook.Command) == 0 {
+		return errors.New("no command given")
+	}
+
+	timeout := hook.Timeout
+	if timeout <= 0 {
+		timeout = DefaultHookTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	out := logger.Writer()
+	defer out.Close()
+
+	cmd := exec.CommandContext(ctx, hook.Command[0], hook.Command[1:]...)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("timed out after %s: %w", timeout, err)
+		}
+		return err
+	}
+
+	return nil
+}
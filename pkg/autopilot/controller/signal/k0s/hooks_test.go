@@ -0,0 +1,147 @@
+//go:build !windows
+
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k0s
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
+	apsigcomm "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common"
+	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
+	apscheme "github.com/k0sproject/k0s/pkg/client/clientset/scheme"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	crrec "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestHooks ensures that update hooks move the signal node to the next state
+// once they succeed, and are retried periodically if they fail.
+func TestHooks(t *testing.T) {
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	signalData := func(hooks *apsigv2.CommandHooks, status string, timestamp time.Time) apsigv2.SignalData {
+		commandID := 123
+		return apsigv2.SignalData{
+			PlanID:  "abc123",
+			Created: "now",
+			Command: apsigv2.Command{
+				ID: &commandID,
+				K0sUpdate: &apsigv2.CommandK0sUpdate{
+					URL:     "https://k0s.example.com/downloads/k0s-v99.99.99",
+					Version: "v99.99.99",
+					Hooks:   hooks,
+				},
+			},
+			Status: &apsigv2.Status{Status: status, Timestamp: timestamp.Format(time.RFC3339)},
+		}
+	}
+
+	hookOutput := filepath.Join(t.TempDir(), "hook")
+	succeeding := []apsigv2.CommandHook{
+		{Command: []string{"sh", "-c", `echo "$K0S_AUTOPILOT_HOOK_PHASE $K0S_AUTOPILOT_NODE_NAME $K0S_AUTOPILOT_UPDATE_VERSION" > "$0"`, hookOutput}},
+	}
+	failing := []apsigv2.CommandHook{{Command: []string{"false"}}}
+	timingOut := []apsigv2.CommandHook{{Command: []string{"sleep", "10"}, Timeout: 10 * time.Millisecond}}
+
+	var tests = []struct {
+		name            string
+		phase           hookPhase
+		data            apsigv2.SignalData
+		expectedStatus  string
+		expectedRequeue bool
+		expectedOutput  string
+	}{
+		{"PreUpdateSucceeded", preUpdateHooks, signalData(&apsigv2.CommandHooks{PreUpdate: succeeding}, PreUpdateHooks, time.Now()), Cordoning, false, "pre-update foo v99.99.99\n"},
+		{"PostUpdateSucceeded", postUpdateHooks, signalData(&apsigv2.CommandHooks{PostUpdate: succeeding}, PostUpdateHooks, time.Now()), apsigcomm.Completed, false, "post-update foo v99.99.99\n"},
+		{"PreUpdateFailed", preUpdateHooks, signalData(&apsigv2.CommandHooks{PreUpdate: failing}, PreUpdateHooks, time.Now()), apsigcomm.FailedPreUpdateHooks, true, ""},
+		{"PostUpdateTimedOut", postUpdateHooks, signalData(&apsigv2.CommandHooks{PostUpdate: timingOut}, PostUpdateHooks, time.Now()), apsigcomm.FailedPostUpdateHooks, true, ""},
+		{"RetryPending", preUpdateHooks, signalData(&apsigv2.CommandHooks{PreUpdate: succeeding}, apsigcomm.FailedPreUpdateHooks, time.Now()), apsigcomm.FailedPreUpdateHooks, true, ""},
+		{"RetrySucceeded", preUpdateHooks, signalData(&apsigv2.CommandHooks{PreUpdate: succeeding}, apsigcomm.FailedPreUpdateHooks, time.Now().Add(-time.Hour)), Cordoning, false, "pre-update foo v99.99.99\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Cleanup(func() { os.Remove(hookOutput) })
+
+			annotations := make(map[string]string)
+			require.NoError(t, test.data.Marshal(annotations))
+
+			scheme := runtime.NewScheme()
+			assert.NoError(t, apscheme.AddToScheme(scheme))
+			assert.NoError(t, v1.AddToScheme(scheme))
+
+			client := crfake.NewClientBuilder().WithObjects(&v1.Node{
+				TypeMeta:   metav1.TypeMeta{Kind: "Node", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: annotations},
+			}).WithScheme(scheme).Build()
+
+			r := &hooks{
+				log:      logger,
+				client:   client,
+				delegate: apdel.NodeControllerDelegate(),
+				phase:    test.phase,
+			}
+
+			req := crrec.Request{NamespacedName: types.NamespacedName{Name: "foo"}}
+			res, err := r.Reconcile(context.TODO(), req)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedRequeue, res.RequeueAfter > 0)
+
+			signalNode := r.delegate.CreateObject()
+			require.NoError(t, client.Get(context.TODO(), req.NamespacedName, signalNode))
+
+			var signalData apsigv2.SignalData
+			require.NoError(t, signalData.Unmarshal(signalNode.GetAnnotations()))
+			if assert.NotNil(t, signalData.Status) {
+				assert.Equal(t, test.expectedStatus, signalData.Status.Status)
+			}
+
+			if test.expectedOutput == "" {
+				assert.NoFileExists(t, hookOutput)
+			} else {
+				output, err := os.ReadFile(hookOutput)
+				require.NoError(t, err)
+				assert.Equal(t, test.expectedOutput, string(output))
+			}
+		})
+	}
+}
+
+// TestHookPhaseInitialState ensures that hook phases are skipped if there are
+// no hooks to be run.
+func TestHookPhaseInitialState(t *testing.T) {
+	data := apsigv2.SignalData{Command: apsigv2.Command{K0sUpdate: &apsigv2.CommandK0sUpdate{}}}
+	assert.Equal(t, Cordoning, preUpdateHooks.initialState(data))
+	assert.Equal(t, apsigcomm.Completed, postUpdateHooks.initialState(data))
+
+	data.Command.K0sUpdate.Hooks = &apsigv2.CommandHooks{
+		PostUpdate: []apsigv2.CommandHook{{Command: []string{"true"}}},
+	}
+	assert.Equal(t, Cordoning, preUpdateHooks.initialState(data))
+	assert.Equal(t, PostUpdateHooks, postUpdateHooks.initialState(data))
+}
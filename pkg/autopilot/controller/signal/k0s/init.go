@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
 	apconst "github.com/k0sproject/k0s/pkg/autopilot/constant"
@@ -34,11 +35,24 @@ import (
 
 const (
 	Downloading     = "Downloading"
+	PreUpdateHooks  = "PreUpdateHooks"
 	Cordoning       = "Cordoning"
 	CordoningFailed = "CordoningFailed"
 	UnCordoning     = "UnCordoning"
+	PostUpdateHooks = "PostUpdateHooks"
 	ApplyingUpdate  = "ApplyingUpdate"
 	Restart         = "Restart"
+	RollingBack     = "RollingBack"
+)
+
+const (
+	// PreviousK0sBinary is the name of the backup of the k0s binary that has
+	// been replaced by an update, used to roll back failed updates.
+	PreviousK0sBinary = "k0s.previous"
+
+	// DefaultRollbackTimeout is the time in which an updated k0s needs to
+	// become healthy before the update is rolled back.
+	DefaultRollbackTimeout = 10 * time.Minute
 )
 
 // RegisterControllers registers all of the autopilot controllers used for updating `k0s`
@@ -67,6 +81,10 @@ func RegisterControllers(ctx context.Context, logger *logrus.Entry, mgr crman.Ma
 		return fmt.Errorf("unable to register k0s 'downloading' controller: %w", err)
 	}
 
+	if err := registerHooks(logger, mgr, hooksEventFilter(hostname, preUpdateHooks, apsigpred.DefaultErrorHandler(logger, "k0s pre-update-hooks")), delegate, preUpdateHooks); err != nil {
+		return fmt.Errorf("unable to register k0s 'pre-update-hooks' controller: %w", err)
+	}
+
 	if err := registerCordoning(logger, mgr, cordoningEventFilter(hostname, apsigpred.DefaultErrorHandler(logger, "k0s cordoning")), delegate); err != nil {
 		return fmt.Errorf("unable to register k0s 'cordoning' controller: %w", err)
 	}
@@ -79,14 +97,22 @@ func RegisterControllers(ctx context.Context, logger *logrus.Entry, mgr crman.Ma
 		return fmt.Errorf("unable to register k0s 'restart' controller: %w", err)
 	}
 
-	if err := registerRestarted(logger, mgr, restartedEventFilter(hostname, apsigpred.DefaultErrorHandler(logger, "k0s restarted")), delegate); err != nil {
+	if err := registerRestarted(logger, mgr, restartedEventFilter(hostname, apsigpred.DefaultErrorHandler(logger, "k0s restarted")), delegate, k0sBinaryDir); err != nil {
 		return fmt.Errorf("unable to register k0s 'restarted' controller: %w", err)
 	}
 
+	if err := registerRolledBack(logger, mgr, rolledBackEventFilter(hostname, apsigpred.DefaultErrorHandler(logger, "k0s rolled-back")), delegate); err != nil {
+		return fmt.Errorf("unable to register k0s 'rolled-back' controller: %w", err)
+	}
+
 	if err := registerUnCordoning(logger, mgr, unCordoningEventFilter(hostname, apsigpred.DefaultErrorHandler(logger, "k0s uncordoning")), delegate); err != nil {
 		return fmt.Errorf("unable to register k0s 'uncordon' controller: %w", err)
 	}
 
+	if err := registerHooks(logger, mgr, hooksEventFilter(hostname, postUpdateHooks, apsigpred.DefaultErrorHandler(logger, "k0s post-update-hooks")), delegate, postUpdateHooks); err != nil {
+		return fmt.Errorf("unable to register k0s 'post-update-hooks' controller: %w", err)
+	}
+
 	return nil
 }
 
@@ -18,14 +18,21 @@ package k0s
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
 
 	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
 	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
+	apsigcomm "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common"
 	apsigpred "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common/predicate"
 	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
 
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	cr "sigs.k8s.io/controller-runtime"
 	crcli "sigs.k8s.io/controller-runtime/pkg/client"
 	crev "sigs.k8s.io/controller-runtime/pkg/event"
@@ -33,10 +40,18 @@ import (
 	crpred "sigs.k8s.io/controller-runtime/pkg/predicate"
 )
 
+const (
+	restartedRequeueDuration = 10 * time.Second
+)
+
 type restarted struct {
-	log      *logrus.Entry
-	client   crcli.Client
-	delegate apdel.ControllerDelegate
+	log               *logrus.Entry
+	client            crcli.Client
+	delegate          apdel.ControllerDelegate
+	k0sBinaryDir      string
+	rollbackTimeout   time.Duration
+	k0sVersionHandler k0sVersionHandlerFunc
+	terminate         func() error
 }
 
 // restartedEventFilter creates a controller-runtime predicate that governs which
@@ -60,7 +75,7 @@ func restartedEventFilter(hostname string, handler apsigpred.ErrorHandler) crpre
 //
 // This controller is only interested in changes to signal nodes where its signaling
 // status is marked as `Restart`
-func registerRestarted(logger *logrus.Entry, mgr crman.Manager, eventFilter crpred.Predicate, delegate apdel.ControllerDelegate) error {
+func registerRestarted(logger *logrus.Entry, mgr crman.Manager, eventFilter crpred.Predicate, delegate apdel.ControllerDelegate, k0sBinaryDir string) error {
 	logger.Infof("Registering 'restarted' reconciler for '%s'", delegate.Name())
 
 	return cr.NewControllerManagedBy(mgr).
@@ -68,9 +83,22 @@ func registerRestarted(logger *logrus.Entry, mgr crman.Manager, eventFilter crpr
 		WithEventFilter(eventFilter).
 		Complete(
 			&restarted{
-				log:      logger.WithFields(logrus.Fields{"reconciler": "restarted", "object": delegate.Name()}),
-				client:   mgr.GetClient(),
-				delegate: delegate,
+				log:             logger.WithFields(logrus.Fields{"reconciler": "restarted", "object": delegate.Name()}),
+				client:          mgr.GetClient(),
+				delegate:        delegate,
+				k0sBinaryDir:    k0sBinaryDir,
+				rollbackTimeout: DefaultRollbackTimeout,
+				k0sVersionHandler: func() (string, error) {
+					return getK0sVersion(DefaultK0sStatusSocketPath)
+				},
+				terminate: func() error {
+					k0sPid, err := getK0sPid(DefaultK0sStatusSocketPath)
+					if err != nil {
+						return fmt.Errorf("unable to get k0s pid: %w", err)
+					}
+
+					return syscall.Kill(k0sPid, syscall.SIGTERM)
+				},
 			},
 		)
 }
@@ -83,7 +111,9 @@ func registerRestarted(logger *logrus.Entry, mgr crman.Manager, eventFilter crpr
 // when the event is "created", indicating that `k0s` has actually restarted.
 //
 // If the installed `k0s` version is the version specified in the plan (or if a `forceupdate`),
-// the plan will move to 'Completed'.
+// and the signal node is healthy, the plan will move to 'UnCordoning'. If this doesn't happen
+// within the rollback timeout, the previous `k0s` binary is restored and `k0s` is restarted
+// again, moving the plan to 'RollingBack'.
 func (r *restarted) Reconcile(ctx context.Context, req cr.Request) (cr.Result, error) {
 	signalNode := r.delegate.CreateObject()
 	if err := r.client.Get(ctx, req.NamespacedName, signalNode); err != nil {
@@ -94,7 +124,7 @@ func (r *restarted) Reconcile(ctx context.Context, req cr.Request) (cr.Result, e
 
 	// Get the current version of k0s
 	logger.Info("Determining the current version of k0s")
-	k0sVersion, err := getK0sVersion(DefaultK0sStatusSocketPath)
+	k0sVersion, err := r.k0sVersionHandler()
 	if err != nil {
 		logger.Info("Unable to determine current verion of k0s; requeuing")
 		return cr.Result{}, fmt.Errorf("unable to get k0s version: %w", err)
@@ -108,22 +138,87 @@ func (r *restarted) Reconcile(ctx context.Context, req cr.Request) (cr.Result, e
 	}
 
 	// Move to the next successful state 'UnCordoning' if our versions match
+	// and the signal node is healthy.
 
-	if k0sVersion == signalData.Command.K0sUpdate.Version || signalData.Command.K0sUpdate.ForceUpdate {
-		signalNodeCopy := r.delegate.DeepCopy(signalNode)
-		signalData.Status = apsigv2.NewStatus(UnCordoning)
+	unhealthyReason := ""
+	if k0sVersion != signalData.Command.K0sUpdate.Version && !signalData.Command.K0sUpdate.ForceUpdate {
+		unhealthyReason = fmt.Sprintf("k0s version %s is running instead of %s", k0sVersion, signalData.Command.K0sUpdate.Version)
+	} else if !isSignalNodeReady(signalNode) {
+		unhealthyReason = "node is not ready"
+	}
 
-		if err := signalData.Marshal(signalNodeCopy.GetAnnotations()); err != nil {
-			return cr.Result{}, fmt.Errorf("unable to marshal signal data for node='%s': %w", req.NamespacedName.Name, err)
-		}
+	if unhealthyReason == "" {
+		return cr.Result{}, r.moveToNextState(ctx, signalNode, signalData, UnCordoning)
+	}
 
-		logger.Infof("Updating signaling response to '%s'", signalData.Status.Status)
-		if err := r.client.Update(ctx, signalNodeCopy, &crcli.UpdateOptions{}); err != nil {
-			return cr.Result{}, fmt.Errorf("unable to update signal node with '%s' status: %w", signalData.Status.Status, err)
-		}
+	// Give the updated k0s some time to become healthy before rolling back.
+
+	restartedAt, err := time.Parse(time.RFC3339, signalData.Status.Timestamp)
+	if err != nil {
+		return cr.Result{}, fmt.Errorf("invalid signaling response timestamp '%s': %w", signalData.Status.Timestamp, err)
+	}
 
-		return cr.Result{}, nil
+	if time.Since(restartedAt) < r.rollbackTimeout {
+		logger.Infof("Updated k0s is not healthy yet (%s); requeuing", unhealthyReason)
+		return cr.Result{RequeueAfter: restartedRequeueDuration}, nil
+	}
+
+	logger.Warnf("Updated k0s didn't become healthy within %s (%s), rolling back", r.rollbackTimeout, unhealthyReason)
+
+	previous := filepath.Join(r.k0sBinaryDir, PreviousK0sBinary)
+	if _, err := os.Stat(previous); errors.Is(err, os.ErrNotExist) {
+		logger.Errorf("Unable to roll back, the previous k0s binary '%s' doesn't exist", previous)
+		return cr.Result{}, r.moveToNextState(ctx, signalNode, signalData, apsigcomm.Failed)
+	}
+
+	if err := os.Rename(previous, filepath.Join(r.k0sBinaryDir, "k0s")); err != nil {
+		return cr.Result{}, fmt.Errorf("unable to restore the previous k0s binary: %w", err)
+	}
+
+	if err := r.moveToNextState(ctx, signalNode, signalData, RollingBack); err != nil {
+		return cr.Result{}, err
+	}
+
+	// Restart into the previous k0s binary, which will move to 'RolledBack'.
+
+	logger.Info("Restarting k0s to complete the rollback")
+	if err := r.terminate(); err != nil {
+		return cr.Result{}, fmt.Errorf("unable to restart k0s: %w", err)
 	}
 
 	return cr.Result{}, nil
 }
+
+func (r *restarted) moveToNextState(ctx context.Context, signalNode crcli.Object, signalData apsigv2.SignalData, state string) error {
+	signalNodeCopy := r.delegate.DeepCopy(signalNode)
+	signalData.Status = apsigv2.NewStatus(state)
+
+	if err := signalData.Marshal(signalNodeCopy.GetAnnotations()); err != nil {
+		return fmt.Errorf("unable to marshal signal data for node='%s': %w", signalNode.GetName(), err)
+	}
+
+	r.log.WithField("signalnode", signalNode.GetName()).Infof("Updating signaling response to '%s'", signalData.Status.Status)
+	if err := r.client.Update(ctx, signalNodeCopy, &crcli.UpdateOptions{}); err != nil {
+		return fmt.Errorf("unable to update signal node with '%s' status: %w", signalData.Status.Status, err)
+	}
+
+	return nil
+}
+
+// isSignalNodeReady determines if the provided signal node is ready. Only
+// `Node` objects have a readiness condition, any other signal node is
+// considered to be ready.
+func isSignalNodeReady(signalNode crcli.Object) bool {
+	node, ok := signalNode.(*corev1.Node)
+	if !ok {
+		return true
+	}
+
+	for _, cond := range node.Status.Conditions {
+		if cond.Type == corev1.NodeReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,154 @@
+//go:build !windows
+
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k0s
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
+	apsigcomm "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common"
+	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
+	apscheme "github.com/k0sproject/k0s/pkg/client/clientset/scheme"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	crcli "sigs.k8s.io/controller-runtime/pkg/client"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+	crrec "sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// TestRestartedRollback ensures that an updated k0s that doesn't become healthy
+// within the rollback timeout gets rolled back to the previous binary.
+func TestRestartedRollback(t *testing.T) {
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	signalAnnotations := func(restartedAt time.Time) map[string]string {
+		commandID := 123
+		data := apsigv2.SignalData{
+			PlanID:  "abc123",
+			Created: "now",
+			Command: apsigv2.Command{
+				ID: &commandID,
+				K0sUpdate: &apsigv2.CommandK0sUpdate{
+					URL:     "https://k0s.example.com/downloads/k0s-v99.99.99",
+					Version: "v99.99.99",
+				},
+			},
+			Status: &apsigv2.Status{Status: Restart, Timestamp: restartedAt.Format(time.RFC3339)},
+		}
+
+		annotations := make(map[string]string)
+		require.NoError(t, data.Marshal(annotations))
+		return annotations
+	}
+
+	node := func(ready v1.ConditionStatus, restartedAt time.Time) crcli.Object {
+		return &v1.Node{
+			TypeMeta:   metav1.TypeMeta{Kind: "Node", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: signalAnnotations(restartedAt)},
+			Status: v1.NodeStatus{
+				Conditions: []v1.NodeCondition{{Type: v1.NodeReady, Status: ready}},
+			},
+		}
+	}
+
+	controlNode := &apv1beta2.ControlNode{
+		TypeMeta:   metav1.TypeMeta{Kind: "ControlNode", APIVersion: "autopilot.k0sproject.io/v1beta2"},
+		ObjectMeta: metav1.ObjectMeta{Name: "foo", Annotations: signalAnnotations(time.Now().Add(-time.Hour))},
+	}
+
+	var tests = []struct {
+		name               string
+		object             crcli.Object
+		delegate           apdel.ControllerDelegate
+		k0sVersion         string
+		previousBinary     bool
+		expectedStatus     string
+		expectedRequeue    bool
+		expectedRolledBack bool
+	}{
+		{"Healthy", node(v1.ConditionTrue, time.Now()), apdel.NodeControllerDelegate(), "v99.99.99", true, UnCordoning, false, false},
+		{"NotReadyYet", node(v1.ConditionFalse, time.Now()), apdel.NodeControllerDelegate(), "v99.99.99", true, Restart, true, false},
+		{"NotReadyTimedOut", node(v1.ConditionFalse, time.Now().Add(-time.Hour)), apdel.NodeControllerDelegate(), "v99.99.99", true, RollingBack, false, true},
+		{"WrongVersionTimedOut", controlNode, apdel.ControlNodeControllerDelegate(), "v1.0.0", true, RollingBack, false, true},
+		{"NoPreviousBinary", node(v1.ConditionFalse, time.Now().Add(-time.Hour)), apdel.NodeControllerDelegate(), "v99.99.99", false, apsigcomm.Failed, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			assert.NoError(t, apscheme.AddToScheme(scheme))
+			assert.NoError(t, v1.AddToScheme(scheme))
+
+			client := crfake.NewClientBuilder().WithObjects(test.object).WithScheme(scheme).Build()
+
+			binDir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(binDir, "k0s"), []byte("new"), 0755))
+			if test.previousBinary {
+				require.NoError(t, os.WriteFile(filepath.Join(binDir, PreviousK0sBinary), []byte("previous"), 0755))
+			}
+
+			var terminated bool
+			r := &restarted{
+				log:               logger,
+				client:            client,
+				delegate:          test.delegate,
+				k0sBinaryDir:      binDir,
+				rollbackTimeout:   DefaultRollbackTimeout,
+				k0sVersionHandler: echoedK0sVersionHandler(test.k0sVersion),
+				terminate: func() error {
+					terminated = true
+					return nil
+				},
+			}
+
+			req := crrec.Request{NamespacedName: types.NamespacedName{Name: "foo"}}
+			res, err := r.Reconcile(context.TODO(), req)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expectedRequeue, res.RequeueAfter > 0)
+			assert.Equal(t, test.expectedRolledBack, terminated)
+
+			signalNode := test.delegate.CreateObject()
+			require.NoError(t, client.Get(context.TODO(), req.NamespacedName, signalNode))
+
+			var signalData apsigv2.SignalData
+			require.NoError(t, signalData.Unmarshal(signalNode.GetAnnotations()))
+			if assert.NotNil(t, signalData.Status) {
+				assert.Equal(t, test.expectedStatus, signalData.Status.Status)
+			}
+
+			content, err := os.ReadFile(filepath.Join(binDir, "k0s"))
+			require.NoError(t, err)
+			if test.expectedRolledBack {
+				assert.Equal(t, "previous", string(content))
+				assert.NoFileExists(t, filepath.Join(binDir, PreviousK0sBinary))
+			} else {
+				assert.Equal(t, "new", string(content))
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+//go:build !windows
+
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k0s
+
+import (
+	"context"
+	"fmt"
+
+	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
+	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
+	apsigcomm "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common"
+	apsigpred "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common/predicate"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/client-go/kubernetes"
+	cr "sigs.k8s.io/controller-runtime"
+	crev "sigs.k8s.io/controller-runtime/pkg/event"
+	crman "sigs.k8s.io/controller-runtime/pkg/manager"
+	crpred "sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// rolledBackEventFilter creates a controller-runtime predicate that governs which
+// objects will make it into reconciliation, and which will be ignored.
+func rolledBackEventFilter(hostname string, handler apsigpred.ErrorHandler) crpred.Predicate {
+	return crpred.And(
+		apsigpred.SignalNamePredicate(hostname),
+		apsigpred.NewSignalDataPredicateAdapter(handler).And(
+			signalDataUpdateCommandK0sPredicate(),
+			apsigpred.SignalDataStatusPredicate(RollingBack),
+		),
+		apcomm.FalseFuncs{
+			CreateFunc: func(ce crev.CreateEvent) bool {
+				return true
+			},
+		},
+	)
+}
+
+type rolledBack struct {
+	uncordoning
+}
+
+// registerRolledBack registers the 'rolled-back' controller to the controller-runtime manager.
+//
+// This controller is only interested in signal nodes where its signaling status is
+// marked as `RollingBack`, once `k0s` has been restarted using the previous binary.
+func registerRolledBack(logger *logrus.Entry, mgr crman.Manager, eventFilter crpred.Predicate, delegate apdel.ControllerDelegate) error {
+	logger.Infof("Registering 'rolled-back' reconciler for '%s'", delegate.Name())
+
+	clientset, err := kubernetes.NewForConfig(mgr.GetConfig())
+	if err != nil {
+		return err
+	}
+
+	return cr.NewControllerManagedBy(mgr).
+		For(delegate.CreateObject()).
+		WithEventFilter(eventFilter).
+		Complete(
+			&rolledBack{
+				uncordoning{
+					log:       logger.WithFields(logrus.Fields{"reconciler": "rolled-back", "object": delegate.Name()}),
+					client:    mgr.GetClient(),
+					delegate:  delegate,
+					clientset: clientset,
+				},
+			},
+		)
+}
+
+// Reconcile for the 'rolled-back' reconciler un-cordons the node that has been
+// rolled back to its previous `k0s` version, and marks the signaling as `RolledBack`.
+func (r *rolledBack) Reconcile(ctx context.Context, req cr.Request) (cr.Result, error) {
+	signalNode := r.delegate.CreateObject()
+	if err := r.client.Get(ctx, req.NamespacedName, signalNode); err != nil {
+		return cr.Result{}, fmt.Errorf("unable to get signal for node='%s': %w", req.NamespacedName.Name, err)
+	}
+
+	if needsCordoning(signalNode) {
+		r.log.WithField("signalnode", signalNode.GetName()).Info("Un-cordoning rolled back node")
+		if err := r.unCordonNode(ctx, signalNode); err != nil {
+			return cr.Result{}, err
+		}
+	}
+
+	return cr.Result{}, r.moveToNextState(ctx, signalNode, apsigcomm.RolledBack)
+}
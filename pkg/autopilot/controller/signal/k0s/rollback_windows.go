@@ -0,0 +1,35 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k0s
+
+import (
+	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
+	apsigpred "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common/predicate"
+
+	"github.com/sirupsen/logrus"
+	crman "sigs.k8s.io/controller-runtime/pkg/manager"
+	crpred "sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// rolledBackEventFilter creates a controller-runtime predicate that governs which
+// objects will make it into reconciliation, and which will be ignored.
+func rolledBackEventFilter(hostname string, handler apsigpred.ErrorHandler) crpred.Predicate {
+	return nil
+}
+
+// registerRolledBack registers the 'rolled-back' controller to the controller-runtime manager.
+func registerRolledBack(logger *logrus.Entry, mgr crman.Manager, eventFilter crpred.Predicate, delegate apdel.ControllerDelegate) error {
+	return nil
+}
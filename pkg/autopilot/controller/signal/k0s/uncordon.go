@@ -21,7 +21,6 @@ import (
 
 	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
 	apdel "github.com/k0sproject/k0s/pkg/autopilot/controller/delegate"
-	apsigcomm "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common"
 	apsigpred "github.com/k0sproject/k0s/pkg/autopilot/controller/signal/common/predicate"
 	apsigv2 "github.com/k0sproject/k0s/pkg/autopilot/signaling/v2"
 
@@ -109,7 +108,7 @@ func (r *uncordoning) Reconcile(ctx context.Context, req cr.Request) (cr.Result,
 	if !needsCordoning(signalNode) {
 		logger.Infof("ignoring non worker node")
 
-		return cr.Result{}, r.moveToNextState(ctx, signalNode, apsigcomm.Completed)
+		return cr.Result{}, r.moveToNextState(ctx, signalNode, postUpdateHooks.initialState(signalData))
 	}
 
 	logger.Infof("starting to un-cordon node %s", signalNode.GetName())
@@ -117,7 +116,7 @@ func (r *uncordoning) Reconcile(ctx context.Context, req cr.Request) (cr.Result,
 		return cr.Result{}, err
 	}
 
-	return cr.Result{}, r.moveToNextState(ctx, signalNode, apsigcomm.Completed)
+	return cr.Result{}, r.moveToNextState(ctx, signalNode, postUpdateHooks.initialState(signalData))
 }
 
 func (r *uncordoning) moveToNextState(ctx context.Context, signalNode crcli.Object, state string) error {
@@ -186,8 +186,9 @@ func (u *updater) toPlan(nextVersion *uc.Update) apv1beta2.Plan {
 	if !updateCommandFound {
 		p.Spec.Commands = append(p.Spec.Commands, apv1beta2.PlanCommand{
 			K0sUpdate: &apv1beta2.PlanCommandK0sUpdate{
-				Version:   string(nextVersion.Version),
-				Platforms: platforms,
+				Version:      string(nextVersion.Version),
+				Platforms:    platforms,
+				Verification: u.updateConfig.Spec.Verification.DeepCopy(),
 				Targets: apv1beta2.PlanCommandTargets{
 					Controllers: apv1beta2.PlanCommandTarget{
 						Discovery: apv1beta2.PlanCommandTargetDiscovery{
@@ -203,25 +204,42 @@ func (u *updater) toPlan(nextVersion *uc.Update) apv1beta2.Plan {
 			},
 		})
 	} else {
+		// Airgap bundles are distributed ahead of any k0s updates, so that the
+		// new images are already in place when the updated workers restart.
+		var airgapCommands, k0sCommands []apv1beta2.PlanCommand
 		for _, cmd := range u.updateConfig.Spec.PlanSpec.Commands {
-			planCmd := apv1beta2.PlanCommand{}
-			if cmd.K0sUpdate != nil {
-				planCmd.K0sUpdate = &apv1beta2.PlanCommandK0sUpdate{
-					Version:     string(nextVersion.Version),
-					ForceUpdate: cmd.K0sUpdate.ForceUpdate,
-					Platforms:   platforms,
-					Targets:     cmd.K0sUpdate.Targets,
-				}
-			}
 			if cmd.AirgapUpdate != nil {
-				planCmd.AirgapUpdate = &apv1beta2.PlanCommandAirgapUpdate{
-					Version:   string(nextVersion.Version),
-					Platforms: airgapPlatforms,
-					Workers:   cmd.AirgapUpdate.Workers,
+				if len(airgapPlatforms) == 0 {
+					u.log.Warnf("No airgap bundles available for version %s, skipping airgap update", nextVersion.Version)
+				} else {
+					airgapCommands = append(airgapCommands, apv1beta2.PlanCommand{
+						AirgapUpdate: &apv1beta2.PlanCommandAirgapUpdate{
+							Version:   string(nextVersion.Version),
+							Platforms: airgapPlatforms,
+							Workers:   cmd.AirgapUpdate.Workers,
+
+							Verification:   u.updateConfig.Spec.Verification.DeepCopy(),
+							ProxyDownloads: cmd.AirgapUpdate.ProxyDownloads,
+						},
+					})
 				}
 			}
-			p.Spec.Commands = append(p.Spec.Commands, planCmd)
+			if cmd.K0sUpdate != nil {
+				k0sCommands = append(k0sCommands, apv1beta2.PlanCommand{
+					K0sUpdate: &apv1beta2.PlanCommandK0sUpdate{
+						Version:     string(nextVersion.Version),
+						ForceUpdate: cmd.K0sUpdate.ForceUpdate,
+						Platforms:   platforms,
+						Targets:     cmd.K0sUpdate.Targets,
+
+						Verification:   u.updateConfig.Spec.Verification.DeepCopy(),
+						ProxyDownloads: cmd.K0sUpdate.ProxyDownloads,
+						Hooks:          cmd.K0sUpdate.Hooks.DeepCopy(),
+					},
+				})
+			}
 		}
+		p.Spec.Commands = append(airgapCommands, k0sCommands...)
 	}
 
 	return p
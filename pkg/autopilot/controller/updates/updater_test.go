@@ -0,0 +1,103 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package updates
+
+import (
+	"testing"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	uc "github.com/k0sproject/k0s/pkg/autopilot/updater"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestToPlan(t *testing.T) {
+	workers := apv1beta2.PlanCommandTarget{
+		Discovery: apv1beta2.PlanCommandTargetDiscovery{
+			Selector: &apv1beta2.PlanCommandTargetDiscoverySelector{Labels: "airgap=true"},
+		},
+	}
+
+	commands := []apv1beta2.AutopilotPlanCommand{
+		{
+			K0sUpdate:    &apv1beta2.AutopilotPlanCommandK0sUpdate{ForceUpdate: true},
+			AirgapUpdate: &apv1beta2.AutopilotPlanCommandAirgapUpdate{Workers: workers},
+		},
+	}
+
+	k0sURLs := map[string]string{"linux-amd64": "https://example.com/k0s-amd64"}
+	airgapURLs := map[string]string{"linux-amd64": "https://example.com/airgap-amd64.tar"}
+
+	newUpdater := func(commands []apv1beta2.AutopilotPlanCommand) *updater {
+		return &updater{
+			log: logrus.NewEntry(logrus.StandardLogger()),
+			updateConfig: apv1beta2.UpdateConfig{
+				Spec: apv1beta2.UpdateSpec{
+					PlanSpec:     apv1beta2.AutopilotPlanSpec{Commands: commands},
+					Verification: apv1beta2.PlanCommandVerification{PublicKeys: []string{"key"}},
+				},
+			},
+		}
+	}
+
+	t.Run("default", func(t *testing.T) {
+		plan := newUpdater(nil).toPlan(&uc.Update{
+			Version:      "v1.27.2+k0s.0",
+			DownloadURLs: uc.DownloadURLs{"k0s": k0sURLs, "airgap": airgapURLs},
+		})
+
+		require.Len(t, plan.Spec.Commands, 1)
+		cmd := plan.Spec.Commands[0]
+		require.NotNil(t, cmd.K0sUpdate)
+		assert.Nil(t, cmd.AirgapUpdate)
+		assert.Equal(t, "v1.27.2+k0s.0", cmd.K0sUpdate.Version)
+		assert.Equal(t, "https://example.com/k0s-amd64", cmd.K0sUpdate.Platforms["linux-amd64"].URL)
+		assert.Equal(t, []string{"key"}, cmd.K0sUpdate.Verification.PublicKeys)
+	})
+
+	t.Run("airgap_first", func(t *testing.T) {
+		plan := newUpdater(commands).toPlan(&uc.Update{
+			Version:      "v1.27.2+k0s.0",
+			DownloadURLs: uc.DownloadURLs{"k0s": k0sURLs, "airgap": airgapURLs},
+		})
+
+		require.Len(t, plan.Spec.Commands, 2)
+
+		airgap := plan.Spec.Commands[0]
+		require.NotNil(t, airgap.AirgapUpdate)
+		assert.Nil(t, airgap.K0sUpdate)
+		assert.Equal(t, "v1.27.2+k0s.0", airgap.AirgapUpdate.Version)
+		assert.Equal(t, "https://example.com/airgap-amd64.tar", airgap.AirgapUpdate.Platforms["linux-amd64"].URL)
+		assert.Equal(t, workers, airgap.AirgapUpdate.Workers)
+		assert.Equal(t, []string{"key"}, airgap.AirgapUpdate.Verification.PublicKeys)
+
+		k0s := plan.Spec.Commands[1]
+		require.NotNil(t, k0s.K0sUpdate)
+		assert.Nil(t, k0s.AirgapUpdate)
+		assert.True(t, k0s.K0sUpdate.ForceUpdate)
+	})
+
+	t.Run("no_airgap_bundles", func(t *testing.T) {
+		plan := newUpdater(commands).toPlan(&uc.Update{
+			Version:      "v1.27.2+k0s.0",
+			DownloadURLs: uc.DownloadURLs{"k0s": k0sURLs},
+		})
+
+		require.Len(t, plan.Spec.Commands, 1)
+		assert.NotNil(t, plan.Spec.Commands[0].K0sUpdate)
+	})
+}
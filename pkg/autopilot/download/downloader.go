@@ -17,8 +17,11 @@ package download
 import (
 	"context"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"hash"
+	"net/http"
+	"os"
 
 	"github.com/cavaliergopher/grab/v3"
 	"github.com/sirupsen/logrus"
@@ -33,6 +36,14 @@ type Config struct {
 	ExpectedHash string
 	Hasher       hash.Hash
 	DownloadDir  string
+
+	// HTTPClient is used to download the file, if set.
+	HTTPClient *http.Client
+
+	// Verification makes the downloaded file subject to mandatory
+	// verification. If nil, the file is only verified against ExpectedHash,
+	// if provided.
+	Verification *Verification
 }
 
 type downloader struct {
@@ -54,6 +65,10 @@ func NewDownloader(config Config, logger *logrus.Entry) Downloader {
 // on a separate goroutine. Cancelling the context will abort this operation
 // once started.
 func (d *downloader) Download(ctx context.Context) error {
+	if v := d.config.Verification; v != nil && len(v.PublicKeys) == 0 && d.config.ExpectedHash == "" {
+		return errors.New("verification required, but neither an expected hash nor trusted public keys have been provided")
+	}
+
 	// Setup the library for downloading HTTP content ..
 	dlreq, err := grab.NewRequest(d.config.DownloadDir, d.config.URL)
 	if err != nil {
@@ -71,13 +86,32 @@ func (d *downloader) Download(ctx context.Context) error {
 	}
 
 	client := grab.NewClient()
+	if d.config.HTTPClient != nil {
+		client.HTTPClient = d.config.HTTPClient
+	}
 	d.httpResponse = client.Do(dlreq)
 
 	select {
 	case <-d.httpResponse.Done:
-		return d.httpResponse.Err()
+		if err := d.httpResponse.Err(); err != nil {
+			return err
+		}
 
 	case <-ctx.Done():
 		return fmt.Errorf("download cancelled")
 	}
+
+	if d.config.Verification != nil {
+		filename := d.httpResponse.Filename
+		if err := d.config.Verification.verify(ctx, d.config.HTTPClient, d.config.URL, d.config.ExpectedHash, filename); err != nil {
+			// Don't leave unverified files lying around.
+			if err := os.Remove(filename); err != nil && !errors.Is(err, os.ErrNotExist) {
+				d.logger.WithError(err).Warnf("Failed to remove unverified download '%s'", filename)
+			}
+
+			return fmt.Errorf("verification failed: %w", err)
+		}
+	}
+
+	return nil
 }
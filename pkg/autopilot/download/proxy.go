@@ -0,0 +1,206 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ProxyPath is the path of the download proxy in the k0s API of controllers.
+const ProxyPath = "/v1beta1/autopilot/download"
+
+// ProxiedURL returns the URL under which the download proxy at proxyURL
+// serves the given URL.
+func ProxiedURL(proxyURL, url string) string {
+	return proxyURL + "?" + neturl.Values{"url": []string{url}}.Encode()
+}
+
+// ProxyResourcesFunc returns all of the URLs that may be downloaded through the
+// proxy, mapped to their expected SHA256 hash, which may be empty.
+type ProxyResourcesFunc func(ctx context.Context) (map[string]string, error)
+
+type proxy struct {
+	logger    *logrus.Entry
+	cacheDir  string
+	resources ProxyResourcesFunc
+
+	mu sync.Mutex
+}
+
+// NewProxyHandler builds an HTTP handler that serves downloads on behalf of
+// nodes that can't reach the download URLs by themselves. Downloads are cached
+// in cacheDir and verified against their expected hash, if provided. Cached
+// downloads whose URLs are no longer provided by resources are removed.
+func NewProxyHandler(logger *logrus.Entry, cacheDir string, resources ProxyResourcesFunc) http.Handler {
+	return &proxy{
+		logger:    logger.WithField("component", "download-proxy"),
+		cacheDir:  cacheDir,
+		resources: resources,
+	}
+}
+
+func (p *proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	url := r.URL.Query().Get("url")
+	if url == "" {
+		http.Error(w, "missing url", http.StatusBadRequest)
+		return
+	}
+
+	resources, err := p.resources(r.Context())
+	if err != nil {
+		p.logger.WithError(err).Error("Failed to determine downloadable resources")
+		http.Error(w, "failed to determine downloadable resources", http.StatusInternalServerError)
+		return
+	}
+
+	expectedHash, ok := resources[url]
+	if !ok {
+		http.Error(w, "not a downloadable resource", http.StatusForbidden)
+		return
+	}
+
+	// Downloads may be large and take a while to be fetched and transferred.
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		p.logger.WithError(err).Warn("Failed to clear write deadline")
+	}
+
+	cached, err := p.fetch(r.Context(), url, expectedHash, resources)
+	if err != nil {
+		p.logger.WithError(err).Errorf("Failed to download '%s'", url)
+		http.Error(w, fmt.Sprintf("failed to download: %v", err), http.StatusBadGateway)
+		return
+	}
+
+	f, err := os.Open(cached)
+	if err != nil {
+		http.Error(w, "failed to open download", http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+
+	stat, err := f.Stat()
+	if err != nil {
+		http.Error(w, "failed to open download", http.StatusInternalServerError)
+		return
+	}
+
+	// Preserve the original file name, which is what downloaders would pick
+	// when downloading the URL directly.
+	if u, err := neturl.Parse(url); err == nil {
+		disposition := mime.FormatMediaType("attachment", map[string]string{"filename": path.Base(u.Path)})
+		w.Header().Set("Content-Disposition", disposition)
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	http.ServeContent(w, r, "", stat.ModTime(), f)
+}
+
+// fetch returns the path of the cached download of url, downloading it first if
+// it isn't cached yet.
+func (p *proxy) fetch(ctx context.Context, url, expectedHash string, resources map[string]string) (string, error) {
+	path := filepath.Join(p.cacheDir, cacheKey(url))
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	// Someone else might have downloaded it in the meantime.
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	if err := os.MkdirAll(p.cacheDir, 0700); err != nil {
+		return "", err
+	}
+
+	p.prune(resources)
+
+	tmpDir, err := os.MkdirTemp(p.cacheDir, ".download-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	p.logger.Infof("Downloading '%s'", url)
+	dl := NewDownloader(Config{
+		URL:          url,
+		ExpectedHash: expectedHash,
+		Hasher:       sha256.New(),
+		DownloadDir:  tmpDir,
+	}, p.logger)
+	if err := dl.Download(ctx); err != nil {
+		return "", err
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		return "", err
+	}
+	if len(entries) != 1 {
+		return "", fmt.Errorf("expected a single downloaded file, got %d", len(entries))
+	}
+
+	if err := os.Rename(filepath.Join(tmpDir, entries[0].Name()), path); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+// prune removes all cached downloads that aren't part of resources anymore.
+func (p *proxy) prune(resources map[string]string) {
+	keep := make(map[string]struct{}, len(resources))
+	for url := range resources {
+		keep[cacheKey(url)] = struct{}{}
+	}
+
+	entries, err := os.ReadDir(p.cacheDir)
+	if err != nil {
+		p.logger.WithError(err).Warn("Failed to list cached downloads")
+		return
+	}
+
+	for _, entry := range entries {
+		if _, ok := keep[entry.Name()]; ok || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		if err := os.RemoveAll(filepath.Join(p.cacheDir, entry.Name())); err != nil {
+			p.logger.WithError(err).Warnf("Failed to remove cached download '%s'", entry.Name())
+		}
+	}
+}
+
+// cacheKey returns the name of the cached download of url.
+func cacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
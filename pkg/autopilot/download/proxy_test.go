@@ -0,0 +1,118 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyHandler(t *testing.T) {
+	content := []byte("k0s binary")
+	digest := sha256.Sum256(content)
+
+	var requests atomic.Int32
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			requests.Add(1)
+		}
+		_, _ = w.Write(content)
+	}))
+	defer upstream.Close()
+
+	k0sURL := upstream.URL + "/k0s"
+	brokenURL := upstream.URL + "/broken/k0s"
+	resources := map[string]string{
+		k0sURL:    hex.EncodeToString(digest[:]),
+		brokenURL: "0000",
+	}
+
+	cacheDir := t.TempDir()
+	proxy := httptest.NewServer(NewProxyHandler(logrus.NewEntry(logrus.StandardLogger()), cacheDir, func(context.Context) (map[string]string, error) {
+		return resources, nil
+	}))
+	defer proxy.Close()
+
+	get := func(t *testing.T, url string) (int, []byte) {
+		resp, err := http.Get(ProxiedURL(proxy.URL, url))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, body
+	}
+
+	t.Run("download", func(t *testing.T) {
+		for i := 0; i < 2; i++ {
+			status, body := get(t, k0sURL)
+			assert.Equal(t, http.StatusOK, status)
+			assert.Equal(t, content, body)
+		}
+
+		// The second request is served from the cache.
+		assert.Equal(t, int32(1), requests.Load())
+		assert.FileExists(t, filepath.Join(cacheDir, cacheKey(k0sURL)))
+	})
+
+	t.Run("file_name", func(t *testing.T) {
+		dir := t.TempDir()
+		dl := NewDownloader(Config{
+			URL:          ProxiedURL(proxy.URL, k0sURL),
+			ExpectedHash: hex.EncodeToString(digest[:]),
+			Hasher:       sha256.New(),
+			DownloadDir:  dir,
+		}, logrus.NewEntry(logrus.StandardLogger()))
+
+		require.NoError(t, dl.Download(context.TODO()))
+		assert.FileExists(t, filepath.Join(dir, "k0s"))
+	})
+
+	t.Run("forbidden", func(t *testing.T) {
+		status, _ := get(t, upstream.URL+"/other")
+		assert.Equal(t, http.StatusForbidden, status)
+	})
+
+	t.Run("hash_mismatch", func(t *testing.T) {
+		status, _ := get(t, brokenURL)
+		assert.Equal(t, http.StatusBadGateway, status)
+		assert.NoFileExists(t, filepath.Join(cacheDir, cacheKey(brokenURL)))
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		newURL := upstream.URL + "/new/k0s"
+		resources = map[string]string{newURL: ""}
+
+		status, body := get(t, newURL)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, content, body)
+
+		entries, err := os.ReadDir(cacheDir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, cacheKey(newURL), entries[0].Name())
+	})
+}
@@ -0,0 +1,186 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+)
+
+// SignatureSuffix is appended to the URL of a downloaded file in order to
+// obtain the URL of its published signature.
+const SignatureSuffix = ".sig"
+
+// maxSignatureSize limits the amount of data read when fetching signatures.
+const maxSignatureSize = 64 * 1024
+
+// Verification describes how a downloaded file needs to be verified. Files are
+// required to be verified against either their expected hash or a signature
+// made by one of the trusted public keys.
+type Verification struct {
+	// PublicKeys is a list of PEM encoded PKIX public keys (ECDSA or RSA).
+	// If not empty, the file needs to have a valid signature from any of them.
+	PublicKeys []string
+
+	// SignatureURL is the URL of the signature of the file. Defaults to the
+	// URL of the file, suffixed by SignatureSuffix.
+	SignatureURL string
+}
+
+// ParsePublicKeys parses a list of PEM encoded PKIX public keys.
+func ParsePublicKeys(keys []string) ([]crypto.PublicKey, error) {
+	var publicKeys []crypto.PublicKey
+	for i, key := range keys {
+		block, _ := pem.Decode([]byte(key))
+		if block == nil || block.Type != "PUBLIC KEY" {
+			return nil, fmt.Errorf("public key %d: no PEM encoded public key found", i)
+		}
+
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("public key %d: %w", i, err)
+		}
+
+		switch publicKey.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey:
+			publicKeys = append(publicKeys, publicKey)
+		default:
+			return nil, fmt.Errorf("public key %d: unsupported key type %T", i, publicKey)
+		}
+	}
+
+	return publicKeys, nil
+}
+
+// VerifySignature verifies that the signature is a valid signature of the
+// SHA256 digest of a file made by any of the provided public keys. ECDSA
+// signatures are expected to be ASN.1 encoded, RSA signatures to use PKCS #1
+// v1.5. The signature may be base64 encoded.
+func VerifySignature(digest, signature []byte, publicKeys []crypto.PublicKey) error {
+	if decoded, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature))); err == nil {
+		signature = decoded
+	}
+
+	for _, publicKey := range publicKeys {
+		switch publicKey := publicKey.(type) {
+		case *ecdsa.PublicKey:
+			if ecdsa.VerifyASN1(publicKey, digest, signature) {
+				return nil
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest, signature) == nil {
+				return nil
+			}
+		}
+	}
+
+	return errors.New("signature doesn't match any of the trusted public keys")
+}
+
+// verify ensures that the file at the given path satisfies the verification.
+func (v *Verification) verify(ctx context.Context, client *http.Client, url, expectedHash, path string) error {
+	if len(v.PublicKeys) == 0 {
+		if expectedHash == "" {
+			return errors.New("neither an expected hash nor trusted public keys have been provided")
+		}
+
+		// The hash has already been verified by the download itself.
+		return nil
+	}
+
+	publicKeys, err := ParsePublicKeys(v.PublicKeys)
+	if err != nil {
+		return err
+	}
+
+	signatureURL := v.SignatureURL
+	if signatureURL == "" {
+		signatureURL = url + SignatureSuffix
+	}
+
+	signature, err := fetchSignature(ctx, client, signatureURL)
+	if err != nil {
+		return err
+	}
+
+	digest, err := sha256File(path)
+	if err != nil {
+		return err
+	}
+
+	if err := VerifySignature(digest, signature, publicKeys); err != nil {
+		return fmt.Errorf("failed to verify signature '%s': %w", signatureURL, err)
+	}
+
+	return nil
+}
+
+// fetchSignature downloads the signature at the given URL, using the default
+// HTTP client if client is nil.
+func fetchSignature(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature URL: %w", err)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signature '%s': %w", url, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download signature '%s': %s", url, resp.Status)
+	}
+
+	signature, err := io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signature '%s': %w", url, err)
+	}
+
+	return signature, nil
+}
+
+// sha256File calculates the SHA256 digest of the file at the given path.
+func sha256File(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, err
+	}
+
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,119 @@
+// Copyright 2023 k0s authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package download
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodePublicKey(t *testing.T, key crypto.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+}
+
+func TestDownloadVerification(t *testing.T) {
+	content := []byte("k0s binary")
+	digest := sha256.Sum256(content)
+
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ecdsaSignature, err := ecdsa.SignASN1(rand.Reader, ecdsaKey, digest[:])
+	require.NoError(t, err)
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	rsaSignature, err := rsa.SignPKCS1v15(rand.Reader, rsaKey, crypto.SHA256, digest[:])
+	require.NoError(t, err)
+
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	signatures := map[string][]byte{
+		"/ecdsa/k0s.sig":  []byte(base64.StdEncoding.EncodeToString(ecdsaSignature) + "\n"),
+		"/rsa/k0s.sig":    rsaSignature,
+		"/broken/k0s.sig": []byte("broken"),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Base(r.URL.Path) == "k0s" {
+			_, _ = w.Write(content)
+			return
+		}
+		if signature, ok := signatures[r.URL.Path]; ok {
+			_, _ = w.Write(signature)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	for _, test := range []struct {
+		name         string
+		path         string
+		expectedHash string
+		verification *Verification
+		expectedErr  string
+	}{
+		{"no_verification", "/ecdsa/k0s", "", nil, ""},
+		{"hash", "/ecdsa/k0s", hex.EncodeToString(digest[:]), &Verification{}, ""},
+		{"nothing_to_verify", "/ecdsa/k0s", "", &Verification{}, "verification required, but neither an expected hash nor trusted public keys have been provided"},
+		{"ecdsa", "/ecdsa/k0s", "", &Verification{PublicKeys: []string{encodePublicKey(t, &ecdsaKey.PublicKey)}}, ""},
+		{"rsa", "/rsa/k0s", "", &Verification{PublicKeys: []string{encodePublicKey(t, &otherKey.PublicKey), encodePublicKey(t, &rsaKey.PublicKey)}}, ""},
+		{"untrusted_key", "/ecdsa/k0s", "", &Verification{PublicKeys: []string{encodePublicKey(t, &otherKey.PublicKey)}}, "signature doesn't match any of the trusted public keys"},
+		{"broken_signature", "/broken/k0s", "", &Verification{PublicKeys: []string{encodePublicKey(t, &ecdsaKey.PublicKey)}}, "signature doesn't match any of the trusted public keys"},
+		{"missing_signature", "/missing/k0s", "", &Verification{PublicKeys: []string{encodePublicKey(t, &ecdsaKey.PublicKey)}}, "404 Not Found"},
+		{"invalid_key", "/ecdsa/k0s", "", &Verification{PublicKeys: []string{"invalid"}}, "public key 0: no PEM encoded public key found"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			dir := t.TempDir()
+			dl := NewDownloader(Config{
+				URL:          server.URL + test.path,
+				ExpectedHash: test.expectedHash,
+				Hasher:       sha256.New(),
+				DownloadDir:  dir,
+				Verification: test.verification,
+			}, logrus.NewEntry(logrus.StandardLogger()))
+
+			err := dl.Download(context.TODO())
+			if test.expectedErr == "" {
+				require.NoError(t, err)
+				assert.FileExists(t, filepath.Join(dir, "k0s"))
+			} else {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), test.expectedErr)
+				assert.NoFileExists(t, filepath.Join(dir, "k0s"))
+			}
+		})
+	}
+}
@@ -146,6 +146,14 @@ type CommandK0sUpdate struct {
 	Version     string `json:"version" validate:"required"`
 	Sha256      string `json:"sha256,omitempty"`
 	ForceUpdate bool   `json:"forceupdate,omitempty"`
+
+	Verification *CommandVerification `json:"verification,omitempty"`
+
+	// ProxyURL is the URL of the download proxy of the controllers. If set,
+	// the update is downloaded through it.
+	ProxyURL string `json:"proxyurl,omitempty"`
+
+	Hooks *CommandHooks `json:"hooks,omitempty"`
 }
 
 // CommandAirgapUpdate describes what an update to `airgap` is.
@@ -153,6 +161,33 @@ type CommandAirgapUpdate struct {
 	URL     string `json:"url" validate:"required,url"`
 	Version string `json:"version" validate:"required"`
 	Sha256  string `json:"sha256,omitempty"`
+
+	Verification *CommandVerification `json:"verification,omitempty"`
+
+	// ProxyURL is the URL of the download proxy of the controllers. If set,
+	// the update is downloaded through it.
+	ProxyURL string `json:"proxyurl,omitempty"`
+}
+
+// CommandVerification requires the downloaded update to be verified, either
+// against its SHA256 hash, or against a signature made by any of the trusted
+// public keys.
+type CommandVerification struct {
+	PublicKeys []string `json:"publicKeys,omitempty"`
+}
+
+// CommandHooks are commands that are run on the node before and after the
+// update is applied.
+type CommandHooks struct {
+	PreUpdate  []CommandHook `json:"preupdate,omitempty"`
+	PostUpdate []CommandHook `json:"postupdate,omitempty"`
+}
+
+// CommandHook is a command that is run on the node, along with the time in which
+// it needs to finish.
+type CommandHook struct {
+	Command []string      `json:"command"`
+	Timeout time.Duration `json:"timeout,omitempty"`
 }
 
 // validateCommand ensures that a `Command` contains at-most-one of
@@ -0,0 +1,89 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package updater
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/Masterminds/semver/v3"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultUpdateServer is the URL of the public k0s update server.
+const DefaultUpdateServer = "https://updates.k0sproject.io/"
+
+type indexClient struct {
+	path string
+}
+
+// NewIndexClient creates a client that looks up updates in a local index file
+// instead of asking an update server, e.g. in airgapped environments. The
+// index is a JSON list of channels along with their versions:
+//
+//	[{"name": "stable", "versions": ["v1.27.1+k0s.0", "v1.27.2+k0s.0"]}]
+func NewIndexClient(path string) Client {
+	return &indexClient{path}
+}
+
+func (c *indexClient) GetUpdate(channel, _, _, _ string) (*Update, error) {
+	if channel == "" {
+		channel = defaultChannel
+	}
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return nil, err
+	}
+	var channels []Channel
+	if err := yaml.Unmarshal(data, &channels); err != nil {
+		return nil, fmt.Errorf("failed to parse update index %s: %w", c.path, err)
+	}
+
+	for _, ch := range channels {
+		if ch.Name != channel {
+			continue
+		}
+		var latest Version
+		for _, version := range ch.Versions {
+			if _, err := semver.NewVersion(string(version)); err != nil {
+				return nil, fmt.Errorf("invalid version %q in channel %q: %w", version, channel, err)
+			}
+			if latest == "" || version.Compare(string(latest)) > 0 {
+				latest = version
+			}
+		}
+		if latest == "" {
+			return nil, fmt.Errorf("channel %q doesn't contain any versions", channel)
+		}
+		return &Update{Version: latest}, nil
+	}
+
+	return nil, fmt.Errorf("channel %q not found in update index %s", channel, c.path)
+}
+
+// IsNewerThan checks if the version is newer than the given one. Both versions
+// need to be valid semantic versions.
+func (v Version) IsNewerThan(other string) (bool, error) {
+	if _, err := semver.NewVersion(string(v)); err != nil {
+		return false, fmt.Errorf("invalid version %q: %w", v, err)
+	}
+	if _, err := semver.NewVersion(other); err != nil {
+		return false, fmt.Errorf("invalid version %q: %w", other, err)
+	}
+	return v.Compare(other) > 0, nil
+}
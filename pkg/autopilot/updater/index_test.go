@@ -0,0 +1,65 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexClient(t *testing.T) {
+	index := filepath.Join(t.TempDir(), "index.json")
+	require.NoError(t, os.WriteFile(index, []byte(`[
+		{"name": "stable", "versions": ["v1.27.1+k0s.0", "v1.27.2+k0s.1", "v1.27.2+k0s.0"]},
+		{"name": "unstable", "versions": ["v1.28.0-rc.1+k0s.0"]},
+		{"name": "empty", "versions": []},
+		{"name": "broken", "versions": ["latest"]}
+	]`), 0644))
+	client := NewIndexClient(index)
+
+	update, err := client.GetUpdate("", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, Version("v1.27.2+k0s.1"), update.Version)
+
+	update, err = client.GetUpdate("unstable", "", "", "")
+	require.NoError(t, err)
+	assert.Equal(t, Version("v1.28.0-rc.1+k0s.0"), update.Version)
+
+	_, err = client.GetUpdate("empty", "", "", "")
+	assert.ErrorContains(t, err, `channel "empty" doesn't contain any versions`)
+	_, err = client.GetUpdate("broken", "", "", "")
+	assert.ErrorContains(t, err, `invalid version "latest" in channel "broken"`)
+	_, err = client.GetUpdate("nightly", "", "", "")
+	assert.ErrorContains(t, err, `channel "nightly" not found in update index`)
+}
+
+func TestVersion_IsNewerThan(t *testing.T) {
+	newer, err := Version("v1.27.2+k0s.0").IsNewerThan("v1.27.1+k0s.0")
+	assert.NoError(t, err)
+	assert.True(t, newer)
+
+	newer, err = Version("v1.27.2+k0s.0").IsNewerThan("v1.27.2+k0s.0")
+	assert.NoError(t, err)
+	assert.False(t, newer)
+
+	_, err = Version("v1.27.2+k0s.0").IsNewerThan("")
+	assert.ErrorContains(t, err, `invalid version ""`)
+}
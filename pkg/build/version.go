@@ -25,3 +25,7 @@ var KubernetesVersion string
 var KineVersion string
 var EtcdVersion string
 var KonnectivityVersion string
+
+// BuildDate gets overridden at build time using -X with the date of the last
+// commit, in RFC 3339 format.
+var BuildDate string
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/sirupsen/logrus"
+
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+)
+
+// ImportCA copies the externally issued CA referenced by the given spec into
+// the certificate directory, where it's picked up by EnsureCA. The CA is
+// validated before it's imported. A CA that's being rotated out is skipped.
+func (m *Manager) ImportCA(name string, spec *v1beta1.CASpec) error {
+	cert, err := os.ReadFile(spec.CertFile)
+	if err != nil {
+		return fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	key, err := os.ReadFile(spec.KeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read CA key: %w", err)
+	}
+	var chain []byte
+	if spec.ChainFile != "" {
+		if chain, err = os.ReadFile(spec.ChainFile); err != nil {
+			return fmt.Errorf("failed to read CA chain: %w", err)
+		}
+	}
+
+	if err := ValidateCA(cert, key, chain, time.Now()); err != nil {
+		return fmt.Errorf("invalid CA %s: %w", spec.CertFile, err)
+	}
+
+	certFile, keyFile, chainFile := m.caFiles(name)
+	_, _, previousCertFile := m.caRotationFiles(name)
+	if previous, err := os.ReadFile(previousCertFile); err == nil && bytes.Equal(previous, cert) {
+		logrus.Warnf("Not importing %s, it's being rotated out", spec.CertFile)
+		return nil
+	}
+	if existing, err := os.ReadFile(certFile); err == nil && !bytes.Equal(existing, cert) {
+		logrus.Warnf("Replacing CA certificate %s with %s", certFile, spec.CertFile)
+	}
+
+	if err := file.WriteContentAtomically(keyFile, key, constant.CertSecureMode); err != nil {
+		return err
+	}
+	if err := file.WriteContentAtomically(certFile, cert, constant.CertMode); err != nil {
+		return err
+	}
+	if chain == nil {
+		if err := os.Remove(chainFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+	return file.WriteContentAtomically(chainFile, chain, constant.CertMode)
+}
+
+// caFiles returns the paths of the certificate, the key and the chain of the
+// CA with the given name.
+func (m *Manager) caFiles(name string) (certFile, keyFile, chainFile string) {
+	base := filepath.Join(m.K0sVars.CertRootDir, name)
+	return base + ".crt", base + ".key", base + "-chain.crt"
+}
+
+// validateCAFiles validates the CA with the given name in the certificate
+// directory. The chain is optional.
+func (m *Manager) validateCAFiles(name string) error {
+	certFile, keyFile, chainFile := m.caFiles(name)
+	cert, err := os.ReadFile(certFile)
+	if err != nil {
+		return err
+	}
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return err
+	}
+	chain, err := os.ReadFile(chainFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if err := ValidateCA(cert, key, chain, time.Now()); err != nil {
+		return fmt.Errorf("invalid CA %s: %w", certFile, err)
+	}
+	return nil
+}
+
+// ValidateCA checks if the given PEM encoded certificate and key are usable as
+// a certificate authority at the given time. If a chain of intermediate and
+// root certificates is given, the certificate has to chain up to one of them.
+func ValidateCA(certPEM, keyPEM, chainPEM []byte, now time.Time) error {
+	certs, err := helpers.ParseCertificatesPEM(certPEM)
+	if err != nil {
+		return fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	if len(certs) != 1 {
+		return fmt.Errorf("expected a single certificate, got %d (put intermediate certificates into the chain)", len(certs))
+	}
+	cert := certs[0]
+
+	if !cert.BasicConstraintsValid || !cert.IsCA {
+		return errors.New("not a CA certificate")
+	}
+	// A certificate without key usage extension may be used for any purpose.
+	if cert.KeyUsage != 0 && cert.KeyUsage&x509.KeyUsageCertSign == 0 {
+		return errors.New("key usage doesn't permit signing certificates")
+	}
+	if now.Before(cert.NotBefore) {
+		return fmt.Errorf("not valid before %s", cert.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(cert.NotAfter) {
+		return fmt.Errorf("expired at %s", cert.NotAfter.Format(time.RFC3339))
+	}
+
+	key, err := helpers.ParsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return fmt.Errorf("failed to parse key: %w", err)
+	}
+	if publicKey, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool }); !ok || !publicKey.Equal(cert.PublicKey) {
+		return errors.New("key doesn't match the certificate")
+	}
+
+	if len(bytes.TrimSpace(chainPEM)) == 0 {
+		return nil
+	}
+	chain, err := helpers.ParseCertificatesPEM(chainPEM)
+	if err != nil {
+		return fmt.Errorf("failed to parse chain: %w", err)
+	}
+	roots := x509.NewCertPool()
+	for _, c := range chain {
+		roots.AddCert(c)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: now,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to verify certificate against chain: %w", err)
+	}
+	return nil
+}
+
+// isIssuedBy checks if the given certificate file has been signed by the given
+// CA certificate file.
+func isIssuedBy(certFile, caFile string) bool {
+	cert, err := readCertificate(certFile)
+	if err != nil {
+		return false
+	}
+	ca, err := readCertificate(caFile)
+	if err != nil {
+		return false
+	}
+	return cert.CheckSignatureFrom(ca) == nil
+}
+
+func readCertificate(path string) (*x509.Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return helpers.ParseCertificatePEM(data)
+}
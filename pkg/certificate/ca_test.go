@@ -0,0 +1,167 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+func newTestCA(t *testing.T, cn string, parent *testCA, modify func(*x509.Certificate)) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(time.Now().UnixNano()),
+		Subject:               pkix.Name{CommonName: cn},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	if modify != nil {
+		modify(template)
+	}
+
+	parentCert, parentKey := template, key
+	if parent != nil {
+		parentCert, parentKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parentCert, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCA{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+func TestValidateCA(t *testing.T) {
+	root := newTestCA(t, "root", nil, nil)
+	intermediate := newTestCA(t, "intermediate", root, nil)
+	other := newTestCA(t, "other", nil, nil)
+	now := time.Now()
+
+	t.Run("self_signed", func(t *testing.T) {
+		assert.NoError(t, ValidateCA(root.certPEM, root.keyPEM, nil, now))
+	})
+
+	t.Run("intermediate_with_chain", func(t *testing.T) {
+		assert.NoError(t, ValidateCA(intermediate.certPEM, intermediate.keyPEM, root.certPEM, now))
+	})
+
+	t.Run("wrong_chain", func(t *testing.T) {
+		err := ValidateCA(intermediate.certPEM, intermediate.keyPEM, other.certPEM, now)
+		assert.ErrorContains(t, err, "failed to verify certificate against chain")
+	})
+
+	t.Run("mismatching_key", func(t *testing.T) {
+		assert.EqualError(t, ValidateCA(root.certPEM, other.keyPEM, nil, now), "key doesn't match the certificate")
+	})
+
+	t.Run("bundle", func(t *testing.T) {
+		bundle := append(append([]byte{}, intermediate.certPEM...), root.certPEM...)
+		err := ValidateCA(bundle, intermediate.keyPEM, nil, now)
+		assert.ErrorContains(t, err, "expected a single certificate, got 2")
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		err := ValidateCA(root.certPEM, root.keyPEM, nil, now.Add(2*time.Hour))
+		assert.ErrorContains(t, err, "expired at")
+	})
+
+	t.Run("no_ca", func(t *testing.T) {
+		leaf := newTestCA(t, "leaf", root, func(c *x509.Certificate) { c.IsCA = false })
+		assert.EqualError(t, ValidateCA(leaf.certPEM, leaf.keyPEM, nil, now), "not a CA certificate")
+	})
+
+	t.Run("no_cert_sign_usage", func(t *testing.T) {
+		ca := newTestCA(t, "ca", nil, func(c *x509.Certificate) { c.KeyUsage = x509.KeyUsageDigitalSignature })
+		assert.EqualError(t, ValidateCA(ca.certPEM, ca.keyPEM, nil, now), "key usage doesn't permit signing certificates")
+	})
+}
+
+func TestImportCA(t *testing.T) {
+	root := newTestCA(t, "root", nil, nil)
+	intermediate := newTestCA(t, "intermediate", root, nil)
+
+	srcDir := t.TempDir()
+	spec := &v1beta1.CASpec{
+		CertFile:  filepath.Join(srcDir, "k0s.crt"),
+		KeyFile:   filepath.Join(srcDir, "k0s.key"),
+		ChainFile: filepath.Join(srcDir, "chain.crt"),
+	}
+	require.NoError(t, os.WriteFile(spec.CertFile, intermediate.certPEM, 0644))
+	require.NoError(t, os.WriteFile(spec.KeyFile, intermediate.keyPEM, 0600))
+	require.NoError(t, os.WriteFile(spec.ChainFile, root.certPEM, 0644))
+
+	m := &Manager{K0sVars: constant.CfgVars{CertRootDir: t.TempDir()}}
+	require.NoError(t, m.ImportCA("ca", spec))
+	require.NoError(t, m.EnsureCA("ca", "kubernetes-ca"))
+
+	cert, err := os.ReadFile(filepath.Join(m.K0sVars.CertRootDir, "ca.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, intermediate.certPEM, cert, "the imported CA should be used")
+
+	// Certificates issued by the imported CA are managed by k0s.
+	adminReq := Request{
+		Name:   "admin",
+		CN:     "kubernetes-admin",
+		O:      "system:masters",
+		CACert: filepath.Join(m.K0sVars.CertRootDir, "ca.crt"),
+		CAKey:  filepath.Join(m.K0sVars.CertRootDir, "ca.key"),
+	}
+	_, err = m.EnsureCertificate(adminReq, "")
+	require.NoError(t, err)
+	certFile := filepath.Join(m.K0sVars.CertRootDir, "admin.crt")
+	assert.True(t, isIssuedBy(certFile, adminReq.CACert))
+	assert.True(t, m.regenerateCert(adminReq, filepath.Join(m.K0sVars.CertRootDir, "admin.key"), certFile))
+
+	// CAs that don't chain up to the given chain are rejected.
+	other := newTestCA(t, "other", nil, nil)
+	require.NoError(t, os.WriteFile(spec.ChainFile, other.certPEM, 0644))
+	assert.ErrorContains(t, m.ImportCA("ca", spec), "failed to verify certificate against chain")
+}
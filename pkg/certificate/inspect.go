@@ -0,0 +1,157 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Info describes a certificate file.
+type Info struct {
+	Path          string    `json:"path"`
+	Subject       string    `json:"subject"`
+	Issuer        string    `json:"issuer"`
+	SANs          []string  `json:"sans,omitempty"`
+	IsCA          bool      `json:"isCA"`
+	NotAfter      time.Time `json:"notAfter"`
+	DaysRemaining int       `json:"daysRemaining"`
+}
+
+// ExpiresWithin returns true if the certificate expires within the given
+// duration after now.
+func (i *Info) ExpiresWithin(d time.Duration, now time.Time) bool {
+	return i.NotAfter.Before(now.Add(d))
+}
+
+// ListCertificates inspects the certificates in the given directories and
+// their subdirectories. Only the first certificate of each file is inspected.
+// Bundles, which contain certificates that are stored in separate files as
+// well, and files that are the target of a symlink next to them, e.g. the ones
+// of kubelet's certificate rotation, are skipped. Non-existent directories are
+// ignored.
+func ListCertificates(now time.Time, dirs ...string) ([]Info, error) {
+	var infos []Info
+	for _, dir := range dirs {
+		paths, err := certificateFiles(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			info, err := inspectCertificateFile(path, now)
+			if err != nil {
+				return nil, err
+			}
+			if info != nil {
+				infos = append(infos, *info)
+			}
+		}
+	}
+	return infos, nil
+}
+
+func certificateFiles(dir string) ([]string, error) {
+	var paths []string
+	symlinks, linkTargets := make(map[string]bool), make(map[string]bool)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if !strings.HasSuffix(name, ".crt") && !strings.HasSuffix(name, ".pem") || strings.HasSuffix(name, "-bundle.crt") {
+			return nil
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			target, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return nil // dangling symlink
+			}
+			symlinks[path], linkTargets[target] = true, true
+		}
+		paths = append(paths, path)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := paths[:0]
+	for _, path := range paths {
+		if !symlinks[path] {
+			if realPath, err := filepath.EvalSymlinks(path); err == nil && linkTargets[realPath] {
+				continue
+			}
+		}
+		filtered = append(filtered, path)
+	}
+	sort.Strings(filtered)
+	return filtered, nil
+}
+
+// inspectCertificateFile returns nil if the file contains no certificate, e.g.
+// if it's a key.
+func inspectCertificateFile(path string, now time.Time) (*Info, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return nil, nil
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, &fs.PathError{Op: "parse", Path: path, Err: err}
+		}
+
+		info := &Info{
+			Path:          path,
+			Subject:       cert.Subject.String(),
+			Issuer:        cert.Issuer.String(),
+			IsCA:          cert.IsCA,
+			NotAfter:      cert.NotAfter,
+			DaysRemaining: int(math.Floor(cert.NotAfter.Sub(now).Hours() / 24)),
+		}
+		info.SANs = append(info.SANs, cert.DNSNames...)
+		for _, ip := range cert.IPAddresses {
+			info.SANs = append(info.SANs, ip.String())
+		}
+		return info, nil
+	}
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/x509"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListCertificates(t *testing.T) {
+	ca := newTestCA(t, "ca", nil, nil)
+	leaf := newTestCA(t, "leaf", ca, func(c *x509.Certificate) {
+		c.IsCA = false
+		c.DNSNames = []string{"localhost"}
+		c.IPAddresses = []net.IP{net.IPv4(127, 0, 0, 1)}
+		c.NotAfter = time.Now().Add(50 * time.Hour)
+	})
+
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "etcd"), 0755))
+	for name, data := range map[string][]byte{
+		"ca.crt":                 ca.certPEM,
+		"ca.key":                 ca.keyPEM,
+		"ca-bundle.crt":          ca.certPEM,
+		"etcd/server.crt":        leaf.certPEM,
+		"kubelet-client-old.pem": append(append([]byte{}, leaf.certPEM...), leaf.keyPEM...),
+	} {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+	}
+	require.NoError(t, os.Symlink(filepath.Join(dir, "kubelet-client-old.pem"), filepath.Join(dir, "kubelet-client-current.pem")))
+
+	now := time.Now()
+	certs, err := ListCertificates(now, dir, filepath.Join(dir, "non-existent"))
+	require.NoError(t, err)
+
+	var paths []string
+	for _, cert := range certs {
+		paths = append(paths, cert.Path)
+	}
+	assert.Equal(t, []string{
+		filepath.Join(dir, "ca.crt"),
+		filepath.Join(dir, "etcd", "server.crt"),
+		filepath.Join(dir, "kubelet-client-current.pem"),
+	}, paths)
+
+	if assert.Len(t, certs, 3) {
+		assert.True(t, certs[0].IsCA)
+		assert.Equal(t, "CN=ca", certs[0].Subject)
+
+		server := certs[1]
+		assert.False(t, server.IsCA)
+		assert.Equal(t, "CN=leaf", server.Subject)
+		assert.Equal(t, "CN=ca", server.Issuer)
+		assert.Equal(t, []string{"localhost", "127.0.0.1"}, server.SANs)
+		assert.Equal(t, 2, server.DaysRemaining)
+		assert.True(t, server.ExpiresWithin(72*time.Hour, now))
+		assert.False(t, server.ExpiresWithin(48*time.Hour, now))
+	}
+}
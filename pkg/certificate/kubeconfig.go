@@ -0,0 +1,113 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// WriteKubeconfig writes a kubeconfig that authenticates with the given client
+// certificate to dest, owned by the given user.
+func WriteKubeconfig(dest, url, caCert, clientCert, clientKey, owner string) error {
+	// We always overwrite the kubeconfigs as the certs might be regenerated at startup
+	const (
+		clusterName = "local"
+		contextName = "Default"
+		userName    = "user"
+	)
+
+	kubeconfig, err := clientcmd.Write(clientcmdapi.Config{
+		Clusters: map[string]*clientcmdapi.Cluster{clusterName: {
+			Server:                   url,
+			CertificateAuthorityData: []byte(caCert),
+		}},
+		Contexts: map[string]*clientcmdapi.Context{contextName: {
+			Cluster:  clusterName,
+			AuthInfo: userName,
+		}},
+		CurrentContext: contextName,
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{userName: {
+			ClientCertificateData: []byte(clientCert),
+			ClientKeyData:         []byte(clientKey),
+		}},
+	})
+	if err != nil {
+		return err
+	}
+
+	err = file.WriteContentAtomically(dest, kubeconfig, constant.CertSecureMode)
+	if err != nil {
+		return err
+	}
+
+	return file.Chown(dest, owner, constant.CertSecureMode)
+}
+
+// WriteAdminKubeconfig issues the admin client certificate, valid for the
+// manager's admin kubeconfig validity, and writes it into the admin
+// kubeconfig, along with the current CA bundle. The kubeconfig points to the
+// API server on localhost at the given port.
+func (m *Manager) WriteAdminKubeconfig(apiPort int) error {
+	// The admin kubeconfig trusts the whole bundle, so that it keeps working
+	// while the CA is rotated.
+	caCert, err := ReadCABundle(m.K0sVars.CertRootDir)
+	if err != nil {
+		return fmt.Errorf("failed to read CA bundle: %w", err)
+	}
+
+	adminReq := Request{
+		Name:     "admin",
+		CN:       "kubernetes-admin",
+		O:        "system:masters",
+		CACert:   filepath.Join(m.K0sVars.CertRootDir, "ca.crt"),
+		CAKey:    filepath.Join(m.K0sVars.CertRootDir, "ca.key"),
+		Validity: m.adminKubeconfigValidity(),
+	}
+	adminCert, err := m.EnsureCertificate(adminReq, "root")
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("https://localhost:%d", apiPort)
+	return WriteKubeconfig(m.K0sVars.AdminKubeConfigPath, url, string(caCert), adminCert.Cert, adminCert.Key, "root")
+}
+
+// AdminKubeconfigNeedsRenewal reports whether the admin client certificate
+// is missing, unreadable, or has less than a third of the manager's admin
+// kubeconfig validity left at the given point in time.
+func (m *Manager) AdminKubeconfigNeedsRenewal(now time.Time) bool {
+	info, err := inspectCertificateFile(filepath.Join(m.K0sVars.CertRootDir, "admin.crt"), now)
+	if err != nil || info == nil {
+		return true
+	}
+	return info.ExpiresWithin(m.adminKubeconfigValidity()/3, now)
+}
+
+func (m *Manager) adminKubeconfigValidity() time.Duration {
+	if m.AdminKubeconfigValidity != 0 {
+		return m.AdminKubeconfigValidity
+	}
+	return m.Validity
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+func TestManager_WriteAdminKubeconfig(t *testing.T) {
+	dir := t.TempDir()
+	k0sVars := constant.CfgVars{
+		CertRootDir:         dir,
+		AdminKubeConfigPath: filepath.Join(dir, "admin.conf"),
+	}
+	m := NewManager(k0sVars, &v1beta1.CertificatesSpec{
+		AdminKubeconfigValidity: &metav1.Duration{Duration: time.Hour},
+	})
+	require.NoError(t, m.EnsureCA("ca", "kubernetes-ca"))
+
+	now := time.Now()
+	assert.True(t, m.AdminKubeconfigNeedsRenewal(now), "missing certificate")
+
+	require.NoError(t, m.WriteAdminKubeconfig(6443))
+
+	kubeconfig, err := clientcmd.LoadFromFile(k0sVars.AdminKubeConfigPath)
+	require.NoError(t, err)
+	cluster := kubeconfig.Clusters[kubeconfig.Contexts[kubeconfig.CurrentContext].Cluster]
+	assert.Equal(t, "https://localhost:6443", cluster.Server)
+	authInfo := kubeconfig.AuthInfos[kubeconfig.Contexts[kubeconfig.CurrentContext].AuthInfo]
+	leaf, err := helpers.ParseCertificatePEM(authInfo.ClientCertificateData)
+	require.NoError(t, err)
+	assert.Equal(t, "kubernetes-admin", leaf.Subject.CommonName)
+	assert.Equal(t, []string{"system:masters"}, leaf.Subject.Organization)
+	assert.InDelta(t, time.Hour, leaf.NotAfter.Sub(leaf.NotBefore), float64(time.Minute))
+
+	assert.False(t, m.AdminKubeconfigNeedsRenewal(now))
+	assert.True(t, m.AdminKubeconfigNeedsRenewal(now.Add(45*time.Minute)), "less than a third of the validity left")
+}
@@ -26,12 +26,14 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/cloudflare/cfssl/certinfo"
 	"github.com/cloudflare/cfssl/cli"
 	"github.com/cloudflare/cfssl/cli/genkey"
 	"github.com/cloudflare/cfssl/cli/sign"
 	"github.com/cloudflare/cfssl/csr"
+	"github.com/cloudflare/cfssl/helpers"
 	"github.com/cloudflare/cfssl/initca"
 	"github.com/cloudflare/cfssl/signer"
 	"github.com/sirupsen/logrus"
@@ -39,6 +41,7 @@ import (
 	"github.com/k0sproject/k0s/internal/pkg/file"
 	"github.com/k0sproject/k0s/internal/pkg/stringslice"
 	"github.com/k0sproject/k0s/internal/pkg/users"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/constant"
 )
 
@@ -50,6 +53,14 @@ type Request struct {
 	CAKey     string
 	CACert    string
 	Hostnames []string
+	// Groups are added as additional organizations to the certificate's
+	// subject, which Kubernetes interprets as group memberships.
+	Groups []string
+	// Validity is the lifetime of the certificate. Defaults to the manager's validity.
+	Validity time.Duration
+	// Provided certificates are issued via the manager's provider, if it has
+	// one, instead of the request's CA.
+	Provided bool
 }
 
 // Certificate is a helper struct to be able to return the created key and cert data
@@ -61,26 +72,48 @@ type Certificate struct {
 // Manager is the certificate manager
 type Manager struct {
 	K0sVars constant.CfgVars
+	// KeyAlgorithm is the algorithm of the keys generated for CAs and
+	// certificates. Defaults to RSA-2048.
+	KeyAlgorithm v1beta1.KeyAlgorithm
+	// CAValidity is the validity of generated CAs. Defaults to ten years.
+	CAValidity time.Duration
+	// Validity is the validity of issued certificates, unless the request
+	// specifies one. Defaults to one year.
+	Validity time.Duration
+	// AdminKubeconfigValidity is the validity of the admin client
+	// certificate. Defaults to the validity of issued certificates.
+	AdminKubeconfigValidity time.Duration
+	// Provider issues the certificates of provided requests, if set.
+	Provider Provider
+
+	provided *providedCertificates
 }
 
-// EnsureCA makes sure the given CA certs and key is created.
+// NewManager creates a certificate manager that uses the given certificate
+// settings.
+func NewManager(k0sVars constant.CfgVars, spec *v1beta1.CertificatesSpec) Manager {
+	return Manager{
+		K0sVars:      k0sVars,
+		KeyAlgorithm: spec.GetKeyAlgorithm(),
+		CAValidity:   spec.GetCAValidity(),
+		Validity:     spec.GetValidity(),
+		provided:     new(providedCertificates),
+
+		AdminKubeconfigValidity: spec.GetAdminKubeconfigValidity(),
+	}
+}
+
+// EnsureCA makes sure the given CA certs and key is created. Existing CAs,
+// e.g. externally issued ones, are validated instead. If there's a file named
+// "<name>-chain.crt" next to them, the CA has to chain up to its certificates.
 func (m *Manager) EnsureCA(name, cn string) error {
-	keyFile := filepath.Join(m.K0sVars.CertRootDir, fmt.Sprintf("%s.key", name))
-	certFile := filepath.Join(m.K0sVars.CertRootDir, fmt.Sprintf("%s.crt", name))
+	certFile, keyFile, _ := m.caFiles(name)
 
 	if file.Exists(keyFile) && file.Exists(certFile) {
-		return nil
+		return m.validateCAFiles(name)
 	}
 
-	req := new(csr.CertificateRequest)
-	req.KeyRequest = csr.NewKeyRequest()
-	req.KeyRequest.A = "rsa"
-	req.KeyRequest.S = 2048
-	req.CN = cn
-	req.CA = &csr.CAConfig{
-		Expiry: "87600h",
-	}
-	cert, _, key, err := initca.New(req)
+	cert, key, err := m.GenerateCA(cn)
 	if err != nil {
 		return err
 	}
@@ -98,77 +131,45 @@ func (m *Manager) EnsureCA(name, cn string) error {
 	return nil
 }
 
+// GenerateCA creates a new self-signed CA with the given common name and
+// returns its PEM encoded certificate and key.
+func (m *Manager) GenerateCA(cn string) (cert, key []byte, err error) {
+	caValidity := m.CAValidity
+	if caValidity == 0 {
+		caValidity = v1beta1.DefaultCAValidity
+	}
+
+	req := new(csr.CertificateRequest)
+	req.KeyRequest = m.keyRequest()
+	req.CN = cn
+	req.CA = &csr.CAConfig{
+		Expiry: caValidity.String(),
+	}
+	cert, _, key, err = initca.New(req)
+	return cert, key, err
+}
+
 // EnsureCertificate creates the specified certificate if it does not already exist
 func (m *Manager) EnsureCertificate(certReq Request, ownerName string) (Certificate, error) {
 
 	keyFile := filepath.Join(m.K0sVars.CertRootDir, fmt.Sprintf("%s.key", certReq.Name))
 	certFile := filepath.Join(m.K0sVars.CertRootDir, fmt.Sprintf("%s.crt", certReq.Name))
 
+	if certReq.Provided && m.Provider != nil {
+		return m.ensureProvidedCertificate(certReq, ownerName)
+	}
+
 	uid, _ := users.GetUID(ownerName)
 
 	// if regenerateCert returns true, it means we need to create the certs
 	if m.regenerateCert(certReq, keyFile, certFile) {
 		logrus.Debugf("creating certificate %s", certFile)
-		req := csr.CertificateRequest{
-			KeyRequest: csr.NewKeyRequest(),
-			CN:         certReq.CN,
-			Names: []csr.Name{
-				{O: certReq.O},
-			},
-		}
-
-		req.KeyRequest.A = "rsa"
-		req.KeyRequest.S = 2048
-		req.Hosts = stringslice.Unique(certReq.Hostnames)
-
-		var key, csrBytes []byte
-		g := &csr.Generator{Validator: genkey.Validator}
-		csrBytes, key, err := g.ProcessRequest(&req)
-		if err != nil {
-			return Certificate{}, err
-		}
-		config := cli.Config{
-			CAFile:    fmt.Sprintf("file:%s", certReq.CACert),
-			CAKeyFile: fmt.Sprintf("file:%s", certReq.CAKey),
-		}
-		s, err := sign.SignerFromConfig(config)
-		if err != nil {
-			return Certificate{}, err
-		}
-
-		var cert []byte
-		signReq := signer.SignRequest{
-			Request: string(csrBytes),
-			Profile: "kubernetes",
-		}
-
-		cert, err = s.Sign(signReq)
-		if err != nil {
-			return Certificate{}, err
-		}
-		c := Certificate{
-			Key:  string(key),
-			Cert: string(cert),
-		}
-		err = file.WriteContentAtomically(keyFile, key, constant.CertSecureMode)
-		if err != nil {
-			return Certificate{}, err
-		}
-		err = file.WriteContentAtomically(certFile, cert, constant.CertMode)
+		c, err := m.IssueCertificate(certReq)
 		if err != nil {
 			return Certificate{}, err
 		}
 
-		err = os.Chown(keyFile, uid, -1)
-		if err != nil && os.Geteuid() == 0 {
-			return Certificate{}, err
-		}
-		err = os.Chown(certFile, uid, -1)
-		if err != nil && os.Geteuid() == 0 {
-			return Certificate{}, err
-		}
-
-		return c, nil
+		return writeCertificate(keyFile, certFile, []byte(c.Key), []byte(c.Cert), uid)
 	}
 
 	// certs exist, let's just verify their permissions
@@ -191,8 +192,151 @@ func (m *Manager) EnsureCertificate(certReq Request, ownerName string) (Certific
 
 }
 
+// IssueCertificate creates a new key and a certificate signed by the CA,
+// without storing them on disk
+func (m *Manager) IssueCertificate(certReq Request) (Certificate, error) {
+	req := certReq.csrRequest()
+	req.KeyRequest = m.keyRequest()
+
+	g := &csr.Generator{Validator: genkey.Validator}
+	csrBytes, key, err := g.ProcessRequest(&req)
+	if err != nil {
+		return Certificate{}, err
+	}
+	cert, err := m.sign(certReq, csrBytes)
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	return Certificate{
+		Key:  string(key),
+		Cert: string(cert),
+	}, nil
+}
+
+// RenewCertificate re-issues the specified certificate, keeping its existing
+// private key
+func (m *Manager) RenewCertificate(certReq Request, ownerName string) (Certificate, error) {
+	keyFile := filepath.Join(m.K0sVars.CertRootDir, fmt.Sprintf("%s.key", certReq.Name))
+	certFile := filepath.Join(m.K0sVars.CertRootDir, fmt.Sprintf("%s.crt", certReq.Name))
+
+	uid, _ := users.GetUID(ownerName)
+
+	key, err := os.ReadFile(keyFile)
+	if err != nil {
+		return Certificate{}, fmt.Errorf("failed to read key %s for %s: %w", keyFile, certReq.Name, err)
+	}
+	privateKey, err := helpers.ParsePrivateKeyPEM(key)
+	if err != nil {
+		return Certificate{}, fmt.Errorf("failed to parse key %s for %s: %w", keyFile, certReq.Name, err)
+	}
+
+	logrus.Debugf("renewing certificate %s", certFile)
+	req := certReq.csrRequest()
+	csrBytes, err := csr.Generate(privateKey, &req)
+	if err != nil {
+		return Certificate{}, err
+	}
+	cert, err := m.sign(certReq, csrBytes)
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	return writeCertificate(keyFile, certFile, key, cert, uid)
+}
+
+// csrRequest returns the certificate signing request for this request,
+// without a key request
+func (r *Request) csrRequest() csr.CertificateRequest {
+	var names []csr.Name
+	if r.O != "" {
+		names = append(names, csr.Name{O: r.O})
+	}
+	for _, group := range r.Groups {
+		names = append(names, csr.Name{O: group})
+	}
+
+	return csr.CertificateRequest{
+		CN:    r.CN,
+		Names: names,
+		Hosts: stringslice.Unique(r.Hostnames),
+	}
+}
+
+// keyRequest returns the key request for the manager's key algorithm
+func (m *Manager) keyRequest() *csr.KeyRequest {
+	switch m.KeyAlgorithm {
+	case v1beta1.KeyAlgorithmRSA4096:
+		return &csr.KeyRequest{A: "rsa", S: 4096}
+	case v1beta1.KeyAlgorithmECDSAP256:
+		return &csr.KeyRequest{A: "ecdsa", S: 256}
+	default:
+		return &csr.KeyRequest{A: "rsa", S: 2048}
+	}
+}
+
+// sign signs the given certificate signing request using the request's CA.
+// Uses the manager's validity if the request doesn't specify one.
+func (m *Manager) sign(r Request, csrBytes []byte) ([]byte, error) {
+	if r.Validity == 0 {
+		r.Validity = m.Validity
+	}
+	return r.sign(csrBytes)
+}
+
+// sign signs the given certificate signing request using this request's CA
+func (r *Request) sign(csrBytes []byte) ([]byte, error) {
+	config := cli.Config{
+		CAFile:    fmt.Sprintf("file:%s", r.CACert),
+		CAKeyFile: fmt.Sprintf("file:%s", r.CAKey),
+	}
+	s, err := sign.SignerFromConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	signReq := signer.SignRequest{
+		Request: string(csrBytes),
+		Profile: "kubernetes",
+	}
+	if r.Validity != 0 {
+		now := time.Now()
+		signReq.NotBefore = now
+		signReq.NotAfter = now.Add(r.Validity)
+	}
+
+	return s.Sign(signReq)
+}
+
+func writeCertificate(keyFile, certFile string, key, cert []byte, uid int) (Certificate, error) {
+	err := file.WriteContentAtomically(keyFile, key, constant.CertSecureMode)
+	if err != nil {
+		return Certificate{}, err
+	}
+	err = file.WriteContentAtomically(certFile, cert, constant.CertMode)
+	if err != nil {
+		return Certificate{}, err
+	}
+
+	err = os.Chown(keyFile, uid, -1)
+	if err != nil && os.Geteuid() == 0 {
+		return Certificate{}, err
+	}
+	err = os.Chown(certFile, uid, -1)
+	if err != nil && os.Geteuid() == 0 {
+		return Certificate{}, err
+	}
+
+	return Certificate{
+		Key:  string(key),
+		Cert: string(cert),
+	}, nil
+}
+
 // if regenerateCert does not need to do any changes, it will return false
 // if a change in SAN hosts is detected, if will return true, to re-generate certs
+// certificates issued by the request's CA, e.g. an externally issued one, are
+// managed by k0s as well
 func (m *Manager) regenerateCert(certReq Request, keyFile string, certFile string) bool {
 	var cert *certinfo.Certificate
 	var err error
@@ -207,7 +351,7 @@ func (m *Manager) regenerateCert(certReq Request, keyFile string, certFile strin
 		return true
 	}
 
-	if isManagedByK0s(cert) {
+	if isManagedByK0s(cert) || isIssuedBy(certFile, certReq.CACert) {
 		return true
 	}
 
@@ -0,0 +1,82 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/cloudflare/cfssl/helpers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestManager_CertificateSettings(t *testing.T) {
+	m := NewManager(constant.CfgVars{CertRootDir: t.TempDir()}, &v1beta1.CertificatesSpec{
+		KeyAlgorithm: v1beta1.KeyAlgorithmECDSAP256,
+		CAValidity:   &metav1.Duration{Duration: 720 * time.Hour},
+		Validity:     &metav1.Duration{Duration: 24 * time.Hour},
+	})
+	require.NoError(t, m.EnsureCA("ca", "kubernetes-ca"))
+
+	caCert, err := readCertificate(filepath.Join(m.K0sVars.CertRootDir, "ca.crt"))
+	require.NoError(t, err)
+	assert.IsType(t, &ecdsa.PublicKey{}, caCert.PublicKey)
+	assert.InDelta(t, 720*time.Hour, caCert.NotAfter.Sub(caCert.NotBefore), float64(10*time.Minute))
+
+	req := Request{
+		Name:   "test",
+		CN:     "test",
+		CACert: filepath.Join(m.K0sVars.CertRootDir, "ca.crt"),
+		CAKey:  filepath.Join(m.K0sVars.CertRootDir, "ca.key"),
+	}
+	cert, err := m.IssueCertificate(req)
+	require.NoError(t, err)
+	leaf, err := helpers.ParseCertificatePEM([]byte(cert.Cert))
+	require.NoError(t, err)
+	assert.IsType(t, &ecdsa.PublicKey{}, leaf.PublicKey)
+	assert.InDelta(t, 24*time.Hour, leaf.NotAfter.Sub(leaf.NotBefore), float64(time.Minute))
+
+	// The request's validity takes precedence.
+	req.Validity = time.Hour
+	cert, err = m.IssueCertificate(req)
+	require.NoError(t, err)
+	leaf, err = helpers.ParseCertificatePEM([]byte(cert.Cert))
+	require.NoError(t, err)
+	assert.InDelta(t, time.Hour, leaf.NotAfter.Sub(leaf.NotBefore), float64(time.Minute))
+}
+
+func TestManager_DefaultKeyAlgorithm(t *testing.T) {
+	m := NewManager(constant.CfgVars{CertRootDir: t.TempDir()}, nil)
+	require.NoError(t, m.EnsureCA("ca", "kubernetes-ca"))
+
+	data, err := os.ReadFile(filepath.Join(m.K0sVars.CertRootDir, "ca.key"))
+	require.NoError(t, err)
+	key, err := helpers.ParsePrivateKeyPEM(data)
+	require.NoError(t, err)
+	if assert.IsType(t, &rsa.PrivateKey{}, key) {
+		assert.Equal(t, 2048, key.(*rsa.PrivateKey).N.BitLen())
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/k0sproject/k0s/internal/pkg/users"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Provider issues certificates on behalf of an external certificate
+// authority, instead of the CAs managed by k0s.
+type Provider interface {
+	// CA returns the PEM encoded certificates of the issuing CA and its
+	// chain, which need to be trusted by the peers of the issued
+	// certificates.
+	CA(ctx context.Context) ([]byte, error)
+
+	// Issue issues a new key and certificate for the given request. The
+	// request's CA is ignored.
+	Issue(ctx context.Context, req Request) (Certificate, error)
+}
+
+// NewProvider creates the provider that's configured in the given spec.
+// Returns nil if there's none.
+func NewProvider(spec *v1beta1.CertificateProviderSpec) (Provider, error) {
+	switch {
+	case spec == nil:
+		return nil, nil
+	case spec.Vault != nil:
+		return NewVaultProvider(spec.Vault)
+	default:
+		return nil, errors.New("no certificate provider configured")
+	}
+}
+
+// providedTimeout is the time to wait for the provider to issue a certificate.
+const providedTimeout = 1 * time.Minute
+
+// providedCertificates keeps track of the certificates issued by a provider,
+// so that they can be renewed.
+type providedCertificates struct {
+	mu       sync.Mutex
+	requests map[string]providedRequest
+}
+
+type providedRequest struct {
+	req   Request
+	owner string
+}
+
+// ImportProviderCA stores the CA of the manager's provider next to the CA
+// with the given name, so that it's part of its trust bundle. Removes a
+// previously stored CA if the CA with the given name isn't supposed to trust
+// the provider.
+func (m *Manager) ImportProviderCA(ctx context.Context, name string, trust bool) error {
+	providerCAFile := m.providerCAFile(name)
+	if m.Provider == nil || !trust {
+		_, err := removeFile(providerCAFile)
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, providedTimeout)
+	defer cancel()
+	ca, err := m.Provider.CA(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get CA of certificate provider: %w", err)
+	}
+	_, err = writeFileIfChanged(providerCAFile, concatPEM(ca), constant.CertMode)
+	return err
+}
+
+func (m *Manager) providerCAFile(name string) string {
+	return filepath.Join(m.K0sVars.CertRootDir, name+"-provider.crt")
+}
+
+// ensureProvidedCertificate issues the requested certificate via the
+// manager's provider and remembers the request for renewals.
+func (m *Manager) ensureProvidedCertificate(certReq Request, ownerName string) (Certificate, error) {
+	if m.provided != nil {
+		m.provided.mu.Lock()
+		if m.provided.requests == nil {
+			m.provided.requests = make(map[string]providedRequest)
+		}
+		m.provided.requests[certReq.Name] = providedRequest{certReq, ownerName}
+		m.provided.mu.Unlock()
+	}
+
+	return m.issueProvidedCertificate(context.Background(), certReq, ownerName)
+}
+
+func (m *Manager) issueProvidedCertificate(ctx context.Context, certReq Request, ownerName string) (Certificate, error) {
+	if certReq.Validity == 0 {
+		certReq.Validity = m.Validity
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, providedTimeout)
+	defer cancel()
+	c, err := m.Provider.Issue(ctx, certReq)
+	if err != nil {
+		return Certificate{}, fmt.Errorf("failed to issue certificate %s via provider: %w", certReq.Name, err)
+	}
+
+	keyFile := filepath.Join(m.K0sVars.CertRootDir, certReq.Name+".key")
+	certFile := filepath.Join(m.K0sVars.CertRootDir, certReq.Name+".crt")
+	uid, _ := users.GetUID(ownerName)
+	return writeCertificate(keyFile, certFile, []byte(c.Key), []byte(c.Cert), uid)
+}
+
+// RenewProvidedCertificates re-issues the certificates issued by the
+// manager's provider once two thirds of their lifetime have passed. The
+// components using them reload them on their own.
+func (m *Manager) RenewProvidedCertificates(ctx context.Context, now time.Time) error {
+	if m.Provider == nil || m.provided == nil {
+		return nil
+	}
+
+	m.provided.mu.Lock()
+	requests := make([]providedRequest, 0, len(m.provided.requests))
+	for _, r := range m.provided.requests {
+		requests = append(requests, r)
+	}
+	m.provided.mu.Unlock()
+
+	var errs []error
+	for _, r := range requests {
+		certFile := filepath.Join(m.K0sVars.CertRootDir, r.req.Name+".crt")
+		if cert, err := readCertificate(certFile); err == nil {
+			lifetime := cert.NotAfter.Sub(cert.NotBefore)
+			if now.Before(cert.NotBefore.Add(lifetime * 2 / 3)) {
+				continue
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			logrus.WithError(err).Warnf("Failed to read %s, re-issuing it", certFile)
+		}
+
+		logrus.Infof("Renewing certificate %s via provider", certFile)
+		if _, err := m.issueProvidedCertificate(ctx, r.req, r.owner); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProvider issues certificates with a CA managed by another manager.
+type fakeProvider struct {
+	ca     Manager
+	issued int
+}
+
+func (p *fakeProvider) CA(context.Context) ([]byte, error) {
+	return os.ReadFile(filepath.Join(p.ca.K0sVars.CertRootDir, "provider-ca.crt"))
+}
+
+func (p *fakeProvider) Issue(_ context.Context, req Request) (Certificate, error) {
+	p.issued++
+	req.CACert = filepath.Join(p.ca.K0sVars.CertRootDir, "provider-ca.crt")
+	req.CAKey = filepath.Join(p.ca.K0sVars.CertRootDir, "provider-ca.key")
+	return p.ca.IssueCertificate(req)
+}
+
+func TestManager_ProvidedCertificates(t *testing.T) {
+	providerCA := NewManager(constant.CfgVars{CertRootDir: t.TempDir()}, nil)
+	require.NoError(t, providerCA.EnsureCA("provider-ca", "provider-ca"))
+	provider := &fakeProvider{ca: providerCA}
+
+	m := NewManager(constant.CfgVars{CertRootDir: t.TempDir()}, nil)
+	m.Provider = provider
+	require.NoError(t, m.EnsureCA("ca", "kubernetes-ca"))
+	require.NoError(t, m.ImportProviderCA(context.TODO(), "ca", true))
+	_, err := m.WriteCABundle("ca")
+	require.NoError(t, err)
+
+	bundle, err := os.ReadFile(filepath.Join(m.K0sVars.CertRootDir, "ca-bundle.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(bundle), "BEGIN CERTIFICATE"), "bundle should contain the provider's CA")
+
+	req := Request{
+		Name:      "server",
+		CN:        "kubernetes",
+		CACert:    filepath.Join(m.K0sVars.CertRootDir, "ca.crt"),
+		CAKey:     filepath.Join(m.K0sVars.CertRootDir, "ca.key"),
+		Hostnames: []string{"localhost"},
+		Validity:  3 * time.Hour,
+		Provided:  true,
+	}
+	_, err = m.EnsureCertificate(req, "")
+	require.NoError(t, err)
+	assert.Equal(t, 1, provider.issued)
+
+	certFile := filepath.Join(m.K0sVars.CertRootDir, "server.crt")
+	caFile := filepath.Join(providerCA.K0sVars.CertRootDir, "provider-ca.crt")
+	assert.True(t, isIssuedBy(certFile, caFile), "certificate should be issued by the provider")
+
+	// Not renewed before two thirds of the lifetime have passed.
+	require.NoError(t, m.RenewProvidedCertificates(context.TODO(), time.Now().Add(time.Hour)))
+	assert.Equal(t, 1, provider.issued)
+
+	require.NoError(t, m.RenewProvidedCertificates(context.TODO(), time.Now().Add(2*time.Hour+time.Minute)))
+	assert.Equal(t, 2, provider.issued)
+
+	// The provider's CA is removed if it isn't trusted anymore.
+	require.NoError(t, m.ImportProviderCA(context.TODO(), "ca", false))
+	_, err = m.WriteCABundle("ca")
+	require.NoError(t, err)
+	bundle, err = os.ReadFile(filepath.Join(m.K0sVars.CertRootDir, "ca-bundle.crt"))
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(bundle), "BEGIN CERTIFICATE"))
+}
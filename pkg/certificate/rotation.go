@@ -0,0 +1,339 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CARotationSecretName is the name of the Secret in the kube-system namespace
+// that holds the cluster-wide state of a CA rotation.
+const CARotationSecretName = "k0s-ca-rotation"
+
+// CARotationPhase is a stage of a CA rotation.
+type CARotationPhase string
+
+const (
+	// CARotationTrust adds the new CA to all trust bundles. Certificates are
+	// still issued by the previous CA.
+	CARotationTrust CARotationPhase = "trust"
+	// CARotationReissue issues all certificates with the new CA. The previous
+	// CA is still trusted, so that certificates issued by it remain valid
+	// until they have been replaced.
+	CARotationReissue CARotationPhase = "reissue"
+	// CARotationFinalize removes the previous CA from all trust bundles.
+	CARotationFinalize CARotationPhase = "finalize"
+)
+
+const (
+	caRotationPhaseKey    = "phase"
+	caRotationCertKey     = "ca.crt"
+	caRotationKeyKey      = "ca.key"
+	caRotationPreviousKey = "previous-ca.crt"
+)
+
+// CARotation is the cluster-wide state of a CA rotation.
+type CARotation struct {
+	Phase CARotationPhase
+
+	// The PEM encoded certificate and key of the CA that's rotated in.
+	CACert, CAKey []byte
+
+	// The PEM encoded certificate of the CA that's rotated out.
+	PreviousCACert []byte
+}
+
+// StartCARotation starts a new rotation from the current CA to the given one.
+// A new rotation may only be started if there's no other rotation in progress.
+func StartCARotation(current *CARotation, currentCACert, caCert, caKey []byte) (*CARotation, error) {
+	if current != nil && current.Phase != CARotationFinalize {
+		return nil, fmt.Errorf("CA rotation already in progress, currently in phase %q", current.Phase)
+	}
+	if err := ValidateCA(caCert, caKey, nil, time.Now()); err != nil {
+		return nil, fmt.Errorf("invalid CA: %w", err)
+	}
+	if bytes.Equal(currentCACert, caCert) {
+		return nil, errors.New("the new CA is the same as the current one")
+	}
+
+	return &CARotation{
+		Phase:          CARotationTrust,
+		CACert:         caCert,
+		CAKey:          caKey,
+		PreviousCACert: currentCACert,
+	}, nil
+}
+
+// Advance moves the rotation to the given phase. Phases can't be skipped.
+func (r *CARotation) Advance(phase CARotationPhase) error {
+	var from CARotationPhase
+	switch phase {
+	case CARotationReissue:
+		from = CARotationTrust
+	case CARotationFinalize:
+		from = CARotationReissue
+	default:
+		return fmt.Errorf("cannot advance CA rotation to phase %q", phase)
+	}
+
+	if r.Phase != from {
+		return fmt.Errorf("cannot advance CA rotation from phase %q to %q, expected phase %q", r.Phase, phase, from)
+	}
+	r.Phase = phase
+	return nil
+}
+
+// Signer returns the certificate of the CA that issues certificates in the
+// rotation's current phase.
+func (r *CARotation) Signer() []byte {
+	if r.Phase == CARotationTrust {
+		return r.PreviousCACert
+	}
+	return r.CACert
+}
+
+// TrustBundle returns the certificates of all the CAs that are trusted in the
+// rotation's current phase, the signer first.
+func (r *CARotation) TrustBundle() []byte {
+	switch r.Phase {
+	case CARotationTrust:
+		return concatPEM(r.PreviousCACert, r.CACert)
+	case CARotationReissue:
+		return concatPEM(r.CACert, r.PreviousCACert)
+	default:
+		return concatPEM(r.CACert)
+	}
+}
+
+// CARotationFromSecret parses the rotation state stored in the given Secret.
+func CARotationFromSecret(secret *corev1.Secret) (*CARotation, error) {
+	r := &CARotation{
+		Phase:          CARotationPhase(secret.Data[caRotationPhaseKey]),
+		CACert:         secret.Data[caRotationCertKey],
+		CAKey:          secret.Data[caRotationKeyKey],
+		PreviousCACert: secret.Data[caRotationPreviousKey],
+	}
+
+	switch r.Phase {
+	case CARotationTrust, CARotationReissue, CARotationFinalize:
+	default:
+		return nil, fmt.Errorf("unknown CA rotation phase: %q", r.Phase)
+	}
+	for key, value := range map[string][]byte{
+		caRotationCertKey:     r.CACert,
+		caRotationKeyKey:      r.CAKey,
+		caRotationPreviousKey: r.PreviousCACert,
+	} {
+		if len(value) == 0 {
+			return nil, fmt.Errorf("CA rotation secret is missing %s", key)
+		}
+	}
+
+	return r, nil
+}
+
+// Secret returns the Secret that stores the rotation state.
+func (r *CARotation) Secret() *corev1.Secret {
+	return &corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      CARotationSecretName,
+			Namespace: metav1.NamespaceSystem,
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			caRotationPhaseKey:    []byte(r.Phase),
+			caRotationCertKey:     r.CACert,
+			caRotationKeyKey:      r.CAKey,
+			caRotationPreviousKey: r.PreviousCACert,
+		},
+	}
+}
+
+// ApplyCARotation brings the files of the CA with the given name in line with
+// the given rotation state and rewrites its trust bundle. Returns true if any
+// of the files changed.
+//
+//   - In the trust phase, the new CA is stored as "<name>-next.crt/key".
+//   - In the reissue phase, the new CA replaces "<name>.crt/key", and the
+//     previous one is kept as "<name>-previous.crt". The previous CA's chain
+//     is removed.
+//   - In the finalize phase, the previous CA is removed.
+//
+// Certificates issued by the CA aren't touched. They're reissued by the
+// controller on the next start.
+func (m *Manager) ApplyCARotation(name string, r *CARotation) (changed bool, err error) {
+	if err := ValidateCA(r.CACert, r.CAKey, nil, time.Now()); err != nil {
+		return false, fmt.Errorf("invalid CA: %w", err)
+	}
+
+	certFile, keyFile, chainFile := m.caFiles(name)
+	nextCertFile, nextKeyFile, previousCertFile := m.caRotationFiles(name)
+
+	current, err := os.ReadFile(certFile)
+	if err != nil {
+		return false, err
+	}
+
+	var writes []func() (bool, error)
+	write := func(path string, data []byte, mode fs.FileMode) {
+		writes = append(writes, func() (bool, error) { return writeFileIfChanged(path, data, mode) })
+	}
+	remove := func(path string) {
+		writes = append(writes, func() (bool, error) { return removeFile(path) })
+	}
+
+	switch {
+	case r.Phase == CARotationTrust && !bytes.Equal(current, r.CACert):
+		write(nextKeyFile, r.CAKey, constant.CertSecureMode)
+		write(nextCertFile, r.CACert, constant.CertMode)
+		remove(previousCertFile)
+
+	case r.Phase == CARotationTrust:
+		return false, fmt.Errorf("%s has already been replaced by the new CA", certFile)
+
+	case r.Phase == CARotationReissue:
+		write(previousCertFile, r.PreviousCACert, constant.CertMode)
+		fallthrough
+
+	default:
+		write(keyFile, r.CAKey, constant.CertSecureMode)
+		write(certFile, r.CACert, constant.CertMode)
+		if !bytes.Equal(current, r.CACert) {
+			// The chain belongs to the previous CA.
+			remove(chainFile)
+		}
+		remove(nextKeyFile)
+		remove(nextCertFile)
+		if r.Phase == CARotationFinalize {
+			remove(previousCertFile)
+		}
+	}
+
+	for _, write := range writes {
+		written, err := write()
+		if err != nil {
+			return changed, err
+		}
+		changed = changed || written
+	}
+
+	written, err := m.WriteCABundle(name)
+	return changed || written, err
+}
+
+// WriteCABundle writes the trust bundle of the CA with the given name, i.e.
+// the CA itself along with the CAs that are currently rotated in or out and
+// the CA of the certificate provider, if any.
+func (m *Manager) WriteCABundle(name string) (changed bool, err error) {
+	certFile, _, _ := m.caFiles(name)
+	nextCertFile, _, previousCertFile := m.caRotationFiles(name)
+
+	var certs [][]byte
+	for _, path := range []string{certFile, nextCertFile, previousCertFile, m.providerCAFile(name)} {
+		cert, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) && path != certFile {
+			continue
+		} else if err != nil {
+			return false, err
+		}
+		certs = append(certs, cert)
+	}
+
+	return writeFileIfChanged(m.caBundleFile(name), concatPEM(certs...), constant.CertMode)
+}
+
+// ReadCABundle reads the trust bundle of the cluster CA. Falls back to the
+// CA certificate if there's no bundle.
+func ReadCABundle(certRootDir string) ([]byte, error) {
+	bundle, err := os.ReadFile(CABundlePath(certRootDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return os.ReadFile(filepath.Join(certRootDir, "ca.crt"))
+	}
+	return bundle, err
+}
+
+// CABundleVersion returns the version of the given trust bundle. It changes
+// whenever the bundle changes, i.e. in each phase of a CA rotation, so that
+// nodes can tell whether they trust the current set of CAs.
+func CABundleVersion(bundle []byte) string {
+	hash := sha256.Sum256(bundle)
+	return hex.EncodeToString(hash[:8])
+}
+
+// CABundlePath returns the path of the cluster CA's trust bundle.
+func CABundlePath(certRootDir string) string {
+	return filepath.Join(certRootDir, "ca-bundle.crt")
+}
+
+func (m *Manager) caBundleFile(name string) string {
+	return filepath.Join(m.K0sVars.CertRootDir, name+"-bundle.crt")
+}
+
+// caRotationFiles returns the paths of the files that hold the CAs that are
+// rotated in or out.
+func (m *Manager) caRotationFiles(name string) (nextCertFile, nextKeyFile, previousCertFile string) {
+	base := filepath.Join(m.K0sVars.CertRootDir, name)
+	return base + "-next.crt", base + "-next.key", base + "-previous.crt"
+}
+
+// concatPEM concatenates the given PEM encoded blocks, making sure that each
+// of them ends with a line break.
+func concatPEM(blocks ...[]byte) []byte {
+	var buf bytes.Buffer
+	for _, block := range blocks {
+		block = bytes.TrimSpace(block)
+		if len(block) == 0 {
+			continue
+		}
+		buf.Write(block)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func writeFileIfChanged(path string, data []byte, mode fs.FileMode) (bool, error) {
+	if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, data) {
+		return false, nil
+	}
+	return true, file.WriteContentAtomically(path, data, mode)
+}
+
+func removeFile(path string) (bool, error) {
+	err := os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
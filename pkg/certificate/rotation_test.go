@@ -0,0 +1,186 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCARotation_Phases(t *testing.T) {
+	previous := newTestCA(t, "previous", nil, nil)
+	next := newTestCA(t, "next", nil, nil)
+
+	_, err := StartCARotation(nil, previous.certPEM, previous.certPEM, previous.keyPEM)
+	assert.EqualError(t, err, "the new CA is the same as the current one")
+
+	r, err := StartCARotation(nil, previous.certPEM, next.certPEM, next.keyPEM)
+	require.NoError(t, err)
+	assert.Equal(t, CARotationTrust, r.Phase)
+	assert.Equal(t, previous.certPEM, r.Signer())
+	assert.Equal(t, concatPEM(previous.certPEM, next.certPEM), r.TrustBundle())
+
+	_, err = StartCARotation(r, next.certPEM, previous.certPEM, previous.keyPEM)
+	assert.EqualError(t, err, `CA rotation already in progress, currently in phase "trust"`)
+	assert.EqualError(t, r.Advance(CARotationFinalize), `cannot advance CA rotation from phase "trust" to "finalize", expected phase "reissue"`)
+
+	require.NoError(t, r.Advance(CARotationReissue))
+	assert.Equal(t, next.certPEM, r.Signer())
+	assert.Equal(t, concatPEM(next.certPEM, previous.certPEM), r.TrustBundle())
+
+	require.NoError(t, r.Advance(CARotationFinalize))
+	assert.Equal(t, next.certPEM, r.Signer())
+	assert.Equal(t, concatPEM(next.certPEM), r.TrustBundle())
+	assert.EqualError(t, r.Advance(CARotationTrust), `cannot advance CA rotation to phase "trust"`)
+
+	// A finalized rotation may be followed by a new one.
+	r, err = StartCARotation(r, next.certPEM, previous.certPEM, previous.keyPEM)
+	require.NoError(t, err)
+	assert.Equal(t, CARotationTrust, r.Phase)
+}
+
+func TestCARotation_Secret(t *testing.T) {
+	previous := newTestCA(t, "previous", nil, nil)
+	next := newTestCA(t, "next", nil, nil)
+
+	r, err := StartCARotation(nil, previous.certPEM, next.certPEM, next.keyPEM)
+	require.NoError(t, err)
+
+	parsed, err := CARotationFromSecret(r.Secret())
+	require.NoError(t, err)
+	assert.Equal(t, r, parsed)
+
+	secret := r.Secret()
+	secret.Data["phase"] = []byte("bogus")
+	_, err = CARotationFromSecret(secret)
+	assert.EqualError(t, err, `unknown CA rotation phase: "bogus"`)
+
+	secret = r.Secret()
+	delete(secret.Data, "ca.key")
+	_, err = CARotationFromSecret(secret)
+	assert.EqualError(t, err, "CA rotation secret is missing ca.key")
+}
+
+func TestManager_ApplyCARotation(t *testing.T) {
+	previous := newTestCA(t, "previous", nil, nil)
+	next := newTestCA(t, "next", nil, nil)
+
+	m := &Manager{K0sVars: constant.CfgVars{CertRootDir: t.TempDir()}}
+	dir := m.K0sVars.CertRootDir
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), previous.certPEM, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.key"), previous.keyPEM, 0600))
+
+	readFile := func(t *testing.T, name string) []byte {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return data
+	}
+
+	r, err := StartCARotation(nil, previous.certPEM, next.certPEM, next.keyPEM)
+	require.NoError(t, err)
+
+	t.Run("trust", func(t *testing.T) {
+		changed, err := m.ApplyCARotation("ca", r)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, previous.certPEM, readFile(t, "ca.crt"))
+		assert.Equal(t, next.certPEM, readFile(t, "ca-next.crt"))
+		assert.Equal(t, next.keyPEM, readFile(t, "ca-next.key"))
+		assert.Equal(t, r.TrustBundle(), readFile(t, "ca-bundle.crt"))
+
+		changed, err = m.ApplyCARotation("ca", r)
+		require.NoError(t, err)
+		assert.False(t, changed, "applying the same phase twice shouldn't change anything")
+	})
+
+	t.Run("reissue", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "ca-chain.crt"), previous.certPEM, 0644))
+		require.NoError(t, r.Advance(CARotationReissue))
+
+		changed, err := m.ApplyCARotation("ca", r)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, next.certPEM, readFile(t, "ca.crt"))
+		assert.Equal(t, next.keyPEM, readFile(t, "ca.key"))
+		assert.Equal(t, previous.certPEM, readFile(t, "ca-previous.crt"))
+		assert.NoFileExists(t, filepath.Join(dir, "ca-chain.crt"))
+		assert.NoFileExists(t, filepath.Join(dir, "ca-next.crt"))
+		assert.NoFileExists(t, filepath.Join(dir, "ca-next.key"))
+		assert.Equal(t, r.TrustBundle(), readFile(t, "ca-bundle.crt"))
+
+		// The new CA can't be distributed once it has been put in place.
+		trust := *r
+		trust.Phase = CARotationTrust
+		_, err = m.ApplyCARotation("ca", &trust)
+		assert.ErrorContains(t, err, "has already been replaced by the new CA")
+	})
+
+	t.Run("finalize", func(t *testing.T) {
+		require.NoError(t, r.Advance(CARotationFinalize))
+
+		changed, err := m.ApplyCARotation("ca", r)
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.Equal(t, next.certPEM, readFile(t, "ca.crt"))
+		assert.NoFileExists(t, filepath.Join(dir, "ca-previous.crt"))
+		assert.Equal(t, r.TrustBundle(), readFile(t, "ca-bundle.crt"))
+	})
+}
+
+func TestReadCABundle(t *testing.T) {
+	ca := newTestCA(t, "ca", nil, nil)
+	dir := t.TempDir()
+
+	_, err := ReadCABundle(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ca.crt"), ca.certPEM, 0644))
+	bundle, err := ReadCABundle(dir)
+	require.NoError(t, err)
+	assert.Equal(t, ca.certPEM, bundle, "should fall back to the CA certificate")
+
+	require.NoError(t, os.WriteFile(CABundlePath(dir), []byte("bundle"), 0644))
+	bundle, err = ReadCABundle(dir)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bundle"), bundle)
+}
+
+func TestCABundleVersion(t *testing.T) {
+	previous := newTestCA(t, "previous", nil, nil)
+	next := newTestCA(t, "next", nil, nil)
+	r, err := StartCARotation(nil, previous.certPEM, next.certPEM, next.keyPEM)
+	require.NoError(t, err)
+
+	versions := map[string]CARotationPhase{}
+	for _, phase := range []CARotationPhase{CARotationTrust, CARotationReissue, CARotationFinalize} {
+		if phase != CARotationTrust {
+			require.NoError(t, r.Advance(phase))
+		}
+		version := CABundleVersion(r.TrustBundle())
+		assert.Len(t, version, 16)
+		assert.NotContains(t, versions, version, "version of phase %q isn't unique", phase)
+		versions[version] = phase
+	}
+
+	assert.Equal(t, CABundleVersion(next.certPEM), CABundleVersion(next.certPEM))
+}
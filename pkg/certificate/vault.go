@@ -0,0 +1,186 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+)
+
+// VaultProvider issues certificates via the PKI secrets engine of a
+// HashiCorp Vault server.
+type VaultProvider struct {
+	client    *http.Client
+	baseURL   *url.URL
+	mount     string
+	role      string
+	namespace string
+	tokenFile string
+}
+
+var _ Provider = (*VaultProvider)(nil)
+
+// NewVaultProvider creates a provider for the given Vault configuration.
+func NewVaultProvider(spec *v1beta1.VaultProviderSpec) (*VaultProvider, error) {
+	baseURL, err := url.Parse(spec.Address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid Vault address: %w", err)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if spec.CAFile != "" {
+		ca, err := os.ReadFile(spec.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read Vault CA: %w", err)
+		}
+		roots := x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in %s", spec.CAFile)
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+	}
+
+	return &VaultProvider{
+		client:    &http.Client{Transport: transport, Timeout: 30 * time.Second},
+		baseURL:   baseURL,
+		mount:     spec.GetMount(),
+		role:      spec.Role,
+		namespace: spec.Namespace,
+		tokenFile: spec.TokenFile,
+	}, nil
+}
+
+// CA implements [Provider].
+func (v *VaultProvider) CA(ctx context.Context) ([]byte, error) {
+	var data struct {
+		Certificate string `json:"certificate"`
+	}
+	if err := v.do(ctx, http.MethodGet, "cert/ca_chain", nil, &data); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(data.Certificate) == "" {
+		return nil, errors.New("empty CA chain returned by Vault")
+	}
+	return []byte(data.Certificate), nil
+}
+
+// Issue implements [Provider].
+func (v *VaultProvider) Issue(ctx context.Context, req Request) (Certificate, error) {
+	var ips, names []string
+	for _, hostname := range req.Hostnames {
+		if net.ParseIP(hostname) != nil {
+			ips = append(ips, hostname)
+		} else {
+			names = append(names, hostname)
+		}
+	}
+
+	body := map[string]any{
+		"common_name":          req.CN,
+		"alt_names":            strings.Join(names, ","),
+		"ip_sans":              strings.Join(ips, ","),
+		"exclude_cn_from_sans": true,
+		"format":               "pem",
+		"private_key_format":   "pem",
+	}
+	if req.Validity != 0 {
+		body["ttl"] = fmt.Sprintf("%ds", int64(req.Validity.Seconds()))
+	}
+
+	var data struct {
+		Certificate string `json:"certificate"`
+		PrivateKey  string `json:"private_key"`
+	}
+	if err := v.do(ctx, http.MethodPost, "issue/"+v.role, body, &data); err != nil {
+		return Certificate{}, err
+	}
+	if data.Certificate == "" || data.PrivateKey == "" {
+		return Certificate{}, errors.New("no certificate and key returned by Vault")
+	}
+
+	return Certificate{
+		Cert: strings.TrimSpace(data.Certificate) + "\n",
+		Key:  strings.TrimSpace(data.PrivateKey) + "\n",
+	}, nil
+}
+
+// do sends a request to the given path of the PKI secrets engine and decodes
+// the data of the response into the given value.
+func (v *VaultProvider) do(ctx context.Context, method, path string, body any, data any) error {
+	token, err := os.ReadFile(v.tokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read Vault token: %w", err)
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(encoded)
+	}
+
+	u := v.baseURL.JoinPath("v1", v.mount, path)
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", strings.TrimSpace(string(token)))
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var decoded struct {
+		Errors []string        `json:"errors"`
+		Data   json.RawMessage `json:"data"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&decoded); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to decode Vault response (%s): %w", resp.Status, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(decoded.Errors) > 0 {
+			return fmt.Errorf("%s %s: %s: %s", method, u.Path, resp.Status, strings.Join(decoded.Errors, "; "))
+		}
+		return fmt.Errorf("%s %s: %s", method, u.Path, resp.Status)
+	}
+
+	return json.Unmarshal(decoded.Data, data)
+}
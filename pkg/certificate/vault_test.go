@@ -0,0 +1,104 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package certificate
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider(t *testing.T) {
+	var requests []*http.Request
+	var bodies []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		if r.Body != nil && r.Method == http.MethodPost {
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		}
+		requests = append(requests, r)
+		bodies = append(bodies, body)
+
+		switch r.URL.Path {
+		case "/v1/k0s-pki/cert/ca_chain":
+			_, _ = w.Write([]byte(`{"data":{"certificate":"CA CHAIN"}}`))
+		case "/v1/k0s-pki/issue/k0s":
+			_, _ = w.Write([]byte(`{"data":{"certificate":"CERT","private_key":"KEY"}}`))
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("s.token\n"), 0600))
+
+	spec := &v1beta1.VaultProviderSpec{
+		Address:   server.URL,
+		TokenFile: tokenFile,
+		Namespace: "ns",
+		Mount:     "k0s-pki",
+		Role:      "k0s",
+	}
+	provider, err := NewVaultProvider(spec)
+	require.NoError(t, err)
+
+	ca, err := provider.CA(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "CA CHAIN", string(ca))
+
+	cert, err := provider.Issue(context.TODO(), Request{
+		Name:      "server",
+		CN:        "kubernetes",
+		Hostnames: []string{"localhost", "127.0.0.1", "kubernetes.default", "::1"},
+		Validity:  24 * time.Hour,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, Certificate{Cert: "CERT\n", Key: "KEY\n"}, cert)
+
+	require.Len(t, requests, 2)
+	for _, r := range requests {
+		assert.Equal(t, "s.token", r.Header.Get("X-Vault-Token"))
+		assert.Equal(t, "ns", r.Header.Get("X-Vault-Namespace"))
+	}
+	assert.Equal(t, map[string]any{
+		"common_name":          "kubernetes",
+		"alt_names":            "localhost,kubernetes.default",
+		"ip_sans":              "127.0.0.1,::1",
+		"exclude_cn_from_sans": true,
+		"format":               "pem",
+		"private_key_format":   "pem",
+		"ttl":                  "86400s",
+	}, bodies[1])
+
+	spec.Role = "unknown"
+	provider, err = NewVaultProvider(spec)
+	require.NoError(t, err)
+	_, err = provider.Issue(context.TODO(), Request{CN: "kubernetes"})
+	assert.ErrorContains(t, err, "permission denied")
+}
@@ -18,6 +18,7 @@ package cleanup
 
 import (
 	"fmt"
+	"io"
 	"os/exec"
 
 	"github.com/k0sproject/k0s/pkg/component/worker"
@@ -33,16 +34,31 @@ type Config struct {
 	containerRuntime runtime.ContainerRuntime
 	dataDir          string
 	k0sVars          constant.CfgVars
+	opts             Options
 	runDir           string
 }
 
+// Options controls what the cleanup leaves behind on the host.
+type Options struct {
+	// DryRun only reports what would be removed, without removing anything.
+	DryRun bool
+	// KeepDataDir leaves the k0s data directory in place.
+	KeepDataDir bool
+	// KeepCNI leaves CNI configuration files, network interfaces and packet
+	// filtering rules in place.
+	KeepCNI bool
+	// KeepContainerdState leaves the containerd root and state directories in
+	// place, and doesn't stop or remove any containers.
+	KeepContainerdState bool
+}
+
 type containerdConfig struct {
 	binPath    string
 	cmd        *exec.Cmd
 	socketPath string
 }
 
-func NewConfig(k0sVars constant.CfgVars, cfgFile string, criSocketPath string) (*Config, error) {
+func NewConfig(k0sVars constant.CfgVars, cfgFile string, criSocketPath string, opts Options) (*Config, error) {
 	runDir := "/run/k0s" // https://github.com/k0sproject/k0s/pull/591/commits/c3f932de85a0b209908ad39b817750efc4987395
 
 	var err error
@@ -70,38 +86,127 @@ func NewConfig(k0sVars constant.CfgVars, cfgFile string, criSocketPath string) (
 		dataDir:          k0sVars.DataDir,
 		runDir:           runDir,
 		k0sVars:          k0sVars,
+		opts:             opts,
 	}, nil
 }
 
-func (c *Config) Cleanup() error {
+// Cleanup runs all cleanup steps and writes a report of the leftovers that
+// couldn't be removed to out.
+func (c *Config) Cleanup(out io.Writer) error {
 	var msg []error
-	cleanupSteps := []Step{
-		&containers{Config: c},
-		&users{Config: c},
-		&services{Config: c},
-		&directories{Config: c},
-		&cni{},
-		&bridge{},
-	}
+	var leftovers []string
 
-	for _, step := range cleanupSteps {
+	for _, step := range c.steps() {
 		logrus.Info("* ", step.Name())
 		err := step.Run()
 		if err != nil {
 			logrus.Debug(err)
 			msg = append(msg, err)
 		}
+		// Verify right away, as later steps may remove what's needed for the
+		// verification, e.g. the directories step removes the k0s binaries.
+		leftovers = append(leftovers, verify(step)...)
+	}
+
+	if err := reportLeftovers(leftovers, out); err != nil {
+		msg = append(msg, err)
 	}
+
 	if len(msg) > 0 {
 		return fmt.Errorf("errors received during clean-up: %v", msg)
 	}
 	return nil
 }
 
+// verify returns the leftovers of step, if it is verifiable.
+func verify(step Step) []string {
+	v, ok := step.(verifiableStep)
+	if !ok {
+		return nil
+	}
+	found, err := v.Verify()
+	if err != nil {
+		logrus.Warnf("failed to verify %s: %v", step.Name(), err)
+	}
+	return found
+}
+
+// reportLeftovers writes the leftovers that couldn't be removed to out.
+func reportLeftovers(leftovers []string, out io.Writer) error {
+	if len(leftovers) == 0 {
+		return nil
+	}
+
+	if _, err := fmt.Fprintln(out, "The following leftovers could not be removed:"); err != nil {
+		return err
+	}
+	for _, leftover := range leftovers {
+		if _, err := fmt.Fprintf(out, "  - %s\n", leftover); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DryRun writes every operation the cleanup would perform to out, without
+// actually modifying the host.
+func (c *Config) DryRun(out io.Writer) error {
+	var msg []error
+
+	for _, step := range c.steps() {
+		if _, err := fmt.Fprintf(out, "* %s\n", step.Name()); err != nil {
+			return err
+		}
+		actions, err := step.DryRun()
+		if err != nil {
+			logrus.Debug(err)
+			msg = append(msg, err)
+		}
+		for _, action := range actions {
+			if _, err := fmt.Fprintf(out, "  - %s\n", action); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(msg) > 0 {
+		return fmt.Errorf("errors received during clean-up dry-run: %v", msg)
+	}
+	return nil
+}
+
+func (c *Config) steps() []Step {
+	var steps []Step
+	// the containers are left alone along with the containerd state
+	if !c.opts.KeepContainerdState {
+		steps = append(steps, &containers{Config: c})
+	}
+	steps = append(steps, &users{Config: c}, &services{Config: c})
+	// the network step depends on the k0s binaries and the CNI config files
+	if !c.opts.KeepCNI {
+		steps = append(steps, &network{Config: c})
+	}
+	steps = append(steps, &directories{Config: c})
+	if !c.opts.KeepCNI {
+		steps = append(steps, &cni{})
+	}
+	return steps
+}
+
 // Step interface is used to implement cleanup steps
 type Step interface {
 	// Run impelements specific cleanup operations
 	Run() error
 	// Name returns name of the step for conveninece
 	Name() string
+	// DryRun returns a description of each operation Run would perform
+	DryRun() ([]string, error)
+}
+
+// verifiableStep is implemented by steps that can detect what they failed to
+// remove after they ran
+type verifiableStep interface {
+	Step
+	// Verify returns a description of each leftover that is still present
+	Verify() ([]string, error)
 }
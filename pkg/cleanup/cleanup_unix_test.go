@@ -0,0 +1,44 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSteps(t *testing.T) {
+	hasContainersStep := func(steps []Step) bool {
+		for _, step := range steps {
+			if _, ok := step.(*containers); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("stops and removes containers by default", func(t *testing.T) {
+		underTest := &Config{}
+		assert.True(t, hasContainersStep(underTest.steps()))
+	})
+
+	t.Run("leaves containers alone if containerd state is kept", func(t *testing.T) {
+		underTest := &Config{opts: Options{KeepContainerdState: true}}
+		assert.False(t, hasContainersStep(underTest.steps()))
+	})
+}
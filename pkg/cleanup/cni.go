@@ -27,6 +27,12 @@ import (
 
 type cni struct{}
 
+var cniFiles = []string{
+	"/etc/cni/net.d/10-calico.conflist",
+	"/etc/cni/net.d/calico-kubeconfig",
+	"/etc/cni/net.d/10-kuberouter.conflist",
+}
+
 // Name returns the name of the step
 func (c *cni) Name() string {
 	return "CNI leftovers cleanup step"
@@ -36,12 +42,7 @@ func (c *cni) Name() string {
 func (c *cni) Run() error {
 	var msg []error
 
-	files := []string{
-		"/etc/cni/net.d/10-calico.conflist",
-		"/etc/cni/net.d/calico-kubeconfig",
-		"/etc/cni/net.d/10-kuberouter.conflist",
-	}
-	for _, f := range files {
+	for _, f := range cniFiles {
 		if err := os.Remove(f); err != nil && !errors.Is(err, fs.ErrNotExist) {
 			logrus.Debug("failed to remove", f, err)
 			msg = append(msg, err)
@@ -52,3 +53,14 @@ func (c *cni) Run() error {
 	}
 	return nil
 }
+
+// DryRun lists the CNI leftovers that Run would remove
+func (c *cni) DryRun() ([]string, error) {
+	var actions []string
+	for _, f := range cniFiles {
+		if _, err := os.Stat(f); err == nil {
+			actions = append(actions, fmt.Sprintf("remove file %s", f))
+		}
+	}
+	return actions, nil
+}
@@ -65,6 +65,52 @@ func (c *containers) Run() error {
 	return nil
 }
 
+// DryRun lists the containers and mounts that Run would remove
+func (c *containers) DryRun() ([]string, error) {
+	var actions []string
+
+	if c.isCustomCriUsed() {
+		pods, err := c.Config.containerRuntime.ListContainers()
+		if err != nil {
+			return nil, fmt.Errorf("failed to list containers: %w", err)
+		}
+		for _, pod := range pods {
+			actions = append(actions, fmt.Sprintf("stop and remove container %s", pod))
+		}
+	} else {
+		// listing the containers would require starting containerd, which is
+		// exactly what a dry-run should avoid
+		actions = append(actions, fmt.Sprintf("start containerd (%s) to stop and remove all containers", c.Config.containerd.binPath))
+	}
+
+	for _, path := range []string{"kubelet/pods", "run/netns"} {
+		mountPoints, err := listMounts(path)
+		if err != nil {
+			return actions, err
+		}
+		for _, mountPoint := range mountPoints {
+			actions = append(actions, fmt.Sprintf("unmount and remove %s", mountPoint))
+		}
+	}
+
+	return actions, nil
+}
+
+// listMounts returns all mount points containing path
+func listMounts(path string) ([]string, error) {
+	procMounts, err := mount.New("").List()
+	if err != nil {
+		return nil, err
+	}
+	var mountPoints []string
+	for _, v := range procMounts {
+		if strings.Contains(v.Path, path) {
+			mountPoints = append(mountPoints, v.Path)
+		}
+	}
+	return mountPoints, nil
+}
+
 func removeMount(path string) error {
 	var msg []string
 
@@ -17,11 +17,14 @@ limitations under the License.
 package cleanup
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
-	"strings"
+	"path/filepath"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/exp/slices"
 	"k8s.io/mount-utils"
 )
 
@@ -38,35 +41,122 @@ func (d *directories) Name() string {
 func (d *directories) Run() error {
 	// unmount any leftover overlays (such as in alpine)
 	mounter := mount.New("")
-	procMounts, err := mounter.List()
+	mountPoints, err := d.mountPoints(mounter)
 	if err != nil {
 		return err
 	}
 
 	// search and unmount kubelet volume mounts
-	for _, v := range procMounts {
-		if strings.Compare(v.Path, fmt.Sprintf("%s/kubelet", d.Config.dataDir)) == 0 {
-			logrus.Debugf("%v is mounted! attempting to unmount...", v.Path)
-			if err = mounter.Unmount(v.Path); err != nil {
-				logrus.Warningf("failed to unmount %v", v.Path)
+	for _, path := range mountPoints {
+		logrus.Debugf("%v is mounted! attempting to unmount...", path)
+		if err = mounter.Unmount(path); err != nil {
+			logrus.Warningf("failed to unmount %v", path)
+		}
+	}
+
+	for _, dir := range []string{d.Config.dataDir, d.Config.runDir} {
+		if d.Config.opts.KeepDataDir && dir == d.Config.dataDir {
+			logrus.Debugf("keeping k0s data-dir (%v)", dir)
+			continue
+		}
+		logrus.Debugf("deleting k0s generated directory %v", dir)
+		if err := removeAllExcept(dir, d.keep()...); err != nil {
+			return fmt.Errorf("failed to delete %v. err: %v", dir, err)
+		}
+	}
+
+	return nil
+}
+
+// DryRun lists the mounts and directories that Run would remove
+func (d *directories) DryRun() ([]string, error) {
+	var actions []string
+
+	mountPoints, err := d.mountPoints(mount.New(""))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range mountPoints {
+		actions = append(actions, fmt.Sprintf("unmount %s", path))
+	}
+
+	for _, dir := range []string{d.Config.dataDir, d.Config.runDir} {
+		if d.Config.opts.KeepDataDir && dir == d.Config.dataDir {
+			continue
+		}
+		keep := d.keep()
+		if len(keep) == 0 {
+			actions = append(actions, fmt.Sprintf("remove directory %s", dir))
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return actions, err
+		}
+		for _, entry := range entries {
+			if !slices.Contains(keep, entry.Name()) {
+				actions = append(actions, fmt.Sprintf("remove %s", filepath.Join(dir, entry.Name())))
 			}
-		} else if strings.Compare(v.Path, d.Config.dataDir) == 0 {
-			logrus.Debugf("%v is mounted! attempting to unmount...", v.Path)
-			if err = mounter.Unmount(v.Path); err != nil {
-				logrus.Warningf("failed to unmount %v", v.Path)
+		}
+	}
+
+	return actions, nil
+}
+
+// mountPoints returns the mounts of the kubelet dir and the data dir itself
+func (d *directories) mountPoints(mounter mount.Interface) ([]string, error) {
+	procMounts, err := mounter.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var mountPoints []string
+	for _, v := range procMounts {
+		switch v.Path {
+		case filepath.Join(d.Config.dataDir, "kubelet"):
+			mountPoints = append(mountPoints, v.Path)
+		case d.Config.dataDir:
+			if !d.Config.opts.KeepDataDir {
+				mountPoints = append(mountPoints, v.Path)
 			}
 		}
 	}
+	return mountPoints, nil
+}
 
-	logrus.Debugf("deleting k0s generated data-dir (%v) and run-dir (%v)", d.Config.dataDir, d.Config.runDir)
-	if err := os.RemoveAll(d.Config.dataDir); err != nil {
-		fmtError := fmt.Errorf("failed to delete %v. err: %v", d.Config.dataDir, err)
-		return fmtError
+// keep returns the names of the entries of the data-dir and run-dir that need
+// to be preserved
+func (d *directories) keep() []string {
+	if d.Config.opts.KeepContainerdState {
+		return []string{"containerd"}
 	}
-	if err := os.RemoveAll(d.Config.runDir); err != nil {
-		fmtError := fmt.Errorf("failed to delete %v. err: %v", d.Config.runDir, err)
-		return fmtError
+	return nil
+}
+
+// removeAllExcept removes dir and everything below it. If any names to keep
+// are given, dir itself and its entries with those names are left in place.
+func removeAllExcept(dir string, keep ...string) error {
+	if len(keep) == 0 {
+		return os.RemoveAll(dir)
 	}
 
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if slices.Contains(keep, entry.Name()) {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(dir, entry.Name())); err != nil {
+			return err
+		}
+	}
 	return nil
 }
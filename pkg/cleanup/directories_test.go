@@ -0,0 +1,58 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemoveAllExcept(t *testing.T) {
+	setup := func(t *testing.T) string {
+		dir := t.TempDir()
+		for _, name := range []string{"bin", "containerd", "kubelet"} {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, name, "sub"), 0755))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "file"), []byte("x"), 0644))
+		return dir
+	}
+
+	t.Run("removes everything without exceptions", func(t *testing.T) {
+		dir := setup(t)
+		require.NoError(t, removeAllExcept(dir))
+		assert.NoDirExists(t, dir)
+	})
+
+	t.Run("keeps the given entries", func(t *testing.T) {
+		dir := setup(t)
+		require.NoError(t, removeAllExcept(dir, "containerd"))
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "containerd", entries[0].Name())
+		assert.DirExists(t, filepath.Join(dir, "containerd", "sub"))
+	})
+
+	t.Run("ignores non-existent directories", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "missing")
+		assert.NoError(t, removeAllExcept(dir, "containerd"))
+	})
+}
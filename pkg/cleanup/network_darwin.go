@@ -1,5 +1,5 @@
 /*
-Copyright 2021 k0s authors
+Copyright 2023 k0s authors
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -16,38 +16,26 @@ limitations under the License.
 
 package cleanup
 
-import (
-	"fmt"
-	"runtime"
-
-	"github.com/vishvananda/netlink"
-)
-
-type bridge struct{}
+type network struct {
+	Config *Config
+}
 
 // Name returns the name of the step
-func (b *bridge) Name() string {
-	return "kube-bridge leftovers cleanup step"
+func (n *network) Name() string {
+	return "network leftovers cleanup step"
 }
 
-// Run removes found kube-bridge leftovers
-func (b *bridge) Run() error {
-	if runtime.GOOS == "windows" {
-		return nil
-	}
-
-	lnks, err := netlink.LinkList()
-	if err != nil {
-		return fmt.Errorf("failed to get link list from netlink: %v", err)
-	}
-
-	for _, l := range lnks {
-		if l.Attrs().Name == "kube-bridge" {
-			err := netlink.LinkDel(l)
-			if err != nil {
-				return err
-			}
-		}
-	}
+// Run removes found network leftovers
+func (n *network) Run() error {
 	return nil
 }
+
+// DryRun lists the network leftovers that Run would remove
+func (n *network) DryRun() ([]string, error) {
+	return nil, nil
+}
+
+// Verify lists the network leftovers that are still present on the host
+func (n *network) Verify() ([]string, error) {
+	return nil, nil
+}
@@ -0,0 +1,419 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/internal/pkg/iptablesutils"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netlink"
+	"go.uber.org/multierr"
+	"golang.org/x/sys/unix"
+)
+
+const netnsDir = "/run/netns"
+
+// The k0s supported CNI providers, as in the cluster config.
+const (
+	providerCalico     = "calico"
+	providerKubeRouter = "kuberouter"
+)
+
+// Interfaces that are created by the CNI providers, either by name or by prefix.
+var (
+	cniLinks = map[string][]string{
+		providerCalico:     {"tunl0", "vxlan.calico", "vxlan-v6.calico", "wireguard.cali", "wg-v6.cali"},
+		providerKubeRouter: {"kube-bridge", "kube-dummy-if"},
+	}
+	cniLinkPrefixes = map[string][]string{
+		providerCalico:     {"cali"},
+		providerKubeRouter: {"tun-"},
+	}
+)
+
+// ipvsLink is the dummy interface kube-proxy binds IPVS service addresses to.
+const ipvsLink = "kube-ipvs0"
+
+// iptables chains created by kube-proxy, kube-router (all KUBE-) and calico.
+var iptablesChainPrefixes = []string{"KUBE-", "cali-"}
+
+// nftables tables created by kube-proxy and calico.
+var nftablesTables = []string{"kube-proxy", "calico"}
+
+type network struct {
+	Config *Config
+
+	// usesIPVS is set once kube-proxy is known to have run in IPVS mode, so
+	// that IPVS leftovers are still found after the kube-ipvs0 link is gone.
+	usesIPVS bool
+}
+
+// networkLeftover is a single network artifact that can be removed from the host
+type networkLeftover struct {
+	description string
+	remove      func() error
+}
+
+// Name returns the name of the step
+func (n *network) Name() string {
+	return "network leftovers cleanup step"
+}
+
+// Run removes CNI network namespaces and interfaces, IPVS entries, iptables
+// rules and nftables tables
+func (n *network) Run() error {
+	leftovers, err := n.leftovers()
+	for _, l := range leftovers {
+		logrus.Debug(l.description)
+		if removeErr := l.remove(); removeErr != nil {
+			err = multierr.Append(err, fmt.Errorf("failed to %s: %w", l.description, removeErr))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("error occurred while removing network leftovers: %w", err)
+	}
+	return nil
+}
+
+// DryRun lists the network leftovers that Run would remove
+func (n *network) DryRun() ([]string, error) {
+	leftovers, err := n.leftovers()
+	return describeLeftovers(leftovers), err
+}
+
+// Verify lists the network leftovers that are still present on the host
+func (n *network) Verify() ([]string, error) {
+	return n.DryRun()
+}
+
+func describeLeftovers(leftovers []networkLeftover) []string {
+	var descriptions []string
+	for _, l := range leftovers {
+		descriptions = append(descriptions, l.description)
+	}
+	return descriptions
+}
+
+func (n *network) leftovers() ([]networkLeftover, error) {
+	var leftovers []networkLeftover
+	var errs error
+
+	// namespaces go first, so that the veth pairs inside them are gone as well
+	for _, find := range []func() ([]networkLeftover, error){
+		n.networkNamespaces,
+		n.links,
+		n.ipvsEntries,
+		n.iptablesRules,
+		n.nftablesTables,
+	} {
+		found, err := find()
+		leftovers = append(leftovers, found...)
+		errs = multierr.Append(errs, err)
+	}
+
+	return leftovers, errs
+}
+
+// providers returns the CNI providers whose leftovers are to be removed. The
+// CNI config files are the most reliable source, as workers usually don't
+// have a k0s config file. If none are found, the node config is consulted.
+func (n *network) providers() []string {
+	var providers []string
+	if file.Exists("/etc/cni/net.d/10-calico.conflist") {
+		providers = append(providers, providerCalico)
+	}
+	if file.Exists("/etc/cni/net.d/10-kuberouter.conflist") {
+		providers = append(providers, providerKubeRouter)
+	}
+	if len(providers) > 0 {
+		return providers
+	}
+
+	loadingRules := config.ClientConfigLoadingRules{Nodeconfig: true, K0sVars: n.Config.k0sVars}
+	cfg, err := loadingRules.Load()
+	if err != nil || cfg.Spec == nil || cfg.Spec.Network == nil {
+		logrus.Debugf("failed to determine CNI provider, cleaning up after all of them: %v", err)
+		return []string{providerCalico, providerKubeRouter}
+	}
+	switch cfg.Spec.Network.Provider {
+	case providerCalico, providerKubeRouter:
+		return []string{cfg.Spec.Network.Provider}
+	}
+	return nil
+}
+
+// networkNamespaces finds the network namespaces created for pods
+func (n *network) networkNamespaces() ([]networkLeftover, error) {
+	entries, err := os.ReadDir(netnsDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var leftovers []networkLeftover
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), "cni-") {
+			continue
+		}
+		path := filepath.Join(netnsDir, entry.Name())
+		leftovers = append(leftovers, networkLeftover{
+			description: fmt.Sprintf("remove network namespace %s", path),
+			remove: func() error {
+				if err := unix.Unmount(path, unix.MNT_DETACH); err != nil && !errors.Is(err, unix.EINVAL) {
+					return err
+				}
+				return os.Remove(path)
+			},
+		})
+	}
+	return leftovers, nil
+}
+
+// links finds the network interfaces created by the CNI providers and kube-proxy
+func (n *network) links() ([]networkLeftover, error) {
+	lnks, err := netlink.LinkList()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get link list from netlink: %w", err)
+	}
+
+	names, prefixes := []string{ipvsLink}, []string{}
+	for _, provider := range n.providers() {
+		names = append(names, cniLinks[provider]...)
+		prefixes = append(prefixes, cniLinkPrefixes[provider]...)
+	}
+
+	var leftovers []networkLeftover
+	for _, l := range lnks {
+		l, name := l, l.Attrs().Name
+		if !matchesLink(name, names, prefixes) {
+			continue
+		}
+		leftovers = append(leftovers, networkLeftover{
+			description: fmt.Sprintf("remove network interface %s", name),
+			remove:      func() error { return netlink.LinkDel(l) },
+		})
+	}
+	return leftovers, nil
+}
+
+func matchesLink(name string, names, prefixes []string) bool {
+	for _, n := range names {
+		if name == n {
+			return true
+		}
+	}
+	for _, p := range prefixes {
+		if strings.HasPrefix(name, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipvsEntries finds the IPVS virtual services if kube-proxy ran in IPVS mode.
+// There's no k0s managed IPVS tooling, so this relies on ipvsadm on the host.
+func (n *network) ipvsEntries() ([]networkLeftover, error) {
+	if !n.usesIPVS {
+		usesIPVS, err := n.detectIPVS()
+		if err != nil {
+			return nil, err
+		}
+		if !usesIPVS {
+			return nil, nil
+		}
+		n.usesIPVS = true
+	}
+
+	ipvsadm, err := exec.LookPath("ipvsadm")
+	if err != nil {
+		logrus.Debugf("ipvsadm not found, not removing IPVS entries: %v", err)
+		return nil, nil
+	}
+
+	out, err := exec.Command(ipvsadm, "--save", "-n").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list IPVS entries: %w", err)
+	}
+	var services int
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.HasPrefix(line, "-A ") {
+			services++
+		}
+	}
+	if services == 0 {
+		return nil, nil
+	}
+
+	return []networkLeftover{{
+		description: fmt.Sprintf("remove %d IPVS virtual services", services),
+		remove:      func() error { return exec.Command(ipvsadm, "--clear").Run() },
+	}}, nil
+}
+
+// detectIPVS checks whether kube-proxy ran in IPVS mode, either by its dummy
+// interface or by the node config.
+func (n *network) detectIPVS() (bool, error) {
+	_, err := netlink.LinkByName(ipvsLink)
+	if err == nil {
+		return true, nil
+	}
+	var notFound netlink.LinkNotFoundError
+	if !errors.As(err, &notFound) {
+		return false, fmt.Errorf("failed to get %s link from netlink: %w", ipvsLink, err)
+	}
+
+	loadingRules := config.ClientConfigLoadingRules{Nodeconfig: true, K0sVars: n.Config.k0sVars}
+	cfg, err := loadingRules.Load()
+	if err != nil || cfg.Spec == nil || cfg.Spec.Network == nil || cfg.Spec.Network.KubeProxy == nil {
+		return false, nil
+	}
+	kubeProxy := cfg.Spec.Network.KubeProxy
+	return !kubeProxy.Disabled && kubeProxy.Mode == v1beta1.ModeIPVS, nil
+}
+
+// iptablesRules finds the iptables rules and chains created by kube-proxy and
+// the CNI providers, using the iptables binaries shipped with k0s.
+func (n *network) iptablesRules() ([]networkLeftover, error) {
+	var leftovers []networkLeftover
+	var errs error
+
+	for _, mode := range []string{iptablesutils.ModeNFT, iptablesutils.ModeLegacy} {
+		xtables := filepath.Join(n.Config.k0sVars.BinDir, fmt.Sprintf("xtables-%s-multi", mode))
+		if !file.Exists(xtables) {
+			continue
+		}
+		for _, family := range []string{"iptables", "ip6tables"} {
+			xtables, family, mode := xtables, family, mode
+			saved, err := exec.Command(xtables, family+"-save").Output()
+			if err != nil {
+				errs = multierr.Append(errs, fmt.Errorf("%s-%s: %w", family, mode, err))
+				continue
+			}
+			filtered, removed := filterIPTablesRules(saved, iptablesChainPrefixes)
+			if removed == 0 {
+				continue
+			}
+			leftovers = append(leftovers, networkLeftover{
+				description: fmt.Sprintf("remove %d %s-%s rules and chains", removed, family, mode),
+				remove: func() error {
+					cmd := exec.Command(xtables, family+"-restore")
+					cmd.Stdin = bytes.NewReader(filtered)
+					if out, err := cmd.CombinedOutput(); err != nil {
+						return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+					}
+					return nil
+				},
+			})
+		}
+	}
+
+	return leftovers, errs
+}
+
+// filterIPTablesRules removes the chains with any of the given prefixes from
+// iptables-save output, along with all rules in or jumping to those chains.
+// It returns the filtered output and the number of lines that were removed.
+func filterIPTablesRules(saved []byte, chainPrefixes []string) ([]byte, uint) {
+	isRemovedChain := func(chain string) bool {
+		for _, prefix := range chainPrefixes {
+			if strings.HasPrefix(chain, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+
+	var filtered bytes.Buffer
+	var removed uint
+	scanner := bufio.NewScanner(bytes.NewReader(saved))
+	for scanner.Scan() {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+
+		remove := false
+		switch {
+		case strings.HasPrefix(line, ":"):
+			remove = isRemovedChain(strings.TrimPrefix(fields[0], ":"))
+		case strings.HasPrefix(line, "-A "):
+			for i, field := range fields {
+				if (i == 1 || (i > 0 && (fields[i-1] == "-j" || fields[i-1] == "-g"))) && isRemovedChain(field) {
+					remove = true
+					break
+				}
+			}
+		}
+
+		if remove {
+			removed++
+			continue
+		}
+		filtered.WriteString(line)
+		filtered.WriteByte('\n')
+	}
+
+	return filtered.Bytes(), removed
+}
+
+// nftablesTables finds the nftables tables created by kube-proxy and calico.
+// There's no k0s managed nftables tooling, so this relies on nft on the host.
+func (n *network) nftablesTables() ([]networkLeftover, error) {
+	nft, err := exec.LookPath("nft")
+	if err != nil {
+		logrus.Debugf("nft not found, not removing nftables tables: %v", err)
+		return nil, nil
+	}
+
+	out, err := exec.Command(nft, "list", "tables").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nftables tables: %w", err)
+	}
+
+	var leftovers []networkLeftover
+	for _, line := range strings.Split(string(out), "\n") {
+		// table <family> <name>
+		fields := strings.Fields(line)
+		if len(fields) != 3 || fields[0] != "table" {
+			continue
+		}
+		family, name := fields[1], fields[2]
+		for _, table := range nftablesTables {
+			if name != table {
+				continue
+			}
+			leftovers = append(leftovers, networkLeftover{
+				description: fmt.Sprintf("remove nftables table %s %s", family, name),
+				remove:      func() error { return exec.Command(nft, "delete", "table", family, name).Run() },
+			})
+		}
+	}
+	return leftovers, nil
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterIPTablesRules(t *testing.T) {
+	saved := `# Generated by iptables-save
+*filter
+:INPUT ACCEPT [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT ACCEPT [0:0]
+:DOCKER - [0:0]
+:KUBE-FORWARD - [0:0]
+:cali-INPUT - [0:0]
+-A INPUT -m comment --comment "cali:Cz_u1IQiXIMmKD4c" -j cali-INPUT
+-A FORWARD -m comment --comment "kubernetes forwarding rules" -j KUBE-FORWARD
+-A FORWARD -o docker0 -j DOCKER
+-A KUBE-FORWARD -m conntrack --ctstate INVALID -j DROP
+-A cali-INPUT -j ACCEPT
+-A OUTPUT -j ACCEPT
+COMMIT
+`
+
+	filtered, removed := filterIPTablesRules([]byte(saved), iptablesChainPrefixes)
+
+	assert.Equal(t, uint(6), removed)
+	assert.Equal(t, `# Generated by iptables-save
+*filter
+:INPUT ACCEPT [0:0]
+:FORWARD DROP [0:0]
+:OUTPUT ACCEPT [0:0]
+:DOCKER - [0:0]
+-A FORWARD -o docker0 -j DOCKER
+-A OUTPUT -j ACCEPT
+COMMIT
+`, string(filtered))
+}
+
+func TestMatchesLink(t *testing.T) {
+	names, prefixes := []string{"kube-ipvs0", "tunl0"}, []string{"cali"}
+
+	assert.True(t, matchesLink("kube-ipvs0", names, prefixes))
+	assert.True(t, matchesLink("cali1234abcd", names, prefixes))
+	assert.False(t, matchesLink("eth0", names, prefixes))
+	assert.False(t, matchesLink("tunl01", names, prefixes))
+}
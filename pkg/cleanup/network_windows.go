@@ -0,0 +1,41 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cleanup
+
+type network struct {
+	Config *Config
+}
+
+// Name returns the name of the step
+func (n *network) Name() string {
+	return "network leftovers cleanup step"
+}
+
+// Run removes found network leftovers
+func (n *network) Run() error {
+	return nil
+}
+
+// DryRun lists the network leftovers that Run would remove
+func (n *network) DryRun() ([]string, error) {
+	return nil, nil
+}
+
+// Verify lists the network leftovers that are still present on the host
+func (n *network) Verify() ([]string, error) {
+	return nil, nil
+}
@@ -24,6 +24,8 @@ import (
 	"strings"
 
 	"github.com/k0sproject/k0s/pkg/install"
+
+	"github.com/kardianos/service"
 )
 
 type services struct {
@@ -50,6 +52,23 @@ func (s *services) Run() error {
 	return nil
 }
 
+// DryRun lists the k0s services that Run would uninstall
+func (s *services) DryRun() ([]string, error) {
+	var actions []string
+
+	for _, role := range []string{"controller", "worker"} {
+		svc, err := service.New(&install.Program{}, install.GetServiceConfig(role))
+		if err != nil {
+			return actions, err
+		}
+		if _, err := svc.Status(); errors.Is(err, service.ErrNotInstalled) {
+			continue
+		}
+		actions = append(actions, fmt.Sprintf("uninstall service %s", svc.String()))
+	}
+	return actions, nil
+}
+
 func isExitCode(err error, exitcode int) bool {
 	var e *exec.ExitError
 	return errors.As(err, &e) && e.ProcessState.ExitCode() == exitcode
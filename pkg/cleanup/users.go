@@ -17,6 +17,9 @@ limitations under the License.
 package cleanup
 
 import (
+	"fmt"
+	"os/user"
+
 	"github.com/k0sproject/k0s/pkg/config"
 	"github.com/k0sproject/k0s/pkg/install"
 	"github.com/sirupsen/logrus"
@@ -33,6 +36,11 @@ func (u *users) Name() string {
 
 // Run removes all controller users that are present on the host
 func (u *users) Run() error {
+	if u.Config.opts.KeepDataDir {
+		// the users own files in the data-dir, so they have to stay as well
+		logrus.Debug("keeping controller users along with the data-dir")
+		return nil
+	}
 	loadingRules := config.ClientConfigLoadingRules{Nodeconfig: true, K0sVars: u.Config.k0sVars}
 	cfg, err := loadingRules.Load()
 	if err != nil {
@@ -44,3 +52,24 @@ func (u *users) Run() error {
 	}
 	return nil
 }
+
+// DryRun lists the controller users that Run would delete
+func (u *users) DryRun() ([]string, error) {
+	if u.Config.opts.KeepDataDir {
+		return nil, nil
+	}
+
+	loadingRules := config.ClientConfigLoadingRules{Nodeconfig: true, K0sVars: u.Config.k0sVars}
+	cfg, err := loadingRules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cluster setup: %w", err)
+	}
+
+	var actions []string
+	for _, name := range install.GetControllerUsers(cfg) {
+		if _, err := user.Lookup(name); err == nil {
+			actions = append(actions, fmt.Sprintf("delete user %s", name))
+		}
+	}
+	return actions, nil
+}
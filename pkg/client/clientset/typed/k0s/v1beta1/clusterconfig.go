@@ -39,6 +39,7 @@ type ClusterConfigsGetter interface {
 type ClusterConfigInterface interface {
 	Create(ctx context.Context, clusterConfig *v1beta1.ClusterConfig, opts v1.CreateOptions) (*v1beta1.ClusterConfig, error)
 	Update(ctx context.Context, clusterConfig *v1beta1.ClusterConfig, opts v1.UpdateOptions) (*v1beta1.ClusterConfig, error)
+	UpdateStatus(ctx context.Context, clusterConfig *v1beta1.ClusterConfig, opts v1.UpdateOptions) (*v1beta1.ClusterConfig, error)
 	Delete(ctx context.Context, name string, opts v1.DeleteOptions) error
 	Get(ctx context.Context, name string, opts v1.GetOptions) (*v1beta1.ClusterConfig, error)
 	List(ctx context.Context, opts v1.ListOptions) (*v1beta1.ClusterConfigList, error)
@@ -132,6 +133,22 @@ func (c *clusterConfigs) Update(ctx context.Context, clusterConfig *v1beta1.Clus
 	return
 }
 
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *clusterConfigs) UpdateStatus(ctx context.Context, clusterConfig *v1beta1.ClusterConfig, opts v1.UpdateOptions) (result *v1beta1.ClusterConfig, err error) {
+	result = &v1beta1.ClusterConfig{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("clusterconfigs").
+		Name(clusterConfig.Name).
+		SubResource("status").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Body(clusterConfig).
+		Do(ctx).
+		Into(result)
+	return
+}
+
 // Delete takes name of the clusterConfig and deletes it. Returns an error if one occurs.
 func (c *clusterConfigs) Delete(ctx context.Context, name string, opts v1.DeleteOptions) error {
 	return c.client.Delete().
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/sirupsen/logrus"
+)
+
+// AdminKubeconfigRenewer re-issues the admin client certificate and rewrites
+// the admin kubeconfig before the certificate expires.
+type AdminKubeconfigRenewer struct {
+	log         *logrus.Entry
+	certManager certificate.Manager
+	apiPort     int
+
+	stop func()
+}
+
+var _ manager.Component = (*AdminKubeconfigRenewer)(nil)
+
+// NewAdminKubeconfigRenewer creates a new admin kubeconfig renewer for the
+// API server listening on the given port.
+func NewAdminKubeconfigRenewer(certManager certificate.Manager, apiPort int) *AdminKubeconfigRenewer {
+	return &AdminKubeconfigRenewer{
+		log:         logrus.WithField("component", "admin-kubeconfig-renewer"),
+		certManager: certManager,
+		apiPort:     apiPort,
+	}
+}
+
+func (r *AdminKubeconfigRenewer) Init(context.Context) error {
+	return nil
+}
+
+func (r *AdminKubeconfigRenewer) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			if !r.certManager.AdminKubeconfigNeedsRenewal(time.Now()) {
+				return
+			}
+			if err := r.certManager.WriteAdminKubeconfig(r.apiPort); err != nil {
+				r.log.WithError(err).Error("Failed to renew admin kubeconfig")
+				return
+			}
+			r.log.Info("Renewed admin kubeconfig")
+		}, adminKubeconfigCheckInterval(r.certManager.AdminKubeconfigValidity))
+	}()
+
+	r.stop = func() { cancel(); <-done }
+	return nil
+}
+
+func (r *AdminKubeconfigRenewer) Stop() error {
+	if r.stop != nil {
+		r.stop()
+	}
+	return nil
+}
+
+// adminKubeconfigCheckInterval returns the interval in which the admin client
+// certificate is checked for renewal. It's checked more often than the other
+// certificates if it's short-lived, so that it's renewed well before it
+// expires.
+func adminKubeconfigCheckInterval(validity time.Duration) time.Duration {
+	if validity > 0 && validity/10 < certificateRenewalInterval {
+		return validity / 10
+	}
+	return certificateRenewalInterval
+}
@@ -0,0 +1,322 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
+	"github.com/k0sproject/k0s/pkg/certificate"
+	apclient "github.com/k0sproject/k0s/pkg/client/clientset"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/constant"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// apiEndpointCheckInterval is the interval in which API endpoints are checked.
+	apiEndpointCheckInterval = 30 * time.Second
+	// apiEndpointCheckTimeout is the time after which an API endpoint is
+	// considered unreachable.
+	apiEndpointCheckTimeout = 5 * time.Second
+)
+
+// APIEndpointHealthChecker continuously health-checks the cluster's API
+// endpoints, i.e. the external address and the SANs, and publishes their
+// reachability in the status of this controller's ControlNode. Changes in
+// reachability are reported as events, so that stale load balancer members
+// become visible.
+type APIEndpointHealthChecker struct {
+	log *logrus.Entry
+
+	certRootDir       string
+	endpoints         []string
+	kubeClientFactory kubeutil.ClientFactoryInterface
+	apClient          apclient.Interface
+
+	mu       sync.Mutex
+	statuses map[string]*apv1beta2.APIEndpointStatus
+
+	stop func()
+}
+
+var _ manager.Component = (*APIEndpointHealthChecker)(nil)
+
+// NewAPIEndpointHealthChecker creates a new health checker for the API
+// endpoints of the given node config.
+func NewAPIEndpointHealthChecker(nodeConfig *v1beta1.ClusterConfig, k0sVars constant.CfgVars, kubeClientFactory kubeutil.ClientFactoryInterface) *APIEndpointHealthChecker {
+	return &APIEndpointHealthChecker{
+		log:               logrus.WithField("component", "api-endpoint-health"),
+		certRootDir:       k0sVars.CertRootDir,
+		endpoints:         apiEndpoints(nodeConfig.Spec.API),
+		kubeClientFactory: kubeClientFactory,
+		statuses:          make(map[string]*apv1beta2.APIEndpointStatus),
+	}
+}
+
+// apiEndpoints returns the host:port pairs of the external address and the
+// SANs of the given API spec. Loopback addresses are skipped.
+func apiEndpoints(api *v1beta1.APISpec) []string {
+	var endpoints []string
+	seen := make(map[string]bool)
+	port := strconv.Itoa(api.Port)
+	for _, address := range append([]string{api.ExternalAddress}, api.SANs...) {
+		if address == "" || seen[address] {
+			continue
+		}
+		if ip := net.ParseIP(address); address == "localhost" || (ip != nil && ip.IsLoopback()) {
+			continue
+		}
+		seen[address] = true
+		endpoints = append(endpoints, net.JoinHostPort(address, port))
+	}
+	return endpoints
+}
+
+func (c *APIEndpointHealthChecker) Init(context.Context) error {
+	return nil
+}
+
+func (c *APIEndpointHealthChecker) Start(context.Context) error {
+	if len(c.endpoints) == 0 {
+		c.log.Info("No API endpoints to check")
+		return nil
+	}
+
+	httpClient, err := c.newHTTPClient()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			c.checkEndpoints(ctx, httpClient)
+			if err := c.publish(ctx); err != nil {
+				c.log.WithError(err).Warn("Failed to publish API endpoint health")
+			}
+		}, apiEndpointCheckInterval)
+	}()
+
+	c.stop = func() { cancel(); <-done }
+	return nil
+}
+
+func (c *APIEndpointHealthChecker) Stop() error {
+	if c.stop != nil {
+		c.stop()
+	}
+	return nil
+}
+
+// newHTTPClient returns a client that trusts the cluster's CA, so that the
+// endpoints' certificates are verified.
+func (c *APIEndpointHealthChecker) newHTTPClient() (*http.Client, error) {
+	ca, err := certificate.ReadCABundle(c.certRootDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster CA: %w", err)
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificates found in cluster CA")
+	}
+
+	return &http.Client{
+		Timeout: apiEndpointCheckTimeout,
+		Transport: &http.Transport{
+			// Load balancers are checked directly, not via proxies.
+			Proxy:           nil,
+			TLSClientConfig: &tls.Config{RootCAs: rootCAs},
+		},
+	}, nil
+}
+
+// checkEndpoints checks all endpoints concurrently and updates their status.
+func (c *APIEndpointHealthChecker) checkEndpoints(ctx context.Context, httpClient *http.Client) {
+	var wg sync.WaitGroup
+	for _, endpoint := range c.endpoints {
+		wg.Add(1)
+		go func(endpoint string) {
+			defer wg.Done()
+			c.updateStatus(endpoint, checkAPIEndpoint(ctx, httpClient, endpoint), metav1.Now())
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+// checkAPIEndpoint checks if the API server behind the given endpoint is ready.
+func checkAPIEndpoint(ctx context.Context, httpClient *http.Client, endpoint string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "https://"+endpoint+"/readyz", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API server not ready: %s", resp.Status)
+	}
+	return nil
+}
+
+// updateStatus records the result of a health check. Changes in reachability
+// are logged.
+func (c *APIEndpointHealthChecker) updateStatus(endpoint string, checkErr error, now metav1.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	status, ok := c.statuses[endpoint]
+	reachable := checkErr == nil
+	if !ok || status.Reachable != reachable {
+		if ok || !reachable {
+			if reachable {
+				c.log.Infof("API endpoint %s is reachable again", endpoint)
+			} else {
+				c.log.WithError(checkErr).Warnf("API endpoint %s is unreachable", endpoint)
+			}
+		}
+		status = &apv1beta2.APIEndpointStatus{Address: endpoint, LastTransitionTime: now}
+		c.statuses[endpoint] = status
+	}
+
+	status.Reachable = reachable
+	status.LastCheckTime = now
+	status.Message = ""
+	if checkErr != nil {
+		status.Message = checkErr.Error()
+	}
+}
+
+// endpointStatuses returns a copy of the current status of all endpoints, in
+// the order in which they're configured.
+func (c *APIEndpointHealthChecker) endpointStatuses() []apv1beta2.APIEndpointStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var statuses []apv1beta2.APIEndpointStatus
+	for _, endpoint := range c.endpoints {
+		if status, ok := c.statuses[endpoint]; ok {
+			statuses = append(statuses, *status.DeepCopy())
+		}
+	}
+	return statuses
+}
+
+// publish writes the endpoint statuses to this controller's ControlNode, and
+// emits events for the endpoints whose reachability changed.
+func (c *APIEndpointHealthChecker) publish(ctx context.Context) error {
+	nodeName, err := apcomm.FindEffectiveHostname()
+	if err != nil {
+		return err
+	}
+	client, err := c.kubeClientFactory.GetClient()
+	if err != nil {
+		return err
+	}
+	if c.apClient == nil {
+		// The REST config is available once the client has been created.
+		if c.apClient, err = apclient.NewForConfig(c.kubeClientFactory.GetRESTConfig()); err != nil {
+			return err
+		}
+	}
+	return publishAPIEndpointHealth(ctx, client, c.apClient, nodeName, c.endpointStatuses())
+}
+
+func publishAPIEndpointHealth(ctx context.Context, client kubernetes.Interface, apClient apclient.Interface, nodeName string, statuses []apv1beta2.APIEndpointStatus) error {
+	controlNodes := apClient.AutopilotV1beta2().ControlNodes()
+	node, err := controlNodes.Get(ctx, nodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// The ControlNode is created by autopilot, which might be disabled.
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	previous := make(map[string]apv1beta2.APIEndpointStatus)
+	for _, status := range node.Status.APIEndpoints {
+		previous[status.Address] = status
+	}
+
+	for _, status := range statuses {
+		if prev, ok := previous[status.Address]; ok && prev.Reachable == status.Reachable {
+			continue
+		} else if !ok && status.Reachable {
+			continue
+		}
+		if err := createAPIEndpointEvent(ctx, client, node, status); err != nil {
+			return err
+		}
+	}
+
+	node.Status.APIEndpoints = statuses
+	_, err = controlNodes.UpdateStatus(ctx, node, metav1.UpdateOptions{})
+	return err
+}
+
+func createAPIEndpointEvent(ctx context.Context, client kubernetes.Interface, node *apv1beta2.ControlNode, status apv1beta2.APIEndpointStatus) error {
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "k0s.",
+		},
+		EventTime:      metav1.NowMicro(),
+		FirstTimestamp: status.LastCheckTime,
+		LastTimestamp:  status.LastCheckTime,
+		InvolvedObject: corev1.ObjectReference{
+			Kind:       "ControlNode",
+			Name:       node.Name,
+			UID:        node.UID,
+			APIVersion: apv1beta2.SchemeGroupVersion.String(),
+		},
+		Action:              "APIEndpointHealthCheck",
+		ReportingController: "k0s-controller",
+		ReportingInstance:   node.Name,
+	}
+	if status.Reachable {
+		event.Type = corev1.EventTypeNormal
+		event.Reason = "APIEndpointReachable"
+		event.Message = fmt.Sprintf("API endpoint %s is reachable", status.Address)
+	} else {
+		event.Type = corev1.EventTypeWarning
+		event.Reason = "APIEndpointUnreachable"
+		event.Message = fmt.Sprintf("API endpoint %s is unreachable: %s", status.Address, status.Message)
+	}
+
+	_, err := client.CoreV1().Events(metav1.NamespaceDefault).Create(ctx, event, metav1.CreateOptions{})
+	return err
+}
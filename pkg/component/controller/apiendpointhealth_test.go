@@ -0,0 +1,125 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	apfake "github.com/k0sproject/k0s/pkg/client/clientset/fake"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestAPIEndpoints(t *testing.T) {
+	endpoints := apiEndpoints(&v1beta1.APISpec{
+		Port:            6443,
+		ExternalAddress: "k0s.example.com",
+		SANs:            []string{"10.0.0.1", "127.0.0.1", "::1", "localhost", "fd00::1", "k0s.example.com"},
+	})
+	assert.Equal(t, []string{"k0s.example.com:6443", "10.0.0.1:6443", "[fd00::1]:6443"}, endpoints)
+}
+
+func TestCheckAPIEndpoint(t *testing.T) {
+	ready := true
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/readyz" && ready {
+			_, _ = w.Write([]byte("ok"))
+			return
+		}
+		http.Error(w, "not ready", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	endpoint := strings.TrimPrefix(server.URL, "https://")
+	assert.NoError(t, checkAPIEndpoint(context.TODO(), server.Client(), endpoint))
+
+	ready = false
+	assert.ErrorContains(t, checkAPIEndpoint(context.TODO(), server.Client(), endpoint), "API server not ready: 500 Internal Server Error")
+}
+
+func TestAPIEndpointHealth(t *testing.T) {
+	c := &APIEndpointHealthChecker{
+		log:       logrus.WithField("test", t.Name()),
+		endpoints: []string{"10.0.0.1:6443", "10.0.0.2:6443"},
+		statuses:  make(map[string]*apv1beta2.APIEndpointStatus),
+	}
+	first := metav1.NewTime(time.Unix(1000, 0))
+	second := metav1.NewTime(time.Unix(2000, 0))
+
+	c.updateStatus("10.0.0.1:6443", nil, first)
+	c.updateStatus("10.0.0.2:6443", nil, first)
+	c.updateStatus("10.0.0.1:6443", nil, second)
+	c.updateStatus("10.0.0.2:6443", errors.New("connection refused"), second)
+
+	statuses := c.endpointStatuses()
+	assert.Equal(t, []apv1beta2.APIEndpointStatus{{
+		Address:            "10.0.0.1:6443",
+		Reachable:          true,
+		LastCheckTime:      second,
+		LastTransitionTime: first,
+	}, {
+		Address:            "10.0.0.2:6443",
+		Reachable:          false,
+		Message:            "connection refused",
+		LastCheckTime:      second,
+		LastTransitionTime: second,
+	}}, statuses)
+
+	ctx := context.TODO()
+	client := fake.NewSimpleClientset()
+	apClient := apfake.NewSimpleClientset(&apv1beta2.ControlNode{
+		ObjectMeta: metav1.ObjectMeta{Name: "controller"},
+		Status: apv1beta2.ControlNodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}},
+		},
+	})
+
+	require.NoError(t, publishAPIEndpointHealth(ctx, client, apClient, "controller", statuses))
+	node, err := apClient.AutopilotV1beta2().ControlNodes().Get(ctx, "controller", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, node.Status.Addresses, 1, "other status fields should be preserved")
+	assert.Equal(t, statuses, node.Status.APIEndpoints)
+
+	events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	if assert.Len(t, events.Items, 1, "only the unreachable endpoint should be reported") {
+		assert.Equal(t, "APIEndpointUnreachable", events.Items[0].Reason)
+		assert.Equal(t, "API endpoint 10.0.0.2:6443 is unreachable: connection refused", events.Items[0].Message)
+	}
+
+	// Publishing the same state again doesn't emit any events.
+	require.NoError(t, publishAPIEndpointHealth(ctx, client, apClient, "controller", statuses))
+	events, err = client.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, events.Items, 1)
+
+	// A missing ControlNode is not an error.
+	assert.NoError(t, publishAPIEndpointHealth(ctx, client, apClient, "other", statuses))
+}
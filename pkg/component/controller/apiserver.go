@@ -20,25 +20,33 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/file"
 	"github.com/k0sproject/k0s/internal/pkg/stringmap"
 	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
 	"github.com/k0sproject/k0s/internal/pkg/users"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/assets"
+	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/component/manager"
 	"github.com/k0sproject/k0s/pkg/constant"
 	"github.com/k0sproject/k0s/pkg/supervisor"
+
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
 // APIServer implement the component interface to run kube api
@@ -95,13 +103,13 @@ func (a *APIServer) Init(_ context.Context) error {
 }
 
 // Run runs kube api
-func (a *APIServer) Start(_ context.Context) error {
+func (a *APIServer) Start(ctx context.Context) error {
 	logrus.Info("Starting kube-apiserver")
 	args := stringmap.StringMap{
 		"advertise-address":                a.ClusterConfig.Spec.API.Address,
 		"secure-port":                      fmt.Sprintf("%d", a.ClusterConfig.Spec.API.Port),
 		"authorization-mode":               "Node,RBAC",
-		"client-ca-file":                   path.Join(a.K0sVars.CertRootDir, "ca.crt"),
+		"client-ca-file":                   certificate.CABundlePath(a.K0sVars.CertRootDir),
 		"enable-bootstrap-token-auth":      "true",
 		"kubelet-client-certificate":       path.Join(a.K0sVars.CertRootDir, "apiserver-kubelet-client.crt"),
 		"kubelet-client-key":               path.Join(a.K0sVars.CertRootDir, "apiserver-kubelet-client.key"),
@@ -120,7 +128,7 @@ func (a *APIServer) Start(_ context.Context) error {
 		"service-account-jwks-uri":         "https://kubernetes.default.svc/openid/v1/jwks",
 		"profiling":                        "false",
 		"v":                                a.LogLevel,
-		"kubelet-certificate-authority":    path.Join(a.K0sVars.CertRootDir, "ca.crt"),
+		"kubelet-certificate-authority":    certificate.CABundlePath(a.K0sVars.CertRootDir),
 		"enable-admission-plugins":         "NodeRestriction",
 	}
 
@@ -136,6 +144,28 @@ func (a *APIServer) Start(_ context.Context) error {
 	}
 
 	args["api-audiences"] = strings.Join(apiAudiences, ",")
+	args = a.ClusterConfig.Spec.API.OIDC.BuildArgs(args)
+	if audit := a.ClusterConfig.Spec.API.Audit; audit != nil {
+		if err := a.prepareAudit(audit); err != nil {
+			return err
+		}
+		args = audit.BuildArgs(args, a.auditPolicyPath())
+	}
+	if admission := a.ClusterConfig.Spec.API.Admission; admission != nil {
+		if err := a.writeAdmissionConfig(admission); err != nil {
+			return err
+		}
+		args = admission.BuildArgs(args, a.admissionConfigPath())
+	}
+	if encryption := a.ClusterConfig.Spec.API.Encryption; encryption != nil {
+		if err := a.writeEncryptionConfig(encryption); err != nil {
+			return err
+		}
+		if err := waitForKMSPlugin(ctx, encryption.KMS.Socket, kmsPluginTimeout); err != nil {
+			return err
+		}
+		args = encryption.BuildArgs(args, a.encryptionConfigPath())
+	}
 
 	for name, value := range a.ClusterConfig.Spec.API.ExtraArgs {
 		if _, ok := args[name]; ok {
@@ -198,6 +228,97 @@ func (a *APIServer) writeKonnectivityConfig() error {
 	return nil
 }
 
+func (a *APIServer) auditPolicyPath() string {
+	return filepath.Join(a.K0sVars.DataDir, "audit-policy.yaml")
+}
+
+// prepareAudit writes the inline audit policy, if any, and creates the
+// directory of the audit log if it doesn't exist yet. Existing directories are
+// left as is, they need to be writable by kube-apiserver.
+func (a *APIServer) prepareAudit(audit *v1beta1.Audit) error {
+	if audit.Policy != "" {
+		if err := file.WriteContentAtomically(a.auditPolicyPath(), []byte(audit.Policy), constant.CertMode); err != nil {
+			return fmt.Errorf("failed to write audit policy: %w", err)
+		}
+	}
+
+	if logPath := audit.LogPath(); logPath != "" && logPath != "-" {
+		logDir := filepath.Dir(logPath)
+		if _, err := os.Stat(logDir); errors.Is(err, os.ErrNotExist) {
+			if err := dir.Init(logDir, constant.AuditLogDirMode); err != nil {
+				return fmt.Errorf("failed to create audit log directory: %w", err)
+			}
+			if err := os.Chown(logDir, a.uid, a.gid); err != nil && os.Geteuid() == 0 {
+				return fmt.Errorf("failed to chown audit log directory: %w", err)
+			}
+		} else if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (a *APIServer) admissionConfigPath() string {
+	return filepath.Join(a.K0sVars.DataDir, "admission-config.yaml")
+}
+
+// writeAdmissionConfig writes the AdmissionConfiguration for the configured
+// admission plugins, if any.
+func (a *APIServer) writeAdmissionConfig(admission *v1beta1.Admission) error {
+	config, err := admission.AdmissionConfiguration()
+	if err != nil || config == nil {
+		return err
+	}
+	if err := file.WriteContentAtomically(a.admissionConfigPath(), config, constant.CertMode); err != nil {
+		return fmt.Errorf("failed to write admission configuration: %w", err)
+	}
+	return nil
+}
+
+func (a *APIServer) encryptionConfigPath() string {
+	return filepath.Join(a.K0sVars.DataDir, "encryption-config.yaml")
+}
+
+// writeEncryptionConfig writes the EncryptionConfiguration for kube-apiserver.
+func (a *APIServer) writeEncryptionConfig(encryption *v1beta1.Encryption) error {
+	config, err := encryption.EncryptionConfiguration()
+	if err != nil {
+		return err
+	}
+	if err := file.WriteContentAtomically(a.encryptionConfigPath(), config, constant.CertSecureMode); err != nil {
+		return fmt.Errorf("failed to write encryption configuration: %w", err)
+	}
+	if err := os.Chown(a.encryptionConfigPath(), a.uid, a.gid); err != nil && os.Geteuid() == 0 {
+		return fmt.Errorf("failed to chown encryption configuration: %w", err)
+	}
+	return nil
+}
+
+// kmsPluginTimeout is the time to wait for the KMS plugin to accept
+// connections before kube-apiserver gets started.
+const kmsPluginTimeout = 2 * time.Minute
+
+// waitForKMSPlugin waits until the KMS plugin accepts connections on the given
+// unix socket. kube-apiserver fails to start if the plugin isn't reachable.
+func waitForKMSPlugin(ctx context.Context, socket string, timeout time.Duration) error {
+	var lastErr error
+	err := wait.PollUntilContextTimeout(ctx, 1*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		var dialer net.Dialer
+		conn, err := dialer.DialContext(ctx, "unix", socket)
+		if err != nil {
+			lastErr = err
+			logrus.WithError(err).Debug("KMS plugin not reachable yet")
+			return false, nil
+		}
+		return true, conn.Close()
+	})
+	if err != nil && lastErr != nil {
+		return fmt.Errorf("KMS plugin not reachable at %s: %w", socket, lastErr)
+	}
+	return err
+}
+
 // Stop stops APIServer
 func (a *APIServer) Stop() error {
 	return a.supervisor.Stop()
@@ -205,6 +326,16 @@ func (a *APIServer) Stop() error {
 
 // Health-check interface
 func (a *APIServer) Ready() error {
+	return a.probe(context.TODO(), "readyz")
+}
+
+// Healthy implements [prober.Healthz].
+func (a *APIServer) Healthy(ctx context.Context) error {
+	return a.probe(ctx, "livez")
+}
+
+// probe queries the given health endpoint of the API server.
+func (a *APIServer) probe(ctx context.Context, endpoint string) error {
 	// Load client cert so the api can authenitcate the request.
 	certFile := path.Join(a.K0sVars.CertRootDir, "admin.crt")
 	keyFile := path.Join(a.K0sVars.CertRootDir, "admin.key")
@@ -213,7 +344,7 @@ func (a *APIServer) Ready() error {
 		return err
 	}
 	// Load CA cert
-	caCert, err := os.ReadFile(path.Join(a.K0sVars.CertRootDir, "ca.crt"))
+	caCert, err := certificate.ReadCABundle(a.K0sVars.CertRootDir)
 	if err != nil {
 		return err
 	}
@@ -228,7 +359,12 @@ func (a *APIServer) Ready() error {
 		TLSClientConfig: tlsConfig,
 	}
 	client := &http.Client{Transport: tr}
-	resp, err := client.Get(fmt.Sprintf("https://localhost:%d/readyz?verbose", a.ClusterConfig.Spec.API.Port))
+	defer client.CloseIdleConnections()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("https://localhost:%d/%s?verbose", a.ClusterConfig.Spec.API.Port, endpoint), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return err
 	}
@@ -236,9 +372,9 @@ func (a *APIServer) Ready() error {
 	if resp.StatusCode != http.StatusOK {
 		body, err := io.ReadAll(resp.Body)
 		if err == nil {
-			logrus.Debugf("api server readyz output:\n %s", string(body))
+			logrus.Debugf("api server %s output:\n %s", endpoint, string(body))
 		}
-		return fmt.Errorf("expected 200 for api server ready check, got %d", resp.StatusCode)
+		return fmt.Errorf("expected 200 for api server %s check, got %d", endpoint, resp.StatusCode)
 	}
 	return nil
 }
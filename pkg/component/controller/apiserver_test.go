@@ -17,8 +17,12 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+	"net"
+	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/constant"
@@ -121,3 +125,68 @@ func (a *apiServerSuite) TestGetEtcdArgs() {
 		require.Contains(result[1], "--etcd-prefix=k0s-tenant-1")
 	})
 }
+
+func (a *apiServerSuite) TestPrepareAudit() {
+	dataDir := a.T().TempDir()
+	logPath := filepath.Join(dataDir, "audit", "logs", "audit.log")
+	underTest := &APIServer{
+		K0sVars: constant.CfgVars{DataDir: dataDir},
+		uid:     os.Geteuid(),
+		gid:     os.Getegid(),
+	}
+
+	err := underTest.prepareAudit(&v1beta1.Audit{
+		Policy: "apiVersion: audit.k8s.io/v1\nkind: Policy\n",
+		Log:    &v1beta1.AuditLog{Path: logPath},
+	})
+
+	require := a.Require()
+	require.NoError(err)
+	policy, err := os.ReadFile(filepath.Join(dataDir, "audit-policy.yaml"))
+	require.NoError(err)
+	require.Equal("apiVersion: audit.k8s.io/v1\nkind: Policy\n", string(policy))
+	require.DirExists(filepath.Dir(logPath))
+}
+
+func (a *apiServerSuite) TestWriteAdmissionConfig() {
+	dataDir := a.T().TempDir()
+	underTest := &APIServer{K0sVars: constant.CfgVars{DataDir: dataDir}}
+
+	require := a.Require()
+	require.NoError(underTest.writeAdmissionConfig(&v1beta1.Admission{EnablePlugins: []string{"EventRateLimit"}}))
+	require.NoFileExists(filepath.Join(dataDir, "admission-config.yaml"))
+
+	require.NoError(underTest.writeAdmissionConfig(&v1beta1.Admission{
+		Plugins: []v1beta1.AdmissionPlugin{{Name: "EventRateLimit", Path: "/etc/k0s/eventratelimit.yaml"}},
+	}))
+	config, err := os.ReadFile(filepath.Join(dataDir, "admission-config.yaml"))
+	require.NoError(err)
+	require.Contains(string(config), "kind: AdmissionConfiguration")
+}
+
+func (a *apiServerSuite) TestWriteEncryptionConfig() {
+	dataDir := a.T().TempDir()
+	underTest := &APIServer{K0sVars: constant.CfgVars{DataDir: dataDir}, uid: os.Geteuid(), gid: os.Getegid()}
+
+	require := a.Require()
+	require.NoError(underTest.writeEncryptionConfig(&v1beta1.Encryption{
+		KMS: &v1beta1.KMS{Name: "vault", Socket: "/run/kms/vault.sock"},
+	}))
+	config, err := os.ReadFile(filepath.Join(dataDir, "encryption-config.yaml"))
+	require.NoError(err)
+	require.Contains(string(config), "kind: EncryptionConfiguration")
+	require.Contains(string(config), "endpoint: unix:///run/kms/vault.sock")
+}
+
+func (a *apiServerSuite) TestWaitForKMSPlugin() {
+	socket := filepath.Join(a.T().TempDir(), "kms.sock")
+
+	require := a.Require()
+	err := waitForKMSPlugin(context.TODO(), socket, 100*time.Millisecond)
+	require.ErrorContains(err, "KMS plugin not reachable at "+socket)
+
+	listener, err := net.Listen("unix", socket)
+	require.NoError(err)
+	defer listener.Close()
+	require.NoError(waitForKMSPlugin(context.TODO(), socket, 100*time.Millisecond))
+}
@@ -36,6 +36,7 @@ type Autopilot struct {
 	K0sVars            constant.CfgVars
 	AdminClientFactory kubernetes.ClientFactoryInterface
 	EnableWorker       bool
+	K0sAPIAddress      string
 }
 
 func (a *Autopilot) Init(ctx context.Context) error {
@@ -57,6 +58,7 @@ func (a *Autopilot) Start(ctx context.Context) error {
 		ManagerPort:         8899,
 		MetricsBindAddr:     "0",
 		HealthProbeBindAddr: "0",
+		K0sAPIAddress:       a.K0sAPIAddress,
 	}, logrus.WithFields(logrus.Fields{"component": "autopilot"}), a.EnableWorker, a.AdminClientFactory, autopilotClientFactory)
 	if err != nil {
 		return fmt.Errorf("failed to create autopilot controller: %w", err)
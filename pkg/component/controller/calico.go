@@ -50,6 +50,7 @@ type Calico struct {
 	saver      manifestsSaver
 	prevConfig calicoConfig
 	k0sVars    constant.CfgVars
+	nodeConf   *v1beta1.ClusterConfig
 }
 
 type manifestsSaver interface {
@@ -76,10 +77,26 @@ type calicoConfig struct {
 	IPAutodetectionMethod      string
 	IPV6AutodetectionMethod    string
 	PullPolicy                 string
+
+	// Windows nodes
+	CalicoCNIWindowsImage  string
+	CalicoNodeWindowsImage string
+	KubeProxyWindowsImage  string
+	WindowsAPIServerHost   string
+	WindowsAPIServerPort   int
+	ServiceCIDR            string
+	ClusterDNSIP           string
+	ClusterDomain          string
+
+	BGPPeers                      []v1beta1.CalicoBGPPeer
+	FelixLogSeverityScreen        string
+	FelixPrometheusMetricsEnabled bool
+	FelixPrometheusMetricsPort    int
+	FelixIptablesBackend          string
 }
 
 // NewCalico creates new Calico reconciler component
-func NewCalico(k0sVars constant.CfgVars, crdSaver manifestsSaver, manifestsSaver manifestsSaver) *Calico {
+func NewCalico(k0sVars constant.CfgVars, nodeConfig *v1beta1.ClusterConfig, crdSaver manifestsSaver, manifestsSaver manifestsSaver) *Calico {
 	return &Calico{
 		log: logrus.WithFields(logrus.Fields{"component": "calico"}),
 
@@ -87,6 +104,7 @@ func NewCalico(k0sVars constant.CfgVars, crdSaver manifestsSaver, manifestsSaver
 		saver:      manifestsSaver,
 		prevConfig: calicoConfig{},
 		k0sVars:    k0sVars,
+		nodeConf:   nodeConfig,
 	}
 }
 
@@ -203,6 +221,39 @@ func (c *Calico) getConfig(clusterConfig *v1beta1.ClusterConfig) (calicoConfig,
 		IPAutodetectionMethod:      clusterConfig.Spec.Network.Calico.IPAutodetectionMethod,
 		IPV6AutodetectionMethod:    ipv6AutoDetectionMethod,
 		PullPolicy:                 clusterConfig.Spec.Images.DefaultPullPolicy,
+
+		BGPPeers:                      clusterConfig.Spec.Network.Calico.BGPPeers,
+		FelixLogSeverityScreen:        "info",
+		FelixPrometheusMetricsEnabled: true,
+		FelixIptablesBackend:          "auto",
+	}
+
+	if config.WithWindowsNodes {
+		dnsAddress, err := clusterConfig.Spec.Network.DNSAddress()
+		if err != nil {
+			return config, err
+		}
+		config.CalicoCNIWindowsImage = clusterConfig.Spec.Images.Calico.CNIWindows.URI()
+		config.CalicoNodeWindowsImage = clusterConfig.Spec.Images.Calico.NodeWindows.URI()
+		config.KubeProxyWindowsImage = clusterConfig.Spec.Images.Calico.KubeProxyWindows.URI()
+		config.WindowsAPIServerHost = c.nodeConf.Spec.API.APIAddress()
+		config.WindowsAPIServerPort = c.nodeConf.Spec.API.Port
+		config.ServiceCIDR = clusterConfig.Spec.Network.ServiceCIDR
+		config.ClusterDNSIP = dnsAddress
+		config.ClusterDomain = clusterConfig.Spec.Network.ClusterDomain
+	}
+
+	if felix := clusterConfig.Spec.Network.Calico.Felix; felix != nil {
+		if felix.LogSeverityScreen != "" {
+			config.FelixLogSeverityScreen = felix.LogSeverityScreen
+		}
+		if felix.PrometheusMetricsEnabled != nil {
+			config.FelixPrometheusMetricsEnabled = *felix.PrometheusMetricsEnabled
+		}
+		config.FelixPrometheusMetricsPort = felix.PrometheusMetricsPort
+		if felix.IptablesBackend != "" {
+			config.FelixIptablesBackend = felix.IptablesBackend
+		}
 	}
 
 	return config, nil
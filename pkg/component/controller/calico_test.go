@@ -18,10 +18,13 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/constant"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/yaml"
 )
@@ -43,7 +46,7 @@ func TestCalicoManifests(t *testing.T) {
 	t.Run("must_write_crd_during_bootstrap", func(t *testing.T) {
 		saver := inMemorySaver{}
 		crdSaver := inMemorySaver{}
-		calico := NewCalico(k0sVars, crdSaver, saver)
+		calico := NewCalico(k0sVars, clusterConfig, crdSaver, saver)
 		require.NoError(t, calico.Start(context.Background()))
 		require.NoError(t, calico.Stop())
 
@@ -56,7 +59,7 @@ func TestCalicoManifests(t *testing.T) {
 	t.Run("must_write_only_non_crd_on_change", func(t *testing.T) {
 		saver := inMemorySaver{}
 		crdSaver := inMemorySaver{}
-		calico := NewCalico(k0sVars, crdSaver, saver)
+		calico := NewCalico(k0sVars, clusterConfig, crdSaver, saver)
 
 		_ = calico.processConfigChanges(calicoConfig{})
 
@@ -70,7 +73,7 @@ func TestCalicoManifests(t *testing.T) {
 		clusterConfig.Spec.Network.Calico.EnableWireguard = true
 		saver := inMemorySaver{}
 		crdSaver := inMemorySaver{}
-		calico := NewCalico(k0sVars, crdSaver, saver)
+		calico := NewCalico(k0sVars, clusterConfig, crdSaver, saver)
 		cfg, err := calico.getConfig(clusterConfig)
 		require.NoError(t, err)
 		_ = calico.processConfigChanges(cfg)
@@ -86,7 +89,7 @@ func TestCalicoManifests(t *testing.T) {
 		clusterConfig.Spec.Network.Calico.EnableWireguard = false
 		saver := inMemorySaver{}
 		crdSaver := inMemorySaver{}
-		calico := NewCalico(k0sVars, crdSaver, saver)
+		calico := NewCalico(k0sVars, clusterConfig, crdSaver, saver)
 
 		cfg, err := calico.getConfig(clusterConfig)
 		require.NoError(t, err)
@@ -104,7 +107,7 @@ func TestCalicoManifests(t *testing.T) {
 			clusterConfig.Spec.Network.Calico.IPAutodetectionMethod = "somemethod"
 			saver := inMemorySaver{}
 			crdSaver := inMemorySaver{}
-			calico := NewCalico(k0sVars, crdSaver, saver)
+			calico := NewCalico(k0sVars, clusterConfig, crdSaver, saver)
 			templateContext, err := calico.getConfig(clusterConfig)
 			require.NoError(t, err)
 			require.Equal(t, clusterConfig.Spec.Network.Calico.IPAutodetectionMethod, templateContext.IPAutodetectionMethod)
@@ -125,7 +128,7 @@ func TestCalicoManifests(t *testing.T) {
 			clusterConfig.Spec.Network.Calico.IPv6AutodetectionMethod = "anothermethod"
 			saver := inMemorySaver{}
 			crdSaver := inMemorySaver{}
-			calico := NewCalico(k0sVars, crdSaver, saver)
+			calico := NewCalico(k0sVars, clusterConfig, crdSaver, saver)
 			templateContext, err := calico.getConfig(clusterConfig)
 			require.NoError(t, err)
 			require.Equal(t, clusterConfig.Spec.Network.Calico.IPAutodetectionMethod, templateContext.IPAutodetectionMethod)
@@ -142,6 +145,148 @@ func TestCalicoManifests(t *testing.T) {
 			spec.RequireContainerHasEnvVariable(t, "calico-node", "IP_AUTODETECTION_METHOD", templateContext.IPAutodetectionMethod)
 		})
 	})
+
+	t.Run("felix_settings", func(t *testing.T) {
+		t.Run("defaults", func(t *testing.T) {
+			clusterConfig.Spec.Network.Calico.Felix = nil
+			saver := inMemorySaver{}
+			calico := NewCalico(k0sVars, clusterConfig, inMemorySaver{}, saver)
+			cfg, err := calico.getConfig(clusterConfig)
+			require.NoError(t, err)
+			_ = calico.processConfigChanges(cfg)
+
+			spec := daemonSetContainersEnv{}
+			require.NoError(t, yaml.Unmarshal(saver["calico-DaemonSet-calico-node.yaml"], &spec))
+			spec.RequireContainerHasEnvVariable(t, "calico-node", "FELIX_LOGSEVERITYSCREEN", "info")
+			spec.RequireContainerHasEnvVariable(t, "calico-node", "FELIX_PROMETHEUSMETRICSENABLED", "true")
+			spec.RequireContainerHasEnvVariable(t, "calico-node", "FELIX_IPTABLESBACKEND", "auto")
+			spec.RequireContainerHasNoEnvVariable(t, "calico-node", "FELIX_PROMETHEUSMETRICSPORT")
+		})
+		t.Run("overrides", func(t *testing.T) {
+			metricsEnabled := false
+			clusterConfig.Spec.Network.Calico.Felix = &v1beta1.CalicoFelix{
+				LogSeverityScreen:        "Warning",
+				PrometheusMetricsEnabled: &metricsEnabled,
+				PrometheusMetricsPort:    9095,
+				IptablesBackend:          "nft",
+			}
+			t.Cleanup(func() { clusterConfig.Spec.Network.Calico.Felix = nil })
+			saver := inMemorySaver{}
+			calico := NewCalico(k0sVars, clusterConfig, inMemorySaver{}, saver)
+			cfg, err := calico.getConfig(clusterConfig)
+			require.NoError(t, err)
+			_ = calico.processConfigChanges(cfg)
+
+			spec := daemonSetContainersEnv{}
+			require.NoError(t, yaml.Unmarshal(saver["calico-DaemonSet-calico-node.yaml"], &spec))
+			spec.RequireContainerHasEnvVariable(t, "calico-node", "FELIX_LOGSEVERITYSCREEN", "Warning")
+			spec.RequireContainerHasEnvVariable(t, "calico-node", "FELIX_PROMETHEUSMETRICSENABLED", "false")
+			spec.RequireContainerHasEnvVariable(t, "calico-node", "FELIX_PROMETHEUSMETRICSPORT", "9095")
+			spec.RequireContainerHasEnvVariable(t, "calico-node", "FELIX_IPTABLESBACKEND", "nft")
+		})
+	})
+
+	t.Run("bgp_peers", func(t *testing.T) {
+		clusterConfig.Spec.Network.Calico.BGPPeers = []v1beta1.CalicoBGPPeer{
+			{Name: "tor-a", PeerIP: "192.0.2.1", ASNumber: 64512, NodeSelector: "rack == 'a'"},
+			{Name: "tor-b", PeerIP: "192.0.2.2", ASNumber: 64513, KeepOriginalNextHop: true},
+		}
+		t.Cleanup(func() { clusterConfig.Spec.Network.Calico.BGPPeers = nil })
+		saver := inMemorySaver{}
+		calico := NewCalico(k0sVars, clusterConfig, inMemorySaver{}, saver)
+		cfg, err := calico.getConfig(clusterConfig)
+		require.NoError(t, err)
+		_ = calico.processConfigChanges(cfg)
+
+		manifest, found := saver["calico-BGPPeer-bgppeers.yaml"]
+		require.True(t, found, "must have BGP peers manifest")
+		docs := strings.Split(strings.TrimPrefix(string(manifest), "\n---\n"), "\n---\n")
+		require.Len(t, docs, 2)
+
+		var peer struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec map[string]interface{} `json:"spec"`
+		}
+		require.NoError(t, yaml.Unmarshal([]byte(docs[0]), &peer))
+		assert.Equal(t, "BGPPeer", peer.Kind)
+		assert.Equal(t, "tor-a", peer.Metadata.Name)
+		assert.Equal(t, map[string]interface{}{
+			"peerIP":       "192.0.2.1",
+			"asNumber":     float64(64512),
+			"nodeSelector": "rack == 'a'",
+		}, peer.Spec)
+
+		require.NoError(t, yaml.Unmarshal([]byte(docs[1]), &peer))
+		assert.Equal(t, "tor-b", peer.Metadata.Name)
+		assert.Equal(t, true, peer.Spec["keepOriginalNextHop"])
+	})
+
+	t.Run("windows_nodes", func(t *testing.T) {
+		t.Run("not_deployed_by_default", func(t *testing.T) {
+			saver := inMemorySaver{}
+			calico := NewCalico(k0sVars, clusterConfig, inMemorySaver{}, saver)
+			cfg, err := calico.getConfig(clusterConfig)
+			require.NoError(t, err)
+			_ = calico.processConfigChanges(cfg)
+
+			for _, name := range []string{
+				"calico-ConfigMap-calico-windows-config.yaml",
+				"calico-DaemonSet-calico-node-windows.yaml",
+				"calico-DaemonSet-kube-proxy-windows.yaml",
+			} {
+				assert.Empty(t, strings.TrimSpace(string(saver[name])), "%s must be empty", name)
+			}
+		})
+
+		t.Run("deployed_if_enabled", func(t *testing.T) {
+			clusterConfig.Spec.Network.Calico.WithWindowsNodes = true
+			clusterConfig.Spec.API.Address = "192.0.2.10"
+			t.Cleanup(func() { clusterConfig.Spec.Network.Calico.WithWindowsNodes = false })
+			saver := inMemorySaver{}
+			calico := NewCalico(k0sVars, clusterConfig, inMemorySaver{}, saver)
+			cfg, err := calico.getConfig(clusterConfig)
+			require.NoError(t, err)
+			_ = calico.processConfigChanges(cfg)
+
+			var configMap struct {
+				Data map[string]string `json:"data"`
+			}
+			require.NoError(t, yaml.Unmarshal(saver["calico-ConfigMap-calico-windows-config.yaml"], &configMap))
+			assert.Equal(t, "vxlan", configMap.Data["CALICO_NETWORKING_BACKEND"])
+			assert.Equal(t, "192.0.2.10", configMap.Data["KUBERNETES_SERVICE_HOST"])
+			assert.Equal(t, "6443", configMap.Data["KUBERNETES_SERVICE_PORT"])
+			assert.Equal(t, "10.96.0.0/12", configMap.Data["K8S_SERVICE_CIDR"])
+			assert.Equal(t, "10.96.0.10", configMap.Data["DNS_NAME_SERVERS"])
+			cniConfig := strings.ReplaceAll(configMap.Data["cni_network_config"], "__CNI_MTU__", "0")
+			assert.True(t, json.Valid([]byte(cniConfig)), "CNI config must be valid JSON: %s", cniConfig)
+
+			spec := daemonSetContainersEnv{}
+			require.NoError(t, yaml.Unmarshal(saver["calico-DaemonSet-calico-node-windows.yaml"], &spec))
+			spec.RequireContainerHasEnvVariable(t, "calico-node-startup", "KUBE_NETWORK", "Calico.*")
+			spec.RequireContainerHasEnvVariable(t, "felix", "FELIX_LOGSEVERITYSCREEN", "info")
+
+			var kubeProxy struct {
+				Spec struct {
+					Template struct {
+						Spec struct {
+							NodeSelector map[string]string `json:"nodeSelector"`
+							Containers   []struct {
+								Image string `json:"image"`
+							} `json:"containers"`
+						} `json:"spec"`
+					} `json:"template"`
+				} `json:"spec"`
+			}
+			require.NoError(t, yaml.Unmarshal(saver["calico-DaemonSet-kube-proxy-windows.yaml"], &kubeProxy))
+			assert.Equal(t, map[string]string{"kubernetes.io/os": "windows"}, kubeProxy.Spec.Template.Spec.NodeSelector)
+			if assert.Len(t, kubeProxy.Spec.Template.Spec.Containers, 1) {
+				assert.Equal(t, clusterConfig.Spec.Images.Calico.KubeProxyWindows.URI(), kubeProxy.Spec.Template.Spec.Containers[0].Image)
+			}
+		})
+	})
 }
 
 // this structure is needed only for unit tests and basically it describes some fields that are needed to be parsed out of the daemon set manifest
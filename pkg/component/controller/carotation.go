@@ -0,0 +1,122 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/sirupsen/logrus"
+)
+
+// caRotationCheckInterval is the interval in which the CA rotation state is
+// checked.
+const caRotationCheckInterval = 30 * time.Second
+
+// CARotationReconciler applies the cluster-wide state of a CA rotation to the
+// certificate directory of this controller. The state is stored in a Secret
+// that's managed via "k0s ca rotate". The certificates issued by the CA are
+// reissued when the controller is restarted.
+type CARotationReconciler struct {
+	log *logrus.Entry
+
+	certManager       certificate.Manager
+	kubeClientFactory kubeutil.ClientFactoryInterface
+
+	lastApplied certificate.CARotationPhase
+
+	stop func()
+}
+
+var _ manager.Component = (*CARotationReconciler)(nil)
+
+// NewCARotationReconciler creates a new CA rotation reconciler.
+func NewCARotationReconciler(certManager certificate.Manager, kubeClientFactory kubeutil.ClientFactoryInterface) *CARotationReconciler {
+	return &CARotationReconciler{
+		log:               logrus.WithField("component", "ca-rotation"),
+		certManager:       certManager,
+		kubeClientFactory: kubeClientFactory,
+	}
+}
+
+func (r *CARotationReconciler) Init(context.Context) error {
+	return nil
+}
+
+func (r *CARotationReconciler) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			client, err := r.kubeClientFactory.GetClient()
+			if err == nil {
+				err = r.reconcile(ctx, client)
+			}
+			if err != nil {
+				r.log.WithError(err).Error("Failed to reconcile CA rotation")
+			}
+		}, caRotationCheckInterval)
+	}()
+
+	r.stop = func() { cancel(); <-done }
+	return nil
+}
+
+func (r *CARotationReconciler) Stop() error {
+	if r.stop != nil {
+		r.stop()
+	}
+	return nil
+}
+
+func (r *CARotationReconciler) reconcile(ctx context.Context, client kubernetes.Interface) error {
+	secret, err := client.CoreV1().Secrets(metav1.NamespaceSystem).Get(ctx, certificate.CARotationSecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	rotation, err := certificate.CARotationFromSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	changed, err := r.certManager.ApplyCARotation("ca", rotation)
+	if err != nil {
+		return fmt.Errorf("failed to apply CA rotation phase %q: %w", rotation.Phase, err)
+	}
+
+	if changed {
+		r.log.Warnf("Applied CA rotation phase %q, restart this controller to reissue its certificates and reload the trust bundle", rotation.Phase)
+	} else if r.lastApplied != rotation.Phase {
+		r.log.Infof("CA rotation phase %q is in effect", rotation.Phase)
+	}
+	r.lastApplied = rotation.Phase
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/sirupsen/logrus"
+)
+
+// certificateRenewalInterval is the interval in which certificates issued by
+// a certificate provider are checked for renewal.
+const certificateRenewalInterval = 5 * time.Minute
+
+// CertificateRenewer renews the certificates issued by the certificate
+// manager's provider before they expire.
+type CertificateRenewer struct {
+	log         *logrus.Entry
+	certManager certificate.Manager
+
+	stop func()
+}
+
+var _ manager.Component = (*CertificateRenewer)(nil)
+
+// NewCertificateRenewer creates a new certificate renewer.
+func NewCertificateRenewer(certManager certificate.Manager) *CertificateRenewer {
+	return &CertificateRenewer{
+		log:         logrus.WithField("component", "certificate-renewer"),
+		certManager: certManager,
+	}
+}
+
+func (r *CertificateRenewer) Init(context.Context) error {
+	return nil
+}
+
+func (r *CertificateRenewer) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			if err := r.certManager.RenewProvidedCertificates(ctx, time.Now()); err != nil {
+				r.log.WithError(err).Error("Failed to renew certificates")
+			}
+		}, certificateRenewalInterval)
+	}()
+
+	r.stop = func() { cancel(); <-done }
+	return nil
+}
+
+func (r *CertificateRenewer) Stop() error {
+	if r.stop != nil {
+		r.stop()
+	}
+	return nil
+}
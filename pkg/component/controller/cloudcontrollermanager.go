@@ -0,0 +1,352 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/stringmap"
+	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/sirupsen/logrus"
+)
+
+// CloudControllerManager is the component implementation to manage the
+// external cloud controller manager of the configured cloud provider.
+type CloudControllerManager struct {
+	log logrus.FieldLogger
+
+	manifestDir string
+
+	previousConfig *cloudControllerManagerConfig
+}
+
+var _ manager.Component = (*CloudControllerManager)(nil)
+var _ manager.Reconciler = (*CloudControllerManager)(nil)
+
+type cloudControllerManagerConfig struct {
+	Provider   string
+	Image      string
+	PullPolicy string
+	SecretName string
+	Command    string
+	Args       []string
+}
+
+// NewCloudControllerManager creates a new CloudControllerManager component.
+func NewCloudControllerManager(k0sVars constant.CfgVars) *CloudControllerManager {
+	return &CloudControllerManager{
+		log: logrus.WithFields(logrus.Fields{"component": constant.CloudProviderComponentName}),
+
+		manifestDir: path.Join(k0sVars.ManifestsDir, "cloud-provider"),
+	}
+}
+
+// Init does nothing
+func (c *CloudControllerManager) Init(context.Context) error {
+	return nil
+}
+
+// Start does nothing
+func (c *CloudControllerManager) Start(context.Context) error {
+	return nil
+}
+
+// Reconcile detects changes in configuration and applies them to the component
+func (c *CloudControllerManager) Reconcile(_ context.Context, clusterConfig *v1beta1.ClusterConfig) error {
+	var cloudProvider *v1beta1.CloudProviderExtension
+	if clusterConfig.Spec.Extensions != nil {
+		cloudProvider = clusterConfig.Spec.Extensions.CloudProvider
+	}
+	if !cloudProvider.IsEnabled() {
+		c.previousConfig = nil
+		return os.RemoveAll(c.manifestDir)
+	}
+
+	cfg, err := c.getConfig(clusterConfig, cloudProvider)
+	if err != nil {
+		return err
+	}
+	if reflect.DeepEqual(cfg, c.previousConfig) {
+		c.log.Debug("current config matches existing, not gonna do anything")
+		return nil
+	}
+
+	if err := dir.Init(c.manifestDir, constant.ManifestsDirMode); err != nil {
+		return err
+	}
+
+	tw := templatewriter.TemplateWriter{
+		Name:     "cloud-controller-manager",
+		Template: cloudControllerManagerTemplate,
+		Data:     cfg,
+		Path:     filepath.Join(c.manifestDir, "cloud-controller-manager.yaml"),
+	}
+	if err := tw.Write(); err != nil {
+		return fmt.Errorf("error writing cloud-controller-manager manifests: %w", err)
+	}
+	c.previousConfig = cfg
+
+	return nil
+}
+
+// Stop does nothing
+func (c *CloudControllerManager) Stop() error {
+	return nil
+}
+
+func (c *CloudControllerManager) getConfig(clusterConfig *v1beta1.ClusterConfig, cloudProvider *v1beta1.CloudProviderExtension) (*cloudControllerManagerConfig, error) {
+	image := cloudProvider.Image
+	if image == nil {
+		image = v1beta1.DefaultCloudProviderImage(cloudProvider.Provider)
+		if image == nil {
+			return nil, fmt.Errorf("unsupported cloud provider: %q", cloudProvider.Provider)
+		}
+	}
+	pullPolicy := string(cloudProvider.ImagePullPolicy)
+	if pullPolicy == "" {
+		pullPolicy = clusterConfig.Spec.Images.DefaultPullPolicy
+	}
+	secretName := cloudProvider.SecretName
+	if secretName == "" {
+		secretName = v1beta1.DefaultCloudProviderSecretName(cloudProvider.Provider)
+	}
+
+	// Pod networking is the job of the CNI, not the one of the cloud
+	// controller manager, hence no cloud routes.
+	args := stringmap.StringMap{
+		"cloud-provider":                  string(cloudProvider.Provider),
+		"configure-cloud-routes":          "false",
+		"leader-elect":                    "true",
+		"use-service-account-credentials": "true",
+	}
+
+	var command string
+	switch cloudProvider.Provider {
+	case v1beta1.CloudProviderOpenStack:
+		command = "/bin/openstack-cloud-controller-manager"
+		args["cloud-config"] = "/etc/config/cloud.conf"
+	case v1beta1.CloudProviderHetzner:
+		command = "/bin/hcloud-cloud-controller-manager"
+		args["allow-untagged-cloud"] = "true"
+		args["webhook-secure-port"] = "0"
+	}
+
+	args.Merge(stringmap.StringMap(cloudProvider.ExtraArgs))
+	dashedArgs := args.ToDashedArgs()
+	sort.Strings(dashedArgs)
+
+	return &cloudControllerManagerConfig{
+		Provider:   string(cloudProvider.Provider),
+		Image:      image.URI(),
+		PullPolicy: pullPolicy,
+		SecretName: secretName,
+		Command:    command,
+		Args:       dashedArgs,
+	}, nil
+}
+
+// The manifests are based on the upstream example deployments of the
+// respective cloud controller managers. The cloud controller manager runs on
+// the worker nodes, as k0s controllers aren't part of the cluster by default.
+// It has to tolerate the taint which is put onto nodes whose kubelets are
+// configured to use an external cloud provider, as it's the cloud controller
+// manager that removes this taint in the first place.
+const cloudControllerManagerTemplate = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: cloud-controller-manager
+  namespace: kube-system
+  labels:
+    app.kubernetes.io/name: cloud-controller-manager
+    app.kubernetes.io/managed-by: k0s
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: system:k0s:cloud-controller-manager
+  labels:
+    app.kubernetes.io/name: cloud-controller-manager
+    app.kubernetes.io/managed-by: k0s
+rules:
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch", "update"]
+- apiGroups: [""]
+  resources: ["nodes"]
+  verbs: ["*"]
+- apiGroups: [""]
+  resources: ["nodes/status"]
+  verbs: ["patch"]
+- apiGroups: [""]
+  resources: ["services"]
+  verbs: ["list", "patch", "update", "watch"]
+- apiGroups: [""]
+  resources: ["services/status"]
+  verbs: ["list", "patch", "update", "watch"]
+- apiGroups: [""]
+  resources: ["serviceaccounts"]
+  verbs: ["create", "get"]
+- apiGroups: [""]
+  resources: ["serviceaccounts/token"]
+  verbs: ["create"]
+- apiGroups: [""]
+  resources: ["persistentvolumes"]
+  verbs: ["get", "list", "update", "watch"]
+- apiGroups: [""]
+  resources: ["endpoints"]
+  verbs: ["create", "get", "list", "watch", "update"]
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["create", "get", "list", "watch", "update"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: system:k0s:cloud-controller-manager
+  labels:
+    app.kubernetes.io/name: cloud-controller-manager
+    app.kubernetes.io/managed-by: k0s
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: system:k0s:cloud-controller-manager
+subjects:
+- kind: ServiceAccount
+  name: cloud-controller-manager
+  namespace: kube-system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: cloud-controller-manager:apiserver-authentication-reader
+  namespace: kube-system
+  labels:
+    app.kubernetes.io/name: cloud-controller-manager
+    app.kubernetes.io/managed-by: k0s
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: extension-apiserver-authentication-reader
+subjects:
+- kind: ServiceAccount
+  name: cloud-controller-manager
+  namespace: kube-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: cloud-controller-manager
+  namespace: kube-system
+  labels:
+    app.kubernetes.io/name: cloud-controller-manager
+    app.kubernetes.io/managed-by: k0s
+    k0s.k0sproject.io/cloud-provider: {{ .Provider }}
+spec:
+  replicas: 1
+  revisionHistoryLimit: 10
+  strategy:
+    type: Recreate
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: cloud-controller-manager
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: cloud-controller-manager
+    spec:
+      serviceAccountName: cloud-controller-manager
+      priorityClassName: system-cluster-critical
+      hostNetwork: true
+      dnsPolicy: Default
+      nodeSelector:
+        kubernetes.io/os: linux
+      tolerations:
+      - key: node.cloudprovider.kubernetes.io/uninitialized
+        value: "true"
+        effect: NoSchedule
+      - key: CriticalAddonsOnly
+        operator: Exists
+      - key: node-role.kubernetes.io/master
+        operator: Exists
+        effect: NoSchedule
+      - key: node-role.kubernetes.io/control-plane
+        operator: Exists
+        effect: NoSchedule
+      - key: node.kubernetes.io/not-ready
+        operator: Exists
+        effect: NoSchedule
+      containers:
+      - name: cloud-controller-manager
+        image: {{ .Image }}
+        imagePullPolicy: {{ .PullPolicy }}
+{{- with .Command }}
+        command:
+        - {{ . }}
+{{- end }}
+        args:
+{{- range .Args }}
+        - {{ . | quote }}
+{{- end }}
+{{- if eq .Provider "hcloud" }}
+        env:
+        - name: HCLOUD_TOKEN
+          valueFrom:
+            secretKeyRef:
+              name: {{ .SecretName }}
+              key: token
+        - name: HCLOUD_NETWORK
+          valueFrom:
+            secretKeyRef:
+              name: {{ .SecretName }}
+              key: network
+              optional: true
+{{- else if and (eq .Provider "aws") .SecretName }}
+        envFrom:
+        - secretRef:
+            name: {{ .SecretName }}
+            optional: true
+{{- end }}
+        resources:
+          requests:
+            cpu: 100m
+            memory: 50Mi
+{{- if eq .Provider "openstack" }}
+        volumeMounts:
+        - name: cloud-config
+          mountPath: /etc/config
+          readOnly: true
+      volumes:
+      - name: cloud-config
+        secret:
+          secretName: {{ .SecretName }}
+{{- end }}
+`
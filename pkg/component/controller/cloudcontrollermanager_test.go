@@ -0,0 +1,130 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestCloudControllerManager_Reconcile(t *testing.T) {
+	k0sVars := constant.GetConfig(t.TempDir())
+	cfg := v1beta1.DefaultClusterConfig()
+	underTest := NewCloudControllerManager(k0sVars)
+	manifest := filepath.Join(underTest.manifestDir, "cloud-controller-manager.yaml")
+
+	readContainer := func(t *testing.T) (map[string]any, map[string]any) {
+		data, err := os.ReadFile(manifest)
+		require.NoError(t, err)
+
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			var obj unstructured.Unstructured
+			require.NoError(t, decoder.Decode(&obj.Object))
+			if obj.GetKind() != "Deployment" {
+				continue
+			}
+			podSpec, _, err := unstructured.NestedMap(obj.Object, "spec", "template", "spec")
+			require.NoError(t, err)
+			containers := podSpec["containers"].([]any)
+			require.Len(t, containers, 1)
+			return podSpec, containers[0].(map[string]any)
+		}
+	}
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoDirExists(t, underTest.manifestDir)
+	})
+
+	t.Run("aws", func(t *testing.T) {
+		cfg.Spec.Extensions.CloudProvider = &v1beta1.CloudProviderExtension{
+			Enabled:   true,
+			Provider:  v1beta1.CloudProviderAWS,
+			ExtraArgs: map[string]string{"v": "2", "leader-elect": "false"},
+		}
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+
+		podSpec, container := readContainer(t)
+		assert.Equal(t, true, podSpec["hostNetwork"])
+		assert.NotContains(t, podSpec, "volumes")
+		assert.Equal(t, v1beta1.DefaultCloudProviderImage(v1beta1.CloudProviderAWS).URI(), container["image"])
+		assert.Equal(t, "IfNotPresent", container["imagePullPolicy"])
+		assert.NotContains(t, container, "command")
+		assert.NotContains(t, container, "envFrom")
+		assert.Equal(t, []any{
+			"--cloud-provider=aws",
+			"--configure-cloud-routes=false",
+			"--leader-elect=false",
+			"--use-service-account-credentials=true",
+			"--v=2",
+		}, container["args"])
+	})
+
+	t.Run("openstack", func(t *testing.T) {
+		cfg.Spec.Extensions.CloudProvider = &v1beta1.CloudProviderExtension{
+			Enabled:  true,
+			Provider: v1beta1.CloudProviderOpenStack,
+		}
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+
+		podSpec, container := readContainer(t)
+		assert.Equal(t, []any{"/bin/openstack-cloud-controller-manager"}, container["command"])
+		assert.Contains(t, container["args"], "--cloud-config=/etc/config/cloud.conf")
+		volumes := podSpec["volumes"].([]any)
+		require.Len(t, volumes, 1)
+		secretName, _, err := unstructured.NestedString(volumes[0].(map[string]any), "secret", "secretName")
+		require.NoError(t, err)
+		assert.Equal(t, "cloud-config", secretName)
+	})
+
+	t.Run("hcloud", func(t *testing.T) {
+		cfg.Spec.Extensions.CloudProvider = &v1beta1.CloudProviderExtension{
+			Enabled:    true,
+			Provider:   v1beta1.CloudProviderHetzner,
+			SecretName: "my-hcloud",
+		}
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+
+		_, container := readContainer(t)
+		assert.Contains(t, container["args"], "--cloud-provider=hcloud")
+		env := container["env"].([]any)
+		require.Len(t, env, 2)
+		token := env[0].(map[string]any)
+		assert.Equal(t, "HCLOUD_TOKEN", token["name"])
+		secretName, _, err := unstructured.NestedString(token, "valueFrom", "secretKeyRef", "name")
+		require.NoError(t, err)
+		assert.Equal(t, "my-hcloud", secretName)
+	})
+
+	t.Run("disabled_again", func(t *testing.T) {
+		cfg.Spec.Extensions.CloudProvider.Enabled = false
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoDirExists(t, underTest.manifestDir)
+	})
+}
@@ -30,13 +30,17 @@ import (
 	"github.com/k0sproject/k0s/pkg/config"
 	"github.com/k0sproject/k0s/pkg/constant"
 	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+	"github.com/k0sproject/k0s/pkg/tracing"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 )
 
@@ -121,12 +125,16 @@ func (r *ClusterConfigReconciler) Start(ctx context.Context) error {
 					r.log.Debug("config source closed channel")
 					return
 				}
+				// Each reconciliation is traced on its own, not as part of
+				// the controller startup.
+				reconcileCtx, span := tracing.Tracer().Start(ctx, "reconcile cluster configuration", trace.WithNewRoot())
 				err := multierr.Combine(cfg.Validate()...)
 				if err != nil {
 					err = fmt.Errorf("failed to validate cluster configuration: %w", err)
 				} else {
-					err = r.ComponentManager.Reconcile(ctx, cfg)
+					err = r.ComponentManager.Reconcile(reconcileCtx, cfg)
 				}
+				tracing.End(span, err)
 				r.reportStatus(statusCtx, cfg, err)
 				if err != nil {
 					r.log.WithError(err).Error("Failed to reconcile cluster configuration")
@@ -155,7 +163,6 @@ func (r *ClusterConfigReconciler) reportStatus(ctx context.Context, config *v1be
 		r.log.Error("failed to get hostname:", err)
 		hostname = ""
 	}
-	// TODO We need to design proper status field(s) to the cluster cfg object, now just send event
 	client, err := r.KubeClientFactory.GetClient()
 	if err != nil {
 		r.log.Error("failed to get kube client:", err)
@@ -192,6 +199,74 @@ func (r *ClusterConfigReconciler) reportStatus(ctx context.Context, config *v1be
 	if err != nil {
 		r.log.Error("failed to create event for config reconcile:", err)
 	}
+
+	// The status is only maintained for configurations stored in the API,
+	// and only by the leader, so that controllers don't overwrite each other.
+	if config.UID != "" && r.leaderElector.IsLeader() {
+		if err := r.updateStatus(ctx, config, hostname, reconcileError); err != nil {
+			r.log.WithError(err).Error("Failed to update cluster configuration status")
+		}
+	}
+}
+
+func (r *ClusterConfigReconciler) updateStatus(ctx context.Context, config *v1beta1.ClusterConfig, reconciledBy string, reconcileError error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := r.configClient.Get(ctx, config.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if current.UID != config.UID {
+			// The configuration has been replaced in the meantime.
+			return nil
+		}
+
+		setReconcileStatus(&current.Status, config.Generation, reconciledBy, reconcileError)
+		_, err = r.configClient.UpdateStatus(ctx, current, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// setReconcileStatus updates the given status with the outcome of
+// reconciling the given generation of the cluster configuration.
+func setReconcileStatus(status *v1beta1.ClusterConfigStatus, generation int64, reconciledBy string, reconcileError error) {
+	status.ObservedGeneration = generation
+	status.ReconciledBy = reconciledBy
+	status.ComponentErrors = nil
+
+	reconciled := metav1.Condition{
+		Type:               v1beta1.ClusterConfigReconciled,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "ReconcileSucceeded",
+		Message:            "Successfully reconciled cluster config",
+	}
+	degraded := metav1.Condition{
+		Type:               v1beta1.ClusterConfigDegraded,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: generation,
+		Reason:             "ReconcileSucceeded",
+		Message:            "All components reconciled the cluster config",
+	}
+
+	if reconcileError != nil {
+		reason := "InvalidConfig"
+		if reconcileErr, ok := reconcileError.(manager.ReconcileError); ok {
+			reason = "ComponentsFailed"
+			for _, err := range reconcileErr.Errors {
+				componentErr := v1beta1.ComponentError{Message: err.Error()}
+				if err, ok := err.(manager.ComponentError); ok {
+					componentErr.Component, componentErr.Message = err.Component, err.Err.Error()
+				}
+				status.ComponentErrors = append(status.ComponentErrors, componentErr)
+			}
+		}
+
+		reconciled.Status, reconciled.Reason, reconciled.Message = metav1.ConditionFalse, reason, reconcileError.Error()
+		degraded.Status, degraded.Reason, degraded.Message = metav1.ConditionTrue, reason, reconcileError.Error()
+	}
+
+	apimeta.SetStatusCondition(&status.Conditions, reconciled)
+	apimeta.SetStatusCondition(&status.Conditions, degraded)
 }
 
 func (r *ClusterConfigReconciler) clusterConfigExists(ctx context.Context) error {
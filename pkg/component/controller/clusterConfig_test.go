@@ -0,0 +1,62 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetReconcileStatus(t *testing.T) {
+	var status v1beta1.ClusterConfigStatus
+
+	setReconcileStatus(&status, 1, "controller-1", manager.ReconcileError{Errors: []error{
+		manager.ComponentError{Component: "Konnectivity", Err: errors.New("boom")},
+	}})
+
+	assert.Equal(t, int64(1), status.ObservedGeneration)
+	assert.Equal(t, "controller-1", status.ReconciledBy)
+	assert.Equal(t, []v1beta1.ComponentError{{Component: "Konnectivity", Message: "boom"}}, status.ComponentErrors)
+	assert.True(t, apimeta.IsStatusConditionFalse(status.Conditions, v1beta1.ClusterConfigReconciled))
+	if degraded := apimeta.FindStatusCondition(status.Conditions, v1beta1.ClusterConfigDegraded); assert.NotNil(t, degraded) {
+		assert.Equal(t, metav1.ConditionTrue, degraded.Status)
+		assert.Equal(t, "ComponentsFailed", degraded.Reason)
+		assert.Equal(t, int64(1), degraded.ObservedGeneration)
+	}
+
+	setReconcileStatus(&status, 2, "controller-2", errors.New("failed to validate cluster configuration"))
+	assert.Empty(t, status.ComponentErrors)
+	if degraded := apimeta.FindStatusCondition(status.Conditions, v1beta1.ClusterConfigDegraded); assert.NotNil(t, degraded) {
+		assert.Equal(t, "InvalidConfig", degraded.Reason)
+	}
+
+	setReconcileStatus(&status, 3, "controller-1", nil)
+	assert.Equal(t, int64(3), status.ObservedGeneration)
+	assert.Equal(t, "controller-1", status.ReconciledBy)
+	assert.Empty(t, status.ComponentErrors)
+	assert.Len(t, status.Conditions, 2)
+	assert.True(t, apimeta.IsStatusConditionTrue(status.Conditions, v1beta1.ClusterConfigReconciled))
+	assert.True(t, apimeta.IsStatusConditionFalse(status.Conditions, v1beta1.ClusterConfigDegraded))
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"context"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+)
+
+var _ ConfigSource = (*compositeSource)(nil)
+
+type compositeSource struct {
+	source          ConfigSource
+	bootstrapConfig *v1beta1.ClusterConfig
+	resultChan      chan *v1beta1.ClusterConfig
+}
+
+// NewCompositeSource returns a ConfigSource that overlays the bootstrapping
+// values of the given file-based config, i.e. the node specific settings that
+// aren't part of the cluster-wide config, onto every config that's pushed by
+// the given source.
+func NewCompositeSource(source ConfigSource, fileConfig *v1beta1.ClusterConfig) ConfigSource {
+	fileConfig = fileConfig.DeepCopy()
+	return &compositeSource{
+		source:          source,
+		bootstrapConfig: fileConfig.GetBootstrappingConfig(fileConfig.Spec.Storage),
+		resultChan:      make(chan *v1beta1.ClusterConfig, 1),
+	}
+}
+
+func (c *compositeSource) Release(ctx context.Context) {
+	// Forward the configs before releasing the source, as releasing may block
+	// until the first config has been received.
+	go func() {
+		defer close(c.resultChan)
+		for {
+			select {
+			case cfg, ok := <-c.source.ResultChan():
+				if !ok {
+					return
+				}
+				cfg = overlayBootstrapConfig(cfg, c.bootstrapConfig)
+				select {
+				case c.resultChan <- cfg:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	c.source.Release(ctx)
+}
+
+func (c *compositeSource) ResultChan() <-chan *v1beta1.ClusterConfig {
+	return c.resultChan
+}
+
+func (c *compositeSource) Stop() {
+	c.source.Stop()
+}
+
+func (c *compositeSource) NeedToStoreInitialConfig() bool {
+	return c.source.NeedToStoreInitialConfig()
+}
+
+// overlayBootstrapConfig returns a copy of the given config with the fields
+// owned by the bootstrapping config replaced by their bootstrapping values.
+// The fields are assigned explicitly instead of being merged, so that false,
+// zero or empty bootstrapping values take precedence, too.
+func overlayBootstrapConfig(cfg, bootstrapConfig *v1beta1.ClusterConfig) *v1beta1.ClusterConfig {
+	cfg = cfg.DeepCopy()
+	if cfg.Spec == nil {
+		cfg.Spec = &v1beta1.ClusterSpec{}
+	}
+
+	bootstrapSpec := bootstrapConfig.Spec.DeepCopy()
+	cfg.Spec.API = bootstrapSpec.API
+	cfg.Spec.Storage = bootstrapSpec.Storage
+	if bootstrapSpec.Network != nil {
+		if cfg.Spec.Network == nil {
+			cfg.Spec.Network = &v1beta1.Network{}
+		}
+		cfg.Spec.Network.ServiceCIDR = bootstrapSpec.Network.ServiceCIDR
+		cfg.Spec.Network.DualStack = bootstrapSpec.Network.DualStack
+		cfg.Spec.Network.ClusterDomain = bootstrapSpec.Network.ClusterDomain
+	}
+	cfg.Spec.Install = bootstrapSpec.Install
+	cfg.Spec.Applier = bootstrapSpec.Applier
+	cfg.Spec.Certificates = bootstrapSpec.Certificates
+	cfg.Spec.Prober = bootstrapSpec.Prober
+	cfg.Spec.LeaderElection = bootstrapSpec.LeaderElection
+
+	return cfg
+}
@@ -18,8 +18,13 @@ package clusterconfig
 
 import (
 	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
 )
 
 type ConfigSource interface {
@@ -32,3 +37,90 @@ type ConfigSource interface {
 	// NeedToStoreInitialConfig tells the configsource user if the initial config should be stored in the api or not
 	NeedToStoreInitialConfig() bool
 }
+
+// The names of the built-in config sources.
+const (
+	// StaticSourceName is the name of the source that provides the cluster
+	// config from the k0s config file.
+	StaticSourceName = "static"
+	// APISourceName is the name of the source that watches the cluster config
+	// stored in the Kubernetes API.
+	APISourceName = "api"
+	// CompositeSourceName is the name of the source that watches the cluster
+	// config stored in the Kubernetes API and overlays the bootstrapping values
+	// of the k0s config file onto it.
+	CompositeSourceName = "composite"
+)
+
+// SourceOptions are the options that are passed to the SourceFactories.
+type SourceOptions struct {
+	KubeClientFactory kubeutil.ClientFactoryInterface
+	// LoadFileConfig loads the cluster config from the k0s config file.
+	LoadFileConfig func() (*v1beta1.ClusterConfig, error)
+}
+
+// SourceFactory creates a ConfigSource.
+type SourceFactory func(SourceOptions) (ConfigSource, error)
+
+var (
+	sourcesMu sync.RWMutex
+	sources   = map[string]SourceFactory{
+		StaticSourceName: func(opts SourceOptions) (ConfigSource, error) {
+			fileConfig, err := opts.LoadFileConfig()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load cluster config: %w", err)
+			}
+			return NewStaticSource(fileConfig)
+		},
+		APISourceName: func(opts SourceOptions) (ConfigSource, error) {
+			return NewAPIConfigSource(opts.KubeClientFactory)
+		},
+		CompositeSourceName: func(opts SourceOptions) (ConfigSource, error) {
+			fileConfig, err := opts.LoadFileConfig()
+			if err != nil {
+				return nil, fmt.Errorf("failed to load cluster config: %w", err)
+			}
+			apiSource, err := NewAPIConfigSource(opts.KubeClientFactory)
+			if err != nil {
+				return nil, err
+			}
+			return NewCompositeSource(apiSource, fileConfig), nil
+		},
+	}
+)
+
+// RegisterSource registers a ConfigSource under the given name, so that it can
+// be selected when starting a controller. This allows custom builds to
+// integrate external config stores. Sources are meant to be registered in init
+// functions. Panics if a source with the same name is already registered.
+func RegisterSource(name string, factory SourceFactory) {
+	sourcesMu.Lock()
+	defer sourcesMu.Unlock()
+	if _, exists := sources[name]; exists {
+		panic(fmt.Sprintf("config source %q is already registered", name))
+	}
+	sources[name] = factory
+}
+
+// SourceNames returns the sorted names of all registered config sources.
+func SourceNames() []string {
+	sourcesMu.RLock()
+	defer sourcesMu.RUnlock()
+	names := make([]string, 0, len(sources))
+	for name := range sources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewSource creates the ConfigSource that's registered under the given name.
+func NewSource(name string, opts SourceOptions) (ConfigSource, error) {
+	sourcesMu.RLock()
+	factory, ok := sources[name]
+	sourcesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown config source %q, expected one of %s", name, strings.Join(SourceNames(), ", "))
+	}
+	return factory(opts)
+}
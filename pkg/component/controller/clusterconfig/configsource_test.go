@@ -0,0 +1,94 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterconfig
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceRegistry(t *testing.T) {
+	fileConfig := v1beta1.DefaultClusterConfig()
+	opts := SourceOptions{
+		LoadFileConfig: func() (*v1beta1.ClusterConfig, error) { return fileConfig, nil },
+	}
+
+	source, err := NewSource(StaticSourceName, opts)
+	require.NoError(t, err)
+	assert.False(t, source.NeedToStoreInitialConfig())
+
+	_, err = NewSource("custom", opts)
+	assert.ErrorContains(t, err, `unknown config source "custom", expected one of api, composite, static`)
+
+	var custom staticSource
+	RegisterSource("custom", func(opts SourceOptions) (ConfigSource, error) { return &custom, nil })
+	t.Cleanup(func() {
+		sourcesMu.Lock()
+		defer sourcesMu.Unlock()
+		delete(sources, "custom")
+	})
+	source, err = NewSource("custom", opts)
+	require.NoError(t, err)
+	assert.Same(t, &custom, source)
+	assert.Equal(t, []string{"api", "composite", "custom", "static"}, SourceNames())
+	assert.Panics(t, func() { RegisterSource("custom", nil) })
+
+	opts.LoadFileConfig = func() (*v1beta1.ClusterConfig, error) { return nil, errors.New("no config") }
+	_, err = NewSource(CompositeSourceName, opts)
+	assert.ErrorContains(t, err, "failed to load cluster config: no config")
+}
+
+func TestCompositeSource(t *testing.T) {
+	fileConfig := v1beta1.DefaultClusterConfig()
+	fileConfig.Spec.API.Address = "10.0.0.1"
+	fileConfig.Spec.Network.ServiceCIDR = "10.97.0.0/12"
+	fileConfig.Spec.Network.KubeProxy.Mode = "ipvs"
+
+	apiConfig := v1beta1.DefaultClusterConfig().GetClusterWideConfig()
+	apiConfig.ResourceVersion = "42"
+	apiConfig.Spec.Network.KubeProxy.Mode = "iptables"
+	apiConfig.Spec.Network.Provider = "calico"
+	apiConfig.Spec.Network.DualStack.Enabled = true
+	apiConfig.Spec.API = &v1beta1.APISpec{TunneledNetworkingMode: true}
+
+	apiSource, err := NewStaticSource(apiConfig)
+	require.NoError(t, err)
+	source := NewCompositeSource(apiSource, fileConfig)
+	assert.False(t, source.NeedToStoreInitialConfig())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go source.Release(ctx)
+
+	cfg := <-source.ResultChan()
+	assert.Equal(t, "42", cfg.ResourceVersion)
+	if assert.NotNil(t, cfg.Spec.API) {
+		assert.Equal(t, "10.0.0.1", cfg.Spec.API.Address)
+		assert.False(t, cfg.Spec.API.TunneledNetworkingMode, "false bootstrapping values should take precedence")
+	}
+	assert.Equal(t, "10.97.0.0/12", cfg.Spec.Network.ServiceCIDR)
+	assert.False(t, cfg.Spec.Network.DualStack.Enabled, "false bootstrapping values should take precedence")
+	assert.Equal(t, "calico", cfg.Spec.Network.Provider)
+	assert.Equal(t, "iptables", cfg.Spec.Network.KubeProxy.Mode, "cluster-wide settings should be taken from the API")
+	assert.True(t, apiConfig.Spec.API.TunneledNetworkingMode, "the source's config should be left untouched")
+}
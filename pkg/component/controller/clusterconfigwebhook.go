@@ -0,0 +1,237 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/sirupsen/logrus"
+)
+
+// clusterConfigWebhookPath is the path on which the webhook is served.
+const clusterConfigWebhookPath = "/validate-clusterconfig"
+
+// ClusterConfigWebhook serves a validating admission webhook for the
+// ClusterConfig on the loopback interface of this controller. It rejects
+// invalid configurations, as well as changes to fields that can't be changed
+// once the cluster has been created, before they reach the reconcilers. As
+// each API server talks to the webhook of its own controller, the webhook is
+// registered with a loopback URL.
+type ClusterConfigWebhook struct {
+	log *logrus.Entry
+
+	k0sVars constant.CfgVars
+	port    int
+
+	stop func()
+}
+
+var _ manager.Component = (*ClusterConfigWebhook)(nil)
+
+// NewClusterConfigWebhook creates a new ClusterConfig webhook that listens on
+// the given port.
+func NewClusterConfigWebhook(k0sVars constant.CfgVars, port int) *ClusterConfigWebhook {
+	return &ClusterConfigWebhook{
+		log:     logrus.WithField("component", "clusterconfig-webhook"),
+		k0sVars: k0sVars,
+		port:    port,
+	}
+}
+
+func (w *ClusterConfigWebhook) Init(context.Context) error {
+	return nil
+}
+
+func (w *ClusterConfigWebhook) Start(context.Context) error {
+	listener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(w.port)))
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(clusterConfigWebhookPath, w.serveValidate)
+	server := &http.Server{
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		certFile := filepath.Join(w.k0sVars.CertRootDir, "k0s-webhook.crt")
+		keyFile := filepath.Join(w.k0sVars.CertRootDir, "k0s-webhook.key")
+		if err := server.ServeTLS(listener, certFile, keyFile); !errors.Is(err, http.ErrServerClosed) {
+			w.log.WithError(err).Error("Failed to serve")
+		}
+	}()
+	w.stop = func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			w.log.WithError(err).Warn("Failed to shut down")
+			_ = server.Close()
+		}
+		<-done
+	}
+
+	if err := w.writeManifests(); err != nil {
+		w.stop()
+		return fmt.Errorf("failed to write webhook manifests: %w", err)
+	}
+
+	return nil
+}
+
+func (w *ClusterConfigWebhook) Stop() error {
+	if w.stop != nil {
+		w.stop()
+	}
+	return nil
+}
+
+func (w *ClusterConfigWebhook) writeManifests() error {
+	caBundle, err := certificate.ReadCABundle(w.k0sVars.CertRootDir)
+	if err != nil {
+		return err
+	}
+
+	manifestsDir := filepath.Join(w.k0sVars.ManifestsDir, "clusterconfig-webhook")
+	if err := dir.Init(manifestsDir, constant.ManifestsDirMode); err != nil {
+		return err
+	}
+
+	tw := templatewriter.TemplateWriter{
+		Name:     "clusterconfig-webhook",
+		Template: clusterConfigWebhookTemplate,
+		Data: struct {
+			URL      string
+			CABundle string
+		}{
+			URL:      fmt.Sprintf("https://%s%s", net.JoinHostPort("127.0.0.1", strconv.Itoa(w.port)), clusterConfigWebhookPath),
+			CABundle: base64.StdEncoding.EncodeToString(caBundle),
+		},
+		Path: filepath.Join(manifestsDir, "clusterconfig-webhook.yaml"),
+	}
+	return tw.Write()
+}
+
+func (w *ClusterConfigWebhook) serveValidate(rw http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(rw, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var review admissionv1.AdmissionReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil || review.Request == nil {
+		http.Error(rw, "invalid admission review", http.StatusBadRequest)
+		return
+	}
+
+	response := &admissionv1.AdmissionResponse{UID: review.Request.UID, Allowed: true}
+	if err := validateClusterConfigAdmission(review.Request); err != nil {
+		w.log.WithError(err).Infof("Rejecting %s of ClusterConfig %s/%s", strings.ToLower(string(review.Request.Operation)), review.Request.Namespace, review.Request.Name)
+		response.Allowed = false
+		response.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+			Message: err.Error(),
+		}
+	}
+
+	review.Request = nil
+	review.Response = response
+	rw.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(rw).Encode(&review); err != nil {
+		w.log.WithError(err).Error("Failed to write admission response")
+	}
+}
+
+// validateClusterConfigAdmission validates the ClusterConfig of the given
+// admission request in the same way as the reconciler does, and, for updates,
+// rejects changes to immutable fields.
+func validateClusterConfigAdmission(req *admissionv1.AdmissionRequest) error {
+	var config v1beta1.ClusterConfig
+	if err := json.Unmarshal(req.Object.Raw, &config); err != nil {
+		return fmt.Errorf("failed to decode cluster configuration: %w", err)
+	}
+
+	errs := config.Validate()
+
+	if req.Operation == admissionv1.Update {
+		var old v1beta1.ClusterConfig
+		if err := json.Unmarshal(req.OldObject.Raw, &old); err != nil {
+			return fmt.Errorf("failed to decode previous cluster configuration: %w", err)
+		}
+		for _, err := range config.ValidateUpdate(&old) {
+			errs = append(errs, err)
+		}
+	}
+
+	if len(errs) > 0 {
+		messages := make([]string, len(errs))
+		for i, err := range errs {
+			messages[i] = err.Error()
+		}
+		return fmt.Errorf("invalid cluster configuration: %s", strings.Join(messages, "; "))
+	}
+
+	return nil
+}
+
+const clusterConfigWebhookTemplate = `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingWebhookConfiguration
+metadata:
+  name: k0s-clusterconfig
+webhooks:
+- name: clusterconfigs.k0s.k0sproject.io
+  admissionReviewVersions: ["v1"]
+  sideEffects: None
+  failurePolicy: Fail
+  timeoutSeconds: 5
+  clientConfig:
+    url: {{ .URL }}
+    caBundle: {{ .CABundle }}
+  rules:
+  - apiGroups: ["k0s.k0sproject.io"]
+    apiVersions: ["v1beta1"]
+    operations: ["CREATE", "UPDATE"]
+    resources: ["clusterconfigs"]
+    scope: Namespaced
+`
@@ -0,0 +1,110 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClusterConfigWebhook_Validate(t *testing.T) {
+	encode := func(t *testing.T, config *v1beta1.ClusterConfig) runtime.RawExtension {
+		raw, err := json.Marshal(config)
+		require.NoError(t, err)
+		return runtime.RawExtension{Raw: raw}
+	}
+
+	review := func(t *testing.T, req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+		body, err := json.Marshal(&admissionv1.AdmissionReview{Request: req})
+		require.NoError(t, err)
+
+		underTest := &ClusterConfigWebhook{log: logrus.WithField("test", t.Name())}
+		rec := httptest.NewRecorder()
+		underTest.serveValidate(rec, httptest.NewRequest(http.MethodPost, clusterConfigWebhookPath, bytes.NewReader(body)))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var resp admissionv1.AdmissionReview
+		require.NoError(t, json.NewDecoder(rec.Body).Decode(&resp))
+		require.NotNil(t, resp.Response)
+		assert.Equal(t, req.UID, resp.Response.UID)
+		return resp.Response
+	}
+
+	old := v1beta1.DefaultClusterConfig()
+
+	t.Run("valid_update", func(t *testing.T) {
+		config := old.DeepCopy()
+		config.Spec.Network.KubeRouter.MTU = 1350
+
+		resp := review(t, &admissionv1.AdmissionRequest{
+			UID:       "1",
+			Operation: admissionv1.Update,
+			Object:    encode(t, config),
+			OldObject: encode(t, old),
+		})
+		assert.True(t, resp.Allowed)
+	})
+
+	t.Run("immutable_field", func(t *testing.T) {
+		config := old.DeepCopy()
+		config.Spec.Network.Provider = "calico"
+
+		resp := review(t, &admissionv1.AdmissionRequest{
+			UID:       "2",
+			Operation: admissionv1.Update,
+			Object:    encode(t, config),
+			OldObject: encode(t, old),
+		})
+		assert.False(t, resp.Allowed)
+		if assert.NotNil(t, resp.Result) {
+			assert.Contains(t, resp.Result.Message, "spec.network.provider")
+		}
+	})
+
+	t.Run("invalid_config", func(t *testing.T) {
+		config := old.DeepCopy()
+		config.Spec.Network.PodCIDR = "invalid"
+
+		resp := review(t, &admissionv1.AdmissionRequest{
+			UID:       "3",
+			Operation: admissionv1.Create,
+			Object:    encode(t, config),
+		})
+		assert.False(t, resp.Allowed)
+	})
+
+	t.Run("unknown_field", func(t *testing.T) {
+		resp := review(t, &admissionv1.AdmissionRequest{
+			UID:       "4",
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte(`{"spec":{"bogus":true}}`)},
+		})
+		assert.False(t, resp.Allowed)
+	})
+}
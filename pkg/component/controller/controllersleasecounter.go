@@ -18,28 +18,65 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"time"
 
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
+	apclient "github.com/k0sproject/k0s/pkg/client/clientset"
+	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
 	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/constant"
+	"github.com/k0sproject/k0s/pkg/etcd"
 	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
 	"github.com/k0sproject/k0s/pkg/leaderelection"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/retry"
 	nodeutil "k8s.io/component-helpers/node/util"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// controllerHeartbeatInterval is the interval in which a controller
+	// publishes its status to its ControlNode.
+	controllerHeartbeatInterval = 30 * time.Second
+	// etcdHealthCheckTimeout is the time after which the local etcd member is
+	// considered unhealthy.
+	etcdHealthCheckTimeout = 5 * time.Second
+)
+
 // K0sControllersLeaseCounter implements a component that manages a lease per controller.
 // The per-controller leases are used to determine the amount of currently running controllers
+//
+// In addition, the controller's status is published to its ControlNode in
+// every heartbeat interval.
 type K0sControllersLeaseCounter struct {
 	ClusterConfig     *v1beta1.ClusterConfig
 	KubeClientFactory kubeutil.ClientFactoryInterface
-
+	K0sVars           constant.CfgVars
+	// Version is the k0s version of this controller.
+	Version string
+	// LeaderElectors are the leader electors whose leases are reported as held
+	// by this controller.
+	LeaderElectors []leaseHolder
+
+	apClient    apclient.Interface
 	cancelFunc  context.CancelFunc
 	leaseCancel context.CancelFunc
 }
 
+type leaseHolder interface {
+	Status() leaderelector.Status
+}
+
 var _ manager.Component = (*K0sControllersLeaseCounter)(nil)
 
 // Init initializes the component needs
@@ -89,9 +126,109 @@ func (l *K0sControllersLeaseCounter) Start(ctx context.Context) error {
 			}
 		}
 	}()
+
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := l.publishStatus(ctx); err != nil {
+			log.WithError(err).Warn("Failed to publish controller status")
+		}
+	}, controllerHeartbeatInterval)
+
 	return nil
 }
 
+// publishStatus writes the current status of this controller to its
+// ControlNode.
+func (l *K0sControllersLeaseCounter) publishStatus(ctx context.Context) error {
+	nodeName, err := apcomm.FindEffectiveHostname()
+	if err != nil {
+		return err
+	}
+	if l.apClient == nil {
+		if _, err := l.KubeClientFactory.GetClient(); err != nil {
+			return err
+		}
+		// The REST config is available once the client has been created.
+		if l.apClient, err = apclient.NewForConfig(l.KubeClientFactory.GetRESTConfig()); err != nil {
+			return err
+		}
+	}
+
+	status, err := l.controllerStatus(ctx)
+	if err != nil {
+		return err
+	}
+	return publishControllerStatus(ctx, l.apClient, nodeName, status)
+}
+
+// controllerStatus gathers the current status of this controller.
+func (l *K0sControllersLeaseCounter) controllerStatus(ctx context.Context) (*apv1beta2.ControllerStatus, error) {
+	configHash, err := hashNodeConfig(l.ClusterConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &apv1beta2.ControllerStatus{
+		Version:           l.Version,
+		ConfigHash:        configHash,
+		LastHeartbeatTime: metav1.Now(),
+	}
+	for _, leaderElector := range l.LeaderElectors {
+		if leaseStatus := leaderElector.Status(); leaseStatus.IsLeader {
+			status.Leases = append(status.Leases, leaseStatus.Lease)
+		}
+	}
+
+	if storage := l.ClusterConfig.Spec.Storage; storage.Type == v1beta1.EtcdStorageType && storage.Etcd != nil && !storage.Etcd.IsExternalClusterUsed() {
+		status.Etcd = &apv1beta2.EtcdMemberStatus{Healthy: true}
+		if err := l.checkEtcdHealth(ctx, storage.Etcd); err != nil {
+			status.Etcd.Healthy = false
+			status.Etcd.Message = err.Error()
+		}
+	}
+
+	return status, nil
+}
+
+func (l *K0sControllersLeaseCounter) checkEtcdHealth(ctx context.Context, etcdConfig *v1beta1.EtcdConfig) error {
+	client, err := etcd.NewClient(l.K0sVars.CertRootDir, l.K0sVars.EtcdCertDir, etcdConfig)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, etcdHealthCheckTimeout)
+	defer cancel()
+	return client.Health(ctx)
+}
+
+// hashNodeConfig returns a hash of the given node config, so that differences
+// between the configs of the controllers can be spotted.
+func hashNodeConfig(nodeConfig *v1beta1.ClusterConfig) (string, error) {
+	data, err := json.Marshal(nodeConfig.Spec)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func publishControllerStatus(ctx context.Context, apClient apclient.Interface, nodeName string, status *apv1beta2.ControllerStatus) error {
+	controlNodes := apClient.AutopilotV1beta2().ControlNodes()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := controlNodes.Get(ctx, nodeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			// The ControlNode is created by autopilot, which might be disabled.
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		node.Status.Controller = status
+		_, err = controlNodes.UpdateStatus(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+}
+
 // Stop stops the component
 func (l *K0sControllersLeaseCounter) Stop() error {
 	if l.leaseCancel != nil {
@@ -0,0 +1,96 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	apv1beta2 "github.com/k0sproject/k0s/pkg/apis/autopilot/v1beta2"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	apfake "github.com/k0sproject/k0s/pkg/client/clientset/fake"
+	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type fakeLeaseHolder leaderelector.Status
+
+func (f fakeLeaseHolder) Status() leaderelector.Status {
+	return leaderelector.Status(f)
+}
+
+func TestControllerStatus(t *testing.T) {
+	nodeConfig := v1beta1.DefaultClusterConfig()
+	nodeConfig.Spec.Storage = &v1beta1.StorageSpec{Type: v1beta1.KineStorageType, Kine: v1beta1.DefaultKineConfig("/var/lib/k0s")}
+
+	l := &K0sControllersLeaseCounter{
+		ClusterConfig: nodeConfig,
+		Version:       "v1.27.1+k0s.0",
+		LeaderElectors: []leaseHolder{
+			fakeLeaseHolder{Lease: "k0s-endpoint-reconciler", IsLeader: true},
+			fakeLeaseHolder{Lease: "k0s-component-coredns"},
+			fakeLeaseHolder{Lease: "k0s-component-helm", IsLeader: true},
+		},
+	}
+
+	status, err := l.controllerStatus(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, "v1.27.1+k0s.0", status.Version)
+	assert.Equal(t, []string{"k0s-endpoint-reconciler", "k0s-component-helm"}, status.Leases)
+	assert.Nil(t, status.Etcd, "kine doesn't have etcd members")
+	assert.False(t, status.LastHeartbeatTime.IsZero())
+
+	hash, err := hashNodeConfig(nodeConfig)
+	require.NoError(t, err)
+	assert.Equal(t, hash, status.ConfigHash)
+	assert.Len(t, hash, 64)
+
+	otherConfig := nodeConfig.DeepCopy()
+	otherConfig.Spec.Network.ServiceCIDR = "10.97.0.0/16"
+	otherHash, err := hashNodeConfig(otherConfig)
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, otherHash)
+}
+
+func TestPublishControllerStatus(t *testing.T) {
+	ctx := context.TODO()
+	apClient := apfake.NewSimpleClientset(&apv1beta2.ControlNode{
+		ObjectMeta: metav1.ObjectMeta{Name: "controller"},
+		Status: apv1beta2.ControlNodeStatus{
+			Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.1"}},
+		},
+	})
+	status := &apv1beta2.ControllerStatus{
+		Version:    "v1.27.1+k0s.0",
+		ConfigHash: "abc",
+		Leases:     []string{"k0s-endpoint-reconciler"},
+		Etcd:       &apv1beta2.EtcdMemberStatus{Healthy: true},
+	}
+
+	require.NoError(t, publishControllerStatus(ctx, apClient, "controller", status))
+	node, err := apClient.AutopilotV1beta2().ControlNodes().Get(ctx, "controller", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Len(t, node.Status.Addresses, 1, "other status fields should be preserved")
+	assert.Equal(t, status, node.Status.Controller)
+
+	// A missing ControlNode is not an error.
+	assert.NoError(t, publishControllerStatus(ctx, apClient, "other", status))
+}
@@ -17,18 +17,24 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math"
 	"path"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/k0sproject/k0s/pkg/component/manager"
 
 	"github.com/sirupsen/logrus"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
 
 	"github.com/k0sproject/k0s/internal/pkg/dir"
 	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
@@ -37,6 +43,37 @@ import (
 	k8sutil "github.com/k0sproject/k0s/pkg/kubernetes"
 )
 
+const corefileTemplate = `.:53 {
+    errors
+    health
+    ready
+{{- range .Rewrites }}
+    rewrite name {{ .MatchOrDefault }} {{ .From }} {{ .To }}{{ if eq .MatchOrDefault "regex" }} answer auto{{ end }}
+{{- end }}
+    kubernetes {{ .ClusterDomain }} in-addr.arpa ip6.arpa {
+      pods insecure
+      ttl 30
+      fallthrough in-addr.arpa ip6.arpa
+    }
+    prometheus :9153
+    forward . {{ if .UpstreamResolvers }}{{ join " " .UpstreamResolvers }}{{ else }}/etc/resolv.conf{{ end }}
+    cache 30
+    loop
+    reload
+    loadbalance
+}
+{{- range $domain, $resolvers := .StubDomains }}
+{{ $domain }}:53 {
+    errors
+    cache 30
+    forward . {{ join " " $resolvers }}
+}
+{{- end }}
+{{- range .ServerBlocks }}
+{{ trim . }}
+{{- end }}
+`
+
 const coreDNSTemplate = `
 apiVersion: v1
 kind: ServiceAccount
@@ -93,22 +130,7 @@ metadata:
   namespace: kube-system
 data:
   Corefile: |
-    .:53 {
-        errors
-        health
-        ready
-        kubernetes {{ .ClusterDomain }} in-addr.arpa ip6.arpa {
-          pods insecure
-          ttl 30
-          fallthrough in-addr.arpa ip6.arpa
-        }
-        prometheus :9153
-        forward . /etc/resolv.conf
-        cache 30
-        loop
-        reload
-        loadbalance
-    }
+{{ .Corefile | indent 4 }}
 ---
 apiVersion: apps/v1
 kind: Deployment
@@ -144,6 +166,10 @@ spec:
           effect: "NoSchedule"
       nodeSelector:
         kubernetes.io/os: linux
+{{- if .TopologySpreadConstraints }}
+      topologySpreadConstraints:
+{{ .TopologySpreadConstraints | indent 8 }}
+{{- else }}
       # Require running coredns replicas on different nodes
       affinity:
         podAntiAffinity:
@@ -154,16 +180,13 @@ spec:
               - key: k8s-app
                 operator: In
                 values: ['kube-dns']
+{{- end }}
       containers:
       - name: coredns
         image: {{ .Image }}
         imagePullPolicy: {{ .PullPolicy }}
         resources:
-          limits:
-            memory: 170Mi
-          requests:
-            cpu: 100m
-            memory: 70Mi
+{{ .Resources | indent 10 }}
         args: [ "-conf", "/etc/coredns/Corefile" ]
         volumeMounts:
         - name: config-volume
@@ -242,6 +265,18 @@ spec:
   - name: metrics
     port: 9153
     protocol: TCP
+{{- if .PodDisruptionBudget }}
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: coredns
+  namespace: kube-system
+  labels:
+    k8s-app: kube-dns
+spec:
+{{ .PodDisruptionBudget | indent 2 }}
+{{- end }}
 `
 
 const HostsPerExtraReplica = 10.0
@@ -263,11 +298,36 @@ type CoreDNS struct {
 }
 
 type coreDNSConfig struct {
-	Replicas      int
-	ClusterDNSIP  string
+	Replicas                  int
+	ClusterDNSIP              string
+	ClusterDomain             string
+	Corefile                  string
+	Image                     string
+	PullPolicy                string
+	Resources                 string
+	TopologySpreadConstraints string
+	PodDisruptionBudget       string
+}
+
+// kubeDNSSelector selects the CoreDNS Pods.
+var kubeDNSSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"k8s-app": "kube-dns"}}
+
+func defaultCoreDNSResources() *corev1.ResourceRequirements {
+	return &corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse("170Mi"),
+		},
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("100m"),
+			corev1.ResourceMemory: resource.MustParse("70Mi"),
+		},
+	}
+}
+
+// corefileConfig holds the values that are rendered into the Corefile.
+type corefileConfig struct {
 	ClusterDomain string
-	Image         string
-	PullPolicy    string
+	v1beta1.CoreDNS
 }
 
 // NewCoreDNS creates new instance of CoreDNS component
@@ -327,33 +387,126 @@ func (c *CoreDNS) getConfig(ctx context.Context, clusterConfig *v1beta1.ClusterC
 		return coreDNSConfig{}, err
 	}
 
-	nodes, err := c.client.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	nodes, err := c.client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return coreDNSConfig{}, err
 	}
 
+	settings := clusterConfig.Spec.Network.CoreDNS
+	if settings == nil {
+		settings = &v1beta1.CoreDNS{}
+	}
+
 	nodeCount := len(nodes.Items)
-	replicas := replicaCount(nodeCount)
+	replicas := replicaCount(nodeCount, settings.Replicas)
+
+	corefile, err := renderCorefile(clusterConfig.Spec.Network.ClusterDomain, settings)
+	if err != nil {
+		return coreDNSConfig{}, err
+	}
 
 	config := coreDNSConfig{
 		Replicas:      replicas,
 		ClusterDomain: clusterConfig.Spec.Network.ClusterDomain,
 		ClusterDNSIP:  dns,
+		Corefile:      corefile,
 		Image:         clusterConfig.Spec.Images.CoreDNS.URI(),
 		PullPolicy:    clusterConfig.Spec.Images.DefaultPullPolicy,
 	}
 
+	resources := settings.Resources
+	if resources == nil {
+		resources = defaultCoreDNSResources()
+	}
+	if config.Resources, err = toYAML(resources); err != nil {
+		return coreDNSConfig{}, err
+	}
+
+	if len(settings.TopologySpreadConstraints) > 0 {
+		constraints := make([]corev1.TopologySpreadConstraint, len(settings.TopologySpreadConstraints))
+		for i := range settings.TopologySpreadConstraints {
+			settings.TopologySpreadConstraints[i].DeepCopyInto(&constraints[i])
+			if constraints[i].LabelSelector == nil {
+				constraints[i].LabelSelector = kubeDNSSelector.DeepCopy()
+			}
+		}
+		if config.TopologySpreadConstraints, err = toYAML(constraints); err != nil {
+			return coreDNSConfig{}, err
+		}
+	}
+
+	if pdb := settings.PodDisruptionBudget; pdb != nil {
+		if config.PodDisruptionBudget, err = toYAML(&policyv1.PodDisruptionBudgetSpec{
+			MinAvailable:   pdb.MinAvailable,
+			MaxUnavailable: pdb.MaxUnavailable,
+			Selector:       kubeDNSSelector,
+		}); err != nil {
+			return coreDNSConfig{}, err
+		}
+	}
+
 	return config, nil
 }
 
-// calculates an extra replica per 10 hosts
-func replicaCount(nodeCount int) int {
+func toYAML(obj any) (string, error) {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(string(data), "\n"), nil
+}
+
+// renderCorefile renders the Corefile for the given cluster domain, applying
+// the customizations of the CoreDNS settings, if any.
+func renderCorefile(clusterDomain string, settings *v1beta1.CoreDNS) (string, error) {
+	cfg := corefileConfig{ClusterDomain: clusterDomain}
+	if settings != nil {
+		cfg.CoreDNS = *settings
+	}
+
+	var buf bytes.Buffer
+	tw := templatewriter.TemplateWriter{
+		Name:     "corefile",
+		Template: corefileTemplate,
+		Data:     cfg,
+	}
+	if err := tw.WriteToBuffer(&buf); err != nil {
+		return "", fmt.Errorf("error rendering Corefile: %w", err)
+	}
+
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// calculates an extra replica per 10 hosts, unless configured otherwise
+func replicaCount(nodeCount int, settings *v1beta1.CoreDNSReplicas) int {
+	if settings != nil && settings.Count != nil {
+		return int(*settings.Count)
+	}
+
+	hostsPerExtraReplica, min, max := HostsPerExtraReplica, 1, 0
+	if settings != nil {
+		if settings.NodesPerReplica > 0 {
+			hostsPerExtraReplica = float64(settings.NodesPerReplica)
+		}
+		if settings.Min > 0 {
+			min = int(settings.Min)
+		}
+		max = int(settings.Max)
+	}
+
 	// always at least one so we get the coreDNS up-and running fast with the first node joining the cluster
-	if nodeCount <= 1 {
-		return 1
+	replicas := 1
+	if nodeCount > 1 {
+		replicas += int(math.Ceil(float64(nodeCount) / hostsPerExtraReplica))
+	}
+
+	if replicas < min {
+		replicas = min
+	}
+	if max > 0 && replicas > max {
+		replicas = max
 	}
-	extraReplicas := int(math.Ceil(float64(nodeCount) / HostsPerExtraReplica))
-	return 1 + extraReplicas
+	return replicas
 }
 
 // Stop stops the CoreDNS reconciler
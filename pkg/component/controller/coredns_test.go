@@ -16,7 +16,27 @@ limitations under the License.
 
 package controller
 
-import "testing"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
+	"github.com/k0sproject/k0s/internal/testutil"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/utils/pointer"
+)
 
 func Test_replicaCount(t *testing.T) {
 	tests := []struct {
@@ -62,9 +82,195 @@ func Test_replicaCount(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := replicaCount(tt.nodes); got != tt.want {
+			if got := replicaCount(tt.nodes, nil); got != tt.want {
 				t.Errorf("replicaCount() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func Test_replicaCountWithSettings(t *testing.T) {
+	tests := []struct {
+		name     string
+		nodes    int
+		settings v1beta1.CoreDNSReplicas
+		want     int
+	}{
+		{"fixed count", 100, v1beta1.CoreDNSReplicas{Count: pointer.Int32(2), Max: 1}, 2},
+		{"fixed count of zero", 1, v1beta1.CoreDNSReplicas{Count: pointer.Int32(0)}, 0},
+		{"nodes per replica", 100, v1beta1.CoreDNSReplicas{NodesPerReplica: 50}, 3},
+		{"min", 1, v1beta1.CoreDNSReplicas{Min: 2}, 2},
+		{"max", 100, v1beta1.CoreDNSReplicas{Max: 4}, 4},
+		{"within bounds", 20, v1beta1.CoreDNSReplicas{Min: 2, Max: 4}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, replicaCount(tt.nodes, &tt.settings))
+		})
+	}
+}
+
+func TestCoreDNSManifest(t *testing.T) {
+	render := func(t *testing.T, clusterConfig *v1beta1.ClusterConfig) map[string]*unstructured.Unstructured {
+		underTest, err := NewCoreDNS(constant.GetConfig(t.TempDir()), testutil.NewFakeClientFactory(), clusterConfig)
+		require.NoError(t, err)
+		cfg, err := underTest.getConfig(context.TODO(), clusterConfig)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		tw := templatewriter.TemplateWriter{Name: "coredns", Template: coreDNSTemplate, Data: cfg}
+		require.NoError(t, tw.WriteToBuffer(&buf))
+
+		objects := make(map[string]*unstructured.Unstructured)
+		decoder := k8syaml.NewYAMLOrJSONDecoder(&buf, 4096)
+		for {
+			var obj unstructured.Unstructured
+			if err := decoder.Decode(&obj); errors.Is(err, io.EOF) {
+				break
+			} else {
+				require.NoError(t, err)
+			}
+			objects[obj.GetKind()] = &obj
+		}
+		return objects
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		objects := render(t, v1beta1.DefaultClusterConfig())
+		assert.NotContains(t, objects, "PodDisruptionBudget")
+
+		deployment := objects["Deployment"]
+		require.NotNil(t, deployment)
+		replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+		assert.Equal(t, int64(1), replicas)
+
+		podSpec, _, _ := unstructured.NestedMap(deployment.Object, "spec", "template", "spec")
+		assert.Contains(t, podSpec, "affinity")
+		assert.NotContains(t, podSpec, "topologySpreadConstraints")
+
+		containers, _, _ := unstructured.NestedSlice(podSpec, "containers")
+		require.Len(t, containers, 1)
+		assert.Equal(t, map[string]any{
+			"limits":   map[string]any{"memory": "170Mi"},
+			"requests": map[string]any{"cpu": "100m", "memory": "70Mi"},
+		}, containers[0].(map[string]any)["resources"])
+	})
+
+	t.Run("tuned", func(t *testing.T) {
+		clusterConfig := v1beta1.DefaultClusterConfig()
+		minAvailable := intstr.FromInt(1)
+		clusterConfig.Spec.Network.CoreDNS = &v1beta1.CoreDNS{
+			Replicas: &v1beta1.CoreDNSReplicas{Count: pointer.Int32(3)},
+			Resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m")},
+			},
+			TopologySpreadConstraints: []corev1.TopologySpreadConstraint{{
+				MaxSkew:           1,
+				TopologyKey:       "topology.kubernetes.io/zone",
+				WhenUnsatisfiable: corev1.ScheduleAnyway,
+			}},
+			PodDisruptionBudget: &v1beta1.CoreDNSPodDisruptionBudget{MinAvailable: &minAvailable},
+		}
+
+		objects := render(t, clusterConfig)
+
+		deployment := objects["Deployment"]
+		require.NotNil(t, deployment)
+		replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+		assert.Equal(t, int64(3), replicas)
+
+		podSpec, _, _ := unstructured.NestedMap(deployment.Object, "spec", "template", "spec")
+		assert.NotContains(t, podSpec, "affinity")
+		assert.Equal(t, []any{map[string]any{
+			"maxSkew":           int64(1),
+			"topologyKey":       "topology.kubernetes.io/zone",
+			"whenUnsatisfiable": "ScheduleAnyway",
+			"labelSelector":     map[string]any{"matchLabels": map[string]any{"k8s-app": "kube-dns"}},
+		}}, podSpec["topologySpreadConstraints"])
+
+		containers, _, _ := unstructured.NestedSlice(podSpec, "containers")
+		require.Len(t, containers, 1)
+		assert.Equal(t, map[string]any{
+			"requests": map[string]any{"cpu": "50m"},
+		}, containers[0].(map[string]any)["resources"])
+
+		pdb := objects["PodDisruptionBudget"]
+		require.NotNil(t, pdb)
+		spec, _, _ := unstructured.NestedMap(pdb.Object, "spec")
+		assert.Equal(t, map[string]any{
+			"minAvailable": int64(1),
+			"selector":     map[string]any{"matchLabels": map[string]any{"k8s-app": "kube-dns"}},
+		}, spec)
+	})
+}
+
+func TestRenderCorefile(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		corefile, err := renderCorefile("cluster.local", nil)
+		require.NoError(t, err)
+		assert.Equal(t, `.:53 {
+    errors
+    health
+    ready
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+      pods insecure
+      ttl 30
+      fallthrough in-addr.arpa ip6.arpa
+    }
+    prometheus :9153
+    forward . /etc/resolv.conf
+    cache 30
+    loop
+    reload
+    loadbalance
+}`, corefile)
+	})
+
+	t.Run("customized", func(t *testing.T) {
+		corefile, err := renderCorefile("cluster.local", &v1beta1.CoreDNS{
+			UpstreamResolvers: []string{"1.1.1.1", "8.8.8.8:53"},
+			StubDomains: map[string][]string{
+				"internal.example.com": {"10.0.0.10"},
+				"corp.example.com":     {"10.0.0.20", "10.0.0.21"},
+			},
+			Rewrites: []v1beta1.CoreDNSRewrite{
+				{From: "db.example.com", To: "db.default.svc.cluster.local"},
+				{Match: v1beta1.CoreDNSRewriteMatchRegex, From: `(.*)\.example\.org`, To: "{1}.default.svc.cluster.local"},
+			},
+			ServerBlocks: []string{"example.net:53 {\n    errors\n    whoami\n}\n"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, `.:53 {
+    errors
+    health
+    ready
+    rewrite name exact db.example.com db.default.svc.cluster.local
+    rewrite name regex (.*)\.example\.org {1}.default.svc.cluster.local answer auto
+    kubernetes cluster.local in-addr.arpa ip6.arpa {
+      pods insecure
+      ttl 30
+      fallthrough in-addr.arpa ip6.arpa
+    }
+    prometheus :9153
+    forward . 1.1.1.1 8.8.8.8:53
+    cache 30
+    loop
+    reload
+    loadbalance
+}
+corp.example.com:53 {
+    errors
+    cache 30
+    forward . 10.0.0.20 10.0.0.21
+}
+internal.example.com:53 {
+    errors
+    cache 30
+    forward . 10.0.0.10
+}
+example.net:53 {
+    errors
+    whoami
+}`, corefile)
+	})
+}
@@ -21,6 +21,8 @@ import (
 	"crypto/x509"
 	"encoding/pem"
 	"fmt"
+	"net"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -136,6 +138,11 @@ func (a *CSRApprover) approveCSR(ctx context.Context) error {
 			continue
 		}
 
+		if err := a.ensureRequestedByNode(ctx, &csr, x509cr); err != nil {
+			a.log.WithError(err).Warnf("Not approving kubelet-serving CSR %q", csr.Name)
+			continue
+		}
+
 		approved, err := a.authorize(ctx, &csr, authorization.ResourceAttributes{
 			Group:    "certificates.k8s.io",
 			Resource: "certificatesigningrequests",
@@ -146,7 +153,8 @@ func (a *CSRApprover) approveCSR(ctx context.Context) error {
 		}
 
 		if !approved {
-			return fmt.Errorf("failed to perform SubjectAccessReview for CSR %q", csr.Name)
+			a.log.Warnf("Not approving CSR %q as %q isn't allowed to create CSRs", csr.Name, csr.Spec.Username)
+			continue
 		}
 
 		a.log.Infof("approving csr %s with SANs: %s, IP Addresses:%s", csr.ObjectMeta.Name, x509cr.DNSNames, x509cr.IPAddresses)
@@ -155,8 +163,6 @@ func (a *CSRApprover) approveCSR(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("error updating approval for CSR %q: %w", csr.Name, err)
 		}
-
-		return nil
 	}
 
 	return nil
@@ -195,6 +201,42 @@ func (a *CSRApprover) ensureKubeletServingCert(csr *v1.CertificateSigningRequest
 	return certificates.ValidateKubeletServingCSR(x509cr, usages)
 }
 
+// ensureRequestedByNode checks that the CSR has been created by the node that
+// it's for, and that the requested SANs are addresses of that node. This
+// prevents nodes from obtaining serving certificates for other nodes' names.
+func (a *CSRApprover) ensureRequestedByNode(ctx context.Context, csr *v1.CertificateSigningRequest, x509cr *x509.CertificateRequest) error {
+	if csr.Spec.Username != x509cr.Subject.CommonName {
+		return fmt.Errorf("requested by %q instead of %q", csr.Spec.Username, x509cr.Subject.CommonName)
+	}
+
+	nodeName := strings.TrimPrefix(x509cr.Subject.CommonName, "system:node:")
+	node, err := a.clientset.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get node %q: %w", nodeName, err)
+	}
+
+	addresses := sets.NewString()
+	for _, address := range node.Status.Addresses {
+		if ip := net.ParseIP(address.Address); ip != nil {
+			addresses.Insert(ip.String())
+		} else {
+			addresses.Insert(address.Address)
+		}
+	}
+	for _, dnsName := range x509cr.DNSNames {
+		if !addresses.Has(dnsName) {
+			return fmt.Errorf("DNS name %q isn't an address of node %q", dnsName, nodeName)
+		}
+	}
+	for _, ip := range x509cr.IPAddresses {
+		if !addresses.Has(ip.String()) {
+			return fmt.Errorf("IP address %s isn't an address of node %q", ip, nodeName)
+		}
+	}
+
+	return nil
+}
+
 func getCertApprovalCondition(status *v1.CertificateSigningRequestStatus) (approved bool, denied bool) {
 	for _, c := range status.Conditions {
 		if c.Type == v1.CertificateApproved {
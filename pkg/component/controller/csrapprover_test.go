@@ -24,15 +24,21 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"net"
 	"testing"
 
 	"github.com/k0sproject/k0s/internal/testutil"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authorization "k8s.io/api/authorization/v1"
 	certv1 "k8s.io/api/certificates/v1"
 	core "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 func TestBasicCRSApprover(t *testing.T) {
@@ -85,6 +91,74 @@ func TestBasicCRSApprover(t *testing.T) {
 	}
 }
 
+func TestCSRApprover_KubeletServing(t *testing.T) {
+	node := &core.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+		Status: core.NodeStatus{
+			Addresses: []core.NodeAddress{
+				{Type: core.NodeHostName, Address: "worker"},
+				{Type: core.NodeInternalIP, Address: "10.0.0.1"},
+			},
+		},
+	}
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	newCSR := func(name, username string, dnsNames []string, ips ...net.IP) *certv1.CertificateSigningRequest {
+		return &certv1.CertificateSigningRequest{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: certv1.CertificateSigningRequestSpec{
+				Request: pemWithTemplate(&x509.CertificateRequest{
+					Subject: pkix.Name{
+						CommonName:   "system:node:worker",
+						Organization: []string{"system:nodes"},
+					},
+					DNSNames:    dnsNames,
+					IPAddresses: ips,
+				}, privateKey),
+				SignerName: certv1.KubeletServingSignerName,
+				Username:   username,
+				Usages: []certv1.KeyUsage{
+					certv1.UsageDigitalSignature,
+					certv1.UsageKeyEncipherment,
+					certv1.UsageServerAuth,
+				},
+			},
+		}
+	}
+
+	fakeFactory := testutil.NewFakeClientFactory(
+		node,
+		newCSR("valid", "system:node:worker", []string{"worker"}, net.ParseIP("10.0.0.1")),
+		newCSR("other-requestor", "system:node:other", []string{"worker"}),
+		newCSR("foreign-name", "system:node:worker", []string{"kubernetes.default.svc"}),
+		newCSR("foreign-ip", "system:node:worker", nil, net.ParseIP("10.0.0.2")),
+	)
+	fakeFactory.Client.(*kubefake.Clientset).PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		sar := action.(k8stesting.CreateAction).GetObject().(*authorization.SubjectAccessReview)
+		sar.Status.Allowed = true
+		return true, sar, nil
+	})
+
+	ctx := context.TODO()
+	c := NewCSRApprover(&v1beta1.ClusterConfig{}, &leaderelector.Dummy{Leader: true}, fakeFactory)
+	require.NoError(t, c.Init(ctx))
+	require.NoError(t, c.approveCSR(ctx))
+
+	for name, expectApproved := range map[string]bool{
+		"valid":           true,
+		"other-requestor": false,
+		"foreign-name":    false,
+		"foreign-ip":      false,
+	} {
+		csr, err := fakeFactory.Client.CertificatesV1().CertificateSigningRequests().Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		approved, _ := getCertApprovalCondition(&csr.Status)
+		assert.Equal(t, expectApproved, approved, "unexpected approval state of CSR %s", name)
+	}
+}
+
 func pemWithPrivateKey(pk crypto.PrivateKey) []byte {
 	template := &x509.CertificateRequest{
 		Subject: pkix.Name{
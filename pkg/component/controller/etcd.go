@@ -50,6 +50,11 @@ type Etcd struct {
 	JoinClient  *token.JoinClient
 	K0sVars     constant.CfgVars
 	LogLevel    string
+	// Additional SANs for the peer and server certificates
+	PeerSANs, ServerSANs []string
+	// Issue the peer and server certificates via the certificate manager's
+	// provider
+	UseCertificateProvider bool
 
 	supervisor supervisor.Supervisor
 	uid        int
@@ -93,12 +98,12 @@ func (e *Etcd) Init(_ context.Context) error {
 	return assets.Stage(e.K0sVars.BinDir, "etcd", constant.BinDirMode)
 }
 
-func (e *Etcd) syncEtcdConfig(peerURL, etcdCaCert, etcdCaCertKey string) ([]string, error) {
+func (e *Etcd) syncEtcdConfig(ctx context.Context, peerURL, etcdCaCert, etcdCaCertKey string) ([]string, error) {
 	var etcdResponse v1beta1.EtcdResponse
 	var err error
 	for i := 0; i < 20; i++ {
 		logrus.Debugf("trying to sync etcd config")
-		etcdResponse, err = e.JoinClient.JoinEtcd(peerURL)
+		etcdResponse, err = e.JoinClient.JoinEtcd(ctx, peerURL)
 		if err == nil {
 			break
 		}
@@ -140,6 +145,7 @@ func (e *Etcd) Start(ctx context.Context) error {
 
 	etcdCaCert := filepath.Join(e.K0sVars.EtcdCertDir, "ca.crt")
 	etcdCaCertKey := filepath.Join(e.K0sVars.EtcdCertDir, "ca.key")
+	etcdCaBundle := filepath.Join(e.K0sVars.EtcdCertDir, "ca-bundle.crt")
 	etcdServerCert := filepath.Join(e.K0sVars.EtcdCertDir, "server.crt")
 	etcdServerKey := filepath.Join(e.K0sVars.EtcdCertDir, "server.key")
 	etcdPeerCert := filepath.Join(e.K0sVars.EtcdCertDir, "peer.crt")
@@ -165,10 +171,10 @@ func (e *Etcd) Start(ctx context.Context) error {
 		"--initial-advertise-peer-urls": peerURL,
 		"--name":                        name,
 		"--tls-min-version":             string(tlsutil.TLSVersion12),
-		"--trusted-ca-file":             etcdCaCert,
+		"--trusted-ca-file":             etcdCaBundle,
 		"--cert-file":                   etcdServerCert,
 		"--key-file":                    etcdServerKey,
-		"--peer-trusted-ca-file":        etcdCaCert,
+		"--peer-trusted-ca-file":        etcdCaBundle,
 		"--peer-key-file":               etcdPeerKey,
 		"--peer-cert-file":              etcdPeerCert,
 		"--log-level":                   e.LogLevel,
@@ -179,7 +185,7 @@ func (e *Etcd) Start(ctx context.Context) error {
 	if file.Exists(filepath.Join(e.K0sVars.EtcdDataDir, "member", "snap", "db")) {
 		logrus.Warnf("etcd db file(s) already exist, not gonna run join process")
 	} else if e.JoinClient != nil {
-		initialCluster, err := e.syncEtcdConfig(peerURL, etcdCaCert, etcdCaCertKey)
+		initialCluster, err := e.syncEtcdConfig(ctx, peerURL, etcdCaCert, etcdCaCertKey)
 		if err != nil {
 			return fmt.Errorf("failed to sync etcd config: %w", err)
 		}
@@ -240,6 +246,12 @@ func (e *Etcd) setupCerts(ctx context.Context) error {
 	if err := e.CertManager.EnsureCA("etcd/ca", "etcd-ca"); err != nil {
 		return fmt.Errorf("failed to create etcd ca: %w", err)
 	}
+	if err := e.CertManager.ImportProviderCA(ctx, "etcd/ca", e.UseCertificateProvider); err != nil {
+		return err
+	}
+	if _, err := e.CertManager.WriteCABundle("etcd/ca"); err != nil {
+		return fmt.Errorf("failed to write etcd CA bundle: %w", err)
+	}
 
 	eg, _ := errgroup.WithContext(ctx)
 
@@ -263,15 +275,16 @@ func (e *Etcd) setupCerts(ctx context.Context) error {
 	eg.Go(func() error {
 		// etcd server cert
 		etcdCertReq := certificate.Request{
-			Name:   filepath.Join("etcd", "server"),
-			CN:     "etcd-server",
-			O:      "etcd-server",
-			CACert: etcdCaCert,
-			CAKey:  etcdCaCertKey,
-			Hostnames: []string{
+			Name:     filepath.Join("etcd", "server"),
+			CN:       "etcd-server",
+			O:        "etcd-server",
+			CACert:   etcdCaCert,
+			CAKey:    etcdCaCertKey,
+			Provided: e.UseCertificateProvider,
+			Hostnames: append([]string{
 				"127.0.0.1",
 				"localhost",
-			},
+			}, e.ServerSANs...),
 		}
 		_, err := e.CertManager.EnsureCertificate(etcdCertReq, constant.EtcdUser)
 		return err
@@ -279,14 +292,15 @@ func (e *Etcd) setupCerts(ctx context.Context) error {
 
 	eg.Go(func() error {
 		etcdPeerCertReq := certificate.Request{
-			Name:   filepath.Join("etcd", "peer"),
-			CN:     e.Config.PeerAddress,
-			O:      "etcd-peer",
-			CACert: etcdCaCert,
-			CAKey:  etcdCaCertKey,
-			Hostnames: []string{
+			Name:     filepath.Join("etcd", "peer"),
+			CN:       e.Config.PeerAddress,
+			O:        "etcd-peer",
+			CACert:   etcdCaCert,
+			CAKey:    etcdCaCertKey,
+			Provided: e.UseCertificateProvider,
+			Hostnames: append([]string{
 				e.Config.PeerAddress,
-			},
+			}, e.PeerSANs...),
 		}
 		_, err := e.CertManager.EnsureCertificate(etcdPeerCertReq, constant.EtcdUser)
 		return err
@@ -304,8 +318,12 @@ func (e *Etcd) Ready() error {
 	logrus.WithField("component", "etcd").Debug("checking etcd endpoint for health")
 	ctx, cancel := context.WithTimeout(e.ctx, 1*time.Second)
 	defer cancel()
-	err := etcd.CheckEtcdReady(ctx, e.K0sVars.CertRootDir, e.K0sVars.EtcdCertDir, e.Config)
-	return err
+	return e.Healthy(ctx)
+}
+
+// Healthy implements [prober.Healthz].
+func (e *Etcd) Healthy(ctx context.Context) error {
+	return etcd.CheckEtcdReady(ctx, e.K0sVars.CertRootDir, e.K0sVars.EtcdCertDir, e.Config)
 }
 
 func detectUnsupportedEtcdArch() error {
@@ -0,0 +1,195 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+	"github.com/k0sproject/k0s/pkg/kubernetes/watch"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// eventForwarderCheckInterval is the interval in which the forwarder
+	// checks if it should be watching events, i.e. if this controller is the
+	// leader and if there are any sinks configured.
+	eventForwarderCheckInterval = 10 * time.Second
+	// eventForwarderSendTimeout is the time after which sending an event to a
+	// sink is given up.
+	eventForwarderSendTimeout = 10 * time.Second
+)
+
+// EventForwarder forwards the Kubernetes events that are reported by k0s, such
+// as config reconciliation results, autopilot progress and component failures,
+// to the external sinks defined in the cluster config. Events are only
+// forwarded by the leading controller, starting from the time it acquired the
+// lead.
+type EventForwarder struct {
+	log *logrus.Entry
+
+	leaderElector     leaderelector.Interface
+	kubeClientFactory kubeutil.ClientFactoryInterface
+
+	mu    sync.Mutex
+	spec  *v1beta1.EventForwardingSpec
+	sinks map[string]eventSink
+	since time.Time
+
+	stop func()
+}
+
+var (
+	_ manager.Component  = (*EventForwarder)(nil)
+	_ manager.Reconciler = (*EventForwarder)(nil)
+)
+
+// NewEventForwarder creates a new event forwarder.
+func NewEventForwarder(leaderElector leaderelector.Interface, kubeClientFactory kubeutil.ClientFactoryInterface) *EventForwarder {
+	return &EventForwarder{
+		log:               logrus.WithField("component", "event-forwarder"),
+		leaderElector:     leaderElector,
+		kubeClientFactory: kubeClientFactory,
+	}
+}
+
+func (f *EventForwarder) Init(context.Context) error {
+	return nil
+}
+
+func (f *EventForwarder) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait.UntilWithContext(ctx, f.run, eventForwarderCheckInterval)
+	}()
+
+	f.stop = func() { cancel(); <-done }
+	return nil
+}
+
+func (f *EventForwarder) Stop() error {
+	if f.stop != nil {
+		f.stop()
+	}
+	return nil
+}
+
+// Reconcile implements [manager.Reconciler].
+func (f *EventForwarder) Reconcile(_ context.Context, cfg *v1beta1.ClusterConfig) error {
+	spec := cfg.Spec.EventForwarding
+	sinks := make(map[string]eventSink)
+	if spec.IsEnabled() {
+		for i := range spec.Sinks {
+			sink, err := newEventSink(&spec.Sinks[i])
+			if err != nil {
+				return err
+			}
+			sinks[spec.Sinks[i].Name] = sink
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.spec, f.sinks = spec.DeepCopy(), sinks
+	return nil
+}
+
+// run watches and forwards events for as long as this controller is the
+// leader and there are sinks configured.
+func (f *EventForwarder) run(ctx context.Context) {
+	if !f.isActive() {
+		f.mu.Lock()
+		f.since = time.Time{}
+		f.mu.Unlock()
+		return
+	}
+
+	f.mu.Lock()
+	if f.since.IsZero() {
+		f.since = time.Now()
+	}
+	f.mu.Unlock()
+
+	client, err := f.kubeClientFactory.GetClient()
+	if err != nil {
+		f.log.WithError(err).Error("Failed to get Kubernetes client")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		wait.UntilWithContext(ctx, func(context.Context) {
+			if !f.isActive() {
+				cancel()
+			}
+		}, eventForwarderCheckInterval)
+	}()
+
+	err = watch.Events(client.CoreV1().Events(metav1.NamespaceAll)).
+		WithFieldSelector(fields.OneTermEqualSelector("reportingComponent", "k0s-controller")).
+		WithErrorCallback(watch.IsRetryable).
+		Until(ctx, func(event *corev1.Event) (bool, error) {
+			f.forward(ctx, event)
+			return false, nil
+		})
+	if err != nil && ctx.Err() == nil {
+		f.log.WithError(err).Error("Failed to watch events")
+	}
+}
+
+// isActive checks if events should currently be forwarded.
+func (f *EventForwarder) isActive() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.spec.IsEnabled() && f.leaderElector.IsLeader()
+}
+
+// forward sends the given event to all sinks, unless it has been observed
+// before the forwarder started or has already been forwarded.
+func (f *EventForwarder) forward(ctx context.Context, event *corev1.Event) {
+	f.mu.Lock()
+	t := eventTime(event)
+	if !t.After(f.since) || !f.spec.IsEnabled() || !f.spec.Includes(event.Type) {
+		f.mu.Unlock()
+		return
+	}
+	f.since = t
+	sinks := f.sinks
+	f.mu.Unlock()
+
+	for name, sink := range sinks {
+		sendCtx, cancel := context.WithTimeout(ctx, eventForwarderSendTimeout)
+		if err := sink.send(sendCtx, event); err != nil {
+			f.log.WithError(err).Errorf("Failed to forward event %s to sink %s", event.Name, name)
+		}
+		cancel()
+	}
+}
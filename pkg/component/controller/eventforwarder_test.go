@@ -0,0 +1,164 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newTestEvent(eventType string, t time.Time) *corev1.Event {
+	return &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{Name: "k0s.abc"},
+		EventTime:  metav1.NewMicroTime(t),
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "ClusterConfig",
+			Name: "k0s",
+		},
+		Type:                eventType,
+		Reason:              "FailedReconciling",
+		Message:             "something went wrong",
+		ReportingController: "k0s-controller",
+		ReportingInstance:   "controller-0",
+	}
+}
+
+func TestWebhookEventSink(t *testing.T) {
+	received := make(chan map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		var payload map[string]any
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		received <- payload
+	}))
+	defer server.Close()
+
+	sink, err := newEventSink(&v1beta1.EventSink{
+		Name:    "webhook",
+		Webhook: &v1beta1.WebhookEventSink{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer secret"}},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2023, 5, 4, 3, 2, 1, 0, time.UTC)
+	require.NoError(t, sink.send(context.TODO(), newTestEvent(corev1.EventTypeWarning, now)))
+	assert.Equal(t, map[string]any{
+		"time":              "2023-05-04T03:02:01Z",
+		"type":              "Warning",
+		"reason":            "FailedReconciling",
+		"message":           "something went wrong",
+		"kind":              "ClusterConfig",
+		"name":              "k0s",
+		"reportingInstance": "controller-0",
+	}, <-received)
+}
+
+func TestSlackEventSink(t *testing.T) {
+	var text string
+	authorized := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized {
+			http.Error(w, "invalid_token", http.StatusForbidden)
+			return
+		}
+		var payload struct{ Text string }
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+		text = payload.Text
+	}))
+	defer server.Close()
+
+	sink := &slackEventSink{url: server.URL}
+	require.NoError(t, sink.send(context.TODO(), newTestEvent(corev1.EventTypeWarning, time.Now())))
+	assert.Equal(t, ":warning: *FailedReconciling* ClusterConfig/k0s: something went wrong (reported by controller-0)", text)
+
+	authorized = false
+	assert.ErrorContains(t, sink.send(context.TODO(), newTestEvent(corev1.EventTypeNormal, time.Now())), "unexpected response: 403 Forbidden")
+}
+
+func TestSyslogEventSink(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	sink, err := newEventSink(&v1beta1.EventSink{
+		Name:   "syslog",
+		Syslog: &v1beta1.SyslogEventSink{Address: "udp://" + conn.LocalAddr().String()},
+	})
+	require.NoError(t, err)
+
+	now := time.Date(2023, 5, 4, 3, 2, 1, 0, time.UTC)
+	require.NoError(t, sink.send(context.TODO(), newTestEvent(corev1.EventTypeWarning, now)))
+
+	buf := make([]byte, 1024)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(10*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "<28>1 2023-05-04T03:02:01.000000Z controller-0 k0s - FailedReconciling - ClusterConfig/k0s: something went wrong", string(buf[:n]))
+}
+
+type recordingEventSink []*corev1.Event
+
+func (s *recordingEventSink) send(_ context.Context, event *corev1.Event) error {
+	*s = append(*s, event)
+	return nil
+}
+
+func TestEventForwarder_Forward(t *testing.T) {
+	start := time.Now()
+	sink := new(recordingEventSink)
+	f := &EventForwarder{
+		log:           logrus.WithField("test", t.Name()),
+		leaderElector: &leaderelector.Dummy{Leader: true},
+		spec: &v1beta1.EventForwardingSpec{
+			Types: []string{corev1.EventTypeWarning},
+			Sinks: []v1beta1.EventSink{{Name: "recording"}},
+		},
+		sinks: map[string]eventSink{"recording": sink},
+		since: start,
+	}
+	assert.True(t, f.isActive())
+
+	old := newTestEvent(corev1.EventTypeWarning, start.Add(-time.Second))
+	normal := newTestEvent(corev1.EventTypeNormal, start.Add(time.Second))
+	warning := newTestEvent(corev1.EventTypeWarning, start.Add(2*time.Second))
+
+	f.forward(context.TODO(), old)
+	f.forward(context.TODO(), normal)
+	f.forward(context.TODO(), warning)
+	// Events are forwarded only once, e.g. when the watch is restarted.
+	f.forward(context.TODO(), warning)
+
+	assert.Equal(t, []*corev1.Event{warning}, []*corev1.Event(*sink))
+
+	require.NoError(t, f.Reconcile(context.TODO(), &v1beta1.ClusterConfig{Spec: &v1beta1.ClusterSpec{}}))
+	assert.False(t, f.isActive())
+}
@@ -0,0 +1,208 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// eventSink sends events to an external system.
+type eventSink interface {
+	send(context.Context, *corev1.Event) error
+}
+
+// newEventSink creates the sink for the given spec. The spec is expected to
+// have been validated.
+func newEventSink(spec *v1beta1.EventSink) (eventSink, error) {
+	switch {
+	case spec.Webhook != nil:
+		return &webhookEventSink{url: spec.Webhook.URL, headers: spec.Webhook.Headers}, nil
+	case spec.Slack != nil:
+		return &slackEventSink{url: spec.Slack.WebhookURL}, nil
+	case spec.Syslog != nil:
+		u, err := url.Parse(spec.Syslog.Address)
+		if err != nil {
+			return nil, err
+		}
+		return &syslogEventSink{network: u.Scheme, address: u.Host}, nil
+	default:
+		return nil, fmt.Errorf("event sink %q has no destination", spec.Name)
+	}
+}
+
+// forwardedEvent is the JSON representation of events sent to webhooks.
+type forwardedEvent struct {
+	Time              time.Time `json:"time"`
+	Type              string    `json:"type"`
+	Reason            string    `json:"reason"`
+	Message           string    `json:"message"`
+	Kind              string    `json:"kind"`
+	Name              string    `json:"name"`
+	Namespace         string    `json:"namespace,omitempty"`
+	ReportingInstance string    `json:"reportingInstance,omitempty"`
+}
+
+type webhookEventSink struct {
+	url     string
+	headers map[string]string
+}
+
+func (s *webhookEventSink) send(ctx context.Context, event *corev1.Event) error {
+	return postJSON(ctx, s.url, s.headers, &forwardedEvent{
+		Time:              eventTime(event),
+		Type:              event.Type,
+		Reason:            event.Reason,
+		Message:           event.Message,
+		Kind:              event.InvolvedObject.Kind,
+		Name:              event.InvolvedObject.Name,
+		Namespace:         event.InvolvedObject.Namespace,
+		ReportingInstance: event.ReportingInstance,
+	})
+}
+
+type slackEventSink struct {
+	url string
+}
+
+func (s *slackEventSink) send(ctx context.Context, event *corev1.Event) error {
+	icon := ":information_source:"
+	if event.Type == corev1.EventTypeWarning {
+		icon = ":warning:"
+	}
+	text := fmt.Sprintf("%s *%s* %s/%s: %s", icon, event.Reason, event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Message)
+	if event.ReportingInstance != "" {
+		text += fmt.Sprintf(" (reported by %s)", event.ReportingInstance)
+	}
+	return postJSON(ctx, s.url, nil, map[string]string{"text": text})
+}
+
+func postJSON(ctx context.Context, url string, headers map[string]string, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected response: %s", resp.Status)
+	}
+	return nil
+}
+
+// syslogEventSink sends events as RFC 5424 messages. It's implemented here,
+// as the standard library's syslog package isn't available on Windows.
+type syslogEventSink struct {
+	network, address string
+}
+
+const (
+	syslogFacilityDaemon  = 3
+	syslogSeverityWarning = 4
+	syslogSeverityInfo    = 6
+	syslogTimestampFormat = "2006-01-02T15:04:05.000000Z07:00"
+	syslogNilValue        = "-"
+)
+
+func (s *syslogEventSink) send(ctx context.Context, event *corev1.Event) error {
+	msg := formatSyslogMessage(event)
+	if s.network == "tcp" {
+		// Octet counting framing, as of RFC 6587.
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.network, s.address)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetWriteDeadline(deadline); err != nil {
+			return err
+		}
+	}
+	_, err = conn.Write([]byte(msg))
+	return err
+}
+
+func formatSyslogMessage(event *corev1.Event) string {
+	severity := syslogSeverityInfo
+	if event.Type == corev1.EventTypeWarning {
+		severity = syslogSeverityWarning
+	}
+
+	hostname := event.ReportingInstance
+	if hostname == "" {
+		hostname, _ = os.Hostname()
+	}
+	if hostname == "" {
+		hostname = syslogNilValue
+	}
+
+	msgID := event.Reason
+	if msgID == "" || len(msgID) > 32 || strings.ContainsAny(msgID, " \t") {
+		msgID = syslogNilValue
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s %s %s %s %s/%s: %s",
+		syslogFacilityDaemon*8+severity,
+		eventTime(event).UTC().Format(syslogTimestampFormat),
+		hostname,
+		"k0s",          // APP-NAME
+		syslogNilValue, // PROCID
+		msgID,
+		syslogNilValue, // STRUCTURED-DATA
+		event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Message,
+	)
+}
+
+// eventTime returns the time at which the given event was observed.
+func eventTime(event *corev1.Event) time.Time {
+	switch {
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
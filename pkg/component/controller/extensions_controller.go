@@ -19,7 +19,11 @@ package controller
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/avast/retry-go"
@@ -34,13 +38,18 @@ import (
 	"github.com/k0sproject/k0s/pkg/helm"
 	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
 	"github.com/sirupsen/logrus"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/config"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	ctrlManager "sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -56,6 +65,7 @@ type ExtensionsController struct {
 	helm             *helm.Commands
 	kubeConfig       string
 	leaderElector    leaderelector.Interface
+	clientFactory    kubeutil.ClientFactoryInterface
 }
 
 var _ manager.Component = (*ExtensionsController)(nil)
@@ -63,18 +73,29 @@ var _ manager.Reconciler = (*ExtensionsController)(nil)
 
 // NewExtensionsController builds new HelmAddons
 func NewExtensionsController(s manifestsSaver, k0sVars constant.CfgVars, kubeClientFactory kubeutil.ClientFactoryInterface, leaderElector leaderelector.Interface, concurrencyLevel int) *ExtensionsController {
+	helmCommands := helm.NewCommands(k0sVars)
+	helmCommands.Credentials = &secretCredentialsProvider{kubeClientFactory}
+	if concurrencyLevel <= 0 {
+		concurrencyLevel = defaultConcurrencyLevel
+	}
+
 	return &ExtensionsController{
 		concurrencyLevel: concurrencyLevel,
 		saver:            s,
 		L:                logrus.WithFields(logrus.Fields{"component": "extensions_controller"}),
-		helm:             helm.NewCommands(k0sVars),
+		helm:             helmCommands,
 		kubeConfig:       k0sVars.AdminKubeConfigPath,
 		leaderElector:    leaderElector,
+		clientFactory:    kubeClientFactory,
 	}
 }
 
 const (
 	namespaceToWatch = "kube-system"
+
+	// defaultConcurrencyLevel is the default number of charts that are
+	// reconciled concurrently.
+	defaultConcurrencyLevel = 10
 )
 
 // Run runs the extensions controller
@@ -93,7 +114,7 @@ func (ec *ExtensionsController) Reconcile(ctx context.Context, clusterConfig *k0
 	default:
 	}
 
-	if err := ec.reconcileHelmExtensions(helmSettings); err != nil {
+	if err := ec.reconcileHelmExtensions(ctx, helmSettings); err != nil {
 		return fmt.Errorf("can't reconcile helm based extensions: %w", err)
 	}
 
@@ -146,13 +167,21 @@ func yamlifyValues(values map[string]interface{}) (string, error) {
 // reconcileHelmExtensions creates instance of Chart CR for each chart of the config file
 // it also reconciles repositories settings
 // the actual helm install/update/delete management is done by ChartReconciler structure
-func (ec *ExtensionsController) reconcileHelmExtensions(helmSpec *k0sAPI.HelmExtensions) error {
+func (ec *ExtensionsController) reconcileHelmExtensions(ctx context.Context, helmSpec *k0sAPI.HelmExtensions) error {
 	if helmSpec == nil {
 		return nil
 	}
 
+	client, err := ec.clientFactory.GetClient()
+	if err != nil {
+		return err
+	}
+	if err := reconcileRepositorySecrets(ctx, client, helmSpec.Repositories); err != nil {
+		return err
+	}
+
 	for _, repo := range helmSpec.Repositories {
-		if err := ec.addRepo(repo); err != nil {
+		if err := ec.addRepo(ctx, repo); err != nil {
 			return fmt.Errorf("can't init repository %q: %w", repo.URL, err)
 		}
 	}
@@ -215,12 +244,27 @@ func (cr *ChartReconciler) Reconcile(ctx context.Context, req reconcile.Request)
 		}
 		return reconcile.Result{}, nil
 	}
+	if chartInstance.Status.ReleaseName == "" || cr.chartNeedsUpgrade(chartInstance) {
+		pending, err := pendingDependencies(ctx, cr.Client, chartInstance)
+		if err != nil {
+			return reconcile.Result{}, fmt.Errorf("can't check dependencies: %w", err)
+		}
+		if len(pending) > 0 {
+			cr.L.Infof("Waiting for dependencies of %s: %s", req, strings.Join(pending, ", "))
+			cr.markWaitingForDependencies(ctx, &chartInstance, pending)
+			return reconcile.Result{RequeueAfter: dependencyRetryInterval}, nil
+		}
+	}
+
 	cr.L.Debugf("Install or update reconciliation request: %s", req)
 	if err := cr.updateOrInstallChart(ctx, chartInstance); err != nil {
 		return reconcile.Result{Requeue: true}, fmt.Errorf("can't update or install chart: %w", err)
 	}
 
 	cr.L.Debugf("Installed or updated reconciliation request: %s", req)
+	if chartInstance.Spec.GetDriftPolicy() != v1beta1.DriftPolicyIgnore {
+		return reconcile.Result{RequeueAfter: driftCheckInterval}, nil
+	}
 	return reconcile.Result{}, nil
 }
 func (cr *ChartReconciler) uninstall(ctx context.Context, chart v1beta1.Chart) error {
@@ -235,8 +279,8 @@ const defaultTimeout = time.Duration(10 * time.Minute)
 func (cr *ChartReconciler) updateOrInstallChart(ctx context.Context, chart v1beta1.Chart) error {
 	var err error
 	var chartRelease *release.Release
-	timeout, err := time.ParseDuration(chart.Spec.Timeout)
-	if err != nil {
+	timeout, parseErr := time.ParseDuration(chart.Spec.Timeout)
+	if parseErr != nil {
 		cr.L.Tracef("Can't parse `%s` as time.Duration, using default timeout `%s`", chart.Spec.Timeout, defaultTimeout)
 		timeout = defaultTimeout
 	}
@@ -244,6 +288,7 @@ func (cr *ChartReconciler) updateOrInstallChart(ctx context.Context, chart v1bet
 		cr.L.Tracef("Using default timeout `%s`, failed to parse `%s`", defaultTimeout, chart.Spec.Timeout)
 		timeout = defaultTimeout
 	}
+	opts := releaseOptions(chart.Spec, timeout)
 	defer func() {
 		if err != nil {
 			cr.updateStatus(ctx, chart, chartRelease, err)
@@ -252,12 +297,13 @@ func (cr *ChartReconciler) updateOrInstallChart(ctx context.Context, chart v1bet
 	if chart.Status.ReleaseName == "" {
 		// new chartRelease
 		cr.L.Tracef("Start update or install %s", chart.Spec.ChartName)
-		chartRelease, err = cr.helm.InstallChart(chart.Spec.ChartName,
+		cr.markUpgrading(ctx, &chart, "Installing", fmt.Sprintf("Installing chart %s", chart.Spec.ChartName))
+		chartRelease, err = cr.helm.InstallChart(ctx, chart.Spec.ChartName,
 			chart.Spec.Version,
 			chart.Spec.ReleaseName,
 			chart.Spec.Namespace,
 			chart.Spec.YamlValues(),
-			timeout,
+			opts,
 		)
 		if err != nil {
 			return fmt.Errorf("can't reconcile installation for %q: %w", chart.GetName(), err)
@@ -265,22 +311,67 @@ func (cr *ChartReconciler) updateOrInstallChart(ctx context.Context, chart v1bet
 	} else {
 		if cr.chartNeedsUpgrade(chart) {
 			// update
-			chartRelease, err = cr.helm.UpgradeChart(chart.Spec.ChartName,
+			cr.markUpgrading(ctx, &chart, "Upgrading", fmt.Sprintf("Upgrading release %s", chart.Status.ReleaseName))
+			chartRelease, err = cr.helm.UpgradeChart(ctx, chart.Spec.ChartName,
 				chart.Status.Version,
 				chart.Status.ReleaseName,
 				chart.Status.Namespace,
 				chart.Spec.YamlValues(),
-				timeout,
+				opts,
 			)
 			if err != nil {
 				return fmt.Errorf("can't reconcile upgrade for %q: %w", chart.GetName(), err)
 			}
+		} else if policy := chart.Spec.GetDriftPolicy(); policy != v1beta1.DriftPolicyIgnore {
+			var drift []string
+			drift, err = cr.detectDrift(ctx, chart)
+			if err != nil {
+				return fmt.Errorf("can't detect drift for %q: %w", chart.GetName(), err)
+			}
+			if len(drift) > 0 && policy == v1beta1.DriftPolicyRemediate {
+				cr.L.Infof("Release %s/%s drifted, upgrading: %v", chart.Status.Namespace, chart.Status.ReleaseName, drift)
+				cr.markUpgrading(ctx, &chart, "RemediatingDrift", fmt.Sprintf("Upgrading drifted release %s", chart.Status.ReleaseName))
+				chartRelease, err = cr.helm.UpgradeChart(ctx, chart.Spec.ChartName,
+					chart.Status.Version,
+					chart.Status.ReleaseName,
+					chart.Status.Namespace,
+					chart.Spec.YamlValues(),
+					opts,
+				)
+				if err != nil {
+					return fmt.Errorf("can't remediate drift for %q: %w", chart.GetName(), err)
+				}
+				drift = nil
+			}
+			chart.Status.Drift = drift
 		}
 	}
 	cr.updateStatus(ctx, chart, chartRelease, nil)
 	return nil
 }
 
+// detectDrift returns the resources of the chart's release whose live state
+// differs from the release manifest.
+func (cr *ChartReconciler) detectDrift(ctx context.Context, chart v1beta1.Chart) ([]string, error) {
+	chartRelease, err := cr.helm.GetRelease(chart.Status.ReleaseName, chart.Status.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("can't get release: %w", err)
+	}
+	return findDrift(ctx, cr.Client, chartRelease.Manifest, chartRelease.Namespace)
+}
+
+// releaseOptions returns the helm options for installing or upgrading the
+// release of the given chart.
+func releaseOptions(spec v1beta1.ChartSpec, timeout time.Duration) helm.ReleaseOptions {
+	return helm.ReleaseOptions{
+		Timeout:      timeout,
+		Atomic:       spec.IsAtomic(),
+		Wait:         spec.ShouldWait(),
+		SkipCRDs:     spec.SkipCRDs,
+		DisableHooks: spec.DisableHooks,
+	}
+}
+
 func (cr *ChartReconciler) chartNeedsUpgrade(chart v1beta1.Chart) bool {
 	return !(chart.Status.Namespace == chart.Spec.Namespace &&
 		chart.Status.ReleaseName == chart.Spec.ReleaseName &&
@@ -288,6 +379,21 @@ func (cr *ChartReconciler) chartNeedsUpgrade(chart v1beta1.Chart) bool {
 		chart.Status.ValuesHash == chart.Spec.HashValues())
 }
 
+// markUpgrading sets the Upgrading condition of the chart before a release is
+// installed or upgraded, which may take a while.
+func (cr *ChartReconciler) markUpgrading(ctx context.Context, chart *v1beta1.Chart, reason, message string) {
+	meta.SetStatusCondition(&chart.Status.Conditions, metav1.Condition{
+		Type:               v1beta1.ConditionUpgrading,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: chart.Generation,
+		Reason:             reason,
+		Message:            message,
+	})
+	if err := cr.Client.Status().Update(ctx, chart); err != nil {
+		cr.L.WithError(err).Warn("Failed to update status for chart release ", chart.Name)
+	}
+}
+
 func (cr *ChartReconciler) updateStatus(ctx context.Context, chart v1beta1.Chart, chartRelease *release.Release, err error) {
 
 	chart.Spec.YamlValues()
@@ -297,6 +403,13 @@ func (cr *ChartReconciler) updateStatus(ctx context.Context, chart v1beta1.Chart
 		chart.Status.AppVersion = chartRelease.Chart.AppVersion()
 		chart.Status.Revision = int64(chartRelease.Version)
 		chart.Status.Namespace = chartRelease.Namespace
+		chart.Status.RenderedValuesHash = renderedValuesHash(chartRelease)
+		if chartRelease.Info != nil {
+			chart.Status.Status = chartRelease.Info.Status.String()
+			chart.Status.LastDeployed = &metav1.Time{Time: chartRelease.Info.LastDeployed.Time}
+		}
+		// A new revision has not yet been checked for drift
+		chart.Status.Drift = nil
 	}
 	chart.Status.Updated = time.Now().String()
 	if err != nil {
@@ -305,13 +418,109 @@ func (cr *ChartReconciler) updateStatus(ctx context.Context, chart v1beta1.Chart
 		chart.Status.Error = ""
 	}
 	chart.Status.ValuesHash = chart.Spec.HashValues()
+	setChartConditions(&chart.Status, chart.Generation, err)
+	if len(chart.Spec.DependsOn) > 0 {
+		meta.SetStatusCondition(&chart.Status.Conditions, metav1.Condition{
+			Type:               v1beta1.ConditionDependenciesReady,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: chart.Generation,
+			Reason:             "Ready",
+		})
+	} else {
+		meta.RemoveStatusCondition(&chart.Status.Conditions, v1beta1.ConditionDependenciesReady)
+	}
+	if chart.Status.ReleaseName != "" {
+		history, histErr := cr.helm.History(chart.Status.ReleaseName, chart.Status.Namespace)
+		if histErr != nil {
+			cr.L.WithError(histErr).Warn("Failed to get history for chart release ", chart.Name)
+		} else {
+			chart.Status.History = chartRevisions(history, maxChartRevisions)
+		}
+	}
 	if updErr := cr.Client.Status().Update(ctx, &chart); updErr != nil {
 		cr.L.WithError(updErr).Error("Failed to update status for chart release", chart.Name)
 	}
 }
 
-func (ec *ExtensionsController) addRepo(repo k0sAPI.Repository) error {
-	return ec.helm.AddRepository(repo)
+// maxChartRevisions is the number of release revisions kept in the status of
+// a chart.
+const maxChartRevisions = 10
+
+// setChartConditions sets the conditions of a chart after a reconciliation,
+// which failed if err is not nil.
+func setChartConditions(status *v1beta1.ChartStatus, generation int64, err error) {
+	set := func(conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	set(v1beta1.ConditionUpgrading, metav1.ConditionFalse, "Finished", "")
+	deployed := fmt.Sprintf("Revision %d of release %s is deployed", status.Revision, status.ReleaseName)
+
+	if err == nil {
+		set(v1beta1.ConditionFailed, metav1.ConditionFalse, "Succeeded", "")
+		set(v1beta1.ConditionInstalled, metav1.ConditionTrue, "Deployed", deployed)
+		return
+	}
+
+	set(v1beta1.ConditionFailed, metav1.ConditionTrue, "ReconcileFailed", err.Error())
+	if status.ReleaseName == "" {
+		set(v1beta1.ConditionInstalled, metav1.ConditionFalse, "InstallFailed", err.Error())
+	} else if meta.FindStatusCondition(status.Conditions, v1beta1.ConditionInstalled) == nil {
+		set(v1beta1.ConditionInstalled, metav1.ConditionTrue, "Deployed", deployed)
+	}
+}
+
+// chartRevisions converts the given release revisions into the chart's
+// history, keeping the newest max revisions.
+func chartRevisions(releases []*release.Release, max int) []v1beta1.ChartRevision {
+	releases = append([]*release.Release(nil), releases...)
+	sort.Slice(releases, func(i, j int) bool { return releases[i].Version > releases[j].Version })
+	if len(releases) > max {
+		releases = releases[:max]
+	}
+
+	revisions := make([]v1beta1.ChartRevision, 0, len(releases))
+	for _, r := range releases {
+		revision := v1beta1.ChartRevision{Revision: int64(r.Version)}
+		if r.Chart != nil && r.Chart.Metadata != nil {
+			revision.Version = r.Chart.Metadata.Version
+			revision.AppVersion = r.Chart.Metadata.AppVersion
+		}
+		if r.Info != nil {
+			revision.Status = r.Info.Status.String()
+			revision.Description = r.Info.Description
+			revision.Updated = &metav1.Time{Time: r.Info.LastDeployed.Time}
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions
+}
+
+// renderedValuesHash returns the checksum of the values the given release was
+// rendered with, i.e. its configured values merged with the chart's defaults.
+func renderedValuesHash(r *release.Release) string {
+	if r.Chart == nil {
+		return ""
+	}
+	values, err := chartutil.CoalesceValues(r.Chart, r.Config)
+	if err != nil {
+		return ""
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+func (ec *ExtensionsController) addRepo(ctx context.Context, repo k0sAPI.Repository) error {
+	return ec.helm.AddRepository(ctx, repo)
 }
 
 const chartCrdTemplate = `
@@ -330,10 +539,43 @@ spec:
 {{ .Values | nindent 4 }}
   version: {{ .Version }}
   namespace: {{ .TargetNS }}
+{{- with .Atomic }}
+  atomic: {{ . }}
+{{- end }}
+{{- with .Wait }}
+  wait: {{ . }}
+{{- end }}
+{{- if .SkipCRDs }}
+  skipCRDs: true
+{{- end }}
+{{- if .DisableHooks }}
+  disableHooks: true
+{{- end }}
+{{- with .DriftPolicy }}
+  driftPolicy: {{ . }}
+{{- end }}
+{{- with .DependsOn }}
+  dependsOn:
+{{- range . }}
+{{- if .Chart }}
+  - chart: k0s-addon-chart-{{ .Chart }}
+{{- else }}
+  - component: {{ .Component }}
+{{- end }}
+{{- end }}
+{{- end }}
 `
 
 const finalizerName = "helm.k0sproject.io/uninstall-helm-release"
 
+// newChartRateLimiter returns the rate limiter of the chart reconciliation
+// queue. Failing charts are retried with a per-chart exponential backoff,
+// starting at five seconds and growing up to ten minutes, so that they don't
+// hold up the reconciliation of other charts.
+func newChartRateLimiter() workqueue.RateLimiter {
+	return workqueue.NewItemExponentialFailureRateLimiter(5*time.Second, 10*time.Minute)
+}
+
 // Init
 func (ec *ExtensionsController) Init(_ context.Context) error {
 	return nil
@@ -353,9 +595,6 @@ func (ec *ExtensionsController) Start(ctx context.Context) error {
 	mgr, err := ctrlManager.New(clientConfig, ctrlManager.Options{
 		MetricsBindAddress: "0",
 		Logger:             logrusr.New(ec.L),
-		Controller: config.Controller{
-			GroupKindConcurrency: map[string]int{gk.String(): 10},
-		},
 	})
 	if err != nil {
 		return fmt.Errorf("can't build controller-runtime controller for helm extensions: %w", err)
@@ -388,6 +627,12 @@ func (ec *ExtensionsController) Start(ctx context.Context) error {
 			),
 			),
 		).
+		WithOptions(controller.Options{
+			Controller: config.Controller{
+				MaxConcurrentReconciles: ec.concurrencyLevel,
+			},
+			RateLimiter: newChartRateLimiter(),
+		}).
 		Complete(&ChartReconciler{
 			Client:        mgr.GetClient(),
 			leaderElector: ec.leaderElector, // TODO: drop in favor of controller-runtime lease manager?
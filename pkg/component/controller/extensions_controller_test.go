@@ -17,10 +17,25 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
+	"errors"
 	"testing"
+	"time"
 
+	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
 	"github.com/k0sproject/k0s/pkg/apis/helm/v1beta1"
+	k0sAPI "github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+	"github.com/k0sproject/k0s/pkg/helm"
+
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
 )
 
 func TestChartNeedsUpgrade(t *testing.T) {
@@ -134,3 +149,210 @@ func TestChartNeedsUpgrade(t *testing.T) {
 		})
 	}
 }
+
+func TestReleaseOptions(t *testing.T) {
+	yes, no := true, false
+
+	var testCases = []struct {
+		description string
+		spec        v1beta1.ChartSpec
+		expected    helm.ReleaseOptions
+	}{
+		{
+			"defaults",
+			v1beta1.ChartSpec{},
+			helm.ReleaseOptions{Timeout: time.Minute, Atomic: true, Wait: true},
+		},
+		{
+			"non_atomic",
+			v1beta1.ChartSpec{Atomic: &no},
+			helm.ReleaseOptions{Timeout: time.Minute, Wait: true},
+		},
+		{
+			"no_wait",
+			v1beta1.ChartSpec{Atomic: &no, Wait: &no},
+			helm.ReleaseOptions{Timeout: time.Minute},
+		},
+		{
+			"atomic_implies_wait",
+			v1beta1.ChartSpec{Atomic: &yes, Wait: &no},
+			helm.ReleaseOptions{Timeout: time.Minute, Atomic: true, Wait: true},
+		},
+		{
+			"skip_crds_and_hooks",
+			v1beta1.ChartSpec{SkipCRDs: true, DisableHooks: true},
+			helm.ReleaseOptions{Timeout: time.Minute, Atomic: true, Wait: true, SkipCRDs: true, DisableHooks: true},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.description, func(t *testing.T) {
+			assert.Equal(t, tc.expected, releaseOptions(tc.spec, time.Minute))
+		})
+	}
+}
+
+func TestChartCrdTemplate(t *testing.T) {
+	render := func(t *testing.T, chart k0sAPI.Chart) v1beta1.ChartSpec {
+		tw := templatewriter.TemplateWriter{
+			Name:     "addon_crd_manifest",
+			Template: chartCrdTemplate,
+			Data: struct {
+				k0sAPI.Chart
+				Finalizer string
+			}{
+				Chart:     chart,
+				Finalizer: finalizerName,
+			},
+		}
+		var buf bytes.Buffer
+		require.NoError(t, tw.WriteToBuffer(&buf))
+
+		var rendered v1beta1.Chart
+		require.NoError(t, yaml.Unmarshal(buf.Bytes(), &rendered))
+		return rendered.Spec
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		spec := render(t, k0sAPI.Chart{Name: "test", ChartName: "repo/test", TargetNS: "ns"})
+		assert.Nil(t, spec.Atomic)
+		assert.Nil(t, spec.Wait)
+		assert.False(t, spec.SkipCRDs)
+		assert.False(t, spec.DisableHooks)
+	})
+
+	t.Run("options", func(t *testing.T) {
+		no := false
+		spec := render(t, k0sAPI.Chart{
+			Name: "test", ChartName: "repo/test", TargetNS: "ns",
+			Atomic: &no, Wait: &no, SkipCRDs: true, DisableHooks: true,
+		})
+		if assert.NotNil(t, spec.Atomic) {
+			assert.False(t, *spec.Atomic)
+		}
+		if assert.NotNil(t, spec.Wait) {
+			assert.False(t, *spec.Wait)
+		}
+		assert.True(t, spec.SkipCRDs)
+		assert.True(t, spec.DisableHooks)
+	})
+
+	t.Run("dependencies", func(t *testing.T) {
+		spec := render(t, k0sAPI.Chart{
+			Name: "test", ChartName: "repo/test", TargetNS: "ns",
+			DependsOn: []k0sAPI.ChartDependency{{Chart: "cert-manager"}, {Component: "network"}},
+		})
+		assert.Equal(t, []v1beta1.ChartDependency{
+			{Chart: "k0s-addon-chart-cert-manager"},
+			{Component: v1beta1.ComponentNetwork},
+		}, spec.DependsOn)
+	})
+}
+
+func TestSetChartConditions(t *testing.T) {
+	conditionStatus := func(status *v1beta1.ChartStatus, conditionType string) metav1.ConditionStatus {
+		condition := meta.FindStatusCondition(status.Conditions, conditionType)
+		if condition == nil {
+			return ""
+		}
+		return condition.Status
+	}
+
+	t.Run("install_failed", func(t *testing.T) {
+		var status v1beta1.ChartStatus
+		setChartConditions(&status, 1, errors.New("boom"))
+		assert.Equal(t, metav1.ConditionFalse, conditionStatus(&status, v1beta1.ConditionInstalled))
+		assert.Equal(t, metav1.ConditionFalse, conditionStatus(&status, v1beta1.ConditionUpgrading))
+		assert.Equal(t, metav1.ConditionTrue, conditionStatus(&status, v1beta1.ConditionFailed))
+		assert.Equal(t, "boom", meta.FindStatusCondition(status.Conditions, v1beta1.ConditionFailed).Message)
+	})
+
+	t.Run("installed", func(t *testing.T) {
+		status := v1beta1.ChartStatus{ReleaseName: "test", Revision: 2}
+		setChartConditions(&status, 3, nil)
+		assert.Equal(t, metav1.ConditionTrue, conditionStatus(&status, v1beta1.ConditionInstalled))
+		assert.Equal(t, metav1.ConditionFalse, conditionStatus(&status, v1beta1.ConditionFailed))
+		installed := meta.FindStatusCondition(status.Conditions, v1beta1.ConditionInstalled)
+		assert.Equal(t, "Revision 2 of release test is deployed", installed.Message)
+		assert.Equal(t, int64(3), installed.ObservedGeneration)
+	})
+
+	t.Run("upgrade_failed", func(t *testing.T) {
+		status := v1beta1.ChartStatus{ReleaseName: "test", Revision: 2}
+		setChartConditions(&status, 1, nil)
+		setChartConditions(&status, 2, errors.New("boom"))
+		assert.Equal(t, metav1.ConditionTrue, conditionStatus(&status, v1beta1.ConditionInstalled))
+		assert.Equal(t, metav1.ConditionTrue, conditionStatus(&status, v1beta1.ConditionFailed))
+	})
+}
+
+func TestChartRevisions(t *testing.T) {
+	deployed := helmtime.Unix(1680000000, 0)
+	var releases []*release.Release
+	for i := 1; i <= 4; i++ {
+		releases = append(releases, &release.Release{
+			Version: i,
+			Chart:   &chart.Chart{Metadata: &chart.Metadata{Version: "1.0.0", AppVersion: "v1"}},
+			Info:    &release.Info{Status: release.StatusSuperseded, Description: "Upgrade complete", LastDeployed: deployed},
+		})
+	}
+	releases[0], releases[3] = releases[3], releases[0]
+	releases[0].Info.Status = release.StatusDeployed
+
+	revisions := chartRevisions(releases, 3)
+	require.Len(t, revisions, 3)
+	assert.Equal(t, v1beta1.ChartRevision{
+		Revision:    4,
+		Status:      "deployed",
+		Version:     "1.0.0",
+		AppVersion:  "v1",
+		Updated:     &metav1.Time{Time: deployed.Time},
+		Description: "Upgrade complete",
+	}, revisions[0])
+	assert.Equal(t, int64(3), revisions[1].Revision)
+	assert.Equal(t, int64(2), revisions[2].Revision)
+	assert.Equal(t, 4, releases[0].Version, "input has been reordered")
+}
+
+func TestRenderedValuesHash(t *testing.T) {
+	newRelease := func(config map[string]interface{}) *release.Release {
+		return &release.Release{
+			Chart: &chart.Chart{
+				Metadata: &chart.Metadata{Name: "test"},
+				Values:   map[string]interface{}{"replicas": 1, "image": "test"},
+			},
+			Config: config,
+		}
+	}
+
+	defaults := renderedValuesHash(newRelease(nil))
+	assert.NotEmpty(t, defaults)
+	assert.Equal(t, defaults, renderedValuesHash(newRelease(map[string]interface{}{"replicas": 1})))
+	assert.NotEqual(t, defaults, renderedValuesHash(newRelease(map[string]interface{}{"replicas": 2})))
+}
+
+func TestChartRateLimiter(t *testing.T) {
+	limiter := newChartRateLimiter()
+	slow, fast := "kube-system/slow", "kube-system/fast"
+
+	assert.Equal(t, 5*time.Second, limiter.When(slow))
+	assert.Equal(t, 10*time.Second, limiter.When(slow))
+	assert.Equal(t, 20*time.Second, limiter.When(slow))
+	assert.Equal(t, 5*time.Second, limiter.When(fast), "backoff is per chart")
+
+	for i := 0; i < 20; i++ {
+		limiter.When(slow)
+	}
+	assert.Equal(t, 10*time.Minute, limiter.When(slow))
+
+	limiter.Forget(slow)
+	assert.Equal(t, 5*time.Second, limiter.When(slow))
+}
+
+func TestNewExtensionsControllerConcurrencyLevel(t *testing.T) {
+	ec := NewExtensionsController(nil, constant.CfgVars{}, nil, nil, 0)
+	assert.Equal(t, defaultConcurrencyLevel, ec.concurrencyLevel)
+
+	ec = NewExtensionsController(nil, constant.CfgVars{}, nil, nil, 20)
+	assert.Equal(t, 20, ec.concurrencyLevel)
+}
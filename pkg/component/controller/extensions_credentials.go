@@ -0,0 +1,150 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	k0sAPI "github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/helm"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+const (
+	// repositorySecretLabel marks the secrets holding helm repository credentials.
+	repositorySecretLabel = "helm.k0sproject.io/repository-credentials"
+	// repositoryNameAnnotation holds the name of the repository of a secret.
+	repositoryNameAnnotation = "helm.k0sproject.io/repository-name"
+
+	repositoryUsernameKey = "username"
+	repositoryPasswordKey = "password"
+	repositoryTokenKey    = "token"
+	repositoryCABundleKey = "ca.crt"
+)
+
+// repositorySecretName returns the name of the secret holding the credentials
+// of the given repository. Repository names that are no valid object names
+// are hashed.
+func repositorySecretName(repoName string) string {
+	name := "k0s-helm-repository-" + repoName
+	if len(validation.IsDNS1123Subdomain(name)) == 0 {
+		return name
+	}
+	hash := sha256.Sum256([]byte(repoName))
+	return "k0s-helm-repository-" + hex.EncodeToString(hash[:8])
+}
+
+// reconcileRepositorySecrets stores the credentials of the given repositories
+// in secrets, and deletes the secrets of repositories that are gone. The secret
+// of a repository without credentials in the config is left untouched, so that
+// credentials can be removed from the config once they're stored in a secret.
+func reconcileRepositorySecrets(ctx context.Context, client kubernetes.Interface, repos []k0sAPI.Repository) error {
+	secrets := client.CoreV1().Secrets(namespaceToWatch)
+
+	keep := make(map[string]bool)
+	for _, repo := range repos {
+		keep[repositorySecretName(repo.Name)] = true
+		if !repo.HasCredentials() {
+			continue
+		}
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        repositorySecretName(repo.Name),
+				Namespace:   namespaceToWatch,
+				Labels:      map[string]string{repositorySecretLabel: "true"},
+				Annotations: map[string]string{repositoryNameAnnotation: repo.Name},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{
+				repositoryUsernameKey: []byte(repo.Username),
+				repositoryPasswordKey: []byte(repo.Password),
+				repositoryTokenKey:    []byte(repo.Token),
+				repositoryCABundleKey: []byte(repo.CABundle),
+			},
+		}
+
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			existing, err := secrets.Get(ctx, secret.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				_, err = secrets.Create(ctx, secret, metav1.CreateOptions{})
+				return err
+			} else if err != nil {
+				return err
+			}
+			secret.ResourceVersion = existing.ResourceVersion
+			_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("can't store credentials of repository %q: %w", repo.Name, err)
+		}
+	}
+
+	list, err := secrets.List(ctx, metav1.ListOptions{LabelSelector: repositorySecretLabel + "=true"})
+	if err != nil {
+		return fmt.Errorf("can't list repository credentials: %w", err)
+	}
+	for _, secret := range list.Items {
+		if keep[secret.Name] {
+			continue
+		}
+		if err := secrets.Delete(ctx, secret.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("can't delete credentials of repository %q: %w", secret.Annotations[repositoryNameAnnotation], err)
+		}
+	}
+
+	return nil
+}
+
+// secretCredentialsProvider reads helm repository credentials from secrets.
+type secretCredentialsProvider struct {
+	clientFactory kubeutil.ClientFactoryInterface
+}
+
+var _ helm.CredentialsProvider = (*secretCredentialsProvider)(nil)
+
+func (p *secretCredentialsProvider) RepositoryCredentials(ctx context.Context, repoName string) (*helm.RepositoryCredentials, error) {
+	client, err := p.clientFactory.GetClient()
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := client.CoreV1().Secrets(namespaceToWatch).Get(ctx, repositorySecretName(repoName), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return &helm.RepositoryCredentials{
+		Username: string(secret.Data[repositoryUsernameKey]),
+		Password: string(secret.Data[repositoryPasswordKey]),
+		Token:    string(secret.Data[repositoryTokenKey]),
+		CABundle: secret.Data[repositoryCABundleKey],
+	}, nil
+}
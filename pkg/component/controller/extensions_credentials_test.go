@@ -0,0 +1,85 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k0sproject/k0s/internal/testutil"
+	k0sAPI "github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/helm"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRepositorySecretName(t *testing.T) {
+	assert.Equal(t, "k0s-helm-repository-bitnami", repositorySecretName("bitnami"))
+	assert.Equal(t, "k0s-helm-repository-343d9801d729ac32", repositorySecretName("My_Repo"))
+}
+
+func TestReconcileRepositorySecrets(t *testing.T) {
+	ctx := context.Background()
+	clients := testutil.NewFakeClientFactory()
+	client, err := clients.GetClient()
+	require.NoError(t, err)
+	provider := &secretCredentialsProvider{clients}
+
+	require.NoError(t, reconcileRepositorySecrets(ctx, client, []k0sAPI.Repository{
+		{Name: "public", URL: "https://public.example.com"},
+		{Name: "private", URL: "https://private.example.com", Username: "user", Password: "pass"},
+		{Name: "token", URL: "https://token.example.com", Token: "secret", CABundle: "ca"},
+	}))
+
+	creds, err := provider.RepositoryCredentials(ctx, "public")
+	assert.NoError(t, err)
+	assert.Nil(t, creds)
+
+	creds, err = provider.RepositoryCredentials(ctx, "private")
+	assert.NoError(t, err)
+	assert.Equal(t, &helm.RepositoryCredentials{Username: "user", Password: "pass", CABundle: []byte{}}, creds)
+
+	creds, err = provider.RepositoryCredentials(ctx, "token")
+	assert.NoError(t, err)
+	assert.Equal(t, &helm.RepositoryCredentials{Token: "secret", CABundle: []byte("ca")}, creds)
+
+	// Update one and drop the other repository.
+	require.NoError(t, reconcileRepositorySecrets(ctx, client, []k0sAPI.Repository{
+		{Name: "private", URL: "https://private.example.com", Token: "new"},
+	}))
+
+	creds, err = provider.RepositoryCredentials(ctx, "private")
+	assert.NoError(t, err)
+	assert.Equal(t, "new", creds.Token)
+	assert.Empty(t, creds.Username)
+
+	_, err = client.CoreV1().Secrets(namespaceToWatch).Get(ctx, repositorySecretName("token"), metav1.GetOptions{})
+	assert.Error(t, err, "secret of removed repository should have been deleted")
+
+	// Remove the credentials from the config, the secret stays authoritative.
+	require.NoError(t, reconcileRepositorySecrets(ctx, client, []k0sAPI.Repository{
+		{Name: "private", URL: "https://private.example.com"},
+	}))
+
+	creds, err = provider.RepositoryCredentials(ctx, "private")
+	assert.NoError(t, err)
+	if assert.NotNil(t, creds) {
+		assert.Equal(t, "new", creds.Token)
+	}
+}
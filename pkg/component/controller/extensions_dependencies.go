@@ -0,0 +1,118 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/helm/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// dependencyRetryInterval is the interval in which charts that are waiting
+// for their dependencies are checked again.
+const dependencyRetryInterval = 10 * time.Second
+
+// pendingDependencies returns a description of each dependency of the given
+// chart that is not yet ready.
+func pendingDependencies(ctx context.Context, c client.Reader, chart v1beta1.Chart) ([]string, error) {
+	var pending []string
+	for _, dependency := range chart.Spec.DependsOn {
+		switch {
+		case dependency.Chart != "":
+			ready, err := chartReady(ctx, c, client.ObjectKey{Namespace: chart.Namespace, Name: dependency.Chart})
+			if err != nil {
+				return nil, err
+			}
+			if !ready {
+				pending = append(pending, "chart "+dependency.Chart)
+			}
+
+		case dependency.Component == v1beta1.ComponentNetwork:
+			ready, err := networkReady(ctx, c)
+			if err != nil {
+				return nil, err
+			}
+			if !ready {
+				pending = append(pending, "component "+dependency.Component)
+			}
+
+		default:
+			return nil, fmt.Errorf("unsupported dependency: %+v", dependency)
+		}
+	}
+
+	return pending, nil
+}
+
+// chartReady checks if the given chart has been installed successfully and is
+// not being upgraded.
+func chartReady(ctx context.Context, c client.Reader, key client.ObjectKey) (bool, error) {
+	var chart v1beta1.Chart
+	if err := c.Get(ctx, key, &chart); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("can't get chart %s: %w", key, err)
+	}
+
+	conditions := chart.Status.Conditions
+	return meta.IsStatusConditionTrue(conditions, v1beta1.ConditionInstalled) &&
+		!meta.IsStatusConditionTrue(conditions, v1beta1.ConditionUpgrading) &&
+		!meta.IsStatusConditionTrue(conditions, v1beta1.ConditionFailed), nil
+}
+
+// networkReady checks if the network provider is up, i.e. if any node is
+// ready. Nodes don't become ready before their pod network is configured.
+func networkReady(ctx context.Context, c client.Reader) (bool, error) {
+	var nodes corev1.NodeList
+	if err := c.List(ctx, &nodes); err != nil {
+		return false, fmt.Errorf("can't list nodes: %w", err)
+	}
+
+	for _, node := range nodes.Items {
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady && condition.Status == corev1.ConditionTrue {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// markWaitingForDependencies sets the DependenciesReady condition of the
+// chart to false.
+func (cr *ChartReconciler) markWaitingForDependencies(ctx context.Context, chart *v1beta1.Chart, pending []string) {
+	meta.SetStatusCondition(&chart.Status.Conditions, metav1.Condition{
+		Type:               v1beta1.ConditionDependenciesReady,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: chart.Generation,
+		Reason:             "WaitingForDependencies",
+		Message:            "Waiting for " + strings.Join(pending, ", "),
+	})
+	if err := cr.Client.Status().Update(ctx, chart); err != nil {
+		cr.L.WithError(err).Warn("Failed to update status for chart release ", chart.Name)
+	}
+}
@@ -0,0 +1,130 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/helm/v1beta1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newDependencyTestChart(name string, conditions ...metav1.Condition) *v1beta1.Chart {
+	return &v1beta1.Chart{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespaceToWatch},
+		Status:     v1beta1.ChartStatus{Conditions: conditions},
+	}
+}
+
+func newDependencyTestNode(name string, ready corev1.ConditionStatus) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+func TestPendingDependencies(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, clientgoscheme.AddToScheme(scheme))
+	require.NoError(t, v1beta1.AddToScheme(scheme))
+
+	installed := metav1.Condition{Type: v1beta1.ConditionInstalled, Status: metav1.ConditionTrue}
+	upgrading := metav1.Condition{Type: v1beta1.ConditionUpgrading, Status: metav1.ConditionTrue}
+	failed := metav1.Condition{Type: v1beta1.ConditionFailed, Status: metav1.ConditionTrue}
+
+	chart := v1beta1.Chart{
+		ObjectMeta: metav1.ObjectMeta{Name: "dependent", Namespace: namespaceToWatch},
+		Spec: v1beta1.ChartSpec{
+			DependsOn: []v1beta1.ChartDependency{
+				{Chart: "cert-manager"},
+				{Component: v1beta1.ComponentNetwork},
+			},
+		},
+	}
+
+	for _, test := range []struct {
+		name     string
+		objects  []client.Object
+		expected []string
+	}{
+		{
+			"nothing_ready",
+			nil,
+			[]string{"chart cert-manager", "component network"},
+		},
+		{
+			"chart_not_installed",
+			[]client.Object{
+				newDependencyTestChart("cert-manager"),
+				newDependencyTestNode("worker", corev1.ConditionTrue),
+			},
+			[]string{"chart cert-manager"},
+		},
+		{
+			"chart_upgrading",
+			[]client.Object{
+				newDependencyTestChart("cert-manager", installed, upgrading),
+				newDependencyTestNode("worker", corev1.ConditionTrue),
+			},
+			[]string{"chart cert-manager"},
+		},
+		{
+			"chart_failed",
+			[]client.Object{
+				newDependencyTestChart("cert-manager", installed, failed),
+				newDependencyTestNode("worker", corev1.ConditionTrue),
+			},
+			[]string{"chart cert-manager"},
+		},
+		{
+			"nodes_not_ready",
+			[]client.Object{
+				newDependencyTestChart("cert-manager", installed),
+				newDependencyTestNode("worker", corev1.ConditionFalse),
+			},
+			[]string{"component network"},
+		},
+		{
+			"all_ready",
+			[]client.Object{
+				newDependencyTestChart("cert-manager", installed),
+				newDependencyTestNode("worker0", corev1.ConditionFalse),
+				newDependencyTestNode("worker1", corev1.ConditionTrue),
+			},
+			nil,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			client := crfake.NewClientBuilder().WithScheme(scheme).WithObjects(test.objects...).Build()
+
+			pending, err := pendingDependencies(context.TODO(), client, chart)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, pending)
+		})
+	}
+}
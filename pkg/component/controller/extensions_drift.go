@@ -0,0 +1,222 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// driftCheckInterval is the interval in which the live resources of releases
+// are checked for drift, if the chart's drift policy asks for it.
+const driftCheckInterval = 5 * time.Minute
+
+// findDrift compares the resources of the given release manifest with their
+// live state. It returns a sorted description of each resource that is either
+// missing or whose live state differs from the manifest. Fields that are only
+// present in the live state, e.g. defaults and the status, are not considered
+// drift. Neither are values that the API server normalized, see contains.
+func findDrift(ctx context.Context, c client.Reader, manifest string, namespace string) ([]string, error) {
+	var drift []string
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		var desired unstructured.Unstructured
+		if err := yaml.Unmarshal([]byte(doc), &desired.Object); err != nil {
+			return nil, fmt.Errorf("can't parse release manifest: %w", err)
+		}
+		if len(desired.Object) == 0 {
+			continue
+		}
+
+		resource := fmt.Sprintf("%s %s", desired.GetKind(), desired.GetName())
+		if desired.GetNamespace() == "" {
+			desired.SetNamespace(namespace)
+		}
+
+		var live unstructured.Unstructured
+		live.SetGroupVersionKind(desired.GroupVersionKind())
+		err := c.Get(ctx, client.ObjectKeyFromObject(&desired), &live)
+		if apierrors.IsNotFound(err) {
+			drift = append(drift, resource+": missing")
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("can't get %s: %w", resource, err)
+		}
+
+		// Secrets are stored with their data only.
+		delete(desired.Object, "stringData")
+
+		matches, err := containsJSON(live.Object, desired.Object)
+		if err != nil {
+			return nil, fmt.Errorf("can't compare %s: %w", resource, err)
+		}
+		if !matches {
+			drift = append(drift, resource+": modified")
+		}
+	}
+
+	sort.Strings(drift)
+	return drift, nil
+}
+
+// containsJSON checks if the JSON representation of live contains the one of
+// desired. Both are converted to JSON first, so that numbers of different Go
+// types compare equal.
+func containsJSON(live, desired interface{}) (bool, error) {
+	normalize := func(in interface{}) (out interface{}, err error) {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		return out, json.Unmarshal(data, &out)
+	}
+
+	live, err := normalize(live)
+	if err != nil {
+		return false, err
+	}
+	desired, err = normalize(desired)
+	if err != nil {
+		return false, err
+	}
+	return contains(live, desired), nil
+}
+
+// contains checks if live contains all values of desired. Maps may have
+// additional keys. Lists of objects may be reordered and have additional
+// elements, e.g. ones that were injected by admission controllers. Other lists
+// need to be equal element by element.
+func contains(live, desired interface{}) bool {
+	switch desired := desired.(type) {
+	case map[string]interface{}:
+		live, ok := live.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for key, value := range desired {
+			if value == nil {
+				continue
+			}
+			if !contains(live[key], value) {
+				return false
+			}
+		}
+		return true
+
+	case []interface{}:
+		live, ok := live.([]interface{})
+		if !ok {
+			return false
+		}
+		if !containsObjects(desired) {
+			if len(live) != len(desired) {
+				return false
+			}
+			for i := range desired {
+				if !contains(live[i], desired[i]) {
+					return false
+				}
+			}
+			return true
+		}
+
+		matched := make([]bool, len(live))
+	desired:
+		for _, value := range desired {
+			for i := range live {
+				if !matched[i] && contains(live[i], value) {
+					matched[i] = true
+					continue desired
+				}
+			}
+			return false
+		}
+		return true
+
+	default:
+		return scalarsEqual(live, desired)
+	}
+}
+
+// containsObjects checks if the given list contains objects.
+func containsObjects(list []interface{}) bool {
+	for _, value := range list {
+		if _, ok := value.(map[string]interface{}); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// scalarsEqual checks if the given scalars are equal. Numbers and strings are
+// compared by their string representation, so that ports given as either one
+// compare equal. Values that are equal as quantities, such as "1Gi" and
+// "1024Mi" or 0.5 and "500m", are considered equal as well.
+func scalarsEqual(live, desired interface{}) bool {
+	if reflect.DeepEqual(live, desired) {
+		return true
+	}
+
+	liveStr, ok := scalarString(live)
+	if !ok {
+		return false
+	}
+	desiredStr, ok := scalarString(desired)
+	if !ok {
+		return false
+	}
+	if liveStr == desiredStr {
+		return true
+	}
+
+	liveQuantity, err := resource.ParseQuantity(liveStr)
+	if err != nil {
+		return false
+	}
+	desiredQuantity, err := resource.ParseQuantity(desiredStr)
+	if err != nil {
+		return false
+	}
+	return liveQuantity.Cmp(desiredQuantity) == 0
+}
+
+// scalarString returns the string representation of numbers and strings.
+func scalarString(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case string:
+		return value, true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case int64:
+		return strconv.FormatInt(value, 10), true
+	case int:
+		return strconv.Itoa(value), true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	crfake "sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const driftTestManifest = `
+---
+# Source: test/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+data:
+  key: value
+---
+# Source: test/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  labels:
+    app: test
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: test
+  template:
+    metadata:
+      labels:
+        app: test
+    spec:
+      containers:
+      - name: app
+        image: test:1.0
+`
+
+func newDriftTestDeployment(replicas int32) *appsv1.Deployment {
+	labels := map[string]string{"app": "test"}
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "app",
+			Namespace:   "test-ns",
+			Labels:      labels,
+			Annotations: map[string]string{"meta.helm.sh/release-name": "test"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: pointer.Int32(replicas),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{
+						Name:            "app",
+						Image:           "test:1.0",
+						ImagePullPolicy: corev1.PullIfNotPresent,
+					}},
+				},
+			},
+		},
+	}
+}
+
+func TestFindDrift(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: "test-ns"},
+		Data:       map[string]string{"key": "value"},
+	}
+
+	t.Run("no_drift", func(t *testing.T) {
+		client := crfake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(configMap.DeepCopy(), newDriftTestDeployment(2)).
+			Build()
+
+		drift, err := findDrift(context.TODO(), client, driftTestManifest, "test-ns")
+		require.NoError(t, err)
+		assert.Empty(t, drift)
+	})
+
+	t.Run("modified_and_missing", func(t *testing.T) {
+		client := crfake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(newDriftTestDeployment(0)).
+			Build()
+
+		drift, err := findDrift(context.TODO(), client, driftTestManifest, "test-ns")
+		require.NoError(t, err)
+		assert.Equal(t, []string{
+			"ConfigMap config: missing",
+			"Deployment app: modified",
+		}, drift)
+	})
+
+	t.Run("normalized_values", func(t *testing.T) {
+		deployment := newDriftTestDeployment(2)
+		deployment.Spec.Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+			},
+		}
+		deployment.Spec.Template.Spec.Containers[0].Ports = []corev1.ContainerPort{
+			{Name: "metrics", ContainerPort: 9090, Protocol: corev1.ProtocolTCP},
+			{Name: "http", ContainerPort: 8080, Protocol: corev1.ProtocolTCP},
+		}
+		client := crfake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(configMap.DeepCopy(), deployment).
+			Build()
+
+		manifest := driftTestManifest + `        resources:
+          limits:
+            memory: 1024Mi
+            cpu: 0.5
+        ports:
+        - name: http
+          containerPort: "8080"
+        - name: metrics
+          containerPort: 9090
+`
+		drift, err := findDrift(context.TODO(), client, manifest, "test-ns")
+		require.NoError(t, err)
+		assert.Empty(t, drift)
+	})
+
+	t.Run("other_namespace", func(t *testing.T) {
+		client := crfake.NewClientBuilder().
+			WithScheme(scheme.Scheme).
+			WithObjects(configMap.DeepCopy(), newDriftTestDeployment(2)).
+			Build()
+
+		drift, err := findDrift(context.TODO(), client, driftTestManifest, "other-ns")
+		require.NoError(t, err)
+		assert.Len(t, drift, 2)
+	})
+}
+
+func TestContains(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		live, desired interface{}
+		expected      bool
+	}{
+		{"equal_scalars", "a", "a", true},
+		{"different_scalars", "a", "b", false},
+		{"additional_keys", map[string]interface{}{"a": 1, "b": 2}, map[string]interface{}{"a": 1}, true},
+		{"missing_key", map[string]interface{}{"b": 2}, map[string]interface{}{"a": 1}, false},
+		{"nil_value", map[string]interface{}{}, map[string]interface{}{"a": nil}, true},
+		{"list_length", []interface{}{1, 2}, []interface{}{1}, false},
+		{"list_elements", []interface{}{map[string]interface{}{"a": 1, "b": 2}}, []interface{}{map[string]interface{}{"a": 1}}, true},
+		{"type_mismatch", []interface{}{}, map[string]interface{}{}, false},
+		{"reordered_objects", []interface{}{map[string]interface{}{"a": 2}, map[string]interface{}{"a": 1}}, []interface{}{map[string]interface{}{"a": 1}, map[string]interface{}{"a": 2}}, true},
+		{"injected_objects", []interface{}{map[string]interface{}{"a": 1}, map[string]interface{}{"b": 1}}, []interface{}{map[string]interface{}{"a": 1}}, true},
+		{"missing_object", []interface{}{map[string]interface{}{"a": 1}}, []interface{}{map[string]interface{}{"a": 1}, map[string]interface{}{"a": 1}}, false},
+		{"reordered_scalars", []interface{}{"b", "a"}, []interface{}{"a", "b"}, false},
+		{"binary_quantities", "1Gi", "1024Mi", true},
+		{"decimal_quantities", "500m", 0.5, true},
+		{"different_quantities", "1Gi", "1G", false},
+		{"numeric_port", float64(8080), "8080", true},
+		{"different_port", float64(8080), "8081", false},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, contains(test.live, test.desired))
+		})
+	}
+}
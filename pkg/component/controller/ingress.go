@@ -0,0 +1,442 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Ingress is the component implementation to manage the bundled ingress
+// controller.
+type Ingress struct {
+	log logrus.FieldLogger
+
+	manifestDir string
+
+	previousConfig ingressConfig
+}
+
+var _ manager.Component = (*Ingress)(nil)
+var _ manager.Reconciler = (*Ingress)(nil)
+
+type ingressConfig struct {
+	Image        string
+	PullPolicy   string
+	Replicas     int32
+	DefaultClass bool
+	HostPort     bool
+	ServiceType  string
+}
+
+// NewIngress creates a new Ingress component.
+func NewIngress(k0sVars constant.CfgVars) *Ingress {
+	return &Ingress{
+		log: logrus.WithFields(logrus.Fields{"component": constant.IngressComponentName}),
+
+		manifestDir: path.Join(k0sVars.ManifestsDir, "ingress"),
+	}
+}
+
+// Init does nothing
+func (i *Ingress) Init(context.Context) error {
+	return nil
+}
+
+// Start does nothing
+func (i *Ingress) Start(context.Context) error {
+	return nil
+}
+
+// Reconcile detects changes in configuration and applies them to the component
+func (i *Ingress) Reconcile(_ context.Context, clusterConfig *v1beta1.ClusterConfig) error {
+	var ingress *v1beta1.IngressExtension
+	if clusterConfig.Spec.Extensions != nil {
+		ingress = clusterConfig.Spec.Extensions.Ingress
+	}
+	if !ingress.IsEnabled() {
+		i.previousConfig = ingressConfig{}
+		return os.RemoveAll(i.manifestDir)
+	}
+
+	cfg := i.getConfig(clusterConfig, ingress)
+	if cfg == i.previousConfig {
+		i.log.Debug("current config matches existing, not gonna do anything")
+		return nil
+	}
+
+	if err := dir.Init(i.manifestDir, constant.ManifestsDirMode); err != nil {
+		return err
+	}
+
+	tw := templatewriter.TemplateWriter{
+		Name:     "ingress-nginx",
+		Template: ingressNginxTemplate,
+		Data:     cfg,
+		Path:     filepath.Join(i.manifestDir, "ingress-nginx.yaml"),
+	}
+	if err := tw.Write(); err != nil {
+		return fmt.Errorf("error writing ingress-nginx manifests: %w", err)
+	}
+	i.previousConfig = cfg
+
+	return nil
+}
+
+// Stop does nothing
+func (i *Ingress) Stop() error {
+	return nil
+}
+
+func (i *Ingress) getConfig(clusterConfig *v1beta1.ClusterConfig, ingress *v1beta1.IngressExtension) ingressConfig {
+	image := ingress.Image
+	if image == nil {
+		image = v1beta1.DefaultIngressImage()
+	}
+	pullPolicy := string(ingress.ImagePullPolicy)
+	if pullPolicy == "" {
+		pullPolicy = clusterConfig.Spec.Images.DefaultPullPolicy
+	}
+
+	cfg := ingressConfig{
+		Image:        image.URI(),
+		PullPolicy:   pullPolicy,
+		Replicas:     ingress.Replicas,
+		DefaultClass: ingress.DefaultClass,
+	}
+	if cfg.Replicas < 1 {
+		cfg.Replicas = v1beta1.DefaultIngressReplicas
+	}
+	switch ingress.Exposure {
+	case v1beta1.IngressExposureHostPort:
+		cfg.HostPort = true
+	case v1beta1.IngressExposureNodePort:
+		cfg.ServiceType = "NodePort"
+	default:
+		cfg.ServiceType = "LoadBalancer"
+	}
+
+	return cfg
+}
+
+// The manifests are based on the upstream static deployment of ingress-nginx,
+// without the validating admission webhook. With the HostPort exposure, there's
+// no Service to publish, so the controller reports the node addresses in the
+// status of the Ingresses instead.
+const ingressNginxTemplate = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: ingress-nginx
+  namespace: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+automountServiceAccountToken: true
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: ingress-nginx-controller
+  namespace: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+data:
+  allow-snippet-annotations: "false"
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+rules:
+- apiGroups: [""]
+  resources: ["configmaps", "endpoints", "nodes", "pods", "secrets", "namespaces"]
+  verbs: ["list", "watch"]
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["list", "watch"]
+- apiGroups: [""]
+  resources: ["nodes"]
+  verbs: ["get"]
+- apiGroups: [""]
+  resources: ["services"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["networking.k8s.io"]
+  resources: ["ingresses"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch"]
+- apiGroups: ["networking.k8s.io"]
+  resources: ["ingresses/status"]
+  verbs: ["update"]
+- apiGroups: ["networking.k8s.io"]
+  resources: ["ingressclasses"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["discovery.k8s.io"]
+  resources: ["endpointslices"]
+  verbs: ["list", "watch", "get"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: ingress-nginx
+subjects:
+- kind: ServiceAccount
+  name: ingress-nginx
+  namespace: ingress-nginx
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: ingress-nginx
+  namespace: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+rules:
+- apiGroups: [""]
+  resources: ["namespaces"]
+  verbs: ["get"]
+- apiGroups: [""]
+  resources: ["configmaps", "pods", "secrets", "endpoints"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["services"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["networking.k8s.io"]
+  resources: ["ingresses"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["networking.k8s.io"]
+  resources: ["ingresses/status"]
+  verbs: ["update"]
+- apiGroups: ["networking.k8s.io"]
+  resources: ["ingressclasses"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  resourceNames: ["ingress-nginx-leader"]
+  verbs: ["get", "update"]
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["create"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch"]
+- apiGroups: ["discovery.k8s.io"]
+  resources: ["endpointslices"]
+  verbs: ["list", "watch", "get"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: ingress-nginx
+  namespace: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: ingress-nginx
+subjects:
+- kind: ServiceAccount
+  name: ingress-nginx
+  namespace: ingress-nginx
+---
+apiVersion: networking.k8s.io/v1
+kind: IngressClass
+metadata:
+  name: nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+{{- if .DefaultClass }}
+  annotations:
+    ingressclass.kubernetes.io/is-default-class: "true"
+{{- end }}
+spec:
+  controller: k8s.io/ingress-nginx
+{{- if not .HostPort }}
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: ingress-nginx-controller
+  namespace: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+spec:
+  type: {{ .ServiceType }}
+{{- if eq .ServiceType "LoadBalancer" }}
+  externalTrafficPolicy: Local
+{{- end }}
+  ipFamilyPolicy: PreferDualStack
+  ports:
+  - name: http
+    port: 80
+    protocol: TCP
+    targetPort: http
+    appProtocol: http
+  - name: https
+    port: 443
+    protocol: TCP
+    targetPort: https
+    appProtocol: https
+  selector:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+{{- end }}
+---
+apiVersion: apps/v1
+{{- if .HostPort }}
+kind: DaemonSet
+{{- else }}
+kind: Deployment
+{{- end }}
+metadata:
+  name: ingress-nginx-controller
+  namespace: ingress-nginx
+  labels:
+    app.kubernetes.io/name: ingress-nginx
+    app.kubernetes.io/component: controller
+spec:
+{{- if not .HostPort }}
+  replicas: {{ .Replicas }}
+{{- end }}
+  minReadySeconds: 0
+  revisionHistoryLimit: 10
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: ingress-nginx
+      app.kubernetes.io/component: controller
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: ingress-nginx
+        app.kubernetes.io/component: controller
+    spec:
+      serviceAccountName: ingress-nginx
+      terminationGracePeriodSeconds: 300
+      dnsPolicy: ClusterFirst
+      nodeSelector:
+        kubernetes.io/os: linux
+      containers:
+      - name: controller
+        image: {{ .Image }}
+        imagePullPolicy: {{ .PullPolicy }}
+        args:
+        - /nginx-ingress-controller
+{{- if .HostPort }}
+        - --report-node-internal-ip-address
+{{- else }}
+        - --publish-service=$(POD_NAMESPACE)/ingress-nginx-controller
+{{- end }}
+        - --election-id=ingress-nginx-leader
+        - --controller-class=k8s.io/ingress-nginx
+        - --ingress-class=nginx
+        - --configmap=$(POD_NAMESPACE)/ingress-nginx-controller
+        env:
+        - name: POD_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.name
+        - name: POD_NAMESPACE
+          valueFrom:
+            fieldRef:
+              fieldPath: metadata.namespace
+        - name: LD_PRELOAD
+          value: /usr/local/lib/libmimalloc.so
+        lifecycle:
+          preStop:
+            exec:
+              command:
+              - /wait-shutdown
+        livenessProbe:
+          failureThreshold: 5
+          httpGet:
+            path: /healthz
+            port: 10254
+            scheme: HTTP
+          initialDelaySeconds: 10
+          periodSeconds: 10
+          successThreshold: 1
+          timeoutSeconds: 1
+        readinessProbe:
+          failureThreshold: 3
+          httpGet:
+            path: /healthz
+            port: 10254
+            scheme: HTTP
+          initialDelaySeconds: 10
+          periodSeconds: 10
+          successThreshold: 1
+          timeoutSeconds: 1
+        ports:
+        - name: http
+          containerPort: 80
+          protocol: TCP
+{{- if .HostPort }}
+          hostPort: 80
+{{- end }}
+        - name: https
+          containerPort: 443
+          protocol: TCP
+{{- if .HostPort }}
+          hostPort: 443
+{{- end }}
+        resources:
+          requests:
+            cpu: 100m
+            memory: 90Mi
+        securityContext:
+          allowPrivilegeEscalation: true
+          capabilities:
+            add:
+            - NET_BIND_SERVICE
+            drop:
+            - ALL
+          runAsUser: 101
+`
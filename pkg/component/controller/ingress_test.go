@@ -0,0 +1,116 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestIngress_Reconcile(t *testing.T) {
+	k0sVars := constant.GetConfig(t.TempDir())
+	cfg := v1beta1.DefaultClusterConfig()
+	underTest := NewIngress(k0sVars)
+	manifest := filepath.Join(underTest.manifestDir, "ingress-nginx.yaml")
+
+	readObjects := func(t *testing.T) map[string]unstructured.Unstructured {
+		data, err := os.ReadFile(manifest)
+		require.NoError(t, err)
+
+		objects := make(map[string]unstructured.Unstructured)
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			var obj unstructured.Unstructured
+			if err := decoder.Decode(&obj.Object); err != nil {
+				break
+			}
+			if obj.Object == nil {
+				continue
+			}
+			objects[obj.GetKind()] = obj
+		}
+		return objects
+	}
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoDirExists(t, underTest.manifestDir)
+	})
+
+	t.Run("load_balancer", func(t *testing.T) {
+		cfg.Spec.Extensions.Ingress = v1beta1.DefaultIngressExtension()
+		cfg.Spec.Extensions.Ingress.Enabled = true
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+
+		objects := readObjects(t)
+		assert.NotContains(t, objects, "DaemonSet")
+		assert.Empty(t, objects["IngressClass"].GetAnnotations())
+
+		serviceType, _, err := unstructured.NestedString(objects["Service"].Object, "spec", "type")
+		require.NoError(t, err)
+		assert.Equal(t, "LoadBalancer", serviceType)
+
+		deployment := objects["Deployment"]
+		replicas, _, err := unstructured.NestedFieldNoCopy(deployment.Object, "spec", "replicas")
+		require.NoError(t, err)
+		assert.EqualValues(t, 2, replicas)
+		containers, _, err := unstructured.NestedSlice(deployment.Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+		require.Len(t, containers, 1)
+		container := containers[0].(map[string]any)
+		assert.Equal(t, v1beta1.DefaultIngressImage().URI(), container["image"])
+		assert.Equal(t, "IfNotPresent", container["imagePullPolicy"])
+	})
+
+	t.Run("host_port", func(t *testing.T) {
+		cfg.Spec.Extensions.Ingress.Exposure = v1beta1.IngressExposureHostPort
+		cfg.Spec.Extensions.Ingress.DefaultClass = true
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+
+		objects := readObjects(t)
+		assert.NotContains(t, objects, "Deployment")
+		assert.NotContains(t, objects, "Service")
+		assert.Equal(t, map[string]string{
+			"ingressclass.kubernetes.io/is-default-class": "true",
+		}, objects["IngressClass"].GetAnnotations())
+
+		containers, _, err := unstructured.NestedSlice(objects["DaemonSet"].Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+		require.Len(t, containers, 1)
+		ports := containers[0].(map[string]any)["ports"].([]any)
+		require.Len(t, ports, 2)
+		assert.EqualValues(t, 80, ports[0].(map[string]any)["hostPort"])
+		assert.EqualValues(t, 443, ports[1].(map[string]any)["hostPort"])
+	})
+
+	t.Run("disabled_again", func(t *testing.T) {
+		cfg.Spec.Extensions.Ingress.Enabled = false
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoDirExists(t, underTest.manifestDir)
+	})
+}
@@ -18,7 +18,6 @@ package controller
 
 import (
 	"context"
-	"time"
 
 	"github.com/k0sproject/k0s/pkg/component/manager"
 	"github.com/k0sproject/k0s/pkg/k0scloudprovider"
@@ -36,14 +35,12 @@ var _ manager.Component = (*K0sCloudProvider)(nil)
 // create `Command` instances.
 type CommandBuilder func() (k0scloudprovider.Command, error)
 
-// NewK0sCloudProvider creates a new k0s cloud-provider using the default
-// address collector and command.
-func NewK0sCloudProvider(kubeConfigPath string, frequency time.Duration, port int) *K0sCloudProvider {
-	config := k0scloudprovider.Config{
-		AddressCollector: k0scloudprovider.DefaultAddressCollector(),
-		KubeConfig:       kubeConfigPath,
-		UpdateFrequency:  frequency,
-		BindPort:         port,
+// NewK0sCloudProvider creates a new k0s cloud-provider using the given config
+// and the default command. The default address collector is used if the
+// config doesn't specify one.
+func NewK0sCloudProvider(config k0scloudprovider.Config) *K0sCloudProvider {
+	if config.AddressCollector == nil {
+		config.AddressCollector = k0scloudprovider.DefaultAddressCollector()
 	}
 
 	return newK0sCloudProvider(config, func() (k0scloudprovider.Command, error) {
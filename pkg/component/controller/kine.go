@@ -118,6 +118,9 @@ func (k *Kine) Start(ctx context.Context) error {
 			// invalid URLs that are understood by kine.
 			// https://github.com/k3s-io/kine/blob/v0.9.9/pkg/endpoint/endpoint.go#L274-L282
 			fmt.Sprintf("--listen-address=unix://%s", k.K0sVars.KineSocketPath),
+			// The default metrics port 8080 is prone to clashes, use etcd's
+			// peer port instead, which is unused when running kine.
+			fmt.Sprintf("--metrics-bind-address=localhost:%d", kineMetricsPort),
 		},
 		UID: k.uid,
 		GID: k.gid,
@@ -131,11 +134,20 @@ func (k *Kine) Stop() error {
 	return k.supervisor.Stop()
 }
 
+// kineMetricsPort is the port on which kine serves its metrics.
+const kineMetricsPort = 2380
+
 const hcKey = "/k0s-health-check"
 const hcValue = "value"
 
 func (k *Kine) Ready() error {
-	ok, err := k.bypassClient.Write(k.ctx, hcKey, hcValue, 64*time.Second)
+	return k.Healthy(k.ctx)
+}
+
+// Healthy implements [prober.Healthz]. It writes a value to the datastore and
+// reads it back.
+func (k *Kine) Healthy(ctx context.Context) error {
+	ok, err := k.bypassClient.Write(ctx, hcKey, hcValue, 64*time.Second)
 	if err != nil {
 		return fmt.Errorf("kine-etcd-health: %w", err)
 	}
@@ -143,7 +155,7 @@ func (k *Kine) Ready() error {
 		logrus.Warningf("kine-etcd-health: health-check value was not written")
 	}
 
-	v, err := k.bypassClient.Read(k.ctx, hcKey)
+	v, err := k.bypassClient.Read(ctx, hcKey)
 	if err != nil {
 		return fmt.Errorf("kine-etcd-health read: %w", err)
 	}
@@ -17,8 +17,14 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -34,13 +40,32 @@ import (
 	"github.com/k0sproject/k0s/internal/pkg/users"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/assets"
+	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/component/manager"
 	"github.com/k0sproject/k0s/pkg/component/prober"
 	"github.com/k0sproject/k0s/pkg/constant"
 	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
 	"github.com/k0sproject/k0s/pkg/supervisor"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 )
 
+// konnectivityHealthPort is the port on which konnectivity-server serves its
+// health endpoint.
+const konnectivityHealthPort = 8092
+
+// konnectivityAgentCertsSecretName is the name of the Secret in the
+// kube-system namespace that holds the client certificate of the konnectivity
+// agents if they authenticate via mTLS.
+const konnectivityAgentCertsSecretName = "konnectivity-agent-certs"
+
+// konnectivityAgentCertCheckInterval is the interval in which the client
+// certificate of the konnectivity agents is checked for renewal.
+const konnectivityAgentCertCheckInterval = 10 * time.Minute
+
 // Konnectivity implements the component interface of konnectivity server
 type Konnectivity struct {
 	K0sVars    constant.CfgVars
@@ -49,6 +74,8 @@ type Konnectivity struct {
 	// used for lease lock
 	KubeClientFactory kubeutil.ClientFactoryInterface
 	NodeConfig        *v1beta1.ClusterConfig
+	// issues the client certificate of the agents in mTLS mode
+	CertManager certificate.Manager
 
 	supervisor          *supervisor.Supervisor
 	uid                 int
@@ -60,6 +87,7 @@ type Konnectivity struct {
 	leaseCounterRunning bool
 	previousConfig      konnectivityAgentConfig
 	agentManifestLock   sync.Mutex
+	agentCertChecksum   string
 	*prober.EventEmitter
 }
 
@@ -104,6 +132,7 @@ func (k *Konnectivity) Start(ctx context.Context) error {
 	ctx, k.stopFunc = context.WithCancel(ctx)
 
 	go k.runServer(ctx)
+	go k.runAgentCertReconciler(ctx)
 
 	return nil
 }
@@ -111,12 +140,18 @@ func (k *Konnectivity) Start(ctx context.Context) error {
 // Reconcile detects changes in configuration and applies them to the component
 func (k *Konnectivity) Reconcile(ctx context.Context, clusterCfg *v1beta1.ClusterConfig) error {
 	k.clusterConfig = clusterCfg
-	if !k.SingleNode {
+	if count := clusterCfg.Spec.Konnectivity.ServerCount; count > 0 {
+		// The server count is fixed, no need to watch the controller leases
+		k.serverCountChan <- int(count)
+	} else if !k.SingleNode {
 		go k.runLeaseCounter(ctx)
 	} else {
 		// It's a buffered channel so once we start the runServer routine it'll pick this up and just sees it never changing
 		k.serverCountChan <- 1
 	}
+	if clusterCfg.Spec.Konnectivity.IsMTLS() {
+		return k.reconcileAgentCertificate(ctx)
+	}
 	return k.writeKonnectivityAgent()
 }
 
@@ -125,15 +160,16 @@ func (k *Konnectivity) defaultArgs() stringmap.StringMap {
 	if err != nil {
 		logrus.Errorf("failed to fetch machine ID for konnectivity-server")
 	}
-	return stringmap.StringMap{
+	args := stringmap.StringMap{
 		"--uds-name":                 filepath.Join(k.K0sVars.KonnectivitySocketDir, "konnectivity-server.sock"),
-		"--cluster-cert":             filepath.Join(k.K0sVars.CertRootDir, "server.crt"),
-		"--cluster-key":              filepath.Join(k.K0sVars.CertRootDir, "server.key"),
+		"--cluster-cert":             filepath.Join(k.K0sVars.CertRootDir, "konnectivity-server.crt"),
+		"--cluster-key":              filepath.Join(k.K0sVars.CertRootDir, "konnectivity-server.key"),
 		"--kubeconfig":               k.K0sVars.KonnectivityKubeConfigPath,
 		"--mode":                     "grpc",
 		"--server-port":              "0",
 		"--agent-port":               fmt.Sprintf("%d", k.clusterConfig.Spec.Konnectivity.AgentPort),
 		"--admin-port":               fmt.Sprintf("%d", k.clusterConfig.Spec.Konnectivity.AdminPort),
+		"--health-port":              strconv.Itoa(konnectivityHealthPort),
 		"--agent-namespace":          "kube-system",
 		"--agent-service-account":    "konnectivity-agent",
 		"--authentication-audience":  "system:konnectivity-server",
@@ -146,6 +182,16 @@ func (k *Konnectivity) defaultArgs() stringmap.StringMap {
 		"--proxy-strategies":         "destHost,default",
 		"--cipher-suites":            constant.AllowedTLS12CipherSuiteNames(),
 	}
+
+	if k.clusterConfig.Spec.Konnectivity.IsMTLS() {
+		// Agents present a client certificate instead of a token.
+		delete(args, "--agent-namespace")
+		delete(args, "--agent-service-account")
+		delete(args, "--authentication-audience")
+		args["--cluster-ca-cert"] = certificate.CABundlePath(k.K0sVars.CertRootDir)
+	}
+
+	return args
 }
 
 // runs the supervisor and restarts if the calculated server count changes
@@ -162,13 +208,10 @@ func (k *Konnectivity) runServer(ctx context.Context) {
 				k.Emit("skipping konnectivity server start, cluster config not yet available")
 				continue
 			}
-			// restart only if the count actually changes and we've got the global config
-			if count != k.serverCount && k.clusterConfig != nil {
-				args := k.defaultArgs()
-				args["--server-count"] = strconv.Itoa(count)
-				if args.Equals(previousArgs) {
-					logrus.Info("no changes detected for konnectivity-server")
-				}
+			args := k.defaultArgs()
+			args["--server-count"] = strconv.Itoa(count)
+			// restart only if the count or the args actually change
+			if count != k.serverCount || !args.Equals(previousArgs) {
 				// Stop supervisor
 				if k.supervisor != nil {
 					k.EmitWithPayload("restarting konnectivity server due to server count change",
@@ -195,6 +238,7 @@ func (k *Konnectivity) runServer(ctx context.Context) {
 					continue
 				}
 				k.serverCount = count
+				previousArgs = args
 
 				if err := k.writeKonnectivityAgent(); err != nil {
 					k.EmitWithPayload("failed to write konnectivity agent config", err)
@@ -219,11 +263,28 @@ func (k *Konnectivity) Stop() error {
 	return k.supervisor.Stop()
 }
 
-func (k *Konnectivity) Healthy() error {
+// Healthy implements [prober.Healthz]. It queries the health endpoint of
+// konnectivity-server.
+func (k *Konnectivity) Healthy(ctx context.Context) error {
 	if k.clusterConfig == nil {
 		return fmt.Errorf("cluster config not yet available")
 	}
+	if k.supervisor == nil {
+		return errors.New("konnectivity-server not running")
+	}
 
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://localhost:%d/healthz", konnectivityHealthPort), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("expected 200 for konnectivity-server health check, got %d", resp.StatusCode)
+	}
 	return nil
 }
 
@@ -237,7 +298,47 @@ type konnectivityAgentConfig struct {
 	HostNetwork          bool
 	BindToNodeIP         bool
 	APIServerPortMapping string
+	JoinAPIPortMapping   string
 	FeatureGates         string
+	Kind                 string
+	Replicas             int32
+	Tolerations          string
+	Resources            string
+	MTLS                 bool
+	AgentCertsSecret     string
+	AgentCertChecksum    string
+}
+
+// konnectivityAgentContainer is a konnectivity agent container that is run in
+// each agent Pod.
+type konnectivityAgentContainer struct {
+	Name          string
+	AgentIDSuffix string
+	PortMapping   string
+	// The ports of the agent's health and admin servers. The agent's defaults
+	// are used if zero.
+	HealthPort uint16
+	AdminPort  uint16
+}
+
+// Containers returns the agent containers to be run in each agent Pod. If the
+// join API is tunneled, a second agent exposes it, since every agent maps a
+// single port only.
+func (c konnectivityAgentConfig) Containers() []konnectivityAgentContainer {
+	containers := []konnectivityAgentContainer{{
+		Name:        "konnectivity-agent",
+		PortMapping: c.APIServerPortMapping,
+	}}
+	if c.JoinAPIPortMapping != "" {
+		containers = append(containers, konnectivityAgentContainer{
+			Name:          "konnectivity-agent-join-api",
+			AgentIDSuffix: "-join-api",
+			PortMapping:   c.JoinAPIPortMapping,
+			HealthPort:    8095,
+			AdminPort:     8096,
+		})
+	}
+	return containers
 }
 
 func (k *Konnectivity) writeKonnectivityAgent() error {
@@ -258,10 +359,24 @@ func (k *Konnectivity) writeKonnectivityAgent() error {
 		PullPolicy:      k.clusterConfig.Spec.Images.DefaultPullPolicy,
 	}
 
+	if err := k.applyAgentSpec(&cfg); err != nil {
+		return err
+	}
+
+	if k.clusterConfig.Spec.Konnectivity.IsMTLS() {
+		cfg.MTLS = true
+		cfg.AgentCertsSecret = konnectivityAgentCertsSecretName
+		// Restart the agents whenever their certificate changes.
+		cfg.AgentCertChecksum = k.agentCertChecksum
+	}
+
 	if k.NodeConfig.Spec.API.TunneledNetworkingMode {
 		cfg.HostNetwork = true
 		cfg.BindToNodeIP = true // agent needs to listen on the node IP to be on pair with the tunneled network reconciler
 		cfg.APIServerPortMapping = fmt.Sprintf("6443:localhost:%d", k.clusterConfig.Spec.API.Port)
+		if k.NodeConfig.Spec.API.TunneledJoinAPI {
+			cfg.JoinAPIPortMapping = fmt.Sprintf("9443:localhost:%d", k.NodeConfig.Spec.API.K0sAPIPort)
+		}
 	} else {
 		cfg.FeatureGates = "NodeToMasterTraffic=false"
 	}
@@ -327,6 +442,174 @@ func (k *Konnectivity) writeKonnectivityAgent() error {
 	return nil
 }
 
+// applyAgentSpec renders the agent settings of the cluster config into cfg.
+func (k *Konnectivity) applyAgentSpec(cfg *konnectivityAgentConfig) (err error) {
+	cfg.Kind = string(v1beta1.KonnectivityAgentModeDaemonSet)
+	agent := k.clusterConfig.Spec.Konnectivity.Agent
+	if agent == nil {
+		return nil
+	}
+
+	if agent.Mode == v1beta1.KonnectivityAgentModeDeployment {
+		cfg.Kind = string(v1beta1.KonnectivityAgentModeDeployment)
+		cfg.Replicas = 2
+		if agent.Replicas != nil {
+			cfg.Replicas = *agent.Replicas
+		}
+	}
+	if len(agent.Tolerations) > 0 {
+		if cfg.Tolerations, err = toYAML(agent.Tolerations); err != nil {
+			return err
+		}
+	}
+	if agent.Resources != nil {
+		if cfg.Resources, err = toYAML(agent.Resources); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// runAgentCertReconciler periodically renews the client certificate of the
+// agents, if they authenticate via mTLS.
+func (k *Konnectivity) runAgentCertReconciler(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if k.clusterConfig == nil || !k.clusterConfig.Spec.Konnectivity.IsMTLS() {
+			return
+		}
+		if err := k.reconcileAgentCertificate(ctx); err != nil {
+			k.log.WithError(err).Error("Failed to reconcile konnectivity agent certificate")
+		}
+	}, konnectivityAgentCertCheckInterval)
+}
+
+// reconcileAgentCertificate makes sure that the client certificate of the
+// agents is present and valid, and updates the agent manifest accordingly.
+func (k *Konnectivity) reconcileAgentCertificate(ctx context.Context) error {
+	checksum, err := k.ensureAgentCertificate(ctx)
+	if err != nil {
+		return err
+	}
+
+	k.agentManifestLock.Lock()
+	k.agentCertChecksum = checksum
+	k.agentManifestLock.Unlock()
+
+	return k.writeKonnectivityAgent()
+}
+
+// ensureAgentCertificate stores a client certificate for the agents, issued by
+// the cluster CA, in the agent certificate Secret. The certificate is shared by
+// all controllers. It's only replaced if it's due for renewal, so that the
+// controllers don't overwrite each other's certificates. Returns the checksum
+// of the stored certificate.
+func (k *Konnectivity) ensureAgentCertificate(ctx context.Context) (string, error) {
+	client, err := k.KubeClientFactory.GetClient()
+	if err != nil {
+		return "", err
+	}
+	caBundle, err := certificate.ReadCABundle(k.K0sVars.CertRootDir)
+	if err != nil {
+		return "", err
+	}
+
+	secrets := client.CoreV1().Secrets(metav1.NamespaceSystem)
+	secret, err := secrets.Get(ctx, konnectivityAgentCertsSecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret = nil
+	} else if err != nil {
+		return "", err
+	} else if !agentCertNeedsRenewal(secret.Data, caBundle, time.Now()) {
+		return agentCertChecksum(secret.Data), nil
+	}
+
+	cert, err := k.CertManager.IssueCertificate(certificate.Request{
+		Name:   "konnectivity-agent",
+		CN:     "konnectivity-agent",
+		O:      "kubernetes",
+		CACert: filepath.Join(k.K0sVars.CertRootDir, "ca.crt"),
+		CAKey:  filepath.Join(k.K0sVars.CertRootDir, "ca.key"),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to issue konnectivity agent certificate: %w", err)
+	}
+	data := map[string][]byte{
+		corev1.TLSCertKey:       []byte(cert.Cert),
+		corev1.TLSPrivateKeyKey: []byte(cert.Key),
+		"ca.crt":                caBundle,
+	}
+
+	if secret == nil {
+		_, err = secrets.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      konnectivityAgentCertsSecretName,
+				Namespace: metav1.NamespaceSystem,
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}, metav1.CreateOptions{})
+	} else {
+		secret.Data = data
+		_, err = secrets.Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	if apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err) {
+		// Another controller has been faster, use its certificate.
+		if secret, err = secrets.Get(ctx, konnectivityAgentCertsSecretName, metav1.GetOptions{}); err != nil {
+			return "", err
+		}
+		return agentCertChecksum(secret.Data), nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to store konnectivity agent certificate: %w", err)
+	}
+
+	k.log.Info("Issued new konnectivity agent certificate")
+	return agentCertChecksum(data), nil
+}
+
+// agentCertNeedsRenewal checks if the agent certificate in the given Secret
+// data needs to be replaced. That's the case if it's not valid for client
+// authentication against the given CA bundle, if the bundle changed, or if two
+// thirds of its lifetime have passed.
+func agentCertNeedsRenewal(data map[string][]byte, caBundle []byte, now time.Time) bool {
+	if !bytes.Equal(data["ca.crt"], caBundle) || len(data[corev1.TLSPrivateKeyKey]) == 0 {
+		return true
+	}
+
+	block, _ := pem.Decode(data[corev1.TLSCertKey])
+	if block == nil {
+		return true
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return true
+	}
+
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caBundle) {
+		return true
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:       roots,
+		CurrentTime: now,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		return true
+	}
+
+	lifetime := cert.NotAfter.Sub(cert.NotBefore)
+	return now.After(cert.NotBefore.Add(lifetime * 2 / 3))
+}
+
+// agentCertChecksum returns a checksum of the agent certificate and the CA
+// bundle in the given Secret data.
+func agentCertChecksum(data map[string][]byte) string {
+	h := sha256.New()
+	h.Write(data[corev1.TLSCertKey])
+	h.Write(data["ca.crt"])
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
 func (k *Konnectivity) runLeaseCounter(ctx context.Context) {
 	if k.leaseCounterRunning {
 		return
@@ -341,11 +624,23 @@ func (k *Konnectivity) runLeaseCounter(ctx context.Context) {
 			logrus.Info("stopping konnectivity lease counter")
 			return
 		case <-ticker.C:
+			if k.clusterConfig.Spec.Konnectivity.ServerCount > 0 {
+				// The server count has been fixed in the meantime
+				continue
+			}
 			count, err := k.countLeaseHolders(ctx)
 			if err != nil {
 				logrus.Errorf("failed to count controller leases: %s", err)
 				continue
 			}
+			// This controller is running, even if its own lease expired
+			// temporarily. Never tell konnectivity that there are no servers.
+			if count < 1 {
+				count = 1
+			}
+			if count < k.serverCount {
+				logrus.Infof("controller count decreased from %d to %d, updating konnectivity server count", k.serverCount, count)
+			}
 			k.serverCountChan <- count
 		}
 	}
@@ -385,15 +680,18 @@ metadata:
     kubernetes.io/cluster-service: "true"
 ---
 apiVersion: apps/v1
-# Alternatively, you can deploy the agents as Deployments. It is not necessary
-# to have an agent on each node.
-kind: DaemonSet
+# The agents run as a DaemonSet by default. Alternatively, they can be deployed
+# as a Deployment, as it is not necessary to have an agent on each node.
+kind: {{ .Kind }}
 metadata:
   labels:
     k8s-app: konnectivity-agent
   namespace: kube-system
   name: konnectivity-agent
 spec:
+  {{- if eq .Kind "Deployment" }}
+  replicas: {{ .Replicas }}
+  {{- end }}
   selector:
     matchLabels:
       k8s-app: konnectivity-agent
@@ -404,25 +702,45 @@ spec:
       annotations:
         prometheus.io/scrape: 'true'
         prometheus.io/port: '8093'
+        {{- if .MTLS }}
+        k0s.k0sproject.io/agent-cert-checksum: "{{ .AgentCertChecksum }}"
+        {{- end }}
     spec:
       nodeSelector:
         kubernetes.io/os: linux
       priorityClassName: system-cluster-critical
       tolerations:
+      {{- if .Tolerations }}
+{{ .Tolerations | indent 8 }}
+      {{- else }}
         - operator: Exists
+      {{- end }}
+      {{- if eq .Kind "Deployment" }}
+      # Spread the agents across the nodes
+      affinity:
+        podAntiAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 100
+            podAffinityTerm:
+              topologyKey: kubernetes.io/hostname
+              labelSelector:
+                matchLabels:
+                  k8s-app: konnectivity-agent
+      {{- end }}
       {{- if .HostNetwork }}
       hostNetwork: true
       {{- end }}
       containers:
-        - image: {{ .Image }}
-          imagePullPolicy: {{ .PullPolicy }}
-          name: konnectivity-agent
+        {{- range .Containers }}
+        - image: {{ $.Image }}
+          imagePullPolicy: {{ $.PullPolicy }}
+          name: {{ .Name }}
           command: ["/proxy-agent"]
           env:
               # the variable is not in a use
               # we need it to have agent restarted on server count change
               - name: K0S_CONTROLLER_COUNT
-                value: "{{ .ServerCount }}"
+                value: "{{ $.ServerCount }}"
 
               - name: NODE_IP
                 valueFrom:
@@ -430,36 +748,65 @@ spec:
                     fieldPath: status.hostIP
           args:
             - --logtostderr=true
+            {{- if $.MTLS }}
+            - --ca-cert=/etc/konnectivity-agent/pki/ca.crt
+            - --agent-cert=/etc/konnectivity-agent/pki/tls.crt
+            - --agent-key=/etc/konnectivity-agent/pki/tls.key
+            {{- else }}
             - --ca-cert=/var/run/secrets/kubernetes.io/serviceaccount/ca.crt
-            - --proxy-server-host={{ .ProxyServerHost }}
-            - --proxy-server-port={{ .ProxyServerPort }}
             - --service-account-token-path=/var/run/secrets/tokens/konnectivity-agent-token
+            {{- end }}
+            - --proxy-server-host={{ $.ProxyServerHost }}
+            - --proxy-server-port={{ $.ProxyServerPort }}
             - --agent-identifiers=host=$(NODE_IP)
-            - --agent-id=$(NODE_IP)
-              {{- if .BindToNodeIP }}
+            - --agent-id=$(NODE_IP){{ .AgentIDSuffix }}
+              {{- if .HealthPort }}
+            - --health-server-port={{ .HealthPort }}
+              {{- end }}
+              {{- if .AdminPort }}
+            - --admin-server-port={{ .AdminPort }}
+              {{- end }}
+              {{- if $.BindToNodeIP }}
             - --bind-address=$(NODE_IP)
               {{- end }}
-              {{- if .APIServerPortMapping }}
-            - --apiserver-port-mapping={{ .APIServerPortMapping }}
+              {{- if .PortMapping }}
+            - --apiserver-port-mapping={{ .PortMapping }}
               {{- end }}
-              {{- if .FeatureGates }}
-            - "--feature-gates={{ .FeatureGates }}"
+              {{- if $.FeatureGates }}
+            - "--feature-gates={{ $.FeatureGates }}"
               {{- end }}
+          {{- if $.Resources }}
+          resources:
+{{ $.Resources | indent 12 }}
+          {{- end }}
           volumeMounts:
+            {{- if $.MTLS }}
+            - mountPath: /etc/konnectivity-agent/pki
+              name: konnectivity-agent-certs
+              readOnly: true
+            {{- else }}
             - mountPath: /var/run/secrets/tokens
               name: konnectivity-agent-token
+            {{- end }}
           livenessProbe:
             httpGet:
-              port: 8093
+              port: {{ if .HealthPort }}{{ .HealthPort }}{{ else }}8093{{ end }}
               path: /healthz
             initialDelaySeconds: 15
             timeoutSeconds: 15
+        {{- end }}
       serviceAccountName: konnectivity-agent
       volumes:
+        {{- if .MTLS }}
+        - name: konnectivity-agent-certs
+          secret:
+            secretName: {{ .AgentCertsSecret }}
+        {{- else }}
         - name: konnectivity-agent-token
           projected:
             sources:
               - serviceAccountToken:
                   path: konnectivity-agent-token
                   audience: system:konnectivity-server
+        {{- end }}
 `
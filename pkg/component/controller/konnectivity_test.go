@@ -0,0 +1,197 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/internal/testutil"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/prober"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+)
+
+func TestKonnectivityAgentManifests(t *testing.T) {
+	render := func(t *testing.T, clusterConfig *v1beta1.ClusterConfig) *unstructured.Unstructured {
+		k0sVars := constant.GetConfig(t.TempDir())
+		k := &Konnectivity{
+			K0sVars:       k0sVars,
+			NodeConfig:    clusterConfig,
+			clusterConfig: clusterConfig,
+			log:           logrus.WithField("test", t.Name()),
+			EventEmitter:  prober.NewEventEmitter(),
+		}
+		require.NoError(t, k.writeKonnectivityAgent())
+
+		data, err := os.ReadFile(filepath.Join(k0sVars.ManifestsDir, "konnectivity", "konnectivity-agent.yaml"))
+		require.NoError(t, err)
+		resources, err := testutil.ParseManifests(data)
+		require.NoError(t, err)
+		for _, r := range resources {
+			if r.GetName() == "konnectivity-agent" && (r.GetKind() == "DaemonSet" || r.GetKind() == "Deployment") {
+				return r
+			}
+		}
+		require.Fail(t, "konnectivity-agent workload not found")
+		return nil
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		agent := render(t, v1beta1.DefaultClusterConfig())
+		require.Equal(t, "DaemonSet", agent.GetKind())
+
+		var ds appsv1.DaemonSet
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(agent.Object, &ds))
+		assert.Equal(t, []corev1.Toleration{{Operator: corev1.TolerationOpExists}}, ds.Spec.Template.Spec.Tolerations)
+		assert.Empty(t, ds.Spec.Template.Spec.Containers[0].Resources)
+		assert.Len(t, ds.Spec.Template.Spec.Containers, 1)
+	})
+
+	t.Run("deployment", func(t *testing.T) {
+		clusterConfig := v1beta1.DefaultClusterConfig()
+		clusterConfig.Spec.Konnectivity.Agent = &v1beta1.KonnectivityAgentSpec{
+			Mode:     v1beta1.KonnectivityAgentModeDeployment,
+			Replicas: pointer.Int32(3),
+			Tolerations: []corev1.Toleration{
+				{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "infra", Effect: corev1.TaintEffectNoSchedule},
+			},
+			Resources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("32Mi")},
+			},
+		}
+
+		agent := render(t, clusterConfig)
+		require.Equal(t, "Deployment", agent.GetKind())
+
+		var deployment appsv1.Deployment
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(agent.Object, &deployment))
+		assert.Equal(t, pointer.Int32(3), deployment.Spec.Replicas)
+		assert.Equal(t, clusterConfig.Spec.Konnectivity.Agent.Tolerations, deployment.Spec.Template.Spec.Tolerations)
+		assert.Equal(t, *clusterConfig.Spec.Konnectivity.Agent.Resources, deployment.Spec.Template.Spec.Containers[0].Resources)
+		if assert.NotNil(t, deployment.Spec.Template.Spec.Affinity) {
+			assert.NotNil(t, deployment.Spec.Template.Spec.Affinity.PodAntiAffinity)
+		}
+	})
+
+	t.Run("mtls", func(t *testing.T) {
+		clusterConfig := v1beta1.DefaultClusterConfig()
+		clusterConfig.Spec.Konnectivity.AuthMode = v1beta1.KonnectivityAuthModeMTLS
+
+		agent := render(t, clusterConfig)
+
+		var ds appsv1.DaemonSet
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(agent.Object, &ds))
+		podSpec := ds.Spec.Template.Spec
+		assert.Contains(t, podSpec.Containers[0].Args, "--agent-cert=/etc/konnectivity-agent/pki/tls.crt")
+		assert.Contains(t, podSpec.Containers[0].Args, "--ca-cert=/etc/konnectivity-agent/pki/ca.crt")
+		assert.NotContains(t, podSpec.Containers[0].Args, "--service-account-token-path=/var/run/secrets/tokens/konnectivity-agent-token")
+		if assert.Len(t, podSpec.Volumes, 1) && assert.NotNil(t, podSpec.Volumes[0].Secret) {
+			assert.Equal(t, "konnectivity-agent-certs", podSpec.Volumes[0].Secret.SecretName)
+		}
+		assert.Contains(t, ds.Spec.Template.Annotations, "k0s.k0sproject.io/agent-cert-checksum")
+	})
+
+	t.Run("tunneled_join_api", func(t *testing.T) {
+		clusterConfig := v1beta1.DefaultClusterConfig()
+		clusterConfig.Spec.API.Port = 7443
+		clusterConfig.Spec.API.K0sAPIPort = 7444
+		clusterConfig.Spec.API.TunneledNetworkingMode = true
+		clusterConfig.Spec.API.TunneledJoinAPI = true
+
+		agent := render(t, clusterConfig)
+
+		var ds appsv1.DaemonSet
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(agent.Object, &ds))
+		podSpec := ds.Spec.Template.Spec
+		assert.True(t, podSpec.HostNetwork)
+		require.Len(t, podSpec.Containers, 2)
+		assert.Equal(t, "konnectivity-agent", podSpec.Containers[0].Name)
+		assert.Contains(t, podSpec.Containers[0].Args, "--apiserver-port-mapping=6443:localhost:7443")
+		assert.Contains(t, podSpec.Containers[0].Args, "--agent-id=$(NODE_IP)")
+		assert.Equal(t, "konnectivity-agent-join-api", podSpec.Containers[1].Name)
+		assert.Contains(t, podSpec.Containers[1].Args, "--apiserver-port-mapping=9443:localhost:7444")
+		assert.Contains(t, podSpec.Containers[1].Args, "--agent-id=$(NODE_IP)-join-api")
+		assert.Contains(t, podSpec.Containers[1].Args, "--health-server-port=8095")
+		assert.Equal(t, int32(8095), podSpec.Containers[1].LivenessProbe.HTTPGet.Port.IntVal)
+	})
+}
+
+func TestAgentCertNeedsRenewal(t *testing.T) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "kubernetes-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(10 * 365 * 24 * time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	require.NoError(t, err)
+	caCert, err := x509.ParseCertificate(caDER)
+	require.NoError(t, err)
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+
+	issue := func(notBefore time.Time, usage x509.ExtKeyUsage) []byte {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{CommonName: "konnectivity-agent"},
+			NotBefore:    notBefore,
+			NotAfter:     notBefore.Add(30 * 24 * time.Hour),
+			ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		}, caCert, key.Public(), caKey)
+		require.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+
+	now := time.Now()
+	fresh := issue(now.Add(-time.Hour), x509.ExtKeyUsageClientAuth)
+	data := func(cert, ca []byte) map[string][]byte {
+		return map[string][]byte{"tls.crt": cert, "tls.key": []byte("key"), "ca.crt": ca}
+	}
+
+	assert.False(t, agentCertNeedsRenewal(data(fresh, caPEM), caPEM, now), "fresh certificate")
+	assert.True(t, agentCertNeedsRenewal(data(fresh, caPEM), caPEM, now.Add(21*24*time.Hour)), "two thirds of the lifetime passed")
+	assert.True(t, agentCertNeedsRenewal(data(fresh, caPEM), append(caPEM, caPEM...), now), "CA bundle changed")
+	assert.True(t, agentCertNeedsRenewal(data(issue(now.Add(-time.Hour), x509.ExtKeyUsageServerAuth), caPEM), caPEM, now), "no client auth usage")
+	assert.True(t, agentCertNeedsRenewal(data([]byte("garbage"), caPEM), caPEM, now), "unparsable certificate")
+	assert.True(t, agentCertNeedsRenewal(nil, caPEM, now), "no certificate")
+}
@@ -149,12 +149,33 @@ func (k *KubeProxy) getConfig(clusterConfig *v1beta1.ClusterConfig) (proxyConfig
 	}
 	cfg.IPTables = string(iptables)
 
-	ipvs, err := json.Marshal(clusterConfig.Spec.Network.KubeProxy.IPVS)
+	ipvsConfig := clusterConfig.Spec.Network.KubeProxy.IPVS
+	if cfg.Mode == v1beta1.ModeIPVS && clusterConfig.Spec.Network.ServiceLoadBalancer.IsEnabled() && (ipvsConfig == nil || !ipvsConfig.StrictARP) {
+		// The service load balancer announces its addresses via ARP from a
+		// single node. In IPVS mode, kube-proxy would answer ARP requests for
+		// them on all nodes, unless strict ARP is enabled.
+		k.log.Info("Enabling strict ARP for IPVS, as required by the service load balancer")
+		if ipvsConfig == nil {
+			ipvsConfig = v1beta1.DefaultKubeProxyIPVS()
+		} else {
+			ipvsConfig = ipvsConfig.DeepCopy()
+		}
+		ipvsConfig.StrictARP = true
+	}
+	ipvs, err := json.Marshal(ipvsConfig)
 	if err != nil {
 		return proxyConfig{}, err
 	}
 	cfg.IPVS = string(ipvs)
 
+	if cfg.Mode == v1beta1.ModeNFTables {
+		nftables, err := json.Marshal(clusterConfig.Spec.Network.KubeProxy.NFTables)
+		if err != nil {
+			return proxyConfig{}, err
+		}
+		cfg.NFTables = string(nftables)
+	}
+
 	return cfg, nil
 }
 
@@ -168,6 +189,7 @@ type proxyConfig struct {
 	MetricsBindAddress   string
 	IPTables             string
 	IPVS                 string
+	NFTables             string
 	FeatureGates         map[string]bool
 }
 
@@ -279,6 +301,9 @@ data:
     hostnameOverride: ""
     iptables: {{ .IPTables }}
     ipvs: {{ .IPVS }}
+{{- if .NFTables }}
+    nftables: {{ .NFTables }}
+{{- end }}
     kind: KubeProxyConfiguration
     metricsBindAddress: {{ .MetricsBindAddress }}
     nodePortAddresses: null
@@ -22,7 +22,10 @@ import (
 	"testing"
 
 	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"sigs.k8s.io/yaml"
 )
 
@@ -58,4 +61,50 @@ func TestKubeProxyConfig(t *testing.T) {
 		assert.Equal(t, config.FeatureGates, getFeatureGates(config))
 	})
 
+	t.Run("nftables", func(t *testing.T) {
+		getKubeProxyConfig := func(cfg proxyConfig) map[string]interface{} {
+			tw := templatewriter.TemplateWriter{
+				Name:     "kube-proxy-config",
+				Template: strings.Split(proxyTemplate, "---")[4],
+				Data:     cfg,
+			}
+			b := bytes.NewBuffer([]byte{})
+			assert.NoError(t, tw.WriteToBuffer(b))
+			m := map[string]interface{}{}
+			assert.NoError(t, yaml.Unmarshal(b.Bytes(), &m))
+			kubeProxyConfigData := map[string]interface{}{}
+			assert.NoError(t, yaml.Unmarshal([]byte(m["data"].(map[string]interface{})["config.conf"].(string)), &kubeProxyConfigData))
+			return kubeProxyConfigData
+		}
+
+		assert.NotContains(t, getKubeProxyConfig(proxyConfig{Mode: "iptables", IPTables: "{}", IPVS: "{}"}), "nftables")
+
+		rendered := getKubeProxyConfig(proxyConfig{Mode: "nftables", IPTables: "{}", IPVS: "{}", NFTables: `{"masqueradeAll":true,"syncPeriod":"30s"}`})
+		assert.Equal(t, "nftables", rendered["mode"])
+		assert.Equal(t, map[string]interface{}{"masqueradeAll": true, "syncPeriod": "30s"}, rendered["nftables"])
+	})
+
+	t.Run("strict_arp_for_service_load_balancer", func(t *testing.T) {
+		clusterConfig := v1beta1.DefaultClusterConfig()
+		underTest := NewKubeProxy(constant.GetConfig(t.TempDir()), clusterConfig)
+		getIPVS := func() map[string]interface{} {
+			cfg, err := underTest.getConfig(clusterConfig)
+			require.NoError(t, err)
+			ipvs := map[string]interface{}{}
+			require.NoError(t, yaml.Unmarshal([]byte(cfg.IPVS), &ipvs))
+			return ipvs
+		}
+
+		clusterConfig.Spec.Network.ServiceLoadBalancer = v1beta1.DefaultServiceLoadBalancer()
+		clusterConfig.Spec.Network.ServiceLoadBalancer.Enabled = true
+		assert.NotContains(t, getIPVS(), "strictARP", "iptables mode shouldn't be affected")
+
+		clusterConfig.Spec.Network.KubeProxy.Mode = v1beta1.ModeIPVS
+		assert.Equal(t, true, getIPVS()["strictARP"])
+		assert.False(t, clusterConfig.Spec.Network.KubeProxy.IPVS.StrictARP, "cluster config has been modified")
+
+		clusterConfig.Spec.Network.ServiceLoadBalancer.Enabled = false
+		assert.NotContains(t, getIPVS(), "strictARP")
+	})
+
 }
@@ -20,6 +20,10 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
@@ -43,17 +47,25 @@ var _ manager.Component = (*KubeRouter)(nil)
 var _ manager.Reconciler = (*KubeRouter)(nil)
 
 type kubeRouterConfig struct {
-	MTU               int
-	AutoMTU           bool
-	MetricsPort       int
-	CNIInstallerImage string
-	CNIImage          string
-	GlobalHairpin     bool
-	CNIHairpin        bool
-	IPMasq            bool
-	PeerRouterIPs     string
-	PeerRouterASNs    string
-	PullPolicy        string
+	MTU                     int
+	AutoMTU                 bool
+	MetricsPort             int
+	CNIInstallerImage       string
+	CNIImage                string
+	GlobalHairpin           bool
+	CNIHairpin              bool
+	IPMasq                  bool
+	PeerRouterIPs           string
+	PeerRouterASNs          string
+	PeerRouterPorts         string
+	RouterID                string
+	ClusterASN              uint32
+	AdvertiseClusterIP      bool
+	AdvertiseExternalIP     bool
+	AdvertiseLoadBalancerIP bool
+	AdvertisePodCIDR        bool
+	ExtraArgs               []string
+	PullPolicy              string
 }
 
 // NewKubeRouter creates new KubeRouter reconciler component
@@ -93,6 +105,34 @@ func getHairpinConfig(cfg *kubeRouterConfig, krc *v1beta1.KubeRouter) {
 	}
 }
 
+func getBGPPeersConfig(cfg *kubeRouterConfig, peers []v1beta1.KubeRouterBGPPeer) {
+	if len(peers) == 0 {
+		return
+	}
+
+	ips := make([]string, len(peers))
+	asns := make([]string, len(peers))
+	ports := make([]string, len(peers))
+	customPorts := false
+	for i, peer := range peers {
+		ips[i] = peer.IP
+		asns[i] = strconv.FormatUint(uint64(peer.ASN), 10)
+		port := peer.Port
+		if port == 0 {
+			port = 179
+		} else {
+			customPorts = true
+		}
+		ports[i] = strconv.FormatUint(uint64(port), 10)
+	}
+
+	cfg.PeerRouterIPs = strings.Join(ips, ",")
+	cfg.PeerRouterASNs = strings.Join(asns, ",")
+	if customPorts {
+		cfg.PeerRouterPorts = strings.Join(ports, ",")
+	}
+}
+
 // Reconcile detects changes in configuration and applies them to the component
 func (k *KubeRouter) Reconcile(_ context.Context, clusterConfig *v1beta1.ClusterConfig) error {
 	logrus.Debug("reconcile method called for: KubeRouter")
@@ -114,11 +154,27 @@ func (k *KubeRouter) Reconcile(_ context.Context, clusterConfig *v1beta1.Cluster
 		IPMasq:            clusterConfig.Spec.Network.KubeRouter.IPMasq,
 		CNIImage:          clusterConfig.Spec.Images.KubeRouter.CNI.URI(),
 		CNIInstallerImage: clusterConfig.Spec.Images.KubeRouter.CNIInstaller.URI(),
+		RouterID:          clusterConfig.Spec.Network.KubeRouter.RouterID,
+		ClusterASN:        clusterConfig.Spec.Network.KubeRouter.ClusterASN,
+		AdvertisePodCIDR:  true,
 		PullPolicy:        clusterConfig.Spec.Images.DefaultPullPolicy,
 	}
 	getHairpinConfig(&cfg, clusterConfig.Spec.Network.KubeRouter)
+	getBGPPeersConfig(&cfg, clusterConfig.Spec.Network.KubeRouter.BGPPeers)
+	if adv := clusterConfig.Spec.Network.KubeRouter.Advertise; adv != nil {
+		cfg.AdvertiseClusterIP = adv.ClusterIP
+		cfg.AdvertiseExternalIP = adv.ExternalIP
+		cfg.AdvertiseLoadBalancerIP = adv.LoadBalancerIP
+		if adv.PodCIDR != nil {
+			cfg.AdvertisePodCIDR = *adv.PodCIDR
+		}
+	}
+	for name, value := range clusterConfig.Spec.Network.KubeRouter.ExtraArgs {
+		cfg.ExtraArgs = append(cfg.ExtraArgs, fmt.Sprintf("--%s=%s", name, value))
+	}
+	sort.Strings(cfg.ExtraArgs)
 
-	if cfg == k.previousConfig {
+	if reflect.DeepEqual(cfg, k.previousConfig) {
 		k.log.Info("config matches with previous, not reconciling anything")
 		return nil
 	}
@@ -292,6 +348,30 @@ spec:
         {{- if .PeerRouterASNs }}
         - "--peer-router-asns={{ .PeerRouterASNs }}"
         {{- end }}
+        {{- if .PeerRouterPorts }}
+        - "--peer-router-ports={{ .PeerRouterPorts }}"
+        {{- end }}
+        {{- if .RouterID }}
+        - "--router-id={{ .RouterID }}"
+        {{- end }}
+        {{- if .ClusterASN }}
+        - "--cluster-asn={{ .ClusterASN }}"
+        {{- end }}
+        {{- if .AdvertiseClusterIP }}
+        - "--advertise-cluster-ip=true"
+        {{- end }}
+        {{- if .AdvertiseExternalIP }}
+        - "--advertise-external-ip=true"
+        {{- end }}
+        {{- if .AdvertiseLoadBalancerIP }}
+        - "--advertise-loadbalancer-ip=true"
+        {{- end }}
+        {{- if not .AdvertisePodCIDR }}
+        - "--advertise-pod-cidr=false"
+        {{- end }}
+        {{- range .ExtraArgs }}
+        - {{ printf "%q" . }}
+        {{- end }}
         env:
         - name: NODE_NAME
           valueFrom:
@@ -179,6 +179,52 @@ func TestKubeRouterManualMTUManifests(t *testing.T) {
 	require.Equal(t, float64(1234), p.Dig("mtu"))
 }
 
+func TestKubeRouterBGPManifests(t *testing.T) {
+	k0sVars := constant.GetConfig(t.TempDir())
+	cfg := v1beta1.DefaultClusterConfig()
+	cfg.Spec.Network.Calico = nil
+	cfg.Spec.Network.Provider = "kuberouter"
+	cfg.Spec.Network.KubeRouter = v1beta1.DefaultKubeRouter()
+	cfg.Spec.Network.KubeRouter.BGPPeers = []v1beta1.KubeRouterBGPPeer{
+		{IP: "192.0.2.1", ASN: 64513},
+		{IP: "192.0.2.2", ASN: 64514, Port: 1179},
+	}
+	cfg.Spec.Network.KubeRouter.RouterID = "generate"
+	cfg.Spec.Network.KubeRouter.ClusterASN = 64512
+	podCIDR := false
+	cfg.Spec.Network.KubeRouter.Advertise = &v1beta1.KubeRouterAdvertise{
+		ClusterIP:      true,
+		LoadBalancerIP: true,
+		PodCIDR:        &podCIDR,
+	}
+	cfg.Spec.Network.KubeRouter.ExtraArgs = map[string]string{
+		"override-nexthop": "true",
+		"bgp-port":         "1790",
+	}
+	saver := inMemorySaver{}
+	kr := NewKubeRouter(k0sVars, saver)
+	require.NoError(t, kr.Reconcile(context.Background(), cfg))
+	require.NoError(t, kr.Stop())
+
+	resources, err := testutil.ParseManifests(saver["kube-router.yaml"])
+	require.NoError(t, err)
+	ds, err := findDaemonset(resources)
+	require.NoError(t, err)
+	require.NotNil(t, ds)
+
+	args := ds.Spec.Template.Spec.Containers[0].Args
+	assert.Contains(t, args, "--peer-router-ips=192.0.2.1,192.0.2.2")
+	assert.Contains(t, args, "--peer-router-asns=64513,64514")
+	assert.Contains(t, args, "--peer-router-ports=179,1179")
+	assert.Contains(t, args, "--router-id=generate")
+	assert.Contains(t, args, "--cluster-asn=64512")
+	assert.Contains(t, args, "--advertise-cluster-ip=true")
+	assert.NotContains(t, args, "--advertise-external-ip=true")
+	assert.Contains(t, args, "--advertise-loadbalancer-ip=true")
+	assert.Contains(t, args, "--advertise-pod-cidr=false")
+	assert.Equal(t, []string{"--bgp-port=1790", "--override-nexthop=true"}, args[len(args)-2:])
+}
+
 func findConfig(resources []*unstructured.Unstructured) (corev1.ConfigMap, error) {
 	var cm corev1.ConfigMap
 	for _, r := range resources {
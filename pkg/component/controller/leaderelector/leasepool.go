@@ -19,22 +19,41 @@ package leaderelector
 import (
 	"context"
 	"fmt"
+	"sync"
 	"sync/atomic"
+	"time"
 
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/component/manager"
 	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
 	"github.com/k0sproject/k0s/pkg/leaderelection"
 	"github.com/sirupsen/logrus"
 )
 
+// LeaseName is the name of the lease that's used to elect the leader among the
+// controllers.
+const LeaseName = "k0s-endpoint-reconciler"
+
+// ComponentLeaseName returns the name of the lease that's used to elect the
+// controller that reconciles the given component, if per-component leases are
+// enabled.
+func ComponentLeaseName(component string) string {
+	return "k0s-component-" + component
+}
+
 type LeasePool struct {
 	log *logrus.Entry
 
 	stopCh            chan struct{}
 	leaderStatus      atomic.Value
 	kubeClientFactory kubeutil.ClientFactoryInterface
+	name              string
+	config            *v1beta1.LeaderElectionSpec
 	leaseCancel       context.CancelFunc
 
+	statusMu sync.Mutex
+	status   Status
+
 	acquiredLeaseCallbacks []func()
 	lostLeaseCallbacks     []func()
 }
@@ -42,14 +61,36 @@ type LeasePool struct {
 var _ Interface = (*LeasePool)(nil)
 var _ manager.Component = (*LeasePool)(nil)
 
-// NewLeasePool creates a new leader elector using a Kubernetes lease pool.
-func NewLeasePool(kubeClientFactory kubeutil.ClientFactoryInterface) *LeasePool {
+// Status is the state of the leader election as observed by this controller.
+type Status struct {
+	// The name of the lease.
+	Lease string
+	// The identity of the current leader, if any.
+	Leader string
+	// Whether this controller is the leader.
+	IsLeader bool
+	// The number of times that the leader changed since this controller
+	// started.
+	Transitions int
+	// The time of the last leader change, if any.
+	LastTransitionTime time.Time
+}
+
+// NewLeasePool creates a new leader elector using the Kubernetes lease with the
+// given name. The default timings are used if config is nil.
+func NewLeasePool(kubeClientFactory kubeutil.ClientFactoryInterface, name string, config *v1beta1.LeaderElectionSpec) *LeasePool {
+	if config == nil {
+		config = v1beta1.DefaultLeaderElectionSpec()
+	}
 	d := atomic.Value{}
 	d.Store(false)
 	return &LeasePool{
 		stopCh:            make(chan struct{}),
 		kubeClientFactory: kubeClientFactory,
-		log:               logrus.WithFields(logrus.Fields{"component": "poolleaderelector"}),
+		name:              name,
+		config:            config,
+		status:            Status{Lease: name},
+		log:               logrus.WithFields(logrus.Fields{"component": "poolleaderelector", "lease": name}),
 		leaderStatus:      d,
 	}
 }
@@ -63,9 +104,13 @@ func (l *LeasePool) Start(ctx context.Context) error {
 	if err != nil {
 		return fmt.Errorf("can't create kubernetes rest client for lease pool: %v", err)
 	}
-	leasePool, err := leaderelection.NewLeasePool(ctx, client, "k0s-endpoint-reconciler",
+	leasePool, err := leaderelection.NewLeasePool(ctx, client, l.name,
 		leaderelection.WithLogger(l.log),
-		leaderelection.WithContext(ctx))
+		leaderelection.WithContext(ctx),
+		leaderelection.WithDuration(l.config.LeaseDuration.Duration),
+		leaderelection.WithRenewDeadline(l.config.RenewDeadline.Duration),
+		leaderelection.WithRetryPeriod(l.config.RetryPeriod.Duration),
+		leaderelection.WithNewLeaderCallback(l.observeLeader))
 	if err != nil {
 		return err
 	}
@@ -74,17 +119,18 @@ func (l *LeasePool) Start(ctx context.Context) error {
 		return err
 	}
 	l.leaseCancel = cancel
+	isLeaderGauge.WithLabelValues(l.name).Set(0)
 
 	go func() {
 		for {
 			select {
 			case <-events.AcquiredLease:
 				l.log.Info("acquired leader lease")
-				l.leaderStatus.Store(true)
+				l.setLeader(true)
 				runCallbacks(l.acquiredLeaseCallbacks)
 			case <-events.LostLease:
 				l.log.Info("lost leader lease")
-				l.leaderStatus.Store(false)
+				l.setLeader(false)
 				runCallbacks(l.lostLeaseCallbacks)
 			}
 		}
@@ -92,6 +138,42 @@ func (l *LeasePool) Start(ctx context.Context) error {
 	return nil
 }
 
+func (l *LeasePool) setLeader(isLeader bool) {
+	l.leaderStatus.Store(isLeader)
+
+	l.statusMu.Lock()
+	defer l.statusMu.Unlock()
+	l.status.IsLeader = isLeader
+	isLeaderGauge.WithLabelValues(l.name).Set(boolToFloat(isLeader))
+}
+
+// observeLeader records a change of the leader.
+func (l *LeasePool) observeLeader(identity string) {
+	l.statusMu.Lock()
+	defer l.statusMu.Unlock()
+
+	if l.status.Leader == identity {
+		return
+	}
+	l.log.Infof("observed new leader %q", identity)
+
+	// The first observation isn't a transition, the leader might have been
+	// the same for a long time.
+	if l.status.Leader != "" {
+		l.status.Transitions++
+		l.status.LastTransitionTime = time.Now()
+		transitionsTotal.WithLabelValues(l.name).Inc()
+	}
+	l.status.Leader = identity
+}
+
+// Status returns the state of the leader election.
+func (l *LeasePool) Status() Status {
+	l.statusMu.Lock()
+	defer l.statusMu.Unlock()
+	return l.status
+}
+
 func runCallbacks(callbacks []func()) {
 	for _, fn := range callbacks {
 		if fn != nil {
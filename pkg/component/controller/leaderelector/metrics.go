@@ -0,0 +1,48 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package leaderelector
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	isLeaderGauge = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "k0s",
+		Subsystem: "leader_election",
+		Name:      "is_leader",
+		Help:      "Whether this controller holds the leader lease (1) or not (0).",
+	}, []string{"lease"})
+
+	transitionsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "k0s",
+		Subsystem: "leader_election",
+		Name:      "transitions_total",
+		Help:      "Total number of leader changes observed by this controller.",
+	}, []string{"lease"})
+)
+
+func init() {
+	prometheus.MustRegister(isLeaderGauge, transitionsTotal)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -20,12 +20,21 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"os"
 	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 
 	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
@@ -47,19 +56,25 @@ type Metrics struct {
 
 	hostname   string
 	K0sVars    constant.CfgVars
+	nodeConfig *v1beta1.ClusterConfig
 	saver      manifestsSaver
 	restClient rest.Interface
+	discovery  discovery.CachedDiscoveryInterface
 
-	clusterConfig *v1beta1.ClusterConfig
-	tickerDone    context.CancelFunc
-	jobs          []*job
+	clusterConfig  *v1beta1.ClusterConfig
+	serviceMonitor bool
+	tickerDone     context.CancelFunc
+	jobs           []*job
+
+	// Whether konnectivity-server is running on this controller.
+	EnableKonnectivity bool
 }
 
 var _ manager.Component = (*Metrics)(nil)
 var _ manager.Reconciler = (*Metrics)(nil)
 
 // NewMetrics creates new Metrics reconciler
-func NewMetrics(k0sVars constant.CfgVars, saver manifestsSaver, clientCF kubernetes.ClientFactoryInterface) (*Metrics, error) {
+func NewMetrics(k0sVars constant.CfgVars, nodeConfig *v1beta1.ClusterConfig, saver manifestsSaver, clientCF kubernetes.ClientFactoryInterface) (*Metrics, error) {
 	hostname, err := os.Hostname()
 	if err != nil {
 		return nil, err
@@ -70,31 +85,76 @@ func NewMetrics(k0sVars constant.CfgVars, saver manifestsSaver, clientCF kuberne
 		return nil, fmt.Errorf("error getting REST client for metrics: %w", err)
 	}
 
+	discoveryClient, err := clientCF.GetDiscoveryClient()
+	if err != nil {
+		return nil, fmt.Errorf("error getting discovery client for metrics: %w", err)
+	}
+
 	return &Metrics{
 		log: logrus.WithFields(logrus.Fields{"component": "metrics"}),
 
 		hostname:   hostname,
 		K0sVars:    k0sVars,
+		nodeConfig: nodeConfig,
 		saver:      saver,
 		restClient: restClient,
+		discovery:  discoveryClient,
 	}, nil
 }
 
-// Init does nothing
+// Init creates the scrape jobs for the components running on this controller.
 func (m *Metrics) Init(_ context.Context) error {
+	adminCert := path.Join(m.K0sVars.CertRootDir, "admin.crt")
+	adminKey := path.Join(m.K0sVars.CertRootDir, "admin.key")
+
 	var j *job
-	j, err := m.newJob("kube-scheduler", "https://localhost:10259/metrics")
+	j, err := m.newJob(v1beta1.MetricsJobKubeScheduler, "https://localhost:10259/metrics", adminCert, adminKey)
 	if err != nil {
 		return err
 	}
 	m.jobs = append(m.jobs, j)
 
-	j, err = m.newJob("kube-controller-manager", "https://localhost:10257/metrics")
+	j, err = m.newJob(v1beta1.MetricsJobKubeControllerManager, "https://localhost:10257/metrics", adminCert, adminKey)
 	if err != nil {
 		return err
 	}
 	m.jobs = append(m.jobs, j)
 
+	switch storage := m.nodeConfig.Spec.Storage; storage.Type {
+	case v1beta1.EtcdStorageType:
+		if storage.Etcd.IsExternalClusterUsed() {
+			break
+		}
+		// etcd requires client certificates on its client port.
+		j, err = m.newJob(v1beta1.MetricsJobEtcd, "https://localhost:2379/metrics",
+			filepath.Join(m.K0sVars.CertRootDir, "apiserver-etcd-client.crt"),
+			filepath.Join(m.K0sVars.CertRootDir, "apiserver-etcd-client.key"),
+		)
+		if err != nil {
+			return err
+		}
+		m.jobs = append(m.jobs, j)
+
+	case v1beta1.KineStorageType:
+		j, err = m.newJob(v1beta1.MetricsJobKine, "http://localhost:"+strconv.Itoa(kineMetricsPort)+"/metrics", "", "")
+		if err != nil {
+			return err
+		}
+		m.jobs = append(m.jobs, j)
+	}
+
+	if m.EnableKonnectivity {
+		adminPort := strconv.Itoa(int(m.nodeConfig.Spec.Konnectivity.AdminPort))
+		j, err = m.newJob(v1beta1.MetricsJobKonnectivityServer, "http://localhost:"+adminPort+"/metrics", "", "")
+		if err != nil {
+			return err
+		}
+		m.jobs = append(m.jobs, j)
+	}
+
+	// k0s' own metrics, i.e. the ones of its components and control loops.
+	m.jobs = append(m.jobs, m.newGathererJob(v1beta1.MetricsJobK0s, prometheus.DefaultGatherer))
+
 	return nil
 }
 
@@ -121,14 +181,19 @@ func (m *Metrics) Stop() error {
 func (m *Metrics) Reconcile(_ context.Context, clusterConfig *v1beta1.ClusterConfig) error {
 	m.log.Debug("reconcile method called for: Metrics")
 
-	if m.clusterConfig == nil || clusterConfig.Spec.Images.PushGateway.URI() != m.clusterConfig.Spec.Images.PushGateway.URI() {
+	// The ServiceMonitor is only deployed if the Prometheus Operator is
+	// installed. It's picked up on the next reconciliation otherwise.
+	serviceMonitor := m.hasServiceMonitorAPI()
+
+	if m.clusterConfig == nil || clusterConfig.Spec.Images.PushGateway.URI() != m.clusterConfig.Spec.Images.PushGateway.URI() || serviceMonitor != m.serviceMonitor {
 		tw := templatewriter.TemplateWriter{
 			Name:     "pushgateway-with-ttl",
 			Template: pushGatewayTemplate,
-			Data: map[string]string{
-				"Namespace": namespace,
-				"Name":      pushGatewayName,
-				"Image":     clusterConfig.Spec.Images.PushGateway.URI(),
+			Data: map[string]any{
+				"Namespace":      namespace,
+				"Name":           pushGatewayName,
+				"Image":          clusterConfig.Spec.Images.PushGateway.URI(),
+				"ServiceMonitor": serviceMonitor,
 			},
 		}
 		output := bytes.NewBuffer([]byte{})
@@ -142,14 +207,41 @@ func (m *Metrics) Reconcile(_ context.Context, clusterConfig *v1beta1.ClusterCon
 		}
 	}
 
+	var remoteWriter *remoteWriter
+	if spec := clusterConfig.Spec.MetricsScraper; spec != nil && spec.RemoteWrite != nil {
+		var err error
+		if remoteWriter, err = newRemoteWriter(spec.RemoteWrite); err != nil {
+			return fmt.Errorf("failed to configure remote write: %w", err)
+		}
+	}
+
 	// We just store the last known config
 	for _, j := range m.jobs {
 		j.clusterConfig = clusterConfig
+		j.remoteWriter = remoteWriter
 	}
 	m.clusterConfig = clusterConfig
+	m.serviceMonitor = serviceMonitor
 	return nil
 }
 
+func (m *Metrics) hasServiceMonitorAPI() bool {
+	m.discovery.Invalidate()
+	resources, err := m.discovery.ServerResourcesForGroupVersion("monitoring.coreos.com/v1")
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			m.log.WithError(err).Debug("Failed to discover the ServiceMonitor API")
+		}
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Kind == "ServiceMonitor" {
+			return true
+		}
+	}
+	return false
+}
+
 type job struct {
 	log logrus.FieldLogger
 
@@ -159,12 +251,15 @@ type job struct {
 	clusterConfig *v1beta1.ClusterConfig
 	scrapeClient  *http.Client
 	restClient    rest.Interface
-}
 
-func (m *Metrics) newJob(name, scrapeURL string) (*job, error) {
-	certFile := path.Join(m.K0sVars.CertRootDir, "admin.crt")
-	keyFile := path.Join(m.K0sVars.CertRootDir, "admin.key")
+	// If set, the metrics are gathered from here instead of being scraped.
+	gatherer prometheus.Gatherer
 
+	// If set, the metrics are pushed here, too.
+	remoteWriter *remoteWriter
+}
+
+func (m *Metrics) newJob(name, scrapeURL, certFile, keyFile string) (*job, error) {
 	httpClient, err := getClient(certFile, keyFile)
 	if err != nil {
 		return nil, err
@@ -180,33 +275,65 @@ func (m *Metrics) newJob(name, scrapeURL string) (*job, error) {
 	}, nil
 }
 
+func (m *Metrics) newGathererJob(name string, gatherer prometheus.Gatherer) *job {
+	return &job{
+		log:        m.log.WithField("metrics_job", name),
+		name:       name,
+		hostname:   m.hostname,
+		restClient: m.restClient,
+		gatherer:   gatherer,
+	}
+}
+
 func (j *job) Run(ctx context.Context) {
 	j.log.Debugf("Running %s job", j.name)
 
-	t := time.NewTicker(time.Second * 30)
-	defer t.Stop()
 	for {
+		var scraper *v1beta1.MetricsScraperSpec
+		if j.clusterConfig != nil {
+			scraper = j.clusterConfig.Spec.MetricsScraper
+		}
+
 		select {
 		case <-ctx.Done():
 			return
-		case <-t.C:
+		case <-time.After(scraper.GetInterval()):
 			if j.clusterConfig == nil {
 				continue
 			}
 
-			err := j.collectAndPush(ctx)
+			config := scraper.Job(j.name)
+			if config != nil && config.Disabled {
+				continue
+			}
+
+			err := j.collectAndPush(ctx, config)
 			if err != nil {
 				j.log.Error(err)
 			}
 		}
 	}
 }
+
 func (j *job) pushURL() string {
 	pushAddress := fmt.Sprintf("/api/v1/namespaces/%s/services/http:%s:http/proxy", namespace, pushGatewayName)
 	return fmt.Sprintf("%s/metrics/job/%s/instance/%s", pushAddress, j.name, j.hostname)
 }
 
-func (j *job) collectAndPush(ctx context.Context) error {
+func (j *job) collectAndPush(ctx context.Context, config *v1beta1.MetricsScraperJob) error {
+	var relabelings []v1beta1.MetricRelabeling
+	if config != nil {
+		relabelings = config.MetricRelabelings
+	}
+
+	if j.gatherer != nil {
+		families, err := j.gatherer.Gather()
+		if err != nil {
+			return fmt.Errorf("error gathering metrics for job %s: %w", j.name, err)
+		}
+		return j.relabelAndPush(ctx, families, relabelings)
+	}
+
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.scrapeURL, nil)
 	if err != nil {
 		return fmt.Errorf("error creating GET request for %s: %w", j.scrapeURL, err)
@@ -218,6 +345,20 @@ func (j *job) collectAndPush(ctx context.Context) error {
 	}
 	defer resp.Body.Close()
 
+	if len(relabelings) > 0 || j.remoteWriter != nil {
+		var parser expfmt.TextParser
+		parsed, err := parser.TextToMetricFamilies(resp.Body)
+		if err != nil {
+			return fmt.Errorf("error parsing metrics from %s: %w", j.scrapeURL, err)
+		}
+		families := make([]*dto.MetricFamily, 0, len(parsed))
+		for _, family := range parsed {
+			families = append(families, family)
+		}
+		sort.Slice(families, func(i, k int) bool { return families[i].GetName() < families[k].GetName() })
+		return j.relabelAndPush(ctx, families, relabelings)
+	}
+
 	res := j.restClient.Post().AbsPath(j.pushURL()).Body(resp.Body).Do(ctx)
 	if res.Error() != nil {
 		return fmt.Errorf("error sending POST request for job %s: %w", j.name, res.Error())
@@ -225,6 +366,36 @@ func (j *job) collectAndPush(ctx context.Context) error {
 	return nil
 }
 
+func (j *job) relabelAndPush(ctx context.Context, families []*dto.MetricFamily, relabelings []v1beta1.MetricRelabeling) error {
+	now := time.Now()
+	families, err := relabelMetrics(families, relabelings)
+	if err != nil {
+		return fmt.Errorf("error relabeling metrics for job %s: %w", j.name, err)
+	}
+
+	var buf bytes.Buffer
+	for _, family := range families {
+		if _, err := expfmt.MetricFamilyToText(&buf, family); err != nil {
+			return fmt.Errorf("error encoding metrics for job %s: %w", j.name, err)
+		}
+	}
+
+	var errs []error
+	res := j.restClient.Post().AbsPath(j.pushURL()).Body(&buf).Do(ctx)
+	if res.Error() != nil {
+		errs = append(errs, fmt.Errorf("error sending POST request for job %s: %w", j.name, res.Error()))
+	}
+
+	if j.remoteWriter != nil {
+		labels := map[string]string{"job": j.name, "instance": j.hostname}
+		if err := j.remoteWriter.write(ctx, families, labels, now); err != nil {
+			errs = append(errs, fmt.Errorf("error writing metrics for job %s to remote endpoint: %w", j.name, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
 func getClient(certFile, keyFile string) (*http.Client, error) {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
 	transport.ResponseHeaderTimeout = time.Minute
@@ -324,4 +495,23 @@ spec:
       securityContext:
         runAsNonRoot: true
         runAsUser: 65534
+{{- if .ServiceMonitor }}
+---
+apiVersion: monitoring.coreos.com/v1
+kind: ServiceMonitor
+metadata:
+  labels:
+    component: "pushgateway"
+    app: k0s-observability
+  name: {{ .Name }}
+  namespace: {{ .Namespace }}
+spec:
+  endpoints:
+    - port: http
+      honorLabels: true
+  selector:
+    matchLabels:
+      component: "pushgateway"
+      app: k0s-observability
+{{- end }}
 `
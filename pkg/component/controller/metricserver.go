@@ -158,7 +158,7 @@ metadata:
   name: metrics-server
   namespace: kube-system
 spec:
-  replicas: 1
+  replicas: {{ .Replicas }}
   selector:
     matchLabels:
       k8s-app: metrics-server
@@ -176,7 +176,10 @@ spec:
         - --secure-port=10250
         - --kubelet-preferred-address-types=InternalIP,ExternalIP,Hostname
         - --kubelet-use-node-status-port
-        - --metric-resolution=15s
+        - --metric-resolution={{ .MetricResolution }}
+{{- if .KubeletInsecureTLS }}
+        - --kubelet-insecure-tls
+{{- end }}
         image: {{ .Image }}
         imagePullPolicy: {{ .PullPolicy }}
         livenessProbe:
@@ -200,9 +203,13 @@ spec:
           initialDelaySeconds: 20
           periodSeconds: 10
         resources:
+{{- if .Resources }}
+{{ .Resources | indent 10 }}
+{{- else }}
           requests:
             memory: {{ .MEMRequest }}
             cpu: {{ .CPURequest }}
+{{- end }}
         securityContext:
           readOnlyRootFilesystem: true
           runAsNonRoot: true
@@ -210,6 +217,17 @@ spec:
         volumeMounts:
         - mountPath: /tmp
           name: tmp-dir
+{{- if gt .Replicas 1 }}
+      affinity:
+        podAntiAffinity:
+          preferredDuringSchedulingIgnoredDuringExecution:
+          - weight: 100
+            podAffinityTerm:
+              labelSelector:
+                matchLabels:
+                  k8s-app: metrics-server
+              topologyKey: kubernetes.io/hostname
+{{- end }}
       nodeSelector:
         kubernetes.io/os: linux
       tolerations:
@@ -221,6 +239,21 @@ spec:
       volumes:
       - emptyDir: {}
         name: tmp-dir
+{{- if gt .Replicas 1 }}
+---
+apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  labels:
+    k8s-app: metrics-server
+  name: metrics-server
+  namespace: kube-system
+spec:
+  minAvailable: 1
+  selector:
+    matchLabels:
+      k8s-app: metrics-server
+{{- end }}
 ---
 apiVersion: apiregistration.k8s.io/v1
 kind: APIService
@@ -251,10 +284,14 @@ type MetricServer struct {
 }
 
 type metricsConfig struct {
-	Image      string
-	PullPolicy string
-	CPURequest string
-	MEMRequest string
+	Image              string
+	PullPolicy         string
+	Replicas           int32
+	MetricResolution   string
+	KubeletInsecureTLS bool
+	Resources          string
+	CPURequest         string
+	MEMRequest         string
 }
 
 var _ manager.Component = (*MetricServer)(nil)
@@ -347,9 +384,33 @@ func (m *MetricServer) getConfig(ctx context.Context) (metricsConfig, error) {
 	if m.clusterConfig == nil {
 		return metricsConfig{}, fmt.Errorf("cluster config not available yet")
 	}
+	settings := m.clusterConfig.Spec.MetricsServer
+	if settings == nil {
+		settings = &v1beta1.MetricsServerSpec{}
+	}
+
 	cfg := metricsConfig{
-		Image:      m.clusterConfig.Spec.Images.MetricsServer.URI(),
-		PullPolicy: m.clusterConfig.Spec.Images.DefaultPullPolicy,
+		Image:              m.clusterConfig.Spec.Images.MetricsServer.URI(),
+		PullPolicy:         m.clusterConfig.Spec.Images.DefaultPullPolicy,
+		Replicas:           1,
+		MetricResolution:   "15s",
+		KubeletInsecureTLS: settings.KubeletInsecureTLS,
+	}
+	if settings.Replicas > 0 {
+		cfg.Replicas = settings.Replicas
+	}
+	if settings.MetricResolution != nil {
+		cfg.MetricResolution = settings.MetricResolution.Duration.String()
+	}
+
+	// Explicitly configured resources take precedence over the node based ones.
+	if settings.Resources != nil {
+		resources, err := toYAML(settings.Resources)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Resources = resources
+		return cfg, nil
 	}
 
 	kubeClient, err := m.kubeClientFactory.GetClient()
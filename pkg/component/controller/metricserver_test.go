@@ -20,12 +20,14 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/k0sproject/k0s/internal/testutil"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/constant"
 	"github.com/stretchr/testify/require"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -67,3 +69,29 @@ func TestGetConfigWithSomeNodes(t *testing.T) {
 	require.Equal(t, "100m", metricsCfg.CPURequest)
 	require.Equal(t, "300M", metricsCfg.MEMRequest)
 }
+
+func TestGetConfigWithSettings(t *testing.T) {
+	cfg := v1beta1.DefaultClusterConfig()
+	cfg.Spec.MetricsServer = &v1beta1.MetricsServerSpec{
+		Replicas:           2,
+		KubeletInsecureTLS: true,
+		MetricResolution:   &v1.Duration{Duration: time.Minute},
+		Resources: &corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU: resource.MustParse("200m"),
+			},
+		},
+	}
+	k0sVars := constant.GetConfig(t.TempDir())
+	fakeFactory := testutil.NewFakeClientFactory()
+	ctx := context.Background()
+
+	metrics := NewMetricServer(k0sVars, fakeFactory)
+	require.NoError(t, metrics.Reconcile(ctx, cfg))
+	metricsCfg, err := metrics.getConfig(ctx)
+	require.NoError(t, err)
+	require.Equal(t, int32(2), metricsCfg.Replicas)
+	require.True(t, metricsCfg.KubeletInsecureTLS)
+	require.Equal(t, "1m0s", metricsCfg.MetricResolution)
+	require.Equal(t, "requests:\n  cpu: 200m", metricsCfg.Resources)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	dto "github.com/prometheus/client_model/go"
+)
+
+// metricNameLabel is the label that holds the metric name during relabeling.
+const metricNameLabel = "__name__"
+
+type metricRelabeling struct {
+	*v1beta1.MetricRelabeling
+	regex *regexp.Regexp
+}
+
+// relabelMetrics applies the given relabeling rules to each metric of the
+// given metric families. Metrics that are dropped by any of the rules are
+// removed. Metrics that are renamed via the __name__ label are moved to the
+// family of that name.
+func relabelMetrics(families []*dto.MetricFamily, rules []v1beta1.MetricRelabeling) ([]*dto.MetricFamily, error) {
+	if len(rules) == 0 {
+		return families, nil
+	}
+
+	compiled := make([]metricRelabeling, len(rules))
+	for i := range rules {
+		regex, err := rules[i].CompileRegex()
+		if err != nil {
+			return nil, err
+		}
+		compiled[i] = metricRelabeling{&rules[i], regex}
+	}
+
+	var relabeled []*dto.MetricFamily
+	byName := make(map[string]*dto.MetricFamily)
+	for _, family := range families {
+		for _, metric := range family.Metric {
+			labels := map[string]string{metricNameLabel: family.GetName()}
+			for _, pair := range metric.Label {
+				labels[pair.GetName()] = pair.GetValue()
+			}
+
+			if !applyRelabelings(labels, compiled) {
+				continue
+			}
+
+			name := labels[metricNameLabel]
+			if name == "" {
+				continue
+			}
+			delete(labels, metricNameLabel)
+			metric.Label = labelPairs(labels)
+
+			target, ok := byName[name]
+			if !ok {
+				target = &dto.MetricFamily{Name: &name, Help: family.Help, Type: family.Type}
+				byName[name] = target
+				relabeled = append(relabeled, target)
+			} else if target.GetType() != family.GetType() {
+				return nil, fmt.Errorf("cannot relabel %s to %s: conflicting metric types %s and %s", family.GetName(), name, family.GetType(), target.GetType())
+			}
+			target.Metric = append(target.Metric, metric)
+		}
+	}
+
+	return relabeled, nil
+}
+
+// applyRelabelings applies the given rules to the given labels. Returns false
+// if the metric is to be dropped.
+func applyRelabelings(labels map[string]string, rules []metricRelabeling) bool {
+	for _, rule := range rules {
+		values := make([]string, len(rule.SourceLabels))
+		for i, name := range rule.SourceLabels {
+			values[i] = labels[name]
+		}
+		value := strings.Join(values, rule.GetSeparator())
+
+		switch rule.GetAction() {
+		case v1beta1.RelabelKeep:
+			if !rule.regex.MatchString(value) {
+				return false
+			}
+		case v1beta1.RelabelDrop:
+			if rule.regex.MatchString(value) {
+				return false
+			}
+		case v1beta1.RelabelReplace:
+			match := rule.regex.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+			replacement := rule.regex.ExpandString(nil, rule.GetReplacement(), value, match)
+			if len(replacement) == 0 {
+				delete(labels, rule.TargetLabel)
+			} else {
+				labels[rule.TargetLabel] = string(replacement)
+			}
+		case v1beta1.RelabelLabelDrop, v1beta1.RelabelLabelKeep:
+			keep := rule.GetAction() == v1beta1.RelabelLabelKeep
+			for name := range labels {
+				// The metric name is never dropped.
+				if name != metricNameLabel && rule.regex.MatchString(name) != keep {
+					delete(labels, name)
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+// labelPairs converts the given labels into label pairs, sorted by name.
+func labelPairs(labels map[string]string) []*dto.LabelPair {
+	pairs := make([]*dto.LabelPair, 0, len(labels))
+	for name, value := range labels {
+		name, value := name, value
+		pairs = append(pairs, &dto.LabelPair{Name: &name, Value: &value})
+	}
+	sort.Slice(pairs, func(i, j int) bool { return pairs[i].GetName() < pairs[j].GetName() })
+	return pairs
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/utils/pointer"
+)
+
+const testMetrics = `# HELP etcd_server_has_leader Whether or not a leader exists.
+# TYPE etcd_server_has_leader gauge
+etcd_server_has_leader 1
+# HELP grpc_server_handled_total Total number of RPCs completed on the server.
+# TYPE grpc_server_handled_total counter
+grpc_server_handled_total{grpc_code="OK",grpc_method="Range",grpc_service="etcdserverpb.KV"} 42
+grpc_server_handled_total{grpc_code="OK",grpc_method="Watch",grpc_service="etcdserverpb.Watch"} 7
+`
+
+func TestRelabelMetrics(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		rules    []v1beta1.MetricRelabeling
+		expected string
+	}{
+		{"no_rules", nil, testMetrics},
+		{
+			"drop_by_name",
+			[]v1beta1.MetricRelabeling{{SourceLabels: []string{"__name__"}, Regex: "grpc_.*", Action: "drop"}},
+			`# HELP etcd_server_has_leader Whether or not a leader exists.
+# TYPE etcd_server_has_leader gauge
+etcd_server_has_leader 1
+`,
+		},
+		{
+			"keep_and_replace",
+			[]v1beta1.MetricRelabeling{
+				{SourceLabels: []string{"grpc_service", "grpc_method"}, Separator: pointer.String("/"), Regex: "etcdserverpb.KV/.*", Action: "keep"},
+				{SourceLabels: []string{"grpc_service"}, Regex: `etcdserverpb\.(.*)`, TargetLabel: "service"},
+				{SourceLabels: []string{"__name__"}, Regex: "grpc_(.*)", Replacement: pointer.String("etcd_grpc_$1"), TargetLabel: "__name__"},
+				{Regex: "grpc_.*", Action: "labeldrop"},
+			},
+			`# HELP etcd_grpc_server_handled_total Total number of RPCs completed on the server.
+# TYPE etcd_grpc_server_handled_total counter
+etcd_grpc_server_handled_total{service="KV"} 42
+`,
+		},
+		{
+			"labelkeep",
+			[]v1beta1.MetricRelabeling{{Regex: "grpc_method", Action: "labelkeep"}},
+			`# HELP etcd_server_has_leader Whether or not a leader exists.
+# TYPE etcd_server_has_leader gauge
+etcd_server_has_leader 1
+# HELP grpc_server_handled_total Total number of RPCs completed on the server.
+# TYPE grpc_server_handled_total counter
+grpc_server_handled_total{grpc_method="Range"} 42
+grpc_server_handled_total{grpc_method="Watch"} 7
+`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var parser expfmt.TextParser
+			parsed, err := parser.TextToMetricFamilies(strings.NewReader(testMetrics))
+			require.NoError(t, err)
+			var families []*dto.MetricFamily
+			for _, family := range parsed {
+				families = append(families, family)
+			}
+			sort.Slice(families, func(i, j int) bool { return families[i].GetName() < families[j].GetName() })
+
+			families, err = relabelMetrics(families, test.rules)
+			require.NoError(t, err)
+
+			var buf bytes.Buffer
+			for _, family := range families {
+				_, err := expfmt.MetricFamilyToText(&buf, family)
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.expected, buf.String())
+		})
+	}
+}
+
+func TestRelabelMetrics_ConflictingTypes(t *testing.T) {
+	var parser expfmt.TextParser
+	parsed, err := parser.TextToMetricFamilies(strings.NewReader(testMetrics))
+	require.NoError(t, err)
+	families := []*dto.MetricFamily{parsed["etcd_server_has_leader"], parsed["grpc_server_handled_total"]}
+
+	_, err = relabelMetrics(families, []v1beta1.MetricRelabeling{{
+		SourceLabels: []string{"__name__"},
+		Regex:        ".*",
+		Replacement:  pointer.String("merged"),
+		TargetLabel:  "__name__",
+	}})
+	assert.ErrorContains(t, err, "cannot relabel grpc_server_handled_total to merged: conflicting metric types COUNTER and GAUGE")
+}
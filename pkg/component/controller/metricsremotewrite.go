@@ -0,0 +1,220 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	"github.com/klauspost/compress/s2"
+	dto "github.com/prometheus/client_model/go"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// remoteWriter pushes metrics to a Prometheus remote write endpoint, using
+// version 1.0 of the remote write protocol.
+type remoteWriter struct {
+	url       string
+	client    *http.Client
+	basicAuth *v1beta1.MetricsRemoteWriteBasicAuth
+}
+
+func newRemoteWriter(spec *v1beta1.MetricsRemoteWriteSpec) (*remoteWriter, error) {
+	tlsConfig := &tls.Config{InsecureSkipVerify: spec.InsecureSkipVerify}
+	if spec.CAFile != "" {
+		caCerts, err := os.ReadFile(spec.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read remote write CA certificates: %w", err)
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		if !tlsConfig.RootCAs.AppendCertsFromPEM(caCerts) {
+			return nil, fmt.Errorf("no certificates found in %s", spec.CAFile)
+		}
+	}
+	if spec.CertFile != "" {
+		// Reload the client certificate for each handshake, so that renewed
+		// certificates are picked up.
+		certFile, keyFile := spec.CertFile, spec.KeyFile
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+			return &cert, err
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &remoteWriter{
+		url:       spec.URL,
+		client:    &http.Client{Transport: transport, Timeout: time.Minute},
+		basicAuth: spec.BasicAuth,
+	}, nil
+}
+
+// write sends the given metric families to the remote write endpoint. The
+// given labels are added to all time series.
+func (w *remoteWriter) write(ctx context.Context, families []*dto.MetricFamily, labels map[string]string, now time.Time) error {
+	series := toTimeSeries(families, labels, now)
+	if len(series) == 0 {
+		return nil
+	}
+
+	body := s2.EncodeSnappy(nil, encodeWriteRequest(series))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+	if w.basicAuth != nil {
+		password, err := os.ReadFile(w.basicAuth.PasswordFile)
+		if err != nil {
+			return fmt.Errorf("failed to read remote write password: %w", err)
+		}
+		req.SetBasicAuth(w.basicAuth.Username, strings.TrimSpace(string(password)))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		if len(bytes.TrimSpace(msg)) == 0 {
+			return errors.New(resp.Status)
+		}
+		return fmt.Errorf("%s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+type timeSeries struct {
+	labels    []*dto.LabelPair
+	value     float64
+	timestamp int64
+}
+
+// toTimeSeries converts the given metric families into time series, following
+// the conventions of the Prometheus text format, e.g. summaries and histograms
+// are split into their quantiles or buckets, sums and counts.
+func toTimeSeries(families []*dto.MetricFamily, extraLabels map[string]string, now time.Time) []timeSeries {
+	var series []timeSeries
+	for _, family := range families {
+		name := family.GetName()
+		for _, metric := range family.Metric {
+			timestamp := metric.GetTimestampMs()
+			if timestamp == 0 {
+				timestamp = now.UnixMilli()
+			}
+
+			add := func(name string, value float64, extra ...string) {
+				labels := make(map[string]string, len(metric.Label)+len(extraLabels)+2)
+				for _, pair := range metric.Label {
+					labels[pair.GetName()] = pair.GetValue()
+				}
+				for key, value := range extraLabels {
+					labels[key] = value
+				}
+				for i := 0; i+1 < len(extra); i += 2 {
+					labels[extra[i]] = extra[i+1]
+				}
+				labels[metricNameLabel] = name
+				series = append(series, timeSeries{labelPairs(labels), value, timestamp})
+			}
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				add(name, metric.GetCounter().GetValue())
+			case dto.MetricType_GAUGE:
+				add(name, metric.GetGauge().GetValue())
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+				for _, q := range summary.GetQuantile() {
+					add(name, q.GetValue(), "quantile", formatFloat(q.GetQuantile()))
+				}
+				add(name+"_sum", summary.GetSampleSum())
+				add(name+"_count", float64(summary.GetSampleCount()))
+			case dto.MetricType_HISTOGRAM:
+				histogram := metric.GetHistogram()
+				var hasInf bool
+				for _, b := range histogram.GetBucket() {
+					hasInf = hasInf || math.IsInf(b.GetUpperBound(), +1)
+					add(name+"_bucket", float64(b.GetCumulativeCount()), "le", formatFloat(b.GetUpperBound()))
+				}
+				if !hasInf {
+					add(name+"_bucket", float64(histogram.GetSampleCount()), "le", "+Inf")
+				}
+				add(name+"_sum", histogram.GetSampleSum())
+				add(name+"_count", float64(histogram.GetSampleCount()))
+			default:
+				add(name, metric.GetUntyped().GetValue())
+			}
+		}
+	}
+
+	return series
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// encodeWriteRequest encodes the given time series as a protobuf WriteRequest
+// message, as defined in Prometheus' prompb/remote.proto. The labels of each
+// time series have to be sorted by name.
+func encodeWriteRequest(series []timeSeries) []byte {
+	var request []byte
+	for _, s := range series {
+		var ts []byte
+		for _, pair := range s.labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, pair.GetName())
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, pair.GetValue())
+			ts = protowire.AppendTag(ts, 1, protowire.BytesType)
+			ts = protowire.AppendBytes(ts, label)
+		}
+
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(s.value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample, uint64(s.timestamp))
+		ts = protowire.AppendTag(ts, 2, protowire.BytesType)
+		ts = protowire.AppendBytes(ts, sample)
+
+		request = protowire.AppendTag(request, 1, protowire.BytesType)
+		request = protowire.AppendBytes(request, ts)
+	}
+	return request
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	"github.com/klauspost/compress/s2"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func TestToTimeSeries(t *testing.T) {
+	var parser expfmt.TextParser
+	parsed, err := parser.TextToMetricFamilies(strings.NewReader(`# TYPE requests counter
+requests{code="200"} 3
+# TYPE latency histogram
+latency_bucket{le="0.5"} 1
+latency_bucket{le="1"} 2
+latency_sum 1.2
+latency_count 2
+# TYPE rpc summary
+rpc{quantile="0.9"} 0.3
+rpc_sum 4
+rpc_count 10
+`))
+	require.NoError(t, err)
+
+	now := time.UnixMilli(1683000000000)
+	series := toTimeSeries([]*dto.MetricFamily{parsed["requests"], parsed["latency"], parsed["rpc"]}, map[string]string{"job": "etcd"}, now)
+
+	var lines []string
+	for _, s := range series {
+		var labels []string
+		for _, pair := range s.labels {
+			labels = append(labels, pair.GetName()+"="+pair.GetValue())
+		}
+		assert.Equal(t, now.UnixMilli(), s.timestamp)
+		lines = append(lines, strings.Join(labels, ",")+" "+formatFloat(s.value))
+	}
+	assert.Equal(t, []string{
+		"__name__=requests,code=200,job=etcd 3",
+		"__name__=latency_bucket,job=etcd,le=0.5 1",
+		"__name__=latency_bucket,job=etcd,le=1 2",
+		"__name__=latency_bucket,job=etcd,le=+Inf 2",
+		"__name__=latency_sum,job=etcd 1.2",
+		"__name__=latency_count,job=etcd 2",
+		"__name__=rpc,job=etcd,quantile=0.9 0.3",
+		"__name__=rpc_sum,job=etcd 4",
+		"__name__=rpc_count,job=etcd 10",
+	}, lines)
+}
+
+func TestRemoteWriter(t *testing.T) {
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(passwordFile, []byte("s3cr3t\n"), 0600))
+
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != "k0s" || password != "s3cr3t" {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		assert.Equal(t, "snappy", r.Header.Get("Content-Encoding"))
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		assert.Equal(t, "0.1.0", r.Header.Get("X-Prometheus-Remote-Write-Version"))
+		compressed, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		body, err = s2.Decode(nil, compressed)
+		assert.NoError(t, err)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	spec := &v1beta1.MetricsRemoteWriteSpec{
+		URL:       server.URL,
+		BasicAuth: &v1beta1.MetricsRemoteWriteBasicAuth{Username: "k0s", PasswordFile: passwordFile},
+	}
+	writer, err := newRemoteWriter(spec)
+	require.NoError(t, err)
+
+	var parser expfmt.TextParser
+	parsed, err := parser.TextToMetricFamilies(strings.NewReader("# TYPE up gauge\nup 1\n"))
+	require.NoError(t, err)
+	now := time.UnixMilli(1683000000000)
+	require.NoError(t, writer.write(context.TODO(), []*dto.MetricFamily{parsed["up"]}, map[string]string{"job": "kine"}, now))
+
+	// Decode the WriteRequest's single time series.
+	num, typ, n := protowire.ConsumeTag(body)
+	require.Equal(t, protowire.Number(1), num)
+	require.Equal(t, protowire.BytesType, typ)
+	ts, m := protowire.ConsumeBytes(body[n:])
+	require.Equal(t, len(body), n+m)
+
+	var labels []string
+	var value float64
+	var timestamp int64
+	for len(ts) > 0 {
+		num, _, n := protowire.ConsumeTag(ts)
+		field, m := protowire.ConsumeBytes(ts[n:])
+		ts = ts[n+m:]
+		switch num {
+		case 1: // Label
+			_, _, n := protowire.ConsumeTag(field)
+			name, m := protowire.ConsumeString(field[n:])
+			_, _, k := protowire.ConsumeTag(field[n+m:])
+			val, _ := protowire.ConsumeString(field[n+m+k:])
+			labels = append(labels, name+"="+val)
+		case 2: // Sample
+			_, _, n := protowire.ConsumeTag(field)
+			bits, m := protowire.ConsumeFixed64(field[n:])
+			value = math.Float64frombits(bits)
+			_, _, k := protowire.ConsumeTag(field[n+m:])
+			v, _ := protowire.ConsumeVarint(field[n+m+k:])
+			timestamp = int64(v)
+		}
+	}
+	assert.Equal(t, []string{"__name__=up", "job=kine"}, labels)
+	assert.Equal(t, 1.0, value)
+	assert.Equal(t, now.UnixMilli(), timestamp)
+
+	spec.BasicAuth.Username = "someone"
+	writer, err = newRemoteWriter(spec)
+	require.NoError(t, err)
+	err = writer.write(context.TODO(), []*dto.MetricFamily{parsed["up"]}, nil, now)
+	assert.ErrorContains(t, err, "401 Unauthorized: unauthorized")
+}
@@ -0,0 +1,269 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NodeLocalDNS is the component implementation to manage NodeLocal DNSCache.
+// The kubelet side of it, i.e. pointing Pods to the node-local cache instead of
+// CoreDNS, is handled by the worker config reconciler.
+type NodeLocalDNS struct {
+	log logrus.FieldLogger
+
+	nodeConfig  *v1beta1.ClusterConfig
+	manifestDir string
+
+	previousConfig nodeLocalDNSConfig
+}
+
+var _ manager.Component = (*NodeLocalDNS)(nil)
+var _ manager.Reconciler = (*NodeLocalDNS)(nil)
+
+type nodeLocalDNSConfig struct {
+	LocalIP       string
+	ClusterDNSIP  string
+	ClusterDomain string
+	Image         string
+	PullPolicy    string
+}
+
+// NewNodeLocalDNS creates a new NodeLocalDNS component.
+func NewNodeLocalDNS(k0sVars constant.CfgVars, nodeConfig *v1beta1.ClusterConfig) *NodeLocalDNS {
+	return &NodeLocalDNS{
+		log: logrus.WithFields(logrus.Fields{"component": constant.NodeLocalDNSComponentName}),
+
+		nodeConfig:  nodeConfig,
+		manifestDir: path.Join(k0sVars.ManifestsDir, "nodelocaldns"),
+	}
+}
+
+// Init does nothing
+func (n *NodeLocalDNS) Init(context.Context) error {
+	return nil
+}
+
+// Start does nothing
+func (n *NodeLocalDNS) Start(context.Context) error {
+	return nil
+}
+
+// Reconcile detects changes in configuration and applies them to the component
+func (n *NodeLocalDNS) Reconcile(_ context.Context, clusterConfig *v1beta1.ClusterConfig) error {
+	nodeLocalDNS := clusterConfig.Spec.Network.NodeLocalDNSCache
+	if !nodeLocalDNS.IsEnabled() {
+		n.previousConfig = nodeLocalDNSConfig{}
+		return os.RemoveAll(n.manifestDir)
+	}
+
+	cfg, err := n.getConfig(clusterConfig)
+	if err != nil {
+		return err
+	}
+	if cfg == n.previousConfig {
+		n.log.Debug("current config matches existing, not gonna do anything")
+		return nil
+	}
+
+	if err := dir.Init(n.manifestDir, constant.ManifestsDirMode); err != nil {
+		return err
+	}
+
+	tw := templatewriter.TemplateWriter{
+		Name:     "node-local-dns",
+		Template: nodeLocalDNSTemplate,
+		Data:     cfg,
+		Path:     filepath.Join(n.manifestDir, "node-local-dns.yaml"),
+	}
+	if err := tw.Write(); err != nil {
+		return fmt.Errorf("error writing node-local-dns manifests: %w", err)
+	}
+	n.previousConfig = cfg
+
+	return nil
+}
+
+// Stop does nothing
+func (n *NodeLocalDNS) Stop() error {
+	return nil
+}
+
+func (n *NodeLocalDNS) getConfig(clusterConfig *v1beta1.ClusterConfig) (nodeLocalDNSConfig, error) {
+	dns, err := n.nodeConfig.Spec.Network.DNSAddress()
+	if err != nil {
+		return nodeLocalDNSConfig{}, err
+	}
+
+	nodeLocalDNS := clusterConfig.Spec.Network.NodeLocalDNSCache
+	image := nodeLocalDNS.Image
+	if image == nil {
+		image = v1beta1.DefaultNodeLocalDNSCacheImage()
+	}
+	pullPolicy := string(nodeLocalDNS.ImagePullPolicy)
+	if pullPolicy == "" {
+		pullPolicy = clusterConfig.Spec.Images.DefaultPullPolicy
+	}
+
+	return nodeLocalDNSConfig{
+		LocalIP:       nodeLocalDNS.LocalIP,
+		ClusterDNSIP:  dns,
+		ClusterDomain: clusterConfig.Spec.Network.ClusterDomain,
+		Image:         image.URI(),
+		PullPolicy:    pullPolicy,
+	}, nil
+}
+
+// The caching agent only binds to the local IP, so that the cluster DNS
+// service remains untouched and can be used to forward all cache misses to
+// CoreDNS. This way, any Corefile customizations still apply.
+const nodeLocalDNSTemplate = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: node-local-dns
+  namespace: kube-system
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: node-local-dns
+  namespace: kube-system
+data:
+  Corefile: |
+    {{ .ClusterDomain }}:53 {
+        errors
+        cache {
+            success 9984 30
+            denial 9984 5
+        }
+        reload
+        loop
+        bind {{ .LocalIP }}
+        forward . {{ .ClusterDNSIP }} {
+            force_tcp
+        }
+        prometheus :9253
+        health {{ .LocalIP }}:8080
+    }
+    .:53 {
+        errors
+        cache 30
+        reload
+        loop
+        bind {{ .LocalIP }}
+        forward . {{ .ClusterDNSIP }} {
+            force_tcp
+        }
+        prometheus :9253
+    }
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: node-local-dns
+  namespace: kube-system
+  labels:
+    k8s-app: node-local-dns
+spec:
+  updateStrategy:
+    rollingUpdate:
+      maxUnavailable: 10%
+  selector:
+    matchLabels:
+      k8s-app: node-local-dns
+  template:
+    metadata:
+      labels:
+        k8s-app: node-local-dns
+      annotations:
+        prometheus.io/port: "9253"
+        prometheus.io/scrape: "true"
+    spec:
+      priorityClassName: system-node-critical
+      serviceAccountName: node-local-dns
+      hostNetwork: true
+      dnsPolicy: Default
+      nodeSelector:
+        kubernetes.io/os: linux
+      tolerations:
+      - key: "CriticalAddonsOnly"
+        operator: "Exists"
+      - effect: "NoExecute"
+        operator: "Exists"
+      - effect: "NoSchedule"
+        operator: "Exists"
+      containers:
+      - name: node-cache
+        image: {{ .Image }}
+        imagePullPolicy: {{ .PullPolicy }}
+        resources:
+          requests:
+            cpu: 25m
+            memory: 5Mi
+        args: [ "-localip", "{{ .LocalIP }}", "-conf", "/etc/Corefile", "-health-port", "8080" ]
+        securityContext:
+          capabilities:
+            add:
+            - NET_ADMIN
+        ports:
+        - containerPort: 53
+          name: dns
+          protocol: UDP
+        - containerPort: 53
+          name: dns-tcp
+          protocol: TCP
+        - containerPort: 9253
+          name: metrics
+          protocol: TCP
+        livenessProbe:
+          httpGet:
+            host: {{ .LocalIP }}
+            path: /health
+            port: 8080
+          initialDelaySeconds: 60
+          timeoutSeconds: 5
+        volumeMounts:
+        - mountPath: /run/xtables.lock
+          name: xtables-lock
+          readOnly: false
+        - name: config-volume
+          mountPath: /etc/coredns
+      volumes:
+      - name: xtables-lock
+        hostPath:
+          path: /run/xtables.lock
+          type: FileOrCreate
+      - name: config-volume
+        configMap:
+          name: node-local-dns
+          items:
+          - key: Corefile
+            path: Corefile.base
+`
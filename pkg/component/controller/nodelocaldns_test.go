@@ -0,0 +1,92 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestNodeLocalDNS_Reconcile(t *testing.T) {
+	k0sVars := constant.GetConfig(t.TempDir())
+	cfg := v1beta1.DefaultClusterConfig()
+	underTest := NewNodeLocalDNS(k0sVars, cfg)
+	manifest := filepath.Join(underTest.manifestDir, "node-local-dns.yaml")
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoDirExists(t, underTest.manifestDir)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		cfg.Spec.Network.NodeLocalDNSCache = v1beta1.DefaultNodeLocalDNSCache()
+		cfg.Spec.Network.NodeLocalDNSCache.Enabled = true
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+
+		data, err := os.ReadFile(manifest)
+		require.NoError(t, err)
+
+		var kinds []string
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			var obj unstructured.Unstructured
+			if err := decoder.Decode(&obj.Object); err != nil {
+				break
+			}
+			if obj.Object == nil {
+				continue
+			}
+			kinds = append(kinds, obj.GetKind())
+
+			if obj.GetKind() == "ConfigMap" {
+				corefile, _, err := unstructured.NestedString(obj.Object, "data", "Corefile")
+				require.NoError(t, err)
+				assert.Contains(t, corefile, "cluster.local:53 {")
+				assert.Contains(t, corefile, "bind 169.254.20.10")
+				assert.Contains(t, corefile, "forward . 10.96.0.10 {")
+			}
+
+			if obj.GetKind() == "DaemonSet" {
+				containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+				require.NoError(t, err)
+				require.Len(t, containers, 1)
+				container := containers[0].(map[string]any)
+				assert.Equal(t, v1beta1.DefaultNodeLocalDNSCacheImage().URI(), container["image"])
+				assert.Equal(t, "IfNotPresent", container["imagePullPolicy"])
+			}
+		}
+
+		assert.Equal(t, []string{"ServiceAccount", "ConfigMap", "DaemonSet"}, kinds)
+	})
+
+	t.Run("disabled_again", func(t *testing.T) {
+		cfg.Spec.Network.NodeLocalDNSCache.Enabled = false
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoDirExists(t, underTest.manifestDir)
+	})
+}
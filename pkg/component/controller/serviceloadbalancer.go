@@ -0,0 +1,555 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/constant"
+	"github.com/k0sproject/k0s/static"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ServiceLoadBalancer is the component implementation to manage the load
+// balancer implementation for Services of type LoadBalancer. It deploys
+// MetalLB in L2 mode, along with the address pools from the cluster config.
+// Kube-proxy's side of it, i.e. enabling strict ARP in IPVS mode, is handled
+// by the kube-proxy component.
+type ServiceLoadBalancer struct {
+	log logrus.FieldLogger
+
+	manifestDir string
+
+	previousConfig *metalLBConfig
+}
+
+var _ manager.Component = (*ServiceLoadBalancer)(nil)
+var _ manager.Reconciler = (*ServiceLoadBalancer)(nil)
+
+type metalLBConfig struct {
+	ControllerImage string
+	SpeakerImage    string
+	PullPolicy      string
+	AddressPools    []metalLBAddressPool
+}
+
+type metalLBAddressPool struct {
+	Name       string
+	Addresses  []string
+	AutoAssign bool
+}
+
+// NewServiceLoadBalancer creates a new ServiceLoadBalancer component.
+func NewServiceLoadBalancer(k0sVars constant.CfgVars) *ServiceLoadBalancer {
+	return &ServiceLoadBalancer{
+		log: logrus.WithFields(logrus.Fields{"component": constant.ServiceLoadBalancerComponentName}),
+
+		manifestDir: path.Join(k0sVars.ManifestsDir, "metallb"),
+	}
+}
+
+// Init does nothing
+func (s *ServiceLoadBalancer) Init(context.Context) error {
+	return nil
+}
+
+// Start does nothing
+func (s *ServiceLoadBalancer) Start(context.Context) error {
+	return nil
+}
+
+// Reconcile detects changes in configuration and applies them to the component
+func (s *ServiceLoadBalancer) Reconcile(_ context.Context, clusterConfig *v1beta1.ClusterConfig) error {
+	slb := clusterConfig.Spec.Network.ServiceLoadBalancer
+	if !slb.IsEnabled() {
+		s.previousConfig = nil
+		return os.RemoveAll(s.manifestDir)
+	}
+
+	if slb.Type != v1beta1.ServiceLoadBalancerTypeMetalLB {
+		return fmt.Errorf("unsupported service load balancer type: %q", slb.Type)
+	}
+
+	cfg := s.getConfig(clusterConfig)
+	if reflect.DeepEqual(cfg, s.previousConfig) {
+		s.log.Debug("current config matches existing, not gonna do anything")
+		return nil
+	}
+
+	if err := dir.Init(s.manifestDir, constant.ManifestsDirMode); err != nil {
+		return err
+	}
+	if err := s.writeCRDs(); err != nil {
+		return err
+	}
+
+	tw := templatewriter.TemplateWriter{
+		Name:     "metallb",
+		Template: metalLBTemplate,
+		Data:     cfg,
+		Path:     filepath.Join(s.manifestDir, "metallb.yaml"),
+	}
+	if err := tw.Write(); err != nil {
+		return fmt.Errorf("error writing metallb manifests: %w", err)
+	}
+	s.previousConfig = cfg
+
+	return nil
+}
+
+// Stop does nothing
+func (s *ServiceLoadBalancer) Stop() error {
+	return nil
+}
+
+func (s *ServiceLoadBalancer) writeCRDs() error {
+	crds, err := static.AssetDir("manifests/metallb/CustomResourceDefinition")
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range crds {
+		content, err := static.Asset(fmt.Sprintf("manifests/metallb/CustomResourceDefinition/%s", filename))
+		if err != nil {
+			return fmt.Errorf("failed to fetch crd %s: %w", filename, err)
+		}
+		manifest := filepath.Join(s.manifestDir, "crd-"+filename)
+		if err := file.WriteContentAtomically(manifest, content, constant.CertMode); err != nil {
+			return fmt.Errorf("failed to write crd %s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *ServiceLoadBalancer) getConfig(clusterConfig *v1beta1.ClusterConfig) *metalLBConfig {
+	slb := clusterConfig.Spec.Network.ServiceLoadBalancer
+	metalLB := slb.MetalLB
+	if metalLB == nil {
+		metalLB = v1beta1.DefaultMetalLB()
+	}
+	controllerImage, speakerImage := metalLB.ControllerImage, metalLB.SpeakerImage
+	if controllerImage == nil {
+		controllerImage = v1beta1.DefaultMetalLBControllerImage()
+	}
+	if speakerImage == nil {
+		speakerImage = v1beta1.DefaultMetalLBSpeakerImage()
+	}
+	pullPolicy := string(metalLB.ImagePullPolicy)
+	if pullPolicy == "" {
+		pullPolicy = clusterConfig.Spec.Images.DefaultPullPolicy
+	}
+
+	cfg := &metalLBConfig{
+		ControllerImage: controllerImage.URI(),
+		SpeakerImage:    speakerImage.URI(),
+		PullPolicy:      pullPolicy,
+	}
+	for _, pool := range slb.AddressPools {
+		cfg.AddressPools = append(cfg.AddressPools, metalLBAddressPool{
+			Name:       pool.Name,
+			Addresses:  pool.Addresses,
+			AutoAssign: pool.AutoAssign == nil || *pool.AutoAssign,
+		})
+	}
+
+	return cfg
+}
+
+// The manifests are based on the upstream native deployment of MetalLB,
+// without the validating webhook. The address pools from the cluster config
+// are announced via L2. Users may add their own pools and advertisements,
+// e.g. for BGP, to the metallb-system namespace.
+const metalLBTemplate = `
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: metallb-system
+  labels:
+    pod-security.kubernetes.io/enforce: privileged
+    pod-security.kubernetes.io/audit: privileged
+    pod-security.kubernetes.io/warn: privileged
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: controller
+  namespace: metallb-system
+  labels:
+    app: metallb
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: speaker
+  namespace: metallb-system
+  labels:
+    app: metallb
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: controller
+  namespace: metallb-system
+  labels:
+    app: metallb
+rules:
+- apiGroups: [""]
+  resources: ["secrets"]
+  verbs: ["create", "delete", "get", "list", "patch", "update", "watch"]
+- apiGroups: ["apps"]
+  resources: ["deployments"]
+  resourceNames: ["controller"]
+  verbs: ["get"]
+- apiGroups: ["metallb.io"]
+  resources: ["bgppeers"]
+  verbs: ["get", "list"]
+- apiGroups: ["metallb.io"]
+  resources: ["bfdprofiles", "bgpadvertisements", "communities", "ipaddresspools", "l2advertisements"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["metallb.io"]
+  resources: ["ipaddresspools/status"]
+  verbs: ["update"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: pod-lister
+  namespace: metallb-system
+  labels:
+    app: metallb
+rules:
+- apiGroups: [""]
+  resources: ["pods"]
+  verbs: ["list"]
+- apiGroups: [""]
+  resources: ["secrets", "configmaps"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["metallb.io"]
+  resources: ["bfdprofiles", "bgppeers", "bgpadvertisements", "communities", "ipaddresspools", "l2advertisements"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["metallb.io"]
+  resources: ["servicel2statuses", "servicel2statuses/status"]
+  verbs: ["*"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: metallb-system:controller
+  labels:
+    app: metallb
+rules:
+- apiGroups: [""]
+  resources: ["services", "namespaces"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["services/status"]
+  verbs: ["update"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: metallb-system:speaker
+  labels:
+    app: metallb
+rules:
+- apiGroups: [""]
+  resources: ["services", "endpoints", "nodes", "namespaces"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["discovery.k8s.io"]
+  resources: ["endpointslices"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["create", "patch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: controller
+  namespace: metallb-system
+  labels:
+    app: metallb
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: controller
+subjects:
+- kind: ServiceAccount
+  name: controller
+  namespace: metallb-system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: pod-lister
+  namespace: metallb-system
+  labels:
+    app: metallb
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: pod-lister
+subjects:
+- kind: ServiceAccount
+  name: speaker
+  namespace: metallb-system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: metallb-system:controller
+  labels:
+    app: metallb
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: metallb-system:controller
+subjects:
+- kind: ServiceAccount
+  name: controller
+  namespace: metallb-system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: metallb-system:speaker
+  labels:
+    app: metallb
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: metallb-system:speaker
+subjects:
+- kind: ServiceAccount
+  name: speaker
+  namespace: metallb-system
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: controller
+  namespace: metallb-system
+  labels:
+    app: metallb
+    component: controller
+spec:
+  revisionHistoryLimit: 3
+  selector:
+    matchLabels:
+      app: metallb
+      component: controller
+  template:
+    metadata:
+      labels:
+        app: metallb
+        component: controller
+      annotations:
+        prometheus.io/port: "7472"
+        prometheus.io/scrape: "true"
+    spec:
+      serviceAccountName: controller
+      terminationGracePeriodSeconds: 0
+      nodeSelector:
+        kubernetes.io/os: linux
+      securityContext:
+        fsGroup: 65534
+        runAsNonRoot: true
+        runAsUser: 65534
+      containers:
+      - name: controller
+        image: {{ .ControllerImage }}
+        imagePullPolicy: {{ .PullPolicy }}
+        args:
+        - --port=7472
+        - --log-level=info
+        - --webhook-mode=disabled
+        env:
+        - name: METALLB_ML_SECRET_NAME
+          value: memberlist
+        - name: METALLB_DEPLOYMENT
+          value: controller
+        ports:
+        - containerPort: 7472
+          name: monitoring
+        livenessProbe:
+          httpGet:
+            path: /metrics
+            port: monitoring
+          initialDelaySeconds: 10
+          periodSeconds: 10
+          timeoutSeconds: 1
+          successThreshold: 1
+          failureThreshold: 3
+        readinessProbe:
+          httpGet:
+            path: /metrics
+            port: monitoring
+          initialDelaySeconds: 10
+          periodSeconds: 10
+          timeoutSeconds: 1
+          successThreshold: 1
+          failureThreshold: 3
+        securityContext:
+          allowPrivilegeEscalation: false
+          capabilities:
+            drop:
+            - all
+          readOnlyRootFilesystem: true
+---
+apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: speaker
+  namespace: metallb-system
+  labels:
+    app: metallb
+    component: speaker
+spec:
+  selector:
+    matchLabels:
+      app: metallb
+      component: speaker
+  template:
+    metadata:
+      labels:
+        app: metallb
+        component: speaker
+      annotations:
+        prometheus.io/port: "7472"
+        prometheus.io/scrape: "true"
+    spec:
+      serviceAccountName: speaker
+      terminationGracePeriodSeconds: 2
+      hostNetwork: true
+      nodeSelector:
+        kubernetes.io/os: linux
+      tolerations:
+      - key: node-role.kubernetes.io/master
+        effect: NoSchedule
+        operator: Exists
+      - key: node-role.kubernetes.io/control-plane
+        effect: NoSchedule
+        operator: Exists
+      containers:
+      - name: speaker
+        image: {{ .SpeakerImage }}
+        imagePullPolicy: {{ .PullPolicy }}
+        args:
+        - --port=7472
+        - --log-level=info
+        env:
+        - name: METALLB_NODE_NAME
+          valueFrom:
+            fieldRef:
+              fieldPath: spec.nodeName
+        - name: METALLB_HOST
+          valueFrom:
+            fieldRef:
+              fieldPath: status.hostIP
+        - name: METALLB_ML_BIND_ADDR
+          valueFrom:
+            fieldRef:
+              fieldPath: status.podIP
+        - name: METALLB_ML_LABELS
+          value: "app=metallb,component=speaker"
+        - name: METALLB_ML_SECRET_KEY_PATH
+          value: /etc/ml_secret_key
+        ports:
+        - containerPort: 7472
+          name: monitoring
+        - containerPort: 7946
+          name: memberlist-tcp
+        - containerPort: 7946
+          name: memberlist-udp
+          protocol: UDP
+        livenessProbe:
+          httpGet:
+            path: /metrics
+            port: monitoring
+          initialDelaySeconds: 10
+          periodSeconds: 10
+          timeoutSeconds: 1
+          successThreshold: 1
+          failureThreshold: 3
+        readinessProbe:
+          httpGet:
+            path: /metrics
+            port: monitoring
+          initialDelaySeconds: 10
+          periodSeconds: 10
+          timeoutSeconds: 1
+          successThreshold: 1
+          failureThreshold: 3
+        securityContext:
+          allowPrivilegeEscalation: false
+          capabilities:
+            add:
+            - NET_RAW
+            drop:
+            - ALL
+          readOnlyRootFilesystem: true
+        volumeMounts:
+        - name: memberlist
+          mountPath: /etc/ml_secret_key
+      volumes:
+      - name: memberlist
+        secret:
+          secretName: memberlist
+          defaultMode: 420
+{{- range .AddressPools }}
+---
+apiVersion: metallb.io/v1beta1
+kind: IPAddressPool
+metadata:
+  name: {{ .Name }}
+  namespace: metallb-system
+spec:
+  addresses:
+{{- range .Addresses }}
+  - {{ quote . }}
+{{- end }}
+  autoAssign: {{ .AutoAssign }}
+{{- end }}
+{{- if .AddressPools }}
+---
+apiVersion: metallb.io/v1beta1
+kind: L2Advertisement
+metadata:
+  name: k0s
+  namespace: metallb-system
+spec:
+  ipAddressPools:
+{{- range .AddressPools }}
+  - {{ .Name }}
+{{- end }}
+{{- end }}
+`
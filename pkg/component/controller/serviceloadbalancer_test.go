@@ -0,0 +1,117 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/utils/pointer"
+)
+
+func TestServiceLoadBalancer_Reconcile(t *testing.T) {
+	k0sVars := constant.GetConfig(t.TempDir())
+	cfg := v1beta1.DefaultClusterConfig()
+	underTest := NewServiceLoadBalancer(k0sVars)
+	manifest := filepath.Join(underTest.manifestDir, "metallb.yaml")
+
+	readObjects := func(t *testing.T) map[string]unstructured.Unstructured {
+		data, err := os.ReadFile(manifest)
+		require.NoError(t, err)
+
+		objects := make(map[string]unstructured.Unstructured)
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			var obj unstructured.Unstructured
+			if err := decoder.Decode(&obj.Object); err != nil {
+				break
+			}
+			if obj.Object == nil {
+				continue
+			}
+			objects[obj.GetKind()+"/"+obj.GetName()] = obj
+		}
+		return objects
+	}
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoDirExists(t, underTest.manifestDir)
+	})
+
+	t.Run("enabled_without_pools", func(t *testing.T) {
+		cfg.Spec.Network.ServiceLoadBalancer = v1beta1.DefaultServiceLoadBalancer()
+		cfg.Spec.Network.ServiceLoadBalancer.Enabled = true
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+
+		assert.FileExists(t, filepath.Join(underTest.manifestDir, "crd-metallb.io_ipaddresspools.yaml"))
+		assert.FileExists(t, filepath.Join(underTest.manifestDir, "crd-metallb.io_l2advertisements.yaml"))
+
+		objects := readObjects(t)
+		assert.Contains(t, objects, "Deployment/controller")
+		assert.Contains(t, objects, "DaemonSet/speaker")
+		assert.NotContains(t, objects, "L2Advertisement/k0s")
+
+		containers, _, err := unstructured.NestedSlice(objects["Deployment/controller"].Object, "spec", "template", "spec", "containers")
+		require.NoError(t, err)
+		require.Len(t, containers, 1)
+		container := containers[0].(map[string]any)
+		assert.Equal(t, v1beta1.DefaultMetalLBControllerImage().URI(), container["image"])
+		assert.Contains(t, container["args"], "--webhook-mode=disabled")
+	})
+
+	t.Run("address_pools", func(t *testing.T) {
+		cfg.Spec.Network.ServiceLoadBalancer.AddressPools = []v1beta1.ServiceLoadBalancerAddressPool{
+			{Name: "default", Addresses: []string{"192.168.100.0/28", "192.168.100.32-192.168.100.40"}},
+			{Name: "reserved", Addresses: []string{"fd00::/120"}, AutoAssign: pointer.Bool(false)},
+		}
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+
+		objects := readObjects(t)
+
+		addresses, _, err := unstructured.NestedStringSlice(objects["IPAddressPool/default"].Object, "spec", "addresses")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"192.168.100.0/28", "192.168.100.32-192.168.100.40"}, addresses)
+		autoAssign, _, err := unstructured.NestedBool(objects["IPAddressPool/default"].Object, "spec", "autoAssign")
+		require.NoError(t, err)
+		assert.True(t, autoAssign)
+
+		autoAssign, _, err = unstructured.NestedBool(objects["IPAddressPool/reserved"].Object, "spec", "autoAssign")
+		require.NoError(t, err)
+		assert.False(t, autoAssign)
+
+		pools, _, err := unstructured.NestedStringSlice(objects["L2Advertisement/k0s"].Object, "spec", "ipAddressPools")
+		require.NoError(t, err)
+		assert.Equal(t, []string{"default", "reserved"}, pools)
+	})
+
+	t.Run("disabled_again", func(t *testing.T) {
+		cfg.Spec.Network.ServiceLoadBalancer.Enabled = false
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoDirExists(t, underTest.manifestDir)
+	})
+}
@@ -0,0 +1,277 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/constant"
+	"github.com/k0sproject/k0s/static"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SnapshotController is the component implementation to manage the CSI
+// snapshot controller along with the volume snapshot CRDs. The validation that
+// used to be done by the snapshot validation webhook is part of the CRDs, in
+// the form of CEL validation rules.
+type SnapshotController struct {
+	log logrus.FieldLogger
+
+	manifestDir string
+
+	previousConfig snapshotControllerConfig
+}
+
+var _ manager.Component = (*SnapshotController)(nil)
+var _ manager.Reconciler = (*SnapshotController)(nil)
+
+type snapshotControllerConfig struct {
+	Image      string
+	PullPolicy string
+}
+
+const snapshotControllerManifest = "snapshot-controller.yaml"
+
+// NewSnapshotController creates a new SnapshotController component.
+func NewSnapshotController(k0sVars constant.CfgVars) *SnapshotController {
+	return &SnapshotController{
+		log: logrus.WithFields(logrus.Fields{"component": constant.SnapshotControllerComponentName}),
+
+		manifestDir: path.Join(k0sVars.ManifestsDir, "snapshot-controller"),
+	}
+}
+
+// Init does nothing
+func (s *SnapshotController) Init(context.Context) error {
+	return nil
+}
+
+// Start does nothing
+func (s *SnapshotController) Start(context.Context) error {
+	return nil
+}
+
+// Reconcile detects changes in configuration and applies them to the component
+func (s *SnapshotController) Reconcile(_ context.Context, clusterConfig *v1beta1.ClusterConfig) error {
+	snapshotController := clusterConfig.Spec.SnapshotController
+	if !snapshotController.IsEnabled() {
+		s.previousConfig = snapshotControllerConfig{}
+		// Keep the CRDs, removing them would delete all volume snapshot objects.
+		err := os.Remove(filepath.Join(s.manifestDir, snapshotControllerManifest))
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	cfg := s.getConfig(clusterConfig)
+	if cfg == s.previousConfig {
+		s.log.Debug("current config matches existing, not gonna do anything")
+		return nil
+	}
+
+	if err := dir.Init(s.manifestDir, constant.ManifestsDirMode); err != nil {
+		return err
+	}
+	if err := s.writeCRDs(); err != nil {
+		return err
+	}
+
+	tw := templatewriter.TemplateWriter{
+		Name:     "snapshot-controller",
+		Template: snapshotControllerTemplate,
+		Data:     cfg,
+		Path:     filepath.Join(s.manifestDir, snapshotControllerManifest),
+	}
+	if err := tw.Write(); err != nil {
+		return fmt.Errorf("error writing snapshot-controller manifests: %w", err)
+	}
+	s.previousConfig = cfg
+
+	return nil
+}
+
+// Stop does nothing
+func (s *SnapshotController) Stop() error {
+	return nil
+}
+
+func (s *SnapshotController) writeCRDs() error {
+	crds, err := static.AssetDir("manifests/snapshot-controller/CustomResourceDefinition")
+	if err != nil {
+		return err
+	}
+
+	for _, filename := range crds {
+		content, err := static.Asset(fmt.Sprintf("manifests/snapshot-controller/CustomResourceDefinition/%s", filename))
+		if err != nil {
+			return fmt.Errorf("failed to fetch crd %s: %w", filename, err)
+		}
+		manifest := filepath.Join(s.manifestDir, "crd-"+filename)
+		if err := file.WriteContentAtomically(manifest, content, constant.CertMode); err != nil {
+			return fmt.Errorf("failed to write crd %s: %w", filename, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *SnapshotController) getConfig(clusterConfig *v1beta1.ClusterConfig) snapshotControllerConfig {
+	snapshotController := clusterConfig.Spec.SnapshotController
+	image := snapshotController.Image
+	if image == nil {
+		image = v1beta1.DefaultSnapshotControllerImage()
+	}
+	pullPolicy := string(snapshotController.ImagePullPolicy)
+	if pullPolicy == "" {
+		pullPolicy = clusterConfig.Spec.Images.DefaultPullPolicy
+	}
+
+	return snapshotControllerConfig{
+		Image:      image.URI(),
+		PullPolicy: pullPolicy,
+	}
+}
+
+// The RBAC rules are the ones of the upstream deployment in
+// kubernetes-csi/external-snapshotter.
+const snapshotControllerTemplate = `
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: snapshot-controller
+  namespace: kube-system
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: snapshot-controller-runner
+rules:
+- apiGroups: [""]
+  resources: ["persistentvolumes"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: [""]
+  resources: ["persistentvolumeclaims"]
+  verbs: ["get", "list", "watch", "update"]
+- apiGroups: [""]
+  resources: ["events"]
+  verbs: ["list", "watch", "create", "update", "patch"]
+- apiGroups: ["snapshot.storage.k8s.io"]
+  resources: ["volumesnapshotclasses"]
+  verbs: ["get", "list", "watch"]
+- apiGroups: ["snapshot.storage.k8s.io"]
+  resources: ["volumesnapshotcontents"]
+  verbs: ["create", "get", "list", "watch", "update", "delete", "patch"]
+- apiGroups: ["snapshot.storage.k8s.io"]
+  resources: ["volumesnapshotcontents/status"]
+  verbs: ["patch"]
+- apiGroups: ["snapshot.storage.k8s.io"]
+  resources: ["volumesnapshots"]
+  verbs: ["get", "list", "watch", "update", "patch", "delete"]
+- apiGroups: ["snapshot.storage.k8s.io"]
+  resources: ["volumesnapshots/status"]
+  verbs: ["update", "patch"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: snapshot-controller-role
+subjects:
+- kind: ServiceAccount
+  name: snapshot-controller
+  namespace: kube-system
+roleRef:
+  kind: ClusterRole
+  name: snapshot-controller-runner
+  apiGroup: rbac.authorization.k8s.io
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: snapshot-controller-leaderelection
+  namespace: kube-system
+rules:
+- apiGroups: ["coordination.k8s.io"]
+  resources: ["leases"]
+  verbs: ["get", "watch", "list", "delete", "update", "create"]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: snapshot-controller-leaderelection
+  namespace: kube-system
+subjects:
+- kind: ServiceAccount
+  name: snapshot-controller
+  namespace: kube-system
+roleRef:
+  kind: Role
+  name: snapshot-controller-leaderelection
+  apiGroup: rbac.authorization.k8s.io
+---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: snapshot-controller
+  namespace: kube-system
+  labels:
+    app.kubernetes.io/name: snapshot-controller
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app.kubernetes.io/name: snapshot-controller
+  strategy:
+    rollingUpdate:
+      maxSurge: 0
+      maxUnavailable: 1
+    type: RollingUpdate
+  template:
+    metadata:
+      labels:
+        app.kubernetes.io/name: snapshot-controller
+    spec:
+      serviceAccountName: snapshot-controller
+      priorityClassName: system-cluster-critical
+      nodeSelector:
+        kubernetes.io/os: linux
+      tolerations:
+      - key: "CriticalAddonsOnly"
+        operator: "Exists"
+      containers:
+      - name: snapshot-controller
+        image: {{ .Image }}
+        imagePullPolicy: {{ .PullPolicy }}
+        args:
+        - "--v=2"
+        - "--leader-election=true"
+        - "--leader-election-namespace=kube-system"
+        resources:
+          requests:
+            cpu: 10m
+            memory: 20Mi
+`
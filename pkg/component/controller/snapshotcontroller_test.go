@@ -0,0 +1,96 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+func TestSnapshotController_Reconcile(t *testing.T) {
+	k0sVars := constant.GetConfig(t.TempDir())
+	cfg := v1beta1.DefaultClusterConfig()
+	underTest := NewSnapshotController(k0sVars)
+	manifest := filepath.Join(underTest.manifestDir, "snapshot-controller.yaml")
+	crds := []string{
+		"crd-snapshot.storage.k8s.io_volumesnapshotclasses.yaml",
+		"crd-snapshot.storage.k8s.io_volumesnapshotcontents.yaml",
+		"crd-snapshot.storage.k8s.io_volumesnapshots.yaml",
+	}
+
+	t.Run("disabled_by_default", func(t *testing.T) {
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoDirExists(t, underTest.manifestDir)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		cfg.Spec.SnapshotController = v1beta1.DefaultSnapshotControllerSpec()
+		cfg.Spec.SnapshotController.Enabled = true
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+
+		for _, crd := range crds {
+			assert.FileExists(t, filepath.Join(underTest.manifestDir, crd))
+		}
+
+		data, err := os.ReadFile(manifest)
+		require.NoError(t, err)
+
+		var kinds []string
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(data), 4096)
+		for {
+			var obj unstructured.Unstructured
+			if err := decoder.Decode(&obj.Object); err != nil {
+				break
+			}
+			if obj.Object == nil {
+				continue
+			}
+			kinds = append(kinds, obj.GetKind())
+
+			if obj.GetKind() == "Deployment" {
+				containers, _, err := unstructured.NestedSlice(obj.Object, "spec", "template", "spec", "containers")
+				require.NoError(t, err)
+				require.Len(t, containers, 1)
+				container := containers[0].(map[string]any)
+				assert.Equal(t, v1beta1.DefaultSnapshotControllerImage().URI(), container["image"])
+				assert.Equal(t, "IfNotPresent", container["imagePullPolicy"])
+			}
+		}
+
+		assert.Equal(t, []string{"ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding", "Deployment"}, kinds)
+	})
+
+	t.Run("disabled_again_keeps_crds", func(t *testing.T) {
+		cfg.Spec.SnapshotController.Enabled = false
+		require.NoError(t, underTest.Reconcile(context.TODO(), cfg))
+		assert.NoFileExists(t, manifest)
+		for _, crd := range crds {
+			assert.FileExists(t, filepath.Join(underTest.manifestDir, crd))
+		}
+	})
+}
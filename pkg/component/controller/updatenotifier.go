@@ -0,0 +1,98 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/autopilot/updater"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/component/prober"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/sirupsen/logrus"
+)
+
+// UpdateNotifier periodically checks if there's a newer k0s version available
+// in a release channel, and emits an event if so.
+type UpdateNotifier struct {
+	Client         updater.Client
+	Channel        string
+	CurrentVersion string
+	Interval       time.Duration
+
+	*prober.EventEmitter
+	log      logrus.FieldLogger
+	notified updater.Version
+	stop     func()
+}
+
+var _ manager.Component = (*UpdateNotifier)(nil)
+
+func (n *UpdateNotifier) Init(context.Context) error {
+	n.log = logrus.WithField("component", "update-notifier")
+	if n.EventEmitter == nil {
+		n.EventEmitter = prober.NewEventEmitter()
+	}
+	return nil
+}
+
+func (n *UpdateNotifier) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait.UntilWithContext(ctx, func(context.Context) { n.check() }, n.Interval)
+	}()
+
+	n.stop = func() { cancel(); <-done }
+	return nil
+}
+
+func (n *UpdateNotifier) Stop() error {
+	if n.stop != nil {
+		n.stop()
+	}
+	return nil
+}
+
+// check notifies about the latest version in the channel, once per version.
+func (n *UpdateNotifier) check() {
+	update, err := n.Client.GetUpdate(n.Channel, "", "", n.CurrentVersion)
+	if err != nil {
+		n.log.WithError(err).Warn("Failed to check for updates")
+		return
+	}
+	outdated, err := update.Version.IsNewerThan(n.CurrentVersion)
+	if err != nil {
+		n.log.WithError(err).Warn("Failed to check for updates")
+		return
+	}
+	if !outdated || update.Version == n.notified {
+		return
+	}
+
+	n.log.Infof("k0s %s is available in the %s channel, currently running %s", update.Version, n.Channel, n.CurrentVersion)
+	n.EmitWithPayload("Update available", map[string]string{
+		"channel": n.Channel,
+		"current": n.CurrentVersion,
+		"latest":  string(update.Version),
+	})
+	n.notified = update.Version
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/autopilot/updater"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUpdateClient struct {
+	version updater.Version
+}
+
+func (c *fakeUpdateClient) GetUpdate(string, string, string, string) (*updater.Update, error) {
+	return &updater.Update{Version: c.version}, nil
+}
+
+func TestUpdateNotifier(t *testing.T) {
+	client := &fakeUpdateClient{"v1.27.1+k0s.0"}
+	underTest := &UpdateNotifier{
+		Client:         client,
+		Channel:        "stable",
+		CurrentVersion: "v1.27.1+k0s.0",
+	}
+	require.NoError(t, underTest.Init(context.TODO()))
+
+	underTest.check()
+	assert.Empty(t, underTest.Events())
+
+	client.version = "v1.27.2+k0s.0"
+	underTest.check()
+	underTest.check() // notifies only once per version
+	if assert.Len(t, underTest.Events(), 1) {
+		event := <-underTest.Events()
+		assert.Equal(t, "Update available", event.Message)
+		assert.Equal(t, map[string]string{
+			"channel": "stable",
+			"current": "v1.27.1+k0s.0",
+			"latest":  "v1.27.2+k0s.0",
+		}, event.Payload)
+	}
+
+	client.version = "v1.27.3+k0s.0"
+	underTest.check()
+	assert.Len(t, underTest.Events(), 1)
+}
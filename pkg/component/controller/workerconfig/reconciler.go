@@ -19,6 +19,7 @@ package workerconfig
 import (
 	"context"
 	"crypto/tls"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math"
@@ -32,6 +33,7 @@ import (
 	k0snet "github.com/k0sproject/k0s/internal/pkg/net"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/applier"
+	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
 	"github.com/k0sproject/k0s/pkg/component/manager"
 	workerconfig "github.com/k0sproject/k0s/pkg/component/worker/config"
@@ -41,18 +43,20 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
 	kubeletv1beta1 "k8s.io/kubelet/config/v1beta1"
 	"k8s.io/utils/pointer"
 
 	"github.com/sirupsen/logrus"
 	"go.uber.org/multierr"
 	"golang.org/x/exp/slices"
-	"sigs.k8s.io/yaml"
 )
 
 type resources = []*unstructured.Unstructured
@@ -62,6 +66,7 @@ type resources = []*unstructured.Unstructured
 type Reconciler struct {
 	log logrus.FieldLogger
 
+	certRootDir                    string
 	clusterDomain                  string
 	clusterDNSIP                   net.IP
 	apiServerReconciliationEnabled bool
@@ -111,6 +116,7 @@ func NewReconciler(k0sVars constant.CfgVars, nodeSpec *v1beta1.ClusterSpec, clie
 	reconciler := &Reconciler{
 		log: log,
 
+		certRootDir:                    k0sVars.CertRootDir,
 		clusterDomain:                  nodeSpec.Network.ClusterDomain,
 		clusterDNSIP:                   clusterDNSIP,
 		apiServerReconciliationEnabled: !nodeSpec.API.TunneledNetworkingMode,
@@ -148,6 +154,8 @@ func (r *Reconciler) Init(context.Context) error {
 			Client:    dynamicClient,
 			Discovery: discoveryClient,
 			Resources: resources,
+
+			ForceConflicts: true,
 		}).Apply(ctx, true)
 	}
 
@@ -200,6 +208,18 @@ func (r *Reconciler) Start(context.Context) error {
 		}()
 	}
 
+	// Reconcile the cluster CA, so that workers follow CA rotations.
+	go func() {
+		wait.UntilWithContext(reconcilerCtx, func(ctx context.Context) {
+			err := r.reconcileCertificateAuthorities(ctx, updates, stopped)
+			// Log any reconciliation errors, but only if they don't indicate
+			// that the reconciler has been stopped concurrently.
+			if err != nil && !errors.Is(err, reconcilerCtx.Err()) && !errors.Is(err, errStoppedConcurrently) {
+				r.log.WithError(err).Error("Failed to reconcile certificate authorities")
+			}
+		}, 30*time.Second)
+	}()
+
 	// React to leader elector changes. Enforce a reconciliation whenever the
 	// lease is acquired.
 	r.leaderElector.AddAcquiredLeaseCallback(func() {
@@ -335,6 +355,12 @@ func (r *Reconciler) Reconcile(ctx context.Context, cluster *v1beta1.ClusterConf
 		return err
 	}
 
+	// Reject invalid worker profiles upfront, so that they're not rolled out
+	// to the workers, where they'd prevent kubelet from starting.
+	if errs := cluster.Spec.WorkerProfiles.Validate(); len(errs) > 0 {
+		return fmt.Errorf("invalid worker profiles: %w", multierr.Combine(errs...))
+	}
+
 	configSnapshot := takeConfigSnapshot(cluster.Spec)
 
 	return reconcile(ctx, updates, stopped, func(s *snapshot) {
@@ -417,6 +443,51 @@ func (r *Reconciler) reconcileAPIServers(ctx context.Context, updates chan<- upd
 		})
 }
 
+func (r *Reconciler) reconcileCertificateAuthorities(ctx context.Context, updates chan<- updateFunc, stopped <-chan struct{}) error {
+	client, err := r.clientFactory.GetClient()
+	if err != nil {
+		return err
+	}
+
+	cas, err := loadCertificateAuthorities(ctx, client, r.certRootDir)
+	if err != nil {
+		return err
+	}
+
+	return reconcile(ctx, updates, stopped, func(s *snapshot) { s.certificateAuthorities = *cas })
+}
+
+// loadCertificateAuthorities determines the CAs that workers trust. While a
+// CA rotation is in progress, the signer is included, so that workers are
+// able to detect whether their certificates need to be reissued.
+func loadCertificateAuthorities(ctx context.Context, client kubernetes.Interface, certRootDir string) (*workerconfig.CertificateAuthorities, error) {
+	secret, err := client.CoreV1().Secrets(metav1.NamespaceSystem).Get(ctx, certificate.CARotationSecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		bundle, err := certificate.ReadCABundle(certRootDir)
+		if err != nil {
+			return nil, err
+		}
+		return &workerconfig.CertificateAuthorities{
+			Bundle:  string(bundle),
+			Version: certificate.CABundleVersion(bundle),
+		}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	rotation, err := certificate.CARotationFromSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := rotation.TrustBundle()
+	return &workerconfig.CertificateAuthorities{
+		Bundle:  string(bundle),
+		Signer:  string(rotation.Signer()),
+		Version: certificate.CABundleVersion(bundle),
+	}, nil
+}
+
 func extractAPIServerAddresses(endpoints *corev1.Endpoints) ([]k0snet.HostPort, error) {
 	var warnings error
 	apiServers := []k0snet.HostPort{}
@@ -520,6 +591,11 @@ func (r *Reconciler) buildConfigMaps(snapshot *snapshot) ([]*corev1.ConfigMap, e
 
 	workerProfile = r.buildProfile(snapshot)
 	workerProfile.KubeletConfiguration.CgroupsPerQOS = pointer.Bool(false)
+	// Graceful node shutdown isn't supported on Windows.
+	workerProfile.KubeletConfiguration.ShutdownGracePeriod = metav1.Duration{}
+	workerProfile.KubeletConfiguration.ShutdownGracePeriodCriticalPods = metav1.Duration{}
+	// NodeLocal DNSCache isn't deployed to Windows nodes.
+	workerProfile.KubeletConfiguration.ClusterDNS = []string{r.clusterDNSIP.String()}
 	workerProfiles["default-windows"] = workerProfile
 
 	for _, profile := range snapshot.profiles {
@@ -527,9 +603,17 @@ func (r *Reconciler) buildConfigMaps(snapshot *snapshot) ([]*corev1.ConfigMap, e
 		if !ok {
 			workerProfile = r.buildProfile(snapshot)
 		}
-		if err := yaml.Unmarshal(profile.Config, &workerProfile.KubeletConfiguration); err != nil {
+		if err := profile.DecodeKubeletConfiguration(&workerProfile.KubeletConfiguration); err != nil {
 			return nil, fmt.Errorf("failed to decode worker profile %q: %w", profile.Name, err)
 		}
+		applyProfileFields(&workerProfile.KubeletConfiguration, &profile)
+		if err := applyKubeletConfigPatch(&workerProfile.KubeletConfiguration, profile.KubeletConfigPatch); err != nil {
+			return nil, fmt.Errorf("failed to patch kubelet configuration of worker profile %q: %w", profile.Name, err)
+		}
+		workerProfile.Kernel = profile.Kernel.DeepCopy()
+		if profile.SELinux != nil {
+			workerProfile.SELinux = pointer.Bool(*profile.SELinux)
+		}
 		workerProfiles[profile.Name] = workerProfile
 	}
 
@@ -598,6 +682,11 @@ func (r *Reconciler) buildProfile(snapshot *snapshot) *workerconfig.Profile {
 		cipherSuites[i] = tls.CipherSuiteName(cipherSuite)
 	}
 
+	clusterDNS := r.clusterDNSIP.String()
+	if snapshot.nodeLocalDNSIP != "" {
+		clusterDNS = snapshot.nodeLocalDNSIP
+	}
+
 	workerProfile := &workerconfig.Profile{
 		APIServerAddresses: slices.Clone(snapshot.apiServers),
 		KubeletConfiguration: kubeletv1beta1.KubeletConfiguration{
@@ -606,7 +695,7 @@ func (r *Reconciler) buildProfile(snapshot *snapshot) *workerconfig.Profile {
 				APIVersion: kubeletv1beta1.SchemeGroupVersion.String(),
 				Kind:       "KubeletConfiguration",
 			},
-			ClusterDNS:         []string{r.clusterDNSIP.String()},
+			ClusterDNS:         []string{clusterDNS},
 			ClusterDomain:      r.clusterDomain,
 			TLSMinVersion:      "VersionTLS12",
 			TLSCipherSuites:    cipherSuites,
@@ -614,12 +703,18 @@ func (r *Reconciler) buildProfile(snapshot *snapshot) *workerconfig.Profile {
 			RotateCertificates: true,
 			ServerTLSBootstrap: true,
 			EventRecordQPS:     pointer.Int32(0),
+			// Delay node shutdowns, so that pods get evicted gracefully.
+			ShutdownGracePeriod:             metav1.Duration{Duration: v1beta1.DefaultShutdownGracePeriod},
+			ShutdownGracePeriodCriticalPods: metav1.Duration{Duration: v1beta1.DefaultShutdownGracePeriodCriticalPods},
 		},
 		NodeLocalLoadBalancing: snapshot.nodeLocalLoadBalancing.DeepCopy(),
 		Konnectivity: workerconfig.Konnectivity{
 			Enabled:   r.konnectivityEnabled,
 			AgentPort: snapshot.konnectivityAgentPort,
 		},
+		CertificateAuthorities: snapshot.certificateAuthorities,
+		IPTables:               snapshot.iptables,
+		ExternalCloudProvider:  snapshot.externalCloudProvider,
 	}
 
 	if workerProfile.NodeLocalLoadBalancing != nil &&
@@ -631,6 +726,66 @@ func (r *Reconciler) buildProfile(snapshot *snapshot) *workerconfig.Profile {
 	return workerProfile
 }
 
+// applyProfileFields renders the first-class fields of the given worker
+// profile into the given kubelet configuration. Feature gates are merged with
+// the cluster-wide ones, all other fields replace the defaults.
+func applyProfileFields(config *kubeletv1beta1.KubeletConfiguration, profile *v1beta1.WorkerProfile) {
+	if len(profile.FeatureGates) > 0 && config.FeatureGates == nil {
+		config.FeatureGates = make(map[string]bool, len(profile.FeatureGates))
+	}
+	for name, enabled := range profile.FeatureGates {
+		config.FeatureGates[name] = enabled
+	}
+
+	if profile.SystemReserved != nil {
+		config.SystemReserved = profile.SystemReserved
+	}
+	if profile.KubeReserved != nil {
+		config.KubeReserved = profile.KubeReserved
+	}
+	if profile.EvictionHard != nil {
+		config.EvictionHard = profile.EvictionHard
+	}
+	if profile.EvictionSoft != nil {
+		config.EvictionSoft = profile.EvictionSoft
+	}
+	if profile.EvictionSoftGracePeriod != nil {
+		config.EvictionSoftGracePeriod = profile.EvictionSoftGracePeriod
+	}
+	if profile.MaxPods != nil {
+		config.MaxPods = *profile.MaxPods
+	}
+	if profile.ShutdownGracePeriod != nil || profile.ShutdownGracePeriodCriticalPods != nil {
+		gracePeriod, criticalPodsGracePeriod := profile.ShutdownGracePeriods()
+		config.ShutdownGracePeriod = metav1.Duration{Duration: gracePeriod}
+		config.ShutdownGracePeriodCriticalPods = metav1.Duration{Duration: criticalPodsGracePeriod}
+	}
+}
+
+// applyKubeletConfigPatch applies the given strategic merge patch on top of
+// the given kubelet configuration.
+func applyKubeletConfigPatch(config *kubeletv1beta1.KubeletConfiguration, patch json.RawMessage) error {
+	if len(patch) == 0 {
+		return nil
+	}
+
+	original, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	patched, err := strategicpatch.StrategicMergePatch(original, patch, kubeletv1beta1.KubeletConfiguration{})
+	if err != nil {
+		return err
+	}
+
+	var patchedConfig kubeletv1beta1.KubeletConfiguration
+	if err := json.Unmarshal(patched, &patchedConfig); err != nil {
+		return err
+	}
+	*config = patchedConfig
+	return nil
+}
+
 func toConfigMap(profileName string, profile *workerconfig.Profile) (*corev1.ConfigMap, error) {
 	data, err := workerconfig.ToConfigMapData(profile)
 	if err != nil {
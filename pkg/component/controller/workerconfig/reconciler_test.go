@@ -19,6 +19,8 @@ package workerconfig
 import (
 	"context"
 	"encoding/json"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -27,6 +29,7 @@ import (
 
 	"github.com/k0sproject/k0s/internal/testutil"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
 	"github.com/k0sproject/k0s/pkg/constant"
 
@@ -34,6 +37,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/kubernetes/typed/core/v1/fake"
 	k8stesting "k8s.io/client-go/testing"
 	kubeletv1beta1 "k8s.io/kubelet/config/v1beta1"
@@ -353,6 +357,10 @@ func TestReconciler_ResourceGeneration(t *testing.T) {
 						APIServerBindPort: 1337,
 					},
 				},
+				NodeLocalDNSCache: &v1beta1.NodeLocalDNSCache{
+					Enabled: true,
+					LocalIP: "169.254.20.10",
+				},
 			},
 			Images: &v1beta1.ClusterImages{
 				DefaultPullPolicy: string(corev1.PullNever),
@@ -363,30 +371,69 @@ func TestReconciler_ResourceGeneration(t *testing.T) {
 			}, {
 				Name:   "profile_YYY",
 				Config: []byte(`{"authentication": {"webhook": {"cacheTTL": "15s"}}}`),
+			}, {
+				Name:                    "profile_ZZZ",
+				FeatureGates:            map[string]bool{"kubelet-feature": false, "profile-feature": true},
+				SystemReserved:          map[string]string{"memory": "1Gi"},
+				KubeReserved:            map[string]string{"cpu": "500m"},
+				EvictionHard:            map[string]string{"memory.available": "500Mi"},
+				EvictionSoft:            map[string]string{"nodefs.available": "15%"},
+				EvictionSoftGracePeriod: map[string]string{"nodefs.available": "1m30s"},
+				MaxPods:                 pointer.Int32(42),
+				ShutdownGracePeriod:     &metav1.Duration{Duration: 5 * time.Second},
+			}, {
+				Name:               "profile_PPP",
+				EvictionHard:       map[string]string{"memory.available": "500Mi", "nodefs.available": "10%"},
+				KubeletConfigPatch: []byte(`{"evictionHard": {"memory.available": null, "nodefs.available": "5%"}, "authentication": {"webhook": {"cacheTTL": "15s"}}}`),
 			}},
 		},
 	}))
 
 	configMaps := map[string]func(t *testing.T, expected *kubeletConfig){
 		"worker-config-default-1.27": func(t *testing.T, expected *kubeletConfig) {
+			expected.ClusterDNS = []string{"169.254.20.10"}
 			expected.CgroupsPerQOS = pointer.Bool(true)
 			expected.FeatureGates = map[string]bool{"kubelet-feature": true}
 		},
 
 		"worker-config-default-windows-1.27": func(t *testing.T, expected *kubeletConfig) {
 			expected.CgroupsPerQOS = pointer.Bool(false)
+			expected.ShutdownGracePeriod = metav1.Duration{}
+			expected.ShutdownGracePeriodCriticalPods = metav1.Duration{}
 			expected.FeatureGates = map[string]bool{"kubelet-feature": true}
 		},
 
 		"worker-config-profile_XXX-1.27": func(t *testing.T, expected *kubeletConfig) {
+			expected.ClusterDNS = []string{"169.254.20.10"}
 			expected.Authentication.Anonymous.Enabled = pointer.Bool(true)
 			expected.FeatureGates = map[string]bool{"kubelet-feature": true}
 		},
 
 		"worker-config-profile_YYY-1.27": func(t *testing.T, expected *kubeletConfig) {
+			expected.ClusterDNS = []string{"169.254.20.10"}
 			expected.Authentication.Webhook.CacheTTL = metav1.Duration{Duration: 15 * time.Second}
 			expected.FeatureGates = map[string]bool{"kubelet-feature": true}
 		},
+
+		"worker-config-profile_ZZZ-1.27": func(t *testing.T, expected *kubeletConfig) {
+			expected.ClusterDNS = []string{"169.254.20.10"}
+			expected.FeatureGates = map[string]bool{"kubelet-feature": false, "profile-feature": true}
+			expected.SystemReserved = map[string]string{"memory": "1Gi"}
+			expected.KubeReserved = map[string]string{"cpu": "500m"}
+			expected.EvictionHard = map[string]string{"memory.available": "500Mi"}
+			expected.EvictionSoft = map[string]string{"nodefs.available": "15%"}
+			expected.EvictionSoftGracePeriod = map[string]string{"nodefs.available": "1m30s"}
+			expected.MaxPods = 42
+			expected.ShutdownGracePeriod = metav1.Duration{Duration: 5 * time.Second}
+			expected.ShutdownGracePeriodCriticalPods = metav1.Duration{Duration: 5 * time.Second}
+		},
+
+		"worker-config-profile_PPP-1.27": func(t *testing.T, expected *kubeletConfig) {
+			expected.ClusterDNS = []string{"169.254.20.10"}
+			expected.FeatureGates = map[string]bool{"kubelet-feature": true}
+			expected.EvictionHard = map[string]string{"nodefs.available": "5%"}
+			expected.Authentication.Webhook.CacheTTL = metav1.Duration{Duration: 15 * time.Second}
+		},
 	}
 
 	appliedResources := applied()
@@ -535,6 +582,17 @@ func TestReconciler_ReconcilesOnChangesOnly(t *testing.T) {
 	cluster.Spec.WorkerProfiles = v1beta1.WorkerProfiles{{Name: "foo", Config: json.RawMessage(`{"nodeLeaseDurationSeconds": 1}`)}}
 	t.Run("fifth_time_apply", expectApply)
 	t.Run("sixth_time_cached", expectCached)
+
+	// Invalid worker profiles are rejected without being applied.
+	cluster.Spec.WorkerProfiles = v1beta1.WorkerProfiles{{Name: "foo", Config: json.RawMessage(`{"nodeLeaseDurationSecs": 1}`)}}
+	t.Run("seventh_time_rejected", func(t *testing.T) {
+		err := underTest.Reconcile(context.TODO(), cluster)
+		assert.ErrorContains(t, err, `invalid worker profiles: profile "foo": values: unknown field "nodeLeaseDurationSecs"`)
+	})
+
+	// The last successfully applied config is still in effect.
+	cluster.Spec.WorkerProfiles = v1beta1.WorkerProfiles{{Name: "foo", Config: json.RawMessage(`{"nodeLeaseDurationSeconds": 1}`)}}
+	t.Run("eighth_time_cached", expectCached)
 }
 
 func TestReconciler_runReconcileLoop(t *testing.T) {
@@ -589,6 +647,45 @@ func TestReconciler_runReconcileLoop(t *testing.T) {
 	}
 }
 
+func TestLoadCertificateAuthorities(t *testing.T) {
+	certRootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(certRootDir, "ca.crt"), []byte("current\n"), 0644))
+	client := kubefake.NewSimpleClientset()
+
+	t.Run("no_rotation", func(t *testing.T) {
+		cas, err := loadCertificateAuthorities(context.TODO(), client, certRootDir)
+		require.NoError(t, err)
+		assert.Equal(t, "current\n", cas.Bundle)
+		assert.Empty(t, cas.Signer)
+		assert.Equal(t, certificate.CABundleVersion([]byte("current\n")), cas.Version)
+	})
+
+	for _, test := range []struct {
+		phase          certificate.CARotationPhase
+		bundle, signer string
+	}{
+		{certificate.CARotationTrust, "previous\nnext\n", "previous\n"},
+		{certificate.CARotationReissue, "next\nprevious\n", "next\n"},
+		{certificate.CARotationFinalize, "next\n", "next\n"},
+	} {
+		t.Run(string(test.phase), func(t *testing.T) {
+			rotation := certificate.CARotation{
+				Phase:          test.phase,
+				CACert:         []byte("next\n"),
+				CAKey:          []byte("key\n"),
+				PreviousCACert: []byte("previous\n"),
+			}
+			client := kubefake.NewSimpleClientset(rotation.Secret())
+
+			cas, err := loadCertificateAuthorities(context.TODO(), client, certRootDir)
+			require.NoError(t, err)
+			assert.Equal(t, test.bundle, cas.Bundle)
+			assert.Equal(t, test.signer, cas.Signer)
+			assert.Equal(t, certificate.CABundleVersion([]byte(test.bundle)), cas.Version)
+		})
+	}
+}
+
 func TestReconciler_LeaderElection(t *testing.T) {
 	var le mockLeaderElector
 	cluster := v1beta1.DefaultClusterConfig(nil)
@@ -716,13 +813,15 @@ func makeKubeletConfig(t *testing.T, mods ...func(*kubeletConfig)) string {
 			APIVersion: kubeletv1beta1.SchemeGroupVersion.String(),
 			Kind:       "KubeletConfiguration",
 		},
-		ClusterDNS:         []string{"99.99.99.10"},
-		ClusterDomain:      "test.local",
-		EventRecordQPS:     pointer.Int32(0),
-		FailSwapOn:         pointer.Bool(false),
-		RotateCertificates: true,
-		ServerTLSBootstrap: true,
-		TLSMinVersion:      "VersionTLS12",
+		ClusterDNS:                      []string{"99.99.99.10"},
+		ClusterDomain:                   "test.local",
+		EventRecordQPS:                  pointer.Int32(0),
+		FailSwapOn:                      pointer.Bool(false),
+		RotateCertificates:              true,
+		ServerTLSBootstrap:              true,
+		ShutdownGracePeriod:             metav1.Duration{Duration: 30 * time.Second},
+		ShutdownGracePeriodCriticalPods: metav1.Duration{Duration: 10 * time.Second},
+		TLSMinVersion:                   "VersionTLS12",
 		TLSCipherSuites: []string{
 			"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256",
 			"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384",
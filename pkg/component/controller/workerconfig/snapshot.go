@@ -19,6 +19,7 @@ package workerconfig
 import (
 	"github.com/k0sproject/k0s/internal/pkg/net"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	workerconfig "github.com/k0sproject/k0s/pkg/component/worker/config"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -33,6 +34,9 @@ type snapshot struct {
 	// The snapshot of the cluster configuration.
 	*configSnapshot
 
+	// The cluster CA, as seen by worker nodes.
+	certificateAuthorities workerconfig.CertificateAuthorities
+
 	// A simple counter that can be incremented every time a reconciliation
 	// shall be enforced, even if the rest of the snapshot still matches the
 	// last reconciled state.
@@ -47,6 +51,9 @@ type configSnapshot struct {
 	defaultImagePullPolicy corev1.PullPolicy
 	profiles               v1beta1.WorkerProfiles
 	featureGates           v1beta1.FeatureGates
+	nodeLocalDNSIP         string
+	iptables               workerconfig.IPTables
+	externalCloudProvider  bool
 }
 
 func (s *snapshot) DeepCopy() *snapshot {
@@ -87,11 +94,29 @@ func takeConfigSnapshot(spec *v1beta1.ClusterSpec) configSnapshot {
 		konnectivityAgentPort = uint16(v1beta1.DefaultKonnectivitySpec().AgentPort)
 	}
 
+	var nodeLocalDNSIP string
+	if nodeLocalDNS := spec.Network.NodeLocalDNSCache; nodeLocalDNS.IsEnabled() {
+		nodeLocalDNSIP = nodeLocalDNS.LocalIP
+	}
+
+	iptables := workerconfig.IPTables{Mode: spec.Network.IPTablesMode}
+	if kubeProxy := spec.Network.KubeProxy; kubeProxy != nil && !kubeProxy.Disabled {
+		iptables.KubeProxyMode = kubeProxy.Mode
+	}
+
+	var externalCloudProvider bool
+	if spec.Extensions != nil {
+		externalCloudProvider = spec.Extensions.CloudProvider.IsEnabled()
+	}
+
 	return configSnapshot{
 		spec.Network.NodeLocalLoadBalancing.DeepCopy(),
 		konnectivityAgentPort,
 		corev1.PullPolicy(spec.Images.DefaultPullPolicy),
 		spec.WorkerProfiles.DeepCopy(),
 		spec.FeatureGates.DeepCopy(),
+		nodeLocalDNSIP,
+		iptables,
+		externalCloudProvider,
 	}
 }
@@ -0,0 +1,192 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskmonitor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/component/prober"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultMinFreePercent is the default percentage of free space and free
+// inodes below which a file system is considered to be running out of space.
+const DefaultMinFreePercent = 10
+
+// checkInterval is the interval in which the file systems are checked.
+const checkInterval = 1 * time.Minute
+
+// Path is a directory whose file system is monitored.
+type Path struct {
+	// Name identifies the path in logs, events and metrics, e.g. "etcd".
+	Name string
+	Path string
+	// Reclaim is called to free up space once the file system is running out
+	// of space. Optional.
+	Reclaim func(context.Context) error
+}
+
+// DiskMonitor monitors the free space and inodes of the file systems of the
+// given paths. It exposes them as metrics, and emits events once a file
+// system is running out of space and once it has recovered.
+type DiskMonitor struct {
+	Paths []Path
+	// The percentage of free space and free inodes below which a file system
+	// is considered to be running out of space.
+	MinFreePercent uint
+	// Whether to call the paths' Reclaim functions when running out of space.
+	Reclaim bool
+
+	*prober.EventEmitter
+
+	log     logrus.FieldLogger
+	usageOf func(string) (usage, error)
+	mu      sync.RWMutex
+	low     map[string]bool
+	stop    func()
+}
+
+var _ manager.Component = (*DiskMonitor)(nil)
+
+// usage describes the space and inodes of a file system.
+type usage struct {
+	freeBytes, totalBytes   uint64
+	freeInodes, totalInodes uint64
+}
+
+// freePercent returns the lower one of the percentages of free space and
+// free inodes. File systems that don't report inodes are judged by their
+// free space only.
+func (u usage) freePercent() float64 {
+	var free float64 = 100
+	if u.totalBytes > 0 {
+		free = 100 * float64(u.freeBytes) / float64(u.totalBytes)
+	}
+	if u.totalInodes > 0 {
+		if inodes := 100 * float64(u.freeInodes) / float64(u.totalInodes); inodes < free {
+			free = inodes
+		}
+	}
+	return free
+}
+
+func (m *DiskMonitor) Init(context.Context) error {
+	m.log = logrus.WithField("component", "disk-monitor")
+	if m.EventEmitter == nil {
+		m.EventEmitter = prober.NewEventEmitter()
+	}
+	if m.usageOf == nil {
+		m.usageOf = usageOf
+	}
+	if m.MinFreePercent == 0 {
+		m.MinFreePercent = DefaultMinFreePercent
+	}
+	m.low = make(map[string]bool, len(m.Paths))
+	return nil
+}
+
+func (m *DiskMonitor) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait.UntilWithContext(ctx, m.check, checkInterval)
+	}()
+
+	m.stop = func() { cancel(); <-done }
+	return nil
+}
+
+func (m *DiskMonitor) Stop() error {
+	if m.stop != nil {
+		m.stop()
+	}
+	return nil
+}
+
+func (m *DiskMonitor) check(ctx context.Context) {
+	for _, path := range m.Paths {
+		log := m.log.WithField("path", path.Path)
+
+		u, err := m.usageOf(path.Path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			log.WithError(err).Warn("Failed to check disk usage")
+			continue
+		}
+		updateMetrics(path, u)
+
+		low := u.freePercent() < float64(m.MinFreePercent)
+		switch {
+		case low && !m.low[path.Name]:
+			log.Warnf("Running out of disk space for %s: %s", path.Name, u)
+			m.EmitWithPayload(fmt.Sprintf("Running out of disk space for %s", path.Name), path.Path)
+			if m.Reclaim && path.Reclaim != nil {
+				m.reclaim(ctx, log, path)
+			}
+		case !low && m.low[path.Name]:
+			log.Infof("Disk space for %s recovered: %s", path.Name, u)
+			m.EmitWithPayload(fmt.Sprintf("Disk space for %s recovered", path.Name), path.Path)
+		}
+		m.mu.Lock()
+		m.low[path.Name] = low
+		m.mu.Unlock()
+	}
+}
+
+// LowDiskSpace returns the sorted names of the paths whose file systems are
+// currently running out of space.
+func (m *DiskMonitor) LowDiskSpace() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var names []string
+	for name, low := range m.low {
+		if low {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *DiskMonitor) reclaim(ctx context.Context, log logrus.FieldLogger, path Path) {
+	log.Infof("Reclaiming disk space for %s", path.Name)
+	if err := path.Reclaim(ctx); err != nil {
+		log.WithError(err).Errorf("Failed to reclaim disk space for %s", path.Name)
+		m.EmitWithPayload(fmt.Sprintf("Failed to reclaim disk space for %s", path.Name), err.Error())
+		return
+	}
+
+	m.EmitWithPayload(fmt.Sprintf("Reclaimed disk space for %s", path.Name), path.Path)
+}
+
+func (u usage) String() string {
+	return fmt.Sprintf("%d of %d bytes and %d of %d inodes free", u.freeBytes, u.totalBytes, u.freeInodes, u.totalInodes)
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskmonitor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskMonitor_Check(t *testing.T) {
+	usages := map[string]usage{
+		"/data": {freeBytes: 50, totalBytes: 100, freeInodes: 50, totalInodes: 100},
+		"/etcd": {freeBytes: 50, totalBytes: 100, freeInodes: 50, totalInodes: 100},
+	}
+	var reclaimed int
+	var reclaimErr error
+
+	underTest := &DiskMonitor{
+		Paths: []Path{
+			{Name: "data", Path: "/data"},
+			{Name: "etcd", Path: "/etcd", Reclaim: func(context.Context) error { reclaimed++; return reclaimErr }},
+			{Name: "missing", Path: "/missing"},
+		},
+		Reclaim: true,
+		usageOf: func(path string) (usage, error) {
+			if u, ok := usages[path]; ok {
+				return u, nil
+			}
+			return usage{}, &os.PathError{Op: "statfs", Path: path, Err: os.ErrNotExist}
+		},
+	}
+	require.NoError(t, underTest.Init(context.TODO()))
+	assert.Equal(t, uint(DefaultMinFreePercent), underTest.MinFreePercent)
+
+	underTest.check(context.TODO())
+	assert.Empty(t, underTest.Events())
+	assert.Zero(t, reclaimed)
+	assert.Empty(t, underTest.LowDiskSpace())
+
+	// Running out of inodes.
+	usages["/etcd"] = usage{freeBytes: 50, totalBytes: 100, freeInodes: 5, totalInodes: 100}
+	underTest.check(context.TODO())
+	assert.Equal(t, 1, reclaimed)
+	assert.Equal(t, "Running out of disk space for etcd", (<-underTest.Events()).Message)
+	assert.Equal(t, "Reclaimed disk space for etcd", (<-underTest.Events()).Message)
+	assert.Empty(t, underTest.Events())
+
+	// Reclaiming happens only once.
+	underTest.check(context.TODO())
+	assert.Equal(t, 1, reclaimed)
+	assert.Empty(t, underTest.Events())
+
+	usages["/etcd"] = usage{freeBytes: 50, totalBytes: 100, freeInodes: 50, totalInodes: 100}
+	underTest.check(context.TODO())
+	assert.Equal(t, "Disk space for etcd recovered", (<-underTest.Events()).Message)
+
+	// Running out of space, failing to reclaim it.
+	reclaimErr = errors.New("boom")
+	usages["/etcd"] = usage{freeBytes: 5, totalBytes: 100}
+	usages["/data"] = usage{freeBytes: 5, totalBytes: 100}
+	underTest.check(context.TODO())
+	assert.Equal(t, 2, reclaimed)
+	assert.Equal(t, "Running out of disk space for data", (<-underTest.Events()).Message)
+	assert.Equal(t, "Running out of disk space for etcd", (<-underTest.Events()).Message)
+	event := <-underTest.Events()
+	assert.Equal(t, "Failed to reclaim disk space for etcd", event.Message)
+	assert.Equal(t, "boom", event.Payload)
+	assert.Equal(t, []string{"data", "etcd"}, underTest.LowDiskSpace())
+}
+
+func TestUsage_FreePercent(t *testing.T) {
+	assert.Equal(t, float64(100), usage{}.freePercent())
+	assert.Equal(t, float64(25), usage{freeBytes: 25, totalBytes: 100}.freePercent())
+	assert.Equal(t, float64(10), usage{freeBytes: 25, totalBytes: 100, freeInodes: 1, totalInodes: 10}.freePercent())
+}
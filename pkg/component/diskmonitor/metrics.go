@@ -0,0 +1,59 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskmonitor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	freeBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "k0s",
+		Subsystem: "disk",
+		Name:      "free_bytes",
+		Help:      "Free space of the file system of a path used by k0s.",
+	}, []string{"name", "path"})
+	totalBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "k0s",
+		Subsystem: "disk",
+		Name:      "total_bytes",
+		Help:      "Total space of the file system of a path used by k0s.",
+	}, []string{"name", "path"})
+	freeInodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "k0s",
+		Subsystem: "disk",
+		Name:      "free_inodes",
+		Help:      "Free inodes of the file system of a path used by k0s.",
+	}, []string{"name", "path"})
+	totalInodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "k0s",
+		Subsystem: "disk",
+		Name:      "total_inodes",
+		Help:      "Total inodes of the file system of a path used by k0s.",
+	}, []string{"name", "path"})
+)
+
+func init() {
+	prometheus.MustRegister(freeBytes, totalBytes, freeInodes, totalInodes)
+}
+
+func updateMetrics(path Path, u usage) {
+	freeBytes.WithLabelValues(path.Name, path.Path).Set(float64(u.freeBytes))
+	totalBytes.WithLabelValues(path.Name, path.Path).Set(float64(u.totalBytes))
+	freeInodes.WithLabelValues(path.Name, path.Path).Set(float64(u.freeInodes))
+	totalInodes.WithLabelValues(path.Name, path.Path).Set(float64(u.totalInodes))
+}
@@ -0,0 +1,40 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskmonitor
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+func usageOf(path string) (usage, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return usage{}, &os.PathError{Op: "statfs", Path: path, Err: err}
+	}
+
+	return usage{
+		freeBytes:   st.Bavail * uint64(st.Bsize),
+		totalBytes:  st.Blocks * uint64(st.Bsize),
+		freeInodes:  st.Ffree,
+		totalInodes: st.Files,
+	}, nil
+}
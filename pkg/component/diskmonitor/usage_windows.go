@@ -0,0 +1,41 @@
+//go:build windows
+// +build windows
+
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diskmonitor
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+func usageOf(path string) (usage, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return usage{}, err
+	}
+
+	var free, total uint64
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &free, &total, nil); err != nil {
+		return usage{}, &os.PathError{Op: "GetDiskFreeSpaceEx", Path: path, Err: err}
+	}
+
+	// Windows doesn't have a notion of inodes.
+	return usage{freeBytes: free, totalBytes: total}, nil
+}
@@ -57,6 +57,16 @@ type Ready interface {
 	Ready() error
 }
 
+// Drainer is implemented by components that perform operations in the
+// background which shouldn't be interrupted, e.g. applying manifests.
+type Drainer interface {
+	// Drain stops the component from starting new operations and waits for
+	// in-flight ones to finish, or until the given context is done. The
+	// manager calls Drain right before Stop, while the components that this
+	// component depends on are still running.
+	Drain(context.Context) error
+}
+
 // Reconciler defines the component interface that is reconciled based
 // on changes on the global config CR object changes.
 //
@@ -26,7 +26,10 @@ import (
 
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/performance"
+	"github.com/k0sproject/k0s/pkg/tracing"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -40,8 +43,17 @@ type Manager struct {
 	Components        []Component
 	prober            prober
 	ReadyWaitDuration time.Duration
+	// The time each component may take to initialize or to start. Disabled
+	// if zero.
+	Timeout time.Duration
+	// What to do if a component exceeds the timeout.
+	TimeoutPolicy TimeoutPolicy
+	// The time each component may take to drain and to stop. Disabled if
+	// zero. Components exceeding it are abandoned.
+	StopTimeout time.Duration
 
 	started              *list.List
+	dependencies         map[Component][]Component
 	lastReconciledConfig *v1beta1.ClusterConfig
 }
 
@@ -50,7 +62,9 @@ func New(prober prober) *Manager {
 	return &Manager{
 		Components:        []Component{},
 		ReadyWaitDuration: 2 * time.Minute,
+		TimeoutPolicy:     TimeoutPolicyContinue,
 		started:           list.New(),
+		dependencies:      make(map[Component][]Component),
 		prober:            prober,
 	}
 }
@@ -65,6 +79,14 @@ func (m *Manager) Add(ctx context.Context, component Component) {
 	}
 }
 
+// DependsOn declares that the given component depends on others. When
+// stopping, the manager stops a component only after all components that
+// depend on it have been stopped, regardless of the order in which they were
+// added.
+func (m *Manager) DependsOn(component Component, dependencies ...Component) {
+	m.dependencies[component] = append(m.dependencies[component], dependencies...)
+}
+
 // Init initializes all managed components
 func (m *Manager) Init(ctx context.Context) error {
 	g, _ := errgroup.WithContext(ctx)
@@ -75,7 +97,12 @@ func (m *Manager) Init(ctx context.Context) error {
 		c := comp
 		// init this async
 		g.Go(func() error {
-			return c.Init(ctx)
+			ctx, span := startSpan(ctx, "init", compName)
+			start := time.Now()
+			err := m.runWithTimeout(ctx, "initialize", compName, c, c.Init)
+			tracing.End(span, err)
+			initDuration.WithLabelValues(compName).Set(time.Since(start).Seconds())
+			return err
 		})
 	}
 	err := g.Wait()
@@ -90,32 +117,40 @@ func (m *Manager) Start(ctx context.Context) error {
 		compName := reflect.TypeOf(comp).Elem().Name()
 		perfTimer.Checkpoint(fmt.Sprintf("running-%s", compName))
 		logrus.Infof("starting %v", compName)
-		if err := comp.Start(ctx); err != nil {
+		spanCtx, span := startSpan(ctx, "start", compName)
+		start := time.Now()
+		if err := m.runWithTimeout(spanCtx, "start", compName, comp, comp.Start); err != nil {
+			tracing.End(span, err)
+			startFailures.WithLabelValues(compName).Inc()
 			_ = m.Stop()
 			return err
 		}
 		m.started.PushFront(comp)
 		perfTimer.Checkpoint(fmt.Sprintf("running-%s-done", compName))
-		if err := waitForReady(ctx, comp, compName, m.ReadyWaitDuration); err != nil {
+		if err := waitForReady(spanCtx, comp, compName, m.ReadyWaitDuration); err != nil {
+			tracing.End(span, err)
+			startFailures.WithLabelValues(compName).Inc()
 			_ = m.Stop()
 			return err
 		}
+		span.End()
+		startDuration.WithLabelValues(compName).Set(time.Since(start).Seconds())
 		m.prober.Register(compName, comp)
 	}
 	perfTimer.Output()
 	return nil
 }
 
-// Stop stops all managed components
+// Stop stops all managed components in reverse order, unless they have
+// dependents that have to be stopped first. Components implementing [Drainer]
+// are drained before they are stopped.
 func (m *Manager) Stop() error {
 	var ret error
-	var next *list.Element
 
-	for e := m.started.Front(); e != nil; e = next {
-		component := e.Value.(Component)
+	for component := m.nextToStop(); component != nil; component = m.nextToStop() {
 		name := reflect.TypeOf(component).Elem().Name()
 
-		if err := component.Stop(); err != nil {
+		if err := m.stopWithTimeout(name, component); err != nil {
 			logrus.Errorf("failed to stop component %s: %s", name, err.Error())
 			if ret == nil {
 				ret = fmt.Errorf("failed to stop components")
@@ -123,13 +158,41 @@ func (m *Manager) Stop() error {
 		} else {
 			logrus.Infof("stopped component %s", name)
 		}
-
-		next = e.Next()
-		m.started.Remove(e)
 	}
 	return ret
 }
 
+// nextToStop removes the most recently started component that no other
+// started component depends on from the started components and returns it.
+// If there's none, due to a dependency cycle, it falls back to the most
+// recently started component. Returns nil if all components are stopped.
+func (m *Manager) nextToStop() Component {
+	front := m.started.Front()
+	if front == nil {
+		return nil
+	}
+
+	for e := front; e != nil; e = e.Next() {
+		if !m.hasStartedDependents(e.Value.(Component)) {
+			return m.started.Remove(e).(Component)
+		}
+	}
+
+	logrus.Warn("Components depend on each other, stopping them in reverse order")
+	return m.started.Remove(front).(Component)
+}
+
+func (m *Manager) hasStartedDependents(component Component) bool {
+	for e := m.started.Front(); e != nil; e = e.Next() {
+		for _, dependency := range m.dependencies[e.Value.(Component)] {
+			if dependency == component {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // ReconcileError is just a wrapper for possible many errors
 type ReconcileError struct {
 	Errors []error
@@ -144,7 +207,29 @@ func (r ReconcileError) Error() string {
 	return strings.Join(messages, "\n")
 }
 
-// Reconcile reconciles all managed components
+func (r ReconcileError) Unwrap() []error {
+	return r.Errors
+}
+
+// ComponentError is the error of a single component that failed to reconcile.
+type ComponentError struct {
+	// The name of the component's type.
+	Component string
+	Err       error
+}
+
+func (e ComponentError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Component, e.Err)
+}
+
+func (e ComponentError) Unwrap() error {
+	return e.Err
+}
+
+// Reconcile reconciles all managed components. The config is handed to each
+// component synchronously, one after another, so that no config update is
+// ever skipped for slow components. Components that are added afterwards are
+// reconciled against the last config when they're added.
 func (m *Manager) Reconcile(ctx context.Context, cfg *v1beta1.ClusterConfig) error {
 	errors := make([]error, 0)
 	var ret error
@@ -172,13 +257,23 @@ func (m *Manager) reconcileComponent(ctx context.Context, comp Component, cfg *v
 		return nil
 	}
 	logrus.Infof("starting to reconcile %s", compName)
-	if err := clusterComponent.Reconcile(ctx, cfg); err != nil {
+	ctx, span := startSpan(ctx, "reconcile", reflect.TypeOf(comp).Elem().Name())
+	start := time.Now()
+	err := clusterComponent.Reconcile(ctx, cfg)
+	tracing.End(span, err)
+	observeReconcile(reflect.TypeOf(comp).Elem().Name(), start, err)
+	if err != nil {
 		logrus.Errorf("failed to reconcile component %s: %s", compName, err.Error())
-		return err
+		return ComponentError{Component: reflect.TypeOf(comp).Elem().Name(), Err: err}
 	}
 	return nil
 }
 
+// startSpan starts a span for the given operation on the given component.
+func startSpan(ctx context.Context, operation, compName string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, operation+" "+compName, trace.WithAttributes(attribute.String("component", compName)))
+}
+
 func isReconcileComponent(comp Component) bool {
 	_, ok := comp.(Reconciler)
 	return ok
@@ -18,12 +18,20 @@ package manager
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	proberPackage "github.com/k0sproject/k0s/pkg/component/prober"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 type Fake struct {
@@ -165,3 +173,237 @@ func TestManagerHealthyFail(t *testing.T) {
 	require.True(t, f2.StopCalled)
 	require.False(t, f3.StopCalled)
 }
+
+type FakeReconciler struct {
+	Fake
+}
+
+func (f *FakeReconciler) Reconcile(context.Context, *v1beta1.ClusterConfig) error {
+	return f.ReconcileErr
+}
+
+func TestManagerReconcileMetrics(t *testing.T) {
+	m := New(proberPackage.NopProber{})
+	ctx := context.Background()
+
+	f := &FakeReconciler{}
+	m.Add(ctx, f)
+
+	reconcilesBefore := testutil.ToFloat64(reconciles.WithLabelValues("FakeReconciler"))
+	errorsBefore := testutil.ToFloat64(reconcileErrors.WithLabelValues("FakeReconciler"))
+
+	require.NoError(t, m.Reconcile(ctx, &v1beta1.ClusterConfig{}))
+	f.ReconcileErr = errors.New("failed")
+	err := m.Reconcile(ctx, &v1beta1.ClusterConfig{})
+	require.Error(t, err)
+
+	var componentErr ComponentError
+	if assert.ErrorAs(t, err, &componentErr) {
+		assert.Equal(t, "FakeReconciler", componentErr.Component)
+	}
+	assert.Equal(t, reconcilesBefore+2, testutil.ToFloat64(reconciles.WithLabelValues("FakeReconciler")))
+	assert.Equal(t, errorsBefore+1, testutil.ToFloat64(reconcileErrors.WithLabelValues("FakeReconciler")))
+}
+
+func TestManagerTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	m := New(proberPackage.NopProber{})
+	ctx := context.Background()
+
+	f := &FakeReconciler{Fake{ReconcileErr: errors.New("failed")}}
+	m.Add(ctx, f)
+
+	require.NoError(t, m.Init(ctx))
+	require.NoError(t, m.Start(ctx))
+	require.Error(t, m.Reconcile(ctx, &v1beta1.ClusterConfig{}))
+
+	spans := recorder.Ended()
+	if assert.Len(t, spans, 3) {
+		assert.Equal(t, "init FakeReconciler", spans[0].Name())
+		assert.Equal(t, "start FakeReconciler", spans[1].Name())
+		assert.Equal(t, "reconcile FakeReconciler", spans[2].Name())
+		assert.Equal(t, codes.Error, spans[2].Status().Code)
+	}
+}
+
+type SlowFake struct {
+	Fake
+	*proberPackage.EventEmitter
+	release chan struct{}
+}
+
+func (f *SlowFake) Start(ctx context.Context) error {
+	f.Emit("starting")
+	<-f.release
+	return f.Fake.Start(ctx)
+}
+
+func TestManagerTimeout(t *testing.T) {
+	t.Run("fail", func(t *testing.T) {
+		m := New(proberPackage.NopProber{})
+		m.Timeout = 10 * time.Millisecond
+		m.TimeoutPolicy = TimeoutPolicyFail
+		ctx := context.Background()
+
+		f1 := &Fake{}
+		m.Add(ctx, f1)
+		f2 := &SlowFake{EventEmitter: proberPackage.NewEventEmitter(), release: make(chan struct{})}
+		t.Cleanup(func() { close(f2.release) })
+		m.Add(ctx, f2)
+
+		timeoutsBefore := testutil.ToFloat64(timeouts.WithLabelValues("SlowFake", "start"))
+		err := m.Start(ctx)
+		assert.ErrorContains(t, err, "failed to start SlowFake within 10ms")
+		assert.Equal(t, timeoutsBefore+1, testutil.ToFloat64(timeouts.WithLabelValues("SlowFake", "start")))
+		assert.True(t, f1.StopCalled)
+
+		// The pending event is still there for the prober to collect.
+		assert.Len(t, f2.Events(), 1)
+	})
+
+	t.Run("continue", func(t *testing.T) {
+		m := New(proberPackage.NopProber{})
+		m.Timeout = 10 * time.Millisecond
+		ctx := context.Background()
+
+		f := &SlowFake{EventEmitter: proberPackage.NewEventEmitter(), release: make(chan struct{})}
+		m.Add(ctx, f)
+
+		time.AfterFunc(50*time.Millisecond, func() { close(f.release) })
+		require.NoError(t, m.Start(ctx))
+		assert.True(t, f.RunCalled)
+	})
+}
+
+// OrderedFake records the order in which it's drained and stopped.
+type OrderedFake struct {
+	Fake
+	name    string
+	stopped *[]string
+}
+
+func (f *OrderedFake) Stop() error {
+	*f.stopped = append(*f.stopped, f.name)
+	return f.Fake.Stop()
+}
+
+type DrainingFake struct {
+	OrderedFake
+	drainErr error
+}
+
+func (f *DrainingFake) Drain(ctx context.Context) error {
+	*f.stopped = append(*f.stopped, "drain "+f.name)
+	if f.drainErr != nil {
+		<-ctx.Done()
+		return f.drainErr
+	}
+	return nil
+}
+
+func TestManagerStopOrder(t *testing.T) {
+	var stopped []string
+	m := New(proberPackage.NopProber{})
+	ctx := context.Background()
+
+	storage := &OrderedFake{name: "storage", stopped: &stopped}
+	m.Add(ctx, storage)
+	applier := &DrainingFake{OrderedFake: OrderedFake{name: "applier", stopped: &stopped}}
+	m.Add(ctx, applier)
+	apiServer := &OrderedFake{name: "apiserver", stopped: &stopped}
+	m.Add(ctx, apiServer)
+	other := &OrderedFake{name: "other", stopped: &stopped}
+	m.Add(ctx, other)
+	m.DependsOn(applier, apiServer)
+	m.DependsOn(apiServer, storage)
+
+	require.NoError(t, m.Start(ctx))
+	require.NoError(t, m.Stop())
+
+	// The applier is drained and stopped before the API server, although it
+	// has been started before it.
+	assert.Equal(t, []string{"other", "drain applier", "applier", "apiserver", "storage"}, stopped)
+
+	// Stopping again is a no-op.
+	stopped = nil
+	require.NoError(t, m.Stop())
+	assert.Empty(t, stopped)
+}
+
+func TestManagerStopDependencyCycle(t *testing.T) {
+	var stopped []string
+	m := New(proberPackage.NopProber{})
+	ctx := context.Background()
+
+	f1 := &OrderedFake{name: "f1", stopped: &stopped}
+	m.Add(ctx, f1)
+	f2 := &OrderedFake{name: "f2", stopped: &stopped}
+	m.Add(ctx, f2)
+	m.DependsOn(f1, f2)
+	m.DependsOn(f2, f1)
+
+	require.NoError(t, m.Start(ctx))
+	require.NoError(t, m.Stop())
+	assert.Equal(t, []string{"f2", "f1"}, stopped)
+}
+
+type BlockingFake struct {
+	Fake
+	release chan struct{}
+}
+
+func (f *BlockingFake) Stop() error {
+	<-f.release
+	return f.Fake.Stop()
+}
+
+func TestManagerStopTimeout(t *testing.T) {
+	t.Run("drain", func(t *testing.T) {
+		var stopped []string
+		m := New(proberPackage.NopProber{})
+		m.StopTimeout = 10 * time.Millisecond
+		ctx := context.Background()
+
+		f := &DrainingFake{OrderedFake{name: "f", stopped: &stopped}, errors.New("still busy")}
+		m.Add(ctx, f)
+
+		timeoutsBefore := testutil.ToFloat64(timeouts.WithLabelValues("DrainingFake", "drain"))
+		require.NoError(t, m.Start(ctx))
+		require.NoError(t, m.Stop())
+		assert.Equal(t, []string{"drain f", "f"}, stopped)
+		assert.Equal(t, timeoutsBefore+1, testutil.ToFloat64(timeouts.WithLabelValues("DrainingFake", "drain")))
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		m := New(proberPackage.NopProber{})
+		m.StopTimeout = 10 * time.Millisecond
+		ctx := context.Background()
+
+		f1 := &Fake{}
+		m.Add(ctx, f1)
+		f2 := &BlockingFake{release: make(chan struct{})}
+		t.Cleanup(func() { close(f2.release) })
+		m.Add(ctx, f2)
+
+		timeoutsBefore := testutil.ToFloat64(timeouts.WithLabelValues("BlockingFake", "stop"))
+		require.NoError(t, m.Start(ctx))
+		assert.ErrorContains(t, m.Stop(), "failed to stop components")
+		assert.Equal(t, timeoutsBefore+1, testutil.ToFloat64(timeouts.WithLabelValues("BlockingFake", "stop")))
+
+		// The remaining components are stopped nonetheless.
+		assert.True(t, f1.StopCalled)
+	})
+}
+
+func TestParseTimeoutPolicy(t *testing.T) {
+	policy, err := ParseTimeoutPolicy("fail")
+	assert.NoError(t, err)
+	assert.Equal(t, TimeoutPolicyFail, policy)
+
+	_, err = ParseTimeoutPolicy("abort")
+	assert.ErrorContains(t, err, `unknown timeout policy "abort"`)
+}
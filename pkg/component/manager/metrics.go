@@ -0,0 +1,88 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const metricsNamespace, metricsSubsystem = "k0s", "component"
+
+// The metrics of all component managers. They're labeled with the name of the
+// component, and exposed via the /metrics endpoint of the status socket.
+var (
+	initDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace, Subsystem: metricsSubsystem,
+		Name: "init_duration_seconds",
+		Help: "Time it took to initialize the component.",
+	}, []string{"component"})
+	startDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace, Subsystem: metricsSubsystem,
+		Name: "start_duration_seconds",
+		Help: "Time it took to start the component, including waiting for it to become ready.",
+	}, []string{"component"})
+	startFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace, Subsystem: metricsSubsystem,
+		Name: "start_failures_total",
+		Help: "Total number of failed component starts.",
+	}, []string{"component"})
+	timeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace, Subsystem: metricsSubsystem,
+		Name: "timeouts_total",
+		Help: "Total number of component initializations, starts, drains and stops that exceeded the timeout.",
+	}, []string{"component", "operation"})
+	reconciles = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace, Subsystem: metricsSubsystem,
+		Name: "reconciles_total",
+		Help: "Total number of reconciliations of the component with the cluster config.",
+	}, []string{"component"})
+	reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace, Subsystem: metricsSubsystem,
+		Name: "reconcile_errors_total",
+		Help: "Total number of failed reconciliations of the component with the cluster config.",
+	}, []string{"component"})
+	reconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace, Subsystem: metricsSubsystem,
+		Name:    "reconcile_duration_seconds",
+		Help:    "Duration of reconciliations of the component with the cluster config.",
+		Buckets: prometheus.ExponentialBuckets(0.01, 2, 12),
+	}, []string{"component"})
+)
+
+func init() {
+	prometheus.MustRegister(
+		initDuration,
+		startDuration,
+		startFailures,
+		timeouts,
+		reconciles,
+		reconcileErrors,
+		reconcileDuration,
+	)
+}
+
+// observeReconcile records a reconciliation of the given component that
+// started at the given time.
+func observeReconcile(component string, start time.Time, err error) {
+	reconciles.WithLabelValues(component).Inc()
+	reconcileDuration.WithLabelValues(component).Observe(time.Since(start).Seconds())
+	if err != nil {
+		reconcileErrors.WithLabelValues(component).Inc()
+	}
+}
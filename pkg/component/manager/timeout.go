@@ -0,0 +1,169 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+
+	proberPackage "github.com/k0sproject/k0s/pkg/component/prober"
+	"github.com/sirupsen/logrus"
+)
+
+// TimeoutPolicy decides what happens if a component exceeds its timeout.
+type TimeoutPolicy string
+
+const (
+	// TimeoutPolicyContinue keeps waiting for the component.
+	TimeoutPolicyContinue TimeoutPolicy = "continue"
+	// TimeoutPolicyFail fails the startup.
+	TimeoutPolicyFail TimeoutPolicy = "fail"
+)
+
+// ParseTimeoutPolicy parses the given string into a timeout policy.
+func ParseTimeoutPolicy(policy string) (TimeoutPolicy, error) {
+	switch p := TimeoutPolicy(policy); p {
+	case TimeoutPolicyContinue, TimeoutPolicyFail:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown timeout policy %q, expected %q or %q", policy, TimeoutPolicyContinue, TimeoutPolicyFail)
+	}
+}
+
+// runWithTimeout runs the given operation of a component. If it doesn't return
+// within the manager's timeout, the component's pending events and the stacks
+// of all goroutines are logged, and the timeout policy is applied.
+func (m *Manager) runWithTimeout(ctx context.Context, operation, compName string, comp Component, run func(context.Context) error) error {
+	if m.Timeout <= 0 {
+		return run(ctx)
+	}
+
+	// The context isn't canceled after the operation returns, as components
+	// may hold on to it.
+	result := make(chan error, 1)
+	go func() { result <- run(ctx) }()
+
+	timer := time.NewTimer(m.Timeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+	}
+
+	timeouts.WithLabelValues(compName, operation).Inc()
+	log := logrus.WithField("component", compName)
+	log.Errorf("Failed to %s component within %s", operation, m.Timeout)
+	logDiagnostics(log, comp)
+
+	if m.TimeoutPolicy == TimeoutPolicyFail {
+		return fmt.Errorf("failed to %s %s within %s", operation, compName, m.Timeout)
+	}
+
+	log.Warn("Still waiting for component")
+	return <-result
+}
+
+// stopWithTimeout drains the component, if it's a [Drainer], and stops it,
+// each within the manager's stop timeout. If draining exceeds the timeout, the
+// component is stopped nonetheless. If stopping exceeds it, the component's
+// pending events and the stacks of all goroutines are logged, and the
+// component is abandoned.
+func (m *Manager) stopWithTimeout(compName string, comp Component) error {
+	log := logrus.WithField("component", compName)
+
+	if drainer, ok := comp.(Drainer); ok {
+		ctx := context.Background()
+		if m.StopTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, m.StopTimeout)
+			defer cancel()
+		}
+		if err := drainer.Drain(ctx); err != nil {
+			if ctx.Err() != nil {
+				timeouts.WithLabelValues(compName, "drain").Inc()
+			}
+			log.WithError(err).Warn("Failed to drain component, stopping it nonetheless")
+		}
+	}
+
+	if m.StopTimeout <= 0 {
+		return comp.Stop()
+	}
+
+	result := make(chan error, 1)
+	go func() { result <- comp.Stop() }()
+
+	timer := time.NewTimer(m.StopTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-result:
+		return err
+	case <-timer.C:
+	}
+
+	timeouts.WithLabelValues(compName, "stop").Inc()
+	log.Errorf("Failed to stop component within %s", m.StopTimeout)
+	logDiagnostics(log, comp)
+	return fmt.Errorf("failed to stop %s within %s", compName, m.StopTimeout)
+}
+
+// logDiagnostics logs the events that the component emitted but that haven't
+// been collected yet, and the stacks of all goroutines.
+func logDiagnostics(log logrus.FieldLogger, comp Component) {
+	if eventer, ok := comp.(proberPackage.Eventer); ok {
+		for _, event := range drainEvents(eventer.Events()) {
+			log.WithField("at", event.At).WithField("payload", event.Payload).Warnf("Event: %s", event.Message)
+		}
+	}
+
+	log.Warnf("Goroutine stacks:\n%s", goroutineStacks())
+}
+
+// drainEvents returns the events that are pending in the given channel. The
+// events are put back afterwards, so that they can still be collected by the
+// prober.
+func drainEvents(events chan proberPackage.Event) []proberPackage.Event {
+	var drained []proberPackage.Event
+	for {
+		select {
+		case event := <-events:
+			drained = append(drained, event)
+		default:
+			for _, event := range drained {
+				select {
+				case events <- event:
+				default:
+				}
+			}
+			return drained
+		}
+	}
+}
+
+func goroutineStacks() []byte {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) || len(buf) >= 64<<20 {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -24,14 +24,17 @@ import (
 	"sync"
 	"time"
 
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
 	"github.com/sirupsen/logrus"
 )
 
 // Healthz represents a component that can be checked for its health.
 type Healthz interface {
 	// Healthy performs a periodical health check and indicates that a component is
-	// healthy and performs well
-	Healthy() error
+	// healthy and performs well. The check is considered failed once the given
+	// context is done.
+	Healthy(ctx context.Context) error
 }
 
 // Prober performs health probes on registred components
@@ -39,6 +42,7 @@ type Prober struct {
 	sync.RWMutex
 	l                    *logrus.Entry
 	interval             time.Duration
+	timeout              time.Duration
 	withHealthComponents map[string]Healthz
 	withEventComponents  map[string]Eventer
 
@@ -60,6 +64,7 @@ func New() *Prober {
 	return &Prober{
 		l:                    logrus.WithFields(logrus.Fields{"component": "prober"}),
 		interval:             10 * time.Second,
+		timeout:              5 * time.Second,
 		withHealthComponents: make(map[string]Healthz),
 		withEventComponents:  make(map[string]Eventer),
 		eventsTrackLength:    3,
@@ -74,6 +79,21 @@ func New() *Prober {
 // DefaultProber default global instance
 var DefaultProber = New()
 
+// Configure applies the given settings to the prober. It needs to be called
+// before any components are registered.
+func (p *Prober) Configure(spec *v1beta1.ProberSpec) {
+	if spec == nil {
+		return
+	}
+
+	p.Lock()
+	defer p.Unlock()
+	p.interval = spec.Interval.Duration
+	p.timeout = spec.Timeout.Duration
+	p.probesTrackLength = spec.ProbesTrackLength
+	p.eventsTrackLength = spec.EventsTrackLength
+}
+
 // State gives read-only copy of current state
 func (p *Prober) State(maxCount int) State {
 	p.RLock()
@@ -157,7 +177,20 @@ func (p *Prober) healthCheckLoop(ctx context.Context) {
 	}
 }
 func (p *Prober) checkComponentsHealth(ctx context.Context, at time.Time) {
+	p.RLock()
+	components := make(map[string]Healthz, len(p.withHealthComponents))
 	for name, component := range p.withHealthComponents {
+		components[name] = component
+	}
+	p.RUnlock()
+
+	for name, component := range components {
+		// Don't hold the lock while probing, so that the state can be
+		// inspected in the meantime.
+		probeCtx, cancel := context.WithTimeout(ctx, p.timeout)
+		err := component.Healthy(probeCtx)
+		cancel()
+
 		p.Lock()
 		if _, ok := p.healthCheckState[name]; !ok {
 			p.healthCheckState[name] = ring.New(p.probesTrackLength)
@@ -166,7 +199,7 @@ func (p *Prober) checkComponentsHealth(ctx context.Context, at time.Time) {
 		p.healthCheckState[name].Value = ProbeResult{
 			Component: name,
 			At:        at,
-			Error:     component.Healthy(),
+			Error:     err,
 		}
 		p.healthCheckState[name] = p.healthCheckState[name].Next()
 		p.Unlock()
@@ -201,13 +234,17 @@ func (p *Prober) Register(name string, component any) {
 	withHealth, ok := component.(Healthz)
 	if ok {
 		l.Debug("component implements Healthz interface, observing")
+		p.Lock()
 		p.withHealthComponents[name] = withHealth
+		p.Unlock()
 	}
 
 	withEvents, ok := component.(Eventer)
 	if ok {
 		l.Debug("component implements Eventer interface, subscribing")
+		p.Lock()
 		p.withEventComponents[name] = withEvents
+		p.Unlock()
 		p.spawnEventCollector(name, withEvents)
 	}
 
@@ -24,8 +24,11 @@ import (
 	"testing"
 	"time"
 
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
 	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestHealthChecks(t *testing.T) {
@@ -93,6 +96,17 @@ func TestHealthChecks(t *testing.T) {
 		assert.Len(t, st.HealthProbes["test3"], 1, "should have 1 result for test2 component")
 
 	})
+	t.Run("probes_time_out", func(t *testing.T) {
+		prober := testProber(1)
+		prober.timeout = 1 * time.Millisecond
+
+		prober.Register("test", blockingComponent{})
+		prober.Run(context.Background())
+		st := prober.State(maxEvents)
+		if assert.Len(t, st.HealthProbes["test"], 1) {
+			assert.ErrorIs(t, st.HealthProbes["test"][0].Error, context.DeadlineExceeded)
+		}
+	})
 }
 
 func testProber(iterations int) *Prober {
@@ -111,7 +125,7 @@ type mockComponent struct {
 	errors  []error
 }
 
-func (mc *mockComponent) Healthy() error {
+func (mc *mockComponent) Healthy(context.Context) error {
 	if mc.counter >= len(mc.errors) {
 		return nil
 	}
@@ -120,6 +134,28 @@ func (mc *mockComponent) Healthy() error {
 	return err
 }
 
+type blockingComponent struct{}
+
+func (blockingComponent) Healthy(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestConfigure(t *testing.T) {
+	prober := New()
+	prober.Configure(&v1beta1.ProberSpec{
+		Interval:          metav1.Duration{Duration: 1 * time.Minute},
+		Timeout:           metav1.Duration{Duration: 30 * time.Second},
+		ProbesTrackLength: 5,
+		EventsTrackLength: 7,
+	})
+
+	assert.Equal(t, 1*time.Minute, prober.interval)
+	assert.Equal(t, 30*time.Second, prober.timeout)
+	assert.Equal(t, 5, prober.probesTrackLength)
+	assert.Equal(t, 7, prober.eventsTrackLength)
+}
+
 func TestMarshalling(t *testing.T) {
 	tests := []*ProbeResult{
 		{
@@ -23,9 +23,14 @@ import (
 	"net"
 	"net/http"
 
+	"github.com/k0sproject/k0s/internal/pkg/iptablesutils"
 	config "github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
 	"github.com/k0sproject/k0s/pkg/component/prober"
 	"github.com/k0sproject/k0s/pkg/constant"
+	"github.com/k0sproject/k0s/pkg/performance"
+
+	clientauthv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
 )
 
 type K0sStatus struct {
@@ -40,6 +45,8 @@ type K0sStatus struct {
 	SingleNode                  bool
 	Args                        []string
 	WorkerToAPIConnectionStatus ProbeStatus
+	LeaderElection              *leaderelector.Status    `json:",omitempty"`
+	IPTables                    *iptablesutils.Detection `json:",omitempty"`
 	ClusterConfig               *config.ClusterConfig
 	K0sVars                     constant.CfgVars
 }
@@ -69,12 +76,31 @@ func GetComponentStatus(socketPath string, maxCount int) (*prober.State, error)
 	return status, nil
 }
 
+// GetStartupProfile returns the checkpoints recorded during the startup of the
+// k0s controller listening on the status socket
+func GetStartupProfile(socketPath string) (*performance.Profile, error) {
+	profile := &performance.Profile{}
+	if err := doHTTPRequestViaUnixSocket(socketPath, "startup-profile", profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// GetAdminCredential returns short-lived admin credentials issued by the k0s
+// controller listening on the status socket
+func GetAdminCredential(socketPath string) (*clientauthv1.ExecCredentialStatus, error) {
+	credential := &clientauthv1.ExecCredentialStatus{}
+	if err := doHTTPRequestViaUnixSocket(socketPath, "credentials", credential); err != nil {
+		return nil, err
+	}
+	return credential, nil
+}
+
 func doHTTPRequestViaUnixSocket(socketPath string, path string, tgt interface{}) error {
 	httpc := http.Client{
 		Transport: &http.Transport{
 			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
-				var d net.Dialer
-				return d.DialContext(ctx, "unix", socketPath)
+				return dial(ctx, socketPath)
 			},
 		},
 	}
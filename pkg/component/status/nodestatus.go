@@ -0,0 +1,321 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	statusv1beta1 "github.com/k0sproject/k0s/pkg/apis/status/v1beta1"
+	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
+	"github.com/k0sproject/k0s/pkg/certificate"
+	apclient "github.com/k0sproject/k0s/pkg/client/clientset"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
+)
+
+// NodeStatusAnnotation is the annotation on Nodes and ControlNodes that holds
+// the JSON encoded NodeStatus of the k0s instance running on them.
+const NodeStatusAnnotation = "k0sproject.io/node-status"
+
+// nodeStatusPublishInterval is the interval in which the node status is
+// published.
+const nodeStatusPublishInterval = 1 * time.Minute
+
+// NodeStatus is the status of a k0s instance, as published on the object that
+// represents it in the cluster.
+type NodeStatus struct {
+	Version    string            `json:"version"`
+	Role       string            `json:"role"`
+	Components []ComponentHealth `json:"components,omitempty"`
+	// The version of the cluster CA bundle that the node trusts.
+	CABundleVersion string `json:"caBundleVersion,omitempty"`
+	// The time at which the status has been published.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// ComponentHealth is the result of the most recent health probe of a
+// component.
+type ComponentHealth struct {
+	Name    string `json:"name"`
+	Healthy bool   `json:"healthy"`
+	Message string `json:"message,omitempty"`
+}
+
+// ParseNodeStatus parses the node status from the given annotations. Returns
+// nil if there's no node status.
+func ParseNodeStatus(annotations map[string]string) (*NodeStatus, error) {
+	data, ok := annotations[NodeStatusAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var status NodeStatus
+	if err := json.Unmarshal([]byte(data), &status); err != nil {
+		return nil, fmt.Errorf("failed to parse %s annotation: %w", NodeStatusAnnotation, err)
+	}
+	return &status, nil
+}
+
+type nodeStatusPublisher interface {
+	PublishNodeStatus(ctx context.Context, status *NodeStatus) error
+}
+
+// runNodeStatusPublisher publishes the node status periodically until the
+// given context is done.
+func (s *Status) runNodeStatusPublisher(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := s.NodeStatusPublisher.PublishNodeStatus(ctx, s.nodeStatus(time.Now())); err != nil && ctx.Err() == nil {
+			s.L.WithError(err).Warn("Failed to publish node status")
+		}
+	}, nodeStatusPublishInterval)
+}
+
+// nodeStatus gathers the current status of this k0s instance.
+func (s *Status) nodeStatus(now time.Time) *NodeStatus {
+	info := &s.StatusInformation
+	status := &NodeStatus{
+		Version:        info.Version,
+		Role:           info.Role,
+		LastUpdateTime: metav1.NewTime(now),
+	}
+	if info.Role == "controller" && info.Workloads {
+		status.Role = "controller+worker"
+	}
+	if certRootDir := info.K0sVars.CertRootDir; certRootDir != "" {
+		if bundle, err := certificate.ReadCABundle(certRootDir); err == nil {
+			status.CABundleVersion = certificate.CABundleVersion(bundle)
+		}
+	}
+
+	if s.Prober == nil {
+		return status
+	}
+	for name, results := range s.Prober.State(math.MaxInt32).HealthProbes {
+		if len(results) == 0 {
+			continue
+		}
+		latest := results[0]
+		for _, result := range results[1:] {
+			if result.At.After(latest.At) {
+				latest = result
+			}
+		}
+		health := ComponentHealth{Name: name, Healthy: latest.Error == nil}
+		if latest.Error != nil {
+			health.Message = latest.Error.Error()
+		}
+		status.Components = append(status.Components, health)
+	}
+	sort.Slice(status.Components, func(i, j int) bool {
+		return status.Components[i].Name < status.Components[j].Name
+	})
+
+	return status
+}
+
+// ControlNodeStatusPublisher publishes the node status of a controller on its
+// ControlNode and in its ControllerStatus.
+type ControlNodeStatusPublisher struct {
+	KubeClientFactory kubeutil.ClientFactoryInterface
+
+	client        apclient.Interface
+	dynamicClient dynamic.Interface
+}
+
+var controllerStatusGVR = statusv1beta1.GroupVersion.WithResource("controllerstatuses")
+
+func (p *ControlNodeStatusPublisher) PublishNodeStatus(ctx context.Context, status *NodeStatus) error {
+	nodeName, err := apcomm.FindEffectiveHostname()
+	if err != nil {
+		return err
+	}
+	if p.client == nil {
+		if _, err := p.KubeClientFactory.GetClient(); err != nil {
+			return err
+		}
+		// The REST config is available once the client has been created.
+		if p.client, err = apclient.NewForConfig(p.KubeClientFactory.GetRESTConfig()); err != nil {
+			return err
+		}
+	}
+	if p.dynamicClient == nil {
+		if p.dynamicClient, err = p.KubeClientFactory.GetDynamicClient(); err != nil {
+			return err
+		}
+	}
+
+	controllerStatuses := p.dynamicClient.Resource(controllerStatusGVR).Namespace(statusv1beta1.ControllerStatusNamespace)
+	controllerStatusErr := updateControllerStatus(ctx, controllerStatuses, nodeName, status)
+	if controllerStatusErr != nil {
+		controllerStatusErr = fmt.Errorf("failed to update ControllerStatus: %w", controllerStatusErr)
+	}
+
+	return errors.Join(controllerStatusErr, p.annotateControlNode(ctx, nodeName, status))
+}
+
+func (p *ControlNodeStatusPublisher) annotateControlNode(ctx context.Context, nodeName string, status *NodeStatus) error {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	controlNodes := p.client.AutopilotV1beta2().ControlNodes()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		node, err := controlNodes.Get(ctx, nodeName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			// The ControlNode is created by autopilot, which might be disabled.
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if node.Annotations == nil {
+			node.Annotations = make(map[string]string)
+		}
+		node.Annotations[NodeStatusAnnotation] = string(data)
+		_, err = controlNodes.Update(ctx, node, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// updateControllerStatus reflects the given node status in the status of the
+// ControllerStatus object with the given name, creating it if necessary.
+func updateControllerStatus(ctx context.Context, client dynamic.ResourceInterface, name string, status *NodeStatus) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		current, err := client.Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			var newStatus unstructured.Unstructured
+			newStatus.SetGroupVersionKind(statusv1beta1.GroupVersion.WithKind("ControllerStatus"))
+			newStatus.SetName(name)
+			newStatus.SetNamespace(statusv1beta1.ControllerStatusNamespace)
+			current, err = client.Create(ctx, &newStatus, metav1.CreateOptions{})
+			if apierrors.IsNotFound(err) {
+				// The ControllerStatus CRD might not have been applied yet.
+				return nil
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		var controllerStatus statusv1beta1.ControllerStatus
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(current.Object, &controllerStatus); err != nil {
+			return err
+		}
+
+		state := &controllerStatus.Status
+		state.Version = status.Version
+		state.Role = status.Role
+		state.CABundleVersion = status.CABundleVersion
+		state.LastUpdateTime = status.LastUpdateTime
+		state.Components = make([]statusv1beta1.ComponentState, len(status.Components))
+		var unhealthy []string
+		for i, component := range status.Components {
+			state.Components[i] = statusv1beta1.ComponentState(component)
+			if !component.Healthy {
+				unhealthy = append(unhealthy, component.Name)
+			}
+		}
+
+		healthy := metav1.Condition{
+			Type:               statusv1beta1.HealthyCondition,
+			ObservedGeneration: controllerStatus.Generation,
+		}
+		switch {
+		case len(status.Components) == 0:
+			healthy.Status = metav1.ConditionUnknown
+			healthy.Reason = "NoHealthProbes"
+			healthy.Message = "No component has been probed yet"
+		case len(unhealthy) == 0:
+			healthy.Status = metav1.ConditionTrue
+			healthy.Reason = "AllComponentsHealthy"
+			healthy.Message = fmt.Sprintf("All %d components are healthy", len(status.Components))
+		default:
+			healthy.Status = metav1.ConditionFalse
+			healthy.Reason = "ComponentsUnhealthy"
+			healthy.Message = fmt.Sprintf("Unhealthy components: %s", strings.Join(unhealthy, ", "))
+		}
+		meta.SetStatusCondition(&state.Conditions, healthy)
+
+		updated, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&controllerStatus)
+		if err != nil {
+			return err
+		}
+		_, err = client.UpdateStatus(ctx, &unstructured.Unstructured{Object: updated}, metav1.UpdateOptions{})
+		return err
+	})
+}
+
+// NodeStatusPublisher publishes the node status of a worker on its Node,
+// using kubelet's credentials.
+type NodeStatusPublisher struct {
+	CertManager certManager
+
+	client kubernetes.Interface
+}
+
+func (p *NodeStatusPublisher) PublishNodeStatus(ctx context.Context, status *NodeStatus) error {
+	nodeName, err := apcomm.FindEffectiveHostname()
+	if err != nil {
+		return err
+	}
+	if p.client == nil {
+		restConfig, err := p.CertManager.GetRestConfig()
+		if err != nil {
+			return err
+		}
+		if p.client, err = kubernetes.NewForConfig(restConfig); err != nil {
+			return err
+		}
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	patch, err := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{NodeStatusAnnotation: string(data)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = p.client.CoreV1().Nodes().Patch(ctx, nodeName, types.MergePatchType, patch, metav1.PatchOptions{})
+	if apierrors.IsNotFound(err) {
+		// The Node is registered by kubelet, which might not have happened yet.
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	statusv1beta1 "github.com/k0sproject/k0s/pkg/apis/status/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/prober"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+type staticState prober.State
+
+func (s staticState) State(int) prober.State { return prober.State(s) }
+
+func TestNodeStatus(t *testing.T) {
+	now := time.Unix(1000, 0)
+	s := &Status{
+		StatusInformation: K0sStatus{Version: "v1.27.1+k0s.0", Role: "controller", Workloads: true},
+		Prober: staticState{HealthProbes: map[string][]prober.ProbeResult{
+			"kubelet": {
+				{Component: "kubelet", At: now.Add(-20 * time.Second)},
+				{Component: "kubelet", At: now.Add(-10 * time.Second), Error: errors.New("connection refused")},
+			},
+			"containerd": {{Component: "containerd", At: now}},
+			"etcd":       {},
+		}},
+	}
+
+	status := s.nodeStatus(now)
+	assert.Equal(t, &NodeStatus{
+		Version: "v1.27.1+k0s.0",
+		Role:    "controller+worker",
+		Components: []ComponentHealth{
+			{Name: "containerd", Healthy: true},
+			{Name: "kubelet", Message: "connection refused"},
+		},
+		LastUpdateTime: metav1.NewTime(now),
+	}, status)
+
+	data, err := json.Marshal(status)
+	require.NoError(t, err)
+	parsed, err := ParseNodeStatus(map[string]string{NodeStatusAnnotation: string(data)})
+	require.NoError(t, err)
+	assert.Equal(t, status.Components, parsed.Components)
+	assert.True(t, status.LastUpdateTime.Equal(&parsed.LastUpdateTime))
+
+	parsed, err = ParseNodeStatus(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, parsed)
+}
+
+func TestUpdateControllerStatus(t *testing.T) {
+	ctx := context.Background()
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme()).
+		Resource(controllerStatusGVR).Namespace(statusv1beta1.ControllerStatusNamespace)
+	getStatus := func() *statusv1beta1.ControllerState {
+		u, err := client.Get(ctx, "controller-0", metav1.GetOptions{})
+		require.NoError(t, err)
+		var controllerStatus statusv1beta1.ControllerStatus
+		require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, &controllerStatus))
+		return &controllerStatus.Status
+	}
+
+	now := metav1.NewTime(time.Unix(1000, 0))
+	nodeStatus := &NodeStatus{
+		Version:         "v1.27.1+k0s.0",
+		Role:            "controller",
+		CABundleVersion: "abc",
+		LastUpdateTime:  now,
+		Components: []ComponentHealth{
+			{Name: "etcd", Healthy: true},
+			{Name: "kube-apiserver", Healthy: true},
+		},
+	}
+	require.NoError(t, updateControllerStatus(ctx, client, "controller-0", nodeStatus))
+
+	state := getStatus()
+	assert.Equal(t, "v1.27.1+k0s.0", state.Version)
+	assert.Equal(t, "controller", state.Role)
+	assert.Equal(t, "abc", state.CABundleVersion)
+	assert.True(t, now.Equal(&state.LastUpdateTime))
+	assert.Equal(t, []statusv1beta1.ComponentState{
+		{Name: "etcd", Healthy: true},
+		{Name: "kube-apiserver", Healthy: true},
+	}, state.Components)
+	assert.True(t, meta.IsStatusConditionTrue(state.Conditions, statusv1beta1.HealthyCondition))
+
+	nodeStatus.Components[0] = ComponentHealth{Name: "etcd", Message: "connection refused"}
+	require.NoError(t, updateControllerStatus(ctx, client, "controller-0", nodeStatus))
+
+	state = getStatus()
+	assert.Equal(t, statusv1beta1.ComponentState{Name: "etcd", Message: "connection refused"}, state.Components[0])
+	if healthy := meta.FindStatusCondition(state.Conditions, statusv1beta1.HealthyCondition); assert.NotNil(t, healthy) {
+		assert.Equal(t, metav1.ConditionFalse, healthy.Status)
+		assert.Equal(t, "Unhealthy components: etcd", healthy.Message)
+	}
+}
@@ -1,5 +1,8 @@
+//go:build !linux
+// +build !linux
+
 /*
-Copyright 2021 k0s authors
+Copyright 2023 k0s authors
 
 Licensed under the Apache License, Version 2.0 (the "License");
 you may not use this file except in compliance with the License.
@@ -14,17 +17,12 @@ See the License for the specific language governing permissions and
 limitations under the License.
 */
 
-package cleanup
-
-type bridge struct {
-}
+package status
 
-// Name returns the name of the step
-func (b *bridge) Name() string {
-	return "kube-bridge leftovers cleanup step"
-}
+import "net"
 
-// Run removes found kube-bridge leftovers
-func (b *bridge) Run() error {
+// authorizePeer relies on the permissions of the unix socket or named pipe
+// only, as peer credentials are only checked on Linux.
+func authorizePeer(net.Conn) error {
 	return nil
 }
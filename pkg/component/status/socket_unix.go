@@ -0,0 +1,58 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+)
+
+// listen creates a unix socket listener for the status API. If restricted is
+// set, the socket is only accessible by its owner, as it hands out admin
+// credentials then. Otherwise, the socket's permissions are left unchanged.
+func listen(socket string, restricted bool) (net.Listener, error) {
+	removeLeftovers(socket)
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		return nil, err
+	}
+	if restricted {
+		if err := os.Chmod(socket, 0600); err != nil {
+			_ = listener.Close()
+			return nil, fmt.Errorf("failed to restrict permissions of %s: %w", socket, err)
+		}
+	}
+	return listener, nil
+}
+
+// removeLeftovers tries to remove leftover sockets that nothing is listening on
+func removeLeftovers(socket string) {
+	_, err := net.Dial("unix", socket)
+	if err != nil {
+		_ = os.Remove(socket)
+	}
+}
+
+func dial(ctx context.Context, socket string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "unix", socket)
+}
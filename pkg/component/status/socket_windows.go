@@ -0,0 +1,43 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"net"
+
+	"github.com/Microsoft/go-winio"
+)
+
+// pipeSecurityDescriptor restricts access to the status pipe to the local
+// Administrators group and the SYSTEM account, for when it hands out admin
+// credentials.
+const pipeSecurityDescriptor = "D:P(A;;GA;;;BA)(A;;GA;;;SY)"
+
+// listen creates a named pipe listener for the status API. If restricted is
+// set, the pipe is only accessible by administrators.
+func listen(socket string, restricted bool) (net.Listener, error) {
+	var config *winio.PipeConfig
+	if restricted {
+		config = &winio.PipeConfig{SecurityDescriptor: pipeSecurityDescriptor}
+	}
+	return winio.ListenPipe(socket, config)
+}
+
+func dial(ctx context.Context, socket string) (net.Conn, error) {
+	return winio.DialPipeContext(ctx, socket)
+}
@@ -19,6 +19,7 @@ package status
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
@@ -27,13 +28,18 @@ import (
 	"time"
 
 	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/iptablesutils"
 	"github.com/k0sproject/k0s/pkg/autopilot/client"
+	"github.com/k0sproject/k0s/pkg/component/controller/leaderelector"
 	"github.com/k0sproject/k0s/pkg/component/manager"
 	"github.com/k0sproject/k0s/pkg/component/prober"
+	"github.com/k0sproject/k0s/pkg/performance"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
+	clientauthv1 "k8s.io/client-go/pkg/apis/clientauthentication/v1"
 	"k8s.io/client-go/rest"
 )
 
@@ -48,14 +54,43 @@ type Status struct {
 	httpserver        http.Server
 	listener          net.Listener
 	CertManager       certManager
+	// CredentialIssuer issues short-lived admin credentials. The credentials
+	// endpoint is only served if this is set.
+	CredentialIssuer credentialIssuer
+	// LeaderElection reports the state of the controller's leader election,
+	// if any.
+	LeaderElection leaderElection
+	// StartupProfile provides the checkpoints recorded during startup. The
+	// startup profile endpoint is only served if this is set.
+	StartupProfile startupProfile
+	// NodeStatusPublisher publishes the node status of this instance in the
+	// cluster. The node status is only published if this is set.
+	NodeStatusPublisher nodeStatusPublisher
+
+	stopPublisher func()
 }
 
 type certManager interface {
 	GetRestConfig() (*rest.Config, error)
 }
 
+type credentialIssuer interface {
+	IssueAdminCredential() (*clientauthv1.ExecCredentialStatus, error)
+}
+
+type leaderElection interface {
+	Status() leaderelector.Status
+}
+
+type startupProfile interface {
+	Profile() performance.Profile
+}
+
 var _ manager.Component = (*Status)(nil)
 
+// connContextKey is the context key for the connection of an HTTP request.
+type connContextKey struct{}
+
 const defaultMaxEvents = 5
 
 // Init initializes component
@@ -73,17 +108,52 @@ func (s *Status) Init(_ context.Context) error {
 			w.WriteHeader(http.StatusInternalServerError)
 		}
 	})
+	mux.Handle("/metrics", promhttp.Handler())
+	if s.StartupProfile != nil {
+		mux.HandleFunc("/startup-profile", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if json.NewEncoder(w).Encode(s.StartupProfile.Profile()) != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})
+	}
+	if s.CredentialIssuer != nil {
+		mux.HandleFunc("/credentials", func(w http.ResponseWriter, r *http.Request) {
+			if conn, ok := r.Context().Value(connContextKey{}).(net.Conn); !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			} else if err := authorizePeer(conn); err != nil {
+				s.L.WithError(err).Warn("Refusing to hand out admin credential")
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			credential, err := s.CredentialIssuer.IssueAdminCredential()
+			if err != nil {
+				s.L.WithError(err).Error("Failed to issue admin credential")
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if json.NewEncoder(w).Encode(credential) != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		})
+	}
 	var err error
 	s.httpserver = http.Server{
 		Handler: mux,
+		ConnContext: func(ctx context.Context, c net.Conn) context.Context {
+			return context.WithValue(ctx, connContextKey{}, c)
+		},
 	}
 	err = dir.Init(s.StatusInformation.K0sVars.RunDir, 0755)
 	if err != nil {
 		return fmt.Errorf("failed to create %s: %w", s.Socket, err)
 	}
 
-	removeLeftovers(s.Socket)
-	s.listener, err = net.Listen("unix", s.Socket)
+	// The credentials endpoint hands out admin credentials, so the socket
+	// needs to be restricted if it's served.
+	s.listener, err = listen(s.Socket, s.CredentialIssuer != nil)
 	if err != nil {
 		s.L.Errorf("failed to create listener %s", err)
 		return err
@@ -93,14 +163,6 @@ func (s *Status) Init(_ context.Context) error {
 	return nil
 }
 
-// removeLeftovers tries to remove leftover sockets that nothing is listening on
-func removeLeftovers(socket string) {
-	_, err := net.Dial("unix", socket)
-	if err != nil {
-		_ = os.Remove(socket)
-	}
-}
-
 // Start runs the component
 func (s *Status) Start(_ context.Context) error {
 	go func() {
@@ -108,11 +170,23 @@ func (s *Status) Start(_ context.Context) error {
 			s.L.Errorf("failed to start status server at %s: %s", s.Socket, err)
 		}
 	}()
+	if s.NodeStatusPublisher != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			s.runNodeStatusPublisher(ctx)
+		}()
+		s.stopPublisher = func() { cancel(); <-done }
+	}
 	return nil
 }
 
 // Stop stops status component and removes the unix socket
 func (s *Status) Stop() error {
+	if s.stopPublisher != nil {
+		s.stopPublisher()
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := s.httpserver.Shutdown(ctx); err != nil && err != context.Canceled {
@@ -145,10 +219,20 @@ const (
 
 func (sh *statusHandler) getCurrentStatus(ctx context.Context) K0sStatus {
 	status := sh.Status.StatusInformation
+	if sh.Status.LeaderElection != nil {
+		leaderElection := sh.Status.LeaderElection.Status()
+		status.LeaderElection = &leaderElection
+	}
 	if !status.Workloads {
 		return status
 	}
 
+	if detection, err := iptablesutils.ReadDetection(status.K0sVars.RunDir); err == nil {
+		status.IPTables = detection
+	} else if !errors.Is(err, os.ErrNotExist) {
+		sh.Status.L.WithError(err).Debug("Failed to read iptables mode")
+	}
+
 	if sh.client == nil {
 		kubeClient, err := sh.buildWorkerSideKubeAPIClient(ctx)
 		if err != nil {
@@ -157,8 +241,7 @@ func (sh *statusHandler) getCurrentStatus(ctx context.Context) K0sStatus {
 		}
 		sh.client = kubeClient
 	}
-	_, err := sh.client.CoreV1().Nodes().List(context.Background(), v1.ListOptions{})
-	if err != nil {
+	if err := ProbeAPIServer(context.Background(), sh.client); err != nil {
 		status.WorkerToAPIConnectionStatus.Message = err.Error()
 		return status
 	}
@@ -166,6 +249,33 @@ func (sh *statusHandler) getCurrentStatus(ctx context.Context) K0sStatus {
 	return status
 }
 
+// ProbeAPIServer checks if the API server is serving requests.
+func ProbeAPIServer(ctx context.Context, client kubernetes.Interface) error {
+	_, err := client.CoreV1().Nodes().List(ctx, v1.ListOptions{})
+	return err
+}
+
+// WaitForAPIServer probes the API server until it's serving requests, or the
+// timeout is exceeded.
+func WaitForAPIServer(ctx context.Context, getClient func() (kubernetes.Interface, error), timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var lastErr error
+	err := wait.PollImmediateUntilWithContext(ctx, defaultPollDuration, func(ctx context.Context) (bool, error) {
+		client, err := getClient()
+		if err == nil {
+			err = ProbeAPIServer(ctx, client)
+		}
+		lastErr = err
+		return err == nil, nil
+	})
+	if err != nil && lastErr != nil {
+		return fmt.Errorf("API server not ready after %s: %w", timeout, lastErr)
+	}
+	return err
+}
+
 func (sh *statusHandler) buildWorkerSideKubeAPIClient(ctx context.Context) (kubernetes.Interface, error) {
 	var restConfig *rest.Config
 	var err error
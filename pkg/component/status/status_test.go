@@ -0,0 +1,54 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package status
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWaitForAPIServer(t *testing.T) {
+	t.Run("ready", func(t *testing.T) {
+		client := fake.NewSimpleClientset()
+		var failures int
+		client.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+			if failures < 1 {
+				failures++
+				return true, nil, errors.New("connection refused")
+			}
+			return false, nil, nil
+		})
+
+		getClient := func() (kubernetes.Interface, error) { return client, nil }
+		assert.NoError(t, WaitForAPIServer(context.Background(), getClient, 10*time.Second))
+		assert.Equal(t, 1, failures)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		getClient := func() (kubernetes.Interface, error) { return nil, errors.New("no kubeconfig") }
+		err := WaitForAPIServer(context.Background(), getClient, 10*time.Millisecond)
+		assert.ErrorContains(t, err, "API server not ready after 10ms: no kubeconfig")
+	})
+}
@@ -0,0 +1,337 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509/pkix"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/pkg/certificate"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	workerconfig "github.com/k0sproject/k0s/pkg/component/worker/config"
+	"github.com/k0sproject/k0s/pkg/constant"
+	kubeutil "github.com/k0sproject/k0s/pkg/kubernetes"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+	kubeletcert "k8s.io/client-go/util/certificate"
+	"k8s.io/client-go/util/certificate/csr"
+	"k8s.io/client-go/util/keyutil"
+
+	"github.com/sirupsen/logrus"
+)
+
+// kubeletCertificateTimeout is the time to wait for kubelet's client
+// certificate to be issued.
+const kubeletCertificateTimeout = 5 * time.Minute
+
+// CARotation keeps the worker's trust in the cluster CA in line with the
+// worker profile, and renews kubelet's certificates when the CA is rotated.
+type CARotation struct {
+	K0sVars       constant.CfgVars
+	WorkerProfile string
+	// The kubeconfigs that need to trust the cluster CA. The first one is used
+	// to talk to the API server.
+	Kubeconfigs []string
+	Kubelet     *Kubelet
+
+	log            logrus.FieldLogger
+	appliedVersion string
+	stop           func()
+}
+
+var _ manager.Component = (*CARotation)(nil)
+
+func (c *CARotation) Init(context.Context) error {
+	c.log = logrus.WithField("component", "ca-rotation")
+	return nil
+}
+
+func (c *CARotation) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan workerconfig.CertificateAuthorities, 1)
+	done := make(chan struct{}, 2)
+
+	go func() {
+		defer func() { done <- struct{}{} }()
+		wait.UntilWithContext(ctx, func(ctx context.Context) {
+			client, err := kubeutil.NewClientFromFile(c.Kubeconfigs[0])
+			if err == nil {
+				err = workerconfig.WatchProfile(ctx, c.log, client, c.K0sVars.DataDir, c.WorkerProfile, func(profile workerconfig.Profile) error {
+					// Only the latest update is of interest.
+					select {
+					case <-updates:
+					default:
+					}
+					updates <- profile.CertificateAuthorities
+					return nil
+				})
+			}
+			if err != nil && ctx.Err() == nil {
+				c.log.WithError(err).Error("Failed to watch worker profile")
+			}
+		}, 10*time.Second)
+	}()
+
+	go func() {
+		defer func() { done <- struct{}{} }()
+		c.runReconcileLoop(ctx, updates)
+	}()
+
+	c.stop = func() { cancel(); <-done; <-done }
+	return nil
+}
+
+func (c *CARotation) Stop() error {
+	if c.stop != nil {
+		c.stop()
+	}
+	return nil
+}
+
+// runReconcileLoop reconciles every update. Failed reconciliations, e.g. if
+// kubelet's certificates couldn't be renewed yet, are retried every minute.
+func (c *CARotation) runReconcileLoop(ctx context.Context, updates <-chan workerconfig.CertificateAuthorities) {
+	retryTicker := time.NewTicker(1 * time.Minute)
+	defer retryTicker.Stop()
+
+	var cas *workerconfig.CertificateAuthorities
+	var lastRecoFailed bool
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case update := <-updates:
+			cas = &update
+		case <-retryTicker.C:
+			if !lastRecoFailed {
+				continue
+			}
+		}
+
+		err := c.reconcile(ctx, cas)
+		if err != nil && ctx.Err() == nil {
+			c.log.WithError(err).Error("Failed to reconcile certificate authorities")
+		}
+		lastRecoFailed = err != nil
+	}
+}
+
+func (c *CARotation) reconcile(ctx context.Context, cas *workerconfig.CertificateAuthorities) error {
+	restart, err := ApplyCertificateAuthorities(c.K0sVars, cas, c.Kubeconfigs...)
+	if err != nil {
+		return err
+	}
+	if cas.Version != c.appliedVersion {
+		c.log.Infof("Trusting CA bundle version %s", cas.Version)
+		c.appliedVersion = cas.Version
+	}
+
+	if cas.Signer != "" {
+		var client kubernetes.Interface
+		var renewed bool
+		client, err = kubeutil.NewClientFromFile(c.Kubeconfigs[0])
+		if err == nil {
+			certDir := filepath.Join(c.K0sVars.DataDir, "kubelet", "pki")
+			renewed, err = renewKubeletCertificates(ctx, client, certDir, []byte(cas.Signer))
+		}
+		restart = restart || renewed
+	}
+
+	if restart {
+		c.log.Info("Restarting kubelet to apply the changed certificate authorities")
+		if err := c.Kubelet.Restart(); err != nil {
+			return fmt.Errorf("failed to restart kubelet: %w", err)
+		}
+	}
+
+	return err
+}
+
+// ApplyCertificateAuthorities writes the CA bundle to the certificate
+// directory, from where kubelet reloads it, and makes the given kubeconfigs
+// trust it. Returns true if any of the kubeconfigs changed, which means that
+// the processes using them need to be restarted. If the worker profile doesn't
+// include a bundle, the CA the worker joined with is used. Bundles that don't
+// match their version are rejected.
+func ApplyCertificateAuthorities(k0sVars constant.CfgVars, cas *workerconfig.CertificateAuthorities, kubeconfigs ...string) (bool, error) {
+	bundlePath := certificate.CABundlePath(k0sVars.CertRootDir)
+	if cas.Version != "" {
+		if version := certificate.CABundleVersion([]byte(cas.Bundle)); version != cas.Version {
+			return false, fmt.Errorf("CA bundle version mismatch: expected %s, got %s", cas.Version, version)
+		}
+	}
+	if cas.Bundle == "" {
+		if file.Exists(bundlePath) {
+			return false, nil
+		}
+		ca, err := os.ReadFile(filepath.Join(k0sVars.CertRootDir, "ca.crt"))
+		if err != nil {
+			return false, err
+		}
+		return false, file.WriteContentAtomically(bundlePath, ca, constant.CertMode)
+	}
+
+	bundle := []byte(cas.Bundle)
+	if existing, err := os.ReadFile(bundlePath); err != nil || !bytes.Equal(existing, bundle) {
+		if err := file.WriteContentAtomically(bundlePath, bundle, constant.CertMode); err != nil {
+			return false, err
+		}
+	}
+
+	var changed bool
+	for _, kubeconfig := range kubeconfigs {
+		kubeconfigChanged, err := setKubeconfigCA(kubeconfig, bundle)
+		if err != nil {
+			return changed, fmt.Errorf("failed to update %s: %w", kubeconfig, err)
+		}
+		changed = changed || kubeconfigChanged
+	}
+
+	return changed, nil
+}
+
+// setKubeconfigCA makes all clusters in the given kubeconfig trust the given
+// CA bundle.
+func setKubeconfigCA(path string, bundle []byte) (bool, error) {
+	kubeconfig, err := clientcmd.LoadFromFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	var changed bool
+	for _, cluster := range kubeconfig.Clusters {
+		if cluster.CertificateAuthority == "" && bytes.Equal(cluster.CertificateAuthorityData, bundle) {
+			continue
+		}
+		cluster.CertificateAuthority = ""
+		cluster.CertificateAuthorityData = bundle
+		changed = true
+	}
+	if !changed {
+		return false, nil
+	}
+
+	data, err := clientcmd.Write(*kubeconfig)
+	if err != nil {
+		return false, err
+	}
+	return true, file.WriteContentAtomically(path, data, 0600)
+}
+
+// renewKubeletCertificates makes sure that kubelet's certificates are issued
+// by the given signer. The client certificate is requested right away, using
+// the current one for authentication. The serving certificate is removed, so
+// that kubelet requests a new one when it's restarted. Returns true if kubelet
+// needs to be restarted.
+func renewKubeletCertificates(ctx context.Context, client kubernetes.Interface, certDir string, signerPEM []byte) (bool, error) {
+	signers, err := certutil.ParseCertsPEM(signerPEM)
+	if err != nil {
+		return false, err
+	}
+	signer := signers[0]
+
+	var renewed bool
+	serverCertFile := filepath.Join(certDir, "kubelet-server-current.pem")
+	if serverCert, err := certutil.CertsFromFile(serverCertFile); err == nil && serverCert[0].CheckSignatureFrom(signer) != nil {
+		if err := os.Remove(serverCertFile); err != nil {
+			return false, err
+		}
+		renewed = true
+	}
+
+	store, err := kubeletcert.NewFileStore("kubelet-client", certDir, certDir, "", "")
+	if err != nil {
+		return renewed, err
+	}
+	current, err := store.Current()
+	if err != nil {
+		return renewed, err
+	}
+	if current.Leaf.CheckSignatureFrom(signer) == nil {
+		return renewed, nil
+	}
+
+	certPEM, keyPEM, err := requestKubeletClientCertificate(ctx, client, current.Leaf.Subject.CommonName)
+	if err != nil {
+		return renewed, err
+	}
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return renewed, err
+	}
+	if certs[0].CheckSignatureFrom(signer) != nil {
+		// Some controllers might not have switched to the new CA yet.
+		return renewed, errors.New("kubelet client certificate hasn't been issued by the new CA yet")
+	}
+	if _, err := store.Update(certPEM, keyPEM); err != nil {
+		return renewed, err
+	}
+
+	return true, nil
+}
+
+func requestKubeletClientCertificate(ctx context.Context, client kubernetes.Interface, commonName string) (certPEM, keyPEM []byte, err error) {
+	if !strings.HasPrefix(commonName, "system:node:") {
+		return nil, nil, fmt.Errorf("not a kubelet client certificate: %s", commonName)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	csrPEM, err := certutil.MakeCSR(key, &pkix.Name{
+		CommonName:   commonName,
+		Organization: []string{"system:nodes"},
+	}, nil, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	usages := []certificatesv1.KeyUsage{certificatesv1.UsageDigitalSignature, certificatesv1.UsageClientAuth}
+	reqName, reqUID, err := csr.RequestCertificate(client, csrPEM, "", certificatesv1.KubeAPIServerClientKubeletSignerName, nil, usages, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, kubeletCertificateTimeout)
+	defer cancel()
+	certPEM, err = csr.WaitForCertificate(ctx, client, reqName, reqUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err = keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return certPEM, keyPEM, nil
+}
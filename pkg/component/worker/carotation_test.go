@@ -0,0 +1,93 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/certificate"
+	workerconfig "github.com/k0sproject/k0s/pkg/component/worker/config"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyCertificateAuthorities(t *testing.T) {
+	k0sVars := constant.CfgVars{CertRootDir: t.TempDir()}
+	bundlePath := filepath.Join(k0sVars.CertRootDir, "ca-bundle.crt")
+	kubeconfigPath := filepath.Join(t.TempDir(), "kubelet.conf")
+	require.NoError(t, os.WriteFile(filepath.Join(k0sVars.CertRootDir, "ca.crt"), []byte("joined"), 0644))
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: k0s
+  cluster:
+    server: https://localhost:6443
+    certificate-authority: /some/ca.crt
+`), 0600))
+
+	t.Run("no_bundle", func(t *testing.T) {
+		restart, err := ApplyCertificateAuthorities(k0sVars, &workerconfig.CertificateAuthorities{}, kubeconfigPath)
+		require.NoError(t, err)
+		assert.False(t, restart)
+		bundle, err := os.ReadFile(bundlePath)
+		require.NoError(t, err)
+		assert.Equal(t, "joined", string(bundle), "should fall back to the joined CA")
+	})
+
+	t.Run("bundle", func(t *testing.T) {
+		cas := &workerconfig.CertificateAuthorities{Bundle: "bundle"}
+		restart, err := ApplyCertificateAuthorities(k0sVars, cas, kubeconfigPath)
+		require.NoError(t, err)
+		assert.True(t, restart)
+
+		bundle, err := os.ReadFile(bundlePath)
+		require.NoError(t, err)
+		assert.Equal(t, "bundle", string(bundle))
+		kubeconfig, err := clientcmd.LoadFromFile(kubeconfigPath)
+		require.NoError(t, err)
+		if assert.Contains(t, kubeconfig.Clusters, "k0s") {
+			assert.Empty(t, kubeconfig.Clusters["k0s"].CertificateAuthority)
+			assert.Equal(t, "bundle", string(kubeconfig.Clusters["k0s"].CertificateAuthorityData))
+			assert.Equal(t, "https://localhost:6443", kubeconfig.Clusters["k0s"].Server)
+		}
+
+		restart, err = ApplyCertificateAuthorities(k0sVars, cas, kubeconfigPath)
+		require.NoError(t, err)
+		assert.False(t, restart, "applying the same bundle twice shouldn't require a restart")
+	})
+
+	t.Run("versioned_bundle", func(t *testing.T) {
+		cas := &workerconfig.CertificateAuthorities{Bundle: "next", Version: certificate.CABundleVersion([]byte("next"))}
+		restart, err := ApplyCertificateAuthorities(k0sVars, cas, kubeconfigPath)
+		require.NoError(t, err)
+		assert.True(t, restart)
+
+		cas = &workerconfig.CertificateAuthorities{Bundle: "tampered", Version: cas.Version}
+		_, err = ApplyCertificateAuthorities(k0sVars, cas, kubeconfigPath)
+		assert.ErrorContains(t, err, "CA bundle version mismatch")
+		bundle, err := os.ReadFile(bundlePath)
+		require.NoError(t, err)
+		assert.Equal(t, "next", string(bundle))
+	})
+}
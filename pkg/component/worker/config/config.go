@@ -27,6 +27,7 @@ import (
 
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	certutil "k8s.io/client-go/util/cert"
 	kubeletv1beta1 "k8s.io/kubelet/config/v1beta1"
 
 	"go.uber.org/multierr"
@@ -39,6 +40,15 @@ type Profile struct {
 	KubeletConfiguration   kubeletv1beta1.KubeletConfiguration
 	NodeLocalLoadBalancing *v1beta1.NodeLocalLoadBalancing
 	Konnectivity           Konnectivity
+	CertificateAuthorities CertificateAuthorities
+	Kernel                 *v1beta1.KernelSettings
+	IPTables               IPTables
+	// Overrides the auto-detection of SELinux support for containerd.
+	SELinux *bool
+	// Indicates that an external cloud controller manager is deployed into
+	// the cluster, so that kubelets need to run with an external cloud
+	// provider.
+	ExternalCloudProvider bool
 }
 
 func (p *Profile) DeepCopy() *Profile {
@@ -62,6 +72,11 @@ func (p *Profile) DeepCopyInto(out *Profile) {
 		*out = new(v1beta1.NodeLocalLoadBalancing)
 		(*in).DeepCopyInto(*out)
 	}
+	out.Kernel = p.Kernel.DeepCopy()
+	if p.SELinux != nil {
+		out.SELinux = new(bool)
+		*out.SELinux = *p.SELinux
+	}
 }
 
 func (p *Profile) Validate(path *field.Path) (errs field.ErrorList) {
@@ -71,6 +86,9 @@ func (p *Profile) Validate(path *field.Path) (errs field.ErrorList) {
 
 	errs = append(errs, p.NodeLocalLoadBalancing.Validate(path.Child("nodeLocalLoadBalancing"))...)
 	errs = append(errs, p.Konnectivity.Validate(path.Child("konnectivity"))...)
+	errs = append(errs, p.CertificateAuthorities.Validate(path.Child("certificateAuthorities"))...)
+	errs = append(errs, p.Kernel.Validate(path.Child("kernel"))...)
+	errs = append(errs, p.IPTables.Validate(path.Child("iptables"))...)
 
 	return
 }
@@ -93,6 +111,64 @@ func (k *Konnectivity) Validate(path *field.Path) (errs field.ErrorList) {
 	return
 }
 
+// CertificateAuthorities describes the cluster CA as seen by worker nodes.
+type CertificateAuthorities struct {
+	// The PEM encoded certificates of the CAs that are trusted.
+	Bundle string `json:"bundle,omitempty"`
+	// The PEM encoded certificate of the CA that currently issues
+	// certificates. Differs from the first certificate in the bundle while a
+	// CA rotation is in progress.
+	Signer string `json:"signer,omitempty"`
+	// The version of the bundle. Changes whenever the bundle changes, e.g. in
+	// each phase of a CA rotation.
+	Version string `json:"version,omitempty"`
+}
+
+func (c *CertificateAuthorities) Validate(path *field.Path) (errs field.ErrorList) {
+	if c == nil {
+		return
+	}
+
+	if c.Bundle != "" {
+		if _, err := certutil.ParseCertsPEM([]byte(c.Bundle)); err != nil {
+			errs = append(errs, field.Invalid(path.Child("bundle"), "", err.Error()))
+		}
+	}
+	if c.Signer != "" {
+		if c.Bundle == "" {
+			errs = append(errs, field.Required(path.Child("bundle"), "required if signer is set"))
+		}
+		if _, err := certutil.ParseCertsPEM([]byte(c.Signer)); err != nil {
+			errs = append(errs, field.Invalid(path.Child("signer"), "", err.Error()))
+		}
+	}
+
+	return
+}
+
+// IPTables holds the cluster-wide settings that influence the iptables mode of
+// worker nodes.
+type IPTables struct {
+	// The iptables mode of worker nodes that don't specify one on their own.
+	Mode string `json:"mode,omitempty"`
+	// The mode in which kube-proxy operates, if it's enabled.
+	KubeProxyMode string `json:"kubeProxyMode,omitempty"`
+}
+
+func (i *IPTables) Validate(path *field.Path) (errs field.ErrorList) {
+	if i == nil {
+		return
+	}
+
+	switch i.Mode {
+	case "", "auto", "legacy", "nft":
+	default:
+		errs = append(errs, field.NotSupported(path.Child("mode"), i.Mode, []string{"auto", "legacy", "nft"}))
+	}
+
+	return
+}
+
 func FromConfigMapData(data map[string]string) (*Profile, error) {
 	var config Profile
 	var errs error
@@ -157,6 +233,11 @@ func forEachConfigMapEntry(profile *Profile, f func(fieldName string, ptr any))
 		"kubeletConfiguration":   &profile.KubeletConfiguration,
 		"nodeLocalLoadBalancing": &profile.NodeLocalLoadBalancing,
 		"konnectivity":           &profile.Konnectivity,
+		"certificateAuthorities": &profile.CertificateAuthorities,
+		"kernel":                 &profile.Kernel,
+		"iptables":               &profile.IPTables,
+		"externalCloudProvider":  &profile.ExternalCloudProvider,
+		"selinux":                &profile.SELinux,
 	} {
 		f(fieldName, ptr)
 	}
@@ -79,6 +79,23 @@ func TestFromConfigMapData(t *testing.T) {
 		assert.ErrorContains(t, err, `nodeLocalLoadBalancing.type: Unsupported value: "Bogus": supported values:`)
 		assert.Nil(t, config)
 	})
+
+	t.Run("iptables", func(t *testing.T) {
+		config, err := FromConfigMapData(map[string]string{
+			"iptables": `{"mode": "bogus"}`,
+		})
+		assert.ErrorContains(t, err, `iptables.mode: Unsupported value: "bogus": supported values: "auto", "legacy", "nft"`)
+		assert.Nil(t, config)
+	})
+
+	t.Run("certificate_authorities", func(t *testing.T) {
+		config, err := FromConfigMapData(map[string]string{
+			"certificateAuthorities": `{"signer": "bogus"}`,
+		})
+		assert.ErrorContains(t, err, "certificateAuthorities.bundle: Required value: required if signer is set")
+		assert.ErrorContains(t, err, "certificateAuthorities.signer: Invalid value")
+		assert.Nil(t, config)
+	})
 }
 
 type roundtripTest struct {
@@ -170,6 +187,53 @@ var roundtripTests = []roundtripTest{
 			"konnectivity": `{"enabled":true,"agentPort":1337}`,
 		},
 	},
+	{
+		"kernel",
+		&Profile{
+			Konnectivity: Konnectivity{AgentPort: 1337},
+			Kernel: &v1beta1.KernelSettings{
+				Sysctls: map[string]string{"vm.max_map_count": "262144"},
+				Modules: []string{"ip_vs"},
+			},
+		},
+		map[string]string{
+			"konnectivity": `{"agentPort":1337}`,
+			"kernel":       `{"sysctls":{"vm.max_map_count":"262144"},"modules":["ip_vs"]}`,
+		},
+	},
+	{
+		"iptables",
+		&Profile{
+			Konnectivity: Konnectivity{AgentPort: 1337},
+			IPTables:     IPTables{Mode: "legacy", KubeProxyMode: "nftables"},
+		},
+		map[string]string{
+			"konnectivity": `{"agentPort":1337}`,
+			"iptables":     `{"mode":"legacy","kubeProxyMode":"nftables"}`,
+		},
+	},
+	{
+		"externalCloudProvider",
+		&Profile{
+			Konnectivity:          Konnectivity{AgentPort: 1337},
+			ExternalCloudProvider: true,
+		},
+		map[string]string{
+			"konnectivity":          `{"agentPort":1337}`,
+			"externalCloudProvider": `true`,
+		},
+	},
+	{
+		"selinux",
+		&Profile{
+			Konnectivity: Konnectivity{AgentPort: 1337},
+			SELinux:      pointer.Bool(false),
+		},
+		map[string]string{
+			"konnectivity": `{"agentPort":1337}`,
+			"selinux":      `false`,
+		},
+	},
 }
 
 func makeHostPort(host string, port uint16) net.HostPort {
@@ -25,9 +25,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -36,6 +36,7 @@ import (
 
 	"github.com/k0sproject/k0s/internal/pkg/dir"
 	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/internal/pkg/selinux"
 	"github.com/k0sproject/k0s/internal/pkg/templatewriter"
 	"github.com/k0sproject/k0s/pkg/assets"
 	"github.com/k0sproject/k0s/pkg/component/manager"
@@ -59,14 +60,20 @@ imports = [
 	{{- end }}
 ]
 `
-const confPath = "/etc/k0s/containerd.toml"
-const importsPath = "/etc/k0s/containerd.d/"
 
 // ContainerD implement the component interface to manage containerd as k0s component
 type ContainerD struct {
 	supervisor supervisor.Supervisor
 	LogLevel   string
 	K0sVars    constant.CfgVars
+	// Rootless runs containerd with the fuse-overlayfs snapshotter plugin,
+	// as needed by the experimental rootless mode.
+	Rootless bool
+	// SELinux enables SELinux support in containerd's CRI plugin and makes
+	// sure that the run directory is labeled accordingly.
+	SELinux bool
+
+	snapshotterSupervisor *supervisor.Supervisor
 
 	OCIBundlePath string
 }
@@ -76,7 +83,7 @@ var _ manager.Component = (*ContainerD)(nil)
 // Init extracts the needed binaries
 func (c *ContainerD) Init(ctx context.Context) error {
 	g, _ := errgroup.WithContext(ctx)
-	for _, bin := range []string{"containerd", "containerd-shim", "containerd-shim-runc-v1", "containerd-shim-runc-v2", "runc"} {
+	for _, bin := range append([]string{containerdBin}, containerdShimBins...) {
 		b := bin
 		g.Go(func() error {
 			return assets.Stage(c.K0sVars.BinDir, b, constant.BinDirMode)
@@ -96,18 +103,30 @@ func (c *ContainerD) Start(ctx context.Context) error {
 
 	c.supervisor = supervisor.Supervisor{
 		Name:    "containerd",
-		BinPath: assets.BinPath("containerd", c.K0sVars.BinDir),
+		BinPath: assets.BinPath(containerdBin, c.K0sVars.BinDir),
 		RunDir:  c.K0sVars.RunDir,
 		DataDir: c.K0sVars.DataDir,
 		Args: []string{
 			fmt.Sprintf("--root=%s", filepath.Join(c.K0sVars.DataDir, "containerd")),
 			fmt.Sprintf("--state=%s", filepath.Join(c.K0sVars.RunDir, "containerd")),
-			fmt.Sprintf("--address=%s", filepath.Join(c.K0sVars.RunDir, "containerd.sock")),
+			fmt.Sprintf("--address=%s", ContainerdAddress(c.K0sVars.RunDir)),
 			fmt.Sprintf("--log-level=%s", c.LogLevel),
 			fmt.Sprintf("--config=%s", confPath),
 		},
 	}
 
+	if c.SELinux {
+		if err := selinux.LabelRunDir(c.K0sVars.RunDir); err != nil {
+			logrus.WithError(err).Warn("Failed to apply SELinux labels to the run directory")
+		}
+	}
+
+	if c.Rootless {
+		if err := c.startFuseOverlayfsSnapshotter(); err != nil {
+			return err
+		}
+	}
+
 	if err := c.supervisor.Supervise(); err != nil {
 		return err
 	}
@@ -137,6 +156,12 @@ func (c *ContainerD) setupConfig() error {
 		return err
 	}
 	containerDConfigurer := containerd.NewConfigurer()
+	if c.Rootless {
+		containerDConfigurer.EnableRootless(c.fuseOverlayfsSocket())
+	}
+	if c.SELinux {
+		containerDConfigurer.EnableSELinux()
+	}
 
 	imports, err := containerDConfigurer.HandleImports()
 	if err != nil {
@@ -158,6 +183,31 @@ func (c *ContainerD) setupConfig() error {
 	return file.WriteContentAtomically(confPath, output.Bytes(), 0644)
 }
 
+// startFuseOverlayfsSnapshotter runs the fuse-overlayfs snapshotter plugin
+// from the host, as it's not bundled with k0s
+func (c *ContainerD) startFuseOverlayfsSnapshotter() error {
+	binPath, err := exec.LookPath("containerd-fuse-overlayfs-grpc")
+	if err != nil {
+		return fmt.Errorf("fuse-overlayfs snapshotter is required in rootless mode: %w", err)
+	}
+
+	c.snapshotterSupervisor = &supervisor.Supervisor{
+		Name:    "containerd-fuse-overlayfs",
+		BinPath: binPath,
+		RunDir:  c.K0sVars.RunDir,
+		DataDir: c.K0sVars.DataDir,
+		Args: []string{
+			c.fuseOverlayfsSocket(),
+			filepath.Join(c.K0sVars.DataDir, "containerd-fuse-overlayfs"),
+		},
+	}
+	return c.snapshotterSupervisor.Supervise()
+}
+
+func (c *ContainerD) fuseOverlayfsSocket() string {
+	return filepath.Join(c.K0sVars.RunDir, "containerd-fuse-overlayfs.sock")
+}
+
 func (c *ContainerD) watchDropinConfigs(ctx context.Context) {
 	log := logrus.WithField("component", "containerd")
 	watcher, err := fsnotify.NewWatcher()
@@ -215,15 +265,20 @@ func (c *ContainerD) restart() {
 		return
 	}
 
-	p := c.supervisor.GetProcess()
-	if err := p.Signal(syscall.SIGHUP); err != nil {
-		log.WithError(err).Warn("failed to send SIGHUP")
+	if err := c.reload(); err != nil {
+		log.WithError(err).Warn("failed to reload containerd")
 	}
 }
 
 // Stop stops containerD
 func (c *ContainerD) Stop() error {
-	return c.supervisor.Stop()
+	if err := c.supervisor.Stop(); err != nil {
+		return err
+	}
+	if c.snapshotterSupervisor != nil {
+		return c.snapshotterSupervisor.Stop()
+	}
+	return nil
 }
 
 // This is the md5sum of the default k0s containerd config file before 1.27
@@ -31,14 +31,21 @@ import (
 	criconfig "github.com/containerd/containerd/pkg/cri/config"
 )
 
-const importsPath = "/etc/k0s/containerd.d/*.toml"
-const containerdCRIConfigPath = "/run/k0s/containerd-cri.toml"
+// FuseOverlayfsSnapshotter is the name of the snapshotter used in rootless mode.
+const FuseOverlayfsSnapshotter = "fuse-overlayfs"
 
 type CRIConfigurer struct {
 	loadPath       string
 	pauseImage     string
 	criRuntimePath string
 
+	// fuseOverlayfsAddress is the socket of the fuse-overlayfs snapshotter
+	// plugin. It's only set in rootless mode.
+	fuseOverlayfsAddress string
+
+	// selinux enables SELinux support in the CRI plugin.
+	selinux bool
+
 	log *logrus.Entry
 }
 
@@ -56,6 +63,18 @@ func NewConfigurer() *CRIConfigurer {
 	}
 }
 
+// EnableRootless configures the CRI plugin to run in a user namespace, using
+// the fuse-overlayfs snapshotter plugin listening on the given socket.
+func (c *CRIConfigurer) EnableRootless(fuseOverlayfsAddress string) {
+	c.fuseOverlayfsAddress = fuseOverlayfsAddress
+}
+
+// EnableSELinux configures the CRI plugin to label containers, as required
+// on hosts where SELinux is in enforcing mode.
+func (c *CRIConfigurer) EnableSELinux() {
+	c.selinux = true
+}
+
 // HandleImports Resolves containerd imports from the import glob path.
 // If the partial config has CRI plugin enabled, it will add to the runc CRI config (single file).
 // if no CRI plugin is found, it will add the file as-is to imports list returned.
@@ -113,8 +132,14 @@ func (c *CRIConfigurer) HandleImports() ([]string, error) {
 
 // We need to use custom struct so we can unmarshal the CRI plugin config only
 type config struct {
-	Version int
-	Plugins map[string]interface{} `toml:"plugins"`
+	Version      int
+	Plugins      map[string]interface{} `toml:"plugins"`
+	ProxyPlugins map[string]proxyPlugin `toml:"proxy_plugins,omitempty"`
+}
+
+type proxyPlugin struct {
+	Type    string `toml:"type"`
+	Address string `toml:"address"`
 }
 
 // generateDefaultCRIConfig generates the default CRI config and writes it to the given writer
@@ -123,12 +148,29 @@ func (c *CRIConfigurer) generateDefaultCRIConfig(w io.Writer) error {
 	criPluginConfig := criconfig.DefaultConfig()
 	// Set pause image
 	criPluginConfig.SandboxImage = c.pauseImage
+	criPluginConfig.EnableSelinux = c.selinux
+	applyPlatformDefaults(&criPluginConfig)
+
+	var proxyPlugins map[string]proxyPlugin
+	if c.fuseOverlayfsAddress != "" {
+		// Kernel features that aren't available to unprivileged users
+		criPluginConfig.DisableApparmor = true
+		criPluginConfig.RestrictOOMScoreAdj = true
+		criPluginConfig.DisableHugetlbController = true
+
+		// Overlay mounts inside user namespaces aren't supported by all kernels
+		criPluginConfig.ContainerdConfig.Snapshotter = FuseOverlayfsSnapshotter
+		proxyPlugins = map[string]proxyPlugin{
+			FuseOverlayfsSnapshotter: {Type: "snapshot", Address: c.fuseOverlayfsAddress},
+		}
+	}
 
 	containerdConfig := config{
 		Version: 2,
 		Plugins: map[string]interface{}{
 			"io.containerd.grpc.v1.cri": criPluginConfig,
 		},
+		ProxyPlugins: proxyPlugins,
 	}
 
 	err := toml.NewEncoder(w).Encode(containerdConfig)
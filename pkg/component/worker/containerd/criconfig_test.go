@@ -21,6 +21,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/pelletier/go-toml"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/require"
 )
@@ -120,3 +121,51 @@ func loadFile(t *testing.T, path string) string {
 	}
 	return string(data)
 }
+
+func TestCRIConfigurer_EnableRootless(t *testing.T) {
+	criRuntimePath := filepath.Join(t.TempDir(), "cri.toml")
+	c := CRIConfigurer{
+		loadPath:       filepath.Join(t.TempDir(), "*.toml"),
+		criRuntimePath: criRuntimePath,
+		log:            logrus.New().WithField("test", t.Name()),
+	}
+	c.EnableRootless("/run/k0s/containerd-fuse-overlayfs.sock")
+
+	_, err := c.HandleImports()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(criRuntimePath)
+	require.NoError(t, err)
+	var cfg map[string]interface{}
+	require.NoError(t, toml.Unmarshal(data, &cfg))
+
+	proxyPlugin := cfg["proxy_plugins"].(map[string]interface{})[FuseOverlayfsSnapshotter].(map[string]interface{})
+	require.Equal(t, "snapshot", proxyPlugin["type"])
+	require.Equal(t, "/run/k0s/containerd-fuse-overlayfs.sock", proxyPlugin["address"])
+
+	criPlugin := cfg["plugins"].(map[string]interface{})["io.containerd.grpc.v1.cri"].(map[string]interface{})
+	require.Equal(t, true, criPlugin["disable_apparmor"])
+	require.Equal(t, true, criPlugin["restrict_oom_score_adj"])
+	require.Equal(t, FuseOverlayfsSnapshotter, criPlugin["containerd"].(map[string]interface{})["snapshotter"])
+}
+
+func TestCRIConfigurer_EnableSELinux(t *testing.T) {
+	criRuntimePath := filepath.Join(t.TempDir(), "cri.toml")
+	c := CRIConfigurer{
+		loadPath:       filepath.Join(t.TempDir(), "*.toml"),
+		criRuntimePath: criRuntimePath,
+		log:            logrus.New().WithField("test", t.Name()),
+	}
+	c.EnableSELinux()
+
+	_, err := c.HandleImports()
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(criRuntimePath)
+	require.NoError(t, err)
+	var cfg map[string]interface{}
+	require.NoError(t, toml.Unmarshal(data, &cfg))
+
+	criPlugin := cfg["plugins"].(map[string]interface{})["io.containerd.grpc.v1.cri"].(map[string]interface{})
+	require.Equal(t, true, criPlugin["enable_selinux"])
+}
@@ -0,0 +1,29 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package containerd
+
+import criconfig "github.com/containerd/containerd/pkg/cri/config"
+
+const importsPath = `C:\etc\k0s\containerd.d\*.toml`
+const containerdCRIConfigPath = `C:\etc\k0s\containerd-cri.toml`
+
+// applyPlatformDefaults points the CNI plugin to the directories that Calico
+// for Windows installs its binaries and configuration into.
+func applyPlatformDefaults(cfg *criconfig.PluginConfig) {
+	cfg.NetworkPluginBinDir = `C:\opt\cni\bin`
+	cfg.NetworkPluginConfDir = `C:\etc\cni\net.d`
+}
@@ -0,0 +1,47 @@
+//go:build !windows
+// +build !windows
+
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"path/filepath"
+	"syscall"
+)
+
+const confPath = "/etc/k0s/containerd.toml"
+const importsPath = "/etc/k0s/containerd.d/"
+
+const containerdBin = "containerd"
+
+var containerdShimBins = []string{"containerd-shim", "containerd-shim-runc-v1", "containerd-shim-runc-v2", "runc"}
+
+// ContainerdAddress returns the address of the k0s managed containerd.
+func ContainerdAddress(runDir string) string {
+	return filepath.Join(runDir, "containerd.sock")
+}
+
+// ContainerdEndpoint returns the CRI endpoint of the k0s managed containerd.
+func ContainerdEndpoint(runDir string) string {
+	return "unix://" + filepath.ToSlash(ContainerdAddress(runDir))
+}
+
+// reload makes containerd pick up its changed configuration.
+func (c *ContainerD) reload() error {
+	return c.supervisor.GetProcess().Signal(syscall.SIGHUP)
+}
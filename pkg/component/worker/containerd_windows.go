@@ -0,0 +1,44 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+const confPath = `C:\etc\k0s\containerd.toml`
+const importsPath = `C:\etc\k0s\containerd.d\`
+
+const containerdBin = "containerd.exe"
+
+var containerdShimBins = []string{"containerd-shim-runhcs-v1.exe"}
+
+// ContainerdAddress returns the address of the k0s managed containerd, which
+// listens on a named pipe on Windows.
+func ContainerdAddress(string) string {
+	return `\\.\pipe\k0s-containerd`
+}
+
+// ContainerdEndpoint returns the CRI endpoint of the k0s managed containerd.
+func ContainerdEndpoint(string) string {
+	return "npipe:////./pipe/k0s-containerd"
+}
+
+// reload makes containerd pick up its changed configuration. Windows has no
+// SIGHUP, so containerd gets restarted instead.
+func (c *ContainerD) reload() error {
+	if err := c.supervisor.Stop(); err != nil {
+		return err
+	}
+	return c.supervisor.Supervise()
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/sirupsen/logrus"
+)
+
+// kernelReconcileInterval is the interval in which the kernel settings are
+// checked for drift.
+const kernelReconcileInterval = 1 * time.Minute
+
+// sysctl is the desired value of a sysctl.
+type sysctl struct {
+	value string
+	// If set, the value is a lower bound. Larger values are left untouched.
+	atLeast bool
+}
+
+// The sysctls that are required by the worker components.
+var defaultSysctls = map[string]sysctl{
+	"net.ipv4.conf.all.forwarding":        {value: "1"},
+	"net.ipv4.conf.default.forwarding":    {value: "1"},
+	"net.ipv6.conf.all.forwarding":        {value: "1"},
+	"net.ipv6.conf.default.forwarding":    {value: "1"},
+	"net.bridge.bridge-nf-call-iptables":  {value: "1"},
+	"net.bridge.bridge-nf-call-ip6tables": {value: "1"},
+	// Pods that watch lots of files, e.g. log shippers, easily exceed the
+	// inotify limits of most distributions.
+	"fs.inotify.max_user_instances": {value: "8192", atLeast: true},
+	"fs.inotify.max_user_watches":   {value: "524288", atLeast: true},
+}
+
+// The kernel modules that are required by the worker components.
+var defaultModules = []string{
+	"overlay",
+	"nf_conntrack",
+	"br_netfilter",
+	// https://github.com/kubernetes/kubernetes/issues/108877
+	"ip_tables",
+}
+
+// Kernel ensures that the sysctls and kernel modules required by the worker,
+// along with the ones given in the worker profile, are set and loaded. They're
+// applied on startup and re-applied whenever they drift.
+type Kernel struct {
+	Settings *v1beta1.KernelSettings
+
+	log          logrus.FieldLogger
+	procSysDir   string
+	sysModuleDir string
+	loadModule   func(name string) error
+	// The last error per sysctl or module, so that they're logged only once.
+	failures map[string]string
+	stop     func()
+}
+
+var _ manager.Component = (*Kernel)(nil)
+
+func (k *Kernel) Init(context.Context) error {
+	k.log = logrus.WithField("component", "kernel")
+	k.procSysDir = "/proc/sys"
+	k.sysModuleDir = "/sys/module"
+	k.loadModule = func(name string) error {
+		if out, err := exec.Command("modprobe", name).CombinedOutput(); err != nil {
+			return fmt.Errorf("modprobe failed: %w (%s)", err, strings.TrimSpace(string(out)))
+		}
+		return nil
+	}
+	k.failures = make(map[string]string)
+	return nil
+}
+
+// Start applies the kernel settings right away, so that they're in place
+// before the container runtime and kubelet are started.
+func (k *Kernel) Start(context.Context) error {
+	if k.Settings != nil && k.Settings.Disabled {
+		k.log.Info("Management of kernel settings is disabled in the worker profile")
+		return nil
+	}
+
+	sysctls, modules := k.desiredState()
+	k.reconcile(sysctls, modules)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wait.UntilWithContext(ctx, func(context.Context) {
+			k.reconcile(sysctls, modules)
+		}, kernelReconcileInterval)
+	}()
+
+	k.stop = func() { cancel(); <-done }
+	return nil
+}
+
+func (k *Kernel) Stop() error {
+	if k.stop != nil {
+		k.stop()
+	}
+	return nil
+}
+
+// desiredState merges the default sysctls and modules with the ones given in
+// the worker profile. Sysctls from the profile override the defaults.
+func (k *Kernel) desiredState() (map[string]sysctl, []string) {
+	sysctls := make(map[string]sysctl, len(defaultSysctls))
+	for name, value := range defaultSysctls {
+		sysctls[name] = value
+	}
+	modules := append([]string(nil), defaultModules...)
+
+	if k.Settings != nil {
+		for name, value := range k.Settings.Sysctls {
+			sysctls[name] = sysctl{value: value}
+		}
+		modules = append(modules, k.Settings.Modules...)
+	}
+
+	return sysctls, modules
+}
+
+func (k *Kernel) reconcile(sysctls map[string]sysctl, modules []string) {
+	// Load the modules first, as some of the sysctls are provided by them.
+	for _, module := range modules {
+		k.report("module "+module, k.ensureModule(module))
+	}
+
+	names := make([]string, 0, len(sysctls))
+	for name := range sysctls {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		k.report("sysctl "+name, k.ensureSysctl(name, sysctls[name]))
+	}
+}
+
+// report logs the given error, unless it has been logged before.
+func (k *Kernel) report(subject string, err error) {
+	if err == nil {
+		delete(k.failures, subject)
+		return
+	}
+	if k.failures[subject] == err.Error() {
+		return
+	}
+	k.failures[subject] = err.Error()
+	k.log.WithError(err).Warnf("Failed to ensure %s", subject)
+}
+
+func (k *Kernel) ensureModule(name string) error {
+	// Modules use underscores in sysfs, regardless of how they're named.
+	if _, err := os.Stat(filepath.Join(k.sysModuleDir, strings.ReplaceAll(name, "-", "_"))); err == nil {
+		return nil
+	}
+
+	if err := k.loadModule(name); err != nil {
+		return err
+	}
+	k.log.Infof("Loaded kernel module %s", name)
+	return nil
+}
+
+func (k *Kernel) ensureSysctl(name string, desired sysctl) error {
+	path := filepath.Join(k.procSysDir, sysctlPath(name))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("sysctl doesn't exist: %w", err)
+		}
+		return err
+	}
+
+	current := strings.Join(strings.Fields(string(data)), " ")
+	if desired.isSatisfiedBy(current) {
+		return nil
+	}
+
+	if err := os.WriteFile(path, []byte(desired.value), 0644); err != nil {
+		return err
+	}
+	k.log.Infof("Changed sysctl %s from %q to %q", name, current, desired.value)
+	return nil
+}
+
+func (s *sysctl) isSatisfiedBy(current string) bool {
+	desired := strings.Join(strings.Fields(s.value), " ")
+	if current == desired {
+		return true
+	}
+	if !s.atLeast {
+		return false
+	}
+
+	currentValue, err := strconv.ParseInt(current, 10, 64)
+	if err != nil {
+		return false
+	}
+	desiredValue, err := strconv.ParseInt(desired, 10, 64)
+	return err == nil && currentValue >= desiredValue
+}
+
+// sysctlPath converts the given sysctl name into its path below /proc/sys.
+// Names containing slashes are taken as paths already, which allows for
+// names with dots in them, e.g. VLAN interfaces.
+func sysctlPath(name string) string {
+	if strings.Contains(name, "/") {
+		return name
+	}
+	return strings.ReplaceAll(name, ".", "/")
+}
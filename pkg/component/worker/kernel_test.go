@@ -0,0 +1,104 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKernel_Reconcile(t *testing.T) {
+	procSysDir, sysModuleDir := t.TempDir(), t.TempDir()
+	writeSysctl := func(t *testing.T, path, value string) {
+		t.Helper()
+		path = filepath.Join(procSysDir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(value+"\n"), 0644))
+	}
+	readSysctl := func(t *testing.T, path string) string {
+		t.Helper()
+		data, err := os.ReadFile(filepath.Join(procSysDir, path))
+		require.NoError(t, err)
+		return string(data)
+	}
+
+	writeSysctl(t, "net/ipv4/conf/all/forwarding", "0")
+	writeSysctl(t, "fs/inotify/max_user_instances", "128")
+	writeSysctl(t, "fs/inotify/max_user_watches", "1048576")
+	writeSysctl(t, "net/ipv4/ip_local_port_range", "32768\t60999")
+	writeSysctl(t, "net/ipv4/conf/eth0.1/rp_filter", "1")
+	require.NoError(t, os.Mkdir(filepath.Join(sysModuleDir, "overlay"), 0755))
+
+	var loadedModules []string
+	underTest := Kernel{
+		Settings: &v1beta1.KernelSettings{
+			Sysctls: map[string]string{
+				"net.ipv4.ip_local_port_range":   "32768 60999",
+				"net/ipv4/conf/eth0.1/rp_filter": "2",
+				"fs.inotify.max_user_instances":  "256",
+			},
+			Modules: []string{"nf-nat"},
+		},
+	}
+	require.NoError(t, underTest.Init(context.TODO()))
+	underTest.procSysDir, underTest.sysModuleDir = procSysDir, sysModuleDir
+	underTest.loadModule = func(name string) error {
+		loadedModules = append(loadedModules, name)
+		return os.Mkdir(filepath.Join(sysModuleDir, strings.ReplaceAll(name, "-", "_")), 0755)
+	}
+
+	underTest.reconcile(underTest.desiredState())
+
+	assert.Equal(t, []string{"nf_conntrack", "br_netfilter", "ip_tables", "nf-nat"}, loadedModules)
+	assert.Equal(t, "1", readSysctl(t, "net/ipv4/conf/all/forwarding"))
+	assert.Equal(t, "2", readSysctl(t, "net/ipv4/conf/eth0.1/rp_filter"))
+	assert.Equal(t, "32768\t60999\n", readSysctl(t, "net/ipv4/ip_local_port_range"), "equivalent value was changed")
+	assert.Equal(t, "256", readSysctl(t, "fs/inotify/max_user_instances"), "profile didn't override default")
+	assert.Equal(t, "1048576\n", readSysctl(t, "fs/inotify/max_user_watches"), "larger value was lowered")
+	assert.Contains(t, underTest.failures, "sysctl net.ipv6.conf.all.forwarding")
+
+	t.Run("reapplies_drifted_settings", func(t *testing.T) {
+		loadedModules = nil
+		writeSysctl(t, "net/ipv4/conf/all/forwarding", "0")
+
+		underTest.reconcile(underTest.desiredState())
+
+		assert.Empty(t, loadedModules)
+		assert.Equal(t, "1", readSysctl(t, "net/ipv4/conf/all/forwarding"))
+	})
+}
+
+func TestKernel_Disabled(t *testing.T) {
+	underTest := Kernel{Settings: &v1beta1.KernelSettings{Disabled: true}}
+	require.NoError(t, underTest.Init(context.TODO()))
+	underTest.procSysDir, underTest.sysModuleDir = t.TempDir(), t.TempDir()
+	underTest.loadModule = func(name string) error {
+		assert.Fail(t, "Unexpected module load", name)
+		return nil
+	}
+
+	require.NoError(t, underTest.Start(context.TODO()))
+	assert.NoError(t, underTest.Stop())
+}
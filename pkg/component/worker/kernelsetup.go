@@ -19,6 +19,4 @@ limitations under the License.
 
 package worker
 
-// KernelSetup comment
-func KernelSetup()             {}
 func KernelMajorVersion() byte { return 0 }
@@ -33,9 +33,12 @@ import (
 	"github.com/k0sproject/k0s/internal/pkg/file"
 	"github.com/k0sproject/k0s/internal/pkg/flags"
 	"github.com/k0sproject/k0s/internal/pkg/iptablesutils"
+	"github.com/k0sproject/k0s/internal/pkg/selinux"
 	"github.com/k0sproject/k0s/internal/pkg/stringmap"
 	"github.com/k0sproject/k0s/pkg/assets"
+	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/component/manager"
+	workerconfig "github.com/k0sproject/k0s/pkg/component/worker/config"
 	"github.com/k0sproject/k0s/pkg/constant"
 	"github.com/k0sproject/k0s/pkg/supervisor"
 
@@ -64,6 +67,15 @@ type Kubelet struct {
 	Taints              []string
 	ExtraArgs           string
 	IPTablesMode        string
+	// The cluster-wide iptables settings from the worker profile. The
+	// profile's mode is used if IPTablesMode is empty.
+	IPTables workerconfig.IPTables
+	// Rootless runs kubelet in a user namespace, as needed by the
+	// experimental rootless mode.
+	Rootless bool
+	// SELinux makes sure that the kubelet root and pods directories are
+	// labeled, so that containers can access the pod volumes.
+	SELinux bool
 }
 
 var _ manager.Component = (*Kubelet)(nil)
@@ -94,20 +106,15 @@ func (k *Kubelet) Init(_ context.Context) error {
 	}
 
 	if runtime.GOOS == "linux" {
-		iptablesMode := k.IPTablesMode
-		if iptablesMode == "" || iptablesMode == "auto" {
-			var err error
-			iptablesMode, err = iptablesutils.DetectHostIPTablesMode(k.K0sVars.BinDir)
-			if err != nil {
-				if KernelMajorVersion() < 5 {
-					iptablesMode = iptablesutils.ModeLegacy
-				} else {
-					iptablesMode = iptablesutils.ModeNFT
-				}
-				logrus.WithError(err).Infof("Failed to detect iptables mode, using iptables-%s by default", iptablesMode)
-			}
+		detection := k.selectIPTablesMode()
+		iptablesMode := detection.Mode
+		logrus.Infof("using iptables-%s (%s)", iptablesMode, detection.Reason)
+		if err := dir.Init(k.K0sVars.RunDir, constant.RunDirMode); err != nil {
+			return fmt.Errorf("failed to create %s: %w", k.K0sVars.RunDir, err)
+		}
+		if err := iptablesutils.WriteDetection(k.K0sVars.RunDir, detection); err != nil {
+			logrus.WithError(err).Warn("Failed to record iptables mode")
 		}
-		logrus.Infof("using iptables-%s", iptablesMode)
 		oldpath := fmt.Sprintf("xtables-%s-multi", iptablesMode)
 		for _, symlink := range []string{"iptables", "iptables-save", "iptables-restore", "ip6tables", "ip6tables-save", "ip6tables-restore"} {
 			symlinkPath := filepath.Join(k.K0sVars.BinDir, symlink)
@@ -128,9 +135,47 @@ func (k *Kubelet) Init(_ context.Context) error {
 		return fmt.Errorf("failed to create %s: %w", k.dataDir, err)
 	}
 
+	if k.SELinux {
+		// Create the pods directory upfront, so that it can be labeled before
+		// kubelet creates any pod volumes in there.
+		podsDir := filepath.Join(k.dataDir, "pods")
+		if err := dir.Init(podsDir, constant.DataDirMode); err != nil {
+			return fmt.Errorf("failed to create %s: %w", podsDir, err)
+		}
+		if err := selinux.LabelKubeletDirs(k.dataDir); err != nil {
+			logrus.WithError(err).Warn("Failed to apply SELinux labels to the kubelet directories")
+		}
+	}
+
 	return nil
 }
 
+// selectIPTablesMode selects the iptables mode that's configured for this
+// node, either via --iptables-mode or cluster-wide. Detects the mode if it's
+// not configured or set to auto.
+func (k *Kubelet) selectIPTablesMode() *iptablesutils.Detection {
+	switch {
+	case k.IPTablesMode != "" && k.IPTablesMode != iptablesutils.ModeAuto:
+		return &iptablesutils.Detection{Mode: k.IPTablesMode, Reason: "set via --iptables-mode"}
+	case k.IPTablesMode == "" && k.IPTables.Mode != "" && k.IPTables.Mode != iptablesutils.ModeAuto:
+		return &iptablesutils.Detection{Mode: k.IPTables.Mode, Reason: "set via spec.network.iptablesMode"}
+	}
+
+	detection, err := iptablesutils.DetectHostIPTablesMode(k.K0sVars.BinDir, iptablesutils.DetectOptions{
+		KubeProxyMode: k.IPTables.KubeProxyMode,
+	})
+	if err == nil {
+		return detection
+	}
+
+	mode := iptablesutils.ModeNFT
+	if KernelMajorVersion() < 5 {
+		mode = iptablesutils.ModeLegacy
+	}
+	logrus.WithError(err).Infof("Failed to detect iptables mode, using iptables-%s by default", mode)
+	return &iptablesutils.Detection{Mode: mode, Reason: fmt.Sprintf("detection failed, default for kernel version: %v", err)}
+}
+
 // Run runs kubelet
 func (k *Kubelet) Start(ctx context.Context) error {
 	cmd := "kubelet"
@@ -144,7 +189,7 @@ func (k *Kubelet) Start(ctx context.Context) error {
 	}
 
 	kubeletConfigData := kubeletConfig{
-		ClientCAFile:       filepath.Join(k.K0sVars.CertRootDir, "ca.crt"),
+		ClientCAFile:       certificate.CABundlePath(k.K0sVars.CertRootDir),
 		VolumePluginDir:    k.K0sVars.KubeletVolumePluginDir,
 		KubeReservedCgroup: "system.slice",
 		KubeletCgroups:     "/system.slice/containerd.service",
@@ -175,23 +220,30 @@ func (k *Kubelet) Start(ctx context.Context) error {
 		if err != nil {
 			return fmt.Errorf("can't get hostname: %v", err)
 		}
+		// Windows has neither cgroups nor a resolv.conf.
 		kubeletConfigData.CgroupsPerQOS = false
 		kubeletConfigData.ResolvConf = ""
+		kubeletConfigData.KubeReservedCgroup = ""
+		kubeletConfigData.KubeletCgroups = ""
+		delete(args, "--runtime-cgroups")
 		args["--enforce-node-allocatable"] = ""
-		args["--pod-infra-container-image"] = "mcr.microsoft.com/oss/kubernetes/pause:1.4.1"
-		args["--cni-conf-dir"] = "C:\\k\\cni\\config"
 		args["--hostname-override"] = node
-		args["--hairpin-mode"] = "promiscuous-bridge"
-		args["--cert-dir"] = "C:\\var\\lib\\k0s\\kubelet_certs"
 	} else {
 		kubeletConfigData.CgroupsPerQOS = true
 		kubeletConfigData.ResolvConf = determineKubeletResolvConfPath()
 	}
 
+	if k.Rootless {
+		// The systemd slices aren't delegated to unprivileged users.
+		kubeletConfigData.KubeReservedCgroup = ""
+		kubeletConfigData.KubeletCgroups = ""
+		delete(args, "--runtime-cgroups")
+	}
+
 	if k.CRISocket == "" {
 		// Still use this deprecated cAdvisor flag that the kubelet leaks until
 		// KEP 2371 lands. ("cAdvisor-less, CRI-full Container and Pod Stats")
-		args["--containerd"] = filepath.Join(k.K0sVars.RunDir, "containerd.sock")
+		args["--containerd"] = ContainerdAddress(k.K0sVars.RunDir)
 	}
 
 	// We only support external providers
@@ -231,19 +283,26 @@ func (k *Kubelet) Stop() error {
 	return k.supervisor.Stop()
 }
 
+// Restart restarts kubelet, e.g. to make it pick up a changed kubeconfig.
+func (k *Kubelet) Restart() error {
+	if err := k.supervisor.Stop(); err != nil {
+		return err
+	}
+	return k.supervisor.Supervise()
+}
+
 func (k *Kubelet) prepareLocalKubeletConfig(kubeletConfigData kubeletConfig) (string, error) {
 	preparedConfig := k.Configuration.DeepCopy()
-	preparedConfig.Authentication.X509.ClientCAFile = kubeletConfigData.ClientCAFile // filepath.Join(k.K0sVars.CertRootDir, "ca.crt")
-	preparedConfig.VolumePluginDir = kubeletConfigData.VolumePluginDir               // k.K0sVars.KubeletVolumePluginDir
+	preparedConfig.Authentication.X509.ClientCAFile = kubeletConfigData.ClientCAFile
+	preparedConfig.VolumePluginDir = kubeletConfigData.VolumePluginDir // k.K0sVars.KubeletVolumePluginDir
 	preparedConfig.KubeReservedCgroup = kubeletConfigData.KubeReservedCgroup
 	preparedConfig.KubeletCgroups = kubeletConfigData.KubeletCgroups
 	preparedConfig.ResolverConfig = pointer.String(kubeletConfigData.ResolvConf)
 	preparedConfig.CgroupsPerQOS = pointer.Bool(kubeletConfigData.CgroupsPerQOS)
 	preparedConfig.StaticPodURL = kubeletConfigData.StaticPodURL
 
-	if k.CRISocket == "" { // This will never be true for Windows (it needs an externally managed CRI).
-		socketPath := filepath.Join(k.K0sVars.RunDir, "containerd.sock")
-		preparedConfig.ContainerRuntimeEndpoint = "unix://" + filepath.ToSlash(socketPath)
+	if k.CRISocket == "" {
+		preparedConfig.ContainerRuntimeEndpoint = ContainerdEndpoint(k.K0sVars.RunDir)
 	} else {
 		_, runtimeEndpoint, err := SplitRuntimeConfig(k.CRISocket)
 		if err != nil {
@@ -252,6 +311,13 @@ func (k *Kubelet) prepareLocalKubeletConfig(kubeletConfigData kubeletConfig) (st
 		preparedConfig.ContainerRuntimeEndpoint = runtimeEndpoint
 	}
 
+	if k.Rootless {
+		if preparedConfig.FeatureGates == nil {
+			preparedConfig.FeatureGates = make(map[string]bool)
+		}
+		preparedConfig.FeatureGates["KubeletInUserNamespace"] = true
+	}
+
 	if len(k.Taints) > 0 {
 		var taints []corev1.Taint
 		for _, taint := range k.Taints {
@@ -0,0 +1,399 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package nodeproblems detects problems on worker nodes and publishes them as
+// node conditions and events, much like node-problem-detector does, but
+// integrated into k0s.
+package nodeproblems
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	apcomm "github.com/k0sproject/k0s/pkg/autopilot/common"
+	"github.com/k0sproject/k0s/pkg/component/manager"
+	"github.com/k0sproject/k0s/pkg/component/prober"
+	"github.com/k0sproject/k0s/pkg/container/runtime"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/sirupsen/logrus"
+)
+
+// The node condition types that are managed by the detector.
+const (
+	// KernelDeadlock indicates that a task of the container runtime or of
+	// kubelet is hanging in the kernel.
+	KernelDeadlock corev1.NodeConditionType = "KernelDeadlock"
+	// ContainerRuntimeUnhealthy indicates that the container runtime can't be
+	// reached or reports itself as not ready.
+	ContainerRuntimeUnhealthy corev1.NodeConditionType = "ContainerRuntimeUnhealthy"
+	// PLEGUnhealthy indicates that kubelet's pod lifecycle event generator is
+	// stalled.
+	PLEGUnhealthy corev1.NodeConditionType = "PLEGUnhealthy"
+	// LowDiskSpace indicates that a file system monitored by k0s is running
+	// out of space. Kubelet's DiskPressure condition is based on its eviction
+	// thresholds, whereas this one is based on k0s's disk monitor.
+	LowDiskSpace corev1.NodeConditionType = "LowDiskSpace"
+)
+
+// eventSource is the component name used for the events emitted by the
+// detector.
+const eventSource = "k0s-node-problem-detector"
+
+// checkInterval is the interval in which problems are checked and the node
+// conditions are published.
+const checkInterval = 30 * time.Second
+
+// The reasons and messages of the conditions if there's no problem.
+var healthyConditions = map[corev1.NodeConditionType]problem{
+	KernelDeadlock:            {"KernelHasNoDeadlock", "kernel has no deadlock"},
+	ContainerRuntimeUnhealthy: {"ContainerRuntimeIsHealthy", "container runtime is healthy"},
+	PLEGUnhealthy:             {"PLEGIsHealthy", "PLEG is healthy"},
+	LowDiskSpace:              {"DiskSpaceIsSufficient", "all monitored file systems have sufficient free space"},
+}
+
+// DiskMonitor reports the file systems that are running out of space.
+type DiskMonitor interface {
+	LowDiskSpace() []string
+}
+
+type certManager interface {
+	GetRestConfig() (*rest.Config, error)
+}
+
+// Detector checks the node for problems in the kernel log, the container
+// runtime, kubelet's PLEG and the disk space. Active problems are published as
+// node conditions, transitions and kernel incidents as events. The detector is
+// healthy as long as there are no active problems.
+type Detector struct {
+	// The CRI endpoint of the container runtime to check. Optional.
+	CRIEndpoint string
+	// Reports the file systems that are running out of space. Optional.
+	DiskMonitor DiskMonitor
+	// Provides kubelet's credentials, which are used to publish the node
+	// conditions and events.
+	CertManager certManager
+
+	*prober.EventEmitter
+
+	log            logrus.FieldLogger
+	nodeName       string
+	now            func() time.Time
+	criStatus      func(context.Context) error
+	openKernelLog  func() (io.ReadCloser, error)
+	kernelLogAlive bool
+
+	mu             sync.Mutex
+	client         kubernetes.Interface
+	kernelDeadlock *problem
+	conditions     map[corev1.NodeConditionType]*corev1.NodeCondition
+
+	stop func()
+}
+
+var _ manager.Component = (*Detector)(nil)
+var _ prober.Healthz = (*Detector)(nil)
+
+// problem describes a detected problem, or the absence of one.
+type problem struct {
+	reason, message string
+}
+
+func (d *Detector) Init(context.Context) error {
+	d.log = logrus.WithField("component", "node-problem-detector")
+	if d.EventEmitter == nil {
+		d.EventEmitter = prober.NewEventEmitter()
+	}
+	if d.nodeName == "" {
+		nodeName, err := apcomm.FindEffectiveHostname()
+		if err != nil {
+			return fmt.Errorf("failed to determine node name: %w", err)
+		}
+		d.nodeName = nodeName
+	}
+	if d.now == nil {
+		d.now = time.Now
+	}
+	if d.criStatus == nil && d.CRIEndpoint != "" {
+		d.criStatus = runtime.NewCRIRuntime(d.CRIEndpoint).Status
+	}
+	if d.openKernelLog == nil {
+		d.openKernelLog = openKernelLog
+	}
+	d.conditions = make(map[corev1.NodeConditionType]*corev1.NodeCondition)
+	return nil
+}
+
+func (d *Detector) Start(context.Context) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+
+	if kernelLog, err := d.openKernelLog(); err != nil {
+		d.log.WithError(err).Warn("Kernel log unavailable, not monitoring it")
+	} else {
+		d.kernelLogAlive = true
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			d.watchKernelLog(ctx, kernelLog)
+		}()
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		wait.UntilWithContext(ctx, d.check, checkInterval)
+	}()
+
+	d.stop = func() { cancel(); wg.Wait() }
+	return nil
+}
+
+func (d *Detector) Stop() error {
+	if d.stop != nil {
+		d.stop()
+	}
+	return nil
+}
+
+// Healthy implements [prober.Healthz].
+func (d *Detector) Healthy(context.Context) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var problems []string
+	for conditionType, condition := range d.conditions {
+		if condition.Status == corev1.ConditionTrue {
+			problems = append(problems, fmt.Sprintf("%s: %s", conditionType, condition.Message))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("node problems detected: %s", strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// check runs all the checks and publishes the resulting node conditions.
+func (d *Detector) check(ctx context.Context) {
+	node, err := d.getNode(ctx)
+	if err != nil {
+		d.log.WithError(err).Debug("Failed to get node")
+	}
+
+	problems := map[corev1.NodeConditionType]*problem{
+		PLEGUnhealthy: checkPLEG(node),
+	}
+	if d.kernelLogAlive {
+		d.mu.Lock()
+		problems[KernelDeadlock] = d.kernelDeadlock
+		d.mu.Unlock()
+	}
+	if d.criStatus != nil {
+		problems[ContainerRuntimeUnhealthy] = d.checkContainerRuntime(ctx)
+	}
+	if d.DiskMonitor != nil {
+		problems[LowDiskSpace] = checkDiskSpace(d.DiskMonitor)
+	}
+
+	now := metav1.NewTime(d.now())
+	for conditionType, problem := range problems {
+		d.updateCondition(ctx, conditionType, problem, now)
+	}
+
+	if node != nil {
+		if err := d.publishConditions(ctx); err != nil {
+			d.log.WithError(err).Warn("Failed to publish node conditions")
+		}
+	}
+}
+
+func (d *Detector) checkContainerRuntime(ctx context.Context) *problem {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := d.criStatus(ctx); err != nil {
+		return &problem{"ContainerRuntimeIsDown", err.Error()}
+	}
+	return nil
+}
+
+// checkPLEG inspects the node's Ready condition, in which kubelet reports
+// PLEG stalls.
+func checkPLEG(node *corev1.Node) *problem {
+	if node == nil {
+		return nil
+	}
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady && condition.Status != corev1.ConditionTrue &&
+			strings.Contains(condition.Message, "PLEG is not healthy") {
+			return &problem{"PLEGIsNotHealthy", condition.Message}
+		}
+	}
+	return nil
+}
+
+func checkDiskSpace(monitor DiskMonitor) *problem {
+	if low := monitor.LowDiskSpace(); len(low) > 0 {
+		return &problem{"DiskSpaceIsLow", "running out of disk space for " + strings.Join(low, ", ")}
+	}
+	return nil
+}
+
+// updateCondition updates the given condition and emits an event whenever
+// its status changes.
+func (d *Detector) updateCondition(ctx context.Context, conditionType corev1.NodeConditionType, p *problem, now metav1.Time) {
+	status := corev1.ConditionFalse
+	current := healthyConditions[conditionType]
+	if p != nil {
+		status, current = corev1.ConditionTrue, *p
+	}
+
+	d.mu.Lock()
+	previous := d.conditions[conditionType]
+	condition := &corev1.NodeCondition{
+		Type:               conditionType,
+		Status:             status,
+		Reason:             current.reason,
+		Message:            current.message,
+		LastHeartbeatTime:  now,
+		LastTransitionTime: now,
+	}
+	if previous != nil && previous.Status == status {
+		condition.LastTransitionTime = previous.LastTransitionTime
+	}
+	d.conditions[conditionType] = condition
+	d.mu.Unlock()
+
+	switch {
+	case status == corev1.ConditionTrue && (previous == nil || previous.Status != status):
+		d.log.Warnf("Node problem detected: %s: %s", current.reason, current.message)
+		d.recordEvent(ctx, corev1.EventTypeWarning, current.reason, current.message)
+	case status == corev1.ConditionFalse && previous != nil && previous.Status != status:
+		d.log.Infof("Node problem resolved: %s", conditionType)
+		d.recordEvent(ctx, corev1.EventTypeNormal, current.reason, current.message)
+	}
+}
+
+// publishConditions patches the node's status with the current conditions.
+// Conditions of other types are left as is, as they're merged by type.
+func (d *Detector) publishConditions(ctx context.Context) error {
+	client, err := d.getClient()
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	conditions := make([]corev1.NodeCondition, 0, len(d.conditions))
+	for _, condition := range d.conditions {
+		conditions = append(conditions, *condition)
+	}
+	d.mu.Unlock()
+	sort.Slice(conditions, func(i, j int) bool { return conditions[i].Type < conditions[j].Type })
+
+	patch, err := json.Marshal(map[string]any{
+		"status": map[string]any{"conditions": conditions},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = client.CoreV1().Nodes().PatchStatus(ctx, d.nodeName, patch)
+	return err
+}
+
+// recordEvent emits the event to the prober and creates it on the node.
+func (d *Detector) recordEvent(ctx context.Context, eventType, reason, message string) {
+	d.EmitWithPayload(fmt.Sprintf("%s: %s", reason, message), eventType)
+
+	client, err := d.getClient()
+	if err != nil {
+		d.log.WithError(err).Debug("Not recording event")
+		return
+	}
+
+	now := metav1.NewTime(d.now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%s.%x", d.nodeName, strings.ToLower(reason), now.UnixNano()),
+			Namespace: metav1.NamespaceDefault,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			Kind: "Node",
+			Name: d.nodeName,
+			// Kubelet uses the node name as the UID for node events, too.
+			UID: types.UID(d.nodeName),
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: eventSource, Host: d.nodeName},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if _, err := client.CoreV1().Events(metav1.NamespaceDefault).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		d.log.WithError(err).Warn("Failed to record event")
+	}
+}
+
+// getNode returns the node object of this worker. Returns nil if the node
+// hasn't been registered by kubelet yet.
+func (d *Detector) getNode(ctx context.Context) (*corev1.Node, error) {
+	client, err := d.getClient()
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := client.CoreV1().Nodes().Get(ctx, d.nodeName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	return node, err
+}
+
+func (d *Detector) getClient() (kubernetes.Interface, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client == nil {
+		restConfig, err := d.CertManager.GetRestConfig()
+		if err != nil {
+			return nil, err
+		}
+		client, err := kubernetes.NewForConfig(restConfig)
+		if err != nil {
+			return nil, err
+		}
+		d.client = client
+	}
+
+	return d.client, nil
+}
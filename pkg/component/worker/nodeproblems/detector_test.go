@@ -0,0 +1,173 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeproblems
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/k0sproject/k0s/pkg/component/prober"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDiskMonitor []string
+
+func (f *fakeDiskMonitor) LowDiskSpace() []string { return *f }
+
+func TestDetector_Check(t *testing.T) {
+	ctx := context.TODO()
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker"},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue, Reason: "KubeletReady"},
+			},
+		},
+	}
+	client := fake.NewSimpleClientset(node)
+
+	var criErr error
+	var lowDiskSpace fakeDiskMonitor
+	now := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	underTest := &Detector{
+		DiskMonitor:   &lowDiskSpace,
+		EventEmitter:  prober.NewEventEmitter(),
+		log:           logrus.New(),
+		nodeName:      "worker",
+		now:           func() time.Time { return now },
+		criStatus:     func(context.Context) error { return criErr },
+		client:        client,
+		conditions:    make(map[corev1.NodeConditionType]*corev1.NodeCondition),
+		openKernelLog: func() (io.ReadCloser, error) { return nil, errors.New("unsupported") },
+	}
+
+	conditionOf := func(t *testing.T, conditionType corev1.NodeConditionType) *corev1.NodeCondition {
+		node, err := client.CoreV1().Nodes().Get(ctx, "worker", metav1.GetOptions{})
+		require.NoError(t, err)
+		for i := range node.Status.Conditions {
+			if node.Status.Conditions[i].Type == conditionType {
+				return &node.Status.Conditions[i]
+			}
+		}
+		return nil
+	}
+
+	t.Run("healthy", func(t *testing.T) {
+		underTest.check(ctx)
+
+		assert.NoError(t, underTest.Healthy(ctx))
+		for _, conditionType := range []corev1.NodeConditionType{ContainerRuntimeUnhealthy, PLEGUnhealthy, LowDiskSpace} {
+			if condition := conditionOf(t, conditionType); assert.NotNil(t, condition, "%s not published", conditionType) {
+				assert.Equal(t, corev1.ConditionFalse, condition.Status)
+			}
+		}
+		assert.Nil(t, conditionOf(t, KernelDeadlock), "Kernel log isn't monitored")
+		if ready := conditionOf(t, corev1.NodeReady); assert.NotNil(t, ready, "Ready condition got lost") {
+			assert.Equal(t, "KubeletReady", ready.Reason)
+		}
+		assert.Empty(t, underTest.Events())
+	})
+
+	t.Run("problems", func(t *testing.T) {
+		now = now.Add(checkInterval)
+		criErr = errors.New("connection refused")
+		lowDiskSpace = fakeDiskMonitor{"containerd"}
+		underTest.check(ctx)
+
+		if condition := conditionOf(t, ContainerRuntimeUnhealthy); assert.NotNil(t, condition) {
+			assert.Equal(t, corev1.ConditionTrue, condition.Status)
+			assert.Equal(t, "ContainerRuntimeIsDown", condition.Reason)
+			assert.Equal(t, "connection refused", condition.Message)
+			assert.Equal(t, now, condition.LastTransitionTime.Time.UTC())
+		}
+		if condition := conditionOf(t, LowDiskSpace); assert.NotNil(t, condition) {
+			assert.Equal(t, corev1.ConditionTrue, condition.Status)
+			assert.Equal(t, "running out of disk space for containerd", condition.Message)
+		}
+
+		err := underTest.Healthy(ctx)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "ContainerRuntimeUnhealthy: connection refused")
+			assert.Contains(t, err.Error(), "LowDiskSpace: running out of disk space for containerd")
+		}
+
+		events, err := client.CoreV1().Events(metav1.NamespaceDefault).List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		var reasons []string
+		for _, event := range events.Items {
+			assert.Equal(t, corev1.EventTypeWarning, event.Type)
+			assert.Equal(t, "Node", event.InvolvedObject.Kind)
+			assert.Equal(t, "worker", event.InvolvedObject.Name)
+			reasons = append(reasons, event.Reason)
+		}
+		assert.ElementsMatch(t, []string{"ContainerRuntimeIsDown", "DiskSpaceIsLow"}, reasons)
+	})
+
+	t.Run("recovery", func(t *testing.T) {
+		transitionTime := now
+		now = now.Add(checkInterval)
+		criErr = nil
+		underTest.check(ctx)
+
+		if condition := conditionOf(t, ContainerRuntimeUnhealthy); assert.NotNil(t, condition) {
+			assert.Equal(t, corev1.ConditionFalse, condition.Status)
+			assert.Equal(t, "ContainerRuntimeIsHealthy", condition.Reason)
+		}
+		if condition := conditionOf(t, LowDiskSpace); assert.NotNil(t, condition) {
+			assert.Equal(t, corev1.ConditionTrue, condition.Status)
+			assert.Equal(t, transitionTime, condition.LastTransitionTime.Time.UTC())
+			assert.Equal(t, now, condition.LastHeartbeatTime.Time.UTC())
+		}
+	})
+}
+
+func TestDetector_KernelDeadlock(t *testing.T) {
+	ctx := context.TODO()
+	underTest := &Detector{
+		EventEmitter:   prober.NewEventEmitter(),
+		log:            logrus.New(),
+		nodeName:       "worker",
+		now:            time.Now,
+		client:         fake.NewSimpleClientset(),
+		conditions:     make(map[corev1.NodeConditionType]*corev1.NodeCondition),
+		kernelLogAlive: true,
+	}
+
+	underTest.handleKernelMessage(ctx, "Killed process 4711 (java) total-vm:1024kB, anon-rss:512kB, file-rss:0kB")
+	assert.Equal(t, "OOMKilling: Killed process 4711 (java) total-vm:1024kB, anon-rss:512kB, file-rss:0kB", (<-underTest.Events()).Message)
+	underTest.check(ctx)
+	assert.NoError(t, underTest.Healthy(ctx))
+
+	underTest.handleKernelMessage(ctx, "INFO: task containerd:1234 blocked for more than 120 seconds.")
+	underTest.check(ctx)
+	err := underTest.Healthy(ctx)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "KernelDeadlock: INFO: task containerd:1234 blocked for more than 120 seconds.")
+	}
+	assert.Equal(t, "ContainerRuntimeHung: INFO: task containerd:1234 blocked for more than 120 seconds.", (<-underTest.Events()).Message)
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeproblems
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"syscall"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// kernelRule matches kernel log messages that indicate a problem.
+type kernelRule struct {
+	reason  string
+	pattern *regexp.Regexp
+	// Indicates that the problem is permanent, i.e. it's reported as the
+	// KernelDeadlock condition until k0s restarts, usually after a reboot.
+	deadlock bool
+}
+
+// kernelRules are checked in order, the first matching one wins. They're
+// modeled after node-problem-detector's kernel monitor rules.
+var kernelRules = []kernelRule{
+	{"ContainerRuntimeHung", regexp.MustCompile(`task (containerd|containerd-shim|runc)\S*:\w+ blocked for more than \w+ seconds\.`), true},
+	{"KubeletHung", regexp.MustCompile(`task kubelet:\w+ blocked for more than \w+ seconds\.`), true},
+	{"TaskHung", regexp.MustCompile(`task \S+:\w+ blocked for more than \w+ seconds\.`), false},
+	{"OOMKilling", regexp.MustCompile(`(Killed process \d+|Out of memory: Kill(ed)? process \d+)`), false},
+	{"KernelOops", regexp.MustCompile(`BUG: unable to handle kernel (NULL pointer dereference|paging request)`), false},
+	{"KernelOops", regexp.MustCompile(`divide error: 0000 \[#\d+\] SMP`), false},
+	{"UnregisterNetDevice", regexp.MustCompile(`unregister_netdevice: waiting for \w+ to become free\. Usage count = \d+`), false},
+	{"Ext4Error", regexp.MustCompile(`EXT4-fs error`), false},
+	{"IOError", regexp.MustCompile(`Buffer I/O error`), false},
+	{"MemoryReadError", regexp.MustCompile(`CE memory read error`), false},
+}
+
+// matchKernelMessage returns the first rule matching the given message.
+func matchKernelMessage(message string) (*kernelRule, bool) {
+	for i := range kernelRules {
+		if kernelRules[i].pattern.MatchString(message) {
+			return &kernelRules[i], true
+		}
+	}
+	return nil, false
+}
+
+// parseKmsgRecord extracts the message from a /dev/kmsg record, which looks
+// like "6,339,5140900,-;message\n KEY=value\n". See
+// https://www.kernel.org/doc/Documentation/ABI/testing/dev-kmsg.
+func parseKmsgRecord(record []byte) (string, bool) {
+	_, message, ok := bytes.Cut(record, []byte{';'})
+	if !ok {
+		return "", false
+	}
+	message, _, _ = bytes.Cut(message, []byte{'\n'})
+	return string(message), true
+}
+
+// kmsgRecordSize is the buffer size used to read kernel log records. Records
+// are read one at a time, and truncated by the kernel if they're too large.
+const kmsgRecordSize = 8192
+
+// watchKernelLog reads the kernel log until the given context is done and
+// reports the messages matching any of the kernel rules.
+func (d *Detector) watchKernelLog(ctx context.Context, kernelLog io.ReadCloser) {
+	go func() { <-ctx.Done(); _ = kernelLog.Close() }()
+
+	buf := make([]byte, kmsgRecordSize)
+	for {
+		n, err := kernelLog.Read(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			if errors.Is(err, syscall.EPIPE) {
+				// Records got overwritten before they could be read.
+				continue
+			}
+			d.log.WithError(err).Error("Failed to read kernel log")
+			return
+		}
+
+		if message, ok := parseKmsgRecord(buf[:n]); ok {
+			d.handleKernelMessage(ctx, message)
+		}
+	}
+}
+
+func (d *Detector) handleKernelMessage(ctx context.Context, message string) {
+	rule, ok := matchKernelMessage(message)
+	if !ok {
+		return
+	}
+
+	if rule.deadlock {
+		// Reported via the KernelDeadlock condition with the next check.
+		d.mu.Lock()
+		if d.kernelDeadlock == nil {
+			d.kernelDeadlock = &problem{rule.reason, message}
+		}
+		d.mu.Unlock()
+		return
+	}
+
+	d.log.Warnf("Kernel problem detected: %s: %s", rule.reason, message)
+	d.recordEvent(ctx, corev1.EventTypeWarning, rule.reason, message)
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package nodeproblems
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseKmsgRecord(t *testing.T) {
+	message, ok := parseKmsgRecord([]byte("6,339,5140900,-;NET: Registered protocol family 10\n SUBSYSTEM=net\n"))
+	assert.True(t, ok)
+	assert.Equal(t, "NET: Registered protocol family 10", message)
+
+	message, ok = parseKmsgRecord([]byte("3,340,5140901,c;no trailing newline"))
+	assert.True(t, ok)
+	assert.Equal(t, "no trailing newline", message)
+
+	_, ok = parseKmsgRecord([]byte("garbage"))
+	assert.False(t, ok)
+}
+
+func TestMatchKernelMessage(t *testing.T) {
+	for _, test := range []struct {
+		message  string
+		reason   string
+		deadlock bool
+	}{
+		{"INFO: task containerd:1234 blocked for more than 120 seconds.", "ContainerRuntimeHung", true},
+		{"INFO: task kubelet:4321 blocked for more than 120 seconds.", "KubeletHung", true},
+		{"INFO: task jbd2/sda1-8:123 blocked for more than 120 seconds.", "TaskHung", false},
+		{"Memory cgroup out of memory: Killed process 4711 (java) total-vm:1024kB, anon-rss:512kB, file-rss:0kB", "OOMKilling", false},
+		{"BUG: unable to handle kernel NULL pointer dereference at 0000000000000008", "KernelOops", false},
+		{"unregister_netdevice: waiting for eth0 to become free. Usage count = 1", "UnregisterNetDevice", false},
+		{"EXT4-fs error (device sda1): ext4_find_entry:1455: inode #2", "Ext4Error", false},
+		{"Buffer I/O error on dev sdb, logical block 0, async page read", "IOError", false},
+	} {
+		t.Run(test.reason, func(t *testing.T) {
+			rule, ok := matchKernelMessage(test.message)
+			if assert.True(t, ok) {
+				assert.Equal(t, test.reason, rule.reason)
+				assert.Equal(t, test.deadlock, rule.deadlock)
+			}
+		})
+	}
+
+	_, ok := matchKernelMessage("NET: Registered protocol family 10")
+	assert.False(t, ok)
+}
@@ -20,7 +20,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/avast/retry-go"
@@ -66,7 +65,7 @@ func (a *OCIBundleReconciler) Start(ctx context.Context) error {
 		return nil
 	}
 	var client *containerd.Client
-	sock := filepath.Join(a.k0sVars.RunDir, "containerd.sock")
+	sock := ContainerdAddress(a.k0sVars.RunDir)
 	err = retry.Do(func() error {
 		client, err = containerd.New(sock, containerd.WithDefaultNamespace("k8s.io"), containerd.WithDefaultPlatform(platforms.OnlyStrict(platforms.DefaultSpec())))
 		if err != nil {
@@ -0,0 +1,32 @@
+//go:build !linux
+// +build !linux
+
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/k0sproject/k0s/pkg/constant"
+)
+
+// EnterRootlessNamespaces is only supported on Linux.
+func EnterRootlessNamespaces(constant.CfgVars) error {
+	return fmt.Errorf("rootless mode is not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,84 @@
+//go:build linux
+// +build linux
+
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"syscall"
+
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"github.com/sirupsen/logrus"
+)
+
+// rootlesskitStateDirEnv is set by rootlesskit for the processes it spawns.
+const rootlesskitStateDirEnv = "ROOTLESSKIT_STATE_DIR"
+
+// EnterRootlessNamespaces makes sure that k0s runs inside the user, mount and
+// network namespaces set up by rootlesskit. If it's not already running in
+// there, the current process is replaced by rootlesskit, which in turn starts
+// k0s again with the same arguments inside the namespaces. Inside the
+// namespaces, /etc and /run are writable copies of the host's directories.
+func EnterRootlessNamespaces(k0sVars constant.CfgVars) error {
+	if os.Getenv(rootlesskitStateDirEnv) != "" {
+		logrus.Info("Running in rootless mode")
+		return nil
+	}
+
+	if os.Geteuid() == 0 {
+		return errors.New("rootless mode needs to be run as an unprivileged user")
+	}
+
+	// The data directory isn't copied into the namespaces, as its contents
+	// need to survive restarts.
+	if err := dir.Init(k0sVars.DataDir, constant.DataDirMode); err != nil {
+		return fmt.Errorf("rootless mode needs a data directory that is writable by the current user, use --data-dir: %w", err)
+	}
+
+	rootlesskit, err := exec.LookPath("rootlesskit")
+	if err != nil {
+		return fmt.Errorf("rootlesskit is required in rootless mode: %w", err)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	args := []string{
+		rootlesskit,
+		"--net=slirp4netns",
+		"--mtu=65520",
+		"--disable-host-loopback",
+		"--port-driver=builtin",
+		"--copy-up=/etc",
+		"--copy-up=/run",
+		"--propagation=rslave",
+		"--",
+		self,
+	}
+	args = append(args, os.Args[1:]...)
+
+	logrus.Infof("Re-executing k0s worker in rootless mode using %s", rootlesskit)
+	return syscall.Exec(rootlesskit, args, os.Environ())
+}
@@ -52,6 +52,19 @@ func BootstrapKubeletKubeconfig(ctx context.Context, k0sVars constant.CfgVars, w
 	// needs to be ignored if it has already been used. This results in the
 	// following order of precedence:
 
+	// 0: The join token is to be fetched from a URL.
+	// It's only fetched if there's no other way to bootstrap the kubelet
+	// kubeconfig, as the token might not be valid anymore.
+	if workerOpts.TokenArg == "" && workerOpts.TokenURL != "" &&
+		!file.Exists(k0sVars.KubeletAuthConfigPath) && !file.Exists(bootstrapKubeconfigPath) {
+		logrus.Info("Fetching join token from ", workerOpts.TokenURL)
+		joinToken, err := token.FetchJoinToken(ctx, workerOpts.TokenURL, workerOpts.TokenURLAuthHeaderFile)
+		if err != nil {
+			return err
+		}
+		workerOpts.TokenArg = joinToken
+	}
+
 	var bootstrapKubeconfig *clientcmdapi.Config
 	switch {
 	// 1: Regular kubelet kubeconfig file exists.
@@ -19,13 +19,15 @@ package config
 import (
 	"fmt"
 	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	aproot "github.com/k0sproject/k0s/pkg/autopilot/controller/root"
+	"github.com/k0sproject/k0s/pkg/autopilot/updater"
+	"github.com/k0sproject/k0s/pkg/component/controller/clusterconfig"
+	"github.com/k0sproject/k0s/pkg/component/diskmonitor"
 	"github.com/k0sproject/k0s/pkg/component/manager"
 	"github.com/k0sproject/k0s/pkg/constant"
 	"github.com/k0sproject/k0s/pkg/k0scloudprovider"
@@ -34,6 +36,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"golang.org/x/exp/slices"
+	corev1 "k8s.io/api/core/v1"
 )
 
 var (
@@ -41,6 +44,7 @@ var (
 	DataDir        string
 	Debug          bool
 	DebugListenOn  string
+	LogFormat      string
 	StatusSocket   string
 	K0sVars        constant.CfgVars
 	workerOpts     WorkerOptions
@@ -71,31 +75,51 @@ type ControllerOptions struct {
 	NoTaints          bool
 	DisableComponents []string
 
-	ClusterComponents               *manager.Manager
-	EnableK0sCloudProvider          bool
-	K0sCloudProviderPort            int
-	K0sCloudProviderUpdateFrequency time.Duration
-	NodeComponents                  *manager.Manager
-	EnableDynamicConfig             bool
-	EnableMetricsScraper            bool
-	KubeControllerManagerExtraArgs  string
+	ClusterComponents                  *manager.Manager
+	EnableK0sCloudProvider             bool
+	K0sCloudProviderPort               int
+	K0sCloudProviderUpdateFrequency    time.Duration
+	K0sCloudProviderAddressAnnotations map[string]string
+	K0sCloudProviderAddressTypes       []string
+	K0sCloudProviderIDTemplate         string
+	K0sCloudProviderTopologyLabels     bool
+	NodeComponents                     *manager.Manager
+	EnableDynamicConfig                bool
+	ConfigSource                       string
+	EnableMetricsScraper               bool
+	KubeControllerManagerExtraArgs     string
+	TracingEndpoint                    string
+	WriteStartupProfile                bool
+	UpdateCheckInterval                time.Duration
+	UpdateCheckChannel                 string
+	UpdateCheckServer                  string
+	UpdateCheckIndex                   string
 }
 
 // Shared worker cli flags
 type WorkerOptions struct {
-	APIServer        string
-	CIDRRange        string
-	CloudProvider    bool
-	ClusterDNS       string
-	CmdLogLevels     map[string]string
-	CriSocket        string
-	KubeletExtraArgs string
-	Labels           []string
-	Taints           []string
-	TokenFile        string
-	TokenArg         string
-	WorkerProfile    string
-	IPTablesMode     string
+	CloudProvider          bool
+	CmdLogLevels           map[string]string
+	CriSocket              string
+	KubeletExtraArgs       string
+	Labels                 []string
+	Taints                 []string
+	TokenFile              string
+	TokenArg               string
+	TokenURL               string
+	TokenURLAuthHeaderFile string
+	WorkerProfile          string
+	IPTablesMode           string
+	Rootless               bool
+	MinFreeDiskPercent     uint
+	ReclaimDiskSpace       bool
+	ComponentTimeout       time.Duration
+	ComponentTimeoutPolicy string
+	ComponentStopTimeout   time.Duration
+
+	// Runs the node problem detector, which publishes node conditions and
+	// events about problems on the worker.
+	EnableNodeProblemDetector bool
 }
 
 func (o *ControllerOptions) Normalize() error {
@@ -130,9 +154,69 @@ func (o *ControllerOptions) Normalize() error {
 	}
 	o.DisableComponents = disabledComponents
 
+	// The static source is the only one that doesn't read the cluster config
+	// from the API, which is only stored there if dynamic config is enabled.
+	switch {
+	case o.ConfigSource == "":
+		o.ConfigSource = clusterconfig.StaticSourceName
+		if o.EnableDynamicConfig {
+			o.ConfigSource = clusterconfig.APISourceName
+		}
+	case o.ConfigSource == clusterconfig.StaticSourceName && o.EnableDynamicConfig:
+		return fmt.Errorf("config source %q can't be used with --enable-dynamic-config", o.ConfigSource)
+	case o.ConfigSource != clusterconfig.StaticSourceName && !o.EnableDynamicConfig:
+		return fmt.Errorf("config source %q requires --enable-dynamic-config", o.ConfigSource)
+	}
+
+	if o.EnableK0sCloudProvider {
+		if _, err := o.K0sCloudProviderConfig(""); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// K0sCloudProviderConfig returns the k0s-cloud-provider config as specified
+// by the command line flags.
+func (o *ControllerOptions) K0sCloudProviderConfig(kubeConfigPath string) (k0scloudprovider.Config, error) {
+	annotations := make(map[string]corev1.NodeAddressType, len(o.K0sCloudProviderAddressAnnotations))
+	for annotation, addressType := range o.K0sCloudProviderAddressAnnotations {
+		t, err := k0scloudprovider.ParseAddressType(addressType)
+		if err != nil {
+			return k0scloudprovider.Config{}, fmt.Errorf("invalid k0s-cloud-provider address annotation %q: %w", annotation, err)
+		}
+		annotations[annotation] = t
+	}
+
+	var addressTypes []corev1.NodeAddressType
+	for _, addressType := range o.K0sCloudProviderAddressTypes {
+		t, err := k0scloudprovider.ParseAddressType(addressType)
+		if err != nil {
+			return k0scloudprovider.Config{}, fmt.Errorf("invalid k0s-cloud-provider address types: %w", err)
+		}
+		addressTypes = append(addressTypes, t)
+	}
+
+	config := k0scloudprovider.Config{
+		AddressCollector: k0scloudprovider.NewAddressCollector(annotations, addressTypes),
+		TopologyLabels:   o.K0sCloudProviderTopologyLabels,
+		KubeConfig:       kubeConfigPath,
+		BindPort:         o.K0sCloudProviderPort,
+		UpdateFrequency:  o.K0sCloudProviderUpdateFrequency,
+	}
+
+	if o.K0sCloudProviderIDTemplate != "" {
+		providerID, err := k0scloudprovider.NewProviderIDTemplate(o.K0sCloudProviderIDTemplate)
+		if err != nil {
+			return k0scloudprovider.Config{}, fmt.Errorf("invalid k0s-cloud-provider provider ID template: %w", err)
+		}
+		config.ProviderID = providerID
+	}
+
+	return config, nil
+}
+
 func DefaultLogLevels() map[string]string {
 	return map[string]string{
 		"etcd":                    "info",
@@ -151,8 +235,9 @@ func GetPersistentFlagSet() *pflag.FlagSet {
 	flagset.BoolVarP(&Debug, "debug", "d", false, "Debug logging (default: false)")
 	flagset.BoolVarP(&Verbose, "verbose", "v", false, "Verbose logging (default: false)")
 	flagset.StringVar(&DataDir, "data-dir", "", "Data Directory for k0s (default: /var/lib/k0s). DO NOT CHANGE for an existing setup, things will break!")
-	flagset.StringVar(&StatusSocket, "status-socket", filepath.Join(K0sVars.RunDir, "status.sock"), "Full file path to the socket file.")
+	flagset.StringVar(&StatusSocket, "status-socket", constant.StatusSocketPath(K0sVars.RunDir), "Full file path to the socket file.")
 	flagset.StringVar(&DebugListenOn, "debugListenOn", ":6060", "Http listenOn for Debug pprof handler")
+	flagset.StringVar(&LogFormat, "log-format", "text", "Log format, either text or json")
 	return flagset
 }
 
@@ -180,35 +265,56 @@ func GetWorkerFlags() *pflag.FlagSet {
 	flagset := &pflag.FlagSet{}
 
 	flagset.StringVar(&workerOpts.WorkerProfile, "profile", "default", "worker profile to use on the node")
-	flagset.StringVar(&workerOpts.APIServer, "api-server", "", "HACK: api-server for the windows worker node")
-	flagset.StringVar(&workerOpts.CIDRRange, "cidr-range", "10.96.0.0/12", "HACK: cidr range for the windows worker node")
-	flagset.StringVar(&workerOpts.ClusterDNS, "cluster-dns", "10.96.0.10", "HACK: cluster dns for the windows worker node")
 	flagset.BoolVar(&workerOpts.CloudProvider, "enable-cloud-provider", false, "Whether or not to enable cloud provider support in kubelet")
 	flagset.StringVar(&workerOpts.TokenFile, "token-file", "", "Path to the file containing token.")
+	flagset.AddFlagSet(tokenURLFlags())
 	flagset.StringToStringVarP(&workerOpts.CmdLogLevels, "logging", "l", DefaultLogLevels(), "Logging Levels for the different components")
 	flagset.StringSliceVarP(&workerOpts.Labels, "labels", "", []string{}, "Node labels, list of key=value pairs")
 	flagset.StringSliceVarP(&workerOpts.Taints, "taints", "", []string{}, "Node taints, list of key=value:effect strings")
 	flagset.StringVar(&workerOpts.KubeletExtraArgs, "kubelet-extra-args", "", "extra args for kubelet")
 	flagset.StringVar(&workerOpts.IPTablesMode, "iptables-mode", "", "iptables mode (valid values: nft, legacy, auto). default: auto")
+	flagset.BoolVar(&workerOpts.Rootless, "rootless", false, "EXPERIMENTAL: run the worker as an unprivileged user in a user namespace")
+	flagset.UintVar(&workerOpts.MinFreeDiskPercent, "min-free-disk-percent", diskmonitor.DefaultMinFreePercent, "percentage of free disk space and inodes below which k0s warns about running out of disk space")
+	flagset.BoolVar(&workerOpts.ReclaimDiskSpace, "reclaim-disk-space", false, "prune unused images and compact etcd when running out of disk space")
+	flagset.BoolVar(&workerOpts.EnableNodeProblemDetector, "enable-node-problem-detector", false, "publish node conditions and events about kernel, container runtime, PLEG and disk space problems")
+	flagset.DurationVar(&workerOpts.ComponentTimeout, "component-timeout", 10*time.Minute, "the time each component may take to initialize or to start, 0 to disable")
+	flagset.StringVar(&workerOpts.ComponentTimeoutPolicy, "component-timeout-policy", string(manager.TimeoutPolicyContinue), "what to do if a component exceeds --component-timeout after logging diagnostics (valid values: continue, fail)")
+	flagset.DurationVar(&workerOpts.ComponentStopTimeout, "component-stop-timeout", 1*time.Minute, "the time each component may take to finish in-flight operations and to stop, 0 to disable")
 	flagset.AddFlagSet(GetCriSocketFlag())
 
+	// Windows workers used to be bootstrapped via these flags. They're
+	// accepted for compatibility only, as the networking configuration is
+	// now taken from the cluster.
+	var obsoleteWindowsFlag string
+	for _, name := range []string{"api-server", "cidr-range", "cluster-dns"} {
+		flagset.StringVar(&obsoleteWindowsFlag, name, "", "")
+		_ = flagset.MarkDeprecated(name, "it has no effect, Windows workers take their networking configuration from the cluster")
+	}
+
 	return flagset
 }
 
 var availableComponents = []string{
 	constant.AutopilotComponentName,
+	constant.CloudProviderComponentName,
+	constant.ClusterConfigWebhookComponentName,
 	constant.ControlAPIComponentName,
 	constant.CoreDNSComponentname,
 	constant.CsrApproverComponentName,
+	constant.APIEndpointHealthComponentName,
 	constant.APIEndpointReconcilerComponentName,
 	constant.HelmComponentName,
+	constant.IngressComponentName,
 	constant.KonnectivityServerComponentName,
 	constant.KubeControllerManagerComponentName,
 	constant.KubeProxyComponentName,
 	constant.KubeSchedulerComponentName,
 	constant.MetricsServerComponentName,
 	constant.NetworkProviderComponentName,
+	constant.NodeLocalDNSComponentName,
 	constant.NodeRoleComponentName,
+	constant.ServiceLoadBalancerComponentName,
+	constant.SnapshotControllerComponentName,
 	constant.SystemRbacComponentName,
 	constant.WorkerConfigComponentName,
 }
@@ -220,20 +326,41 @@ func GetControllerFlags() *pflag.FlagSet {
 	flagset.BoolVar(&controllerOpts.EnableWorker, "enable-worker", false, "enable worker (default false)")
 	flagset.StringSliceVar(&controllerOpts.DisableComponents, "disable-components", []string{}, "disable components (valid items: "+strings.Join(availableComponents, ",")+")")
 	flagset.StringVar(&workerOpts.TokenFile, "token-file", "", "Path to the file containing join-token.")
+	flagset.AddFlagSet(tokenURLFlags())
 	flagset.StringToStringVarP(&workerOpts.CmdLogLevels, "logging", "l", DefaultLogLevels(), "Logging Levels for the different components")
 	flagset.BoolVar(&controllerOpts.SingleNode, "single", false, "enable single node (implies --enable-worker, default false)")
 	flagset.BoolVar(&controllerOpts.NoTaints, "no-taints", false, "disable default taints for controller node")
 	flagset.BoolVar(&controllerOpts.EnableK0sCloudProvider, "enable-k0s-cloud-provider", false, "enables the k0s-cloud-provider (default false)")
 	flagset.DurationVar(&controllerOpts.K0sCloudProviderUpdateFrequency, "k0s-cloud-provider-update-frequency", 2*time.Minute, "the frequency of k0s-cloud-provider node updates")
 	flagset.IntVar(&controllerOpts.K0sCloudProviderPort, "k0s-cloud-provider-port", k0scloudprovider.DefaultBindPort, "the port that k0s-cloud-provider binds on")
+	flagset.StringToStringVar(&controllerOpts.K0sCloudProviderAddressAnnotations, "k0s-cloud-provider-address-annotations", map[string]string{k0scloudprovider.ExternalIPAnnotation: string(corev1.NodeExternalIP)}, "the node annotations from which k0s-cloud-provider takes node addresses, along with the address type")
+	flagset.StringSliceVar(&controllerOpts.K0sCloudProviderAddressTypes, "k0s-cloud-provider-address-types", nil, "the order in which k0s-cloud-provider reports node addresses by type, unlisted types are reported last")
+	flagset.StringVar(&controllerOpts.K0sCloudProviderIDTemplate, "k0s-cloud-provider-provider-id-template", "", "Go template used by k0s-cloud-provider to generate provider IDs for new nodes, e.g. 'k0s://{{ .Name }}'")
+	flagset.BoolVar(&controllerOpts.K0sCloudProviderTopologyLabels, "k0s-cloud-provider-topology-labels", false, "set the region and zone labels of new nodes from their "+k0scloudprovider.RegionLabel+" and "+k0scloudprovider.ZoneLabel+" annotations or labels")
 	flagset.AddFlagSet(GetCriSocketFlag())
 	flagset.BoolVar(&controllerOpts.EnableDynamicConfig, "enable-dynamic-config", false, "enable cluster-wide dynamic config based on custom resource")
+	flagset.StringVar(&controllerOpts.ConfigSource, "config-source", "", "the source of the cluster config, e.g. static, api or composite (default: api if dynamic config is enabled, static otherwise)")
 	flagset.BoolVar(&controllerOpts.EnableMetricsScraper, "enable-metrics-scraper", false, "enable scraping metrics from the controller components (kube-scheduler, kube-controller-manager)")
 	flagset.StringVar(&controllerOpts.KubeControllerManagerExtraArgs, "kube-controller-manager-extra-args", "", "extra args for kube-controller-manager")
+	flagset.StringVar(&controllerOpts.TracingEndpoint, "tracing-endpoint", "", "OTLP/gRPC endpoint to export traces to, e.g. localhost:4317 or http://localhost:4317 to disable TLS (default: OTEL_EXPORTER_OTLP_ENDPOINT, tracing is disabled if neither is set)")
+	flagset.BoolVar(&controllerOpts.WriteStartupProfile, "write-startup-profile", false, "write the checkpoints recorded during startup as JSON to startup-profile.json in the run directory")
+	flagset.DurationVar(&controllerOpts.UpdateCheckInterval, "update-check-interval", 0, "the interval in which to check for newer k0s versions, 0 to disable")
+	flagset.StringVar(&controllerOpts.UpdateCheckChannel, "update-check-channel", "stable", "the release channel to check for newer k0s versions")
+	flagset.StringVar(&controllerOpts.UpdateCheckServer, "update-check-server", updater.DefaultUpdateServer, "the URL of the update server to check for newer k0s versions")
+	flagset.StringVar(&controllerOpts.UpdateCheckIndex, "update-check-index", "", "the path to a local JSON index of the versions per channel, to be used instead of the update server")
 	flagset.AddFlagSet(FileInputFlag())
 	return flagset
 }
 
+// tokenURLFlags returns the flags to fetch the join-token from a URL. They're
+// shared between the worker and controller flags.
+func tokenURLFlags() *pflag.FlagSet {
+	flagset := &pflag.FlagSet{}
+	flagset.StringVar(&workerOpts.TokenURL, "token-url", "", "URL to fetch the join-token from when it's needed for the first time")
+	flagset.StringVar(&workerOpts.TokenURLAuthHeaderFile, "token-url-auth-header-file", "", "Path to a file containing an HTTP header (\"Name: value\") to send along when fetching the join-token")
+	return flagset
+}
+
 // The config flag used to be a persistent, joint flag to all commands
 // now only a few commands use it. This function helps to share the flag with multiple commands without needing to define
 // it in multiple places
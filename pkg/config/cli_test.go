@@ -70,4 +70,38 @@ func TestControllerOptions_Normalize(t *testing.T) {
 		require.NoError(t, err)
 		assert.Equal(t, test.expected, underTest.DisableComponents)
 	}
+
+	for _, test := range []struct {
+		name          string
+		source        string
+		dynamicConfig bool
+		expected      string
+	}{
+		{"defaultsToStatic", "", false, "static"},
+		{"defaultsToAPIWithDynamicConfig", "", true, "api"},
+		{"keepsStatic", "static", false, "static"},
+		{"keepsComposite", "composite", true, "composite"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			underTest := ControllerOptions{ConfigSource: test.source, EnableDynamicConfig: test.dynamicConfig}
+			err := underTest.Normalize()
+
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, underTest.ConfigSource)
+		})
+	}
+
+	t.Run("failsOnStaticSourceWithDynamicConfig", func(t *testing.T) {
+		underTest := ControllerOptions{ConfigSource: "static", EnableDynamicConfig: true}
+		err := underTest.Normalize()
+
+		assert.ErrorContains(t, err, `config source "static" can't be used with --enable-dynamic-config`)
+	})
+
+	t.Run("failsOnAPISourceWithoutDynamicConfig", func(t *testing.T) {
+		underTest := ControllerOptions{ConfigSource: "composite"}
+		err := underTest.Normalize()
+
+		assert.ErrorContains(t, err, `config source "composite" requires --enable-dynamic-config`)
+	})
 }
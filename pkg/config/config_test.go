@@ -25,6 +25,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/k0sproject/k0s/internal/pkg/cloudmetadata"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/client/clientset/fake"
 	k0sv1beta1 "github.com/k0sproject/k0s/pkg/client/clientset/typed/k0s/v1beta1"
@@ -68,7 +69,7 @@ func TestGetConfigFromFile(t *testing.T) {
 	loadingRules := ClientConfigLoadingRules{
 		RuntimeConfigPath: nonExistentPath(t),
 	}
-	err := loadingRules.InitRuntimeConfig(constant.GetConfig(t.TempDir()))
+	err := loadingRules.InitRuntimeConfig(context.TODO(), constant.GetConfig(t.TempDir()))
 	if err != nil {
 		t.Fatalf("failed to initialize k0s config: %s", err.Error())
 	}
@@ -115,7 +116,7 @@ spec:
 	loadingRules := ClientConfigLoadingRules{
 		RuntimeConfigPath: nonExistentPath(t),
 	}
-	err := loadingRules.InitRuntimeConfig(constant.GetConfig(t.TempDir()))
+	err := loadingRules.InitRuntimeConfig(context.TODO(), constant.GetConfig(t.TempDir()))
 	if err != nil {
 		t.Fatalf("failed to initialize k0s config: %s", err.Error())
 	}
@@ -146,12 +147,36 @@ spec:
 	}
 }
 
+func TestAutoExternalAddress(t *testing.T) {
+	yamlData := `
+spec:
+  api:
+    externalAddress: auto
+    sans:
+    - auto
+    - k0s.example.com`
+
+	CfgFile = writeConfigFile(t, yamlData)
+	discoverPublicAddress = func(context.Context) (string, error) { return "203.0.113.1", nil }
+	t.Cleanup(func() { discoverPublicAddress = cloudmetadata.PublicAddress })
+
+	loadingRules := ClientConfigLoadingRules{
+		RuntimeConfigPath: nonExistentPath(t),
+	}
+	require.NoError(t, loadingRules.InitRuntimeConfig(context.TODO(), constant.GetConfig(t.TempDir())))
+
+	cfg, err := loadingRules.Load()
+	require.NoError(t, err)
+	assert.Equal(t, "203.0.113.1", cfg.Spec.API.ExternalAddress)
+	assert.Equal(t, []string{"203.0.113.1", "k0s.example.com"}, cfg.Spec.API.SANs)
+}
+
 func TestConfigFromDefaults(t *testing.T) {
 	CfgFile = ""
 	loadingRules := ClientConfigLoadingRules{
 		RuntimeConfigPath: nonExistentPath(t),
 	}
-	err := loadingRules.InitRuntimeConfig(constant.GetConfig(t.TempDir()))
+	err := loadingRules.InitRuntimeConfig(context.TODO(), constant.GetConfig(t.TempDir()))
 	if err != nil {
 		t.Fatalf("failed to initialize k0s config: %s", err.Error())
 	}
@@ -195,7 +220,7 @@ func TestNodeConfigWithAPIConfig(t *testing.T) {
 		Nodeconfig:        true,
 	}
 
-	err := loadingRules.InitRuntimeConfig(constant.GetConfig(t.TempDir()))
+	err := loadingRules.InitRuntimeConfig(context.TODO(), constant.GetConfig(t.TempDir()))
 	if err != nil {
 		t.Fatalf("failed to initialize k0s config: %s", err.Error())
 	}
@@ -241,7 +266,7 @@ spec:
 	k0sVars := constant.GetConfig(tempDir)
 	k0sVars.DefaultStorageType = "kine"
 
-	err := loadingRules.InitRuntimeConfig(k0sVars)
+	err := loadingRules.InitRuntimeConfig(context.TODO(), k0sVars)
 	if err != nil {
 		t.Fatalf("failed to initialize k0s config: %s", err.Error())
 	}
@@ -282,7 +307,7 @@ spec:
 	k0sVars := constant.GetConfig(t.TempDir())
 	k0sVars.DefaultStorageType = "kine"
 
-	err := loadingRules.InitRuntimeConfig(k0sVars)
+	err := loadingRules.InitRuntimeConfig(context.TODO(), k0sVars)
 	if err != nil {
 		t.Fatalf("failed to initialize k0s config: %s", err.Error())
 	}
@@ -324,7 +349,7 @@ func TestAPIConfig(t *testing.T) {
 		RuntimeConfigPath: nonExistentPath(t),
 		APIClient:         client.K0sV1beta1(),
 	}
-	err := loadingRules.InitRuntimeConfig(constant.GetConfig(t.TempDir()))
+	err := loadingRules.InitRuntimeConfig(context.TODO(), constant.GetConfig(t.TempDir()))
 	if err != nil {
 		t.Fatalf("failed to initialize k0s config: %s", err.Error())
 	}
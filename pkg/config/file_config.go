@@ -17,10 +17,12 @@ limitations under the License.
 package config
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
+	"github.com/k0sproject/k0s/internal/pkg/cloudmetadata"
 	"github.com/k0sproject/k0s/internal/pkg/file"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/constant"
@@ -30,16 +32,30 @@ import (
 
 var (
 	runtimeConfigPathDefault = "/run/k0s/k0s.yaml"
+
+	// discoverPublicAddress discovers the node's public address.
+	discoverPublicAddress = cloudmetadata.PublicAddress
 )
 
-// InitRuntimeConfig generates the runtime /run/k0s/k0s.yaml
-func (rules *ClientConfigLoadingRules) InitRuntimeConfig(k0sVars constant.CfgVars) error {
+// InitRuntimeConfig generates the runtime /run/k0s/k0s.yaml. The "auto"
+// placeholders of the API spec are replaced by the node's public address, so
+// that the runtime config holds the actual address.
+func (rules *ClientConfigLoadingRules) InitRuntimeConfig(ctx context.Context, k0sVars constant.CfgVars) error {
 	rules.K0sVars = k0sVars
 	cfg, err := rules.ParseRuntimeConfig()
 	if err != nil {
 		return err
 	}
 
+	if cfg.Spec.API.HasAutoAddress() {
+		address, err := discoverPublicAddress(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to discover the external address: %w", err)
+		}
+		logrus.Infof("Discovered external address %s", address)
+		cfg.Spec.API.ReplaceAutoAddress(address)
+	}
+
 	yamlData, err := yaml.Marshal(cfg)
 	if err != nil {
 		return err
@@ -34,3 +34,9 @@ const (
 func formatPath(dir string, file string) string {
 	return fmt.Sprintf("%s/%s", dir, file)
 }
+
+// StatusSocketPath returns the default path of the status socket, which is a
+// unix socket inside the given run directory.
+func StatusSocketPath(runDir string) string {
+	return formatPath(runDir, "status.sock")
+}
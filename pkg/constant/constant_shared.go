@@ -44,6 +44,9 @@ const (
 	CertRootDirMode = 0751
 	// EtcdCertDirMode is the expected directory permissions for EtcdCertDir
 	EtcdCertDirMode = 0711
+	// AuditLogDirMode is the expected directory permissions for the directory
+	// of kube-apiserver's audit log, if it's created by k0s.
+	AuditLogDirMode = 0700
 	// CertMode is the expected permissions for certificates. see: https://docs.datadoghq.com/security_monitoring/default_rules/cis-kubernetes-1.5.1-1.1.20/
 	CertMode = 0644
 	// CertSecureMode is the expected file permissions for secure files. see: https://docs.datadoghq.com/security_monitoring/default_rules/cis-kubernetes-1.5.1-1.1.13/
@@ -90,10 +93,30 @@ const (
 	CoreDNSImageVersion                = "1.10.1"
 	EnvoyProxyImage                    = "quay.io/k0sproject/envoy-distroless"
 	EnvoyProxyImageVersion             = "v1.24.1"
+	NodeLocalDNSImage                  = "registry.k8s.io/dns/k8s-dns-node-cache"
+	NodeLocalDNSImageVersion           = "1.22.20"
+	SnapshotControllerImage            = "registry.k8s.io/sig-storage/snapshot-controller"
+	SnapshotControllerImageVersion     = "v7.0.2"
+	IngressNginxImage                  = "registry.k8s.io/ingress-nginx/controller"
+	IngressNginxImageVersion           = "v1.8.1"
+	MetalLBControllerImage             = "quay.io/metallb/controller"
+	MetalLBSpeakerImage                = "quay.io/metallb/speaker"
+	MetalLBImageVersion                = "v0.14.3"
+	AWSCCMImage                        = "registry.k8s.io/provider-aws/cloud-controller-manager"
+	AWSCCMImageVersion                 = "v1.27.1"
+	OpenStackCCMImage                  = "registry.k8s.io/provider-os/openstack-cloud-controller-manager"
+	OpenStackCCMImageVersion           = "v1.27.1"
+	HetznerCCMImage                    = "docker.io/hetznercloud/hcloud-cloud-controller-manager"
+	HetznerCCMImageVersion             = "v1.16.0"
 	CalicoImage                        = "quay.io/k0sproject/calico-cni"
 	CalicoComponentImagesVersion       = "v3.24.5-0"
 	CalicoNodeImage                    = "quay.io/k0sproject/calico-node"
 	KubeControllerImage                = "quay.io/k0sproject/calico-kube-controllers"
+	CalicoCNIWindowsImage              = "docker.io/calico/cni-windows"
+	CalicoNodeWindowsImage             = "docker.io/calico/node-windows"
+	CalicoWindowsImagesVersion         = "v3.24.5"
+	KubeProxyWindowsImage              = "docker.io/sigwindowstools/kube-proxy"
+	KubeProxyWindowsImageVersion       = "v1.27.1-calico-hostprocess"
 	KubeRouterCNIImage                 = "quay.io/k0sproject/kube-router"
 	KubeRouterCNIImageVersion          = "v1.5.1"
 	KubeRouterCNIInstallerImage        = "quay.io/k0sproject/cni-node"
@@ -105,10 +128,14 @@ const (
 
 	APIConfigComponentName             = "api-config" // Deprecated: just don't use dynamic config
 	APIEndpointReconcilerComponentName = "endpoint-reconciler"
+	APIEndpointHealthComponentName     = "endpoint-health"
+	CloudProviderComponentName         = "cloud-provider"
+	ClusterConfigWebhookComponentName  = "clusterconfig-webhook"
 	ControlAPIComponentName            = "control-api"
 	CoreDNSComponentname               = "coredns"
 	CsrApproverComponentName           = "csr-approver"
 	HelmComponentName                  = "helm"
+	IngressComponentName               = "ingress"
 	KonnectivityServerComponentName    = "konnectivity-server"
 	KubeControllerManagerComponentName = "kube-controller-manager"
 	KubeProxyComponentName             = "kube-proxy"
@@ -117,10 +144,17 @@ const (
 	WorkerConfigComponentName          = "worker-config"
 	MetricsServerComponentName         = "metrics-server"
 	NetworkProviderComponentName       = "network-provider"
+	NodeLocalDNSComponentName          = "node-local-dns"
+	ServiceLoadBalancerComponentName   = "service-load-balancer"
+	SnapshotControllerComponentName    = "snapshot-controller"
 	SystemRbacComponentName            = "system-rbac"
 	NodeRoleComponentName              = "node-role"
 	AutopilotComponentName             = "autopilot"
 
+	// ClusterConfigWebhookPort is the port on which the ClusterConfig
+	// validating webhook listens on the loopback interface of controllers.
+	ClusterConfigWebhookPort = 9445
+
 	// ClusterConfigNamespace is the namespace where we expect to find the ClusterConfig CRs
 	ClusterConfigNamespace  = "kube-system"
 	ClusterConfigObjectName = "k0s"
@@ -44,3 +44,9 @@ const (
 func formatPath(dir string, file string) string {
 	return fmt.Sprintf("%s\\%s", dir, file)
 }
+
+// StatusSocketPath returns the default path of the status socket. Windows
+// serves the status API via a named pipe, so the run directory is ignored.
+func StatusSocketPath(string) string {
+	return `\\.\pipe\k0s-status`
+}
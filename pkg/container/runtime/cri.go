@@ -32,6 +32,12 @@ type CRIRuntime struct {
 	criSocketPath string
 }
 
+// NewCRIRuntime creates a runtime that talks to the CRI socket at the given
+// address, e.g. "unix:///run/k0s/containerd.sock".
+func NewCRIRuntime(criSocketPath string) *CRIRuntime {
+	return &CRIRuntime{criSocketPath}
+}
+
 func (cri *CRIRuntime) ListContainers() ([]string, error) {
 	client, conn, err := getRuntimeClient(cri.criSocketPath)
 	defer closeConnection(conn)
@@ -95,6 +101,89 @@ func (cri *CRIRuntime) StopContainer(id string) error {
 	return nil
 }
 
+// PruneImages removes all images that aren't used by any container, like
+// `crictl rmi --prune` does. Pinned images are kept. Returns the IDs of the
+// removed images.
+func (cri *CRIRuntime) PruneImages(ctx context.Context) ([]string, error) {
+	conn, err := getRuntimeClientConnection(cri.criSocketPath)
+	defer closeConnection(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CRI runtime client: %w", err)
+	}
+	if conn == nil {
+		return nil, fmt.Errorf("failed to connect to %s", cri.criSocketPath)
+	}
+	runtimeClient := pb.NewRuntimeServiceClient(conn)
+	imageClient := pb.NewImageServiceClient(conn)
+
+	containers, err := runtimeClient.ListContainers(ctx, &pb.ListContainersRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list containers: %w", err)
+	}
+	inUse := make(map[string]bool)
+	for _, c := range containers.GetContainers() {
+		inUse[c.GetImageRef()] = true
+		inUse[c.GetImage().GetImage()] = true
+	}
+
+	images, err := imageClient.ListImages(ctx, &pb.ListImagesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list images: %w", err)
+	}
+
+	var removed []string
+	for _, image := range images.GetImages() {
+		if image.GetPinned() || isImageInUse(image, inUse) {
+			continue
+		}
+
+		request := &pb.RemoveImageRequest{Image: &pb.ImageSpec{Image: image.GetId()}}
+		logrus.Debugf("RemoveImageRequest: %v", request)
+		if _, err := imageClient.RemoveImage(ctx, request); err != nil {
+			return removed, fmt.Errorf("failed to remove image %s: %w", image.GetId(), err)
+		}
+		removed = append(removed, image.GetId())
+	}
+
+	return removed, nil
+}
+
+// Status checks whether the runtime reports itself as ready. Returns an error
+// if the runtime can't be reached or isn't ready.
+func (cri *CRIRuntime) Status(ctx context.Context) error {
+	conn, err := grpc.DialContext(ctx, cri.criSocketPath, grpc.WithTransportCredentials(insecure.NewCredentials()), grpc.WithBlock())
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", cri.criSocketPath, err)
+	}
+	defer closeConnection(conn)
+
+	status, err := pb.NewRuntimeServiceClient(conn).Status(ctx, &pb.StatusRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to query runtime status: %w", err)
+	}
+	for _, condition := range status.GetStatus().GetConditions() {
+		if condition.GetType() == pb.RuntimeReady && !condition.GetStatus() {
+			return fmt.Errorf("runtime not ready: %s: %s", condition.GetReason(), condition.GetMessage())
+		}
+	}
+
+	return nil
+}
+
+func isImageInUse(image *pb.Image, inUse map[string]bool) bool {
+	if inUse[image.GetId()] {
+		return true
+	}
+	for _, refs := range [][]string{image.GetRepoTags(), image.GetRepoDigests()} {
+		for _, ref := range refs {
+			if inUse[ref] {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 func getRuntimeClient(addr string) (pb.RuntimeServiceClient, *grpc.ClientConn, error) {
 	conn, err := getRuntimeClientConnection(addr)
 	if err != nil {
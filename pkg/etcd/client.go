@@ -178,6 +178,29 @@ func (c *Client) Write(ctx context.Context, key string, value string, ttl time.D
 	return txnResp.Succeeded, nil
 }
 
+// CompactAndDefragment compacts the key space up to the current revision and
+// defragments the backends of the client's endpoints, which frees up the disk
+// space that's occupied by the history of the keys.
+func (c *Client) CompactAndDefragment(ctx context.Context) error {
+	resp, err := c.client.Get(ctx, "health")
+	if err != nil {
+		return fmt.Errorf("can't get current revision: %w", err)
+	}
+
+	_, err = c.client.Compact(ctx, resp.Header.Revision, clientv3.WithCompactPhysical())
+	if err != nil && err != rpctypes.ErrCompacted {
+		return fmt.Errorf("can't compact etcd: %w", err)
+	}
+
+	for _, endpoint := range c.client.Endpoints() {
+		if _, err := c.client.Defragment(ctx, endpoint); err != nil {
+			return fmt.Errorf("can't defragment %s: %w", endpoint, err)
+		}
+	}
+
+	return nil
+}
+
 func (c *Client) Read(ctx context.Context, key string) (*clientv3.GetResponse, error) {
 	resp, err := c.client.KV.Get(ctx, key)
 	if err != nil {
@@ -0,0 +1,283 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/file"
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/constant"
+
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// RepositoryCredentials are the secret settings of a chart repository.
+type RepositoryCredentials struct {
+	Username string
+	Password string
+	Token    string
+	CABundle []byte
+}
+
+// NewRepositoryCredentials returns the credentials of the given repository
+// config, or nil if it has none.
+func NewRepositoryCredentials(r v1beta1.Repository) *RepositoryCredentials {
+	if !r.HasCredentials() {
+		return nil
+	}
+	return &RepositoryCredentials{
+		Username: r.Username,
+		Password: r.Password,
+		Token:    r.Token,
+		CABundle: []byte(r.CABundle),
+	}
+}
+
+// CredentialsProvider looks up the credentials of chart repositories.
+type CredentialsProvider interface {
+	// RepositoryCredentials returns the credentials of the repository with
+	// the given name, or nil if there are none.
+	RepositoryCredentials(ctx context.Context, name string) (*RepositoryCredentials, error)
+}
+
+// repositoryTimeout is the timeout of requests to repositories that use bearer
+// tokens. It matches the default timeout of `helm repo add`.
+const repositoryTimeout = 120 * time.Second
+
+// caFile returns the path to which the CA bundle of the given repository is
+// written.
+func (hc *Commands) caFile(repoName string) string {
+	return filepath.Join(filepath.Dir(hc.repoFile), "certs", repoName+"-ca.crt")
+}
+
+// applyCredentials sets the given credentials on a repository entry. The CA
+// bundle is written to disk, since helm expects it to be in a file.
+func (hc *Commands) applyCredentials(entry *repo.Entry, creds *RepositoryCredentials) error {
+	if creds == nil {
+		return nil
+	}
+
+	entry.Username, entry.Password = creds.Username, creds.Password
+	if len(creds.CABundle) > 0 {
+		caFile := hc.caFile(entry.Name)
+		if err := dir.Init(filepath.Dir(caFile), constant.DataDirMode); err != nil {
+			return err
+		}
+		if err := file.WriteContentAtomically(caFile, creds.CABundle, constant.CertMode); err != nil {
+			return err
+		}
+		entry.CAFile = caFile
+	}
+
+	key := strings.TrimSuffix(entry.URL, "/")
+	var token *repositoryToken
+	if creds.Token != "" {
+		client, err := newTokenClient(entry)
+		if err != nil {
+			return err
+		}
+		token = &repositoryToken{key, creds.Token, client}
+	}
+
+	hc.tokensMu.Lock()
+	defer hc.tokensMu.Unlock()
+	if hc.tokens == nil {
+		hc.tokens = make(map[string]*repositoryToken)
+	}
+	if token != nil {
+		hc.tokens[key] = token
+	} else {
+		delete(hc.tokens, key)
+	}
+
+	return nil
+}
+
+// syncCredentials refreshes the credentials of the given repository from the
+// credentials provider, before charts are pulled from it.
+func (hc *Commands) syncCredentials(ctx context.Context, repoName string) error {
+	if hc.Credentials == nil || repoName == "" {
+		return nil
+	}
+
+	hc.repoFileMu.Lock()
+	defer hc.repoFileMu.Unlock()
+
+	f, err := repo.LoadFile(hc.repoFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	entry := f.Get(repoName)
+	if entry == nil {
+		return nil
+	}
+
+	creds, err := hc.Credentials.RepositoryCredentials(ctx, repoName)
+	if err != nil {
+		return fmt.Errorf("failed to get credentials for repository %q: %w", repoName, err)
+	}
+	if err := hc.applyCredentials(entry, creds); err != nil {
+		return fmt.Errorf("failed to apply credentials for repository %q: %w", repoName, err)
+	}
+
+	f.Update(entry)
+	return f.WriteFile(hc.repoFile, 0600)
+}
+
+// repositoryToken is the bearer token of the repository with the given URL,
+// along with the client used to talk to that repository.
+type repositoryToken struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// tokenFor returns the token of the repository that serves the given URL. If
+// several repositories match, the one with the longest URL wins.
+func (hc *Commands) tokenFor(href string) *repositoryToken {
+	href = strings.SplitN(href, "?", 2)[0]
+
+	hc.tokensMu.Lock()
+	defer hc.tokensMu.Unlock()
+
+	var found *repositoryToken
+	for key, token := range hc.tokens {
+		if href != key && !strings.HasPrefix(href, key+"/") {
+			continue
+		}
+		if found == nil || len(key) > len(found.url) {
+			found = token
+		}
+	}
+	return found
+}
+
+// getters returns the getter providers. HTTP requests to repositories that have
+// a bearer token are authenticated using that token.
+func (hc *Commands) getters() getter.Providers {
+	return getter.Providers{
+		getter.Provider{
+			Schemes: []string{"http", "https"},
+			New: func(options ...getter.Option) (getter.Getter, error) {
+				return &tokenGetter{hc, options}, nil
+			},
+		},
+		getter.Provider{
+			Schemes: []string{"oci"},
+			New:     getter.NewOCIGetter,
+		},
+	}
+}
+
+// tokenGetter is an HTTP getter that adds bearer tokens to requests.
+type tokenGetter struct {
+	hc      *Commands
+	options []getter.Option
+}
+
+func (g *tokenGetter) Get(href string, options ...getter.Option) (*bytes.Buffer, error) {
+	token := g.hc.tokenFor(href)
+	if token == nil {
+		httpGetter, err := getter.NewHTTPGetter(g.options...)
+		if err != nil {
+			return nil, err
+		}
+		return httpGetter.Get(href, options...)
+	}
+
+	return token.get(href)
+}
+
+func (t *repositoryToken) get(href string) (*bytes.Buffer, error) {
+	req, err := http.NewRequest(http.MethodGet, href, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "k0s")
+	req.Header.Set("Authorization", "Bearer "+t.token)
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s : %s", href, resp.Status)
+	}
+
+	var buf bytes.Buffer
+	_, err = io.Copy(&buf, resp.Body)
+	return &buf, err
+}
+
+// newTokenClient returns the HTTP client used for the given repository. It
+// honors the TLS settings of the repository entry.
+func newTokenClient(entry *repo.Entry) (*http.Client, error) {
+	tlsConfig, err := tlsConfig(entry)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{
+		Timeout: repositoryTimeout,
+		Transport: &http.Transport{
+			DisableCompression: true,
+			Proxy:              http.ProxyFromEnvironment,
+			TLSClientConfig:    tlsConfig,
+		},
+	}, nil
+}
+
+func tlsConfig(entry *repo.Entry) (*tls.Config, error) {
+	config := &tls.Config{InsecureSkipVerify: entry.InsecureSkipTLSverify}
+
+	if entry.CertFile != "" && entry.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(entry.CertFile, entry.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if entry.CAFile != "" {
+		ca, err := os.ReadFile(entry.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("can't read CA file: %w", err)
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("no certificates found in CA file %s", entry.CAFile)
+		}
+	}
+
+	return config, nil
+}
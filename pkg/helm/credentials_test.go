@@ -0,0 +1,148 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helm
+
+import (
+	"context"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestRepoName(t *testing.T) {
+	assert.Equal(t, "bitnami", repoName("bitnami/nginx"))
+	assert.Equal(t, "", repoName("oci://registry.example.com/charts/nginx"))
+	assert.Equal(t, "", repoName("nginx"))
+}
+
+func TestTokenGetter(t *testing.T) {
+	var authHeaders []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	hc := &Commands{repoFile: filepath.Join(t.TempDir(), "repositories.yaml")}
+	entry := repo.Entry{Name: "private", URL: server.URL}
+	require.NoError(t, hc.applyCredentials(&entry, &RepositoryCredentials{
+		Token:    "secret",
+		CABundle: pemEncodeCertificate(server.Certificate().Raw),
+	}))
+	assert.Equal(t, hc.caFile("private"), entry.CAFile)
+	assert.FileExists(t, entry.CAFile)
+
+	getter, err := hc.getters().ByScheme("https")
+	require.NoError(t, err)
+	buf, err := getter.Get(server.URL + "/index.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", buf.String())
+	assert.Equal(t, []string{"Bearer secret"}, authHeaders)
+}
+
+func TestTokenFor(t *testing.T) {
+	hc := &Commands{tokens: map[string]*repositoryToken{
+		"https://charts.example.com/team-a":        {url: "https://charts.example.com/team-a", token: "a"},
+		"https://charts.example.com/team-a/nested": {url: "https://charts.example.com/team-a/nested", token: "nested"},
+	}}
+
+	for _, test := range []struct{ href, token string }{
+		{"https://charts.example.com/team-a/index.yaml", "a"},
+		{"https://charts.example.com/team-a/nested/index.yaml?x=y", "nested"},
+		{"https://charts.example.com/team-b/index.yaml", ""},
+		{"https://charts.example.com/team-ab/index.yaml", ""},
+	} {
+		var token string
+		if found := hc.tokenFor(test.href); found != nil {
+			token = found.token
+		}
+		assert.Equal(t, test.token, token, test.href)
+	}
+}
+
+func TestSyncCredentials(t *testing.T) {
+	hc := &Commands{repoFile: filepath.Join(t.TempDir(), "repositories.yaml")}
+	f := repo.NewFile()
+	f.Update(&repo.Entry{Name: "private", URL: "https://charts.example.com"})
+	require.NoError(t, f.WriteFile(hc.repoFile, 0600))
+
+	hc.Credentials = staticCredentials{"private": {Username: "user", Password: "pass"}}
+	require.NoError(t, hc.syncCredentials(context.Background(), "private"))
+	require.NoError(t, hc.syncCredentials(context.Background(), "unknown"))
+
+	f, err := repo.LoadFile(hc.repoFile)
+	require.NoError(t, err)
+	if entry := f.Get("private"); assert.NotNil(t, entry) {
+		assert.Equal(t, "user", entry.Username)
+		assert.Equal(t, "pass", entry.Password)
+	}
+	stat, err := os.Stat(hc.repoFile)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), stat.Mode().Perm())
+}
+
+func TestSyncCredentialsConcurrently(t *testing.T) {
+	hc := &Commands{repoFile: filepath.Join(t.TempDir(), "repositories.yaml")}
+	f := repo.NewFile()
+	f.Update(
+		&repo.Entry{Name: "first", URL: "https://first.example.com"},
+		&repo.Entry{Name: "second", URL: "https://second.example.com"},
+	)
+	require.NoError(t, f.WriteFile(hc.repoFile, 0600))
+	hc.Credentials = staticCredentials{
+		"first":  {Username: "first-user"},
+		"second": {Username: "second-user"},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		for _, name := range []string{"first", "second"} {
+			wg.Add(1)
+			go func(name string) {
+				defer wg.Done()
+				assert.NoError(t, hc.syncCredentials(context.Background(), name))
+			}(name)
+		}
+	}
+	wg.Wait()
+
+	f, err := repo.LoadFile(hc.repoFile)
+	require.NoError(t, err)
+	for _, name := range []string{"first", "second"} {
+		if entry := f.Get(name); assert.NotNil(t, entry, name) {
+			assert.Equal(t, name+"-user", entry.Username)
+		}
+	}
+}
+
+type staticCredentials map[string]*RepositoryCredentials
+
+func (s staticCredentials) RepositoryCredentials(_ context.Context, name string) (*RepositoryCredentials, error) {
+	return s[name], nil
+}
+
+func pemEncodeCertificate(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -17,10 +17,12 @@ limitations under the License.
 package helm
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/sirupsen/logrus"
@@ -44,6 +46,16 @@ type Commands struct {
 	repoFile     string
 	helmCacheDir string
 	kubeConfig   string
+
+	// Credentials provides the credentials of chart repositories. If set, the
+	// credentials are refreshed before charts are pulled.
+	Credentials CredentialsProvider
+
+	// repoFileMu serializes updates of the repository file.
+	repoFileMu sync.Mutex
+
+	tokensMu sync.Mutex
+	tokens   map[string]*repositoryToken
 }
 
 func logFn(format string, args ...interface{}) {
@@ -51,17 +63,6 @@ func logFn(format string, args ...interface{}) {
 	log.Debugf(format, args...)
 }
 
-var getters = getter.Providers{
-	getter.Provider{
-		Schemes: []string{"http", "https"},
-		New:     getter.NewHTTPGetter,
-	},
-	getter.Provider{
-		Schemes: []string{"oci"},
-		New:     getter.NewOCIGetter,
-	},
-}
-
 // NewCommands builds new Commands instance with default values
 func NewCommands(k0sVars constant.CfgVars) *Commands {
 	return &Commands{
@@ -71,6 +72,12 @@ func NewCommands(k0sVars constant.CfgVars) *Commands {
 	}
 }
 
+// ActionConfig returns a helm action configuration for the given namespace,
+// using k0s's kubeconfig and helm cache.
+func (hc *Commands) ActionConfig(namespace string) (*action.Configuration, error) {
+	return hc.getActionCfg(namespace)
+}
+
 func (hc *Commands) getActionCfg(namespace string) (*action.Configuration, error) {
 	insecure := false
 	var impersonateGroup []string
@@ -89,34 +96,32 @@ func (hc *Commands) getActionCfg(namespace string) (*action.Configuration, error
 	return actionConfig, nil
 }
 
-func (hc *Commands) AddRepository(repoCfg v1beta1.Repository) error {
+func (hc *Commands) AddRepository(ctx context.Context, repoCfg v1beta1.Repository) error {
 	err := dir.Init(filepath.Dir(hc.repoFile), constant.DataDirMode)
 	if err != nil && !os.IsExist(err) {
 		return fmt.Errorf("can't add repository to %s: %v", hc.repoFile, err)
 	}
 
-	b, err := os.ReadFile(hc.repoFile)
-	if err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("can't add repository to %s: %v", hc.repoFile, err)
-	}
-
-	var f repo.File
-	if err := yaml.Unmarshal(b, &f); err != nil {
-		return fmt.Errorf("can't add repository to %s: %v", hc.repoFile, err)
-	}
-
 	c := repo.Entry{
 		Name:                  repoCfg.Name,
 		URL:                   repoCfg.URL,
-		Username:              repoCfg.Username,
-		Password:              repoCfg.Password,
 		CertFile:              repoCfg.CertFile,
 		KeyFile:               repoCfg.KeyFile,
 		CAFile:                repoCfg.CAFile,
-		InsecureSkipTLSverify: true,
+		InsecureSkipTLSverify: repoCfg.Insecure,
+	}
+	creds := NewRepositoryCredentials(repoCfg)
+	if creds == nil && hc.Credentials != nil {
+		// The credentials may have been moved out of the cluster config.
+		if creds, err = hc.Credentials.RepositoryCredentials(ctx, repoCfg.Name); err != nil {
+			return fmt.Errorf("can't get credentials for repository %q: %w", repoCfg.Name, err)
+		}
+	}
+	if err := hc.applyCredentials(&c, creds); err != nil {
+		return fmt.Errorf("can't add repository to %s: %v", hc.repoFile, err)
 	}
 
-	r, err := repo.NewChartRepository(&c, getters)
+	r, err := repo.NewChartRepository(&c, hc.getters())
 	if err != nil {
 		return fmt.Errorf("can't add repository to %s: %v", hc.repoFile, err)
 	}
@@ -125,8 +130,23 @@ func (hc *Commands) AddRepository(repoCfg v1beta1.Repository) error {
 	if _, err := r.DownloadIndexFile(); err != nil {
 		return fmt.Errorf("can't add repository: %q is not a valid chart repository or cannot be reached: %v", "repo", err)
 	}
+
+	// The index is downloaded without holding the lock, so that slow
+	// repositories don't block charts from other repositories.
+	hc.repoFileMu.Lock()
+	defer hc.repoFileMu.Unlock()
+
+	b, err := os.ReadFile(hc.repoFile)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't add repository to %s: %v", hc.repoFile, err)
+	}
+
+	var f repo.File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return fmt.Errorf("can't add repository to %s: %v", hc.repoFile, err)
+	}
 	f.Update(&c)
-	if err := f.WriteFile(hc.repoFile, 0644); err != nil {
+	if err := f.WriteFile(hc.repoFile, 0600); err != nil {
 		return fmt.Errorf("can't add repository to %s: %v", hc.repoFile, err)
 	}
 
@@ -142,7 +162,7 @@ func (hc *Commands) downloadDependencies(chart *chart.Chart, chartPath string) e
 			Out:              os.Stdout,
 			ChartPath:        chartPath,
 			SkipUpdate:       false,
-			Getters:          getters,
+			Getters:          hc.getters(),
 			RepositoryConfig: hc.repoFile,
 			RepositoryCache:  hc.helmCacheDir,
 			Debug:            false,
@@ -154,7 +174,7 @@ func (hc *Commands) downloadDependencies(chart *chart.Chart, chartPath string) e
 	return nil
 }
 
-func (hc *Commands) locateChart(name string, version string) (string, error) {
+func (hc *Commands) locateChart(ctx context.Context, name string, version string) (string, error) {
 	name = strings.TrimSpace(name)
 
 	if _, err := os.Stat(name); err == nil {
@@ -168,9 +188,13 @@ func (hc *Commands) locateChart(name string, version string) (string, error) {
 		return name, fmt.Errorf("can't locate chart: path not found: %s", name)
 	}
 
+	if err := hc.syncCredentials(ctx, repoName(name)); err != nil {
+		return "", fmt.Errorf("can't locate chart `%s-%s`: %v", name, version, err)
+	}
+
 	dl := downloader.ChartDownloader{
 		Out:              os.Stdout,
-		Getters:          getters,
+		Getters:          hc.getters(),
 		Options:          []getter.Option{},
 		RepositoryConfig: hc.repoFile,
 		RepositoryCache:  hc.helmCacheDir,
@@ -198,6 +222,18 @@ func (hc *Commands) locateChart(name string, version string) (string, error) {
 	return filename, fmt.Errorf("failed to download %q%s (hint: running `helm repo update` may help)", name, atVersion)
 }
 
+// repoName returns the repository name of a chart reference in the form of
+// "repo/chart". Returns the empty string for other kinds of references.
+func repoName(chartRef string) string {
+	if strings.Contains(chartRef, "://") {
+		return ""
+	}
+	if name, _, found := strings.Cut(chartRef, "/"); found {
+		return name
+	}
+	return ""
+}
+
 func (hc *Commands) isInstallable(chart *chart.Chart) bool {
 	if chart.Metadata.Type != "" && chart.Metadata.Type != "application" {
 		return false
@@ -205,22 +241,38 @@ func (hc *Commands) isInstallable(chart *chart.Chart) bool {
 	return true
 }
 
-func (hc *Commands) InstallChart(chartName string, version string, releaseName string, namespace string, values map[string]interface{}, timeout time.Duration) (*release.Release, error) {
+// ReleaseOptions are the options used when installing or upgrading a release.
+type ReleaseOptions struct {
+	// Timeout is the time to wait for the release to become ready.
+	Timeout time.Duration
+	// Atomic rolls back failed installs and upgrades.
+	Atomic bool
+	// Wait waits for all resources and jobs of the release to become ready.
+	Wait bool
+	// SkipCRDs skips the installation of the chart's CRDs.
+	SkipCRDs bool
+	// DisableHooks prevents the chart's hooks from running.
+	DisableHooks bool
+}
+
+func (hc *Commands) InstallChart(ctx context.Context, chartName string, version string, releaseName string, namespace string, values map[string]interface{}, opts ReleaseOptions) (*release.Release, error) {
 	cfg, err := hc.getActionCfg(namespace)
 	if err != nil {
 		return nil, fmt.Errorf("can't create action configuration: %v", err)
 	}
 	install := action.NewInstall(cfg)
 	install.CreateNamespace = true
-	install.WaitForJobs = true
-	install.Wait = true
-	install.Timeout = timeout
-	chartDir, err := hc.locateChart(chartName, version)
+	install.WaitForJobs = opts.Wait
+	install.Wait = opts.Wait
+	install.Timeout = opts.Timeout
+	install.SkipCRDs = opts.SkipCRDs
+	install.DisableHooks = opts.DisableHooks
+	chartDir, err := hc.locateChart(ctx, chartName, version)
 	if err != nil {
 		return nil, err
 	}
 	install.Namespace = namespace
-	install.Atomic = true
+	install.Atomic = opts.Atomic
 	install.ReleaseName = releaseName
 	name, _, err := install.NameAndChart([]string{chartName})
 	install.ReleaseName = name
@@ -252,20 +304,22 @@ func (hc *Commands) InstallChart(chartName string, version string, releaseName s
 	return chartRelease, nil
 }
 
-func (hc *Commands) UpgradeChart(chartName string, version string, releaseName string, namespace string, values map[string]interface{}, timeout time.Duration) (*release.Release, error) {
+func (hc *Commands) UpgradeChart(ctx context.Context, chartName string, version string, releaseName string, namespace string, values map[string]interface{}, opts ReleaseOptions) (*release.Release, error) {
 	cfg, err := hc.getActionCfg(namespace)
 	if err != nil {
 		return nil, fmt.Errorf("can't create action configuration: %v", err)
 	}
 	upgrade := action.NewUpgrade(cfg)
 	upgrade.Namespace = namespace
-	upgrade.Wait = true
-	upgrade.WaitForJobs = true
+	upgrade.Wait = opts.Wait
+	upgrade.WaitForJobs = opts.Wait
 	upgrade.Install = true
 	upgrade.Force = true
-	upgrade.Atomic = true
-	upgrade.Timeout = timeout
-	chartDir, err := hc.locateChart(chartName, version)
+	upgrade.Atomic = opts.Atomic
+	upgrade.Timeout = opts.Timeout
+	upgrade.SkipCRDs = opts.SkipCRDs
+	upgrade.DisableHooks = opts.DisableHooks
+	chartDir, err := hc.locateChart(ctx, chartName, version)
 	if err != nil {
 		return nil, err
 	}
@@ -307,6 +361,26 @@ func (hc *Commands) ListReleases(namespace string) ([]*release.Release, error) {
 	return helmAction.Run()
 }
 
+// GetRelease returns the latest revision of the given release.
+func (hc *Commands) GetRelease(releaseName string, namespace string) (*release.Release, error) {
+	cfg, err := hc.getActionCfg(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("can't create helmAction configuration: %v", err)
+	}
+	helmAction := action.NewGet(cfg)
+	return helmAction.Run(releaseName)
+}
+
+// History returns all stored revisions of the given release.
+func (hc *Commands) History(releaseName string, namespace string) ([]*release.Release, error) {
+	cfg, err := hc.getActionCfg(namespace)
+	if err != nil {
+		return nil, fmt.Errorf("can't create helmAction configuration: %v", err)
+	}
+	helmAction := action.NewHistory(cfg)
+	return helmAction.Run(releaseName)
+}
+
 func (hc *Commands) UninstallRelease(releaseName string, namespace string) error {
 	cfg, err := hc.getActionCfg(namespace)
 	if err != nil {
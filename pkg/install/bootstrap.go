@@ -0,0 +1,216 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	"sigs.k8s.io/yaml"
+)
+
+// MachineBootstrap is a declarative document that describes how k0s is set up
+// on a machine. It's meant to be generated by external provisioning systems,
+// such as Cluster API bootstrap providers or Terraform, and is consumed by
+// "k0s bootstrap".
+type MachineBootstrap struct {
+	// Role is the role of the machine, one of controller, controller+worker
+	// or worker.
+	Role string `json:"role"`
+	// Token is the join token. Mutually exclusive with TokenURL.
+	Token string `json:"token,omitempty"`
+	// TokenURL is the URL from which the join token is fetched. Mutually
+	// exclusive with Token.
+	TokenURL string `json:"tokenURL,omitempty"`
+	// Config is applied on top of the default k0s config. It may only be given
+	// for controllers.
+	Config json.RawMessage `json:"config,omitempty"`
+	// Files are written before k0s gets installed.
+	Files []MachineBootstrapFile `json:"files,omitempty"`
+	// Args are additional arguments passed to the k0s service.
+	Args []string `json:"args,omitempty"`
+	// Env are additional environment variables of the k0s service, in the
+	// form of NAME=VALUE.
+	Env []string `json:"env,omitempty"`
+}
+
+// MachineBootstrapFile is a file that's written to the machine.
+type MachineBootstrapFile struct {
+	// Path is the absolute path of the file.
+	Path string `json:"path"`
+	// Content is the content of the file, encoded as specified by Encoding.
+	Content string `json:"content"`
+	// Encoding is the encoding of Content, either empty for plain text or
+	// base64.
+	Encoding string `json:"encoding,omitempty"`
+	// Permissions are the octal permissions of the file. Defaults to 0644.
+	Permissions string `json:"permissions,omitempty"`
+}
+
+// ParseMachineBootstrap parses and validates the given JSON document. Unknown
+// fields are rejected, so that typos don't go unnoticed.
+func ParseMachineBootstrap(data []byte) (*MachineBootstrap, error) {
+	var b MachineBootstrap
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&b); err != nil {
+		return nil, fmt.Errorf("failed to parse machine bootstrap document: %w", err)
+	}
+	if err := b.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid machine bootstrap document: %w", err)
+	}
+	return &b, nil
+}
+
+// Validate validates the document.
+func (b *MachineBootstrap) Validate() error {
+	var errs []error
+
+	switch b.Role {
+	case "controller", "controller+worker", "worker":
+	default:
+		errs = append(errs, fmt.Errorf("role: unsupported value %q", b.Role))
+	}
+
+	if b.Token != "" && b.TokenURL != "" {
+		errs = append(errs, errors.New("only one of token and tokenURL may be given"))
+	}
+
+	if len(b.Config) > 0 {
+		if b.Role == "worker" {
+			errs = append(errs, errors.New("config: may only be given for controllers"))
+		} else if _, _, err := b.ClusterConfig(); err != nil {
+			errs = append(errs, fmt.Errorf("config: %w", err))
+		}
+	}
+
+	for i, f := range b.Files {
+		if _, _, err := f.Decode(); err != nil {
+			errs = append(errs, fmt.Errorf("files[%d]: %w", i, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// IsController returns true if the machine runs a controller.
+func (b *MachineBootstrap) IsController() bool {
+	return b.Role != "worker"
+}
+
+// ClusterConfig applies the config of the document on top of the default k0s
+// config. It returns the config file contents along with the parsed and
+// validated config. If the document doesn't contain a config, the default
+// config is returned along with nil file contents.
+func (b *MachineBootstrap) ClusterConfig() ([]byte, *v1beta1.ClusterConfig, error) {
+	if len(b.Config) == 0 {
+		return nil, v1beta1.DefaultClusterConfig(), nil
+	}
+
+	var config map[string]any
+	if err := json.Unmarshal(b.Config, &config); err != nil {
+		return nil, nil, err
+	}
+	if config == nil {
+		return nil, nil, errors.New("must be an object")
+	}
+	if _, ok := config["apiVersion"]; !ok {
+		config["apiVersion"] = v1beta1.ClusterConfigAPIVersion
+	}
+	if _, ok := config["kind"]; !ok {
+		config["kind"] = v1beta1.ClusterConfigKind
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, nil, err
+	}
+	clusterConfig, err := v1beta1.ConfigFromString(string(data))
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := errors.Join(clusterConfig.Validate()...); err != nil {
+		return nil, nil, err
+	}
+
+	return data, clusterConfig, nil
+}
+
+// ServiceArgs returns the arguments for the k0s service, given the paths to
+// which the config and the join token have been written.
+func (b *MachineBootstrap) ServiceArgs(configPath, tokenPath string) []string {
+	var args []string
+	if b.IsController() {
+		args = append(args, "controller")
+		if b.Role == "controller+worker" {
+			args = append(args, "--enable-worker")
+		}
+		if len(b.Config) > 0 {
+			args = append(args, "--config="+configPath)
+		}
+	} else {
+		args = append(args, "worker")
+	}
+
+	if b.Token != "" {
+		args = append(args, "--token-file="+tokenPath)
+	}
+	if b.TokenURL != "" {
+		args = append(args, "--token-url="+b.TokenURL)
+	}
+
+	return append(args, b.Args...)
+}
+
+// Decode returns the decoded content and the permissions of the file.
+func (f *MachineBootstrapFile) Decode() ([]byte, os.FileMode, error) {
+	if !filepath.IsAbs(f.Path) {
+		return nil, 0, fmt.Errorf("path must be absolute: %q", f.Path)
+	}
+
+	var content []byte
+	switch f.Encoding {
+	case "":
+		content = []byte(f.Content)
+	case "base64":
+		var err error
+		if content, err = base64.StdEncoding.DecodeString(f.Content); err != nil {
+			return nil, 0, fmt.Errorf("content: %w", err)
+		}
+	default:
+		return nil, 0, fmt.Errorf("encoding: unsupported value %q", f.Encoding)
+	}
+
+	mode := os.FileMode(0644)
+	if f.Permissions != "" {
+		perm, err := strconv.ParseUint(f.Permissions, 8, 32)
+		if err != nil || perm > 0777 {
+			return nil, 0, fmt.Errorf("permissions: invalid value %q", f.Permissions)
+		}
+		mode = os.FileMode(perm)
+	}
+
+	return content, mode, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMachineBootstrap(t *testing.T) {
+	b, err := ParseMachineBootstrap([]byte(`{
+  "role": "controller+worker",
+  "token": "secret",
+  "config": {"spec": {"api": {"sans": ["192.168.1.10"]}}},
+  "files": [
+    {"path": "/etc/foo", "content": "foo"},
+    {"path": "/etc/bar", "content": "YmFy", "encoding": "base64", "permissions": "0600"}
+  ],
+  "args": ["--labels=region=eu"]
+}`))
+	require.NoError(t, err)
+	assert.True(t, b.IsController())
+
+	assert.Equal(t, []string{
+		"controller", "--enable-worker", "--config=/etc/k0s/k0s.yaml",
+		"--token-file=/etc/k0s/join-token", "--labels=region=eu",
+	}, b.ServiceArgs("/etc/k0s/k0s.yaml", "/etc/k0s/join-token"))
+
+	data, clusterConfig, err := b.ClusterConfig()
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "apiVersion: k0s.k0sproject.io/v1beta1\n")
+	assert.Contains(t, string(data), "kind: ClusterConfig\n")
+	assert.Equal(t, []string{"192.168.1.10"}, clusterConfig.Spec.API.SANs)
+	assert.NotNil(t, clusterConfig.Spec.Network, "defaults should be applied")
+
+	content, mode, err := b.Files[0].Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", string(content))
+	assert.Equal(t, os.FileMode(0644), mode)
+
+	content, mode, err = b.Files[1].Decode()
+	require.NoError(t, err)
+	assert.Equal(t, "bar", string(content))
+	assert.Equal(t, os.FileMode(0600), mode)
+}
+
+func TestParseMachineBootstrap_Worker(t *testing.T) {
+	b, err := ParseMachineBootstrap([]byte(`{"role": "worker", "tokenURL": "https://example.com/token"}`))
+	require.NoError(t, err)
+	assert.False(t, b.IsController())
+	assert.Equal(t, []string{"worker", "--token-url=https://example.com/token"}, b.ServiceArgs("/etc/k0s/k0s.yaml", "/etc/k0s/join-token"))
+
+	data, clusterConfig, err := b.ClusterConfig()
+	require.NoError(t, err)
+	assert.Nil(t, data)
+	assert.NotNil(t, clusterConfig)
+}
+
+func TestParseMachineBootstrap_Invalid(t *testing.T) {
+	for _, test := range []struct {
+		name, doc, err string
+	}{
+		{"unknown_field", `{"role": "worker", "tokne": "x"}`, `unknown field "tokne"`},
+		{"role", `{"role": "etcd"}`, `role: unsupported value "etcd"`},
+		{"token", `{"role": "worker", "token": "x", "tokenURL": "y"}`, "only one of token and tokenURL may be given"},
+		{"worker_config", `{"role": "worker", "config": {}}`, "config: may only be given for controllers"},
+		{"config", `{"role": "controller", "config": {"spec": {"api": {"port": "foo"}}}}`, "config: "},
+		{"file_path", `{"role": "worker", "files": [{"path": "foo"}]}`, `files[0]: path must be absolute: "foo"`},
+		{"file_encoding", `{"role": "worker", "files": [{"path": "/foo", "encoding": "gzip"}]}`, `files[0]: encoding: unsupported value "gzip"`},
+		{"file_permissions", `{"role": "worker", "files": [{"path": "/foo", "permissions": "rw"}]}`, `files[0]: permissions: invalid value "rw"`},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseMachineBootstrap([]byte(test.doc))
+			assert.ErrorContains(t, err, test.err)
+		})
+	}
+}
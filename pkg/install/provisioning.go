@@ -0,0 +1,222 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultBinaryPath is the path to which the k0s binary is downloaded when
+// provisioning a node via cloud-init or Ignition.
+const DefaultBinaryPath = "/usr/local/bin/k0s"
+
+// ProvisioningFile is a file that's written to a node before k0s gets
+// installed.
+type ProvisioningFile struct {
+	Path    string
+	Content []byte
+	Mode    os.FileMode
+}
+
+// ProvisioningSpec describes how to provision a k0s node from an image based
+// pipeline. The generated snippets download the k0s binary, write the given
+// files, install the k0s service for the given role and start it, in that
+// order.
+type ProvisioningSpec struct {
+	// Role is the role passed to "k0s install", i.e. controller or worker.
+	Role string
+	// DownloadURL is the URL from which the k0s binary is downloaded.
+	DownloadURL string
+	// BinaryPath is the path to which the k0s binary is downloaded.
+	BinaryPath string
+	// InstallArgs are the arguments passed to "k0s install <role>".
+	InstallArgs []string
+	// Files are written before k0s is installed, e.g. the k0s config or the
+	// join token.
+	Files []ProvisioningFile
+}
+
+// DownloadURL returns the URL of the k0s binary for the given version and
+// architecture on GitHub.
+func DownloadURL(version, arch string) string {
+	return fmt.Sprintf("https://github.com/k0sproject/k0s/releases/download/%s/k0s-%s-%s", version, version, arch)
+}
+
+func (s *ProvisioningSpec) validate() error {
+	switch s.Role {
+	case "controller", "worker":
+	default:
+		return fmt.Errorf("unsupported role: %q", s.Role)
+	}
+	if s.DownloadURL == "" {
+		return errors.New("download URL must not be empty")
+	}
+	if !strings.HasPrefix(s.BinaryPath, "/") {
+		return fmt.Errorf("binary path must be absolute: %q", s.BinaryPath)
+	}
+	for _, f := range s.Files {
+		if !strings.HasPrefix(f.Path, "/") {
+			return fmt.Errorf("file path must be absolute: %q", f.Path)
+		}
+	}
+	return nil
+}
+
+func (s *ProvisioningSpec) installCommand() []string {
+	return append([]string{s.BinaryPath, "install", s.Role}, s.InstallArgs...)
+}
+
+// CloudInit renders the spec as cloud-config user data. The files are written
+// by cloud-init's write_files module, whereas the binary is downloaded and k0s
+// gets installed and started by the runcmd module, which runs afterwards.
+func (s *ProvisioningSpec) CloudInit() ([]byte, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	type writeFile struct {
+		Path        string `json:"path"`
+		Permissions string `json:"permissions"`
+		Encoding    string `json:"encoding"`
+		Content     string `json:"content"`
+	}
+	var cloudConfig struct {
+		WriteFiles []writeFile `json:"write_files,omitempty"`
+		RunCmd     [][]string  `json:"runcmd"`
+	}
+
+	for _, f := range s.Files {
+		cloudConfig.WriteFiles = append(cloudConfig.WriteFiles, writeFile{
+			Path:        f.Path,
+			Permissions: fmt.Sprintf("%#o", f.Mode.Perm()),
+			Encoding:    "b64",
+			Content:     base64.StdEncoding.EncodeToString(f.Content),
+		})
+	}
+
+	cloudConfig.RunCmd = [][]string{
+		{"curl", "--fail", "--silent", "--show-error", "--location", "--retry", "5", "--create-dirs", "--output", s.BinaryPath, s.DownloadURL},
+		{"chmod", "0755", s.BinaryPath},
+		s.installCommand(),
+		{s.BinaryPath, "start"},
+	}
+
+	data, err := yaml.Marshal(&cloudConfig)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("#cloud-config\n"), data...), nil
+}
+
+// Ignition renders the spec as an Ignition config. Ignition downloads the
+// binary and writes the files before the system boots. A oneshot systemd
+// unit installs and starts k0s on the first boot.
+func (s *ProvisioningSpec) Ignition() ([]byte, error) {
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+
+	type contents struct {
+		Source string `json:"source"`
+	}
+	type file struct {
+		Path      string   `json:"path"`
+		Mode      int      `json:"mode"`
+		Overwrite bool     `json:"overwrite"`
+		Contents  contents `json:"contents"`
+	}
+	type unit struct {
+		Name     string `json:"name"`
+		Enabled  bool   `json:"enabled"`
+		Contents string `json:"contents"`
+	}
+	var config struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+		Storage struct {
+			Files []file `json:"files"`
+		} `json:"storage"`
+		Systemd struct {
+			Units []unit `json:"units"`
+		} `json:"systemd"`
+	}
+
+	config.Ignition.Version = "3.3.0"
+	config.Storage.Files = append(config.Storage.Files, file{
+		Path:      s.BinaryPath,
+		Mode:      0755,
+		Overwrite: true,
+		Contents:  contents{Source: s.DownloadURL},
+	})
+	for _, f := range s.Files {
+		config.Storage.Files = append(config.Storage.Files, file{
+			Path:      f.Path,
+			Mode:      int(f.Mode.Perm()),
+			Overwrite: true,
+			Contents:  contents{Source: "data:;base64," + base64.StdEncoding.EncodeToString(f.Content)},
+		})
+	}
+
+	var unitContents strings.Builder
+	fmt.Fprintf(&unitContents, `[Unit]
+Description=Install and start k0s %[1]s
+Wants=network-online.target
+After=network-online.target
+ConditionPathExists=!/etc/systemd/system/k0s%[1]s.service
+
+[Service]
+Type=oneshot
+RemainAfterExit=yes
+ExecStart=%[2]s
+ExecStart=%[3]s
+
+[Install]
+WantedBy=multi-user.target
+`, s.Role, systemdCommandLine(s.installCommand()), systemdCommandLine([]string{s.BinaryPath, "start"}))
+
+	config.Systemd.Units = append(config.Systemd.Units, unit{
+		Name:     "k0s-install.service",
+		Enabled:  true,
+		Contents: unitContents.String(),
+	})
+
+	return json.MarshalIndent(&config, "", "  ")
+}
+
+// systemdCommandLine quotes the given arguments so that systemd splits them
+// back into the very same arguments and doesn't expand any specifiers or
+// environment variables.
+func systemdCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		arg = strings.NewReplacer("%", "%%", "$", "$$").Replace(arg)
+		if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\;") {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`).Replace(arg) + `"`
+	}
+	return strings.Join(quoted, " ")
+}
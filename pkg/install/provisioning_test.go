@@ -0,0 +1,134 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+)
+
+func testProvisioningSpec() *ProvisioningSpec {
+	return &ProvisioningSpec{
+		Role:        "worker",
+		DownloadURL: DownloadURL("v1.28.2+k0s.0", "amd64"),
+		BinaryPath:  DefaultBinaryPath,
+		InstallArgs: []string{"--token-file=/etc/k0s/join-token", "--labels=foo=bar baz"},
+		Files: []ProvisioningFile{
+			{Path: "/etc/k0s/join-token", Content: []byte("token"), Mode: 0600},
+		},
+	}
+}
+
+func TestProvisioningSpec_CloudInit(t *testing.T) {
+	data, err := testProvisioningSpec().CloudInit()
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(string(data), "#cloud-config\n"))
+
+	var cloudConfig struct {
+		WriteFiles []map[string]string `json:"write_files"`
+		RunCmd     [][]string          `json:"runcmd"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &cloudConfig))
+
+	assert.Equal(t, []map[string]string{{
+		"path":        "/etc/k0s/join-token",
+		"permissions": "0600",
+		"encoding":    "b64",
+		"content":     "dG9rZW4=",
+	}}, cloudConfig.WriteFiles)
+
+	if assert.Len(t, cloudConfig.RunCmd, 4) {
+		download := cloudConfig.RunCmd[0]
+		assert.Equal(t, "curl", download[0])
+		assert.Equal(t, "https://github.com/k0sproject/k0s/releases/download/v1.28.2+k0s.0/k0s-v1.28.2+k0s.0-amd64", download[len(download)-1])
+		assert.Equal(t, []string{"chmod", "0755", "/usr/local/bin/k0s"}, cloudConfig.RunCmd[1])
+		assert.Equal(t, []string{
+			"/usr/local/bin/k0s", "install", "worker",
+			"--token-file=/etc/k0s/join-token", "--labels=foo=bar baz",
+		}, cloudConfig.RunCmd[2])
+		assert.Equal(t, []string{"/usr/local/bin/k0s", "start"}, cloudConfig.RunCmd[3])
+	}
+}
+
+func TestProvisioningSpec_Ignition(t *testing.T) {
+	data, err := testProvisioningSpec().Ignition()
+	require.NoError(t, err)
+
+	var config struct {
+		Ignition struct {
+			Version string `json:"version"`
+		} `json:"ignition"`
+		Storage struct {
+			Files []struct {
+				Path     string `json:"path"`
+				Mode     int    `json:"mode"`
+				Contents struct {
+					Source string `json:"source"`
+				} `json:"contents"`
+			} `json:"files"`
+		} `json:"storage"`
+		Systemd struct {
+			Units []struct {
+				Name     string `json:"name"`
+				Enabled  bool   `json:"enabled"`
+				Contents string `json:"contents"`
+			} `json:"units"`
+		} `json:"systemd"`
+	}
+	require.NoError(t, json.Unmarshal(data, &config))
+
+	assert.Equal(t, "3.3.0", config.Ignition.Version)
+	if assert.Len(t, config.Storage.Files, 2) {
+		assert.Equal(t, "/usr/local/bin/k0s", config.Storage.Files[0].Path)
+		assert.Equal(t, 0755, config.Storage.Files[0].Mode)
+		assert.Equal(t, "/etc/k0s/join-token", config.Storage.Files[1].Path)
+		assert.Equal(t, 0600, config.Storage.Files[1].Mode)
+		assert.Equal(t, "data:;base64,dG9rZW4=", config.Storage.Files[1].Contents.Source)
+	}
+	if assert.Len(t, config.Systemd.Units, 1) {
+		unit := config.Systemd.Units[0]
+		assert.Equal(t, "k0s-install.service", unit.Name)
+		assert.True(t, unit.Enabled)
+		assert.Contains(t, unit.Contents, "ConditionPathExists=!/etc/systemd/system/k0sworker.service\n")
+		assert.Contains(t, unit.Contents, `ExecStart=/usr/local/bin/k0s install worker --token-file=/etc/k0s/join-token "--labels=foo=bar baz"`+"\n")
+		assert.Contains(t, unit.Contents, "ExecStart=/usr/local/bin/k0s start\n")
+	}
+}
+
+func TestProvisioningSpec_Validate(t *testing.T) {
+	spec := testProvisioningSpec()
+	spec.Role = "etcd"
+	_, err := spec.CloudInit()
+	assert.ErrorContains(t, err, `unsupported role: "etcd"`)
+
+	spec = testProvisioningSpec()
+	spec.BinaryPath = "k0s"
+	_, err = spec.Ignition()
+	assert.ErrorContains(t, err, `binary path must be absolute: "k0s"`)
+}
+
+func TestSystemdCommandLine(t *testing.T) {
+	assert.Equal(t,
+		`/bin/echo "" "a b" "\"quoted\"" 100%% $$HOME "back\\slash"`,
+		systemdCommandLine([]string{"/bin/echo", "", "a b", `"quoted"`, "100%", "$HOME", `back\slash`}),
+	)
+}
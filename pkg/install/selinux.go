@@ -0,0 +1,43 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"github.com/k0sproject/k0s/internal/pkg/dir"
+	"github.com/k0sproject/k0s/internal/pkg/selinux"
+	"github.com/k0sproject/k0s/pkg/constant"
+)
+
+// EnsureSELinuxLabels labels the k0s directories for use with SELinux. The
+// data and binary directories are created upfront, so that files that k0s
+// creates in there later on inherit the proper labels. The kubelet directories
+// are labeled by the kubelet component when it starts, as they might not exist
+// yet. It's a no-op if SELinux is disabled.
+func EnsureSELinuxLabels(k0sVars constant.CfgVars) error {
+	if !selinux.Enabled() {
+		return nil
+	}
+
+	if err := dir.Init(k0sVars.DataDir, constant.DataDirMode); err != nil {
+		return err
+	}
+	if err := dir.Init(k0sVars.BinDir, constant.BinDirMode); err != nil {
+		return err
+	}
+
+	return selinux.LabelDirs(k0sVars.DataDir, k0sVars.BinDir, k0sVars.RunDir)
+}
@@ -111,7 +111,17 @@ func EnsureService(args []string, envVars []string, force bool) error {
 			"SystemdScript": systemdScript,
 			"LimitNOFILE":   999999,
 		}
+	case "windows-service":
+		// The Windows service manager doesn't know about Environment lines,
+		// the variables are stored in the service's registry key instead.
+		svcConfig.EnvVars = prepareEnvVars(envVars)
+		svcConfig.Option = map[string]interface{}{
+			"DelayedAutoStart":       true,
+			"OnFailure":              "restart",
+			"OnFailureDelayDuration": "5s",
+		}
 	default:
+		svcConfig.Option = map[string]interface{}{}
 	}
 
 	if len(envVars) > 0 {
@@ -199,7 +209,7 @@ func GetServiceConfig(role string) *service.Config {
 func prepareEnvVars(envVars []string) map[string]string {
 	result := make(map[string]string)
 	for _, envVar := range envVars {
-		parts := strings.SplitN(envVar, "=", 1)
+		parts := strings.SplitN(envVar, "=", 2)
 		if len(parts) != 2 {
 			continue
 		}
@@ -0,0 +1,77 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package install
+
+import (
+	"context"
+	"os"
+
+	"github.com/kardianos/service"
+	"github.com/sirupsen/logrus"
+)
+
+// RunAsService runs fn with the given context. If the process has been
+// started by the Windows service control manager, fn is run through the
+// service protocol, so that the service is reported as running and gets
+// stopped gracefully.
+func RunAsService(ctx context.Context, role string, fn func(context.Context) error) error {
+	if service.Interactive() {
+		return fn(ctx)
+	}
+
+	prg := &serviceProgram{ctx: ctx, run: fn}
+	s, err := service.New(prg, GetServiceConfig(role))
+	if err != nil {
+		return err
+	}
+	if err := s.Run(); err != nil {
+		return err
+	}
+	return prg.err
+}
+
+type serviceProgram struct {
+	ctx context.Context
+	run func(context.Context) error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+}
+
+func (p *serviceProgram) Start(service.Service) error {
+	ctx, cancel := context.WithCancel(p.ctx)
+	p.cancel, p.done = cancel, make(chan struct{})
+	go func() {
+		defer close(p.done)
+		err := p.run(ctx)
+		if ctx.Err() == nil {
+			// Terminate the process, so that the service control manager
+			// notices the failure and applies the service's recovery actions.
+			logrus.WithError(err).Error("Service terminated unexpectedly")
+			os.Exit(1)
+		}
+		p.err = err
+	}()
+	return nil
+}
+
+func (p *serviceProgram) Stop(service.Service) error {
+	p.cancel()
+	<-p.done
+	return nil
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package k0scloudprovider
 
 import (
+	"fmt"
+	"sort"
+
 	v1 "k8s.io/api/core/v1"
 	cloudproviderapi "k8s.io/cloud-provider/api"
 )
@@ -28,9 +31,35 @@ const (
 // AddressCollector finds addresses on a node.
 type AddressCollector func(node *v1.Node) []v1.NodeAddress
 
+// DefaultAddressAnnotations returns the node annotations that are searched
+// for addresses by default, along with the type of the address they contain.
+func DefaultAddressAnnotations() map[string]v1.NodeAddressType {
+	return map[string]v1.NodeAddressType{
+		ExternalIPAnnotation: v1.NodeExternalIP,
+	}
+}
+
+// ParseAddressType parses a node address type.
+func ParseAddressType(addressType string) (v1.NodeAddressType, error) {
+	switch t := v1.NodeAddressType(addressType); t {
+	case v1.NodeHostName, v1.NodeInternalIP, v1.NodeExternalIP, v1.NodeInternalDNS, v1.NodeExternalDNS:
+		return t, nil
+	default:
+		return "", fmt.Errorf("unsupported node address type: %q", addressType)
+	}
+}
+
 // DefaultAddressCollector finds all of the internal and external IP addresses defined on
 // the provided node.
 func DefaultAddressCollector() AddressCollector {
+	return NewAddressCollector(DefaultAddressAnnotations(), nil)
+}
+
+// NewAddressCollector finds the internal IP addresses of the provided node, as
+// well as the addresses in the given annotations. The addresses are ordered by
+// the given preferred address types. Addresses of other types are reported
+// last, in the order in which they have been found.
+func NewAddressCollector(annotations map[string]v1.NodeAddressType, preferredTypes []v1.NodeAddressType) AddressCollector {
 	return func(node *v1.Node) []v1.NodeAddress {
 		if node == nil {
 			return []v1.NodeAddress{}
@@ -39,7 +68,8 @@ func DefaultAddressCollector() AddressCollector {
 		addresses := make([]v1.NodeAddress, 0)
 
 		populateInternalAddress(&addresses, node)
-		populateExternalAddress(&addresses, node)
+		populateAnnotatedAddresses(&addresses, node, annotations)
+		sortAddresses(addresses, preferredTypes)
 
 		return addresses
 	}
@@ -73,15 +103,58 @@ func populateInternalAddress(addrs *[]v1.NodeAddress, node *v1.Node) {
 	}
 }
 
-// populateExternalAddress finds the current "ExternalIP" address as defined by the special
-// k0s label on the provided node.
-func populateExternalAddress(addrs *[]v1.NodeAddress, node *v1.Node) {
+// populateAnnotatedAddresses finds the addresses defined by the given
+// annotations on the provided node, e.g. the "ExternalIP" address defined by
+// the special k0s annotation.
+func populateAnnotatedAddresses(addrs *[]v1.NodeAddress, node *v1.Node, annotations map[string]v1.NodeAddressType) {
 	if addrs == nil || node == nil {
 		return
 	}
 
-	// Search the nodes annotations for any external IP address definitions.
-	if externalIP, ok := node.Annotations[ExternalIPAnnotation]; ok {
-		*addrs = append(*addrs, v1.NodeAddress{Type: v1.NodeExternalIP, Address: externalIP})
+	// Iterate in a stable order, so that addresses don't flap between syncs.
+	keys := make([]string, 0, len(annotations))
+	for key := range annotations {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		address, ok := node.Annotations[key]
+		if !ok {
+			continue
+		}
+		addr := v1.NodeAddress{Type: annotations[key], Address: address}
+		if !containsAddress(*addrs, addr) {
+			*addrs = append(*addrs, addr)
+		}
+	}
+}
+
+func containsAddress(addrs []v1.NodeAddress, addr v1.NodeAddress) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// sortAddresses orders the addresses by the given preferred address types.
+func sortAddresses(addrs []v1.NodeAddress, preferredTypes []v1.NodeAddressType) {
+	if len(preferredTypes) == 0 {
+		return
+	}
+
+	rank := func(t v1.NodeAddressType) int {
+		for i, preferred := range preferredTypes {
+			if t == preferred {
+				return i
+			}
+		}
+		return len(preferredTypes)
 	}
+
+	sort.SliceStable(addrs, func(i, j int) bool {
+		return rank(addrs[i].Type) < rank(addrs[j].Type)
+	})
 }
@@ -117,7 +117,7 @@ func TestPopulateInternalAddress(t *testing.T) {
 	}
 }
 
-// populateExternalAddress
+// populateAnnotatedAddresses
 
 var testDataPopulateExternalAddress = []populateAddressTestData{
 	{
@@ -155,7 +155,7 @@ func TestPopulateExternalAddress(t *testing.T) {
 	for _, tt := range testDataPopulateExternalAddress {
 		t.Run(tt.name, func(t *testing.T) {
 			addrs := make([]v1.NodeAddress, 0)
-			populateExternalAddress(&addrs, tt.input)
+			populateAnnotatedAddresses(&addrs, tt.input, DefaultAddressAnnotations())
 
 			if !reflect.DeepEqual(addrs, tt.output) {
 				t.Errorf("got %q, expected %q", addrs, tt.output)
@@ -163,3 +163,49 @@ func TestPopulateExternalAddress(t *testing.T) {
 		})
 	}
 }
+
+// TestNewAddressCollector verifies that custom annotations and the preferred
+// address types are respected.
+func TestNewAddressCollector(t *testing.T) {
+	collector := NewAddressCollector(map[string]v1.NodeAddressType{
+		ExternalIPAnnotation:   v1.NodeExternalIP,
+		"example.com/hostname": v1.NodeHostName,
+		"example.com/dns":      v1.NodeExternalDNS,
+	}, []v1.NodeAddressType{v1.NodeExternalIP, v1.NodeHostName})
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				ExternalIPAnnotation:   "5.6.7.8",
+				"example.com/hostname": "node.example.com",
+				"example.com/dns":      "node.example.com",
+			},
+		},
+		Status: v1.NodeStatus{
+			Addresses: []v1.NodeAddress{
+				{Type: v1.NodeInternalIP, Address: "1.2.3.4"},
+			},
+		},
+	}
+
+	expected := []v1.NodeAddress{
+		{Type: v1.NodeExternalIP, Address: "5.6.7.8"},
+		{Type: v1.NodeHostName, Address: "node.example.com"},
+		{Type: v1.NodeInternalIP, Address: "1.2.3.4"},
+		{Type: v1.NodeExternalDNS, Address: "node.example.com"},
+	}
+
+	if addrs := collector(node); !reflect.DeepEqual(addrs, expected) {
+		t.Errorf("got %q, expected %q", addrs, expected)
+	}
+}
+
+// TestParseAddressType verifies that only valid address types are accepted.
+func TestParseAddressType(t *testing.T) {
+	if addressType, err := ParseAddressType("ExternalIP"); err != nil || addressType != v1.NodeExternalIP {
+		t.Errorf("got %q and %v, expected %q", addressType, err, v1.NodeExternalIP)
+	}
+	if _, err := ParseAddressType("PublicIP"); err == nil {
+		t.Error("expected an error for an unsupported address type")
+	}
+}
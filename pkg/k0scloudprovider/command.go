@@ -51,9 +51,14 @@ type Command func(stopCh <-chan struct{})
 
 type Config struct {
 	AddressCollector AddressCollector
-	KubeConfig       string
-	BindPort         int
-	UpdateFrequency  time.Duration
+	// ProviderID generates the provider ID of nodes that don't have one.
+	// If nil, nodes are left without a provider ID.
+	ProviderID ProviderIDGenerator
+	// TopologyLabels enables setting the region and zone labels of nodes.
+	TopologyLabels  bool
+	KubeConfig      string
+	BindPort        int
+	UpdateFrequency time.Duration
 }
 
 // NewCommand creates a new k0s-cloud-provider based on a configuration.
@@ -77,8 +82,8 @@ func NewCommand(c Config) (Command, error) {
 	}
 
 	cloudInitializer := func(*config.CompletedConfig) cloudprovider.Interface {
-		// Returns the "k0s cloud provider" using the specified `AddressCollector`
-		return newProvider(c.AddressCollector)
+		// Returns the "k0s cloud provider" using the specified `Config`
+		return newProvider(c)
 	}
 
 	// K0s only supports the cloud-node controller, so only use that.
@@ -18,6 +18,7 @@ package k0scloudprovider
 
 import (
 	"context"
+	"fmt"
 
 	v1 "k8s.io/api/core/v1"
 	cloudprovider "k8s.io/cloud-provider"
@@ -27,14 +28,20 @@ import (
 
 type instancesV2 struct {
 	addressCollector AddressCollector
+	providerID       ProviderIDGenerator
+	topologyLabels   bool
 }
 
 var _ cloudprovider.InstancesV2 = (*instancesV2)(nil)
 
 // newInstancesV2 creates a new `cloudprovider.InstancesV2` using the
-// provided `AddressCollector`
-func newInstancesV2(ac AddressCollector) cloudprovider.InstancesV2 {
-	return &instancesV2{addressCollector: ac}
+// provided `Config`
+func newInstancesV2(c Config) cloudprovider.InstancesV2 {
+	return &instancesV2{
+		addressCollector: c.AddressCollector,
+		providerID:       c.ProviderID,
+		topologyLabels:   c.TopologyLabels,
+	}
 }
 
 // InstanceExists returns true if the instance for the given node exists according to the cloud provider.
@@ -55,9 +62,24 @@ func (i *instancesV2) InstanceShutdown(ctx context.Context, node *v1.Node) (bool
 // for a given node. In cases where node.spec.providerID is empty, implementations can use other
 // properties of the node like its name, labels and annotations.
 func (i *instancesV2) InstanceMetadata(ctx context.Context, node *v1.Node) (*cloudprovider.InstanceMetadata, error) {
-	return &cloudprovider.InstanceMetadata{
-		ProviderID:    node.Spec.ProviderID,
+	providerID := node.Spec.ProviderID
+	if providerID == "" && i.providerID != nil {
+		var err error
+		if providerID, err = i.providerID(node); err != nil {
+			return nil, fmt.Errorf("failed to generate provider ID for node %s: %w", node.Name, err)
+		}
+	}
+
+	metadata := &cloudprovider.InstanceMetadata{
+		ProviderID:    providerID,
 		InstanceType:  Name,
 		NodeAddresses: i.addressCollector(node),
-	}, nil
+	}
+
+	if i.topologyLabels {
+		metadata.Region = topologyValue(node, RegionLabel)
+		metadata.Zone = topologyValue(node, ZoneLabel)
+	}
+
+	return metadata, nil
 }
@@ -0,0 +1,71 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k0scloudprovider
+
+import (
+	"errors"
+	"strings"
+	"text/template"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	// RegionLabel is the node label or annotation from which the node's
+	// region is taken, if topology labels are enabled.
+	RegionLabel = "k0sproject.io/node-region"
+	// ZoneLabel is the node label or annotation from which the node's zone is
+	// taken, if topology labels are enabled.
+	ZoneLabel = "k0sproject.io/node-zone"
+)
+
+// ProviderIDGenerator generates the provider ID for nodes that don't have one.
+type ProviderIDGenerator func(node *v1.Node) (string, error)
+
+// NewProviderIDTemplate parses the given Go template into a
+// ProviderIDGenerator. The template may refer to the node's .Name, .Labels and
+// .Annotations, e.g. "k0s://{{ .Name }}".
+func NewProviderIDTemplate(text string) (ProviderIDGenerator, error) {
+	tmpl, err := template.New("providerID").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(node *v1.Node) (string, error) {
+		var providerID strings.Builder
+		if err := tmpl.Execute(&providerID, struct {
+			Name        string
+			Labels      map[string]string
+			Annotations map[string]string
+		}{node.Name, node.Labels, node.Annotations}); err != nil {
+			return "", err
+		}
+		if providerID.Len() == 0 {
+			return "", errors.New("template rendered an empty provider ID")
+		}
+		return providerID.String(), nil
+	}, nil
+}
+
+// topologyValue returns the value of the given key, taken from the node's
+// annotations or, if not annotated, its labels.
+func topologyValue(node *v1.Node, key string) string {
+	if value, ok := node.Annotations[key]; ok {
+		return value
+	}
+	return node.Labels[key]
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package k0scloudprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewProviderIDTemplate(t *testing.T) {
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "worker-0",
+			Labels: map[string]string{"rack": "r1"},
+		},
+	}
+
+	providerID, err := NewProviderIDTemplate(`k0s://{{ index .Labels "rack" }}/{{ .Name }}`)
+	require.NoError(t, err)
+	id, err := providerID(node)
+	require.NoError(t, err)
+	assert.Equal(t, "k0s://r1/worker-0", id)
+
+	providerID, err = NewProviderIDTemplate(`{{ if false }}k0s://{{ .Name }}{{ end }}`)
+	require.NoError(t, err)
+	_, err = providerID(node)
+	assert.ErrorContains(t, err, "empty provider ID")
+
+	_, err = NewProviderIDTemplate(`k0s://{{ .Name `)
+	assert.Error(t, err)
+}
+
+func TestInstanceMetadata(t *testing.T) {
+	providerID, err := NewProviderIDTemplate("k0s://{{ .Name }}")
+	require.NoError(t, err)
+
+	node := &v1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "worker-0",
+			Labels:      map[string]string{RegionLabel: "eu", ZoneLabel: "eu-1"},
+			Annotations: map[string]string{ZoneLabel: "eu-2"},
+		},
+	}
+
+	t.Run("defaults", func(t *testing.T) {
+		instances := newInstancesV2(Config{AddressCollector: DefaultAddressCollector()})
+		metadata, err := instances.InstanceMetadata(context.TODO(), node)
+		require.NoError(t, err)
+		assert.Empty(t, metadata.ProviderID)
+		assert.Empty(t, metadata.Region)
+		assert.Empty(t, metadata.Zone)
+	})
+
+	t.Run("provider_id_and_topology", func(t *testing.T) {
+		instances := newInstancesV2(Config{
+			AddressCollector: DefaultAddressCollector(),
+			ProviderID:       providerID,
+			TopologyLabels:   true,
+		})
+		metadata, err := instances.InstanceMetadata(context.TODO(), node)
+		require.NoError(t, err)
+		assert.Equal(t, "k0s://worker-0", metadata.ProviderID)
+		assert.Equal(t, "eu", metadata.Region)
+		assert.Equal(t, "eu-2", metadata.Zone, "annotations should take precedence over labels")
+	})
+
+	t.Run("existing_provider_id", func(t *testing.T) {
+		node := node.DeepCopy()
+		node.Spec.ProviderID = "aws:///eu-1/i-1234"
+		instances := newInstancesV2(Config{
+			AddressCollector: DefaultAddressCollector(),
+			ProviderID:       providerID,
+		})
+		metadata, err := instances.InstanceMetadata(context.TODO(), node)
+		require.NoError(t, err)
+		assert.Equal(t, "aws:///eu-1/i-1234", metadata.ProviderID)
+	})
+}
@@ -30,11 +30,10 @@ type provider struct {
 
 var _ cloudprovider.Interface = (*provider)(nil)
 
-// newProvider creates a new cloud provider using the provided
-// `AddressCollector`
-func newProvider(ac AddressCollector) *provider {
+// newProvider creates a new cloud provider using the provided `Config`
+func newProvider(c Config) *provider {
 	return &provider{
-		instances: newInstancesV2(ac),
+		instances: newInstancesV2(c),
 	}
 }
 
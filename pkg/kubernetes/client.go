@@ -18,8 +18,10 @@ package kubernetes
 
 import (
 	"fmt"
+	"path/filepath"
 	"sync"
 
+	"github.com/k0sproject/k0s/internal/pkg/file"
 	cfgClient "github.com/k0sproject/k0s/pkg/client/clientset/typed/k0s/v1beta1"
 	"github.com/k0sproject/k0s/pkg/constant"
 
@@ -45,6 +47,8 @@ type ClientFactoryInterface interface {
 func NewAdminClientFactory(k0sVars constant.CfgVars) ClientFactoryInterface {
 	return &ClientFactory{
 		configPath: k0sVars.AdminKubeConfigPath,
+		certFile:   filepath.Join(k0sVars.CertRootDir, "admin.crt"),
+		keyFile:    filepath.Join(k0sVars.CertRootDir, "admin.key"),
 	}
 }
 
@@ -53,6 +57,9 @@ func NewAdminClientFactory(k0sVars constant.CfgVars) ClientFactoryInterface {
 // the factory itself to components needing kube clients and creation time.
 type ClientFactory struct {
 	configPath string
+	// The client certificate is loaded from these files instead of the
+	// kubeconfig, if they exist, so that it's reloaded when it's renewed.
+	certFile, keyFile string
 
 	client          kubernetes.Interface
 	dynamicClient   dynamic.Interface
@@ -69,7 +76,7 @@ func (c *ClientFactory) GetClient() (kubernetes.Interface, error) {
 	var err error
 
 	if c.restConfig == nil {
-		c.restConfig, err = clientcmd.BuildConfigFromFlags("", c.configPath)
+		c.restConfig, err = c.loadRESTConfig()
 		if err != nil {
 			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 		}
@@ -99,7 +106,7 @@ func (c *ClientFactory) GetDynamicClient() (dynamic.Interface, error) {
 	defer c.mutex.Unlock()
 	var err error
 	if c.restConfig == nil {
-		c.restConfig, err = clientcmd.BuildConfigFromFlags("", c.configPath)
+		c.restConfig, err = c.loadRESTConfig()
 		if err != nil {
 			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 		}
@@ -129,7 +136,7 @@ func (c *ClientFactory) GetDiscoveryClient() (discovery.CachedDiscoveryInterface
 	defer c.mutex.Unlock()
 	var err error
 	if c.restConfig == nil {
-		c.restConfig, err = clientcmd.BuildConfigFromFlags("", c.configPath)
+		c.restConfig, err = c.loadRESTConfig()
 		if err != nil {
 			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 		}
@@ -154,7 +161,7 @@ func (c *ClientFactory) GetConfigClient() (cfgClient.ClusterConfigInterface, err
 	defer c.mutex.Unlock()
 	var err error
 	if c.restConfig == nil {
-		c.restConfig, err = clientcmd.BuildConfigFromFlags("", c.configPath)
+		c.restConfig, err = c.loadRESTConfig()
 		if err != nil {
 			return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
 		}
@@ -171,6 +178,19 @@ func (c *ClientFactory) GetConfigClient() (cfgClient.ClusterConfigInterface, err
 	return c.configClient, nil
 }
 
+func (c *ClientFactory) loadRESTConfig() (*rest.Config, error) {
+	config, err := clientcmd.BuildConfigFromFlags("", c.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if file.Exists(c.certFile) && file.Exists(c.keyFile) {
+		// client-go only reloads certificates that are given as files.
+		config.CertData, config.KeyData = nil, nil
+		config.CertFile, config.KeyFile = c.certFile, c.keyFile
+	}
+	return config, nil
+}
+
 func (c *ClientFactory) GetRESTClient() (rest.Interface, error) {
 	cs, ok := c.client.(*kubernetes.Clientset)
 	if !ok {
@@ -49,6 +49,7 @@ type LeaseConfiguration struct {
 	duration      time.Duration
 	renewDeadline time.Duration
 	retryPeriod   time.Duration
+	onNewLeader   func(identity string)
 	log           logrus.FieldLogger
 	ctx           context.Context
 }
@@ -80,6 +81,15 @@ func WithRetryPeriod(retryPeriod time.Duration) LeaseOpt {
 	}
 }
 
+// WithNewLeaderCallback sets a function that's called with the identity of
+// the lease holder whenever a new lease holder is observed
+func WithNewLeaderCallback(fn func(identity string)) LeaseOpt {
+	return func(config LeaseConfiguration) LeaseConfiguration {
+		config.onNewLeader = fn
+		return config
+	}
+}
+
 // WithLogger allows the consumer to pass a different logrus entry with additional context
 func WithLogger(logger logrus.FieldLogger) LeaseOpt {
 	if logger == nil {
@@ -209,7 +219,7 @@ func (p *LeasePool) Watch(opts ...WatchOpt) (*LeaseEvents, context.CancelFunc, e
 				p.config.log.Info("Lost leader lease")
 				p.events.LostLease <- struct{}{}
 			},
-			OnNewLeader: nil,
+			OnNewLeader: p.config.onNewLeader,
 		},
 	}
 	le, err := leaderelection.NewLeaderElector(lec)
@@ -73,6 +73,34 @@ func TestLeasePoolWatcherTriggersOnLeaseAcquisition(t *testing.T) {
 	}
 }
 
+func TestLeasePoolReportsNewLeader(t *testing.T) {
+	const identity = "test-node"
+
+	fakeClient := fake.NewSimpleClientset()
+
+	leaders := make(chan string, 1)
+	pool, err := NewLeasePool(context.TODO(), fakeClient, "test",
+		WithIdentity(identity), WithNamespace("test"),
+		WithNewLeaderCallback(func(identity string) { leaders <- identity }),
+	)
+	require.NoError(t, err)
+
+	events, cancel, err := pool.Watch(WithOutputChannels(&LeaseEvents{
+		AcquiredLease: make(chan struct{}, 1),
+		LostLease:     make(chan struct{}, 1),
+	}))
+	require.NoError(t, err)
+	defer cancel()
+
+	<-events.AcquiredLease
+	select {
+	case leader := <-leaders:
+		assert.Equal(t, identity, leader)
+	case <-time.After(10 * time.Second):
+		assert.Fail(t, "Timed out while waiting for the new leader to be reported")
+	}
+}
+
 func TestLeasePoolTriggersLostLeaseWhenCancelled(t *testing.T) {
 	const identity = "test-node"
 
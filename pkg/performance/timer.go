@@ -17,9 +17,13 @@ limitations under the License.
 package performance
 
 import (
+	"encoding/json"
 	"errors"
+	"sync"
 	"time"
 
+	"github.com/k0sproject/k0s/internal/pkg/file"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -29,9 +33,29 @@ import (
 // want to see all the recorded timings in a single place, to make comparison easy.
 type Timer struct {
 	log          *logrus.Entry
+	target       string
 	bufferOutput bool
 	startedAt    time.Time
 	buffer       []checkpoint
+
+	// All checkpoints recorded so far, regardless of their output.
+	mu       sync.Mutex
+	recorded []Checkpoint
+}
+
+// Profile is the structured form of all checkpoints recorded by a Timer.
+type Profile struct {
+	Target      string       `json:"target"`
+	StartedAt   time.Time    `json:"startedAt"`
+	Checkpoints []Checkpoint `json:"checkpoints"`
+}
+
+// Checkpoint is a checkpoint recorded by a Timer.
+type Checkpoint struct {
+	Name string `json:"name"`
+	// The time since the timer was started, in nanoseconds.
+	Duration time.Duration `json:"duration"`
+	Error    string        `json:"error,omitempty"`
 }
 
 type checkpoint struct {
@@ -43,6 +67,7 @@ type checkpoint struct {
 func NewTimer(name string) *Timer {
 	return &Timer{
 		log:          logrus.WithField("component", "performance-timer").WithField("target", name),
+		target:       name,
 		bufferOutput: false,
 	}
 }
@@ -65,14 +90,14 @@ func (t *Timer) Start() *Timer {
 func (t *Timer) Checkpoint(name string) {
 	// if the timer was never started, we'll record an errored checkpoint that Output can recognise
 	if t.startedAt.IsZero() {
-		t.buffer = append(t.buffer, checkpoint{
+		t.record(checkpoint{
 			name: name,
 			err:  errors.New("failed to record checkpoint, timer not started"),
 		})
 		return
 	}
 
-	t.buffer = append(t.buffer, checkpoint{
+	t.record(checkpoint{
 		duration: time.Since(t.startedAt),
 		name:     name,
 	})
@@ -82,6 +107,40 @@ func (t *Timer) Checkpoint(name string) {
 	}
 }
 
+func (t *Timer) record(c checkpoint) {
+	t.buffer = append(t.buffer, c)
+
+	recorded := Checkpoint{Name: c.name, Duration: c.duration}
+	if c.err != nil {
+		recorded.Error = c.err.Error()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.recorded = append(t.recorded, recorded)
+}
+
+// Profile returns all checkpoints recorded so far. It may be called
+// concurrently to recording checkpoints.
+func (t *Timer) Profile() Profile {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return Profile{
+		Target:      t.target,
+		StartedAt:   t.startedAt,
+		Checkpoints: append([]Checkpoint{}, t.recorded...),
+	}
+}
+
+// WriteProfile writes all checkpoints recorded so far as JSON to the given
+// path.
+func (t *Timer) WriteProfile(path string) error {
+	data, err := json.MarshalIndent(t.Profile(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return file.WriteContentAtomically(path, append(data, '\n'), 0644)
+}
+
 // Output will loop through the message buffer and output all messages in order.
 func (t *Timer) Output() {
 	for {
@@ -0,0 +1,57 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package performance
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimer_Profile(t *testing.T) {
+	timer := NewTimer("test")
+	timer.Checkpoint("not-started")
+	timer.Buffer().Start()
+	timer.Checkpoint("first")
+	timer.Output()
+	timer.Checkpoint("second")
+
+	profile := timer.Profile()
+	assert.Equal(t, "test", profile.Target)
+	assert.False(t, profile.StartedAt.IsZero())
+	if assert.Len(t, profile.Checkpoints, 3) {
+		assert.Equal(t, "not-started", profile.Checkpoints[0].Name)
+		assert.NotEmpty(t, profile.Checkpoints[0].Error)
+		assert.Equal(t, "first", profile.Checkpoints[1].Name)
+		assert.Empty(t, profile.Checkpoints[1].Error)
+		assert.Equal(t, "second", profile.Checkpoints[2].Name)
+		assert.LessOrEqual(t, profile.Checkpoints[1].Duration, profile.Checkpoints[2].Duration)
+	}
+
+	path := filepath.Join(t.TempDir(), "profile.json")
+	require.NoError(t, timer.WriteProfile(path))
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var written Profile
+	require.NoError(t, json.Unmarshal(data, &written))
+	assert.Equal(t, profile.Checkpoints, written.Checkpoints)
+	assert.True(t, profile.StartedAt.Equal(written.StartedAt))
+}
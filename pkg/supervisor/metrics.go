@@ -0,0 +1,32 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package supervisor
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var restartsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "k0s",
+	Subsystem: "supervisor",
+	Name:      "process_restarts_total",
+	Help:      "Total number of restarts of supervised processes.",
+}, []string{"process"})
+
+func init() {
+	prometheus.MustRegister(restartsTotal)
+}
@@ -33,6 +33,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/k0sproject/k0s/internal/pkg/dir"
+	k0slog "github.com/k0sproject/k0s/internal/pkg/log"
 	"github.com/k0sproject/k0s/pkg/constant"
 )
 
@@ -135,6 +136,9 @@ func (s *Supervisor) Supervise() error {
 		return nil
 	}
 	s.log = logrus.WithField("component", s.Name)
+	if role := k0slog.NodeRole(); role != "" {
+		s.log = s.log.WithField("role", role)
+	}
 	s.PidFile = path.Join(s.RunDir, s.Name) + ".pid"
 	if err := dir.Init(s.RunDir, constant.RunDirMode); err != nil {
 		s.log.Warnf("failed to initialize dir: %v", err)
@@ -207,6 +211,7 @@ func (s *Supervisor) Supervise() error {
 					started <- nil
 				} else {
 					s.log.Infof("Restarted (%d)", restarts)
+					restartsTotal.WithLabelValues(s.Name).Inc()
 				}
 				restarts++
 				if s.processWaitQuit(ctx) {
@@ -18,6 +18,7 @@ package telemetry
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
@@ -32,7 +33,7 @@ import (
 
 // Component is a telemetry component for k0s component manager
 type Component struct {
-	clusterConfig     *v1beta1.ClusterConfig
+	clusterConfig     atomic.Pointer[v1beta1.ClusterConfig]
 	K0sVars           constant.CfgVars
 	Version           string
 	KubeClientFactory kubeutil.ClientFactoryInterface
@@ -84,9 +85,7 @@ func (c *Component) Stop() error {
 		c.log.Info("no token, telemetry is disabled")
 		return nil
 	}
-	if c.stopCh != nil {
-		close(c.stopCh)
-	}
+	c.stopRun()
 	if c.analyticsClient != nil {
 		_ = c.analyticsClient.Close()
 	}
@@ -97,8 +96,11 @@ func (c *Component) Stop() error {
 func (c *Component) Reconcile(ctx context.Context, clusterCfg *v1beta1.ClusterConfig) error {
 	logrus.Debug("reconcile method called for: Telemetry")
 	if !clusterCfg.Spec.Telemetry.Enabled {
-		return c.Stop()
+		c.stopRun()
+		return nil
 	}
+	// The data categories may change while running.
+	c.clusterConfig.Store(clusterCfg)
 	if c.stopCh != nil {
 		// We must have the worker stuff already running, do nothing
 		return nil
@@ -107,24 +109,30 @@ func (c *Component) Reconcile(ctx context.Context, clusterCfg *v1beta1.ClusterCo
 		c.log.Info("no token, telemetry is disabled")
 		return nil
 	}
-	c.clusterConfig = clusterCfg
 	initedCh := make(chan struct{})
 	wait.Until(func() {
 		c.retrieveKubeClient(initedCh)
 	}, time.Second, initedCh)
-	go c.run(ctx)
+	c.stopCh = make(chan struct{})
+	go c.run(ctx, c.stopCh)
 	return nil
 }
 
-func (c Component) run(ctx context.Context) {
-	c.stopCh = make(chan struct{})
+func (c *Component) stopRun() {
+	if c.stopCh != nil {
+		close(c.stopCh)
+		c.stopCh = nil
+	}
+}
+
+func (c *Component) run(ctx context.Context, stopCh <-chan struct{}) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 	for {
 		select {
 		case <-ticker.C:
 			c.sendTelemetry(ctx)
-		case <-c.stopCh:
+		case <-stopCh:
 			return
 		}
 	}
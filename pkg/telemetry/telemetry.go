@@ -18,12 +18,16 @@ package telemetry
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"runtime"
 
 	"github.com/segmentio/analytics-go"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 
 	"github.com/k0sproject/k0s/internal/pkg/sysinfo/machineid"
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
@@ -48,53 +52,71 @@ type workerSums struct {
 	memTotal int64
 }
 
-func (td telemetryData) asProperties() analytics.Properties {
-	return analytics.Properties{
-		"storageType":            td.StorageType,
-		"clusterID":              td.ClusterID,
-		"workerNodesCount":       td.WorkerNodesCount,
-		"controlPlaneNodesCount": td.ControlPlaneNodesCount,
-		"workerData":             td.WorkerData,
-		"memTotal":               td.MEMTotal,
-		"cpuTotal":               td.CPUTotal,
+// asProperties returns the properties of the categories included by the
+// given settings.
+func (td telemetryData) asProperties(settings *v1beta1.ClusterTelemetry) analytics.Properties {
+	props := analytics.Properties{}
+	if settings.Includes(v1beta1.TelemetryCluster) {
+		props["storageType"] = td.StorageType
+		props["workerNodesCount"] = td.WorkerNodesCount
+		props["controlPlaneNodesCount"] = td.ControlPlaneNodesCount
+		props["memTotal"] = td.MEMTotal
+		props["cpuTotal"] = td.CPUTotal
 	}
+	if settings.Includes(v1beta1.TelemetryClusterID) {
+		props["clusterID"] = td.ClusterID
+	}
+	if settings.Includes(v1beta1.TelemetryNodes) {
+		props["workerData"] = td.WorkerData
+	}
+	return props
 }
 
-func (c Component) collectTelemetry(ctx context.Context) (telemetryData, error) {
+func (c *Component) collectTelemetry(ctx context.Context, clusterConfig *v1beta1.ClusterConfig) (telemetryData, error) {
 	var err error
 	data := telemetryData{}
+	settings := clusterConfig.Spec.Telemetry
 
-	data.StorageType = c.getStorageType()
-	data.ClusterID, err = c.getClusterID(ctx)
-
-	if err != nil {
-		return data, fmt.Errorf("can't collect cluster ID: %v", err)
+	if settings.Includes(v1beta1.TelemetryClusterID) {
+		data.ClusterID, err = c.getClusterID(ctx)
+		if err != nil {
+			return data, fmt.Errorf("can't collect cluster ID: %v", err)
+		}
 	}
-	wds, sums, err := c.getWorkerData(ctx)
-	if err != nil {
-		return data, fmt.Errorf("can't collect workers count: %v", err)
+
+	if settings.Includes(v1beta1.TelemetryCluster) || settings.Includes(v1beta1.TelemetryNodes) {
+		wds, sums, err := c.getWorkerData(ctx)
+		if err != nil {
+			return data, fmt.Errorf("can't collect workers count: %v", err)
+		}
+		data.WorkerNodesCount = len(wds)
+		data.WorkerData = wds
+		data.MEMTotal = sums.memTotal
+		data.CPUTotal = sums.cpuTotal
 	}
 
-	data.WorkerNodesCount = len(wds)
-	data.WorkerData = wds
-	data.MEMTotal = sums.memTotal
-	data.CPUTotal = sums.cpuTotal
-	data.ControlPlaneNodesCount, err = kubeutil.GetControlPlaneNodeCount(ctx, c.kubernetesClient)
-	if err != nil {
-		return data, fmt.Errorf("can't collect control plane nodes count: %v", err)
+	if settings.Includes(v1beta1.TelemetryCluster) {
+		data.StorageType = getStorageType(clusterConfig)
+		data.ControlPlaneNodesCount, err = kubeutil.GetControlPlaneNodeCount(ctx, c.kubernetesClient)
+		if err != nil {
+			return data, fmt.Errorf("can't collect control plane nodes count: %v", err)
+		}
 	}
+
 	return data, nil
 }
 
-func (c Component) getStorageType() string {
-	switch c.clusterConfig.Spec.Storage.Type {
-	case v1beta1.EtcdStorageType, v1beta1.KineStorageType:
-		return c.clusterConfig.Spec.Storage.Type
+func getStorageType(clusterConfig *v1beta1.ClusterConfig) string {
+	if clusterConfig.Spec.Storage != nil {
+		switch clusterConfig.Spec.Storage.Type {
+		case v1beta1.EtcdStorageType, v1beta1.KineStorageType:
+			return clusterConfig.Spec.Storage.Type
+		}
 	}
 	return "unknown"
 }
 
-func (c Component) getClusterID(ctx context.Context) (string, error) {
+func (c *Component) getClusterID(ctx context.Context) (string, error) {
 	ns, err := c.kubernetesClient.CoreV1().Namespaces().Get(ctx,
 		"kube-system",
 		metav1.GetOptions{})
@@ -105,7 +127,7 @@ func (c Component) getClusterID(ctx context.Context) (string, error) {
 	return fmt.Sprintf("kube-system:%s", ns.UID), nil
 }
 
-func (c Component) getWorkerData(ctx context.Context) ([]workerData, workerSums, error) {
+func (c *Component) getWorkerData(ctx context.Context) ([]workerData, workerSums, error) {
 	nodes, err := c.kubernetesClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
 	if err != nil {
 		return nil, workerSums{}, err
@@ -130,37 +152,56 @@ func (c Component) getWorkerData(ctx context.Context) ([]workerData, workerSums,
 	return wds, workerSums{cpuTotal: cpuTotal, memTotal: memTotal}, nil
 }
 
-func (c Component) sendTelemetry(ctx context.Context) {
-	data, err := c.collectTelemetry(ctx)
+// buildMessage collects the telemetry data of the categories that are
+// enabled in the given cluster config, and returns the message to be sent.
+func (c *Component) buildMessage(ctx context.Context, clusterConfig *v1beta1.ClusterConfig) (analytics.Track, error) {
+	data, err := c.collectTelemetry(ctx, clusterConfig)
 	if err != nil {
-		c.log.WithError(err).Warning("can't prepare telemetry data")
-		return
+		return analytics.Track{}, err
 	}
+	settings := clusterConfig.Spec.Telemetry
 
 	hostData := analytics.Context{
 		Extra: map[string]interface{}{"direct": true},
 	}
-
 	hostData.App.Version = c.Version
 	hostData.App.Name = "k0s"
 	hostData.App.Namespace = "k0s"
-	hostData.Extra["cpuArch"] = runtime.GOARCH
+	if settings.Includes(v1beta1.TelemetryHost) {
+		hostData.Extra["cpuArch"] = runtime.GOARCH
+		addSysInfo(&hostData)
+	}
+	if settings.Includes(v1beta1.TelemetryCustom) {
+		c.addCustomData(ctx, &hostData)
+	}
 
-	addSysInfo(&hostData)
-	c.addCustomData(ctx, &hostData)
+	anonymousID := sessionID
+	if settings.Includes(v1beta1.TelemetryClusterID) {
+		anonymousID = machineID()
+	}
 
-	c.log.WithField("data", data).WithField("hostdata", hostData).Info("sending telemetry")
-	if err := c.analyticsClient.Enqueue(analytics.Track{
-		AnonymousId: machineID(),
+	return analytics.Track{
+		AnonymousId: anonymousID,
 		Event:       heartbeatEvent,
-		Properties:  data.asProperties(),
+		Properties:  data.asProperties(settings),
 		Context:     &hostData,
-	}); err != nil {
+	}, nil
+}
+
+func (c *Component) sendTelemetry(ctx context.Context) {
+	msg, err := c.buildMessage(ctx, c.clusterConfig.Load())
+	if err != nil {
+		c.log.WithError(err).Warning("can't prepare telemetry data")
+		return
+	}
+
+	c.log.WithField("properties", msg.Properties).WithField("hostdata", msg.Context).Info("sending telemetry")
+	if err := c.analyticsClient.Enqueue(msg); err != nil {
 		c.log.WithError(err).Warning("can't send telemetry data")
 	}
 }
 
-func (c Component) addCustomData(ctx context.Context, analyticCtx *analytics.Context) {
+func (c *Component) addCustomData(ctx context.Context, analyticCtx *analytics.Context) {
 	cm, err := c.kubernetesClient.CoreV1().ConfigMaps("kube-system").Get(ctx, "k0s-telemetry", metav1.GetOptions{})
 	if err != nil {
 		return
@@ -170,6 +211,25 @@ func (c Component) addCustomData(ctx context.Context, analyticCtx *analytics.Con
 	}
 }
 
+// Preview returns the telemetry message that would be sent for the given
+// cluster config, encoded as indented JSON.
+func Preview(ctx context.Context, version string, clusterConfig *v1beta1.ClusterConfig, client kubernetes.Interface) ([]byte, error) {
+	c := &Component{Version: version, kubernetesClient: client}
+	msg, err := c.buildMessage(ctx, clusterConfig)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(msg, "", "  ")
+}
+
+// sessionID identifies the messages of this process if the cluster ID isn't
+// sent.
+var sessionID = func() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}()
+
 func machineID() string {
 	id, _ := machineid.Generate()
 	return id.ID()
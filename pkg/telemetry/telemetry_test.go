@@ -0,0 +1,85 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package telemetry
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPreview(t *testing.T) {
+	client := kubefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system", UID: "uid"}},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "worker"}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "k0s-telemetry"},
+			Data:       map[string]string{"foo": "bar"},
+		},
+	)
+
+	preview := func(t *testing.T, categories ...v1beta1.TelemetryCategory) map[string]any {
+		clusterConfig := v1beta1.DefaultClusterConfig()
+		clusterConfig.Spec.Telemetry.Categories = categories
+		payload, err := Preview(context.TODO(), "v1.2.3+k0s.0", clusterConfig, client)
+		require.NoError(t, err)
+
+		var msg map[string]any
+		require.NoError(t, json.Unmarshal(payload, &msg))
+		return msg
+	}
+
+	t.Run("all", func(t *testing.T) {
+		msg := preview(t)
+		assert.Equal(t, "cluster-heartbeat", msg["event"])
+		assert.Equal(t, machineID(), msg["anonymousId"])
+
+		props := msg["properties"].(map[string]any)
+		assert.Equal(t, "kube-system:uid", props["clusterID"])
+		assert.Equal(t, "etcd", props["storageType"])
+		assert.EqualValues(t, 1, props["workerNodesCount"])
+		assert.Len(t, props["workerData"], 1)
+
+		ctx := msg["context"].(map[string]any)
+		assert.Equal(t, "bar", ctx["custom.foo"])
+		assert.Contains(t, ctx, "cpuArch")
+	})
+
+	t.Run("cluster_only", func(t *testing.T) {
+		msg := preview(t, v1beta1.TelemetryCluster)
+		assert.Equal(t, sessionID, msg["anonymousId"])
+
+		props := msg["properties"].(map[string]any)
+		assert.Equal(t, "etcd", props["storageType"])
+		assert.EqualValues(t, 1, props["workerNodesCount"])
+		assert.NotContains(t, props, "clusterID")
+		assert.NotContains(t, props, "workerData")
+
+		ctx := msg["context"].(map[string]any)
+		assert.NotContains(t, ctx, "custom.foo")
+		assert.NotContains(t, ctx, "cpuArch")
+		assert.Equal(t, "v1.2.3+k0s.0", ctx["app"].(map[string]any)["version"])
+	})
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/avast/retry-go"
+	"github.com/sirupsen/logrus"
+)
+
+// maxTokenSize limits the size of fetched tokens, to guard against bogus responses.
+const maxTokenSize = 64 * 1024
+
+// FetchJoinToken retrieves a join token from the given URL. If authHeaderFile
+// is not empty, it's expected to contain a single HTTP header in the form
+// "Name: value" that will be sent along with the request, e.g. an
+// Authorization header. Failed requests are retried with an exponential
+// backoff for several minutes, as the token might not be available yet.
+func FetchJoinToken(ctx context.Context, url, authHeaderFile string) (string, error) {
+	var authHeaderName, authHeaderValue string
+	if authHeaderFile != "" {
+		var err error
+		authHeaderName, authHeaderValue, err = readHeaderFile(authHeaderFile)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	var token string
+	err := retry.Do(
+		func() (err error) {
+			token, err = fetchJoinToken(ctx, url, authHeaderName, authHeaderValue)
+			return err
+		},
+		retry.Context(ctx),
+		retry.LastErrorOnly(true),
+		retry.Attempts(20),
+		retry.Delay(1*time.Second),
+		retry.MaxDelay(30*time.Second),
+		retry.OnRetry(func(attempt uint, err error) {
+			logrus.WithError(err).Debugf("Failed to fetch join token from %s in attempt #%d, retrying after backoff", url, attempt+1)
+		}),
+	)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch join token from %s: %w", url, err)
+	}
+
+	return token, nil
+}
+
+func fetchJoinToken(ctx context.Context, url, authHeaderName, authHeaderValue string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", retry.Unrecoverable(err)
+	}
+	if authHeaderName != "" {
+		req.Header.Set(authHeaderName, authHeaderValue)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxTokenSize+1))
+	if err != nil {
+		return "", err
+	}
+	if len(body) > maxTokenSize {
+		return "", retry.Unrecoverable(fmt.Errorf("token exceeds %d bytes", maxTokenSize))
+	}
+
+	token := strings.TrimSpace(string(body))
+	if token == "" {
+		return "", fmt.Errorf("empty response")
+	}
+	if _, err := DecodeJoinToken(token); err != nil {
+		return "", retry.Unrecoverable(fmt.Errorf("failed to decode join token: %w", err))
+	}
+
+	return token, nil
+}
+
+func readHeaderFile(path string) (string, string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to read auth header file: %w", err)
+	}
+
+	name, value, found := strings.Cut(strings.TrimSpace(string(content)), ":")
+	name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+	if !found || name == "" || strings.ContainsAny(name, " \t\r\n") {
+		return "", "", fmt.Errorf("auth header file %s doesn't contain a header in the form \"Name: value\"", path)
+	}
+
+	return name, value, nil
+}
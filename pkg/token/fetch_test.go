@@ -0,0 +1,78 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFetchJoinToken(t *testing.T) {
+	joinToken, err := JoinEncode(strings.NewReader("the kubeconfig"))
+	require.NoError(t, err)
+
+	t.Run("sends auth header and retries", func(t *testing.T) {
+		var requests atomic.Int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) == 1 {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.Header.Get("Authorization") != "Bearer secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(joinToken + "\n"))
+		}))
+		defer server.Close()
+
+		headerFile := filepath.Join(t.TempDir(), "header")
+		require.NoError(t, os.WriteFile(headerFile, []byte("Authorization: Bearer secret\n"), 0600))
+
+		token, err := FetchJoinToken(context.TODO(), server.URL, headerFile)
+		require.NoError(t, err)
+		assert.Equal(t, joinToken, token)
+		assert.Equal(t, int32(2), requests.Load())
+	})
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("not a token"))
+		}))
+		defer server.Close()
+
+		_, err := FetchJoinToken(context.TODO(), server.URL, "")
+		assert.ErrorContains(t, err, "failed to decode join token")
+	})
+
+	t.Run("rejects invalid header files", func(t *testing.T) {
+		headerFile := filepath.Join(t.TempDir(), "header")
+		require.NoError(t, os.WriteFile(headerFile, []byte("Bearer secret"), 0600))
+
+		_, err := FetchJoinToken(context.TODO(), "http://localhost", headerFile)
+		assert.ErrorContains(t, err, "doesn't contain a header")
+	})
+}
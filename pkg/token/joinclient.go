@@ -18,6 +18,7 @@ package token
 
 import (
 	"bytes"
+	"context"
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/json"
@@ -27,7 +28,12 @@ import (
 	"os"
 
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/tracing"
 	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
@@ -65,6 +71,9 @@ func JoinClientFromToken(encodedToken string) (*JoinClient, error) {
 	tlsConfig := &tls.Config{
 		InsecureSkipVerify: false,
 		RootCAs:            ca,
+		// Set for tokens that join through the konnectivity tunnel, which
+		// reaches the join API under a different address than it serves.
+		ServerName: config.ServerName,
 	}
 	tr := &http.Transport{TLSClientConfig: tlsConfig}
 	c := &JoinClient{
@@ -79,13 +88,16 @@ func JoinClientFromToken(encodedToken string) (*JoinClient, error) {
 }
 
 // GetCA calls the CA sync API
-func (j *JoinClient) GetCA() (v1beta1.CaResponse, error) {
-	var caData v1beta1.CaResponse
-	req, err := http.NewRequest(http.MethodGet, j.joinAddress+"/v1beta1/ca", nil)
+func (j *JoinClient) GetCA(ctx context.Context) (caData v1beta1.CaResponse, err error) {
+	ctx, span := j.startSpan(ctx, "get CA")
+	defer func() { tracing.End(span, err) }()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.joinAddress+"/v1beta1/ca", nil)
 	if err != nil {
 		return caData, err
 	}
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", j.bearerToken))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 
 	resp, err := j.httpClient.Do(req)
 	if err != nil {
@@ -112,8 +124,10 @@ func (j *JoinClient) GetCA() (v1beta1.CaResponse, error) {
 }
 
 // JoinEtcd calls the etcd join API
-func (j *JoinClient) JoinEtcd(peerAddress string) (v1beta1.EtcdResponse, error) {
-	var etcdResponse v1beta1.EtcdResponse
+func (j *JoinClient) JoinEtcd(ctx context.Context, peerAddress string) (etcdResponse v1beta1.EtcdResponse, err error) {
+	ctx, span := j.startSpan(ctx, "join etcd")
+	defer func() { tracing.End(span, err) }()
+
 	etcdRequest := v1beta1.EtcdRequest{
 		PeerAddress: peerAddress,
 	}
@@ -128,11 +142,12 @@ func (j *JoinClient) JoinEtcd(peerAddress string) (v1beta1.EtcdResponse, error)
 		return etcdResponse, err
 	}
 
-	req, err := http.NewRequest(http.MethodPost, j.joinAddress+"/v1beta1/etcd/members", buf)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.joinAddress+"/v1beta1/etcd/members", buf)
 	if err != nil {
 		return etcdResponse, err
 	}
 	req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", j.bearerToken))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
 	resp, err := j.httpClient.Do(req)
 	if err != nil {
 		return etcdResponse, err
@@ -157,3 +172,10 @@ func (j *JoinClient) JoinEtcd(peerAddress string) (v1beta1.EtcdResponse, error)
 func (j *JoinClient) JoinTokenType() string {
 	return j.joinTokenType
 }
+
+func (j *JoinClient) startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracing.Tracer().Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attribute.String("k0s.join.address", j.joinAddress)),
+	)
+}
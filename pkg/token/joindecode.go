@@ -20,8 +20,12 @@ import (
 	"bytes"
 	"compress/gzip"
 	"encoding/base64"
+	"fmt"
 	"io"
+	"net"
+	"net/url"
 
+	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
@@ -57,3 +61,36 @@ func GetTokenType(clientCfg *clientcmdapi.Config) string {
 
 	return ""
 }
+
+// JoinAddress returns the address in the form of "host:port" of the API that
+// the given join token points to.
+func JoinAddress(encodedToken string) (string, error) {
+	tokenBytes, err := DecodeJoinToken(encodedToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode token: %w", err)
+	}
+
+	clientConfig, err := clientcmd.NewClientConfigFromBytes(tokenBytes)
+	if err != nil {
+		return "", err
+	}
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return "", err
+	}
+
+	joinURL, err := url.Parse(config.Host)
+	if err != nil {
+		return "", err
+	}
+	if joinURL.Host == "" {
+		return "", fmt.Errorf("join URL has no host: %q", config.Host)
+	}
+	if joinURL.Port() != "" {
+		return joinURL.Host, nil
+	}
+	if joinURL.Scheme == "http" {
+		return net.JoinHostPort(joinURL.Hostname(), "80"), nil
+	}
+	return net.JoinHostPort(joinURL.Hostname(), "443"), nil
+}
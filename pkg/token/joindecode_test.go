@@ -0,0 +1,47 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package token
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoinAddress(t *testing.T) {
+	for _, test := range []struct{ joinURL, address string }{
+		{"https://10.0.0.1:9443", "10.0.0.1:9443"},
+		{"https://[fe80::1]:6443", "[fe80::1]:6443"},
+		{"https://k0s.example.com", "k0s.example.com:443"},
+	} {
+		t.Run(test.joinURL, func(t *testing.T) {
+			kubeconfig, err := GenerateKubeconfig(test.joinURL, []byte("the cert"), "the user", "the token")
+			require.NoError(t, err)
+			token, err := JoinEncode(bytes.NewReader(kubeconfig))
+			require.NoError(t, err)
+
+			address, err := JoinAddress(token)
+			assert.NoError(t, err)
+			assert.Equal(t, test.address, address)
+		})
+	}
+
+	_, err := JoinAddress("not a token")
+	assert.ErrorContains(t, err, "failed to decode token")
+}
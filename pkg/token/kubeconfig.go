@@ -19,12 +19,16 @@ package token
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
-	"os"
+	"net"
+	"net/url"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+	"github.com/k0sproject/k0s/pkg/certificate"
 	"github.com/k0sproject/k0s/pkg/constant"
 
 	"k8s.io/client-go/tools/clientcmd"
@@ -36,6 +40,16 @@ const (
 	RoleWorker     = "worker"
 )
 
+// The ports on which the konnectivity agents expose the tunneled APIs on the
+// worker nodes, and the server name under which the APIs are verified when
+// accessed through the tunnel. The tunnel ends on the controllers' loopback
+// interface, which is included in the API server certificates.
+const (
+	tunneledKASPort    = 6443
+	tunneledJoinPort   = 9443
+	tunneledServerName = "localhost"
+)
+
 // CreateKubeletBootstrapToken creates a new k0s bootstrap token.
 func CreateKubeletBootstrapToken(ctx context.Context, api *v1beta1.APISpec, k0sVars constant.CfgVars, role string, expiry time.Duration) (string, error) {
 	userName, joinURL, err := loadUserAndJoinURL(api, role)
@@ -43,6 +57,27 @@ func CreateKubeletBootstrapToken(ctx context.Context, api *v1beta1.APISpec, k0sV
 		return "", err
 	}
 
+	return createBootstrapToken(ctx, k0sVars, role, expiry, userName, &clientcmdapi.Cluster{Server: joinURL})
+}
+
+// CreateTunneledBootstrapToken creates a new k0s bootstrap token that joins
+// through the konnectivity tunnel exposed by the worker node with the given
+// address. This allows nodes to join that can't reach the controllers
+// directly. Requires tunneled networking mode, and additionally the tunneled
+// join API for controller tokens.
+func CreateTunneledBootstrapToken(ctx context.Context, api *v1beta1.APISpec, k0sVars constant.CfgVars, role string, expiry time.Duration, nodeAddress string) (string, error) {
+	userName, joinURL, err := loadUserAndTunneledJoinURL(api, role, nodeAddress)
+	if err != nil {
+		return "", err
+	}
+
+	return createBootstrapToken(ctx, k0sVars, role, expiry, userName, &clientcmdapi.Cluster{
+		Server:        joinURL,
+		TLSServerName: tunneledServerName,
+	})
+}
+
+func createBootstrapToken(ctx context.Context, k0sVars constant.CfgVars, role string, expiry time.Duration, userName string, cluster *clientcmdapi.Cluster) (string, error) {
 	caCert, err := loadCACert(k0sVars)
 	if err != nil {
 		return "", err
@@ -53,7 +88,8 @@ func CreateKubeletBootstrapToken(ctx context.Context, api *v1beta1.APISpec, k0sV
 		return "", err
 	}
 
-	kubeconfig, err := GenerateKubeconfig(joinURL, caCert, userName, token)
+	cluster.CertificateAuthorityData = caCert
+	kubeconfig, err := generateKubeconfig(cluster, userName, token)
 	if err != nil {
 		return "", err
 	}
@@ -62,12 +98,16 @@ func CreateKubeletBootstrapToken(ctx context.Context, api *v1beta1.APISpec, k0sV
 }
 
 func GenerateKubeconfig(joinURL string, caCert []byte, userName string, token string) ([]byte, error) {
+	return generateKubeconfig(&clientcmdapi.Cluster{
+		Server:                   joinURL,
+		CertificateAuthorityData: caCert,
+	}, userName, token)
+}
+
+func generateKubeconfig(cluster *clientcmdapi.Cluster, userName string, token string) ([]byte, error) {
 	const k0sContextName = "k0s"
 	kubeconfig, err := clientcmd.Write(clientcmdapi.Config{
-		Clusters: map[string]*clientcmdapi.Cluster{k0sContextName: {
-			Server:                   joinURL,
-			CertificateAuthorityData: caCert,
-		}},
+		Clusters: map[string]*clientcmdapi.Cluster{k0sContextName: cluster},
 		Contexts: map[string]*clientcmdapi.Context{k0sContextName: {
 			Cluster:  k0sContextName,
 			AuthInfo: userName,
@@ -91,11 +131,34 @@ func loadUserAndJoinURL(api *v1beta1.APISpec, role string) (string, string, erro
 	}
 }
 
+func loadUserAndTunneledJoinURL(api *v1beta1.APISpec, role string, nodeAddress string) (string, string, error) {
+	if !api.TunneledNetworkingMode {
+		return "", "", errors.New("tunneled tokens require tunneled networking mode")
+	}
+
+	userName, _, err := loadUserAndJoinURL(api, role)
+	if err != nil {
+		return "", "", err
+	}
+
+	port := tunneledKASPort
+	if role == RoleController {
+		if !api.TunneledJoinAPI {
+			return "", "", errors.New("tunneled controller tokens require the tunneled join API")
+		}
+		port = tunneledJoinPort
+	}
+
+	joinURL := url.URL{Scheme: "https", Host: net.JoinHostPort(nodeAddress, strconv.Itoa(port))}
+	return userName, joinURL.String(), nil
+}
+
 func loadCACert(k0sVars constant.CfgVars) ([]byte, error) {
-	crtFile := filepath.Join(k0sVars.CertRootDir, "ca.crt")
-	caCert, err := os.ReadFile(crtFile)
+	// Tokens trust the whole bundle, so that they keep working while the CA is
+	// rotated.
+	caCert, err := certificate.ReadCABundle(k0sVars.CertRootDir)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read cluster CA from %q: %w; check if the control plane is initialized on this node", crtFile, err)
+		return nil, fmt.Errorf("failed to read cluster CA from %q: %w; check if the control plane is initialized on this node", k0sVars.CertRootDir, err)
 	}
 
 	return caCert, nil
@@ -17,10 +17,15 @@ limitations under the License.
 package token
 
 import (
+	"bytes"
+	"net/http"
 	"testing"
 
+	"github.com/k0sproject/k0s/pkg/apis/k0s/v1beta1"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
 func TestGenerateKubeconfig(t *testing.T) {
@@ -48,3 +53,46 @@ users:
 	require.NoError(t, err)
 	assert.Equal(t, expected, string(kubeconfig))
 }
+
+func TestLoadUserAndTunneledJoinURL(t *testing.T) {
+	api := v1beta1.DefaultAPISpec()
+	_, _, err := loadUserAndTunneledJoinURL(api, RoleWorker, "10.0.0.1")
+	assert.ErrorContains(t, err, "tunneled tokens require tunneled networking mode")
+
+	api.TunneledNetworkingMode = true
+	userName, joinURL, err := loadUserAndTunneledJoinURL(api, RoleWorker, "10.0.0.1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "kubelet-bootstrap", userName)
+		assert.Equal(t, "https://10.0.0.1:6443", joinURL)
+	}
+
+	_, _, err = loadUserAndTunneledJoinURL(api, RoleController, "10.0.0.1")
+	assert.ErrorContains(t, err, "tunneled controller tokens require the tunneled join API")
+
+	api.TunneledJoinAPI = true
+	userName, joinURL, err = loadUserAndTunneledJoinURL(api, RoleController, "fe80::1")
+	if assert.NoError(t, err) {
+		assert.Equal(t, "controller-bootstrap", userName)
+		assert.Equal(t, "https://[fe80::1]:9443", joinURL)
+	}
+}
+
+func TestJoinClientFromTunneledToken(t *testing.T) {
+	kubeconfig, err := generateKubeconfig(&clientcmdapi.Cluster{
+		Server:                   "https://10.0.0.1:9443",
+		CertificateAuthorityData: []byte("the cert"),
+		TLSServerName:            tunneledServerName,
+	}, "controller-bootstrap", "the token")
+	require.NoError(t, err)
+	assert.Contains(t, string(kubeconfig), "tls-server-name: localhost")
+	token, err := JoinEncode(bytes.NewReader(kubeconfig))
+	require.NoError(t, err)
+
+	joinClient, err := JoinClientFromToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.1:9443", joinClient.joinAddress)
+	assert.Equal(t, "controller-bootstrap", joinClient.JoinTokenType())
+	if transport, ok := joinClient.httpClient.Transport.(*http.Transport); assert.True(t, ok) {
+		assert.Equal(t, "localhost", transport.TLSClientConfig.ServerName)
+	}
+}
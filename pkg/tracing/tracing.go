@@ -0,0 +1,117 @@
+/*
+Copyright 2023 k0s authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/k0sproject/k0s/pkg/build"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/k0sproject/k0s"
+
+// Tracer returns the tracer that's used for all k0s spans. As long as tracing
+// hasn't been set up, spans aren't recorded.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// End records the given error on the span, if any, and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Enabled checks if traces should be exported to the given endpoint, or the
+// one configured via the standard OpenTelemetry environment variables.
+func Enabled(endpoint string) bool {
+	return endpoint != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" ||
+		os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != ""
+}
+
+// Setup exports traces to the given OTLP/gRPC endpoint, either as host:port,
+// or as URL, in which case the http scheme disables TLS. If the endpoint is
+// empty, the standard OpenTelemetry environment variables are used, e.g.
+// OTEL_EXPORTER_OTLP_ENDPOINT and OTEL_EXPORTER_OTLP_INSECURE. If tracing isn't
+// enabled at all, this is a no-op. The returned function flushes all pending
+// spans and stops the export.
+func Setup(ctx context.Context, endpoint, role string) (shutdown func(context.Context) error, err error) {
+	if !Enabled(endpoint) {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var opts []otlptracegrpc.Option
+	if endpoint != "" {
+		if strings.Contains(endpoint, "://") {
+			u, err := url.Parse(endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("invalid tracing endpoint: %w", err)
+			}
+			switch u.Scheme {
+			case "http":
+				opts = append(opts, otlptracegrpc.WithInsecure())
+			case "https":
+			default:
+				return nil, fmt.Errorf("unsupported tracing endpoint scheme: %q", u.Scheme)
+			}
+			endpoint = u.Host
+		}
+		opts = append(opts, otlptracegrpc.WithEndpoint(endpoint))
+	}
+
+	exporter, err := otlptracegrpc.New(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName("k0s"),
+		semconv.ServiceVersion(build.Version),
+		attribute.String("k0s.role", role),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	return provider.Shutdown, nil
+}